@@ -103,6 +103,9 @@ func main() {
 		log.Fatalf("❌ 打开PDF失败: %v", err)
 	}
 	fmt.Printf("✅ PDF文档已打开，共 %d 页\n", doc.Metadata.Pages)
+	if lang := doc.DetectDominantLanguage(); lang != "" {
+		fmt.Printf("🌐 文档主要语言: %s\n", lang)
+	}
 
 	// 提取文本块
 	fmt.Printf("📝 正在提取文本块...\n")
@@ -132,6 +135,12 @@ func main() {
 
 	startTime := time.Now()
 
+	// Demo 使用更严格的过滤规则：至少5个字符、2个单词，并跳过简短的图表/公式标注
+	blockFilter := translator.NewBlockFilter().WithMinLength(5).WithMinWords(2)
+	if err := blockFilter.AddSkipPatterns(`(?i)^.{0,20}\b(page|fig(ure)?|tab(le)?|eq(uation)?)\b.{0,20}$`); err != nil {
+		log.Fatalf("❌ 创建过滤规则失败: %v", err)
+	}
+
 	for i, block := range textBlocks {
 		// 清理文本块，移除页面标记
 		originalText := strings.TrimSpace(block)
@@ -141,21 +150,9 @@ func main() {
 			}
 		}
 
-		// 跳过过短或空的文本块
-		if originalText == "" || len(originalText) < 5 {
-			fmt.Printf("⏭️  跳过第 %d 个文本块（太短或为空）: %s\n", i+1, truncateText(originalText, 30))
-			continue
-		}
-
-		// 跳过只包含数字、符号或单个单词的文本块
-		if len(strings.Fields(originalText)) < 2 {
-			fmt.Printf("⏭️  跳过第 %d 个文本块（内容太简单）: %s\n", i+1, truncateText(originalText, 30))
-			continue
-		}
-
-		// 跳过看起来像页码或引用的文本
-		if isPageNumberOrReference(originalText) {
-			fmt.Printf("⏭️  跳过第 %d 个文本块（页码或引用）: %s\n", i+1, truncateText(originalText, 30))
+		// 跳过过短、内容太简单或像页码/引用的文本块
+		if !blockFilter.ShouldTranslate(originalText) {
+			fmt.Printf("⏭️  跳过第 %d 个文本块: %s\n", i+1, truncateText(originalText, 30))
 			continue
 		}
 
@@ -870,41 +867,3 @@ func validatePDFContent(pdfPath string) error {
 
 	return nil
 }
-
-// isPageNumberOrReference 检查是否为页码或引用
-func isPageNumberOrReference(text string) bool {
-	text = strings.TrimSpace(text)
-
-	// 检查是否为纯数字（页码）
-	if len(text) < 10 && strings.TrimSpace(text) != "" {
-		allDigits := true
-		for _, char := range text {
-			if !((char >= '0' && char <= '9') || char == '.' || char == '-' || char == ' ') {
-				allDigits = false
-				break
-			}
-		}
-		if allDigits {
-			return true
-		}
-	}
-
-	// 检查是否为引用格式 [数字] 或 (数字)
-	if len(text) < 20 && (strings.HasPrefix(text, "[") && strings.HasSuffix(text, "]")) {
-		return true
-	}
-	if len(text) < 20 && (strings.HasPrefix(text, "(") && strings.HasSuffix(text, ")")) {
-		return true
-	}
-
-	// 检查是否为常见的页面元素
-	lowerText := strings.ToLower(text)
-	pageElements := []string{"page", "figure", "table", "fig", "tab", "eq", "equation"}
-	for _, element := range pageElements {
-		if strings.Contains(lowerText, element) && len(text) < 50 {
-			return true
-		}
-	}
-
-	return false
-}
@@ -0,0 +1,252 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"translator-web/middleware"
+	"translator-web/models"
+	"translator-web/translator"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// maxCacheWarmStrings 单次预热最多接受的文本数
+const maxCacheWarmStrings = 20000
+
+// cacheWarmRetention 已结束的预热任务保留时间，过期后不再能查询进度
+const cacheWarmRetention = time.Hour
+
+// cacheWarmJob 后台缓存预热任务
+type cacheWarmJob struct {
+	ID        string                       `json:"id"`
+	SessionID string                       `json:"-"`
+	Status    string                       `json:"status"` // running, completed, canceled, timed_out
+	Progress  translator.CacheWarmProgress `json:"progress"`
+	Error     string                       `json:"error,omitempty"`
+	CreatedAt time.Time                    `json:"createdAt"`
+	EndedAt   time.Time                    `json:"endedAt,omitempty"`
+
+	cancel context.CancelFunc
+}
+
+var (
+	cacheWarmMu   sync.Mutex
+	cacheWarmJobs = make(map[string]*cacheWarmJob)
+)
+
+// getCacheWarmJob 返回会话中预热任务的副本
+func getCacheWarmJob(sessionID, jobID string) (cacheWarmJob, bool) {
+	cacheWarmMu.Lock()
+	defer cacheWarmMu.Unlock()
+	job, ok := cacheWarmJobs[jobID]
+	if !ok || job.SessionID != sessionID {
+		return cacheWarmJob{}, false
+	}
+	return *job, true
+}
+
+// addCacheWarmJob 登记预热任务，并清理过期的已结束任务
+func addCacheWarmJob(job *cacheWarmJob) {
+	cacheWarmMu.Lock()
+	defer cacheWarmMu.Unlock()
+	for id, existing := range cacheWarmJobs {
+		if !existing.EndedAt.IsZero() && time.Since(existing.EndedAt) > cacheWarmRetention {
+			delete(cacheWarmJobs, id)
+		}
+	}
+	cacheWarmJobs[job.ID] = job
+}
+
+// bindCacheWarmRequest 解析 JSON 请求，或包含 strings（JSON 数组）和/或 tmx 文件的表单
+// TMX 中只使用原文，译文由当前提供商重新翻译
+func bindCacheWarmRequest(c *gin.Context) (models.CacheWarmRequest, bool) {
+	var req models.CacheWarmRequest
+	if c.ContentType() == "application/json" {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondError(c, badRequest("请求格式错误: "+err.Error()))
+			return req, false
+		}
+		return req, storedLLMConfig(c, &req.LLMConfig)
+	}
+
+	var form models.TranslateRequest
+	if !bindTranslateForm(c, &form) {
+		return req, false
+	}
+	req.TargetLanguage = form.TargetLanguage
+	req.DocumentType = c.PostForm("documentType")
+	req.UserPrompt = form.UserPrompt
+	req.Formality = form.Formality
+	req.Concurrency = form.Concurrency
+	req.BatchSize = form.BatchSize
+	req.LLMConfig = form.LLMConfig
+
+	if stringsStr := c.PostForm("strings"); stringsStr != "" {
+		if err := json.Unmarshal([]byte(stringsStr), &req.Strings); err != nil {
+			respondError(c, badRequest("strings 格式错误: "+err.Error()))
+			return req, false
+		}
+	}
+	for source := range form.TMX {
+		req.Strings = append(req.Strings, source)
+	}
+	return req, true
+}
+
+// cacheWarmDocumentType 校验预热针对的文档类型，为空表示 EPUB/PPTX 等直接使用目标语言的流程
+func cacheWarmDocumentType(docType string) (translator.DocumentType, error) {
+	switch t := translator.DocumentType(strings.ToLower(docType)); t {
+	case "", translator.DocumentTypeEPUB, translator.DocumentTypePDF, translator.DocumentTypePPTX, translator.DocumentTypeCSV, translator.DocumentTypeTSV:
+		return t, nil
+	default:
+		return "", errors.New("不支持的文档类型: " + docType + "，可选: epub、pdf、pptx、csv、tsv")
+	}
+}
+
+// WarmCacheHandler 在后台按给定原文（或 TMX 中的原文）预先翻译并写入会话缓存
+// 已缓存的文本会被跳过，可重复提交以继续中断的预热；返回任务 ID 用于查询进度或取消
+func WarmCacheHandler(c *gin.Context) {
+	sessionID := middleware.GetSessionID(c)
+	if sessionID == "" {
+		respondError(c, errInvalidSession)
+		return
+	}
+
+	req, ok := bindCacheWarmRequest(c)
+	if !ok {
+		return
+	}
+	if len(req.Strings) == 0 {
+		respondError(c, badRequest("没有需要预热的文本，请提供 strings 或 tmx"))
+		return
+	}
+	if len(req.Strings) > maxCacheWarmStrings {
+		respondError(c, newAPIError(http.StatusRequestEntityTooLarge, CodePayloadTooLarge, "文本过多，单次最多预热 "+strconv.Itoa(maxCacheWarmStrings)+" 条"))
+		return
+	}
+	if req.TargetLanguage == "" {
+		respondError(c, badRequest("目标语言不能为空"))
+		return
+	}
+	docType, err := cacheWarmDocumentType(req.DocumentType)
+	if err != nil {
+		respondError(c, badRequest(err.Error()))
+		return
+	}
+	if err := translator.ValidateFormality(req.Formality); err != nil {
+		respondError(c, badRequest(err.Error()))
+		return
+	}
+	if err := normalizeLLMConfig(&req.LLMConfig); err != nil {
+		respondError(c, badRequest(err.Error()))
+		return
+	}
+	if apiErr := validateLanguages(req.LLMConfig, req.Formality, req.TargetLanguage); apiErr != nil {
+		respondError(c, apiErr)
+		return
+	}
+
+	// 与文档翻译使用相同的缓存和术语表，保证缓存键一致
+	cache, _ := translator.NewCache(filepath.Join("data", "users", sessionID, "cache"))
+	glossary := taskGlossary(sessionID, req.TargetLanguage, req.LLMConfig.Extra["domain"])
+	client, err := translator.NewTranslatorClient(withGlossary(newProviderConfig(req.LLMConfig, req.Formality), glossary), cache)
+	if err != nil {
+		respondError(c, badRequest(err.Error()))
+		return
+	}
+	client.WithThroughput(req.Concurrency, req.BatchSize).WithGlossary(glossary)
+
+	ctx, cancel := taskContext(models.TranslateRequest{})
+	client.WithContext(ctx)
+
+	job := &cacheWarmJob{
+		ID:        uuid.New().String(),
+		SessionID: sessionID,
+		Status:    "running",
+		CreatedAt: time.Now(),
+		cancel:    cancel,
+	}
+	addCacheWarmJob(job)
+
+	warmer := translator.NewCacheWarmer(client, cache, req.TargetLanguage, req.UserPrompt).
+		WithDocumentType(docType).
+		WithProgress(func(progress translator.CacheWarmProgress) {
+			cacheWarmMu.Lock()
+			job.Progress = progress
+			cacheWarmMu.Unlock()
+		})
+
+	go func() {
+		defer cancel()
+		progress, err := warmer.Warm(req.Strings)
+
+		cacheWarmMu.Lock()
+		job.Progress = progress
+		job.EndedAt = time.Now()
+		switch {
+		case errors.Is(err, context.DeadlineExceeded):
+			job.Status = "timed_out"
+			job.Error = "预热超时"
+		case err != nil:
+			job.Status = "canceled"
+			job.Error = "预热已取消"
+		default:
+			job.Status = "completed"
+		}
+		cacheWarmMu.Unlock()
+
+		log.Printf("[会话 %s] 缓存预热结束（%s）：共 %d 条，已缓存 %d，新写入 %d，失败 %d",
+			sessionID[:8], job.Status, progress.Total, progress.Cached, progress.Warmed, progress.Failed)
+	}()
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"jobId":  job.ID,
+		"status": job.Status,
+	})
+}
+
+// GetCacheWarmHandler 查询缓存预热进度
+func GetCacheWarmHandler(c *gin.Context) {
+	sessionID := middleware.GetSessionID(c)
+	if sessionID == "" {
+		respondError(c, errInvalidSession)
+		return
+	}
+
+	job, ok := getCacheWarmJob(sessionID, c.Param("jobId"))
+	if !ok {
+		respondError(c, notFound("预热任务不存在或无权访问"))
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
+
+// CancelCacheWarmHandler 取消进行中的缓存预热，已写入的缓存保留
+func CancelCacheWarmHandler(c *gin.Context) {
+	sessionID := middleware.GetSessionID(c)
+	if sessionID == "" {
+		respondError(c, errInvalidSession)
+		return
+	}
+
+	job, ok := getCacheWarmJob(sessionID, c.Param("jobId"))
+	if !ok {
+		respondError(c, notFound("预热任务不存在或无权访问"))
+		return
+	}
+	if job.Status != "running" {
+		respondError(c, badRequest("预热任务已结束"))
+		return
+	}
+	job.cancel()
+	c.JSON(http.StatusOK, gin.H{"message": "已取消"})
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// warmCache 提交预热请求并等待结束，返回最终的任务状态
+func warmCache(t *testing.T, r *gin.Engine, body string) cacheWarmJob {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/cache/warm", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("状态码 = %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		JobID string `json:"jobId"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/cache/warm/"+resp.JobID, nil))
+		var job cacheWarmJob
+		json.Unmarshal(w.Body.Bytes(), &job)
+		if job.Status != "running" {
+			return job
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("预热任务 %s 未在限定时间内结束", resp.JobID)
+	return cacheWarmJob{}
+}
+
+func TestWarmCacheHandler(t *testing.T) {
+	dir := chdirTemp(t)
+	const sessionID = "session-cache-warm"
+
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Write([]byte(`{"choices":[{"message":{"content":"已预热的译文"}}]}`))
+	}))
+	defer server.Close()
+
+	r := newTestRouter(sessionID, func(r *gin.Engine) {
+		r.POST("/cache/warm", WarmCacheHandler)
+		r.GET("/cache/warm/:jobId", GetCacheWarmHandler)
+	})
+	body := `{"strings":["The first sentence.","The second sentence.","The third sentence."],"targetLanguage":"Uni",` +
+		`"llmConfig":{"provider":"openai","apiUrl":"` + server.URL + `","apiKey":"key","model":"gpt-test"}}`
+
+	job := warmCache(t, r, body)
+	if job.Status != "completed" || job.Progress.Warmed != 3 {
+		t.Fatalf("首次预热 = %+v", job)
+	}
+	entries, _ := os.ReadDir(filepath.Join(dir, "data", "users", sessionID, "cache"))
+	if len(entries) != 3 || calls.Load() != 3 {
+		t.Errorf("缓存 %d 条、请求 %d 次，期望均为 3", len(entries), calls.Load())
+	}
+
+	job = warmCache(t, r, body)
+	if job.Status != "completed" || job.Progress.Cached != 3 || job.Progress.Warmed != 0 {
+		t.Errorf("再次预热 = %+v", job)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("再次预热后共请求 %d 次，期望仍为 3", calls.Load())
+	}
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/cache/warm", strings.NewReader(`{"strings":[],"targetLanguage":"Uni"}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("没有文本时状态码 = %d，期望 400", w.Code)
+	}
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"os"
+	"translator-web/middleware"
+	"translator-web/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ChecksumHandler 返回下载文件的 MD5 校验和（与下载时的 X-Content-MD5 响应头一致）
+func ChecksumHandler(c *gin.Context) {
+	sessionID := middleware.GetSessionID(c)
+	if sessionID == "" {
+		respondError(c, errInvalidSession)
+		return
+	}
+
+	taskID := c.Param("taskId")
+	task, exists := taskManager.GetTask(sessionID, taskID)
+	if !exists {
+		respondError(c, notFound("任务不存在或无权访问"))
+		return
+	}
+
+	outputPath, filename, ok := resolveDownload(c, task)
+	if !ok {
+		return
+	}
+
+	sum, err := taskChecksum(sessionID, taskID, outputPath)
+	if err != nil {
+		respondError(c, internalError("计算校验和失败: "+err.Error()))
+		return
+	}
+
+	info, err := os.Stat(outputPath)
+	if err != nil {
+		respondError(c, notFound("翻译文件不存在"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"taskId":    taskID,
+		"filename":  filename,
+		"size":      info.Size(),
+		"algorithm": "md5",
+		"checksum":  sum,
+	})
+}
+
+// taskChecksum 返回输出文件的 MD5（十六进制），首次计算后缓存在任务中
+func taskChecksum(sessionID, taskID, path string) (string, error) {
+	var sum string
+	taskManager.UpdateTask(sessionID, taskID, func(t *models.TranslateTask) {
+		sum = t.Checksums[path]
+	})
+	if sum != "" {
+		return sum, nil
+	}
+
+	sum, err := fileMD5(path)
+	if err != nil {
+		return "", err
+	}
+
+	taskManager.UpdateTask(sessionID, taskID, func(t *models.TranslateTask) {
+		if t.Checksums == nil {
+			t.Checksums = make(map[string]string)
+		}
+		t.Checksums[path] = sum
+	})
+	return sum, nil
+}
+
+// fileMD5 流式计算文件的 MD5，不会把整个文件读入内存
+func fileMD5(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	hash := md5.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// setChecksumHeaders 设置 X-Content-MD5（十六进制）和 Content-Digest（RFC 9530）响应头
+func setChecksumHeaders(c *gin.Context, sum string) {
+	c.Header("X-Content-MD5", sum)
+	if raw, err := hex.DecodeString(sum); err == nil {
+		c.Header("Content-Digest", "md5=:"+base64.StdEncoding.EncodeToString(raw)+":")
+	}
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"translator-web/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestDownloadChecksumMatchesFile(t *testing.T) {
+	chdirTemp(t)
+	const sessionID = "session-checksum"
+	outputPath := OutputPath(sessionID, "task-checksum-1", "paper.pdf", "mono", ".pdf")
+	writeTestPDF(t, outputPath, 2)
+	taskManager.AddTask(sessionID, &models.TranslateTask{
+		ID:         "task-checksum-1",
+		SessionID:  sessionID,
+		SourceFile: "paper.pdf",
+		Status:     "completed",
+		OutputPath: outputPath,
+	})
+
+	data, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw := md5.Sum(data)
+	want := hex.EncodeToString(raw[:])
+
+	r := newTestRouter(sessionID, func(r *gin.Engine) {
+		r.GET("/download/:taskId", DownloadHandler)
+		r.GET("/download/:taskId/checksum", ChecksumHandler)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/download/task-checksum-1", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("状态码 = %d: %s", w.Code, w.Body.String())
+	}
+	if got := w.Header().Get("X-Content-MD5"); got != want {
+		t.Errorf("X-Content-MD5 = %q，期望 %q", got, want)
+	}
+	if got, digest := w.Header().Get("Content-Digest"), "md5=:"+base64.StdEncoding.EncodeToString(raw[:])+":"; got != digest {
+		t.Errorf("Content-Digest = %q，期望 %q", got, digest)
+	}
+	body := md5.Sum(w.Body.Bytes())
+	if hex.EncodeToString(body[:]) != want {
+		t.Error("下载内容与文件不一致")
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/download/task-checksum-1/checksum", nil))
+	var resp struct {
+		Checksum string `json:"checksum"`
+		Size     int64  `json:"size"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("解析响应失败: %v: %s", err, w.Body.String())
+	}
+	if resp.Checksum != want || resp.Size != int64(len(data)) {
+		t.Errorf("checksum=%q size=%d，期望 %q、%d", resp.Checksum, resp.Size, want, len(data))
+	}
+}
@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"translator-web/translator"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 错误码，前端据此区分错误类型，error 字段中的提示信息仅用于展示
+const (
+	CodeInvalidRequest      = "INVALID_REQUEST"         // 请求参数错误
+	CodeInvalidSession      = "INVALID_SESSION"         // 会话无效
+	CodeNotFound            = "NOT_FOUND"               // 资源不存在或无权访问
+	CodeTaskNotReady        = "TASK_NOT_READY"          // 任务尚未完成，结果不可用
+	CodeUnsupportedFormat   = "UNSUPPORTED_FORMAT"      // 不支持的文件类型
+	CodeUnsupportedLanguage = "UNSUPPORTED_LANGUAGE"    // 提供商不支持所选语言
+	CodePayloadTooLarge     = "PAYLOAD_TOO_LARGE"       // 上传的文件或文本超过大小限制
+	CodeEncryptedPDF        = "ENCRYPTED_PDF"           // PDF 已加密，无法读取
+	CodeInvalidDocument     = "INVALID_DOCUMENT"        // 文档损坏或无法解析
+	CodeAlreadyTarget       = "ALREADY_TARGET_LANGUAGE" // 文档的主要语言已是目标语言
+	CodeInvalidShareLink    = "INVALID_SHARE_LINK"      // 共享链接无效或已过期
+	CodeProviderAuth        = "PROVIDER_AUTH"           // 翻译服务认证失败（API Key 无效或无权限）
+	CodeProviderError       = "PROVIDER_ERROR"          // 翻译服务返回错误或无法连接
+	CodeRateLimited         = "RATE_LIMITED"            // 请求过于频繁（本服务或翻译服务限流）
+	CodeQuotaExceeded       = "QUOTA_EXCEEDED"          // 翻译服务额度已用尽
+	CodeTimeout             = "TIMEOUT"                 // 处理超时
+	CodeInternal            = "INTERNAL_ERROR"          // 服务端内部错误
+)
+
+// APIError 接口错误：HTTP 状态码、机器可读的错误码和提示信息
+// 以 {"code": "...", "error": "..."} 返回，error 字段与原有响应格式兼容
+type APIError struct {
+	Status  int    `json:"-"`
+	Code    string `json:"code"`
+	Message string `json:"error"`
+}
+
+func (e *APIError) Error() string {
+	return e.Message
+}
+
+// newAPIError 创建接口错误
+func newAPIError(status int, code, message string) *APIError {
+	return &APIError{Status: status, Code: code, Message: message}
+}
+
+// badRequest 请求参数错误
+func badRequest(message string) *APIError {
+	return newAPIError(http.StatusBadRequest, CodeInvalidRequest, message)
+}
+
+// notFound 资源不存在
+func notFound(message string) *APIError {
+	return newAPIError(http.StatusNotFound, CodeNotFound, message)
+}
+
+// internalError 服务端内部错误
+func internalError(message string) *APIError {
+	return newAPIError(http.StatusInternalServerError, CodeInternal, message)
+}
+
+// errInvalidSession 会话无效
+var errInvalidSession = newAPIError(http.StatusUnauthorized, CodeInvalidSession, "无效的会话")
+
+// respondError 以 JSON 返回错误，extra 中的字段（如长度上限）一并返回
+func respondError(c *gin.Context, err *APIError, extra ...gin.H) {
+	body := gin.H{"code": err.Code, "error": err.Message}
+	for _, fields := range extra {
+		for k, v := range fields {
+			body[k] = v
+		}
+	}
+	c.JSON(err.Status, body)
+}
+
+// classifyError 将文档处理和翻译服务的已知错误映射为对应的错误码和状态码
+// 无法识别的错误返回 fallback；提示信息始终使用 fallback 的提示信息
+func classifyError(err error, fallback *APIError) *APIError {
+	status, code, ok := errorCode(err)
+	if !ok {
+		return fallback
+	}
+	return newAPIError(status, code, fallback.Message)
+}
+
+// errorCode 识别已知错误的状态码和错误码
+func errorCode(err error) (int, string, bool) {
+	if err == nil {
+		return 0, "", false
+	}
+	if errors.Is(err, translator.ErrEncryptedPDF) {
+		return http.StatusUnprocessableEntity, CodeEncryptedPDF, true
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return http.StatusGatewayTimeout, CodeTimeout, true
+	}
+
+	message := strings.ToLower(err.Error())
+	var httpErr *translator.ProviderHTTPError
+	if errors.As(err, &httpErr) {
+		switch {
+		case httpErr.StatusCode == http.StatusUnauthorized || httpErr.StatusCode == http.StatusForbidden:
+			return http.StatusBadGateway, CodeProviderAuth, true
+		case httpErr.StatusCode == http.StatusPaymentRequired || isQuotaMessage(message):
+			return http.StatusPaymentRequired, CodeQuotaExceeded, true
+		case httpErr.StatusCode == http.StatusTooManyRequests:
+			return http.StatusTooManyRequests, CodeRateLimited, true
+		default:
+			return http.StatusBadGateway, CodeProviderError, true
+		}
+	}
+
+	// 部分服务以 200 状态码返回错误信息（如腾讯云的 AuthFailure、LimitExceeded）
+	switch {
+	case containsAny(message, "authfailure", "unauthorized", "invalid api key", "incorrect api key", "invalid_api_key"):
+		return http.StatusBadGateway, CodeProviderAuth, true
+	case isQuotaMessage(message):
+		return http.StatusPaymentRequired, CodeQuotaExceeded, true
+	case containsAny(message, "rate limit", "too many requests", "limitexceeded"):
+		return http.StatusTooManyRequests, CodeRateLimited, true
+	}
+	return 0, "", false
+}
+
+// isQuotaMessage 判断错误信息是否表示额度用尽
+func isQuotaMessage(message string) bool {
+	return containsAny(message, "insufficient_quota", "quota", "billing", "余额不足")
+}
+
+// containsAny 判断 s 是否包含任意一个子串
+func containsAny(s string, substrs ...string) bool {
+	for _, sub := range substrs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkPDFEncryption 检查上传的 PDF 是否加密，加密时返回 translator.ErrEncryptedPDF
+func checkPDFEncryption(file *uploadedFile) error {
+	src, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	return translator.CheckPDFEncryption(src, file.Size)
+}
+
+// taskErrorCode 返回异步任务失败原因的错误码
+// PDF 格式不兼容时为 INVALID_DOCUMENT，其余无法识别的错误为 INTERNAL_ERROR
+func taskErrorCode(errorMsg string, err error) string {
+	if _, code, ok := errorCode(err); ok {
+		return code
+	}
+	if strings.Contains(errorMsg, "PDF文件格式不兼容") {
+		return CodeInvalidDocument
+	}
+	return CodeInternal
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"translator-web/translator"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// encryptedPDF 生成需要打开密码的 PDF
+func encryptedPDF(t *testing.T) []byte {
+	t.Helper()
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetProtection(gofpdf.CnProtectPrint, "secret", "owner")
+	pdf.SetFont("Helvetica", "", 12)
+	pdf.AddPage()
+	pdf.Text(20, 30, "Confidential page")
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestTranslateHandlerRejectsEncryptedPDF(t *testing.T) {
+	chdirTemp(t)
+	const sessionID = "session-encrypted"
+
+	w := postTranslate(t, sessionID, encryptedPDF(t), map[string]string{
+		"targetLanguage": "Uni",
+		"llmConfig":      `{"provider":"openai","apiUrl":"http://127.0.0.1:1","apiKey":"key","model":"gpt-test"}`,
+	})
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("状态码 = %d，期望 422: %s", w.Code, w.Body.String())
+	}
+	var resp map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp["code"] != CodeEncryptedPDF || resp["error"] == "" {
+		t.Errorf("响应 = %v，期望 code 为 %s", resp, CodeEncryptedPDF)
+	}
+	if n := len(taskManager.GetUserTasks(sessionID)); n != 0 {
+		t.Errorf("加密的 PDF 不应创建任务，实际创建 %d 个", n)
+	}
+}
+
+func TestErrorCode(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{fmt.Errorf("打开文档失败: %w", translator.ErrEncryptedPDF), http.StatusUnprocessableEntity, CodeEncryptedPDF},
+		{fmt.Errorf("翻译失败: %w", context.DeadlineExceeded), http.StatusGatewayTimeout, CodeTimeout},
+		{&translator.ProviderHTTPError{StatusCode: 401, Body: "invalid key"}, http.StatusBadGateway, CodeProviderAuth},
+		{&translator.ProviderHTTPError{StatusCode: 429, Body: `{"error":{"code":"insufficient_quota"}}`}, http.StatusPaymentRequired, CodeQuotaExceeded},
+		{&translator.ProviderHTTPError{StatusCode: 429, Body: "slow down"}, http.StatusTooManyRequests, CodeRateLimited},
+		{&translator.ProviderHTTPError{StatusCode: 500, Body: "oops"}, http.StatusBadGateway, CodeProviderError},
+		{errors.New("腾讯云翻译错误: AuthFailure.SignatureFailure"), http.StatusBadGateway, CodeProviderAuth},
+		{errors.New("LimitExceeded: 请求频率超限"), http.StatusTooManyRequests, CodeRateLimited},
+	}
+	for _, tt := range tests {
+		status, code, ok := errorCode(tt.err)
+		if !ok || status != tt.status || code != tt.code {
+			t.Errorf("errorCode(%v) = %d %s %v，期望 %d %s", tt.err, status, code, ok, tt.status, tt.code)
+		}
+	}
+
+	if _, _, ok := errorCode(errors.New("something else")); ok {
+		t.Error("未知错误不应被识别")
+	}
+	fallback := internalError("处理失败")
+	if got := classifyError(errors.New("something else"), fallback); got != fallback {
+		t.Errorf("未知错误应返回 fallback，得到 %+v", got)
+	}
+	if got := classifyError(translator.ErrEncryptedPDF, fallback); got.Code != CodeEncryptedPDF || got.Message != "处理失败" {
+		t.Errorf("classifyError = %+v", got)
+	}
+	if code := taskErrorCode("PDF文件格式不兼容", errors.New("bad xref")); code != CodeInvalidDocument {
+		t.Errorf("taskErrorCode = %s，期望 %s", code, CodeInvalidDocument)
+	}
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"translator-web/middleware"
+	"translator-web/models"
+	"translator-web/translator"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// EstimateHandler 预估翻译量：解析上传的文档并按过滤规则和缓存统计，不调用翻译服务
+func EstimateHandler(c *gin.Context) {
+	sessionID := middleware.GetSessionID(c)
+	if sessionID == "" {
+		respondError(c, errInvalidSession)
+		return
+	}
+
+	file, ext, ok := uploadedDocument(c)
+	if !ok {
+		return
+	}
+
+	var req models.TranslateRequest
+	if !bindTranslateForm(c, &req) {
+		return
+	}
+	if req.TargetLanguage == "" {
+		respondError(c, badRequest("目标语言不能为空"))
+		return
+	}
+	if err := translator.ValidateFormality(req.Formality); err != nil {
+		respondError(c, badRequest(err.Error()))
+		return
+	}
+	filter, err := newBlockFilter(req.BlockFilter)
+	if err != nil {
+		respondError(c, badRequest(err.Error()))
+		return
+	}
+	if req.LLMConfig.Provider == "" {
+		req.LLMConfig.Provider = "openai"
+	}
+
+	// 上传文件只用于预估，处理完即删除
+	uploadDir := filepath.Join("data", "users", sessionID, "uploads")
+	if err := os.MkdirAll(uploadDir, 0755); err != nil {
+		respondError(c, internalError("创建上传目录失败: "+err.Error()))
+		return
+	}
+	sourcePath := filepath.Join(uploadDir, "estimate-"+uuid.New().String()+ext)
+	if err := file.SaveTo(sourcePath); err != nil {
+		respondError(c, internalError("保存文件失败: "+err.Error()))
+		return
+	}
+	defer os.Remove(sourcePath)
+	if sourcePath, err = convertKindleSource(c.Request.Context(), sourcePath); err != nil {
+		respondError(c, newAPIError(http.StatusBadRequest, CodeInvalidDocument, err.Error()))
+		return
+	}
+	defer os.Remove(sourcePath)
+
+	// 强制重新翻译时不会读取缓存
+	var cache translator.CacheStore
+	if !req.ForceRetranslate {
+		cache, _ = translator.NewCache(filepath.Join("data", "users", sessionID, "cache"))
+	}
+
+	estimate, err := translator.EstimateDocument(sourcePath, req.TargetLanguage, req.UserPrompt, newProviderConfig(req.LLMConfig, req.Formality), cache, filter)
+	if err != nil {
+		log.Printf("[会话 %s] 预估翻译量失败: %v", sessionID[:8], err)
+		respondError(c, classifyError(err, newAPIError(http.StatusBadRequest, CodeInvalidDocument, "解析文档失败: "+err.Error())))
+		return
+	}
+
+	c.JSON(http.StatusOK, estimate)
+}
@@ -0,0 +1,351 @@
+package handlers
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"translator-web/models"
+	"translator-web/translator"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// defaultMaxTargetLanguages 默认单个请求最多的目标语言数
+const defaultMaxTargetLanguages = 5
+
+// maxTargetLanguages 单个请求最多的目标语言数，可通过环境变量 MAX_TARGET_LANGUAGES 设置
+// 各语言的子任务在父任务的队列位置中依次执行，限制语言数避免单个请求长时间占用队列
+var maxTargetLanguages = maxTargetLanguagesFromEnv()
+
+// maxTargetLanguagesFromEnv 从 MAX_TARGET_LANGUAGES 读取目标语言数上限
+func maxTargetLanguagesFromEnv() int {
+	value := os.Getenv("MAX_TARGET_LANGUAGES")
+	if value == "" {
+		return defaultMaxTargetLanguages
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		log.Printf("⚠️  MAX_TARGET_LANGUAGES 无效 %q，使用默认值 %d", value, defaultMaxTargetLanguages)
+		return defaultMaxTargetLanguages
+	}
+	return n
+}
+
+// parseTargetLanguages 解析表单字段 targetLanguages：JSON 数组（如 ["zh","ja"]）或逗号分隔的列表，去除空白和重复项
+// 去重后超过 maxTargetLanguages 种语言时返回错误
+func parseTargetLanguages(value string) ([]string, error) {
+	var parts []string
+	if strings.HasPrefix(strings.TrimSpace(value), "[") {
+		if err := json.Unmarshal([]byte(value), &parts); err != nil {
+			return nil, fmt.Errorf("targetLanguages 格式错误: %w", err)
+		}
+	} else {
+		parts = strings.Split(value, ",")
+	}
+
+	var languages []string
+	seen := make(map[string]bool)
+	for _, part := range parts {
+		lang := strings.TrimSpace(part)
+		if lang == "" || seen[lang] {
+			continue
+		}
+		seen[lang] = true
+		languages = append(languages, lang)
+	}
+	if len(languages) > maxTargetLanguages {
+		return nil, fmt.Errorf("targetLanguages 最多 %d 种语言，实际 %d 种", maxTargetLanguages, len(languages))
+	}
+	return languages, nil
+}
+
+// targetLanguages 返回请求的目标语言，未设置 targetLanguages 时为 targetLanguage
+func targetLanguages(req models.TranslateRequest) []string {
+	if len(req.TargetLanguages) > 0 {
+		return req.TargetLanguages
+	}
+	return []string{req.TargetLanguage}
+}
+
+// submitFanOut 创建多目标语言任务：父任务负责排队和汇总，每种语言一个子任务
+// 子任务在父任务中依次执行，共享文档的解析结果，只有翻译和生成输出按语言分别进行
+func submitFanOut(c *gin.Context, sessionID string, file *uploadedFile, ext string, req models.TranslateRequest, requestHash string) {
+	languages := req.TargetLanguages
+	parentID := uuid.New().String()
+	parent := &models.TranslateTask{
+		ID:             parentID,
+		SessionID:      sessionID,
+		SourceFile:     file.Filename,
+		TargetLanguage: strings.Join(languages, ","),
+		Status:         "pending",
+		CreatedAt:      time.Now(),
+		RequestHash:    requestHash,
+		SourceLanguage: sourceLanguage(req.LLMConfig),
+		SubTasks:       make(map[string]string, len(languages)),
+	}
+	if existingID, duplicate := taskManager.AddTaskUnlessDuplicate(sessionID, parent, !req.ForceRetranslate); duplicate {
+		log.Printf("检测到重复提交，复用任务 %s", existingID)
+		existing, _ := taskManager.GetTask(sessionID, existingID)
+		c.JSON(http.StatusOK, gin.H{
+			"taskId":    existingID,
+			"subTasks":  existing.SubTasks,
+			"message":   "相同的翻译任务已存在",
+			"duplicate": true,
+		})
+		return
+	}
+
+	sourcePath, ok := saveSource(c, sessionID, parentID, file, ext)
+	if !ok {
+		return
+	}
+
+	subTasks := make(map[string]string, len(languages))
+	for _, lang := range languages {
+		subReq := languageRequest(req, lang)
+		subTask := &models.TranslateTask{
+			ID:             uuid.New().String(),
+			SessionID:      sessionID,
+			SourceFile:     file.Filename,
+			TargetLanguage: lang,
+			Status:         "pending",
+			CreatedAt:      parent.CreatedAt,
+			Concurrency:    req.Concurrency,
+			BatchSize:      req.BatchSize,
+			Streaming:      req.Stream,
+			SourceLanguage: parent.SourceLanguage,
+			SourcePath:     sourcePath,
+			Request:        &subReq,
+			ParentID:       parentID,
+		}
+		taskManager.AddTask(sessionID, subTask)
+		subTasks[lang] = subTask.ID
+
+		if req.Stream {
+			openTaskStream(subTask.ID)
+		}
+	}
+	taskManager.UpdateTask(sessionID, parentID, func(t *models.TranslateTask) {
+		t.SourcePath = sourcePath
+		for lang, id := range subTasks {
+			t.SubTasks[lang] = id
+		}
+	})
+
+	position := taskQueue.Submit(sessionID, parentID, func() {
+		processFanOut(sessionID, parentID, file.Filename, sourcePath, req, subTasks)
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"taskId":        parentID,
+		"subTasks":      subTasks,
+		"message":       fmt.Sprintf("翻译任务已创建（%d 种目标语言）", len(languages)),
+		"queuePosition": position,
+	})
+}
+
+// languageRequest 返回翻译成指定语言的子任务配置
+func languageRequest(req models.TranslateRequest, lang string) models.TranslateRequest {
+	req.TargetLanguage = lang
+	req.TargetLanguages = nil
+	return req
+}
+
+// processFanOut 依次将文档翻译成各目标语言，完成后打包所有语言的输出
+func processFanOut(sessionID, parentID, originalName, sourcePath string, req models.TranslateRequest, subTasks map[string]string) {
+	defer observeTaskDuration(sessionID, parentID, time.Now())
+
+	taskManager.UpdateTask(sessionID, parentID, func(t *models.TranslateTask) {
+		t.Status = "processing"
+	})
+	log.Printf("[会话 %s][任务 %s] 开始翻译成 %d 种语言", sessionID[:8], parentID, len(req.TargetLanguages))
+
+	// Kindle 电子书只转换一次，各语言使用转换后的 EPUB
+	ctx, cancel := taskContext(req)
+	converted, err := convertKindleSource(ctx, sourcePath)
+	cancel()
+	if err != nil {
+		for _, id := range subTasks {
+			taskManager.UpdateTask(sessionID, id, func(t *models.TranslateTask) {
+				t.Status = "failed"
+				t.Error = err.Error()
+				t.ErrorCode = CodeInvalidDocument
+			})
+		}
+		finishFanOut(sessionID, parentID, originalName, req.TargetLanguages, subTasks)
+		log.Printf("[会话 %s][任务 %s] 转换电子书失败: %v", sessionID[:8], parentID, err)
+		return
+	}
+	sourcePath = converted
+
+	// 文档只解析一次，各语言共享解析结果和文本块过滤结果
+	source := translator.NewSourceCache()
+	for i, lang := range req.TargetLanguages {
+		id := subTasks[lang]
+		taskManager.UpdateTask(sessionID, id, func(t *models.TranslateTask) {
+			t.SourcePath = sourcePath
+		})
+
+		subReq := languageRequest(req, lang)
+		ctx, cancel := taskContext(subReq)
+		processTranslation(ctx, sessionID, id, originalName, sourcePath, subReq, source)
+		cancel()
+
+		taskManager.UpdateTask(sessionID, parentID, func(t *models.TranslateTask) {
+			t.Progress = float64(i+1) / float64(len(req.TargetLanguages))
+		})
+	}
+	log.Printf("[会话 %s][任务 %s] %d 种语言共解析文档 %d 次", sessionID[:8], parentID, len(req.TargetLanguages), source.Parses())
+
+	finishFanOut(sessionID, parentID, originalName, req.TargetLanguages, subTasks)
+}
+
+// finishFanOut 将各语言子任务的输出打包为 ZIP（每种语言一个目录），并按子任务的结果设置父任务状态
+// 所有语言都完成时为 completed，部分语言有输出时为 partial，都没有输出时为 failed
+func finishFanOut(sessionID, parentID, originalName string, languages []string, subTasks map[string]string) {
+	var finished, withOutput int
+	var firstErr, firstCode string
+	files := make(map[string]string) // ZIP 中的路径 -> 输出文件
+	for _, lang := range languages {
+		sub, ok := taskManager.GetTask(sessionID, subTasks[lang])
+		if !ok {
+			continue
+		}
+		if sub.Status == "completed" {
+			finished++
+		} else if firstErr == "" {
+			firstErr = fmt.Sprintf("%s: %s", lang, sub.Error)
+			firstCode = sub.ErrorCode
+		}
+		for _, path := range taskOutputs(sub) {
+			files[lang+"/"+filepath.Base(path)] = path
+		}
+		if sub.OutputPath != "" {
+			withOutput++
+		}
+	}
+
+	var zipPath string
+	if len(files) > 0 {
+		zipPath = OutputPath(sessionID, parentID, originalName, "languages", ".zip")
+		if err := writeZip(zipPath, files); err != nil {
+			log.Printf("[会话 %s][任务 %s] 打包输出失败: %v", sessionID[:8], parentID, err)
+			zipPath = ""
+		}
+	}
+
+	taskManager.UpdateTask(sessionID, parentID, func(t *models.TranslateTask) {
+		t.CompletedAt = time.Now()
+		t.OutputPath = zipPath
+		switch {
+		case zipPath != "" && finished == len(languages):
+			t.Status = "completed"
+			t.Progress = 1.0
+		case zipPath != "" && withOutput > 0:
+			t.Status = "partial"
+			t.Error = firstErr
+			t.ErrorCode = firstCode
+		default:
+			t.Status = "failed"
+			t.Error = firstErr
+			t.ErrorCode = firstCode
+			if t.Error == "" {
+				t.Error = "打包各语言的输出失败"
+				t.ErrorCode = CodeInternal
+			}
+		}
+	})
+	log.Printf("[会话 %s][任务 %s] %d/%d 种语言翻译完成: %s", sessionID[:8], parentID, finished, len(languages), zipPath)
+}
+
+// taskOutputs 返回任务已生成的输出文件（主输出和各输出格式），按路径排序
+func taskOutputs(task *models.TranslateTask) []string {
+	seen := make(map[string]bool)
+	var paths []string
+	for _, path := range append([]string{task.OutputPath}, mapValues(task.Artifacts)...) {
+		if path == "" || seen[path] {
+			continue
+		}
+		seen[path] = true
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// mapValues 返回 map 的所有值
+func mapValues(m map[string]string) []string {
+	values := make([]string, 0, len(m))
+	for _, v := range m {
+		values = append(values, v)
+	}
+	return values
+}
+
+// writeZip 将文件写入 ZIP：ZIP 中的路径 -> 文件路径
+func writeZip(zipPath string, files map[string]string) (err error) {
+	if err := os.MkdirAll(filepath.Dir(zipPath), 0755); err != nil {
+		return err
+	}
+	out, err := os.Create(zipPath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := out.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(zipPath)
+		}
+	}()
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	zw := zip.NewWriter(out)
+	for _, name := range names {
+		if err := addZipFile(zw, name, files[name]); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// addZipFile 将文件以指定名称写入 ZIP
+func addZipFile(zw *zip.Writer, name, path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(dst, src)
+	return err
+}
+
+// languageSubTask 返回多目标语言任务中指定语言的子任务
+func languageSubTask(sessionID string, task *models.TranslateTask, lang string) (*models.TranslateTask, bool) {
+	id, ok := task.SubTasks[lang]
+	if !ok {
+		return nil, false
+	}
+	return taskManager.GetTask(sessionID, id)
+}
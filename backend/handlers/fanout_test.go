@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestParseTargetLanguages(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []string
+	}{
+		{`["Japanese","French"]`, []string{"Japanese", "French"}},
+		{"ja, fr ,ja,,de", []string{"ja", "fr", "de"}},
+		{" , ", nil},
+	}
+	for _, tt := range tests {
+		got, err := parseTargetLanguages(tt.value)
+		if err != nil {
+			t.Errorf("parseTargetLanguages(%q) 出错: %v", tt.value, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseTargetLanguages(%q) = %v，期望 %v", tt.value, got, tt.want)
+		}
+	}
+
+	if _, err := parseTargetLanguages(`["ja",`); err == nil {
+		t.Error("格式错误的 JSON 应返回错误")
+	}
+}
+
+func TestParseTargetLanguagesLimit(t *testing.T) {
+	defer func(n int) { maxTargetLanguages = n }(maxTargetLanguages)
+	maxTargetLanguages = 2
+
+	if _, err := parseTargetLanguages("ja,fr,ja"); err != nil {
+		t.Errorf("去重后未超过上限不应出错: %v", err)
+	}
+	if _, err := parseTargetLanguages("ja,fr,de"); err == nil {
+		t.Error("超过上限应返回错误")
+	}
+}
+
+func TestTranslateHandlerRejectsTooManyTargetLanguages(t *testing.T) {
+	chdirTemp(t)
+	defer func(n int) { maxTargetLanguages = n }(maxTargetLanguages)
+	maxTargetLanguages = 2
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	part, err := mw.CreateFormFile("file", "paper.pdf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write([]byte("%PDF-1.4\n"))
+	mw.WriteField("targetLanguages", "ja,fr,de")
+	mw.Close()
+
+	r := newTestRouter("session-fanout", func(r *gin.Engine) { r.POST("/translate", TranslateHandler) })
+	req := httptest.NewRequest(http.MethodPost, "/translate", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("状态码 = %d，期望 400: %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), "targetLanguages") {
+		t.Errorf("错误信息应指出 targetLanguages: %s", w.Body.String())
+	}
+	if n := len(taskManager.GetUserTasks("session-fanout")); n != 0 {
+		t.Errorf("不应创建任务，得到 %d 个", n)
+	}
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jung-kurt/gofpdf"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// chdirTemp 切换到临时目录，处理函数写入的 data/ 目录在测试结束后删除
+func chdirTemp(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Chdir(dir)
+	return dir
+}
+
+// writeTestPDF 在 path 生成 pageCount 页的 PDF
+func writeTestPDF(t *testing.T, path string, pageCount int) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetFont("Helvetica", "", 12)
+	for i := 1; i <= pageCount; i++ {
+		pdf.AddPage()
+		pdf.Text(20, 30, fmt.Sprintf("Translated page %d", i))
+	}
+	if err := pdf.OutputFileAndClose(path); err != nil {
+		t.Fatalf("生成测试PDF失败: %v", err)
+	}
+}
+
+// newTestRouter 创建设置了会话ID的路由，register 注册要测试的处理函数
+func newTestRouter(sessionID string, register func(r *gin.Engine)) *gin.Engine {
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("sessionID", sessionID)
+		c.Next()
+	})
+	register(r)
+	return r
+}
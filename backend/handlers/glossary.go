@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"translator-web/middleware"
+	"translator-web/translator"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 术语表范围
+const (
+	glossaryScopeSession = "session" // 仅当前会话可见
+	glossaryScopeGlobal  = "global"  // 所有会话共享
+)
+
+// glossaryStores 已打开的术语表：文件路径 -> *translator.GlossaryStore
+var glossaryStores sync.Map
+
+// openGlossaryStore 打开指定范围的术语表，同一文件只打开一次
+func openGlossaryStore(sessionID, scope string) (*translator.GlossaryStore, error) {
+	path := filepath.Join("data", "glossary.json")
+	if scope != glossaryScopeGlobal {
+		path = filepath.Join("data", "users", sessionID, "glossary.json")
+	}
+
+	if store, ok := glossaryStores.Load(path); ok {
+		return store.(*translator.GlossaryStore), nil
+	}
+	store, err := translator.NewGlossaryStore(path)
+	if err != nil {
+		return nil, err
+	}
+	actual, _ := glossaryStores.LoadOrStore(path, store)
+	return actual.(*translator.GlossaryStore), nil
+}
+
+// glossaryScope 解析范围参数，默认为当前会话
+func glossaryScope(c *gin.Context, scope string) (string, bool) {
+	switch strings.ToLower(scope) {
+	case "", glossaryScopeSession:
+		return glossaryScopeSession, true
+	case glossaryScopeGlobal:
+		return glossaryScopeGlobal, true
+	default:
+		respondError(c, badRequest("不支持的术语表范围: "+scope+"，可选: session、global"))
+		return "", false
+	}
+}
+
+// taskGlossary 合并会话术语表和全局术语表中适用于任务的条目，会话术语表优先
+func taskGlossary(sessionID, targetLanguage, domain string) translator.Glossary {
+	var stores []*translator.GlossaryStore
+	for _, scope := range []string{glossaryScopeSession, glossaryScopeGlobal} {
+		store, err := openGlossaryStore(sessionID, scope)
+		if err != nil {
+			log.Printf("[会话 %s] 读取术语表失败: %v", sessionID[:8], err)
+			continue
+		}
+		stores = append(stores, store)
+	}
+	return translator.NewGlossary(targetLanguage, domain, stores...)
+}
+
+// withGlossary 将术语表合并到提供商配置的 Extra["glossary"]（请求中已有的术语表保留在前）
+func withGlossary(config translator.ProviderConfig, glossary translator.Glossary) translator.ProviderConfig {
+	if len(glossary) == 0 {
+		return config
+	}
+
+	extra := make(map[string]string, len(config.Extra)+1)
+	for k, v := range config.Extra {
+		extra[k] = v
+	}
+	text := glossary.PromptText()
+	if existing := strings.TrimSpace(extra["glossary"]); existing != "" {
+		text = existing + "\n" + text
+	}
+	extra["glossary"] = text
+	config.Extra = extra
+	return config
+}
+
+// GetGlossaryHandler 列出术语表条目，可按 targetLanguage 和 domain 过滤
+func GetGlossaryHandler(c *gin.Context) {
+	sessionID := middleware.GetSessionID(c)
+	if sessionID == "" {
+		respondError(c, errInvalidSession)
+		return
+	}
+	scope, ok := glossaryScope(c, c.Query("scope"))
+	if !ok {
+		return
+	}
+
+	store, err := openGlossaryStore(sessionID, scope)
+	if err != nil {
+		respondError(c, internalError(err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"scope":   scope,
+		"entries": store.List(c.Query("targetLanguage"), c.Query("domain")),
+	})
+}
+
+// AddGlossaryEntryRequest 添加术语请求
+type AddGlossaryEntryRequest struct {
+	Source         string `json:"source"`
+	Target         string `json:"target"`
+	TargetLanguage string `json:"targetLanguage"`
+	Domain         string `json:"domain"`
+	Scope          string `json:"scope"`
+}
+
+// AddGlossaryEntryHandler 添加术语，相同术语和适用范围的已有条目会被替换
+func AddGlossaryEntryHandler(c *gin.Context) {
+	sessionID := middleware.GetSessionID(c)
+	if sessionID == "" {
+		respondError(c, errInvalidSession)
+		return
+	}
+
+	var req AddGlossaryEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, badRequest("请求格式错误: "+err.Error()))
+		return
+	}
+	scope, ok := glossaryScope(c, req.Scope)
+	if !ok {
+		return
+	}
+
+	store, err := openGlossaryStore(sessionID, scope)
+	if err != nil {
+		respondError(c, internalError(err.Error()))
+		return
+	}
+	entry, err := store.Add(translator.GlossaryEntry{
+		Source:         req.Source,
+		Target:         req.Target,
+		TargetLanguage: req.TargetLanguage,
+		Domain:         req.Domain,
+	})
+	if err != nil {
+		respondError(c, badRequest(err.Error()))
+		return
+	}
+	c.JSON(http.StatusCreated, entry)
+}
+
+// DeleteGlossaryEntryHandler 删除术语
+func DeleteGlossaryEntryHandler(c *gin.Context) {
+	sessionID := middleware.GetSessionID(c)
+	if sessionID == "" {
+		respondError(c, errInvalidSession)
+		return
+	}
+	scope, ok := glossaryScope(c, c.Query("scope"))
+	if !ok {
+		return
+	}
+
+	store, err := openGlossaryStore(sessionID, scope)
+	if err != nil {
+		respondError(c, internalError(err.Error()))
+		return
+	}
+	deleted, err := store.Delete(c.Param("id"))
+	if err != nil {
+		respondError(c, internalError(err.Error()))
+		return
+	}
+	if !deleted {
+		respondError(c, notFound("术语不存在"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "已删除"})
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"translator-web/translator"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newGlossaryRouter(sessionID string) *gin.Engine {
+	return newTestRouter(sessionID, func(r *gin.Engine) {
+		r.GET("/glossary", GetGlossaryHandler)
+		r.POST("/glossary", AddGlossaryEntryHandler)
+		r.DELETE("/glossary/:id", DeleteGlossaryEntryHandler)
+	})
+}
+
+func glossaryRequest(r *gin.Engine, method, target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestGlossaryHandlers(t *testing.T) {
+	chdirTemp(t)
+	glossaryStores.Clear()
+	t.Cleanup(glossaryStores.Clear)
+	const sessionID = "session-glossary"
+	r := newGlossaryRouter(sessionID)
+
+	w := glossaryRequest(r, http.MethodPost, "/glossary", `{"source":"transformer","target":"变换器","targetLanguage":"Uni"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("添加术语返回 %d: %s", w.Code, w.Body.String())
+	}
+	var entry translator.GlossaryEntry
+	json.Unmarshal(w.Body.Bytes(), &entry)
+
+	if w := glossaryRequest(r, http.MethodPost, "/glossary", `{"source":"model","target":"模型","scope":"global"}`); w.Code != http.StatusCreated {
+		t.Fatalf("添加全局术语返回 %d: %s", w.Code, w.Body.String())
+	}
+	if w := glossaryRequest(r, http.MethodPost, "/glossary", `{"source":"x","target":"y","scope":"team"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("不支持的范围返回 %d，期望 400", w.Code)
+	}
+	if w := glossaryRequest(r, http.MethodPost, "/glossary", `{"source":"x"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("缺少译法返回 %d，期望 400", w.Code)
+	}
+
+	w = glossaryRequest(r, http.MethodGet, "/glossary?targetLanguage=French", "")
+	if w.Code != http.StatusOK || strings.Contains(w.Body.String(), "transformer") {
+		t.Errorf("按目标语言过滤后不应包含 transformer: %s", w.Body.String())
+	}
+
+	// 其他会话看不到会话术语，但能使用全局术语
+	other := taskGlossary("session-other", "Uni", "")
+	if other.PromptText() != "model => 模型" {
+		t.Errorf("其他会话的术语表 = %q", other.PromptText())
+	}
+
+	// 后续任务的提示词包含合并后的术语表，请求中已有的术语表保留在前
+	glossary := taskGlossary(sessionID, "Uni", "")
+	config := withGlossary(translator.ProviderConfig{Extra: map[string]string{"glossary": "GPU => 图形处理器"}}, glossary)
+	if want := "GPU => 图形处理器\nmodel => 模型\ntransformer => 变换器"; config.Extra["glossary"] != want {
+		t.Errorf("Extra[glossary] = %q，期望 %q", config.Extra["glossary"], want)
+	}
+	if got := glossary.Enforce("The transformer", "这个 transformer"); got != "这个 变换器" {
+		t.Errorf("Enforce = %q", got)
+	}
+
+	if w := glossaryRequest(r, http.MethodDelete, "/glossary/"+entry.ID, ""); w.Code != http.StatusOK {
+		t.Errorf("删除术语返回 %d: %s", w.Code, w.Body.String())
+	}
+	if w := glossaryRequest(r, http.MethodDelete, "/glossary/"+entry.ID, ""); w.Code != http.StatusNotFound {
+		t.Errorf("重复删除返回 %d，期望 404", w.Code)
+	}
+	if len(taskGlossary(sessionID, "Uni", "")) != 1 {
+		t.Error("删除后任务术语表仍包含该术语")
+	}
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"translator-web/middleware"
+	"translator-web/models"
+	"translator-web/translator"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LanguagesHandler 返回提供商支持的目标语言，前端据此生成语言列表
+// 查询参数 provider 为提供商类型（默认 openai），apiUrl 为机器翻译服务的地址；
+// API Key 不能放在查询参数中，使用会话已保存的配置，或通过 QueryLanguagesHandler 在请求体中提交
+func LanguagesHandler(c *gin.Context) {
+	if c.Query("apiKey") != "" {
+		respondError(c, badRequest("apiKey 不能放在查询参数中，请使用 POST /api/languages 在请求体中提交"))
+		return
+	}
+	cfg := models.LLMConfig{
+		Provider: c.DefaultQuery("provider", "openai"),
+		APIURL:   c.Query("apiUrl"),
+	}
+	respondLanguages(c, cfg)
+}
+
+// QueryLanguagesHandler 与 LanguagesHandler 相同，提供商配置（provider、apiUrl、apiKey）以 JSON 请求体提交
+// 用于查询尚未保存的配置对应的机器翻译服务
+func QueryLanguagesHandler(c *gin.Context) {
+	var cfg models.LLMConfig
+	if err := c.ShouldBindJSON(&cfg); err != nil {
+		respondError(c, badRequest("提供商配置格式错误: "+err.Error()))
+		return
+	}
+	if cfg.Provider == "" {
+		cfg.Provider = "openai"
+	}
+	respondLanguages(c, cfg)
+}
+
+// respondLanguages 查询并返回提供商支持的目标语言
+func respondLanguages(c *gin.Context, cfg models.LLMConfig) {
+	withStoredAPIKey(c, &cfg)
+	provider, err := translator.NewProvider(newProviderConfig(cfg, ""), nil)
+	if err != nil {
+		respondError(c, badRequest(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"provider":  cfg.Provider,
+		"languages": translator.ProviderLanguages(provider),
+	})
+}
+
+// withStoredAPIKey 请求没有提供 API Key 时使用已保存的配置中的 API Key
+// 只有提供商和服务地址都与已保存的配置相同时才使用，避免将已保存的 API Key 发送到请求指定的其他地址
+func withStoredAPIKey(c *gin.Context, cfg *models.LLMConfig) {
+	if cfg.APIKey != "" {
+		return
+	}
+	stored, ok, err := loadProviderConfig(middleware.GetSessionID(c))
+	if err != nil {
+		log.Printf("读取已保存的提供商配置失败，不使用 API Key 查询语言: %v", err)
+		return
+	}
+	if !ok || stored.Provider != cfg.Provider {
+		return
+	}
+	if cfg.APIURL == "" {
+		cfg.APIURL = stored.APIURL
+	}
+	if cfg.APIURL == stored.APIURL {
+		cfg.APIKey = stored.APIKey
+	}
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// languageServer 模拟 LibreTranslate 的 /languages 接口，记录收到的 api_key
+type languageServer struct {
+	*httptest.Server
+	mu   sync.Mutex
+	keys []string
+}
+
+func newLanguageServer(t *testing.T) *languageServer {
+	ls := &languageServer{}
+	ls.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ls.mu.Lock()
+		ls.keys = append(ls.keys, r.URL.Query().Get("api_key"))
+		ls.mu.Unlock()
+		w.Write([]byte(`[{"code":"en"},{"code":"fr"}]`))
+	}))
+	t.Cleanup(ls.Close)
+	return ls
+}
+
+// receivedKeys 返回收到的 api_key
+func (ls *languageServer) receivedKeys() string {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	return strings.Join(ls.keys, ",")
+}
+
+func languagesRouter() *gin.Engine {
+	return newTestRouter("languages-session", func(r *gin.Engine) {
+		r.GET("/api/languages", LanguagesHandler)
+		r.POST("/api/languages", QueryLanguagesHandler)
+	})
+}
+
+func TestLanguagesRejectsAPIKeyInQuery(t *testing.T) {
+	w := httptest.NewRecorder()
+	languagesRouter().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/languages?provider=libretranslate&apiKey=secret", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("查询参数带 apiKey 时状态码 = %d，期望 400", w.Code)
+	}
+}
+
+func TestLanguagesPostSendsAPIKeyFromBody(t *testing.T) {
+	server := newLanguageServer(t)
+	body := `{"provider":"libretranslate","apiUrl":"` + server.URL + `/translate","apiKey":"body-key"}`
+	w := httptest.NewRecorder()
+	languagesRouter().ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/languages", strings.NewReader(body)))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("状态码 = %d: %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), `"code":"fr"`) {
+		t.Errorf("应返回服务查询到的语言: %s", w.Body.String())
+	}
+	if got := server.receivedKeys(); got != "body-key" {
+		t.Errorf("语言服务收到的 api_key = %q，期望 body-key", got)
+	}
+}
+
+func TestLanguagesUsesStoredAPIKeyOnlyForStoredURL(t *testing.T) {
+	chdirTemp(t)
+	stored := newLanguageServer(t)
+	other := newLanguageServer(t)
+	t.Setenv("PROVIDER_CONFIG_SECRET", "test-secret")
+	resetProviderConfigSecret(t)
+	saveTestProviderConfig(t, "languages-session", `{"provider":"libretranslate","apiUrl":"`+stored.URL+`/translate","apiKey":"stored-key"}`)
+
+	for _, url := range []string{
+		"/api/languages?provider=libretranslate",
+		"/api/languages?provider=libretranslate&apiUrl=" + other.URL + "/translate",
+	} {
+		w := httptest.NewRecorder()
+		languagesRouter().ServeHTTP(w, httptest.NewRequest(http.MethodGet, url, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: 状态码 = %d: %s", url, w.Code, w.Body.String())
+		}
+	}
+
+	if got := stored.receivedKeys(); got != "stored-key" {
+		t.Errorf("已保存的服务收到的 api_key = %q，期望 stored-key", got)
+	}
+	if got := other.receivedKeys(); got != "" {
+		t.Errorf("其他地址不应收到已保存的 API Key，收到 %q", got)
+	}
+}
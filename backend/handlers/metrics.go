@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// 任务相关的 Prometheus 指标；翻译、缓存和提供商请求的指标在 translator 包中
+var (
+	taskDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "task_duration_seconds",
+		Help:    "Document translation task run time (excluding queue wait) by final status.",
+		Buckets: []float64{1, 5, 15, 30, 60, 120, 300, 600, 1800, 3600},
+	}, []string{"status"})
+
+	_ = promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "task_queue_depth",
+		Help: "Tasks waiting in the queue.",
+	}, func() float64 { return float64(taskQueue.Depth()) })
+
+	_ = promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "tasks_running",
+		Help: "Tasks currently being processed.",
+	}, func() float64 { return float64(taskQueue.Running()) })
+)
+
+// MetricsHandler 以 Prometheus 文本格式输出指标（含 Go 运行时指标，如 go_goroutines）
+func MetricsHandler() gin.HandlerFunc {
+	return gin.WrapH(promhttp.Handler())
+}
+
+// observeTaskDuration 记录任务结束时的状态和执行时长
+func observeTaskDuration(sessionID, taskID string, start time.Time) {
+	status := "unknown"
+	if task, ok := taskManager.TaskSnapshot(sessionID, taskID); ok {
+		status = task.Status
+	}
+	taskDuration.WithLabelValues(status).Observe(time.Since(start).Seconds())
+}
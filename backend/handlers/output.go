@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// unsafeNameChars 文件名中需要替换的字符
+var unsafeNameChars = regexp.MustCompile(`[^\p{L}\p{N}._-]+`)
+
+// OutputPath 生成输出文件路径
+// 输出文件按会话隔离，文件名附带由会话、任务、原文件名和后缀计算的短哈希，
+// 同一会话多次上传同名文件时各任务的输出互不覆盖
+// 哈希有意使用任务 ID 而不是文件内容：同一文件翻译成不同语言或强制重新翻译时内容相同，
+// 按内容命名会让这些任务写入同一个文件；相同提交的复用由 FindExistingTask 在任务层面完成
+func OutputPath(sessionID, taskID, originalName, suffix, ext string) string {
+	baseName := strings.TrimSuffix(filepath.Base(originalName), filepath.Ext(originalName))
+	baseName = unsafeNameChars.ReplaceAllString(baseName, "_")
+	if baseName == "" || baseName == "." {
+		baseName = "document"
+	}
+
+	hash := sha256.Sum256([]byte(sessionID + "|" + taskID + "|" + originalName + "|" + suffix))
+	shortHash := hex.EncodeToString(hash[:])[:8]
+
+	name := baseName
+	if suffix != "" {
+		name += "_" + suffix
+	}
+	name += "_" + shortHash + ext
+
+	return filepath.Join("data", "users", sessionID, "outputs", name)
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOutputPathSeparatesTasks(t *testing.T) {
+	first := OutputPath("session", "task-1", "report.pdf", "mono", ".pdf")
+	second := OutputPath("session", "task-2", "report.pdf", "mono", ".pdf")
+	if first == second {
+		t.Fatalf("同一会话两次上传同名文件的输出路径相同: %s", first)
+	}
+	if again := OutputPath("session", "task-1", "report.pdf", "mono", ".pdf"); again != first {
+		t.Errorf("同一任务的输出路径应保持不变: %s != %s", again, first)
+	}
+
+	if dir := filepath.Dir(first); dir != filepath.Join("data", "users", "session", "outputs") {
+		t.Errorf("输出目录 = %s", dir)
+	}
+	if name := filepath.Base(first); !strings.HasPrefix(name, "report_mono_") || !strings.HasSuffix(name, ".pdf") {
+		t.Errorf("输出文件名 = %s", name)
+	}
+}
+
+func TestOutputPathSanitizesName(t *testing.T) {
+	name := filepath.Base(OutputPath("session", "task", "../a b/我的 论文?.epub", "", ".epub"))
+	if !strings.HasPrefix(name, "我的_论文_") || strings.ContainsAny(name, " ?/") {
+		t.Errorf("输出文件名 = %s", name)
+	}
+	if name := filepath.Base(OutputPath("session", "task", ".pdf", "", ".pdf")); !strings.HasPrefix(name, "document_") {
+		t.Errorf("空文件名应使用 document，得到 %s", name)
+	}
+}
+
+func TestOutputPathSeparatesSessions(t *testing.T) {
+	first := OutputPath("session-a", "task", "paper.pdf", "mono", ".pdf")
+	second := OutputPath("session-b", "task", "paper.pdf", "mono", ".pdf")
+	if first == second || filepath.Base(first) == filepath.Base(second) {
+		t.Errorf("两个会话翻译 paper.pdf 的输出路径冲突: %s, %s", first, second)
+	}
+}
+
+// TestTranslateSameContentDoesNotCollide 内容相同的 paper.pdf 在不同会话和同一会话中强制重新翻译，输出互不覆盖
+func TestTranslateSameContentDoesNotCollide(t *testing.T) {
+	dir := chdirTemp(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"choices":[{"message":{"content":"已翻译的页面"}}]}`))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(dir, "paper.pdf")
+	writeTestPDF(t, path, 1)
+	pdf, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := map[string]string{
+		"targetLanguage":   "Uni",
+		"llmConfig":        `{"provider":"openai","apiUrl":"` + server.URL + `","apiKey":"key","model":"gpt-test"}`,
+		"forceRetranslate": "true",
+	}
+
+	outputs := make(map[string]string)
+	for _, sessionID := range []string{"session-output-a", "session-output-b", "session-output-a"} {
+		w := postTranslate(t, sessionID, pdf, fields)
+		if w.Code != http.StatusOK {
+			t.Fatalf("状态码 = %d: %s", w.Code, w.Body.String())
+		}
+		var resp map[string]any
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		taskID, _ := resp["taskId"].(string)
+		if status := waitForTask(t, sessionID, taskID); status != "completed" {
+			t.Fatalf("任务状态 = %s", status)
+		}
+
+		task, _ := taskManager.TaskSnapshot(sessionID, taskID)
+		if other, exists := outputs[task.OutputPath]; exists {
+			t.Errorf("任务 %s 与 %s 的输出路径相同: %s", taskID, other, task.OutputPath)
+		}
+		outputs[task.OutputPath] = taskID
+		if dir := filepath.Join("data", "users", sessionID, "outputs"); filepath.Dir(task.OutputPath) != dir {
+			t.Errorf("输出路径 %s 不在会话目录 %s 中", task.OutputPath, dir)
+		}
+	}
+	for path := range outputs {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("输出文件不存在: %v", err)
+		}
+	}
+}
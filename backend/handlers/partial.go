@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"translator-web/models"
+	"translator-web/translator"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// partialSource 正在翻译的PDF任务，用于按需生成已完成页面的部分译文
+type partialSource struct {
+	sourcePath string
+	progress   *translator.PageProgress
+}
+
+// partialSources taskID -> *partialSource，任务结束后移除
+var partialSources sync.Map
+
+// trackPageProgress 为PDF任务创建逐页进度跟踪，并同步到任务状态
+// 返回的函数用于在任务结束时注销
+func trackPageProgress(sessionID, taskID, sourcePath string) (*translator.PageProgress, func()) {
+	progress := translator.NewPageProgress()
+	progress.OnChange = func(completed, total int) {
+		taskManager.UpdateTask(sessionID, taskID, func(t *models.TranslateTask) {
+			t.TranslatedPages = completed
+			t.TotalPages = total
+		})
+	}
+
+	partialSources.Store(taskID, &partialSource{sourcePath: sourcePath, progress: progress})
+	return progress, func() { partialSources.Delete(taskID) }
+}
+
+// servePartialDownload 下载前 upToPage 页的译文PDF
+// 任务进行中时按已翻译完成的页面即时生成，任务完成后从最终输出中截取；
+// 生成的文件每次请求单独命名，响应结束后删除
+func servePartialDownload(c *gin.Context, task *models.TranslateTask, upToPageStr string) {
+	upToPage, err := strconv.Atoi(upToPageStr)
+	if err != nil || upToPage < 1 {
+		respondError(c, badRequest("upToPage 必须是正整数"))
+		return
+	}
+	if strings.ToLower(filepath.Ext(task.SourceFile)) != ".pdf" {
+		respondError(c, badRequest("只有 PDF 任务支持部分下载"))
+		return
+	}
+
+	partialPath := OutputPath(task.SessionID, task.ID, task.SourceFile, "partial-"+upToPageStr+"-"+uuid.New().String()[:8], ".pdf")
+	defer os.Remove(partialPath)
+	var pages int
+
+	switch task.Status {
+	case "completed":
+		total, err := translator.GetPDFPageCount(task.OutputPath)
+		if err != nil {
+			respondError(c, notFound("翻译文件不存在"))
+			return
+		}
+		pages = upToPage
+		if pages > total {
+			pages = total
+		}
+		if err := translator.TrimPDFPages(task.OutputPath, partialPath, pages); err != nil {
+			respondError(c, internalError(err.Error()))
+			return
+		}
+	case "failed", "partial", "timed_out":
+		respondError(c, badRequest("任务已失败"))
+		return
+	default:
+		value, ok := partialSources.Load(task.ID)
+		if !ok {
+			respondError(c, newAPIError(http.StatusTooEarly, CodeTaskNotReady, translator.ErrNoPagesReady.Error()))
+			return
+		}
+		source := value.(*partialSource)
+		pages, err = source.progress.SavePartialPDF(source.sourcePath, partialPath, upToPage)
+		if errors.Is(err, translator.ErrNoPagesReady) {
+			respondError(c, newAPIError(http.StatusTooEarly, CodeTaskNotReady, err.Error()))
+			return
+		}
+		if err != nil {
+			respondError(c, internalError(err.Error()))
+			return
+		}
+	}
+
+	baseName := strings.TrimSuffix(task.SourceFile, filepath.Ext(task.SourceFile))
+	c.Header("X-Translated-Pages", strconv.Itoa(pages))
+	c.FileAttachment(partialPath, fmt.Sprintf("translated_%s-p1-%d.pdf", baseName, pages))
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"translator-web/models"
+	"translator-web/translator"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestFailedTaskServesPartialResult(t *testing.T) {
+	dir := chdirTemp(t)
+	const sessionID = "session-partial-result"
+	const taskID = "task-partial-result-1"
+	source := filepath.Join(dir, "paper.pdf")
+	writeTestPDF(t, source, 3)
+	taskManager.AddTask(sessionID, &models.TranslateTask{ID: taskID, SessionID: sessionID, SourceFile: "paper.pdf", Status: "processing"})
+
+	// 翻译服务在最后一个文本块失败前，已完成前两个文本块
+	blocks, _, err := translator.ExtractTranslatableBlocks(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blocks) != 3 {
+		t.Fatalf("提取到 %d 个文本块，期望 3: %q", len(blocks), blocks)
+	}
+	translations := map[string]string{blocks[0]: "第一页译文", blocks[1]: "第二页译文"}
+	failWithPartialResult(sessionID, taskID, "paper.pdf", source, translations, "翻译服务不可用", CodeProviderError)
+
+	task, _ := taskManager.TaskSnapshot(sessionID, taskID)
+	if task.Status != "partial" || task.Error == "" {
+		t.Fatalf("任务状态 = %s，错误 = %q，期望 partial", task.Status, task.Error)
+	}
+
+	r := newTestRouter(sessionID, func(r *gin.Engine) { r.GET("/download/:taskId", DownloadHandler) })
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/download/"+taskID, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("状态码 = %d: %s", w.Code, w.Body.String())
+	}
+	if w.Header().Get("X-Translation-Partial") != "true" {
+		t.Error("部分结果缺少 X-Translation-Partial 响应头")
+	}
+	body := w.Body.String()
+	for _, want := range []string{"第一页译文", "第二页译文", blocks[2]} {
+		if !strings.Contains(body, want) {
+			t.Errorf("部分结果缺少 %q:\n%s", want, body)
+		}
+	}
+}
+
+func TestFailedTaskWithoutTranslations(t *testing.T) {
+	dir := chdirTemp(t)
+	const sessionID = "session-partial-none"
+	source := filepath.Join(dir, "paper.pdf")
+	writeTestPDF(t, source, 1)
+	taskManager.AddTask(sessionID, &models.TranslateTask{ID: "task-partial-none", SessionID: sessionID, Status: "processing"})
+
+	failWithPartialResult(sessionID, "task-partial-none", "paper.pdf", source, map[string]string{}, "翻译服务不可用", CodeProviderError)
+	if task, _ := taskManager.TaskSnapshot(sessionID, "task-partial-none"); task.Status != "failed" || task.OutputPath != "" {
+		t.Errorf("没有译文时任务状态 = %s，输出 = %q，期望 failed 且无输出", task.Status, task.OutputPath)
+	}
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"translator-web/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestServePartialDownloadRemovesGeneratedFile(t *testing.T) {
+	chdirTemp(t)
+	outputPath := OutputPath("session", "task-id-1234", "paper.pdf", "mono", ".pdf")
+	writeTestPDF(t, outputPath, 3)
+	task := &models.TranslateTask{
+		ID:         "task-id-1234",
+		SessionID:  "session",
+		SourceFile: "paper.pdf",
+		Status:     "completed",
+		OutputPath: outputPath,
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/download?upToPage=2", nil)
+	servePartialDownload(c, task, "2")
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("状态码 = %d: %s", w.Code, w.Body.String())
+	}
+	if got := w.Header().Get("X-Translated-Pages"); got != "2" {
+		t.Errorf("X-Translated-Pages = %q，期望 2", got)
+	}
+	if w.Body.Len() == 0 || string(w.Body.Bytes()[:5]) != "%PDF-" {
+		t.Error("响应不是PDF")
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(outputPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != filepath.Base(outputPath) {
+		var names []string
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		t.Errorf("响应后输出目录应只剩最终输出，得到 %v", names)
+	}
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+	"translator-web/translator"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestProviderHandler 测试提供商配置的连通性
+func TestProviderHandler(c *gin.Context) {
+	var config translator.ProviderConfig
+	if err := c.ShouldBindJSON(&config); err != nil {
+		respondError(c, badRequest("提供商配置格式错误: "+err.Error()))
+		return
+	}
+
+	if config.Type == "" {
+		respondError(c, badRequest("提供商类型不能为空"))
+		return
+	}
+	if config.APIURL == "" {
+		respondError(c, badRequest("API URL 不能为空"))
+		return
+	}
+
+	provider, err := translator.NewProvider(config, nil)
+	if err != nil {
+		respondError(c, badRequest(err.Error()))
+		return
+	}
+
+	start := time.Now()
+	err = provider.HealthCheck()
+	latency := time.Since(start)
+
+	if err != nil {
+		code := CodeProviderError
+		if _, known, ok := errorCode(err); ok {
+			code = known
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"success":   false,
+			"code":      code,
+			"error":     err.Error(),
+			"latencyMs": latency.Milliseconds(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":   true,
+		"provider":  provider.GetName(),
+		"latencyMs": latency.Milliseconds(),
+	})
+}
@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"translator-web/middleware"
+	"translator-web/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	providerConfigSecretOnce sync.Once
+	providerConfigKey        []byte
+)
+
+func init() {
+	// 已保存的提供商配置随会话结束删除
+	middleware.OnSessionEnd(removeProviderConfig)
+}
+
+// providerConfigSecret 返回加密已保存 API Key 的 AES-256 密钥（由服务端密钥经 SHA-256 派生）
+// 优先使用环境变量 PROVIDER_CONFIG_SECRET；未设置时随机生成。会话只保存在内存中，
+// 服务重启后已保存的配置所属的会话都已失效，随机密钥不需要保存
+func providerConfigSecret() []byte {
+	providerConfigSecretOnce.Do(func() {
+		secret := []byte(os.Getenv("PROVIDER_CONFIG_SECRET"))
+		if len(secret) == 0 {
+			secret = make([]byte, 32)
+			if _, err := rand.Read(secret); err != nil {
+				panic(fmt.Sprintf("生成提供商配置密钥失败: %v", err))
+			}
+			log.Printf("未设置 PROVIDER_CONFIG_SECRET，使用随机密钥加密已保存的 API Key")
+		}
+		sum := sha256.Sum256(secret)
+		providerConfigKey = sum[:]
+	})
+	return providerConfigKey
+}
+
+// encryptAPIKey 使用 AES-GCM 加密 API Key，返回 base64 编码的 "nonce + 密文"
+func encryptAPIKey(apiKey string) (string, error) {
+	gcm, err := providerConfigCipher()
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(apiKey), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// decryptAPIKey 解密 encryptAPIKey 的结果，密钥不匹配或数据被篡改时返回错误
+func decryptAPIKey(encrypted string) (string, error) {
+	gcm, err := providerConfigCipher()
+	if err != nil {
+		return "", err
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return "", errors.New("密文长度无效")
+	}
+	plain, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", err
+	}
+	return string(plain), nil
+}
+
+// providerConfigCipher 创建 AES-GCM 加密器
+func providerConfigCipher() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(providerConfigSecret())
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// storedProviderConfig 保存在磁盘上的提供商配置，API Key 单独加密存放
+type storedProviderConfig struct {
+	Config          models.LLMConfig `json:"config"` // APIKey 为空
+	EncryptedAPIKey string           `json:"encryptedApiKey,omitempty"`
+}
+
+// providerConfigPath 会话的提供商配置文件路径
+func providerConfigPath(sessionID string) string {
+	return filepath.Join("data", "users", sessionID, "provider-config.json")
+}
+
+// removeProviderConfig 删除会话已保存的提供商配置
+func removeProviderConfig(sessionID string) {
+	if err := os.Remove(providerConfigPath(sessionID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Printf("删除会话的提供商配置失败: %v", err)
+	}
+}
+
+// saveProviderConfig 加密 API Key 后保存会话的提供商配置，已有配置时覆盖
+func saveProviderConfig(sessionID string, cfg models.LLMConfig) error {
+	stored := storedProviderConfig{Config: cfg}
+	if cfg.APIKey != "" {
+		encrypted, err := encryptAPIKey(cfg.APIKey)
+		if err != nil {
+			return fmt.Errorf("加密 API Key 失败: %w", err)
+		}
+		stored.EncryptedAPIKey = encrypted
+	}
+	stored.Config.APIKey = ""
+
+	data, err := json.MarshalIndent(stored, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := providerConfigPath(sessionID)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("保存提供商配置失败: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("保存提供商配置失败: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("保存提供商配置失败: %w", err)
+	}
+	return nil
+}
+
+// loadProviderConfig 读取会话已保存的提供商配置并解密 API Key，没有保存时 ok 为 false
+func loadProviderConfig(sessionID string) (cfg models.LLMConfig, ok bool, err error) {
+	data, err := os.ReadFile(providerConfigPath(sessionID))
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, false, nil
+	}
+	if err != nil {
+		return cfg, false, fmt.Errorf("读取提供商配置失败: %w", err)
+	}
+
+	var stored storedProviderConfig
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return cfg, false, fmt.Errorf("提供商配置文件格式错误: %w", err)
+	}
+	cfg = stored.Config
+	if stored.EncryptedAPIKey != "" {
+		if cfg.APIKey, err = decryptAPIKey(stored.EncryptedAPIKey); err != nil {
+			return cfg, false, fmt.Errorf("无法解密已保存的 API Key（服务端密钥可能已更换），请重新保存提供商配置")
+		}
+	}
+	return cfg, true, nil
+}
+
+// maskAPIKey 隐藏 API Key，只保留前 3 位和后 4 位
+func maskAPIKey(apiKey string) string {
+	if apiKey == "" {
+		return ""
+	}
+	if len(apiKey) <= 8 {
+		return "****"
+	}
+	return apiKey[:3] + "****" + apiKey[len(apiKey)-4:]
+}
+
+// storedLLMConfig 请求没有提供 LLM 配置时使用已保存的配置
+// 没有保存配置时返回 true 并保持 cfg 不变，由之后的校验报告缺少的字段
+func storedLLMConfig(c *gin.Context, cfg *models.LLMConfig) bool {
+	if cfg.Provider != "" || cfg.APIURL != "" || cfg.APIKey != "" || cfg.Model != "" {
+		return true
+	}
+	stored, ok, err := loadProviderConfig(middleware.GetSessionID(c))
+	if err != nil {
+		respondError(c, badRequest(err.Error()))
+		return false
+	}
+	if ok {
+		*cfg = stored
+	}
+	return true
+}
+
+// SaveProviderConfigHandler 保存当前会话的提供商配置，API Key 加密后存放在服务端
+// 之后的翻译请求省略 llmConfig 时使用该配置，会话过期时删除
+func SaveProviderConfigHandler(c *gin.Context) {
+	sessionID := middleware.GetSessionID(c)
+	if sessionID == "" {
+		respondError(c, errInvalidSession)
+		return
+	}
+
+	var cfg models.LLMConfig
+	if err := c.ShouldBindJSON(&cfg); err != nil {
+		respondError(c, badRequest("提供商配置格式错误: "+err.Error()))
+		return
+	}
+	if err := normalizeLLMConfig(&cfg); err != nil {
+		respondError(c, badRequest(err.Error()))
+		return
+	}
+	if err := saveProviderConfig(sessionID, cfg); err != nil {
+		respondError(c, internalError(err.Error()))
+		return
+	}
+	log.Printf("[会话 %s] 保存提供商配置，提供商: %s, 模型: %s", sessionID[:8], cfg.Provider, cfg.Model)
+
+	cfg.APIKey = maskAPIKey(cfg.APIKey)
+	c.JSON(http.StatusOK, cfg)
+}
+
+// GetProviderConfigHandler 返回当前会话已保存的提供商配置，API Key 只显示首尾几位
+func GetProviderConfigHandler(c *gin.Context) {
+	sessionID := middleware.GetSessionID(c)
+	if sessionID == "" {
+		respondError(c, errInvalidSession)
+		return
+	}
+
+	cfg, ok, err := loadProviderConfig(sessionID)
+	if err != nil {
+		respondError(c, internalError(err.Error()))
+		return
+	}
+	if !ok {
+		respondError(c, notFound("没有保存的提供商配置"))
+		return
+	}
+	cfg.APIKey = maskAPIKey(cfg.APIKey)
+	c.JSON(http.StatusOK, cfg)
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// postTestProvider 向 /test-provider 提交配置，返回响应内容
+func postTestProvider(t *testing.T, body string) (int, map[string]any) {
+	t.Helper()
+	r := newTestRouter("session-provider", func(r *gin.Engine) { r.POST("/test-provider", TestProviderHandler) })
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/test-provider", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	var resp map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("响应不是 JSON: %s", w.Body.String())
+	}
+	return w.Code, resp
+}
+
+func TestTestProviderHandler(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"choices":[{"message":{"content":"你好"}}]}`))
+	}))
+	defer server.Close()
+
+	code, resp := postTestProvider(t, `{"type":"openai","apiUrl":"`+server.URL+`","apiKey":"key"}`)
+	if code != http.StatusOK || resp["success"] != true {
+		t.Fatalf("连接测试失败: %d %v", code, resp)
+	}
+	if _, ok := resp["latencyMs"].(float64); !ok {
+		t.Errorf("响应缺少 latencyMs: %v", resp)
+	}
+}
+
+func TestTestProviderHandlerReportsError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	code, resp := postTestProvider(t, `{"type":"openai","apiUrl":"`+server.URL+`","apiKey":"bad"}`)
+	if code != http.StatusOK || resp["success"] != false {
+		t.Fatalf("应报告连接失败: %d %v", code, resp)
+	}
+	if msg, _ := resp["error"].(string); msg == "" {
+		t.Errorf("响应缺少错误说明: %v", resp)
+	}
+
+	if code, _ := postTestProvider(t, `{"type":"openai"}`); code != http.StatusBadRequest {
+		t.Errorf("缺少 API URL 时状态码 = %d，期望 400", code)
+	}
+}
@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"translator-web/models"
+)
+
+// defaultMaxConcurrentTasks 默认同时执行的翻译任务数
+const defaultMaxConcurrentTasks = 4
+
+// queuedTask 排队中的任务
+type queuedTask struct {
+	sessionID string
+	taskID    string
+	run       func()
+}
+
+// TaskQueue 全局翻译任务队列
+// 同时执行的任务数不超过上限，超出的任务排队；出队时在会话之间轮转，避免单个用户占满队列
+type TaskQueue struct {
+	mu            sync.Mutex
+	maxConcurrent int
+	running       int
+	sessions      []string                 // 有排队任务的会话，按轮转顺序排列
+	pending       map[string][]*queuedTask // sessionID -> 该会话的排队任务（先进先出）
+
+	// OnPosition 任务排队位置变化或开始执行时回调，position 为0表示已开始执行
+	// 排队位置的回调在持有队列锁时调用，同一任务的回调按发生顺序到达；回调中不能再调用队列的方法
+	OnPosition func(sessionID, taskID string, position int)
+}
+
+var taskQueue *TaskQueue
+
+func init() {
+	taskQueue = NewTaskQueue(maxConcurrentTasksFromEnv())
+	taskQueue.OnPosition = func(sessionID, taskID string, position int) {
+		taskManager.UpdateTask(sessionID, taskID, func(t *models.TranslateTask) {
+			// 只更新尚未开始的任务，已开始、已完成或已取消的任务不会被改回排队状态
+			if t.Status != "pending" && t.Status != "queued" {
+				return
+			}
+			t.QueuePosition = position
+			if position > 0 {
+				t.Status = "queued"
+			} else {
+				t.Status = "pending"
+			}
+		})
+	}
+}
+
+// maxConcurrentTasksFromEnv 从 MAX_CONCURRENT_TASKS 读取并发上限
+func maxConcurrentTasksFromEnv() int {
+	value := os.Getenv("MAX_CONCURRENT_TASKS")
+	if value == "" {
+		return defaultMaxConcurrentTasks
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		log.Printf("⚠️  MAX_CONCURRENT_TASKS 无效 %q，使用默认值 %d", value, defaultMaxConcurrentTasks)
+		return defaultMaxConcurrentTasks
+	}
+	return n
+}
+
+// NewTaskQueue 创建任务队列，maxConcurrent 小于1时按1处理
+func NewTaskQueue(maxConcurrent int) *TaskQueue {
+	if maxConcurrent < 1 {
+		maxConcurrent = 1
+	}
+	return &TaskQueue{
+		maxConcurrent: maxConcurrent,
+		pending:       make(map[string][]*queuedTask),
+	}
+}
+
+// Submit 提交任务，未达到并发上限时立即执行，否则排队
+// 返回排队位置，0表示已开始执行
+func (q *TaskQueue) Submit(sessionID, taskID string, run func()) int {
+	q.mu.Lock()
+	if q.running < q.maxConcurrent {
+		q.running++
+		q.mu.Unlock()
+		go q.execute(&queuedTask{sessionID: sessionID, taskID: taskID, run: run})
+		return 0
+	}
+
+	if len(q.pending[sessionID]) == 0 {
+		q.sessions = append(q.sessions, sessionID)
+	}
+	q.pending[sessionID] = append(q.pending[sessionID], &queuedTask{sessionID: sessionID, taskID: taskID, run: run})
+	order := q.orderLocked()
+	q.notifyLocked(order)
+	q.mu.Unlock()
+
+	for i, task := range order {
+		if task.taskID == taskID {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// Depth 返回排队中的任务数
+func (q *TaskQueue) Depth() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	depth := 0
+	for _, tasks := range q.pending {
+		depth += len(tasks)
+	}
+	return depth
+}
+
+// Running 返回正在执行的任务数
+func (q *TaskQueue) Running() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.running
+}
+
+// execute 执行任务，结束后调度下一个排队任务
+func (q *TaskQueue) execute(task *queuedTask) {
+	defer q.finish()
+	if q.OnPosition != nil {
+		q.OnPosition(task.sessionID, task.taskID, 0)
+	}
+	task.run()
+}
+
+// finish 释放执行名额，按会话轮转取出下一个任务
+func (q *TaskQueue) finish() {
+	q.mu.Lock()
+	if len(q.sessions) == 0 {
+		q.running--
+		q.mu.Unlock()
+		return
+	}
+
+	sessionID := q.sessions[0]
+	next := q.pending[sessionID][0]
+	q.pending[sessionID] = q.pending[sessionID][1:]
+	q.sessions = q.sessions[1:]
+	if len(q.pending[sessionID]) > 0 {
+		// 该会话还有任务，移到轮转末尾
+		q.sessions = append(q.sessions, sessionID)
+	} else {
+		delete(q.pending, sessionID)
+	}
+	q.notifyLocked(q.orderLocked())
+	q.mu.Unlock()
+
+	go q.execute(next)
+}
+
+// orderLocked 按轮转规则计算排队任务的执行顺序（调用方需持有锁）
+func (q *TaskQueue) orderLocked() []*queuedTask {
+	var order []*queuedTask
+	for round := 0; ; round++ {
+		added := false
+		for _, sessionID := range q.sessions {
+			if tasks := q.pending[sessionID]; round < len(tasks) {
+				order = append(order, tasks[round])
+				added = true
+			}
+		}
+		if !added {
+			return order
+		}
+	}
+}
+
+// notifyLocked 回调所有排队任务的最新位置（调用方需持有锁）
+// 在锁内回调，保证已出队任务的开始执行回调不会被之前计算的排队位置覆盖
+func (q *TaskQueue) notifyLocked(order []*queuedTask) {
+	if q.OnPosition == nil {
+		return
+	}
+	for i, task := range order {
+		q.OnPosition(task.sessionID, task.taskID, i+1)
+	}
+}
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+	"translator-web/models"
+)
+
+// recordingQueue 记录执行顺序和每个任务最后一次回调位置的队列
+type recordingQueue struct {
+	*TaskQueue
+	mu       sync.Mutex
+	order    []string
+	position map[string]int
+}
+
+// yield 为 true 时，排队位置的回调先让出处理器再记录，放大回调之间的竞争窗口
+func newRecordingQueue(maxConcurrent int, yield bool) *recordingQueue {
+	rq := &recordingQueue{TaskQueue: NewTaskQueue(maxConcurrent), position: make(map[string]int)}
+	rq.OnPosition = func(sessionID, taskID string, position int) {
+		if yield && position > 0 {
+			runtime.Gosched()
+		}
+		rq.mu.Lock()
+		rq.position[taskID] = position
+		rq.mu.Unlock()
+	}
+	return rq
+}
+
+// submit 提交任务，任务执行时记录任务ID，并等待 release 关闭
+func (rq *recordingQueue) submit(wg *sync.WaitGroup, sessionID, taskID string, release <-chan struct{}) int {
+	wg.Add(1)
+	return rq.Submit(sessionID, taskID, func() {
+		defer wg.Done()
+		rq.mu.Lock()
+		rq.order = append(rq.order, taskID)
+		rq.mu.Unlock()
+		<-release
+	})
+}
+
+func TestTaskQueueRotatesSessions(t *testing.T) {
+	rq := newRecordingQueue(1, false)
+	var wg sync.WaitGroup
+	block := make(chan struct{})
+	done := make(chan struct{})
+	close(done)
+
+	if pos := rq.submit(&wg, "a", "a1", block); pos != 0 {
+		t.Fatalf("第一个任务应立即执行，排队位置 %d", pos)
+	}
+	positions := []int{
+		rq.submit(&wg, "a", "a2", done),
+		rq.submit(&wg, "a", "a3", done),
+		rq.submit(&wg, "a", "a4", done),
+		rq.submit(&wg, "b", "b1", done),
+		rq.submit(&wg, "b", "b2", done),
+	}
+	// 会话 b 的任务插到会话 a 的排队任务之间
+	if want := []int{1, 2, 3, 2, 4}; fmt.Sprint(positions) != fmt.Sprint(want) {
+		t.Errorf("排队位置 = %v，期望 %v", positions, want)
+	}
+	if depth := rq.Depth(); depth != 5 {
+		t.Errorf("Depth = %d，期望 5", depth)
+	}
+
+	close(block)
+	wg.Wait()
+
+	want := "[a1 a2 b1 a3 b2 a4]"
+	if got := fmt.Sprint(rq.order); got != want {
+		t.Errorf("执行顺序 = %s，期望 %s", got, want)
+	}
+}
+
+// TestTaskQueueStartNotifiesLast 任务开始执行的回调（位置0）必须是该任务收到的最后一个回调
+func TestTaskQueueStartNotifiesLast(t *testing.T) {
+	for round := 0; round < 20; round++ {
+		rq := newRecordingQueue(2, true)
+		var wg sync.WaitGroup
+		done := make(chan struct{})
+		close(done)
+
+		var submitters sync.WaitGroup
+		for s := 0; s < 4; s++ {
+			submitters.Add(1)
+			go func(s int) {
+				defer submitters.Done()
+				for i := 0; i < 10; i++ {
+					rq.submit(&wg, fmt.Sprintf("s%d", s), fmt.Sprintf("s%d-%d", s, i), done)
+				}
+			}(s)
+		}
+		submitters.Wait()
+		wg.Wait()
+
+		// 最后一个任务结束后 execute 的回调可能仍在进行，等待队列清空
+		deadline := time.Now().Add(time.Second)
+		for rq.Running() > 0 && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		rq.mu.Lock()
+		for taskID, position := range rq.position {
+			if position != 0 {
+				t.Errorf("第 %d 轮：任务 %s 已执行，但最后收到的排队位置为 %d", round, taskID, position)
+			}
+		}
+		rq.mu.Unlock()
+	}
+}
+
+func TestQueuePositionKeepsStartedTasks(t *testing.T) {
+	for _, tt := range []struct{ status, want string }{
+		{"pending", "queued"},
+		{"queued", "queued"},
+		{"processing", "processing"},
+		{"completed", "completed"},
+	} {
+		taskID := "queue-position-" + tt.status
+		taskManager.AddTask("queue-test", &models.TranslateTask{ID: taskID, SessionID: "queue-test", Status: tt.status})
+		taskQueue.OnPosition("queue-test", taskID, 2)
+		task, _ := taskManager.TaskSnapshot("queue-test", taskID)
+		if task.Status != tt.want {
+			t.Errorf("状态为 %s 的任务收到排队位置后变为 %s，期望 %s", tt.status, task.Status, tt.want)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestTranslateHandlerHTMLFallback(t *testing.T) {
+	dir := chdirTemp(t)
+	const sessionID = "session-regen-fallback"
+
+	// 翻译服务在放行前阻塞，以便在生成输出前占用双语 PDF 的输出路径
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		var req struct {
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		text := req.Messages[len(req.Messages)-1].Content
+		json.NewEncoder(w).Encode(map[string]any{
+			"choices": []map[string]any{{"message": map[string]string{"content": "译文：" + text}}},
+		})
+	}))
+	defer server.Close()
+	released := false
+	defer func() {
+		if !released {
+			close(release)
+		}
+	}()
+
+	path := filepath.Join(dir, "paper.pdf")
+	writeTestPDF(t, path, 2)
+	pdf, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := postTranslate(t, sessionID, pdf, map[string]string{
+		"targetLanguage":        "Uni",
+		"onRegenerationFailure": "html-fallback",
+		"llmConfig":             `{"provider":"openai","apiUrl":"` + server.URL + `","apiKey":"key","model":"gpt-test"}`,
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("状态码 = %d: %s", w.Code, w.Body.String())
+	}
+	var resp map[string]any
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	taskID, _ := resp["taskId"].(string)
+
+	// 输出路径上的目录使双语 PDF 无法写入
+	dualPDF := strings.TrimSuffix(OutputPath(sessionID, taskID, "paper.pdf", "bilingual", ".pdf"), ".pdf") + "-dual.pdf"
+	if err := os.MkdirAll(filepath.Join(dualPDF, "blocked"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	close(release)
+	released = true
+
+	if status := waitForTask(t, sessionID, taskID); status != "completed" {
+		task, _ := taskManager.TaskSnapshot(sessionID, taskID)
+		t.Fatalf("任务状态 = %s: %s", status, task.Error)
+	}
+	task, _ := taskManager.TaskSnapshot(sessionID, taskID)
+	if len(task.Fallbacks) != 1 {
+		t.Fatalf("fallbacks = %+v，期望 1 项", task.Fallbacks)
+	}
+	if fb := task.Fallbacks[0]; fb.Format != "bilingual-pdf" || fb.Artifact != "bilingual-html" || fb.Policy != "html-fallback" || fb.Error == "" {
+		t.Errorf("fallback = %+v", fb)
+	}
+	status, _ := json.Marshal(task)
+	if !strings.Contains(string(status), `"policy":"html-fallback"`) {
+		t.Errorf("任务状态未注明替代输出: %s", status)
+	}
+
+	r := newTestRouter(sessionID, func(r *gin.Engine) { r.GET("/download/:taskId", DownloadHandler) })
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/download/"+taskID, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("下载状态码 = %d: %s", w.Code, w.Body.String())
+	}
+	if disposition := w.Header().Get("Content-Disposition"); !strings.Contains(disposition, ".html") {
+		t.Errorf("下载文件名 = %s，期望为网页", disposition)
+	}
+	if body := w.Body.String(); !strings.Contains(body, "<html") || !strings.Contains(body, "译文：") {
+		t.Errorf("下载内容不是双语网页:\n%s", body)
+	}
+}
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strings"
+	"translator-web/middleware"
+	"translator-web/models"
+	"translator-web/translator"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RetranslateHandler 只重新翻译已完成任务中的指定文本块并重新生成输出
+// 文本块 ID 来自对齐数据（alignment.json），任务提交时需在 outputFormats 中包含 alignment
+// 指定的文本块不读取缓存，其余文本块使用缓存中的译文
+func RetranslateHandler(c *gin.Context) {
+	sessionID := middleware.GetSessionID(c)
+	if sessionID == "" {
+		respondError(c, errInvalidSession)
+		return
+	}
+
+	var body struct {
+		BlockIDs []string `json:"blockIds"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		respondError(c, badRequest("请求格式错误: "+err.Error()))
+		return
+	}
+	if len(body.BlockIDs) == 0 {
+		respondError(c, badRequest("blockIds 不能为空"))
+		return
+	}
+
+	taskID := c.Param("taskId")
+	task, entries, ok := completedTaskAlignment(c, sessionID, taskID)
+	if !ok {
+		return
+	}
+	sources := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		sources[entry.BlockID] = entry.Source
+	}
+
+	var texts, unknown []string
+	seen := make(map[string]bool)
+	for _, id := range body.BlockIDs {
+		source, ok := sources[id]
+		if !ok || source == "" {
+			unknown = append(unknown, id)
+			continue
+		}
+		if !seen[source] {
+			seen[source] = true
+			texts = append(texts, source)
+		}
+	}
+	if len(unknown) > 0 {
+		respondError(c, badRequest("对齐数据中不存在文本块: "+strings.Join(unknown, ", ")), gin.H{"unknownBlockIds": unknown})
+		return
+	}
+
+	// 沿用提交时的配置，重新翻译的文本块不再使用之前人工修改的译文
+	req := *task.Request
+	req.Retranslate = texts
+	req.Edits = withoutEdits(req.Edits, texts)
+
+	log.Printf("[会话 %s][任务 %s] 重新翻译 %d 个文本块（%d 个待翻译文本）", sessionID[:8], taskID, len(body.BlockIDs), len(texts))
+	position, ok := resubmitTask(sessionID, taskID, req)
+	if !ok {
+		respondError(c, newAPIError(http.StatusBadRequest, CodeTaskNotReady, "任务未完成"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"taskId":        taskID,
+		"message":       "重新翻译任务已创建",
+		"blocks":        len(body.BlockIDs),
+		"queuePosition": position,
+	})
+}
+
+// completedTaskAlignment 返回已完成的任务及其对齐数据，失败时已写入错误响应
+func completedTaskAlignment(c *gin.Context, sessionID, taskID string) (*models.TranslateTask, []translator.AlignmentEntry, bool) {
+	task, exists := taskManager.GetTask(sessionID, taskID)
+	if !exists {
+		respondError(c, notFound("任务不存在或无权访问"))
+		return nil, nil, false
+	}
+	if task.Status != "completed" {
+		respondError(c, newAPIError(http.StatusBadRequest, CodeTaskNotReady, "任务未完成"))
+		return nil, nil, false
+	}
+	alignmentPath, ok := task.Artifacts[string(translator.OutputFormatAlignment)]
+	if !ok || task.Request == nil {
+		respondError(c, badRequest("任务未生成对齐数据，提交翻译时需在 outputFormats 中包含 alignment"))
+		return nil, nil, false
+	}
+
+	entries, err := translator.LoadAlignment(alignmentPath)
+	if err != nil {
+		respondError(c, internalError(err.Error()))
+		return nil, nil, false
+	}
+	return task, entries, true
+}
+
+// resubmitTask 以新的配置重新执行已完成的任务并重新生成全部输出，返回排队位置
+// 任务已不是完成状态（如正在被重新翻译）时返回 false；新的配置会保存为任务的配置
+func resubmitTask(sessionID, taskID string, req models.TranslateRequest) (int, bool) {
+	// 翻译记忆已在首次翻译时写入缓存
+	req.ForceRetranslate = false
+	req.TMX = nil
+
+	// 再次检查状态，避免同一任务被同时重新翻译
+	started := false
+	var sourceFile, sourcePath string
+	taskManager.UpdateTask(sessionID, taskID, func(t *models.TranslateTask) {
+		if t.Status != "completed" {
+			return
+		}
+		started = true
+		sourceFile, sourcePath = t.SourceFile, t.SourcePath
+		t.Status = "pending"
+		t.Progress = 0
+		t.Error = ""
+		t.ErrorCode = ""
+
+		saved := req
+		saved.Retranslate = nil
+		t.Request = &saved
+	})
+	if !started {
+		return 0, false
+	}
+
+	// 流式任务重新开始输出，结束标记随重新翻译的结果写入
+	if req.Stream {
+		openTaskStream(taskID)
+	}
+
+	position := taskQueue.Submit(sessionID, taskID, func() {
+		ctx, cancel := taskContext(req)
+		defer cancel()
+		processTranslation(ctx, sessionID, taskID, sourceFile, sourcePath, req, nil)
+	})
+	return position, true
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"translator-web/models"
+	"translator-web/translator"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRetranslateHandlerChangesOnlySelectedBlocks(t *testing.T) {
+	dir := chdirTemp(t)
+	const sessionID = "session-retranslate"
+
+	// 译文前缀随轮次变化，用于区分首次翻译和重新翻译的结果
+	var round atomic.Int32
+	round.Store(1)
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		var req struct {
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		text := req.Messages[len(req.Messages)-1].Content
+		prefix := "初译："
+		if round.Load() == 2 {
+			prefix = "重译："
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"choices": []map[string]any{{"message": map[string]string{"content": prefix + text}}},
+		})
+	}))
+	defer server.Close()
+
+	path := filepath.Join(dir, "paper.pdf")
+	writeTestPDF(t, path, 4)
+	pdf, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := postTranslate(t, sessionID, pdf, map[string]string{
+		"targetLanguage": "Uni",
+		"outputFormats":  "pdf,alignment",
+		"llmConfig":      `{"provider":"openai","apiUrl":"` + server.URL + `","apiKey":"key","model":"gpt-test"}`,
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("状态码 = %d: %s", w.Code, w.Body.String())
+	}
+	var resp map[string]any
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	taskID, _ := resp["taskId"].(string)
+	if status := waitForTask(t, sessionID, taskID); status != "completed" {
+		t.Fatalf("首次翻译状态 = %s", status)
+	}
+
+	task, _ := taskManager.TaskSnapshot(sessionID, taskID)
+	alignmentPath := task.Artifacts[string(translator.OutputFormatAlignment)]
+	before, err := translator.LoadAlignment(alignmentPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(before) != 4 {
+		t.Fatalf("对齐数据条数 = %d，期望 4", len(before))
+	}
+	selected := map[string]bool{before[1].BlockID: true, before[3].BlockID: true}
+
+	round.Store(2)
+	calls.Store(0)
+	r := newTestRouter(sessionID, func(r *gin.Engine) { r.POST("/tasks/:taskId/retranslate", RetranslateHandler) })
+	body, _ := json.Marshal(map[string]any{"blockIds": []string{before[1].BlockID, before[3].BlockID}})
+	w = postJSON(r, "/tasks/"+taskID+"/retranslate", string(body))
+	if w.Code != http.StatusOK {
+		t.Fatalf("重新翻译状态码 = %d: %s", w.Code, w.Body.String())
+	}
+	if status := waitForTask(t, sessionID, taskID); status != "completed" {
+		t.Fatalf("重新翻译状态 = %s", status)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("重新翻译调用翻译服务 %d 次，期望 2", got)
+	}
+
+	after, err := translator.LoadAlignment(alignmentPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(after) != len(before) {
+		t.Fatalf("重新翻译后对齐数据条数 = %d，期望 %d", len(after), len(before))
+	}
+	for i, entry := range after {
+		if entry.BlockID != before[i].BlockID {
+			t.Errorf("第 %d 条文本块 ID = %s，期望保持 %s", i, entry.BlockID, before[i].BlockID)
+		}
+		if selected[entry.BlockID] {
+			if !strings.HasPrefix(entry.Translated, "重译：") {
+				t.Errorf("选中的文本块 %s 译文 = %q，期望重新翻译", entry.BlockID, entry.Translated)
+			}
+		} else if entry.Translated != before[i].Translated {
+			t.Errorf("未选中的文本块 %s 译文 = %q，期望保持 %q", entry.BlockID, entry.Translated, before[i].Translated)
+		}
+	}
+}
+
+func TestRetranslateHandlerRejectsUnknownBlocks(t *testing.T) {
+	dir := chdirTemp(t)
+	const sessionID = "session-retranslate-unknown"
+	alignmentPath := filepath.Join(dir, "alignment.json")
+	os.WriteFile(alignmentPath, []byte(`[{"page":1,"blockId":"p1_text_0","original":"Hello","translated":"你好","source":"Hello"}]`), 0644)
+	taskManager.AddTask(sessionID, &models.TranslateTask{
+		ID:        "task-retranslate",
+		SessionID: sessionID,
+		Status:    "completed",
+		Request:   &models.TranslateRequest{},
+		Artifacts: map[string]string{string(translator.OutputFormatAlignment): alignmentPath},
+	})
+
+	r := newTestRouter(sessionID, func(r *gin.Engine) { r.POST("/tasks/:taskId/retranslate", RetranslateHandler) })
+	if w := postJSON(r, "/tasks/task-retranslate/retranslate", `{"blockIds":["p9_text_9"]}`); w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "p9_text_9") {
+		t.Errorf("未知文本块: 状态码 = %d: %s", w.Code, w.Body.String())
+	}
+	if w := postJSON(r, "/tasks/task-retranslate/retranslate", `{"blockIds":[]}`); w.Code != http.StatusBadRequest {
+		t.Errorf("空列表: 状态码 = %d，期望 400", w.Code)
+	}
+}
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"translator-web/middleware"
+	"translator-web/translator"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReviewHandler 返回已完成任务的可编辑校对页面（左右对照，译文可直接编辑）
+// 页面中的保存按钮将修改提交到 EditsHandler；任务提交时需在 outputFormats 中包含 alignment
+func ReviewHandler(c *gin.Context) {
+	sessionID := middleware.GetSessionID(c)
+	if sessionID == "" {
+		respondError(c, errInvalidSession)
+		return
+	}
+
+	taskID := c.Param("taskId")
+	task, entries, ok := completedTaskAlignment(c, sessionID, taskID)
+	if !ok {
+		return
+	}
+
+	page := translator.RenderReviewHTML(task.SourceFile+" - 校对", entries, "/api/tasks/"+taskID+"/edits")
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(page))
+}
+
+// EditsHandler 保存人工修改的译文并重新生成已完成任务的全部输出
+// 请求为 {blockId: 新译文}，文本块 ID 来自对齐数据；修改按所属的待翻译文本块保存，
+// 写入翻译缓存，并在之后重新翻译该任务时继续使用（对这些文本块调用 retranslate 时除外）
+func EditsHandler(c *gin.Context) {
+	sessionID := middleware.GetSessionID(c)
+	if sessionID == "" {
+		respondError(c, errInvalidSession)
+		return
+	}
+
+	var body map[string]string
+	if err := c.ShouldBindJSON(&body); err != nil {
+		respondError(c, badRequest("请求格式错误: "+err.Error()))
+		return
+	}
+	if len(body) == 0 {
+		respondError(c, badRequest("修改不能为空"))
+		return
+	}
+
+	taskID := c.Param("taskId")
+	task, entries, ok := completedTaskAlignment(c, sessionID, taskID)
+	if !ok {
+		return
+	}
+	sources := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		sources[entry.BlockID] = entry.Source
+	}
+
+	// 按 ID 排序，冲突时的错误信息稳定
+	ids := make([]string, 0, len(body))
+	for id := range body {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	edits := make(map[string]string, len(body))
+	editedBy := make(map[string]string, len(body))
+	var unknown []string
+	for _, id := range ids {
+		source, ok := sources[id]
+		if !ok || source == "" {
+			unknown = append(unknown, id)
+			continue
+		}
+		translation := body[id]
+		if strings.TrimSpace(translation) == "" {
+			respondError(c, badRequest("译文不能为空: "+id))
+			return
+		}
+		if previous, exists := edits[source]; exists && previous != translation {
+			respondError(c, badRequest("文本块 "+editedBy[source]+" 和 "+id+" 属于同一段原文，但修改不一致"))
+			return
+		}
+		edits[source] = translation
+		editedBy[source] = id
+	}
+	if len(unknown) > 0 {
+		respondError(c, badRequest("对齐数据中不存在文本块: "+strings.Join(unknown, ", ")), gin.H{"unknownBlockIds": unknown})
+		return
+	}
+
+	// 沿用提交时的配置，与之前的修改合并
+	req := *task.Request
+	merged := make(map[string]string, len(req.Edits)+len(edits))
+	for source, translation := range req.Edits {
+		merged[source] = translation
+	}
+	for source, translation := range edits {
+		merged[source] = translation
+	}
+	req.Edits = merged
+	req.Retranslate = nil
+
+	log.Printf("[会话 %s][任务 %s] 保存 %d 处人工修改（%d 个文本块）", sessionID[:8], taskID, len(body), len(edits))
+	position, ok := resubmitTask(sessionID, taskID, req)
+	if !ok {
+		respondError(c, newAPIError(http.StatusBadRequest, CodeTaskNotReady, "任务未完成"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"taskId":        taskID,
+		"message":       "修改已保存，正在重新生成输出",
+		"blocks":        len(edits),
+		"queuePosition": position,
+	})
+}
+
+// withoutEdits 返回去掉指定原文后的人工修改
+func withoutEdits(edits map[string]string, sources []string) map[string]string {
+	if len(edits) == 0 {
+		return edits
+	}
+	remaining := make(map[string]string, len(edits))
+	for source, translation := range edits {
+		remaining[source] = translation
+	}
+	for _, source := range sources {
+		delete(remaining, source)
+	}
+	return remaining
+}
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"translator-web/models"
+	"translator-web/translator"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestEditsHandlerUpdatesAlignmentAndPDF(t *testing.T) {
+	dir := chdirTemp(t)
+	const sessionID = "session-review-edits"
+
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		var req struct {
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		json.NewEncoder(w).Encode(map[string]any{
+			"choices": []map[string]any{{"message": map[string]string{"content": "Machine " + req.Messages[len(req.Messages)-1].Content}}},
+		})
+	}))
+	defer server.Close()
+
+	path := filepath.Join(dir, "paper.pdf")
+	writeTestPDF(t, path, 2)
+	pdf, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := postTranslate(t, sessionID, pdf, map[string]string{
+		// 使用拉丁字母的目标语言，避免依赖系统 CJK 字体
+		"targetLanguage": "French",
+		"outputFormats":  "pdf,alignment",
+		// pdfcpu 保留单词间的空格，原文与 PDF 中的文本元素一致
+		"textExtractor": "pdfcpu",
+		"llmConfig":     `{"provider":"openai","apiUrl":"` + server.URL + `","apiKey":"key","model":"gpt-test"}`,
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("状态码 = %d: %s", w.Code, w.Body.String())
+	}
+	var resp map[string]any
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	taskID, _ := resp["taskId"].(string)
+	if status := waitForTask(t, sessionID, taskID); status != "completed" {
+		t.Fatalf("首次翻译状态 = %s", status)
+	}
+
+	task, _ := taskManager.TaskSnapshot(sessionID, taskID)
+	alignmentPath := task.Artifacts[string(translator.OutputFormatAlignment)]
+	before, err := translator.LoadAlignment(alignmentPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(before) != 2 {
+		t.Fatalf("对齐数据条数 = %d，期望 2", len(before))
+	}
+
+	r := newTestRouter(sessionID, func(r *gin.Engine) {
+		r.GET("/tasks/:taskId/review", ReviewHandler)
+		r.POST("/tasks/:taskId/edits", EditsHandler)
+	})
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/tasks/"+taskID+"/review", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `contenteditable="true" data-block-id="`+before[0].BlockID+`"`) {
+		t.Fatalf("校对页面状态码 = %d:\n%s", w.Code, w.Body.String())
+	}
+
+	const edited = "Reviewed wording"
+	calls.Store(0)
+	body, _ := json.Marshal(map[string]string{before[0].BlockID: edited})
+	if w := postJSON(r, "/tasks/"+taskID+"/edits", string(body)); w.Code != http.StatusOK {
+		t.Fatalf("保存修改状态码 = %d: %s", w.Code, w.Body.String())
+	}
+	if status := waitForTask(t, sessionID, taskID); status != "completed" {
+		t.Fatalf("重新生成状态 = %s", status)
+	}
+	if n := calls.Load(); n != 0 {
+		t.Errorf("重新生成时请求翻译服务 %d 次，期望全部来自修改和缓存", n)
+	}
+
+	after, err := translator.LoadAlignment(alignmentPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(after) != 2 || after[0].Translated != edited || after[1].Translated != before[1].Translated {
+		t.Errorf("修改后的对齐数据 = %+v", after)
+	}
+
+	task, _ = taskManager.TaskSnapshot(sessionID, taskID)
+	extractor, err := translator.ParseTextExtractor(translator.TextExtractorPDFCPU)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Overlay 模式在遮罩下保留原文，用 pdfcpu 读取全部文本
+	pages, err := extractor.ExtractPages(task.Artifacts[string(translator.OutputFormatPDF)])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var text string
+	for _, page := range pages {
+		text += page.Text + "\n"
+	}
+	text = strings.ReplaceAll(text, " ", "")
+	if !strings.Contains(text, strings.ReplaceAll(edited, " ", "")) {
+		t.Errorf("重新生成的 PDF 不含修改后的译文:\n%s", text)
+	}
+}
+
+func TestEditsHandlerRejectsInvalidEdits(t *testing.T) {
+	dir := chdirTemp(t)
+	const sessionID = "session-review-invalid"
+	alignmentPath := filepath.Join(dir, "alignment.json")
+	os.WriteFile(alignmentPath, []byte(`[
+		{"page":1,"blockId":"p1_text_0","original":"Hello","translated":"你好","source":"Hello world"},
+		{"page":1,"blockId":"p1_text_1","original":"world","translated":"世界","source":"Hello world"}
+	]`), 0644)
+	taskManager.AddTask(sessionID, &models.TranslateTask{
+		ID:        "task-edits",
+		SessionID: sessionID,
+		Status:    "completed",
+		Request:   &models.TranslateRequest{},
+		Artifacts: map[string]string{string(translator.OutputFormatAlignment): alignmentPath},
+	})
+
+	r := newTestRouter(sessionID, func(r *gin.Engine) { r.POST("/tasks/:taskId/edits", EditsHandler) })
+	for name, body := range map[string]string{
+		"空修改":     `{}`,
+		"未知文本块":   `{"p9_text_9":"译文"}`,
+		"空译文":     `{"p1_text_0":"  "}`,
+		"同一原文不一致": `{"p1_text_0":"你好世界","p1_text_1":"您好世界"}`,
+	} {
+		if w := postJSON(r, "/tasks/task-edits/edits", body); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: 状态码 = %d，期望 400: %s", name, w.Code, w.Body.String())
+		}
+	}
+}
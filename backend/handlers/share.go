@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"translator-web/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// DefaultShareTTL 共享链接默认有效期
+	DefaultShareTTL = 7 * 24 * time.Hour
+	// MaxShareTTL 共享链接最长有效期
+	MaxShareTTL = 30 * 24 * time.Hour
+)
+
+var (
+	shareSecretOnce sync.Once
+	shareSecretKey  []byte
+)
+
+// shareSecret 返回共享链接的签名密钥
+// 优先使用环境变量 SHARE_SECRET；未设置时随机生成，服务重启后已发出的链接失效
+func shareSecret() []byte {
+	shareSecretOnce.Do(func() {
+		if secret := os.Getenv("SHARE_SECRET"); secret != "" {
+			shareSecretKey = []byte(secret)
+			return
+		}
+		shareSecretKey = make([]byte, 32)
+		if _, err := rand.Read(shareSecretKey); err != nil {
+			panic(fmt.Sprintf("生成共享链接密钥失败: %v", err))
+		}
+		log.Printf("未设置 SHARE_SECRET，使用随机密钥签名共享链接（服务重启后已分享的链接将失效）")
+	})
+	return shareSecretKey
+}
+
+// signShare 计算任务 ID 和过期时间的 HMAC-SHA256 签名
+func signShare(taskID string, expiresAt int64) []byte {
+	mac := hmac.New(sha256.New, shareSecret())
+	fmt.Fprintf(mac, "%s|%d", taskID, expiresAt)
+	return mac.Sum(nil)
+}
+
+// NewShareToken 生成任务的共享令牌，格式为 "<taskID>.<过期时间戳>.<签名>"
+// 令牌不包含会话信息，持有者只能下载该任务的输出
+func NewShareToken(taskID string, expiresAt time.Time) string {
+	expiry := expiresAt.Unix()
+	sig := base64.RawURLEncoding.EncodeToString(signShare(taskID, expiry))
+	return taskID + "." + strconv.FormatInt(expiry, 10) + "." + sig
+}
+
+// ParseShareToken 校验共享令牌的签名和有效期，返回任务 ID
+func ParseShareToken(token string, now time.Time) (string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || parts[0] == "" {
+		return "", errors.New("共享链接格式无效")
+	}
+
+	expiry, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return "", errors.New("共享链接格式无效")
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || !hmac.Equal(sig, signShare(parts[0], expiry)) {
+		return "", errors.New("共享链接签名无效")
+	}
+	if now.Unix() >= expiry {
+		return "", errors.New("共享链接已过期")
+	}
+	return parts[0], nil
+}
+
+// ShareTaskHandler 为已完成的任务生成只读共享链接
+// 可选参数 expiresIn 为有效期（如 "24h"），默认 7 天，最长 30 天
+func ShareTaskHandler(c *gin.Context) {
+	sessionID := middleware.GetSessionID(c)
+	if sessionID == "" {
+		respondError(c, errInvalidSession)
+		return
+	}
+
+	taskID := c.Param("taskId")
+	task, exists := taskManager.GetTask(sessionID, taskID)
+	if !exists {
+		respondError(c, notFound("任务不存在或无权访问"))
+		return
+	}
+	if task.Status != "completed" {
+		respondError(c, newAPIError(http.StatusBadRequest, CodeTaskNotReady, "只能分享已完成的任务"))
+		return
+	}
+
+	ttl := DefaultShareTTL
+	if value := c.DefaultPostForm("expiresIn", c.Query("expiresIn")); value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			respondError(c, badRequest("expiresIn 格式错误，应为正的时长（如 24h）"))
+			return
+		}
+		ttl = min(d, MaxShareTTL)
+	}
+
+	expiresAt := time.Now().Add(ttl)
+	token := NewShareToken(taskID, expiresAt)
+	c.JSON(http.StatusOK, gin.H{
+		"token":     token,
+		"url":       "/api/shared/" + token,
+		"expiresAt": expiresAt.UTC().Format(time.RFC3339),
+	})
+}
+
+// SharedDownloadHandler 通过共享链接下载任务输出，不要求会话，只提供下载
+func SharedDownloadHandler(c *gin.Context) {
+	taskID, err := ParseShareToken(c.Param("token"), time.Now())
+	if err != nil {
+		respondError(c, newAPIError(http.StatusForbidden, CodeInvalidShareLink, err.Error()))
+		return
+	}
+
+	task, exists := taskManager.FindTask(taskID)
+	if !exists {
+		respondError(c, notFound("任务不存在或已被清理"))
+		return
+	}
+
+	outputPath, filename, ok := resolveDownload(c, task)
+	if !ok {
+		return
+	}
+
+	if sum, err := taskChecksum(task.SessionID, task.ID, outputPath); err == nil {
+		setChecksumHeaders(c, sum)
+	} else {
+		log.Printf("[共享][任务 %s] 计算校验和失败: %v", task.ID, err)
+	}
+
+	c.FileAttachment(outputPath, filename)
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+	"translator-web/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestShareLinkGrantsDownload(t *testing.T) {
+	chdirTemp(t)
+	const owner = "session-share-owner"
+	outputPath := OutputPath(owner, "task-share-1", "paper.pdf", "mono", ".pdf")
+	writeTestPDF(t, outputPath, 1)
+	taskManager.AddTask(owner, &models.TranslateTask{
+		ID:         "task-share-1",
+		SessionID:  owner,
+		SourceFile: "paper.pdf",
+		Status:     "completed",
+		OutputPath: outputPath,
+	})
+
+	w := httptest.NewRecorder()
+	newTestRouter(owner, func(r *gin.Engine) { r.POST("/tasks/:taskId/share", ShareTaskHandler) }).
+		ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/tasks/task-share-1/share", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("状态码 = %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Token string `json:"token"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+
+	// 其他会话持有链接即可下载
+	shared := newTestRouter("session-share-visitor", func(r *gin.Engine) { r.GET("/shared/:token", SharedDownloadHandler) })
+	download := func(token string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		shared.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/shared/"+token, nil))
+		return w
+	}
+
+	w = download(resp.Token)
+	if w.Code != http.StatusOK {
+		t.Fatalf("共享下载状态码 = %d: %s", w.Code, w.Body.String())
+	}
+	want, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(w.Body.Bytes(), want) {
+		t.Error("共享下载的内容与输出文件不一致")
+	}
+
+	expired := NewShareToken("task-share-1", time.Now().Add(-time.Minute))
+	parts := strings.Split(resp.Token, ".")
+	tampered := []string{
+		expired,
+		"task-other." + parts[1] + "." + parts[2],
+		parts[0] + "." + parts[1] + "0." + parts[2],
+		parts[0] + "." + parts[1] + "." + strings.Repeat("A", len(parts[2])),
+		"not-a-token",
+	}
+	for _, token := range tampered {
+		if w := download(token); w.Code != http.StatusForbidden {
+			t.Errorf("令牌 %q 状态码 = %d，期望 403", token, w.Code)
+		}
+	}
+}
+
+func TestShareRequiresCompletedTask(t *testing.T) {
+	chdirTemp(t)
+	const sessionID = "session-share-pending"
+	taskManager.AddTask(sessionID, &models.TranslateTask{ID: "task-share-2", SessionID: sessionID, Status: "processing"})
+
+	w := httptest.NewRecorder()
+	newTestRouter(sessionID, func(r *gin.Engine) { r.POST("/tasks/:taskId/share", ShareTaskHandler) }).
+		ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/tasks/task-share-2/share", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("未完成任务的分享状态码 = %d，期望 400", w.Code)
+	}
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"translator-web/middleware"
+	"translator-web/translator"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StreamDoneMarker 流式输出结束标记，后接任务最终状态（completed 或 failed）
+const StreamDoneMarker = "[DONE]"
+
+// taskStream 流式任务不断增长的双语输出
+type taskStream struct {
+	mu      sync.Mutex
+	chunks  []string
+	done    bool
+	marker  string
+	changed chan struct{} // 有新内容或结束时关闭并替换
+}
+
+// taskStreams taskID -> *taskStream，任务结束后保留，供晚连接的客户端读取完整输出
+var taskStreams sync.Map
+
+// openTaskStream 为任务创建流式输出
+func openTaskStream(taskID string) *taskStream {
+	stream := &taskStream{changed: make(chan struct{})}
+	taskStreams.Store(taskID, stream)
+	return stream
+}
+
+// getTaskStream 返回任务的流式输出
+func getTaskStream(taskID string) (*taskStream, bool) {
+	value, ok := taskStreams.Load(taskID)
+	if !ok {
+		return nil, false
+	}
+	return value.(*taskStream), true
+}
+
+// appendResult 追加一个文本块的原文和译文
+func (s *taskStream) appendResult(result translator.TranslateResult) {
+	original := strings.TrimSpace(result.Original)
+	if original == "" {
+		return
+	}
+	s.write(original + "\n" + strings.TrimSpace(result.Translated) + "\n\n")
+}
+
+// write 追加一段输出并唤醒等待的读取方
+func (s *taskStream) write(chunk string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.done {
+		return
+	}
+	s.chunks = append(s.chunks, chunk)
+	s.notifyLocked()
+}
+
+// finish 结束输出，marker 为结束标记行
+func (s *taskStream) finish(marker string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.done {
+		return
+	}
+	s.done = true
+	s.marker = marker
+	s.notifyLocked()
+}
+
+func (s *taskStream) notifyLocked() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+// read 返回 offset 之后的输出；未结束时返回的通道会在有新内容时关闭
+func (s *taskStream) read(offset int) (chunks []string, done bool, marker string, changed <-chan struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.chunks[offset:], s.done, s.marker, s.changed
+}
+
+// finishTaskStream 按任务的最终状态结束流式输出
+func finishTaskStream(sessionID, taskID string, stream *taskStream) {
+	marker := StreamDoneMarker + " failed"
+	if task, ok := taskManager.GetTask(sessionID, taskID); ok && (task.Status == "completed" || task.Status == "partial" || task.Status == "timed_out") {
+		marker = StreamDoneMarker + " " + task.Status
+	}
+	stream.finish(marker + "\n")
+}
+
+// chainResultHandler 在已有的结果回调之后追加回调
+func chainResultHandler(client *translator.TranslatorClient, fn func(translator.TranslateResult)) {
+	prev := client.OnResult
+	client.OnResult = func(result translator.TranslateResult) {
+		if prev != nil {
+			prev(result)
+		}
+		fn(result)
+	}
+}
+
+// StreamHandler 以分块传输的方式实时输出流式任务的双语文本
+// 每个文本块输出为 "原文\n译文\n\n"，任务结束时输出 "[DONE] completed" 或 "[DONE] failed"
+func StreamHandler(c *gin.Context) {
+	sessionID := middleware.GetSessionID(c)
+	if sessionID == "" {
+		respondError(c, errInvalidSession)
+		return
+	}
+
+	taskID := c.Param("taskId")
+	if _, exists := taskManager.GetTask(sessionID, taskID); !exists {
+		respondError(c, notFound("任务不存在或无权访问"))
+		return
+	}
+
+	stream, ok := getTaskStream(taskID)
+	if !ok {
+		respondError(c, badRequest("该任务未启用流式输出，请在提交翻译时设置 stream=true"))
+		return
+	}
+
+	c.Header("Content-Type", "text/plain; charset=utf-8")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Status(http.StatusOK)
+
+	offset := 0
+	for {
+		chunks, done, marker, changed := stream.read(offset)
+		for _, chunk := range chunks {
+			if _, err := c.Writer.WriteString(chunk); err != nil {
+				return
+			}
+		}
+		offset += len(chunks)
+		if done {
+			c.Writer.WriteString(marker)
+		}
+		c.Writer.Flush()
+		if done {
+			return
+		}
+
+		select {
+		case <-changed:
+		case <-c.Request.Context().Done():
+			return
+		}
+	}
+}
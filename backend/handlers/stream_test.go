@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"translator-web/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestStreamHandlerDeliversAllBlocks(t *testing.T) {
+	dir := chdirTemp(t)
+	const sessionID = "session-stream"
+
+	// 翻译服务在放行前阻塞，保证连接时任务尚未完成
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		var req struct {
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		text := req.Messages[len(req.Messages)-1].Content
+		json.NewEncoder(w).Encode(map[string]any{
+			"choices": []map[string]any{{"message": map[string]string{"content": "译文：" + text}}},
+		})
+	}))
+	defer server.Close()
+	released := false
+	defer func() {
+		if !released {
+			close(release)
+		}
+	}()
+
+	path := filepath.Join(dir, "paper.pdf")
+	writeTestPDF(t, path, 3)
+	pdf, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := postTranslate(t, sessionID, pdf, map[string]string{
+		"targetLanguage": "Uni",
+		"stream":         "true",
+		"llmConfig":      `{"provider":"openai","apiUrl":"` + server.URL + `","apiKey":"key","model":"gpt-test"}`,
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("状态码 = %d: %s", w.Code, w.Body.String())
+	}
+	var resp map[string]any
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	taskID, _ := resp["taskId"].(string)
+	defer waitForTask(t, sessionID, taskID)
+
+	api := httptest.NewServer(newTestRouter(sessionID, func(r *gin.Engine) { r.GET("/stream/:taskId", StreamHandler) }))
+	defer api.Close()
+	streamResp, err := http.Get(api.URL + "/stream/" + taskID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer streamResp.Body.Close()
+	if streamResp.StatusCode != http.StatusOK {
+		t.Fatalf("流式输出状态码 = %d", streamResp.StatusCode)
+	}
+
+	close(release)
+	released = true
+	body, err := io.ReadAll(streamResp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	output := string(body)
+	if !strings.HasSuffix(output, StreamDoneMarker+" completed\n") {
+		t.Fatalf("输出未以结束标记结尾:\n%s", output)
+	}
+
+	// 每个文本块输出为 "原文\n译文\n\n"，顺序取决于完成顺序
+	pairs := strings.Split(strings.TrimSuffix(output, StreamDoneMarker+" completed\n"), "\n\n")
+	pages := make(map[string]bool)
+	for _, pair := range pairs[:len(pairs)-1] {
+		original, translated, _ := strings.Cut(pair, "\n")
+		if translated != "译文："+original {
+			t.Errorf("原文与译文不匹配: %q", pair)
+		}
+		// 文本提取可能去掉空格，只比较页码
+		for i := 1; i <= 3; i++ {
+			if strings.HasSuffix(original, fmt.Sprint(i)) {
+				pages[original] = true
+			}
+		}
+	}
+	if len(pairs) != 4 || len(pages) != 3 {
+		t.Errorf("期望 3 个文本块，得到:\n%s", output)
+	}
+}
+
+func TestStreamHandlerRequiresStreamingTask(t *testing.T) {
+	chdirTemp(t)
+	const sessionID = "session-stream-off"
+	taskManager.AddTask(sessionID, &models.TranslateTask{ID: "task-no-stream", SessionID: sessionID, Status: "completed"})
+
+	r := newTestRouter(sessionID, func(r *gin.Engine) { r.GET("/stream/:taskId", StreamHandler) })
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/stream/task-no-stream", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("未启用流式输出的任务状态码 = %d，期望 400", w.Code)
+	}
+}
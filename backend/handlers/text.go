@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"translator-web/middleware"
+	"translator-web/models"
+	"translator-web/translator"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxTextTranslateLength 同步文本翻译的最大输入长度（字符数）
+const maxTextTranslateLength = 10000
+
+// defaultTextRateLimit 每个会话每分钟允许的同步文本翻译请求数
+const defaultTextRateLimit = 30
+
+// sessionRateLimiter 按会话限制请求频率（固定一分钟窗口）
+type sessionRateLimiter struct {
+	mu      sync.Mutex
+	limit   int
+	windows map[string]*rateWindow
+}
+
+// rateWindow 会话当前计数窗口
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+var textRateLimiter = newSessionRateLimiter(textRateLimitFromEnv())
+
+// textRateLimitFromEnv 从 TEXT_TRANSLATE_RATE_LIMIT 读取每分钟请求上限
+func textRateLimitFromEnv() int {
+	value := os.Getenv("TEXT_TRANSLATE_RATE_LIMIT")
+	if value == "" {
+		return defaultTextRateLimit
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		log.Printf("⚠️  TEXT_TRANSLATE_RATE_LIMIT 无效 %q，使用默认值 %d", value, defaultTextRateLimit)
+		return defaultTextRateLimit
+	}
+	return n
+}
+
+// newSessionRateLimiter 创建按会话计数的限流器
+func newSessionRateLimiter(limit int) *sessionRateLimiter {
+	return &sessionRateLimiter{
+		limit:   limit,
+		windows: make(map[string]*rateWindow),
+	}
+}
+
+// Allow 记录一次请求，超过限制时返回 false 和需要等待的时间
+func (l *sessionRateLimiter) Allow(sessionID string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	window, ok := l.windows[sessionID]
+	if !ok || now.Sub(window.start) >= time.Minute {
+		// 顺带清理过期窗口，避免长期运行时无限增长
+		for id, w := range l.windows {
+			if now.Sub(w.start) >= time.Minute {
+				delete(l.windows, id)
+			}
+		}
+		window = &rateWindow{start: now}
+		l.windows[sessionID] = window
+	}
+
+	if window.count >= l.limit {
+		return false, window.start.Add(time.Minute).Sub(now)
+	}
+	window.count++
+	return true, 0
+}
+
+// TranslateTextHandler 同步翻译一段文本，不创建任务
+func TranslateTextHandler(c *gin.Context) {
+	sessionID := middleware.GetSessionID(c)
+	if sessionID == "" {
+		respondError(c, errInvalidSession)
+		return
+	}
+
+	var req models.TranslateTextRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, badRequest("请求格式错误: "+err.Error()))
+		return
+	}
+	if !storedLLMConfig(c, &req.LLMConfig) {
+		return
+	}
+
+	if strings.TrimSpace(req.Text) == "" {
+		respondError(c, badRequest("文本不能为空"))
+		return
+	}
+	if length := utf8.RuneCountInString(req.Text); length > maxTextTranslateLength {
+		respondError(c, newAPIError(http.StatusRequestEntityTooLarge, CodePayloadTooLarge, "文本过长，最多支持 "+strconv.Itoa(maxTextTranslateLength)+" 个字符"), gin.H{
+			"length":    length,
+			"maxLength": maxTextTranslateLength,
+		})
+		return
+	}
+	if req.TargetLanguage == "" {
+		respondError(c, badRequest("目标语言不能为空"))
+		return
+	}
+	if err := translator.ValidateFormality(req.Formality); err != nil {
+		respondError(c, badRequest(err.Error()))
+		return
+	}
+	if err := normalizeLLMConfig(&req.LLMConfig); err != nil {
+		respondError(c, badRequest(err.Error()))
+		return
+	}
+	if apiErr := validateLanguages(req.LLMConfig, req.Formality, req.TargetLanguage); apiErr != nil {
+		respondError(c, apiErr)
+		return
+	}
+
+	if ok, retryAfter := textRateLimiter.Allow(sessionID); !ok {
+		seconds := int(retryAfter.Seconds()) + 1
+		c.Header("Retry-After", strconv.Itoa(seconds))
+		respondError(c, newAPIError(http.StatusTooManyRequests, CodeRateLimited, "请求过于频繁，请 "+strconv.Itoa(seconds)+" 秒后重试"))
+		return
+	}
+
+	// 与文档翻译共用会话缓存
+	cache, _ := translator.NewCache(filepath.Join("data", "users", sessionID, "cache"))
+	provider, err := translator.NewProvider(newProviderConfig(req.LLMConfig, req.Formality), cache)
+	if err != nil {
+		respondError(c, badRequest(err.Error()))
+		return
+	}
+
+	start := time.Now()
+	var translated string
+	if req.Markdown {
+		translated, err = translator.TranslateMarkdown(provider, req.Text, req.TargetLanguage, req.UserPrompt)
+	} else {
+		translated, err = provider.Translate(req.Text, req.TargetLanguage, req.UserPrompt)
+	}
+	translator.RecordTranslation(provider.GetConfig().Type, err)
+	if err != nil {
+		log.Printf("[会话 %s] 文本翻译失败: %v", sessionID[:8], err)
+		respondError(c, classifyError(err, newAPIError(http.StatusBadGateway, CodeProviderError, "翻译失败: "+err.Error())))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"translation": translated,
+		"provider":    provider.GetName(),
+		"latencyMs":   time.Since(start).Milliseconds(),
+	})
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// postTranslateText 向 /translate-text 提交 JSON 请求
+func postTranslateText(t *testing.T, sessionID string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+	data, err := json.Marshal(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := newTestRouter(sessionID, func(r *gin.Engine) { r.POST("/translate-text", TranslateTextHandler) })
+	req := httptest.NewRequest(http.MethodPost, "/translate-text", strings.NewReader(string(data)))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+// newTextStub 启动 OpenAI 兼容的翻译服务桩，始终返回 reply
+func newTextStub(t *testing.T, reply string) map[string]any {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{
+			"choices": []any{map[string]any{"message": map[string]string{"content": reply}}},
+		})
+	}))
+	t.Cleanup(server.Close)
+	return map[string]any{"provider": "openai", "apiUrl": server.URL, "apiKey": "key", "model": "gpt-test"}
+}
+
+func TestTranslateTextHandler(t *testing.T) {
+	chdirTemp(t)
+	w := postTranslateText(t, "session-text", map[string]any{
+		"text":           "Hello, world",
+		"targetLanguage": "Uni",
+		"llmConfig":      newTextStub(t, "你好，世界"),
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("状态码 = %d: %s", w.Code, w.Body.String())
+	}
+	var resp map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp["translation"] != "你好，世界" {
+		t.Errorf("translation = %v", resp["translation"])
+	}
+}
+
+func TestTranslateTextHandlerRejectsLongText(t *testing.T) {
+	chdirTemp(t)
+	w := postTranslateText(t, "session-text-long", map[string]any{
+		"text":           strings.Repeat("a", maxTextTranslateLength+1),
+		"targetLanguage": "Uni",
+		"llmConfig":      newTextStub(t, "不应调用"),
+	})
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("状态码 = %d，期望 413: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestTranslateTextHandlerRateLimited(t *testing.T) {
+	chdirTemp(t)
+	defer func(l *sessionRateLimiter) { textRateLimiter = l }(textRateLimiter)
+	textRateLimiter = newSessionRateLimiter(1)
+
+	body := map[string]any{"text": "Hello", "targetLanguage": "Uni", "llmConfig": newTextStub(t, "你好")}
+	if w := postTranslateText(t, "session-text-limit", body); w.Code != http.StatusOK {
+		t.Fatalf("第一次请求状态码 = %d: %s", w.Code, w.Body.String())
+	}
+	w := postTranslateText(t, "session-text-limit", body)
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") == "" {
+		t.Errorf("超出限制应返回 429 和 Retry-After，得到 %d %q", w.Code, w.Header().Get("Retry-After"))
+	}
+	// 限流按会话计数
+	if w := postTranslateText(t, "session-text-other", body); w.Code != http.StatusOK {
+		t.Errorf("其他会话不受影响，状态码 = %d", w.Code)
+	}
+}
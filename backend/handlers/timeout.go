@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+	"translator-web/models"
+)
+
+// defaultTaskTimeout 翻译任务的默认最长执行时间
+const defaultTaskTimeout = time.Hour
+
+// maxTaskTimeout 服务端允许的任务最长执行时间，0 表示不限制
+// 可通过环境变量 TASK_TIMEOUT 设置（如 30m、2h）
+var maxTaskTimeout = taskTimeoutFromEnv()
+
+// taskTimeoutFromEnv 从 TASK_TIMEOUT 读取任务最长执行时间
+func taskTimeoutFromEnv() time.Duration {
+	value := os.Getenv("TASK_TIMEOUT")
+	if value == "" {
+		return defaultTaskTimeout
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout < 0 {
+		log.Printf("⚠️  TASK_TIMEOUT 无效 %q，使用默认值 %s", value, defaultTaskTimeout)
+		return defaultTaskTimeout
+	}
+	return timeout
+}
+
+// taskTimeout 任务使用的超时：请求指定的超时（秒）不能超过服务端上限
+func taskTimeout(req models.TranslateRequest) time.Duration {
+	timeout := time.Duration(req.Timeout) * time.Second
+	if timeout <= 0 || (maxTaskTimeout > 0 && timeout > maxTaskTimeout) {
+		return maxTaskTimeout
+	}
+	return timeout
+}
+
+// taskContext 创建任务上下文，到达超时后取消进行中的翻译请求
+func taskContext(req models.TranslateRequest) (context.Context, context.CancelFunc) {
+	if timeout := taskTimeout(req); timeout > 0 {
+		return context.WithTimeout(context.Background(), timeout)
+	}
+	return context.WithCancel(context.Background())
+}
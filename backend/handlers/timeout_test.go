@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"translator-web/models"
+)
+
+func TestTaskTimeout(t *testing.T) {
+	defer func(v time.Duration) { maxTaskTimeout = v }(maxTaskTimeout)
+	maxTaskTimeout = 10 * time.Minute
+
+	tests := []struct {
+		seconds int
+		want    time.Duration
+	}{
+		{0, 10 * time.Minute},
+		{30, 30 * time.Second},
+		{3600, 10 * time.Minute},
+		{-5, 10 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := taskTimeout(models.TranslateRequest{Timeout: tt.seconds}); got != tt.want {
+			t.Errorf("taskTimeout(%d) = %s，期望 %s", tt.seconds, got, tt.want)
+		}
+	}
+
+	maxTaskTimeout = 0
+	if got := taskTimeout(models.TranslateRequest{}); got != 0 {
+		t.Errorf("服务端不限制且请求未指定时 = %s，期望 0", got)
+	}
+}
+
+func TestTranslateHandlerTimesOut(t *testing.T) {
+	dir := chdirTemp(t)
+	const sessionID = "session-timeout"
+
+	// 翻译服务在请求被取消前不返回
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-done:
+		}
+	}))
+	defer server.Close()
+	defer close(done)
+
+	path := filepath.Join(dir, "paper.pdf")
+	writeTestPDF(t, path, 2)
+	pdf, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	w := postTranslate(t, sessionID, pdf, map[string]string{
+		"targetLanguage": "Uni",
+		"timeout":        "1",
+		"llmConfig":      `{"provider":"openai","apiUrl":"` + server.URL + `","apiKey":"key","model":"gpt-test"}`,
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("状态码 = %d: %s", w.Code, w.Body.String())
+	}
+	var resp map[string]any
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	taskID, _ := resp["taskId"].(string)
+
+	if status := waitForTask(t, sessionID, taskID); status != "timed_out" {
+		t.Fatalf("任务状态 = %s，期望 timed_out", status)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("任务在 %s 后才结束，期望在超时（1 秒）后很快结束", elapsed)
+	}
+	task, _ := taskManager.TaskSnapshot(sessionID, taskID)
+	if task.Error == "" {
+		t.Error("超时的任务应记录错误信息")
+	}
+}
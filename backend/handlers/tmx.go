@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"bytes"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"translator-web/middleware"
+	"translator-web/translator"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TMXHandler 将已完成任务的原文和译文导出为翻译记忆（TMX）
+// 可选参数 srcLang 指定源语言代码，默认使用任务的源语言，未指定时为 en
+func TMXHandler(c *gin.Context) {
+	sessionID := middleware.GetSessionID(c)
+	if sessionID == "" {
+		respondError(c, errInvalidSession)
+		return
+	}
+
+	taskID := c.Param("taskId")
+	task, exists := taskManager.GetTask(sessionID, taskID)
+	if !exists {
+		respondError(c, notFound("任务不存在或无权访问"))
+		return
+	}
+	if task.Status != "completed" {
+		respondError(c, newAPIError(http.StatusBadRequest, CodeTaskNotReady, "任务未完成"))
+		return
+	}
+
+	srcLang := c.Query("srcLang")
+	if srcLang == "" {
+		srcLang = task.SourceLanguage
+	}
+	if srcLang == "" {
+		srcLang = "en"
+	}
+
+	var buf bytes.Buffer
+	if err := translator.ExportTMX(&buf, task.Translations, translator.TMXLanguage(srcLang), translator.TMXLanguage(task.TargetLanguage)); err != nil {
+		respondError(c, internalError(err.Error()))
+		return
+	}
+
+	baseName := strings.TrimSuffix(task.SourceFile, filepath.Ext(task.SourceFile))
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": "translated_" + baseName + ".tmx"}))
+	c.Data(http.StatusOK, "application/x-tmx+xml; charset=utf-8", buf.Bytes())
+}
+
+// readTMX 读取上传的 TMX 文件
+func readTMX(file *multipart.FileHeader) (map[string]string, error) {
+	src, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+	return translator.ImportTMX(src)
+}
@@ -1,12 +1,20 @@
 package handlers
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"maps"
 	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -44,6 +52,54 @@ func (tm *TaskManager) AddTask(sessionID string, task *models.TranslateTask) {
 	tm.userTasks[sessionID][task.ID] = task
 }
 
+// taskDedupWindow 已完成任务在此时间内可被相同的提交复用
+const taskDedupWindow = 10 * time.Minute
+
+// FindExistingTask 查找会话中与请求哈希相同、仍在进行或最近完成的任务
+func (tm *TaskManager) FindExistingTask(sessionID, hash string) (string, bool) {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+
+	return tm.findExistingTaskLocked(sessionID, hash, true)
+}
+
+// AddTaskUnlessDuplicate 原子地查找相同任务，不存在时添加新任务
+// 返回实际使用的任务ID，以及是否复用了已有任务
+func (tm *TaskManager) AddTaskUnlessDuplicate(sessionID string, task *models.TranslateTask, includeCompleted bool) (string, bool) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	if taskID, ok := tm.findExistingTaskLocked(sessionID, task.RequestHash, includeCompleted); ok {
+		return taskID, true
+	}
+	if tm.userTasks[sessionID] == nil {
+		tm.userTasks[sessionID] = make(map[string]*models.TranslateTask)
+	}
+	tm.userTasks[sessionID][task.ID] = task
+	return task.ID, false
+}
+
+// findExistingTaskLocked 查找相同哈希的任务（调用方需持有锁）
+func (tm *TaskManager) findExistingTaskLocked(sessionID, hash string, includeCompleted bool) (string, bool) {
+	if hash == "" {
+		return "", false
+	}
+	for _, task := range tm.userTasks[sessionID] {
+		if task.RequestHash != hash {
+			continue
+		}
+		switch task.Status {
+		case "pending", "queued", "processing":
+			return task.ID, true
+		case "completed":
+			if includeCompleted && time.Since(task.CompletedAt) < taskDedupWindow {
+				return task.ID, true
+			}
+		}
+	}
+	return "", false
+}
+
 // GetTask 获取用户的特定任务
 func (tm *TaskManager) GetTask(sessionID, taskID string) (*models.TranslateTask, bool) {
 	tm.mu.RLock()
@@ -56,6 +112,49 @@ func (tm *TaskManager) GetTask(sessionID, taskID string) (*models.TranslateTask,
 	return nil, false
 }
 
+// TaskSnapshot 在读锁保护下复制用户的特定任务
+// 后台协程会在持有锁时修改任务，需要读取进行中任务的字段时使用副本而不是 GetTask 返回的任务
+func (tm *TaskManager) TaskSnapshot(sessionID, taskID string) (models.TranslateTask, bool) {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+
+	task, found := tm.userTasks[sessionID][taskID]
+	if !found {
+		return models.TranslateTask{}, false
+	}
+	snapshot := *task
+	snapshot.FailedBlocks = slices.Clone(task.FailedBlocks)
+	snapshot.Artifacts = maps.Clone(task.Artifacts)
+	snapshot.Checksums = maps.Clone(task.Checksums)
+	snapshot.Translations = maps.Clone(task.Translations)
+	snapshot.Divergent = slices.Clone(task.Divergent)
+	snapshot.Fallbacks = slices.Clone(task.Fallbacks)
+	snapshot.LowConfidence = slices.Clone(task.LowConfidence)
+	snapshot.SubTasks = maps.Clone(task.SubTasks)
+	if task.Stats != nil {
+		stats := *task.Stats
+		snapshot.Stats = &stats
+	}
+	if task.Usage != nil {
+		usage := *task.Usage
+		snapshot.Usage = &usage
+	}
+	return snapshot, true
+}
+
+// FindTask 在所有会话中按任务 ID 查找任务（用于共享链接等不依赖会话的访问）
+func (tm *TaskManager) FindTask(taskID string) (*models.TranslateTask, bool) {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+
+	for _, userTasks := range tm.userTasks {
+		if task, found := userTasks[taskID]; found {
+			return task, true
+		}
+	}
+	return nil, false
+}
+
 // GetUserTasks 获取用户的所有任务
 func (tm *TaskManager) GetUserTasks(sessionID string) []*models.TranslateTask {
 	tm.mu.RLock()
@@ -90,50 +189,85 @@ func TranslateHandler(c *gin.Context) {
 	// 获取会话 ID
 	sessionID := middleware.GetSessionID(c)
 	if sessionID == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的会话"})
+		respondError(c, errInvalidSession)
 		return
 	}
 
 	// 解析表单
-	file, err := c.FormFile("file")
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "未找到上传文件"})
+	file, ext, ok := uploadedDocument(c)
+	if !ok {
 		return
 	}
 
-	// 检查文件类型
-	ext := strings.ToLower(filepath.Ext(file.Filename))
-	if ext != ".epub" && ext != ".pdf" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "只支持 .epub 和 .pdf 文件"})
+	// 解析配置
+	var req models.TranslateRequest
+	if !bindTranslateForm(c, &req) {
 		return
 	}
 
-	// 检查文件大小（100MB限制）
-	const MaxFileSize = 100 * 1024 * 1024 // 100MB
-	if file.Size > MaxFileSize {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "文件过大，最大支持100MB"})
+	// 验证必填字段，targetLanguages 只有一种语言时按单一目标语言处理
+	switch len(req.TargetLanguages) {
+	case 0:
+	case 1:
+		req.TargetLanguage = req.TargetLanguages[0]
+		req.TargetLanguages = nil
+	default:
+		req.TargetLanguage = ""
+	}
+	languages := targetLanguages(req)
+	if len(languages) == 0 || languages[0] == "" {
+		respondError(c, badRequest("目标语言不能为空"))
 		return
 	}
 
-	// 解析配置
-	var req models.TranslateRequest
-	req.TargetLanguage = c.PostForm("targetLanguage")
-	req.UserPrompt = c.PostForm("userPrompt")
-	req.ForceRetranslate = c.PostForm("forceRetranslate") == "true"
-	req.GenerateMode = c.PostForm("generateMode") // 新增：生成模式
+	if err := translator.ValidateFormality(req.Formality); err != nil {
+		respondError(c, badRequest(err.Error()))
+		return
+	}
 
-	// 解析 LLM 配置
-	llmConfigStr := c.PostForm("llmConfig")
-	if llmConfigStr != "" {
-		if err := json.Unmarshal([]byte(llmConfigStr), &req.LLMConfig); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "LLM 配置格式错误: " + err.Error()})
+	if _, err := newBlockFilter(req.BlockFilter); err != nil {
+		respondError(c, badRequest(err.Error()))
+		return
+	}
+
+	// 校验输出格式是否适用于该文件类型
+	docType := documentTypeForExt(ext)
+	if err := translator.ValidateOutputFormats(docType, req.OutputFormats); err != nil {
+		respondError(c, badRequest(err.Error()))
+		return
+	}
+	if _, err := translator.ParseHTMLLayout(req.HTMLLayout); err != nil {
+		respondError(c, badRequest(err.Error()))
+		return
+	}
+	if _, err := translator.ParseMergeStrategy(req.MergeStrategy); err != nil {
+		respondError(c, badRequest(err.Error()))
+		return
+	}
+	if _, err := translator.ParseMatchMode(req.MatchMode); err != nil {
+		respondError(c, badRequest(err.Error()))
+		return
+	}
+	if _, err := translator.ParsePDFStrategy(req.Strategy); err != nil {
+		respondError(c, badRequest(err.Error()))
+		return
+	}
+	for _, column := range req.CSVColumns {
+		if index, err := strconv.Atoi(column); err == nil && index < 0 {
+			respondError(c, badRequest(fmt.Sprintf("CSV 列号不能为负数: %d", index)))
 			return
 		}
 	}
-
-	// 验证必填字段
-	if req.TargetLanguage == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "目标语言不能为空"})
+	if _, err := translator.ParseTextExtractor(req.TextExtractor); err != nil {
+		respondError(c, badRequest(err.Error()))
+		return
+	}
+	if _, err := translator.ParseRegenerationFailurePolicy(req.OnRegenerationFailure); err != nil {
+		respondError(c, badRequest(err.Error()))
+		return
+	}
+	if _, err := translator.ParseGranularity(req.Granularity); err != nil {
+		respondError(c, badRequest(err.Error()))
 		return
 	}
 
@@ -141,40 +275,41 @@ func TranslateHandler(c *gin.Context) {
 	if req.GenerateMode == "" {
 		req.GenerateMode = "bilingual" // 默认双语
 	}
-	if req.LLMConfig.Provider == "" {
-		req.LLMConfig.Provider = "openai" // 默认使用 OpenAI
-	}
-	if req.LLMConfig.APIURL == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "API URL 不能为空"})
+	if err := normalizeLLMConfig(&req.LLMConfig); err != nil {
+		respondError(c, badRequest(err.Error()))
 		return
 	}
-	// 如果 Model 为空，尝试从 URL 中提取或使用默认值
-	if req.LLMConfig.Model == "" {
-		// 为不同提供商设置默认模型
-		switch req.LLMConfig.Provider {
-		case "openai":
-			req.LLMConfig.Model = "gpt-3.5-turbo"
-		case "claude":
-			req.LLMConfig.Model = "claude-3-5-sonnet-20241022"
-		case "gemini":
-			req.LLMConfig.Model = "gemini-pro"
-		case "deepseek":
-			req.LLMConfig.Model = "deepseek-chat"
-		case "ollama":
-			req.LLMConfig.Model = "llama2"
-		case "custom":
-			// 自定义提供商允许空模型（某些 API 可能不需要）
-			req.LLMConfig.Model = "default"
-		default:
-			req.LLMConfig.Model = "gpt-3.5-turbo"
+	for _, lang := range languages {
+		if apiErr := validateLanguages(req.LLMConfig, req.Formality, lang); apiErr != nil {
+			respondError(c, apiErr)
+			return
 		}
 	}
-	// 本地模型（Ollama、NLTranslator 等）不需要 API Key
-	needsAPIKey := req.LLMConfig.Provider != "ollama" &&
-		req.LLMConfig.Provider != "nltranslator"
+	req.Concurrency, req.BatchSize = translator.ClampThroughput(translator.ProviderType(req.LLMConfig.Provider), req.Concurrency, req.BatchSize)
 
-	if needsAPIKey && req.LLMConfig.APIKey == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "API Key 不能为空"})
+	// 文档的主要语言已是目标语言时（如重复上传译文），需要 force 确认后才翻译，避免重复花费
+	if !req.Force {
+		lang := uploadLanguage(c, file, ext)
+		for _, target := range languages {
+			if translator.IsTargetLanguage(lang, target) {
+				respondError(c, newAPIError(http.StatusUnprocessableEntity, CodeAlreadyTarget,
+					fmt.Sprintf("文档的主要语言（%s）已是目标语言，确认仍要翻译时请设置 force=true", lang)),
+					gin.H{"detectedLanguage": lang})
+				return
+			}
+		}
+	}
+
+	// 计算请求哈希，用于识别重复提交（如重复点击）
+	requestHash, err := taskRequestHash(file, req)
+	if err != nil {
+		respondError(c, internalError("读取上传文件失败: "+err.Error()))
+		return
+	}
+
+	// 多个目标语言时创建父任务，每种语言一个子任务
+	if len(languages) > 1 {
+		submitFanOut(c, sessionID, file, ext, req, requestHash)
 		return
 	}
 
@@ -188,51 +323,446 @@ func TranslateHandler(c *gin.Context) {
 		Status:         "pending",
 		Progress:       0,
 		CreatedAt:      time.Now(),
+		RequestHash:    requestHash,
+		Concurrency:    req.Concurrency,
+		BatchSize:      req.BatchSize,
+		Streaming:      req.Stream,
+		SourceLanguage: sourceLanguage(req.LLMConfig),
+	}
+
+	// 添加到任务管理器，相同的任务正在进行或刚完成时直接返回已有任务
+	// 强制重新翻译时只复用正在进行的任务
+	if existingID, duplicate := taskManager.AddTaskUnlessDuplicate(sessionID, task, !req.ForceRetranslate); duplicate {
+		log.Printf("检测到重复提交，复用任务 %s", existingID)
+		c.JSON(http.StatusOK, gin.H{
+			"taskId":    existingID,
+			"message":   "相同的翻译任务已存在",
+			"duplicate": true,
+		})
+		return
+	}
+
+	sourcePath, ok := saveSource(c, sessionID, taskID, file, ext)
+	if !ok {
+		return
+	}
+
+	// 记录上传文件和翻译配置，供按文本块重新翻译时使用
+	taskManager.UpdateTask(sessionID, taskID, func(t *models.TranslateTask) {
+		t.SourcePath = sourcePath
+		t.Request = &req
+	})
+
+	// 流式任务在排队期间即可连接 /api/stream/:taskId
+	if req.Stream {
+		openTaskStream(taskID)
 	}
 
-	// 添加到任务管理器
-	taskManager.AddTask(sessionID, task)
+	// 提交到任务队列，超出并发上限时排队等待
+	position := taskQueue.Submit(sessionID, taskID, func() {
+		// 超时从任务开始执行时计算，不包含排队等待的时间
+		ctx, cancel := taskContext(req)
+		defer cancel()
+		processTranslation(ctx, sessionID, taskID, file.Filename, sourcePath, req, nil)
+	})
 
+	c.JSON(http.StatusOK, gin.H{
+		"taskId":        taskID,
+		"message":       "翻译任务已创建",
+		"queuePosition": position,
+	})
+}
+
+// saveSource 将上传的文档保存到用户的上传目录，失败时将任务标记为失败并已写入错误响应
+func saveSource(c *gin.Context, sessionID, taskID string, file *uploadedFile, ext string) (string, bool) {
 	// 为用户创建独立的目录
-	userDir := filepath.Join("data", "users", sessionID)
-	uploadDir := filepath.Join(userDir, "uploads")
+	uploadDir := filepath.Join("data", "users", sessionID, "uploads")
 	if err := os.MkdirAll(uploadDir, 0755); err != nil {
 		taskManager.UpdateTask(sessionID, taskID, func(t *models.TranslateTask) {
 			t.Status = "failed"
 			t.Error = "创建上传目录失败: " + err.Error()
+			t.ErrorCode = CodeInternal
 		})
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "创建上传目录失败: " + err.Error()})
-		return
+		respondError(c, internalError("创建上传目录失败: "+err.Error()))
+		return "", false
 	}
 
 	// 根据文件类型确定保存路径
 	sourcePath := filepath.Join(uploadDir, taskID+ext)
-	if err := c.SaveUploadedFile(file, sourcePath); err != nil {
+	if err := file.SaveTo(sourcePath); err != nil {
 		taskManager.UpdateTask(sessionID, taskID, func(t *models.TranslateTask) {
 			t.Status = "failed"
 			t.Error = "保存文件失败: " + err.Error()
+			t.ErrorCode = CodeInternal
 		})
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "保存文件失败: " + err.Error()})
-		return
+		respondError(c, internalError("保存文件失败: "+err.Error()))
+		return "", false
 	}
+	return sourcePath, true
+}
 
-	// 启动后台翻译任务
-	go processTranslation(sessionID, taskID, sourcePath, req)
+// uploadedDocument 读取上传的文档并检查类型和大小，失败时已写入错误响应
+// 表单字段 uploadId 不为空时使用已完成的分块上传，否则读取文件字段 file
+func uploadedDocument(c *gin.Context) (*uploadedFile, string, bool) {
+	var file *uploadedFile
+	if uploadID := c.PostForm("uploadId"); uploadID != "" {
+		upload, ok := completedUpload(middleware.GetSessionID(c), uploadID)
+		if !ok {
+			respondError(c, notFound("上传不存在、未完成或已过期"))
+			return nil, "", false
+		}
+		file = upload
+	} else {
+		header, err := c.FormFile("file")
+		if err != nil {
+			respondError(c, badRequest("未找到上传文件"))
+			return nil, "", false
+		}
+		file = &uploadedFile{Filename: header.Filename, Size: header.Size, header: header}
+	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"taskId":  taskID,
-		"message": "翻译任务已创建",
-	})
+	// 检查文件类型
+	ext := strings.ToLower(filepath.Ext(file.Filename))
+	if !supportedDocumentExt(ext) {
+		respondError(c, newAPIError(http.StatusUnsupportedMediaType, CodeUnsupportedFormat, "只支持 .epub、.pdf、.pptx、.csv、.tsv、.mobi 和 .azw3 文件"))
+		return nil, "", false
+	}
+
+	// 检查文件大小（上限由 MAX_UPLOAD_MB 设置）
+	if file.Size > maxUploadSize {
+		respondError(c, fileTooLargeError())
+		return nil, "", false
+	}
+
+	// Kindle 电子书需要先转换为 EPUB，服务端没有转换工具时在创建任务前拒绝
+	if translator.IsKindleExt(ext) && !translator.EbookConverterAvailable() {
+		respondError(c, newAPIError(http.StatusUnsupportedMediaType, CodeUnsupportedFormat, translator.ErrEbookConverterUnavailable.Error()))
+		return nil, "", false
+	}
+
+	// 加密的 PDF 无法提取文本，在创建任务前拒绝
+	if ext == ".pdf" {
+		switch err := checkPDFEncryption(file); {
+		case errors.Is(err, translator.ErrEncryptedPDF):
+			respondError(c, newAPIError(http.StatusUnprocessableEntity, CodeEncryptedPDF, err.Error()))
+			return nil, "", false
+		case err != nil:
+			respondError(c, internalError("读取上传文件失败: "+err.Error()))
+			return nil, "", false
+		}
+	}
+
+	return file, ext, true
+}
+
+// uploadLanguage 检测上传文档的主要语言，检测失败或 Kindle 电子书（需要先转换）时返回空字符串
+func uploadLanguage(c *gin.Context, file *uploadedFile, ext string) string {
+	if translator.IsKindleExt(ext) {
+		return ""
+	}
+	uploadDir := filepath.Join("data", "users", middleware.GetSessionID(c), "uploads")
+	if err := os.MkdirAll(uploadDir, 0755); err != nil {
+		return ""
+	}
+	path := filepath.Join(uploadDir, "detect-"+uuid.New().String()+ext)
+	defer os.Remove(path)
+	if err := file.SaveTo(path); err != nil {
+		return ""
+	}
+	lang, err := translator.DetectDocumentLanguage(path)
+	if err != nil {
+		log.Printf("检测上传文档的语言失败: %v", err)
+		return ""
+	}
+	return lang
+}
+
+// documentTypeForExt 扩展名对应的文档类型，Kindle 电子书按转换后的 EPUB 处理
+func documentTypeForExt(ext string) translator.DocumentType {
+	if translator.IsKindleExt(ext) {
+		return translator.DocumentTypeEPUB
+	}
+	return translator.DocumentType(strings.TrimPrefix(ext, "."))
+}
+
+// convertKindleSource 将上传的 Kindle 电子书转换为同名的 EPUB 并删除原文件，其余文件原样返回
+func convertKindleSource(ctx context.Context, sourcePath string) (string, error) {
+	if !translator.IsKindleExt(filepath.Ext(sourcePath)) {
+		return sourcePath, nil
+	}
+	epubPath, err := translator.ConvertEbookToEPUB(ctx, sourcePath)
+	if err != nil {
+		return "", err
+	}
+	os.Remove(sourcePath)
+	return epubPath, nil
+}
+
+// bindTranslateForm 从表单解析翻译配置，失败时已写入错误响应
+func bindTranslateForm(c *gin.Context, req *models.TranslateRequest) bool {
+	req.TargetLanguage = c.PostForm("targetLanguage")
+	if value := c.PostForm("targetLanguages"); value != "" {
+		languages, err := parseTargetLanguages(value)
+		if err != nil {
+			respondError(c, badRequest(err.Error()))
+			return false
+		}
+		req.TargetLanguages = languages
+	}
+	req.UserPrompt = c.PostForm("userPrompt")
+	req.ForceRetranslate = c.PostForm("forceRetranslate") == "true"
+	req.Force = c.PostForm("force") == "true"
+	req.Stream = c.PostForm("stream") == "true"
+	req.BackCheck = c.PostForm("backTranslateCheck") == "true"
+	req.GenerateMode = c.PostForm("generateMode") // 新增：生成模式
+	req.OutputFormats = translator.ParseOutputFormats(c.PostForm("outputFormats"))
+	req.HTMLLayout = c.PostForm("htmlLayout")
+	req.Formality = c.PostForm("formality")
+	req.VerticalText = c.PostForm("verticalText") == "true"
+	req.MergeStrategy = c.PostForm("mergeStrategy")
+	req.MatchMode = c.PostForm("matchMode")
+	req.Strategy = c.PostForm("strategy")
+	req.DiffOverlay = c.PostForm("diffOverlay") == "true"
+	req.TextExtractor = c.PostForm("textExtractor")
+	req.OnRegenerationFailure = c.PostForm("onRegenerationFailure")
+	req.SelfRateConfidence = c.PostForm("selfRateConfidence") == "true"
+	req.MarkLowConfidence = c.PostForm("markLowConfidence") == "true"
+	req.Granularity = c.PostForm("granularity")
+	req.CSVColumns = translator.ParseCSVColumns(c.PostForm("csvColumns"))
+	req.CSVHeader = c.PostForm("csvHeader") == "true"
+	if str := c.PostForm("confidenceThreshold"); str != "" {
+		threshold, err := strconv.ParseFloat(str, 64)
+		if err != nil || threshold < 0 || threshold > 1 {
+			respondError(c, badRequest("confidenceThreshold 必须是 0 到 1 之间的数"))
+			return false
+		}
+		req.ConfidenceThreshold = threshold
+	}
+
+	// 解析文本块过滤规则
+	if filterStr := c.PostForm("blockFilter"); filterStr != "" {
+		req.BlockFilter = &models.BlockFilterConfig{}
+		if err := json.Unmarshal([]byte(filterStr), req.BlockFilter); err != nil {
+			respondError(c, badRequest("文本块过滤规则格式错误: "+err.Error()))
+			return false
+		}
+	}
+
+	// 解析并发数、批大小和超时
+	for _, field := range []struct {
+		name  string
+		value *int
+	}{{"concurrency", &req.Concurrency}, {"batchSize", &req.BatchSize}, {"timeout", &req.Timeout}} {
+		str := c.PostForm(field.name)
+		if str == "" {
+			continue
+		}
+		n, err := strconv.Atoi(str)
+		if err != nil || n < 0 {
+			respondError(c, badRequest(field.name+" 必须是非负整数"))
+			return false
+		}
+		*field.value = n
+	}
+
+	// 解析图表标签词译法
+	if labelsStr := c.PostForm("captionLabels"); labelsStr != "" {
+		if err := json.Unmarshal([]byte(labelsStr), &req.CaptionLabels); err != nil {
+			respondError(c, badRequest("图表标签译法格式错误: "+err.Error()))
+			return false
+		}
+	}
+
+	// 解析翻译记忆（TMX），翻译前写入缓存
+	if tmxFile, err := c.FormFile("tmx"); err == nil {
+		pairs, err := readTMX(tmxFile)
+		if err != nil {
+			respondError(c, badRequest("翻译记忆文件格式错误: "+err.Error()))
+			return false
+		}
+		req.TMX = pairs
+	}
+
+	// 解析 LLM 配置，未提供时使用会话已保存的配置
+	if llmConfigStr := c.PostForm("llmConfig"); llmConfigStr != "" {
+		if err := json.Unmarshal([]byte(llmConfigStr), &req.LLMConfig); err != nil {
+			respondError(c, badRequest("LLM 配置格式错误: "+err.Error()))
+			return false
+		}
+	}
+	return storedLLMConfig(c, &req.LLMConfig)
+}
+
+// normalizeLLMConfig 补全提供商和模型的默认值，并校验必填项
+func normalizeLLMConfig(cfg *models.LLMConfig) error {
+	if cfg.Provider == "" {
+		cfg.Provider = "openai" // 默认使用 OpenAI
+	}
+	if cfg.APIURL == "" {
+		return fmt.Errorf("API URL 不能为空")
+	}
+	// 如果 Model 为空，尝试从 URL 中提取或使用默认值
+	if cfg.Model == "" {
+		// 为不同提供商设置默认模型
+		switch cfg.Provider {
+		case "openai":
+			cfg.Model = "gpt-3.5-turbo"
+		case "claude":
+			cfg.Model = "claude-3-5-sonnet-20241022"
+		case "gemini":
+			cfg.Model = "gemini-pro"
+		case "deepseek":
+			cfg.Model = "deepseek-chat"
+		case "ollama":
+			cfg.Model = "llama2"
+		case "custom", "yandex", "tencent":
+			// 自定义提供商和机器翻译服务允许空模型
+			cfg.Model = "default"
+		default:
+			cfg.Model = "gpt-3.5-turbo"
+		}
+	}
+	// 本地模型（Ollama、NLTranslator 等）不需要 API Key
+	needsAPIKey := cfg.Provider != "ollama" &&
+		cfg.Provider != "nltranslator"
+
+	if needsAPIKey && cfg.APIKey == "" {
+		return fmt.Errorf("API Key 不能为空")
+	}
+
+	// 校验自定义提示词模板
+	if tmpl := cfg.Extra[translator.PromptTemplateKey]; tmpl != "" {
+		if _, err := translator.ParsePromptTemplate(tmpl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newProviderConfig 根据请求中的 LLM 配置创建提供商配置
+func newProviderConfig(cfg models.LLMConfig, formality string) translator.ProviderConfig {
+	return translator.ProviderConfig{
+		Type:        translator.ProviderType(cfg.Provider),
+		APIKey:      cfg.APIKey,
+		APIURL:      cfg.APIURL,
+		Model:       cfg.Model,
+		Temperature: cfg.Temperature,
+		MaxTokens:   cfg.MaxTokens,
+		Extra:       cfg.Extra,
+		Formality:   formality,
+
+		CACert:             cfg.CACert,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+
+		MaxIdleConnsPerHost: cfg.MaxIdleConnsPerHost,
+		MaxConnsPerHost:     cfg.MaxConnsPerHost,
+		IdleConnTimeout:     cfg.IdleConnTimeout,
+	}
+}
+
+// validateLanguages 校验提供商是否支持目标语言和源语言（Extra["sourceLanguage"]）
+func validateLanguages(cfg models.LLMConfig, formality, targetLanguage string) *APIError {
+	provider, err := translator.NewProvider(newProviderConfig(cfg, formality), nil)
+	if err != nil {
+		return badRequest(err.Error())
+	}
+	if err := translator.ValidateLanguagePair(provider, targetLanguage, cfg.Extra["sourceLanguage"]); err != nil {
+		return newAPIError(http.StatusBadRequest, CodeUnsupportedLanguage, err.Error())
+	}
+	return nil
+}
+
+// sourceLanguage 返回配置中指定的源语言，未指定或自动检测时返回空字符串
+func sourceLanguage(cfg models.LLMConfig) string {
+	if lang := cfg.Extra["sourceLanguage"]; !strings.EqualFold(lang, "auto") {
+		return lang
+	}
+	return ""
+}
+
+// withSourceLanguage 将源语言写入提供商配置的 Extra["sourceLanguage"]
+func withSourceLanguage(config translator.ProviderConfig, lang string) translator.ProviderConfig {
+	extra := make(map[string]string, len(config.Extra)+1)
+	for k, v := range config.Extra {
+		extra[k] = v
+	}
+	extra["sourceLanguage"] = lang
+	config.Extra = extra
+	return config
+}
+
+// taskRequestHash 计算上传文件内容与翻译配置的哈希
+func taskRequestHash(file *uploadedFile, req models.TranslateRequest) (string, error) {
+	src, err := file.Open()
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, src); err != nil {
+		return "", err
+	}
+
+	config, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+	hasher.Write([]byte{0})
+	hasher.Write(config)
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// newBlockFilter 根据请求配置创建文本块过滤器，未设置的字段使用默认值
+func newBlockFilter(cfg *models.BlockFilterConfig) (*translator.BlockFilter, error) {
+	filter := translator.NewBlockFilter()
+	if cfg == nil {
+		return filter, nil
+	}
+
+	if cfg.MinLength != nil {
+		if *cfg.MinLength < 0 {
+			return nil, fmt.Errorf("最小字符数不能为负数")
+		}
+		filter.WithMinLength(*cfg.MinLength)
+	}
+	if cfg.MinWords != nil {
+		if *cfg.MinWords < 0 {
+			return nil, fmt.Errorf("最少单词数不能为负数")
+		}
+		filter.WithMinWords(*cfg.MinWords)
+	}
+	if cfg.SkipPureNumbers != nil {
+		filter.SkipPureNumbers = *cfg.SkipPureNumbers
+	}
+	if cfg.SkipCitations != nil {
+		filter.SkipCitations = *cfg.SkipCitations
+	}
+	if err := filter.AddSkipPatterns(cfg.SkipPatterns...); err != nil {
+		return nil, err
+	}
+	return filter, nil
 }
 
 // processTranslation 处理翻译任务
-func processTranslation(sessionID, taskID, sourcePath string, req models.TranslateRequest) {
+// source 不为空时与同一文档的其他目标语言共享解析结果
+func processTranslation(ctx context.Context, sessionID, taskID, originalName, sourcePath string, req models.TranslateRequest, source *translator.SourceCache) {
+	// 流式任务结束时写入结束标记（在 panic 恢复之后执行，以便读取最终状态）
+	stream, streaming := getTaskStream(taskID)
+	if streaming {
+		defer finishTaskStream(sessionID, taskID, stream)
+	}
+	defer observeTaskDuration(sessionID, taskID, time.Now())
+
 	taskManager.UpdateTask(sessionID, taskID, func(t *models.TranslateTask) {
 		t.Status = "processing"
 	})
 
 	log.Printf("[会话 %s][任务 %s] 开始处理翻译", sessionID[:8], taskID)
 
+	// 已完成的译文（原文 -> 译文），任务中途失败时用于生成部分结果
+	translations := make(map[string]string)
+
 	defer func() {
 		if r := recover(); r != nil {
 			errorMsg := fmt.Sprintf("%v", r)
@@ -242,27 +772,38 @@ func processTranslation(sessionID, taskID, sourcePath string, req models.Transla
 				errorMsg = "PDF文件格式不兼容。此PDF可能使用了特殊编码、加密或压缩方式。建议：\n1. 使用其他PDF工具（如Adobe Acrobat、PDFtk等）重新保存该文件\n2. 确保PDF未加密且可以正常复制文本\n3. 尝试将PDF转换为标准格式后再上传"
 			}
 
-			taskManager.UpdateTask(sessionID, taskID, func(t *models.TranslateTask) {
-				t.Status = "failed"
-				t.Error = errorMsg
-			})
+			failWithPartialResult(sessionID, taskID, originalName, sourcePath, translations, errorMsg, taskErrorCode(errorMsg, nil))
 			log.Printf("[会话 %s][任务 %s] 翻译失败（panic）: %v", sessionID[:8], taskID, r)
 		}
 	}()
 
-	// 为每个用户创建独立的缓存目录
-	userCacheDir := filepath.Join("data", "users", sessionID, "cache")
-	if err := os.MkdirAll(userCacheDir, 0755); err != nil {
+	// Kindle 电子书先转换为 EPUB，之后按 EPUB 翻译和输出
+	converted, err := convertKindleSource(ctx, sourcePath)
+	if err != nil {
 		taskManager.UpdateTask(sessionID, taskID, func(t *models.TranslateTask) {
 			t.Status = "failed"
-			t.Error = "创建缓存目录失败: " + err.Error()
+			t.Error = err.Error()
+			t.ErrorCode = CodeInvalidDocument
 		})
-		log.Printf("[会话 %s][任务 %s] 创建缓存目录失败: %v", sessionID[:8], taskID, err)
+		log.Printf("[会话 %s][任务 %s] 转换电子书失败: %v", sessionID[:8], taskID, err)
 		return
 	}
+	if converted != sourcePath {
+		sourcePath = converted
+		taskManager.UpdateTask(sessionID, taskID, func(t *models.TranslateTask) {
+			t.SourcePath = sourcePath
+		})
+		log.Printf("[会话 %s][任务 %s] 已将电子书转换为 EPUB: %s", sessionID[:8], taskID, sourcePath)
+	}
+
+	// 为每个用户创建独立的缓存目录（目录不可写时自动回退到内存缓存）
+	userCacheDir := filepath.Join("data", "users", sessionID, "cache")
 
 	log.Printf("[会话 %s][任务 %s] 创建翻译客户端，提供商: %s, 模型: %s", sessionID[:8], taskID, req.LLMConfig.Provider, req.LLMConfig.Model)
 	cache, _ := translator.NewCache(userCacheDir)
+	if cache.IsMemoryFallback() {
+		log.Printf("[会话 %s][任务 %s] 缓存目录不可写，使用内存缓存", sessionID[:8], taskID)
+	}
 
 	// 如果强制重新翻译，禁用缓存读取（但仍然写入缓存）
 	if req.ForceRetranslate {
@@ -270,48 +811,129 @@ func processTranslation(sessionID, taskID, sourcePath string, req models.Transla
 		cache.DisableCache()
 	}
 
-	providerConfig := translator.ProviderConfig{
-		Type:        translator.ProviderType(req.LLMConfig.Provider),
-		APIKey:      req.LLMConfig.APIKey,
-		APIURL:      req.LLMConfig.APIURL,
-		Model:       req.LLMConfig.Model,
-		Temperature: req.LLMConfig.Temperature,
-		MaxTokens:   req.LLMConfig.MaxTokens,
-		Extra:       req.LLMConfig.Extra,
+	providerConfig := newProviderConfig(req.LLMConfig, req.Formality)
+	providerConfig.SelfRateConfidence = req.SelfRateConfidence
+
+	// 未指定源语言时按整篇文档检测一次主要语言，避免逐块检测的结果不一致，也便于需要显式源语言的提供商
+	if sourceLanguage(req.LLMConfig) == "" {
+		if lang, err := source.DetectLanguage(sourcePath); err != nil {
+			log.Printf("[会话 %s][任务 %s] 检测文档语言失败: %v", sessionID[:8], taskID, err)
+		} else if lang != "" {
+			providerConfig = withSourceLanguage(providerConfig, lang)
+			taskManager.UpdateTask(sessionID, taskID, func(t *models.TranslateTask) {
+				t.SourceLanguage = lang
+			})
+			log.Printf("[会话 %s][任务 %s] 检测到文档主要语言: %s", sessionID[:8], taskID, lang)
+		}
+	}
+
+	// 合并会话和全局术语表：写入提示词，并在译文中落实规定译法
+	glossary := taskGlossary(sessionID, req.TargetLanguage, req.LLMConfig.Extra["domain"])
+	if len(glossary) > 0 {
+		providerConfig = withGlossary(providerConfig, glossary)
+		log.Printf("[会话 %s][任务 %s] 使用术语表 %d 条", sessionID[:8], taskID, len(glossary))
+	}
+
+	// 只重新翻译指定文本块时，这些原文不读取缓存，新译文覆盖旧的缓存条目
+	var store translator.CacheStore = cache
+	if len(req.Retranslate) > 0 {
+		docType := translator.DocumentType(strings.TrimPrefix(strings.ToLower(filepath.Ext(sourcePath)), "."))
+		store = translator.BypassCacheFor(cache, providerConfig, docType, req.Retranslate, req.TargetLanguage, req.UserPrompt)
+		log.Printf("[会话 %s][任务 %s] 重新翻译 %d 个文本块", sessionID[:8], taskID, len(req.Retranslate))
 	}
 
 	// 创建统一文档翻译器
-	docTranslator, err := translator.NewDocumentTranslator(providerConfig, cache)
+	docTranslator, err := translator.NewDocumentTranslator(providerConfig, store)
 	if err != nil {
 		taskManager.UpdateTask(sessionID, taskID, func(t *models.TranslateTask) {
 			t.Status = "failed"
 			t.Error = "创建翻译客户端失败: " + err.Error()
+			t.ErrorCode = CodeInvalidRequest
 		})
 		log.Printf("[会话 %s][任务 %s] 创建客户端失败: %v", sessionID[:8], taskID, err)
 		return
 	}
+	docTranslator.Client.WithContext(ctx)
+	docTranslator.OutputFormats = req.OutputFormats
+	docTranslator.HTMLLayout, _ = translator.ParseHTMLLayout(req.HTMLLayout)
+	docTranslator.VerticalText = req.VerticalText
+	docTranslator.MergeStrategy, _ = translator.ParseMergeStrategy(req.MergeStrategy)
+	docTranslator.MatchMode, _ = translator.ParseMatchMode(req.MatchMode)
+	docTranslator.PDFStrategy, _ = translator.ParsePDFStrategy(req.Strategy)
+	docTranslator.DiffOverlay = req.DiffOverlay
+	docTranslator.TextExtractor = req.TextExtractor
+	docTranslator.OnRegenerationFailure, _ = translator.ParseRegenerationFailurePolicy(req.OnRegenerationFailure)
+	docTranslator.ConfidenceThreshold = confidenceThreshold(req)
+	docTranslator.MarkLowConfidence = req.MarkLowConfidence
+	docTranslator.CSVColumns = req.CSVColumns
+	docTranslator.CSVHeader = req.CSVHeader
+	docTranslator.Source = source
+	if filter, err := source.Filter(func() (*translator.BlockFilter, error) { return newBlockFilter(req.BlockFilter) }); err == nil {
+		docTranslator.Client.WithFilter(filter)
+	}
+	granularity, _ := translator.ParseGranularity(req.Granularity)
+	docTranslator.Client.WithCaptionLabels(req.CaptionLabels).WithThroughput(req.Concurrency, req.BatchSize).WithGlossary(glossary).WithGranularity(granularity)
+	if streaming {
+		chainResultHandler(docTranslator.Client, stream.appendResult)
+	}
+
+	// 记录译文，用于导出翻译记忆和失败时生成部分结果（结果回调已由翻译客户端串行化）
+	// 自评可信度低于阈值的文本块列入任务报告，供人工复核
+	var lowConfidence []models.LowConfidenceBlock
+	chainResultHandler(docTranslator.Client, func(result translator.TranslateResult) {
+		if result.Err == nil && result.Translated != "" && result.Translated != result.Original {
+			translations[result.Original] = result.Translated
+			if result.Confidence < docTranslator.ConfidenceThreshold {
+				lowConfidence = append(lowConfidence, models.LowConfidenceBlock{
+					Original:    result.Original,
+					Translation: result.Translated,
+					Confidence:  result.Confidence,
+				})
+			}
+		}
+	})
+
+	// 用上传的翻译记忆预先填充缓存
+	ext := strings.ToLower(filepath.Ext(sourcePath))
+	if len(req.TMX) > 0 {
+		if req.ForceRetranslate {
+			log.Printf("[会话 %s][任务 %s] 强制重新翻译模式下不读取缓存，翻译记忆不会生效", sessionID[:8], taskID)
+		}
+		docType := translator.DocumentType(strings.TrimPrefix(ext, "."))
+		primed := translator.PrimeCache(cache, providerConfig, docType, req.TMX, req.TargetLanguage, req.UserPrompt)
+		log.Printf("[会话 %s][任务 %s] 已从翻译记忆写入 %d/%d 条缓存", sessionID[:8], taskID, primed, len(req.TMX))
+	}
+
+	// 人工修改的译文直接使用，并写入缓存供之后的任务复用
+	if len(req.Edits) > 0 {
+		docTranslator.Client.WithEdits(req.Edits)
+		docType := translator.DocumentType(strings.TrimPrefix(ext, "."))
+		primed := translator.PrimeCache(cache, providerConfig, docType, req.Edits, req.TargetLanguage, req.UserPrompt)
+		log.Printf("[会话 %s][任务 %s] 使用 %d 处人工修改，已写入 %d 条缓存", sessionID[:8], taskID, len(req.Edits), primed)
+	}
 
 	// 确定输出路径
-	userOutputDir := filepath.Join("data", "users", sessionID, "outputs")
+	// PDF 输出为 PDF 文件，EPUB 和 PPTX 保持原格式
+	outputPath := OutputPath(sessionID, taskID, originalName, req.GenerateMode, ext)
+
+	// PDF 任务跟踪逐页进度，支持在完成前下载已翻译的页面
+	if ext == ".pdf" {
+		progress, untrack := trackPageProgress(sessionID, taskID, sourcePath)
+		defer untrack()
+		docTranslator.PageProgress = progress
+	}
+
+	userOutputDir := filepath.Dir(outputPath)
 	if err := os.MkdirAll(userOutputDir, 0755); err != nil {
 		taskManager.UpdateTask(sessionID, taskID, func(t *models.TranslateTask) {
 			t.Status = "failed"
 			t.Error = "创建输出目录失败: " + err.Error()
+			t.ErrorCode = CodeInternal
 		})
 		log.Printf("[会话 %s][任务 %s] 创建输出目录失败: %v", sessionID[:8], taskID, err)
 		return
 	}
 
-	ext := strings.ToLower(filepath.Ext(sourcePath))
-	var outputPath string
-	if ext == ".pdf" {
-		// PDF 默认输出为 PDF 文件
-		outputPath = filepath.Join(userOutputDir, taskID+".pdf")
-	} else {
-		// EPUB 保持原格式
-		outputPath = filepath.Join(userOutputDir, taskID+ext)
-	}
-
 	// 进度回调函数
 	progressCallback := func(progress float64) {
 		taskManager.UpdateTask(sessionID, taskID, func(t *models.TranslateTask) {
@@ -330,30 +952,152 @@ func processTranslation(sessionID, taskID, sourcePath string, req models.Transla
 			errorMsg = "PDF文件格式不兼容。此PDF可能使用了特殊编码、加密或压缩方式。建议：\n1. 使用其他PDF工具（如Adobe Acrobat、PDFtk等）重新保存该文件\n2. 确保PDF未加密且可以正常复制文本\n3. 尝试将PDF转换为标准格式后再上传"
 		}
 
-		taskManager.UpdateTask(sessionID, taskID, func(t *models.TranslateTask) {
-			t.Status = "failed"
-			t.Error = errorMsg
-		})
+		if errors.Is(err, context.DeadlineExceeded) {
+			timeoutWithPartialResult(sessionID, taskID, originalName, sourcePath, translations)
+			log.Printf("[会话 %s][任务 %s] 翻译超时: %v", sessionID[:8], taskID, err)
+			return
+		}
+		failWithPartialResult(sessionID, taskID, originalName, sourcePath, translations, errorMsg, taskErrorCode(errorMsg, err))
 		log.Printf("[会话 %s][任务 %s] 翻译失败: %v", sessionID[:8], taskID, err)
 		return
 	}
 
+	// 回译检查：将译文翻译回源语言，标记与原文差异较大的文本块
+	var divergent []models.DivergentBlock
+	if req.BackCheck && len(translations) > 0 {
+		log.Printf("[会话 %s][任务 %s] 开始回译检查 %d 个文本块", sessionID[:8], taskID, len(translations))
+		for _, check := range docTranslator.Client.BackTranslate(translations, sourceLanguage(req.LLMConfig), req.UserPrompt, translator.DefaultBackTranslationThreshold) {
+			divergent = append(divergent, models.DivergentBlock{
+				Original:        check.Original,
+				Translation:     check.Translation,
+				BackTranslation: check.BackTranslation,
+				Similarity:      check.Similarity,
+			})
+		}
+		log.Printf("[会话 %s][任务 %s] 回译检查完成，%d 个文本块与原文差异较大", sessionID[:8], taskID, len(divergent))
+	}
+
 	// 翻译完成
 	taskManager.UpdateTask(sessionID, taskID, func(t *models.TranslateTask) {
 		t.Status = "completed"
 		t.Progress = 1.0
 		t.CompletedAt = time.Now()
 		t.OutputPath = actualOutputPath // 使用实际的输出路径
+		t.Artifacts = docTranslator.Artifacts
+		t.Translations = translations
+		t.Divergent = divergent
+		t.LowConfidence = lowConfidence
+		t.Fallbacks = nil
+		for _, fallback := range docTranslator.Fallbacks {
+			t.Fallbacks = append(t.Fallbacks, models.ArtifactFallback{
+				Format:   fallback.Format,
+				Artifact: fallback.Artifact,
+				Policy:   fallback.Policy,
+				Error:    fallback.Error,
+			})
+		}
+		if usage := docTranslator.Client.TokenUsage(); usage.Requests > 0 {
+			t.Usage = &models.TokenUsage{InputTokens: usage.InputTokens, OutputTokens: usage.OutputTokens}
+		}
+		t.Stats = &models.TaskStats{
+			TotalBlocks:  docTranslator.Stats.TotalBlocks,
+			UniqueBlocks: docTranslator.Stats.UniqueBlocks,
+			SavedBlocks:  docTranslator.Stats.SavedBlocks,
+		}
+		t.FailedBlocks = nil
+		t.Refusals = 0
+		for _, result := range docTranslator.FailedBlocks {
+			t.FailedBlocks = append(t.FailedBlocks, models.FailedBlock{
+				Index: result.Index,
+				Error: result.Err.Error(),
+			})
+			if errors.Is(result.Err, translator.ErrRefusal) {
+				t.Refusals++
+			}
+		}
 	})
 
+	if len(docTranslator.FailedBlocks) > 0 {
+		log.Printf("[会话 %s][任务 %s] %d 个文本块翻译失败，已使用原文", sessionID[:8], taskID, len(docTranslator.FailedBlocks))
+	}
+	if len(lowConfidence) > 0 {
+		log.Printf("[会话 %s][任务 %s] %d 个文本块的自评可信度低于 %.2f，需要人工复核", sessionID[:8], taskID, len(lowConfidence), docTranslator.ConfidenceThreshold)
+	}
+	for _, fallback := range docTranslator.Fallbacks {
+		log.Printf("[会话 %s][任务 %s] %s 重新生成失败，已按 %s 改为生成 %s: %s", sessionID[:8], taskID, fallback.Format, fallback.Policy, fallback.Artifact, fallback.Error)
+	}
+
 	log.Printf("[会话 %s][任务 %s] 翻译完成: %s", sessionID[:8], taskID, actualOutputPath)
 }
 
+// confidenceThreshold 返回任务的低可信度阈值，未指定时使用默认值
+func confidenceThreshold(req models.TranslateRequest) float64 {
+	if req.ConfidenceThreshold > 0 {
+		return req.ConfidenceThreshold
+	}
+	return translator.DefaultConfidenceThreshold
+}
+
+// failWithPartialResult 将任务标记为失败
+// 已有部分译文时生成尽力而为的双语文本（未翻译的文本块保留原文），任务状态设为 partial
+func failWithPartialResult(sessionID, taskID, originalName, sourcePath string, translations map[string]string, errorMsg, errorCode string) {
+	partialPath := savePartialResult(sessionID, taskID, originalName, sourcePath, translations)
+
+	taskManager.UpdateTask(sessionID, taskID, func(t *models.TranslateTask) {
+		t.Error = errorMsg
+		t.ErrorCode = errorCode
+		if partialPath == "" {
+			t.Status = "failed"
+			return
+		}
+		t.Status = "partial"
+		t.CompletedAt = time.Now()
+		t.OutputPath = partialPath
+		t.Artifacts = map[string]string{string(translator.OutputFormatBilingualText): partialPath}
+	})
+}
+
+// timeoutWithPartialResult 将超过时限的任务标记为 timed_out，已有部分译文时同样生成部分结果供下载
+func timeoutWithPartialResult(sessionID, taskID, originalName, sourcePath string, translations map[string]string) {
+	partialPath := savePartialResult(sessionID, taskID, originalName, sourcePath, translations)
+
+	taskManager.UpdateTask(sessionID, taskID, func(t *models.TranslateTask) {
+		t.Status = "timed_out"
+		t.Error = "翻译超时，已停止未完成的请求"
+		t.ErrorCode = CodeTimeout
+		t.CompletedAt = time.Now()
+		if partialPath != "" {
+			t.OutputPath = partialPath
+			t.Artifacts = map[string]string{string(translator.OutputFormatBilingualText): partialPath}
+		}
+	})
+}
+
+// savePartialResult 用已完成的译文生成双语文本，没有译文或生成失败时返回空字符串
+func savePartialResult(sessionID, taskID, originalName, sourcePath string, translations map[string]string) string {
+	if len(translations) == 0 {
+		return ""
+	}
+
+	path := OutputPath(sessionID, taskID, originalName, "partial", ".txt")
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err == nil {
+		var translated, total int
+		translated, total, err = translator.SavePartialResult(sourcePath, path, translations)
+		if err == nil {
+			log.Printf("[会话 %s][任务 %s] 已生成部分翻译结果（%d/%d 个文本块）: %s", sessionID[:8], taskID, translated, total, path)
+			return path
+		}
+	}
+	log.Printf("[会话 %s][任务 %s] 生成部分翻译结果失败: %v", sessionID[:8], taskID, err)
+	return ""
+}
+
 // GetStatusHandler 获取任务状态
 func GetStatusHandler(c *gin.Context) {
 	sessionID := middleware.GetSessionID(c)
 	if sessionID == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的会话"})
+		respondError(c, errInvalidSession)
 		return
 	}
 
@@ -361,7 +1105,7 @@ func GetStatusHandler(c *gin.Context) {
 
 	task, exists := taskManager.GetTask(sessionID, taskID)
 	if !exists {
-		c.JSON(http.StatusNotFound, gin.H{"error": "任务不存在或无权访问"})
+		respondError(c, notFound("任务不存在或无权访问"))
 		return
 	}
 
@@ -372,7 +1116,7 @@ func GetStatusHandler(c *gin.Context) {
 func DownloadHandler(c *gin.Context) {
 	sessionID := middleware.GetSessionID(c)
 	if sessionID == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的会话"})
+		respondError(c, errInvalidSession)
 		return
 	}
 
@@ -380,50 +1124,129 @@ func DownloadHandler(c *gin.Context) {
 
 	task, exists := taskManager.GetTask(sessionID, taskID)
 	if !exists {
-		c.JSON(http.StatusNotFound, gin.H{"error": "任务不存在或无权访问"})
+		respondError(c, notFound("任务不存在或无权访问"))
 		return
 	}
 
-	if task.Status != "completed" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "任务未完成"})
+	// 多目标语言任务指定 language 时下载该语言子任务的输出，否则下载包含所有语言输出的 ZIP
+	if lang := c.Query("language"); lang != "" {
+		sub, ok := languageSubTask(sessionID, task, lang)
+		if !ok {
+			respondError(c, notFound("该任务没有此目标语言的翻译: "+lang))
+			return
+		}
+		task = sub
+	}
+
+	// 指定 upToPage 时只下载前 N 页已翻译完成的内容
+	if upToPage := c.Query("upToPage"); upToPage != "" {
+		servePartialDownload(c, task, upToPage)
 		return
 	}
 
-	// 检查文件是否存在
-	if _, err := os.Stat(task.OutputPath); err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "翻译文件不存在"})
+	outputPath, filename, ok := resolveDownload(c, task)
+	if !ok {
 		return
 	}
 
-	// 设置下载文件名（根据实际输出文件类型）
-	outputExt := strings.ToLower(filepath.Ext(task.OutputPath))
-	sourceExt := strings.ToLower(filepath.Ext(task.SourceFile))
-
-	var filename string
-	if sourceExt == ".pdf" && outputExt == ".pdf" {
-		// PDF 翻译输出为 PDF 格式
-		baseName := strings.TrimSuffix(task.SourceFile, filepath.Ext(task.SourceFile))
-		filename = "translated_" + baseName + ".pdf"
+	// 附带校验和，便于用户校验下载的文件
+	if sum, err := taskChecksum(sessionID, task.ID, outputPath); err == nil {
+		setChecksumHeaders(c, sum)
 	} else {
-		// 其他情况保持原扩展名
-		filename = "translated_" + task.SourceFile
+		log.Printf("[会话 %s][任务 %s] 计算校验和失败: %v", sessionID[:8], task.ID, err)
+	}
+
+	c.FileAttachment(outputPath, filename)
+}
+
+// AlignmentDownloadHandler 下载任务的对齐数据（alignment.json），需在翻译时选择 alignment 输出格式
+func AlignmentDownloadHandler(c *gin.Context) {
+	sessionID := middleware.GetSessionID(c)
+	if sessionID == "" {
+		respondError(c, errInvalidSession)
+		return
+	}
+
+	task, exists := taskManager.GetTask(sessionID, c.Param("taskId"))
+	if !exists {
+		respondError(c, notFound("任务不存在或无权访问"))
+		return
+	}
+
+	outputPath, filename, ok := resolveArtifact(c, task, string(translator.OutputFormatAlignment))
+	if !ok {
+		return
+	}
+
+	c.FileAttachment(outputPath, filename)
+}
+
+// resolveDownload 确定要下载的文件路径和下载文件名，失败时已写入错误响应
+func resolveDownload(c *gin.Context, task *models.TranslateTask) (string, string, bool) {
+	// 生成了多个输出时，可通过 format 参数选择下载的文件
+	return resolveArtifact(c, task, c.Query("format"))
+}
+
+// resolveArtifact 确定指定输出格式（为空时为主输出）的文件路径和下载文件名，失败时已写入错误响应
+func resolveArtifact(c *gin.Context, task *models.TranslateTask, format string) (string, string, bool) {
+	switch task.Status {
+	case "completed":
+	case "partial":
+		// 任务中途失败，只有部分文本块已翻译
+		c.Header("X-Translation-Partial", "true")
+	case "timed_out":
+		if task.OutputPath == "" {
+			respondError(c, badRequest("任务已超时，没有可下载的部分结果"))
+			return "", "", false
+		}
+		c.Header("X-Translation-Partial", "true")
+	default:
+		respondError(c, newAPIError(http.StatusBadRequest, CodeTaskNotReady, "任务未完成"))
+		return "", "", false
+	}
+
+	outputPath := task.OutputPath
+	if format != "" {
+		path, ok := task.Artifacts[format]
+		if !ok {
+			respondError(c, notFound("该任务未生成此格式的输出: "+format))
+			return "", "", false
+		}
+		outputPath = path
+	}
+
+	// 检查文件是否存在
+	if _, err := os.Stat(outputPath); err != nil {
+		respondError(c, notFound("翻译文件不存在"))
+		return "", "", false
+	}
+
+	// 设置下载文件名（根据实际输出文件类型）
+	outputExt := strings.ToLower(filepath.Ext(outputPath))
+	baseName := strings.TrimSuffix(task.SourceFile, filepath.Ext(task.SourceFile))
+
+	filename := "translated_" + baseName + outputExt
+	if format != "" {
+		filename = "translated_" + baseName + "-" + format + outputExt
 	}
 
-	c.FileAttachment(task.OutputPath, filename)
+	return outputPath, filename, true
 }
 
 // GetTasksHandler 获取当前用户的所有任务
 func GetTasksHandler(c *gin.Context) {
 	sessionID := middleware.GetSessionID(c)
 	if sessionID == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的会话"})
+		respondError(c, errInvalidSession)
 		return
 	}
 
 	taskList := taskManager.GetUserTasks(sessionID)
 
 	c.JSON(http.StatusOK, gin.H{
-		"tasks": taskList,
-		"total": len(taskList),
+		"tasks":        taskList,
+		"total":        len(taskList),
+		"queueDepth":   taskQueue.Depth(),
+		"runningTasks": taskQueue.Running(),
 	})
 }
@@ -0,0 +1,306 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"translator-web/models"
+	"translator-web/translator"
+
+	"github.com/gin-gonic/gin"
+)
+
+// postTranslate 向 /translate 上传 PDF 并提交表单字段
+func postTranslate(t *testing.T, sessionID string, pdf []byte, fields map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+	return postTranslateFile(t, sessionID, "paper.pdf", pdf, fields)
+}
+
+// postTranslateFile 向 /translate 上传名为 filename 的文档并提交表单字段
+func postTranslateFile(t *testing.T, sessionID, filename string, data []byte, fields map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	part, err := mw.CreateFormFile("file", filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write(data)
+	for name, value := range fields {
+		mw.WriteField(name, value)
+	}
+	mw.Close()
+
+	r := newTestRouter(sessionID, func(r *gin.Engine) { r.POST("/translate", TranslateHandler) })
+	req := httptest.NewRequest(http.MethodPost, "/translate", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+// waitForTask 等待任务结束，返回最终状态
+func waitForTask(t *testing.T, sessionID, taskID string) string {
+	t.Helper()
+	deadline := time.Now().Add(30 * time.Second)
+	for time.Now().Before(deadline) {
+		if task, ok := taskManager.TaskSnapshot(sessionID, taskID); ok {
+			switch task.Status {
+			case "completed", "partial", "timed_out", "failed", "cancelled":
+				return task.Status
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("任务 %s 未在限定时间内结束", taskID)
+	return ""
+}
+
+func TestTranslateHandlerDeduplicatesSubmissions(t *testing.T) {
+	dir := chdirTemp(t)
+	const sessionID = "session-dedup"
+
+	// 翻译服务在放行前阻塞，保证第二次提交时第一个任务仍在进行
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.Write([]byte(`{"choices":[{"message":{"content":"已翻译的页面"}}]}`))
+	}))
+	defer server.Close()
+	released := false
+	defer func() {
+		if !released {
+			close(release)
+		}
+	}()
+
+	path := filepath.Join(dir, "paper.pdf")
+	writeTestPDF(t, path, 1)
+	pdf, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := map[string]string{
+		"targetLanguage": "Uni",
+		"llmConfig":      `{"provider":"openai","apiUrl":"` + server.URL + `","apiKey":"key","model":"gpt-test"}`,
+	}
+	submit := func() map[string]any {
+		w := postTranslate(t, sessionID, pdf, fields)
+		if w.Code != http.StatusOK {
+			t.Fatalf("状态码 = %d: %s", w.Code, w.Body.String())
+		}
+		var resp map[string]any
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return resp
+	}
+
+	first := submit()
+	taskID, _ := first["taskId"].(string)
+	if taskID == "" || first["duplicate"] == true {
+		t.Fatalf("第一次提交应创建任务: %v", first)
+	}
+	second := submit()
+	if second["taskId"] != taskID || second["duplicate"] != true {
+		t.Errorf("相同的提交应返回已有任务 %s: %v", taskID, second)
+	}
+	if n := len(taskManager.GetUserTasks(sessionID)); n != 1 {
+		t.Errorf("会话中有 %d 个任务，期望 1", n)
+	}
+
+	close(release)
+	released = true
+	if status := waitForTask(t, sessionID, taskID); status != "completed" {
+		t.Fatalf("任务状态 = %s", status)
+	}
+
+	// 去重窗口内，已完成的相同任务同样被复用
+	if third := submit(); third["taskId"] != taskID {
+		t.Errorf("刚完成的相同任务应被复用: %v", third)
+	}
+	// 配置不同则是新任务
+	fields["targetLanguage"] = "Japanese"
+	if other := submit(); other["taskId"] == taskID || other["duplicate"] == true {
+		t.Errorf("目标语言不同应创建新任务: %v", other)
+	} else {
+		waitForTask(t, sessionID, other["taskId"].(string))
+	}
+	if n := len(taskManager.GetUserTasks(sessionID)); n != 2 {
+		t.Errorf("会话中有 %d 个任务，期望 2", n)
+	}
+}
+
+func TestTranslateHandlerClampsThroughput(t *testing.T) {
+	dir := chdirTemp(t)
+	const sessionID = "session-throughput"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"choices":[{"message":{"content":"已翻译的页面"}}]}`))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(dir, "paper.pdf")
+	writeTestPDF(t, path, 1)
+	pdf, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	llmConfig := `{"provider":"openai","apiUrl":"` + server.URL + `","apiKey":"key","model":"gpt-test"}`
+
+	w := postTranslate(t, sessionID, pdf, map[string]string{
+		"targetLanguage": "Uni",
+		"llmConfig":      llmConfig,
+		"concurrency":    "1000",
+		"batchSize":      "1000",
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("状态码 = %d: %s", w.Code, w.Body.String())
+	}
+	var resp map[string]any
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	taskID, _ := resp["taskId"].(string)
+	defer waitForTask(t, sessionID, taskID)
+
+	// 任务状态中返回实际使用的值
+	r := newTestRouter(sessionID, func(r *gin.Engine) { r.GET("/status/:taskId", GetStatusHandler) })
+	status := httptest.NewRecorder()
+	r.ServeHTTP(status, httptest.NewRequest(http.MethodGet, "/status/"+taskID, nil))
+	var task struct {
+		Concurrency int `json:"concurrency"`
+		BatchSize   int `json:"batchSize"`
+	}
+	if err := json.Unmarshal(status.Body.Bytes(), &task); err != nil {
+		t.Fatalf("解析任务状态失败: %v: %s", err, status.Body.String())
+	}
+	if task.Concurrency != translator.MaxConcurrency || task.BatchSize != translator.MaxBatchSize {
+		t.Errorf("concurrency=%d batchSize=%d，期望 %d、%d", task.Concurrency, task.BatchSize, translator.MaxConcurrency, translator.MaxBatchSize)
+	}
+
+	// 负数和非数字直接拒绝
+	for _, value := range []string{"-1", "many"} {
+		w := postTranslate(t, sessionID, pdf, map[string]string{
+			"targetLanguage": "Uni",
+			"llmConfig":      llmConfig,
+			"concurrency":    value,
+		})
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("concurrency=%s 状态码 = %d，期望 400", value, w.Code)
+		}
+	}
+}
+
+func TestAlignmentDownloadHandler(t *testing.T) {
+	chdirTemp(t)
+	const sessionID = "session-alignment"
+	alignmentPath := OutputPath(sessionID, "task-alignment", "paper.pdf", "", "-alignment.json")
+	os.MkdirAll(filepath.Dir(alignmentPath), 0755)
+	content := `[{"page":1,"blockId":"p1_text_0","bbox":{"x":72,"y":700,"width":120,"height":12},"original":"Hello","translated":"你好"}]`
+	if err := os.WriteFile(alignmentPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	taskManager.AddTask(sessionID, &models.TranslateTask{
+		ID:         "task-alignment",
+		SessionID:  sessionID,
+		SourceFile: "paper.pdf",
+		Status:     "completed",
+		Artifacts:  map[string]string{string(translator.OutputFormatAlignment): alignmentPath},
+	})
+	taskManager.AddTask(sessionID, &models.TranslateTask{ID: "task-no-alignment", SessionID: sessionID, SourceFile: "paper.pdf", Status: "completed"})
+
+	r := newTestRouter(sessionID, func(r *gin.Engine) { r.GET("/download/:taskId/alignment", AlignmentDownloadHandler) })
+	download := func(taskID string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/download/"+taskID+"/alignment", nil))
+		return w
+	}
+
+	w := download("task-alignment")
+	if w.Code != http.StatusOK || w.Body.String() != content {
+		t.Errorf("状态码 = %d，内容 = %s", w.Code, w.Body.String())
+	}
+	if w := download("task-no-alignment"); w.Code == http.StatusOK {
+		t.Error("未生成对齐数据的任务不应返回文件")
+	}
+	if w := download("task-missing"); w.Code != http.StatusNotFound {
+		t.Errorf("任务不存在时状态码 = %d，期望 404", w.Code)
+	}
+}
+
+func TestTranslateHandlerFlagsLowConfidence(t *testing.T) {
+	dir := chdirTemp(t)
+	const sessionID = "session-confidence"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		reply, _ := json.Marshal(map[string]any{"translation": "译文：" + req.Messages[len(req.Messages)-1].Content, "confidence": 0.4})
+		json.NewEncoder(w).Encode(map[string]any{
+			"choices": []map[string]any{{"message": map[string]string{"content": string(reply)}}},
+		})
+	}))
+	defer server.Close()
+
+	path := filepath.Join(dir, "paper.pdf")
+	writeTestPDF(t, path, 1)
+	pdf, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := postTranslate(t, sessionID, pdf, map[string]string{
+		"targetLanguage":     "Uni",
+		"selfRateConfidence": "true",
+		"llmConfig":          `{"provider":"openai","apiUrl":"` + server.URL + `","apiKey":"key","model":"gpt-test"}`,
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("状态码 = %d: %s", w.Code, w.Body.String())
+	}
+	var resp map[string]any
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	taskID, _ := resp["taskId"].(string)
+	if status := waitForTask(t, sessionID, taskID); status != "completed" {
+		t.Fatalf("任务状态 = %s", status)
+	}
+
+	task, _ := taskManager.TaskSnapshot(sessionID, taskID)
+	if len(task.LowConfidence) != 1 {
+		t.Fatalf("lowConfidenceBlocks = %+v，期望 1 项", task.LowConfidence)
+	}
+	if block := task.LowConfidence[0]; block.Confidence != 0.4 || block.Translation != "译文："+block.Original {
+		t.Errorf("低可信度文本块 = %+v", block)
+	}
+}
+
+func TestTranslateRejectsDocumentAlreadyInTargetLanguage(t *testing.T) {
+	chdirTemp(t)
+	const sessionID = "session-already-target"
+	llmConfig, _ := json.Marshal(newTextStub(t, "翻译结果"))
+	csv := []byte("标题,内容\n机器学习,这篇文章介绍了机器学习在自然语言处理中的应用和最新进展。\n数据集,我们在三个公开数据集上评估了模型的准确率和运行速度。\n")
+	fields := map[string]string{"targetLanguage": "Uni", "llmConfig": string(llmConfig)}
+
+	w := postTranslateFile(t, sessionID, "notes.csv", csv, fields)
+	var resp map[string]any
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if w.Code != http.StatusUnprocessableEntity || resp["code"] != CodeAlreadyTarget || resp["detectedLanguage"] != "zh" {
+		t.Fatalf("未设置 force 时状态码 = %d，响应 = %s，期望 422 %s 且检测到 zh", w.Code, w.Body.String(), CodeAlreadyTarget)
+	}
+
+	fields["force"] = "true"
+	w = postTranslateFile(t, sessionID, "notes.csv", csv, fields)
+	if w.Code != http.StatusOK {
+		t.Fatalf("设置 force 后状态码 = %d: %s", w.Code, w.Body.String())
+	}
+	resp = nil
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	taskID, _ := resp["taskId"].(string)
+	if status := waitForTask(t, sessionID, taskID); status != "completed" {
+		t.Errorf("任务状态 = %s，期望 completed", status)
+	}
+}
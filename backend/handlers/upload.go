@@ -0,0 +1,327 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"translator-web/middleware"
+	"translator-web/translator"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// defaultMaxUploadMB 上传文件的默认大小上限（MB）
+const defaultMaxUploadMB = 100
+
+// chunkedUploadRetention 分块上传的保留时间，超过后未完成或未使用的上传会被清理
+const chunkedUploadRetention = 24 * time.Hour
+
+// maxUploadSize 上传文件的大小上限（字节），可通过环境变量 MAX_UPLOAD_MB 设置
+var maxUploadSize = maxUploadSizeFromEnv()
+
+// maxUploadSizeFromEnv 从 MAX_UPLOAD_MB 读取上传文件的大小上限
+func maxUploadSizeFromEnv() int64 {
+	value := os.Getenv("MAX_UPLOAD_MB")
+	if value == "" {
+		return defaultMaxUploadMB << 20
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n < 1 {
+		log.Printf("⚠️  MAX_UPLOAD_MB 无效 %q，使用默认值 %d", value, defaultMaxUploadMB)
+		return defaultMaxUploadMB << 20
+	}
+	return n << 20
+}
+
+// MaxUploadSize 返回上传文件的大小上限（字节）
+func MaxUploadSize() int64 {
+	return maxUploadSize
+}
+
+// uploadedFile 上传的文档：普通表单上传的文件或分块上传组装完成的文件
+type uploadedFile struct {
+	Filename string
+	Size     int64
+
+	header *multipart.FileHeader // 表单上传
+	path   string                // 分块上传组装完成的文件路径
+}
+
+// Open 打开上传的文件
+func (f *uploadedFile) Open() (multipart.File, error) {
+	if f.header != nil {
+		return f.header.Open()
+	}
+	return os.Open(f.path)
+}
+
+// SaveTo 将上传的文件保存到 dst，分块上传的文件保留原件，可用于多次预估或翻译
+func (f *uploadedFile) SaveTo(dst string) error {
+	src, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, src); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// chunkedUpload 分块上传的状态
+// 分块必须按顺序上传，写入同一个文件；重复上传已接收的分块视为重试，直接返回当前进度
+type chunkedUpload struct {
+	ID        string `json:"uploadId"`
+	SessionID string `json:"-"`
+	Filename  string `json:"filename"`
+	Size      int64  `json:"size"`
+	Received  int64  `json:"received"`
+	NextIndex int    `json:"nextIndex"`
+	Complete  bool   `json:"complete"`
+
+	path      string
+	updatedAt time.Time
+	mu        sync.Mutex // 同一上传的分块串行写入
+}
+
+var (
+	chunkedUploadsMu sync.Mutex
+	chunkedUploads   = make(map[string]*chunkedUpload)
+)
+
+// getChunkedUpload 返回会话中的分块上传
+func getChunkedUpload(sessionID, uploadID string) (*chunkedUpload, bool) {
+	chunkedUploadsMu.Lock()
+	defer chunkedUploadsMu.Unlock()
+	upload, ok := chunkedUploads[uploadID]
+	if !ok || upload.SessionID != sessionID {
+		return nil, false
+	}
+	return upload, true
+}
+
+// addChunkedUpload 登记分块上传，并清理过期的上传及其文件
+func addChunkedUpload(upload *chunkedUpload) {
+	chunkedUploadsMu.Lock()
+	defer chunkedUploadsMu.Unlock()
+	for id, existing := range chunkedUploads {
+		existing.mu.Lock()
+		expired := time.Since(existing.updatedAt) > chunkedUploadRetention
+		existing.mu.Unlock()
+		if expired {
+			os.Remove(existing.path)
+			delete(chunkedUploads, id)
+		}
+	}
+	chunkedUploads[upload.ID] = upload
+}
+
+// supportedDocumentExt 检查文件扩展名是否为支持的文档类型（Kindle 电子书转换为 EPUB 后翻译）
+func supportedDocumentExt(ext string) bool {
+	return ext == ".epub" || ext == ".pdf" || ext == ".pptx" || ext == ".csv" || ext == ".tsv" || translator.IsKindleExt(ext)
+}
+
+// fileTooLargeError 文件超过大小上限
+func fileTooLargeError() *APIError {
+	return newAPIError(http.StatusRequestEntityTooLarge, CodePayloadTooLarge, fmt.Sprintf("文件过大，最大支持%dMB", maxUploadSize>>20))
+}
+
+// InitUploadHandler 开始分块上传：校验文件名和总大小，返回 uploadId
+func InitUploadHandler(c *gin.Context) {
+	sessionID := middleware.GetSessionID(c)
+	if sessionID == "" {
+		respondError(c, errInvalidSession)
+		return
+	}
+
+	var req struct {
+		Filename string `json:"filename"`
+		Size     int64  `json:"size"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, badRequest("请求格式错误: "+err.Error()))
+		return
+	}
+	req.Filename = filepath.Base(req.Filename)
+	if !supportedDocumentExt(strings.ToLower(filepath.Ext(req.Filename))) {
+		respondError(c, newAPIError(http.StatusUnsupportedMediaType, CodeUnsupportedFormat, "只支持 .epub、.pdf、.pptx、.csv、.tsv、.mobi 和 .azw3 文件"))
+		return
+	}
+	if req.Size <= 0 {
+		respondError(c, badRequest("size 必须是正整数"))
+		return
+	}
+	if req.Size > maxUploadSize {
+		respondError(c, fileTooLargeError())
+		return
+	}
+
+	dir := filepath.Join("data", "users", sessionID, "uploads", "chunked")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		respondError(c, internalError("创建上传目录失败: "+err.Error()))
+		return
+	}
+
+	upload := &chunkedUpload{
+		ID:        uuid.New().String(),
+		SessionID: sessionID,
+		Filename:  req.Filename,
+		Size:      req.Size,
+		updatedAt: time.Now(),
+	}
+	upload.path = filepath.Join(dir, upload.ID+".part")
+	if err := os.WriteFile(upload.path, nil, 0644); err != nil {
+		respondError(c, internalError("创建上传文件失败: "+err.Error()))
+		return
+	}
+	addChunkedUpload(upload)
+
+	c.JSON(http.StatusOK, upload)
+}
+
+// UploadChunkHandler 上传一个分块（表单字段 uploadId、index 和文件 chunk）
+// index 从 0 开始且必须等于 nextIndex；重传已接收的分块时直接返回当前进度，便于断线后继续
+func UploadChunkHandler(c *gin.Context) {
+	sessionID := middleware.GetSessionID(c)
+	if sessionID == "" {
+		respondError(c, errInvalidSession)
+		return
+	}
+
+	upload, ok := getChunkedUpload(sessionID, c.PostForm("uploadId"))
+	if !ok {
+		respondError(c, notFound("上传不存在或已过期"))
+		return
+	}
+	index, err := strconv.Atoi(c.PostForm("index"))
+	if err != nil || index < 0 {
+		respondError(c, badRequest("index 必须是非负整数"))
+		return
+	}
+	chunk, err := c.FormFile("chunk")
+	if err != nil {
+		respondError(c, badRequest("未找到分块数据"))
+		return
+	}
+
+	upload.mu.Lock()
+	defer upload.mu.Unlock()
+
+	switch {
+	case upload.Complete:
+		respondError(c, badRequest("上传已完成"))
+		return
+	case index < upload.NextIndex:
+		c.JSON(http.StatusOK, upload)
+		return
+	case index > upload.NextIndex:
+		respondError(c, newAPIError(http.StatusConflict, CodeInvalidRequest, fmt.Sprintf("分块顺序错误：应上传第 %d 块，收到第 %d 块", upload.NextIndex, index)))
+		return
+	}
+	if upload.Received+chunk.Size > upload.Size {
+		respondError(c, newAPIError(http.StatusRequestEntityTooLarge, CodePayloadTooLarge, "分块总大小超过声明的文件大小"))
+		return
+	}
+
+	if err := appendChunk(upload.path, upload.Received, chunk); err != nil {
+		respondError(c, internalError("写入分块失败: "+err.Error()))
+		return
+	}
+	upload.Received += chunk.Size
+	upload.NextIndex++
+	upload.updatedAt = time.Now()
+
+	c.JSON(http.StatusOK, upload)
+}
+
+// appendChunk 将分块写入上传文件的 offset 处（覆盖上次写入失败留下的残余数据）
+func appendChunk(path string, offset int64, chunk *multipart.FileHeader) error {
+	src, err := chunk.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	out, err := os.OpenFile(path, os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if err := out.Truncate(offset); err != nil {
+		out.Close()
+		return err
+	}
+	if _, err := out.Seek(offset, io.SeekStart); err != nil {
+		out.Close()
+		return err
+	}
+	if _, err := io.Copy(out, src); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// CompleteUploadHandler 完成分块上传：校验已接收的大小与声明的大小一致
+// 完成后可在 /api/translate 或 /api/estimate 中以表单字段 uploadId 代替 file 提交
+func CompleteUploadHandler(c *gin.Context) {
+	sessionID := middleware.GetSessionID(c)
+	if sessionID == "" {
+		respondError(c, errInvalidSession)
+		return
+	}
+
+	var req struct {
+		UploadID string `json:"uploadId"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, badRequest("请求格式错误: "+err.Error()))
+		return
+	}
+	upload, ok := getChunkedUpload(sessionID, req.UploadID)
+	if !ok {
+		respondError(c, notFound("上传不存在或已过期"))
+		return
+	}
+
+	upload.mu.Lock()
+	defer upload.mu.Unlock()
+	if upload.Received != upload.Size {
+		respondError(c, badRequest(fmt.Sprintf("上传未完成：已接收 %d / %d 字节", upload.Received, upload.Size)))
+		return
+	}
+	upload.Complete = true
+	upload.updatedAt = time.Now()
+
+	log.Printf("[会话 %s] 分块上传完成: %s（%d 字节，%d 块）", sessionID[:8], upload.Filename, upload.Size, upload.NextIndex)
+	c.JSON(http.StatusOK, upload)
+}
+
+// completedUpload 返回会话中已完成的分块上传对应的文件
+func completedUpload(sessionID, uploadID string) (*uploadedFile, bool) {
+	upload, ok := getChunkedUpload(sessionID, uploadID)
+	if !ok {
+		return nil, false
+	}
+	upload.mu.Lock()
+	defer upload.mu.Unlock()
+	if !upload.Complete {
+		return nil, false
+	}
+	upload.updatedAt = time.Now()
+	return &uploadedFile{Filename: upload.Filename, Size: upload.Size, path: upload.path}, true
+}
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newUploadRouter(sessionID string) *gin.Engine {
+	return newTestRouter(sessionID, func(r *gin.Engine) {
+		r.POST("/upload/init", InitUploadHandler)
+		r.POST("/upload/chunk", UploadChunkHandler)
+		r.POST("/upload/complete", CompleteUploadHandler)
+		r.POST("/translate", TranslateHandler)
+	})
+}
+
+// postJSON 以 JSON 提交请求
+func postJSON(r *gin.Engine, target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+// postForm 以 multipart 表单提交请求，files 为 字段名 -> 文件内容
+func postForm(r *gin.Engine, target string, fields map[string]string, files map[string][]byte) *httptest.ResponseRecorder {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for name, value := range fields {
+		mw.WriteField(name, value)
+	}
+	for name, data := range files {
+		part, _ := mw.CreateFormFile(name, name)
+		part.Write(data)
+	}
+	mw.Close()
+	req := httptest.NewRequest(http.MethodPost, target, &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestChunkedUpload(t *testing.T) {
+	dir := chdirTemp(t)
+	const sessionID = "session-chunked-upload"
+	r := newUploadRouter(sessionID)
+
+	path := filepath.Join(dir, "paper.pdf")
+	writeTestPDF(t, path, 3)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := postJSON(r, "/upload/init", `{"filename":"paper.pdf","size":`+strconv.Itoa(len(data))+`}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("init 状态码 = %d: %s", w.Code, w.Body.String())
+	}
+	var upload chunkedUpload
+	json.Unmarshal(w.Body.Bytes(), &upload)
+
+	third := len(data) / 3
+	chunks := [][]byte{data[:third], data[third : 2*third], data[2*third:]}
+	sendChunk := func(index int) *httptest.ResponseRecorder {
+		return postForm(r, "/upload/chunk", map[string]string{"uploadId": upload.ID, "index": strconv.Itoa(index)},
+			map[string][]byte{"chunk": chunks[index]})
+	}
+
+	if w := sendChunk(1); w.Code != http.StatusConflict {
+		t.Errorf("跳过分块时状态码 = %d，期望 409", w.Code)
+	}
+	if w := postJSON(r, "/upload/complete", `{"uploadId":"`+upload.ID+`"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("未接收完就完成时状态码 = %d，期望 400", w.Code)
+	}
+	for i := range chunks {
+		if w := sendChunk(i); w.Code != http.StatusOK {
+			t.Fatalf("第 %d 块状态码 = %d: %s", i, w.Code, w.Body.String())
+		}
+	}
+	// 重传已接收的分块视为重试，不重复写入
+	if w := sendChunk(1); w.Code != http.StatusOK {
+		t.Errorf("重传分块状态码 = %d", w.Code)
+	}
+
+	w = postJSON(r, "/upload/complete", `{"uploadId":"`+upload.ID+`"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("complete 状态码 = %d: %s", w.Code, w.Body.String())
+	}
+	file, ok := completedUpload(sessionID, upload.ID)
+	if !ok {
+		t.Fatal("上传未标记为完成")
+	}
+	assembled, err := os.ReadFile(file.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(assembled, data) {
+		t.Errorf("组装后的文件 %d 字节，与原文件（%d 字节）不一致", len(assembled), len(data))
+	}
+
+	// 组装完成的文件以 uploadId 提交翻译
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"choices":[{"message":{"content":"已翻译的页面"}}]}`))
+	}))
+	defer server.Close()
+	w = postForm(r, "/translate", map[string]string{
+		"uploadId":       upload.ID,
+		"targetLanguage": "Uni",
+		"llmConfig":      `{"provider":"openai","apiUrl":"` + server.URL + `","apiKey":"key","model":"gpt-test"}`,
+	}, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("translate 状态码 = %d: %s", w.Code, w.Body.String())
+	}
+	var resp map[string]any
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if status := waitForTask(t, sessionID, resp["taskId"].(string)); status != "completed" {
+		t.Errorf("任务状态 = %s", status)
+	}
+}
+
+func TestInitUploadRejectsOversizedFile(t *testing.T) {
+	chdirTemp(t)
+	defer func(v int64) { maxUploadSize = v }(maxUploadSize)
+	maxUploadSize = 1 << 20
+
+	r := newUploadRouter("session-upload-limit")
+	if w := postJSON(r, "/upload/init", `{"filename":"book.pdf","size":2097152}`); w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("超过上限时状态码 = %d，期望 413", w.Code)
+	}
+	if w := postJSON(r, "/upload/init", `{"filename":"notes.txt","size":10}`); w.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("不支持的类型状态码 = %d，期望 415", w.Code)
+	}
+}
+
+func TestMaxUploadSizeFromEnv(t *testing.T) {
+	t.Setenv("MAX_UPLOAD_MB", "250")
+	if got := maxUploadSizeFromEnv(); got != 250<<20 {
+		t.Errorf("MAX_UPLOAD_MB=250 时上限 = %d", got)
+	}
+	t.Setenv("MAX_UPLOAD_MB", "lots")
+	if got := maxUploadSizeFromEnv(); got != defaultMaxUploadMB<<20 {
+		t.Errorf("无效值时上限 = %d，期望默认值", got)
+	}
+}
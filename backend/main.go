@@ -8,8 +8,10 @@ import (
 	"net/http/httputil"
 	"net/url"
 	"os"
+	"time"
 	"translator-web/handlers"
 	"translator-web/middleware"
+	"translator-web/translator"
 
 	"github.com/gin-gonic/gin"
 )
@@ -18,10 +20,15 @@ import (
 var frontendFS embed.FS
 
 func main() {
+	sweepWorkDirs()
+
 	r := gin.Default()
 
-	// 设置最大上传文件大小 (100MB)
-	r.MaxMultipartMemory = 100 << 20
+	// 设置最大上传文件大小（默认 100MB，可通过 MAX_UPLOAD_MB 设置）
+	r.MaxMultipartMemory = handlers.MaxUploadSize()
+
+	// Prometheus 指标，不经过会话中间件，避免抓取时创建会话
+	r.GET("/metrics", handlers.MetricsHandler())
 
 	// 应用会话中间件到所有路由
 	r.Use(middleware.SessionMiddleware())
@@ -30,9 +37,34 @@ func main() {
 	api := r.Group("/api")
 	{
 		api.POST("/translate", handlers.TranslateHandler)
+		api.POST("/translate-text", handlers.TranslateTextHandler)
+		api.POST("/estimate", handlers.EstimateHandler)
 		api.GET("/status/:taskId", handlers.GetStatusHandler)
 		api.GET("/download/:taskId", handlers.DownloadHandler)
+		api.GET("/download/:taskId/checksum", handlers.ChecksumHandler)
+		api.GET("/download/:taskId/alignment", handlers.AlignmentDownloadHandler)
+		api.GET("/stream/:taskId", handlers.StreamHandler)
 		api.GET("/tasks", handlers.GetTasksHandler)
+		api.POST("/tasks/:taskId/share", handlers.ShareTaskHandler)
+		api.GET("/tasks/:taskId/tmx", handlers.TMXHandler)
+		api.POST("/tasks/:taskId/retranslate", handlers.RetranslateHandler)
+		api.GET("/tasks/:taskId/review", handlers.ReviewHandler)
+		api.POST("/tasks/:taskId/edits", handlers.EditsHandler)
+		api.GET("/shared/:token", handlers.SharedDownloadHandler)
+		api.GET("/glossary", handlers.GetGlossaryHandler)
+		api.POST("/glossary", handlers.AddGlossaryEntryHandler)
+		api.DELETE("/glossary/:id", handlers.DeleteGlossaryEntryHandler)
+		api.POST("/cache/warm", handlers.WarmCacheHandler)
+		api.GET("/cache/warm/:jobId", handlers.GetCacheWarmHandler)
+		api.DELETE("/cache/warm/:jobId", handlers.CancelCacheWarmHandler)
+		api.POST("/test-provider", handlers.TestProviderHandler)
+		api.GET("/provider-config", handlers.GetProviderConfigHandler)
+		api.POST("/provider-config", handlers.SaveProviderConfigHandler)
+		api.GET("/languages", handlers.LanguagesHandler)
+		api.POST("/languages", handlers.QueryLanguagesHandler)
+		api.POST("/upload/init", handlers.InitUploadHandler)
+		api.POST("/upload/chunk", handlers.UploadChunkHandler)
+		api.POST("/upload/complete", handlers.CompleteUploadHandler)
 	}
 
 	// 根据环境变量决定前端服务方式
@@ -75,3 +107,26 @@ func main() {
 	log.Println("✅ 会话隔离已启用 - 每个用户的任务和文件完全独立")
 	r.Run(":8080")
 }
+
+// sweepWorkDirs 启动时清理遗留的PDF临时工作目录
+// 保留时长由 WORK_DIR_MAX_AGE 指定（如 "12h"），默认24小时
+func sweepWorkDirs() {
+	maxAge := translator.DefaultWorkDirMaxAge
+	if value := os.Getenv("WORK_DIR_MAX_AGE"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			log.Printf("⚠️  WORK_DIR_MAX_AGE 格式无效 %q，使用默认值 %s", value, maxAge)
+		} else {
+			maxAge = parsed
+		}
+	}
+
+	removed, err := translator.SweepWorkDirs(maxAge)
+	if err != nil {
+		log.Printf("⚠️  清理遗留工作目录失败: %v", err)
+		return
+	}
+	if removed > 0 {
+		log.Printf("🧹 已清理 %d 个遗留工作目录", removed)
+	}
+}
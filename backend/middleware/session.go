@@ -30,6 +30,29 @@ type SessionManager struct {
 
 var manager *SessionManager
 
+var (
+	sessionEndHooks   []func(sessionID string)
+	sessionEndHooksMu sync.RWMutex
+)
+
+// OnSessionEnd 注册会话过期或被删除后调用的函数，用于清理与会话绑定的数据
+func OnSessionEnd(fn func(sessionID string)) {
+	sessionEndHooksMu.Lock()
+	defer sessionEndHooksMu.Unlock()
+	sessionEndHooks = append(sessionEndHooks, fn)
+}
+
+// endSessions 对已结束的会话调用 OnSessionEnd 注册的函数（调用方不能持有 sm.mu）
+func endSessions(sessionIDs ...string) {
+	sessionEndHooksMu.RLock()
+	defer sessionEndHooksMu.RUnlock()
+	for _, id := range sessionIDs {
+		for _, fn := range sessionEndHooks {
+			fn(id)
+		}
+	}
+}
+
 func init() {
 	manager = &SessionManager{
 		sessions: make(map[string]*Session),
@@ -55,6 +78,9 @@ func generateSessionID() string {
 
 // GetOrCreateSession 获取或创建会话
 func (sm *SessionManager) GetOrCreateSession(sessionID string) *Session {
+	var expired []string
+	defer func() { endSessions(expired...) }()
+
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 
@@ -68,6 +94,7 @@ func (sm *SessionManager) GetOrCreateSession(sessionID string) *Session {
 			}
 			// 会话过期，删除
 			delete(sm.sessions, sessionID)
+			expired = append(expired, sessionID)
 		}
 	}
 
@@ -83,6 +110,9 @@ func (sm *SessionManager) GetOrCreateSession(sessionID string) *Session {
 
 // GetSession 获取会话（不创建新会话）
 func (sm *SessionManager) GetSession(sessionID string) (*Session, bool) {
+	var expired []string
+	defer func() { endSessions(expired...) }()
+
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 
@@ -95,6 +125,7 @@ func (sm *SessionManager) GetSession(sessionID string) (*Session, bool) {
 	if time.Since(session.LastSeen) >= SessionTimeout {
 		// 删除过期会话
 		delete(sm.sessions, sessionID)
+		expired = append(expired, sessionID)
 		return nil, false
 	}
 
@@ -116,8 +147,13 @@ func (sm *SessionManager) UpdateLastSeen(sessionID string) {
 // DeleteSession 删除会话
 func (sm *SessionManager) DeleteSession(sessionID string) {
 	sm.mu.Lock()
-	defer sm.mu.Unlock()
+	_, exists := sm.sessions[sessionID]
 	delete(sm.sessions, sessionID)
+	sm.mu.Unlock()
+
+	if exists {
+		endSessions(sessionID)
+	}
 }
 
 // cleanupExpiredSessions 定期清理过期会话
@@ -126,15 +162,24 @@ func (sm *SessionManager) cleanupExpiredSessions() {
 	defer ticker.Stop()
 
 	for range ticker.C {
-		sm.mu.Lock()
-		now := time.Now()
-		for id, session := range sm.sessions {
-			if now.Sub(session.LastSeen) >= SessionTimeout {
-				delete(sm.sessions, id)
-			}
+		sm.removeExpiredSessions()
+	}
+}
+
+// removeExpiredSessions 删除所有过期会话
+func (sm *SessionManager) removeExpiredSessions() {
+	var expired []string
+	sm.mu.Lock()
+	now := time.Now()
+	for id, session := range sm.sessions {
+		if now.Sub(session.LastSeen) >= SessionTimeout {
+			delete(sm.sessions, id)
+			expired = append(expired, id)
 		}
-		sm.mu.Unlock()
 	}
+	sm.mu.Unlock()
+
+	endSessions(expired...)
 }
 
 // SessionMiddleware Gin 中间件：确保每个请求都有会话
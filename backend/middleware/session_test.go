@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestOnSessionEndCalledForEndedSessions(t *testing.T) {
+	var mu sync.Mutex
+	var ended []string
+	OnSessionEnd(func(sessionID string) {
+		mu.Lock()
+		defer mu.Unlock()
+		ended = append(ended, sessionID)
+	})
+
+	sm := &SessionManager{sessions: make(map[string]*Session)}
+	expired := time.Now().Add(-SessionTimeout)
+	sm.sessions["deleted"] = &Session{ID: "deleted", LastSeen: time.Now()}
+	sm.sessions["swept"] = &Session{ID: "swept", LastSeen: expired}
+	sm.sessions["revisited"] = &Session{ID: "revisited", LastSeen: expired}
+	sm.sessions["active"] = &Session{ID: "active", LastSeen: time.Now()}
+
+	sm.DeleteSession("deleted")
+	sm.DeleteSession("unknown")
+	if session := sm.GetOrCreateSession("revisited"); session.ID == "revisited" {
+		t.Error("过期会话不应被复用")
+	}
+	sm.removeExpiredSessions()
+
+	mu.Lock()
+	defer mu.Unlock()
+	slices.Sort(ended)
+	if want := []string{"deleted", "revisited", "swept"}; !slices.Equal(ended, want) {
+		t.Errorf("结束的会话 = %v，期望 %v", ended, want)
+	}
+	if _, ok := sm.GetSession("active"); !ok {
+		t.Error("未过期的会话不应被删除")
+	}
+}
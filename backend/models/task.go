@@ -3,16 +3,81 @@ package models
 import "time"
 
 type TranslateTask struct {
-	ID             string    `json:"id"`
-	SessionID      string    `json:"-"` // 不返回给前端
-	SourceFile     string    `json:"sourceFile"`
-	TargetLanguage string    `json:"targetLanguage"`
-	Status         string    `json:"status"` // pending, processing, completed, failed
-	Progress       float64   `json:"progress"`
-	Error          string    `json:"error,omitempty"`
-	CreatedAt      time.Time `json:"createdAt"`
-	CompletedAt    time.Time `json:"completedAt,omitempty"`
-	OutputPath     string    `json:"outputPath,omitempty"`
+	ID              string               `json:"id"`
+	SessionID       string               `json:"-"` // 不返回给前端
+	SourceFile      string               `json:"sourceFile"`
+	TargetLanguage  string               `json:"targetLanguage"`
+	Status          string               `json:"status"` // pending, queued, processing, completed, partial, timed_out, failed
+	Progress        float64              `json:"progress"`
+	Error           string               `json:"error,omitempty"`
+	ErrorCode       string               `json:"errorCode,omitempty"` // 失败原因的错误码（与接口错误响应的 code 相同）
+	CreatedAt       time.Time            `json:"createdAt"`
+	CompletedAt     time.Time            `json:"completedAt,omitempty"`
+	OutputPath      string               `json:"outputPath,omitempty"`
+	FailedBlocks    []FailedBlock        `json:"failedBlocks,omitempty"`        // 翻译失败（已使用原文）的文本块
+	Refusals        int                  `json:"refusals,omitempty"`            // 翻译服务拒绝翻译（已使用原文）的文本块数，包含在 FailedBlocks 中
+	Stats           *TaskStats           `json:"stats,omitempty"`               // 翻译统计信息
+	Artifacts       map[string]string    `json:"artifacts,omitempty"`           // 已生成的输出文件：输出格式 -> 文件路径
+	TranslatedPages int                  `json:"translatedPages,omitempty"`     // 从第1页起已连续翻译完成的页数（PDF）
+	TotalPages      int                  `json:"totalPages,omitempty"`          // 总页数（PDF）
+	QueuePosition   int                  `json:"queuePosition,omitempty"`       // 排队位置（从1开始），仅在 queued 状态下有效
+	Concurrency     int                  `json:"concurrency,omitempty"`         // 实际使用的并发翻译请求数
+	BatchSize       int                  `json:"batchSize,omitempty"`           // 实际使用的批大小
+	Streaming       bool                 `json:"streaming,omitempty"`           // 是否可通过 /api/stream/:taskId 实时获取双语译文
+	RequestHash     string               `json:"-"`                             // 文件内容与翻译配置的哈希，用于识别重复提交
+	Checksums       map[string]string    `json:"-"`                             // 输出文件路径 -> MD5，首次下载时计算
+	SourceLanguage  string               `json:"sourceLanguage,omitempty"`      // 源语言（未指定时为检测到的文档主要语言）
+	Translations    map[string]string    `json:"-"`                             // 原文 -> 译文，用于导出翻译记忆（TMX）
+	Divergent       []DivergentBlock     `json:"divergentBlocks,omitempty"`     // 回译检查中与原文差异较大的文本块（需启用 backTranslateCheck）
+	Usage           *TokenUsage          `json:"usage,omitempty"`               // 大模型提供商返回的 token 用量
+	SourcePath      string               `json:"-"`                             // 上传文件的保存路径，重新翻译时使用
+	Request         *TranslateRequest    `json:"-"`                             // 提交时的翻译配置，重新翻译时使用
+	Fallbacks       []ArtifactFallback   `json:"fallbacks,omitempty"`           // PDF 重新生成失败后改为生成的替代输出（需设置 onRegenerationFailure）
+	LowConfidence   []LowConfidenceBlock `json:"lowConfidenceBlocks,omitempty"` // 自评可信度低于阈值、需要人工复核的文本块（需启用 selfRateConfidence）
+	ParentID        string               `json:"parentId,omitempty"`            // 多目标语言任务中子任务所属的父任务
+	SubTasks        map[string]string    `json:"subTasks,omitempty"`            // 多目标语言任务的子任务：目标语言 -> 子任务 ID
+}
+
+// ArtifactFallback PDF 重新生成失败后生成的替代输出
+type ArtifactFallback struct {
+	Format   string `json:"format"`   // 请求的输出格式（pdf 或 bilingual-pdf）
+	Artifact string `json:"artifact"` // 实际生成的输出格式，original-copy 时与 format 相同但内容未翻译
+	Policy   string `json:"policy"`   // 采用的处理方式：text-fallback、html-fallback 或 original-copy
+	Error    string `json:"error"`    // 重新生成失败的原因
+}
+
+// TaskStats 任务统计信息
+type TaskStats struct {
+	TotalBlocks  int `json:"totalBlocks"`  // 文本块总数
+	UniqueBlocks int `json:"uniqueBlocks"` // 去重后的文本块数
+	SavedBlocks  int `json:"savedBlocks"`  // 因去重节省的翻译次数
+}
+
+// TokenUsage token 用量
+type TokenUsage struct {
+	InputTokens  int `json:"inputTokens"`
+	OutputTokens int `json:"outputTokens"`
+}
+
+// FailedBlock 翻译失败的文本块
+type FailedBlock struct {
+	Index int    `json:"index"`
+	Error string `json:"error"`
+}
+
+// LowConfidenceBlock 翻译服务自评可信度低于阈值的文本块
+type LowConfidenceBlock struct {
+	Original    string  `json:"original"`
+	Translation string  `json:"translation"`
+	Confidence  float64 `json:"confidence"`
+}
+
+// DivergentBlock 回译与原文差异较大、译文可能有误的文本块
+type DivergentBlock struct {
+	Original        string  `json:"original"`
+	Translation     string  `json:"translation"`
+	BackTranslation string  `json:"backTranslation"`
+	Similarity      float64 `json:"similarity"`
 }
 
 type LLMConfig struct {
@@ -23,12 +88,85 @@ type LLMConfig struct {
 	Temperature float64           `json:"temperature"`
 	MaxTokens   int               `json:"maxTokens"`
 	Extra       map[string]string `json:"extra,omitempty"` // 额外参数，用于自定义提供商
+
+	CACert             string `json:"caCert,omitempty"`             // 额外信任的 CA 证书（PEM 格式的证书内容）
+	InsecureSkipVerify bool   `json:"insecureSkipVerify,omitempty"` // 跳过 TLS 证书校验（不安全，需服务端设置 ALLOW_INSECURE_TLS）
+
+	MaxIdleConnsPerHost int `json:"maxIdleConnsPerHost,omitempty"` // 每个主机保留的空闲连接数
+	MaxConnsPerHost     int `json:"maxConnsPerHost,omitempty"`     // 每个主机的最大连接数，为 0 时不限制
+	IdleConnTimeout     int `json:"idleConnTimeout,omitempty"`     // 空闲连接的保留时间（秒）
 }
 
 type TranslateRequest struct {
-	TargetLanguage   string    `json:"targetLanguage"`
-	LLMConfig        LLMConfig `json:"llmConfig"`
-	UserPrompt       string    `json:"userPrompt,omitempty"`
-	ForceRetranslate bool      `json:"forceRetranslate,omitempty"` // 是否强制重新翻译（忽略缓存）
-	GenerateMode     string    `json:"generateMode,omitempty"`     // 生成模式：bilingual（双语）或 monolingual（单语）
+	TargetLanguage   string             `json:"targetLanguage"`
+	TargetLanguages  []string           `json:"targetLanguages,omitempty"` // 同时翻译成多种语言，每种语言一个子任务，设置后忽略 targetLanguage
+	LLMConfig        LLMConfig          `json:"llmConfig"`
+	UserPrompt       string             `json:"userPrompt,omitempty"`
+	ForceRetranslate bool               `json:"forceRetranslate,omitempty"` // 是否强制重新翻译（忽略缓存）
+	Force            bool               `json:"force,omitempty"`            // 文档的主要语言已是目标语言时仍然翻译
+	GenerateMode     string             `json:"generateMode,omitempty"`     // 生成模式：bilingual（双语）或 monolingual（单语）
+	OutputFormats    []string           `json:"outputFormats,omitempty"`    // 需要生成的输出格式，为空时按生成模式决定
+	HTMLLayout       string             `json:"htmlLayout,omitempty"`       // 双语 HTML 输出的排版方式：stacked（默认）或 side-by-side
+	Formality        string             `json:"formality,omitempty"`        // 语气：formal（正式）、informal（非正式）或 neutral（中性）
+	BlockFilter      *BlockFilterConfig `json:"blockFilter,omitempty"`      // 文本块过滤规则，为空时使用默认规则
+	CaptionLabels    map[string]string  `json:"captionLabels,omitempty"`    // 图表标签词的固定译法（如 Figure -> 图），为空时保留原标签
+	Concurrency      int                `json:"concurrency,omitempty"`      // 并发翻译请求数，为空时使用提供商默认值，超出上限时按上限处理
+	BatchSize        int                `json:"batchSize,omitempty"`        // 支持批量接口的提供商每次请求的文本块数
+	Stream           bool               `json:"stream,omitempty"`           // 是否边翻译边输出双语文本（通过 /api/stream/:taskId 获取）
+	TMX              map[string]string  `json:"tmx,omitempty"`              // 上传的翻译记忆（TMX）中的原文 -> 译文，翻译前写入缓存
+
+	BackCheck    bool `json:"backTranslateCheck,omitempty"` // 是否将译文翻译回源语言检查质量（翻译开销约增加一倍）
+	Timeout      int  `json:"timeout,omitempty"`            // 任务最长执行时间（秒），为空或超过服务端上限（TASK_TIMEOUT）时使用服务端上限
+	VerticalText bool `json:"verticalText,omitempty"`       // PDF 原文为竖排时以竖排（逐字堆叠、从右到左分列）输出 CJK 译文
+
+	Strategy      string `json:"strategy,omitempty"`      // 单语 PDF 的生成方式：overlay（默认，在原页面上覆盖译文）、regenerate（重新生成页面）或 auto（覆盖失败时重新生成）
+	MatchMode     string `json:"matchMode,omitempty"`     // PDF 译文与文本元素的匹配模式：strict 只做精确和标准化匹配，为空时依次尝试所有方式
+	MergeStrategy string `json:"mergeStrategy,omitempty"` // PDF 文本碎片的合并策略：conservative、aggressive、line-based 或 none，为空时使用默认阈值
+	DiffOverlay   bool   `json:"diffOverlay,omitempty"`   // 单语 PDF 以原页面为底图，只遮罩并重绘译文与原文不同的文本
+	TextExtractor string `json:"textExtractor,omitempty"` // PDF 文本提取后端：auto、ledongthuc、pdfcpu 或 pdftotext，为空时使用默认解析
+
+	OnRegenerationFailure string `json:"onRegenerationFailure,omitempty"` // PDF 重新生成失败时的处理方式：error（默认）、text-fallback、html-fallback 或 original-copy
+
+	SelfRateConfidence  bool    `json:"selfRateConfidence,omitempty"`  // 要求大模型为每条译文自评可信度（0~1）
+	ConfidenceThreshold float64 `json:"confidenceThreshold,omitempty"` // 自评可信度低于此值的文本块列入 lowConfidenceBlocks，为空时为 0.6
+	MarkLowConfidence   bool    `json:"markLowConfidence,omitempty"`   // 在输出文件中为低可信度的译文追加标记“(?)”
+
+	Granularity string `json:"granularity,omitempty"` // 发送给翻译服务的文本单位：block（默认）、sentence 或 page（仅 PDF，按页合并请求）
+
+	CSVColumns []string `json:"csvColumns,omitempty"` // CSV/TSV 需要翻译的列：列号（从 0 开始）或表头名称，为空时翻译所有列
+	CSVHeader  bool     `json:"csvHeader,omitempty"`  // CSV/TSV 首行为表头，不翻译（按表头名称选择列时自动启用）
+
+	Retranslate []string          `json:"-"` // 重新翻译指定文本块时，这些原文不读取缓存，其余文本块使用缓存中的译文
+	Edits       map[string]string `json:"-"` // 校对时人工修改的译文：原文 -> 译文，直接使用，不请求翻译服务
+}
+
+// TranslateTextRequest 同步文本翻译请求
+type TranslateTextRequest struct {
+	Text           string    `json:"text"`
+	TargetLanguage string    `json:"targetLanguage"`
+	UserPrompt     string    `json:"userPrompt,omitempty"`
+	Formality      string    `json:"formality,omitempty"`
+	LLMConfig      LLMConfig `json:"llmConfig"`
+	Markdown       bool      `json:"markdown,omitempty"` // 按 Markdown 翻译：标记符号、链接地址和代码不发送给翻译服务
+}
+
+// CacheWarmRequest 缓存预热请求（JSON 方式；上传 TMX 时使用表单字段）
+type CacheWarmRequest struct {
+	Strings        []string  `json:"strings"`
+	TargetLanguage string    `json:"targetLanguage"`
+	DocumentType   string    `json:"documentType,omitempty"` // 之后要翻译的文档类型（epub、pdf、pptx、csv、tsv），PDF 的缓存键使用语言代码
+	UserPrompt     string    `json:"userPrompt,omitempty"`
+	Formality      string    `json:"formality,omitempty"`
+	Concurrency    int       `json:"concurrency,omitempty"`
+	BatchSize      int       `json:"batchSize,omitempty"`
+	LLMConfig      LLMConfig `json:"llmConfig"`
+}
+
+// BlockFilterConfig 文本块过滤规则配置，未设置的字段使用默认值
+type BlockFilterConfig struct {
+	MinLength       *int     `json:"minLength,omitempty"`       // 最小字符数
+	MinWords        *int     `json:"minWords,omitempty"`        // 最少单词数
+	SkipPureNumbers *bool    `json:"skipPureNumbers,omitempty"` // 是否跳过纯数字
+	SkipCitations   *bool    `json:"skipCitations,omitempty"`   // 是否跳过引用标记
+	SkipPatterns    []string `json:"skipPatterns,omitempty"`    // 自定义跳过规则（正则表达式）
 }
@@ -0,0 +1,138 @@
+package translator
+
+import (
+	"log"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// DefaultBackTranslationThreshold 回译与原文的相似度低于该值时视为译文可能有误
+const DefaultBackTranslationThreshold = 0.5
+
+// BackTranslationCheck 单个文本块的回译检查结果
+type BackTranslationCheck struct {
+	Original        string  // 原文
+	Translation     string  // 译文
+	BackTranslation string  // 译文翻译回源语言的结果
+	Similarity      float64 // 回译与原文的相似度（0-1）
+}
+
+// BackTranslate 将译文翻译回源语言并与原文比较，返回相似度低于 threshold 的文本块（按相似度升序）
+// pairs 为原文 -> 译文；sourceLanguage 为空时按每个文本块的文字检测源语言
+// 回译会再次请求翻译服务，开销约为原翻译的一倍
+func (c *TranslatorClient) BackTranslate(pairs map[string]string, sourceLanguage, userPrompt string, threshold float64) []BackTranslationCheck {
+	if threshold <= 0 {
+		threshold = DefaultBackTranslationThreshold
+	}
+
+	// 按源语言分组，每组回译一次
+	groups := make(map[string][]string)
+	for original, translation := range pairs {
+		if strings.TrimSpace(translation) == "" || translation == original {
+			continue
+		}
+		lang := sourceLanguage
+		if lang == "" {
+			lang = detectSourceLanguage(original)
+		}
+		groups[lang] = append(groups[lang], original)
+	}
+
+	// 回译结果不应触发原翻译的结果回调（流式输出、翻译记忆等）
+	back := *c
+	back.OnResult = nil
+
+	var flagged []BackTranslationCheck
+	for lang, originals := range groups {
+		sort.Strings(originals)
+		translations := make([]string, len(originals))
+		for i, original := range originals {
+			translations[i] = pairs[original]
+		}
+
+		for i, result := range back.TranslateBlocks(translations, lang, userPrompt, nil) {
+			if result.Err != nil {
+				log.Printf("回译失败，跳过检查: %v", result.Err)
+				continue
+			}
+			check := BackTranslationCheck{
+				Original:        originals[i],
+				Translation:     translations[i],
+				BackTranslation: result.Translated,
+				Similarity:      TextSimilarity(originals[i], result.Translated),
+			}
+			if check.Similarity < threshold {
+				flagged = append(flagged, check)
+			}
+		}
+	}
+
+	sort.Slice(flagged, func(i, j int) bool {
+		if flagged[i].Similarity != flagged[j].Similarity {
+			return flagged[i].Similarity < flagged[j].Similarity
+		}
+		return flagged[i].Original < flagged[j].Original
+	})
+	return flagged
+}
+
+// TextSimilarity 基于最长公共子序列计算两段文本的相似度（0-1）
+// 比较前规范化文本并忽略大小写和标点；以空格分词的文字按单词比较，汉字、假名、谚文按字比较
+func TextSimilarity(a, b string) float64 {
+	tokens1 := similarityTokens(a)
+	tokens2 := similarityTokens(b)
+	maxLen := max(len(tokens1), len(tokens2))
+	if maxLen == 0 {
+		return 1.0
+	}
+	return float64(lcsLength(tokens1, tokens2)) / float64(maxLen)
+}
+
+// similarityTokens 将文本切分为用于相似度比较的词元
+func similarityTokens(text string) []string {
+	var tokens []string
+	var word strings.Builder
+	flush := func() {
+		if word.Len() > 0 {
+			tokens = append(tokens, word.String())
+			word.Reset()
+		}
+	}
+
+	for _, r := range strings.ToLower(NormalizeText(text)) {
+		switch {
+		case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul):
+			flush()
+			tokens = append(tokens, string(r))
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			word.WriteRune(r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return tokens
+}
+
+// lcsLength 计算最长公共子序列长度
+func lcsLength[T comparable](s1, s2 []T) int {
+	if len(s1) == 0 || len(s2) == 0 {
+		return 0
+	}
+
+	// 只保留上一行，节省内存
+	prev := make([]int, len(s2)+1)
+	curr := make([]int, len(s2)+1)
+	for i := 1; i <= len(s1); i++ {
+		for j := 1; j <= len(s2); j++ {
+			if s1[i-1] == s2[j-1] {
+				curr[j] = prev[j-1] + 1
+			} else {
+				curr[j] = max(prev[j], curr[j-1])
+			}
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(s2)]
+}
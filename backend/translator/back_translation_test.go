@@ -0,0 +1,69 @@
+package translator
+
+import (
+	"fmt"
+	"testing"
+)
+
+// replyProvider 按固定对照表返回译文的测试提供商，记录每次请求的目标语言
+type replyProvider struct {
+	stubProvider
+	replies map[string]string
+	targets []string
+}
+
+func (p *replyProvider) Translate(text, targetLanguage, userPrompt string) (string, error) {
+	p.targets = append(p.targets, targetLanguage)
+	reply, ok := p.replies[text]
+	if !ok {
+		return "", fmt.Errorf("replyProvider: 没有 %q 的译文", text)
+	}
+	return reply, nil
+}
+
+func TestBackTranslateFlagsDivergentBlocks(t *testing.T) {
+	provider := &replyProvider{replies: map[string]string{
+		"Le chat dort sur le canapé.":  "The cat is sleeping on the sofa.",
+		"Le marché a fortement chuté.": "Bananas are yellow fruit.",
+	}}
+	client := &TranslatorClient{Provider: provider, Filter: NewBlockFilter(), Concurrency: 1}
+
+	flagged := client.BackTranslate(map[string]string{
+		"The cat is sleeping on the sofa.": "Le chat dort sur le canapé.",
+		"The market fell sharply today.":   "Le marché a fortement chuté.",
+	}, "English", "", 0)
+
+	if len(flagged) != 1 {
+		t.Fatalf("标记了 %d 个文本块，期望 1: %+v", len(flagged), flagged)
+	}
+	check := flagged[0]
+	if check.Original != "The market fell sharply today." || check.BackTranslation != "Bananas are yellow fruit." {
+		t.Errorf("标记的文本块 = %+v", check)
+	}
+	if check.Similarity >= DefaultBackTranslationThreshold {
+		t.Errorf("相似度 = %.2f，应低于 %.2f", check.Similarity, DefaultBackTranslationThreshold)
+	}
+	for _, target := range provider.targets {
+		if target != "English" {
+			t.Errorf("回译的目标语言 = %q，期望源语言 English", target)
+		}
+	}
+}
+
+func TestTextSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"The cat sleeps.", "the CAT sleeps", 1},
+		{"The cat sleeps.", "The dog sleeps.", 2.0 / 3},
+		{"猫在睡觉", "猫在吃饭", 0.5},
+		{"", "", 1},
+		{"Hello", "", 0},
+	}
+	for _, tt := range tests {
+		if got := TextSimilarity(tt.a, tt.b); got < tt.want-1e-9 || got > tt.want+1e-9 {
+			t.Errorf("TextSimilarity(%q, %q) = %.3f，期望 %.3f", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package translator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// 默认过滤阈值
+const (
+	DefaultMinBlockLength = 3 // 少于该字符数的文本块不翻译
+	maxPureNumberLength   = 10
+	maxCitationLength     = 20
+)
+
+// BlockFilter 文本块过滤规则，决定哪些文本块需要发送给翻译服务
+// 被过滤的文本块保留原文
+type BlockFilter struct {
+	MinLength       int              // 最小字符数（按去除首尾空白后的字符计）
+	MinWords        int              // 最少单词数，包含 CJK 字符的文本不受此限制
+	SkipPureNumbers bool             // 跳过纯数字文本（页码、编号等）
+	SkipCitations   bool             // 跳过引用标记，如 [12]、(3)
+	SkipPatterns    []*regexp.Regexp // 自定义跳过规则，匹配任意一条即跳过
+
+	decisions *sync.Map // 文本 -> 是否翻译，不为空时每段文本只判断一次（见 Memoize）
+}
+
+// NewBlockFilter 创建默认文本块过滤器
+func NewBlockFilter() *BlockFilter {
+	return &BlockFilter{
+		MinLength:       DefaultMinBlockLength,
+		SkipPureNumbers: true,
+		SkipCitations:   true,
+	}
+}
+
+// WithMinLength 设置最小字符数
+func (f *BlockFilter) WithMinLength(n int) *BlockFilter {
+	f.MinLength = n
+	return f
+}
+
+// WithMinWords 设置最少单词数
+func (f *BlockFilter) WithMinWords(n int) *BlockFilter {
+	f.MinWords = n
+	return f
+}
+
+// AddSkipPatterns 添加自定义跳过规则（正则表达式）
+func (f *BlockFilter) AddSkipPatterns(patterns ...string) error {
+	for _, pattern := range patterns {
+		if strings.TrimSpace(pattern) == "" {
+			continue
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("跳过规则 %q 不是有效的正则表达式: %w", pattern, err)
+		}
+		f.SkipPatterns = append(f.SkipPatterns, re)
+	}
+	return nil
+}
+
+// Memoize 记住每段文本的判断结果，同一过滤器用于多次翻译（如多种目标语言）时每段文本只判断一次
+// 调用后不应再修改过滤规则
+func (f *BlockFilter) Memoize() *BlockFilter {
+	f.decisions = &sync.Map{}
+	return f
+}
+
+// ShouldTranslate 判断文本块是否需要翻译
+// 过滤器为 nil 时只跳过空白文本
+func (f *BlockFilter) ShouldTranslate(text string) bool {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return false
+	}
+	if f == nil {
+		return true
+	}
+	if f.decisions == nil {
+		return f.matches(text)
+	}
+
+	if decision, ok := f.decisions.Load(text); ok {
+		return decision.(bool)
+	}
+	decision := f.matches(text)
+	f.decisions.Store(text, decision)
+	return decision
+}
+
+// matches 按过滤规则判断去除首尾空白后的文本是否需要翻译
+func (f *BlockFilter) matches(text string) bool {
+	if len([]rune(text)) < f.MinLength {
+		return false
+	}
+	if f.MinWords > 1 && !containsCJK(text) && len(strings.Fields(text)) < f.MinWords {
+		return false
+	}
+	if f.SkipPureNumbers && isPureNumber(text) {
+		return false
+	}
+	if f.SkipCitations && isCitation(text) {
+		return false
+	}
+	for _, re := range f.SkipPatterns {
+		if re.MatchString(text) {
+			return false
+		}
+	}
+	return true
+}
+
+// isPureNumber 判断文本是否只由数字及常见分隔符组成（如页码 "12"、"3-4"、"1.2"）
+func isPureNumber(text string) bool {
+	if len(text) >= maxPureNumberLength {
+		return false
+	}
+	hasDigit := false
+	for _, r := range text {
+		switch {
+		case r >= '0' && r <= '9':
+			hasDigit = true
+		case r == '.' || r == '-' || r == ' ':
+		default:
+			return false
+		}
+	}
+	return hasDigit
+}
+
+// isCitation 判断文本是否为 [12] 或 (3) 形式的引用标记
+func isCitation(text string) bool {
+	if len(text) >= maxCitationLength {
+		return false
+	}
+	return (strings.HasPrefix(text, "[") && strings.HasSuffix(text, "]")) ||
+		(strings.HasPrefix(text, "(") && strings.HasSuffix(text, ")"))
+}
+
+// containsCJK 判断文本是否包含中日韩字符
+func containsCJK(text string) bool {
+	for _, r := range text {
+		if unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) ||
+			unicode.Is(unicode.Katakana, r) || unicode.Is(unicode.Hangul, r) {
+			return true
+		}
+	}
+	return false
+}
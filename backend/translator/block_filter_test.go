@@ -0,0 +1,79 @@
+package translator
+
+import "testing"
+
+func TestBlockFilterDefaults(t *testing.T) {
+	filter := NewBlockFilter()
+	tests := []struct {
+		text string
+		want bool
+	}{
+		{"12", false},
+		{"  3-4 ", false},
+		{"[12]", false},
+		{"(3)", false},
+		{"", false},
+		{"The quick brown fox jumps over the lazy dog.", true},
+		{"快速的狐狸", true},
+	}
+	for _, tt := range tests {
+		if got := filter.ShouldTranslate(tt.text); got != tt.want {
+			t.Errorf("ShouldTranslate(%q) = %v，期望 %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestBlockFilterThresholds(t *testing.T) {
+	filter := NewBlockFilter().WithMinLength(10).WithMinWords(3)
+	tests := []struct {
+		text string
+		want bool
+	}{
+		{"Short", false},
+		{"Introduction", false},
+		{"Results and discussion", true},
+		{"快速的狐狸跳过了懒狗", true},
+	}
+	for _, tt := range tests {
+		if got := filter.ShouldTranslate(tt.text); got != tt.want {
+			t.Errorf("ShouldTranslate(%q) = %v，期望 %v", tt.text, got, tt.want)
+		}
+	}
+
+	filter = NewBlockFilter()
+	filter.SkipPureNumbers = false
+	filter.SkipCitations = false
+	if !filter.ShouldTranslate("123") || !filter.ShouldTranslate("[12]") {
+		t.Error("关闭数字和引用规则后，页码和引用标记应需要翻译")
+	}
+}
+
+func TestBlockFilterSkipPatterns(t *testing.T) {
+	filter := NewBlockFilter()
+	if err := filter.AddSkipPatterns(`^Figure \d+`, "  "); err != nil {
+		t.Fatalf("添加跳过规则失败: %v", err)
+	}
+	if len(filter.SkipPatterns) != 1 {
+		t.Fatalf("空白规则应被忽略，实际规则数 %d", len(filter.SkipPatterns))
+	}
+	if filter.ShouldTranslate("Figure 3: System overview") {
+		t.Error("匹配自定义规则的文本不应翻译")
+	}
+	if !filter.ShouldTranslate("The system overview is shown below.") {
+		t.Error("未匹配自定义规则的文本应翻译")
+	}
+
+	if err := NewBlockFilter().AddSkipPatterns("[unclosed"); err == nil {
+		t.Error("无效的正则表达式应返回错误")
+	}
+}
+
+func TestNilBlockFilterSkipsOnlyBlankText(t *testing.T) {
+	var filter *BlockFilter
+	if filter.ShouldTranslate("   ") {
+		t.Error("空白文本不应翻译")
+	}
+	if !filter.ShouldTranslate("12") {
+		t.Error("过滤器为 nil 时非空白文本应翻译")
+	}
+}
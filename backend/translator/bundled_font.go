@@ -0,0 +1,75 @@
+package translator
+
+import (
+	"embed"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// bundledFonts 内置回退字体目录
+//
+//go:embed fonts
+var bundledFonts embed.FS
+
+var (
+	bundledFontFS   fs.FS = bundledFonts // 读取内置字体的文件系统
+	bundledFontOnce sync.Once
+	bundledFontPath string
+)
+
+// BundledFontPath 返回内置回退字体解压后的文件路径，没有内置字体时返回空字符串
+func BundledFontPath() string {
+	bundledFontOnce.Do(func() {
+		entries, err := fs.ReadDir(bundledFontFS, "fonts")
+		if err != nil {
+			return
+		}
+
+		for _, entry := range entries {
+			ext := strings.ToLower(path.Ext(entry.Name()))
+			if entry.IsDir() || (ext != ".ttf" && ext != ".otf") {
+				continue
+			}
+
+			data, err := fs.ReadFile(bundledFontFS, path.Join("fonts", entry.Name()))
+			if err != nil {
+				log.Printf("警告：读取内置字体失败: %v", err)
+				continue
+			}
+
+			// gofpdf 需要字体文件路径，解压到临时目录
+			fontDir := filepath.Join(os.TempDir(), "translator-web-fonts")
+			if err := os.MkdirAll(fontDir, 0755); err != nil {
+				log.Printf("警告：创建字体目录失败: %v", err)
+				return
+			}
+
+			target := filepath.Join(fontDir, entry.Name())
+			if info, err := os.Stat(target); err != nil || info.Size() != int64(len(data)) {
+				if err := os.WriteFile(target, data, 0644); err != nil {
+					log.Printf("警告：解压内置字体失败: %v", err)
+					return
+				}
+			}
+
+			bundledFontPath = target
+			return
+		}
+	})
+	return bundledFontPath
+}
+
+// isCJKLanguage 判断语言是否需要 CJK 字体
+func isCJKLanguage(language string) bool {
+	switch strings.ToLower(language) {
+	case "zh", "uni", "zh-cn", "zh-tw", "zh-hk", "ja", "japanese", "ko", "korean":
+		return true
+	default:
+		return false
+	}
+}
@@ -1,41 +1,85 @@
 package translator
 
 import (
+	"container/list"
 	"crypto/sha256"
 	"encoding/hex"
+	"log"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
+	"sync/atomic"
 )
 
-// Cache 翻译缓存
+// CacheStore 翻译缓存存储接口
+type CacheStore interface {
+	Get(key string) (string, bool)
+	Set(key, value string) error
+	DisableCache()
+	EnableCache()
+}
+
+// Cache 翻译缓存（磁盘存储，不可写时回退到内存）
 type Cache struct {
 	dir      string
 	mutex    sync.RWMutex
-	disabled bool // 是否禁用缓存
+	disabled atomic.Bool  // 是否禁用缓存，可在翻译进行中切换
+	memory   *MemoryCache // 磁盘不可写时使用的内存缓存
 }
 
 // NewCache 创建缓存
+// 目录无法创建或不可写时（如只读容器文件系统）记录警告并回退到内存缓存
 func NewCache(dir string) (*Cache, error) {
+	if err := checkCacheDir(dir); err != nil {
+		log.Printf("警告：缓存目录不可用，回退到内存缓存: %v", err)
+		return &Cache{dir: dir, memory: NewMemoryCache()}, nil
+	}
+	return &Cache{dir: dir}, nil
+}
+
+// checkCacheDir 检查缓存目录是否可创建且可写
+func checkCacheDir(dir string) error {
 	if err := os.MkdirAll(dir, 0755); err != nil {
-		return nil, err
+		return err
+	}
+
+	probe, err := os.CreateTemp(dir, ".probe-*")
+	if err != nil {
+		return err
 	}
-	return &Cache{dir: dir, disabled: false}, nil
+	probe.Close()
+	return os.Remove(probe.Name())
+}
+
+// IsMemoryFallback 是否已回退到内存缓存
+func (c *Cache) IsMemoryFallback() bool {
+	return c.memory != nil
 }
 
 // DisableCache 禁用缓存（用于强制重新翻译）
 func (c *Cache) DisableCache() {
-	c.disabled = true
+	c.disabled.Store(true)
+	if c.memory != nil {
+		c.memory.DisableCache()
+	}
 }
 
 // EnableCache 启用缓存
 func (c *Cache) EnableCache() {
-	c.disabled = false
+	c.disabled.Store(false)
+	if c.memory != nil {
+		c.memory.EnableCache()
+	}
 }
 
 // Get 获取缓存
 func (c *Cache) Get(key string) (string, bool) {
-	if c.disabled {
+	if c.memory != nil {
+		return c.memory.Get(key)
+	}
+
+	if c.disabled.Load() {
 		return "", false
 	}
 
@@ -55,7 +99,11 @@ func (c *Cache) Get(key string) (string, bool) {
 
 // Set 设置缓存
 func (c *Cache) Set(key, value string) error {
-	if c.disabled {
+	if c.memory != nil {
+		return c.memory.Set(key, value)
+	}
+
+	if c.disabled.Load() {
 		return nil // 禁用时不写入
 	}
 
@@ -74,14 +122,207 @@ func (c *Cache) hashKey(key string) string {
 	return hex.EncodeToString(hash[:])
 }
 
-// CacheKey 生成缓存键
-func CacheKey(text, targetLanguage, userPrompt string) string {
+// MemoryCache 内存翻译缓存，适用于无状态/临时部署
+type MemoryCache struct {
+	entries    map[string]*list.Element
+	order      *list.List // 最近使用的条目在前
+	maxEntries int        // 最大条目数，0 表示不限制
+	mutex      sync.RWMutex
+	disabled   atomic.Bool // 是否禁用缓存，可在翻译进行中切换
+}
+
+// memoryCacheEntry 内存缓存条目
+type memoryCacheEntry struct {
+	key   string
+	value string
+}
+
+// NewMemoryCache 创建内存缓存
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// WithMaxEntries 设置最大条目数，超出时按LRU淘汰
+func (c *MemoryCache) WithMaxEntries(maxEntries int) *MemoryCache {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.maxEntries = maxEntries
+	c.evict()
+	return c
+}
+
+// DisableCache 禁用缓存（用于强制重新翻译）
+func (c *MemoryCache) DisableCache() {
+	c.disabled.Store(true)
+}
+
+// EnableCache 启用缓存
+func (c *MemoryCache) EnableCache() {
+	c.disabled.Store(false)
+}
+
+// Get 获取缓存
+func (c *MemoryCache) Get(key string) (string, bool) {
+	if c.disabled.Load() {
+		return "", false
+	}
+
+	// 不限制条目数时无需维护LRU顺序，使用读锁即可
+	// maxEntries 可被 WithMaxEntries 修改，需在持有锁时读取
+	c.mutex.RLock()
+	if c.maxEntries <= 0 {
+		defer c.mutex.RUnlock()
+
+		if elem, ok := c.entries[key]; ok {
+			return elem.Value.(*memoryCacheEntry).value, true
+		}
+		return "", false
+	}
+	c.mutex.RUnlock()
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return "", false
+	}
+	c.order.MoveToFront(elem)
+	return elem.Value.(*memoryCacheEntry).value, true
+}
+
+// Set 设置缓存
+func (c *MemoryCache) Set(key, value string) error {
+	if c.disabled.Load() {
+		return nil // 禁用时不写入
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		elem.Value.(*memoryCacheEntry).value = value
+		c.order.MoveToFront(elem)
+		return nil
+	}
+
+	c.entries[key] = c.order.PushFront(&memoryCacheEntry{key: key, value: value})
+	c.evict()
+	return nil
+}
+
+// Len 返回缓存条目数
+func (c *MemoryCache) Len() int {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return len(c.entries)
+}
+
+// evict 淘汰超出上限的最久未使用条目（调用方需持有写锁）
+func (c *MemoryCache) evict() {
+	if c.maxEntries <= 0 {
+		return
+	}
+
+	for c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*memoryCacheEntry).key)
+	}
+}
+
+// TranslateOptions 影响译文、参与缓存键计算的翻译选项
+// 只包含实际发送给翻译服务的内容，与文档格式和提供商的请求格式无关
+type TranslateOptions struct {
+	UserPrompt         string // 自定义提示词
+	PromptTemplate     string // 自定义系统提示词模板
+	Domain             string // 领域（提示词模板变量）
+	Glossary           string // 术语表
+	Formality          string // 语气：formal、informal、neutral
+	SelfRateConfidence bool   // 回复为带自评可信度的 JSON
+}
+
+// effectivePrompt 返回参与缓存键计算的提示词，使不同模板、术语表、语气的翻译结果互不冲突
+func (o TranslateOptions) effectivePrompt() string {
+	prompt := o.UserPrompt
+	if o.PromptTemplate != "" {
+		prompt += "|" + o.PromptTemplate + "|" + o.Domain + "|" + o.Glossary
+	} else if o.Glossary != "" {
+		prompt += "|glossary=" + o.Glossary
+	}
+	if formalityInstruction(o.Formality) != "" {
+		prompt += "|formality=" + o.Formality
+	}
+	// 带自评可信度的回复为 JSON，与普通译文分开缓存
+	if o.SelfRateConfidence {
+		prompt += "|confidence"
+	}
+	return prompt
+}
+
+// CacheKeyWithOptions 生成缓存键，只取决于规范化的原文、目标语言和有效的提示词、术语表、语气
+// 文本先经 NormalizeText 规范化，目标语言的代码和名称（如 zh、Uni）视为相同，
+// 因此 PDF 和 EPUB 等不同格式中的相同句子共用一个缓存条目
+func CacheKeyWithOptions(text, targetLanguage string, opts TranslateOptions) string {
 	// 使用哈希而不是JSON来避免键顺序问题
 	h := sha256.New()
-	h.Write([]byte(text))
+	h.Write([]byte(NormalizeText(text)))
 	h.Write([]byte("|")) // 分隔符
-	h.Write([]byte(targetLanguage))
+	h.Write([]byte(canonicalCacheLanguage(targetLanguage)))
 	h.Write([]byte("|"))
-	h.Write([]byte(userPrompt))
+	h.Write([]byte(opts.effectivePrompt()))
 	return hex.EncodeToString(h.Sum(nil))
 }
+
+// CacheKey 生成只带自定义提示词的缓存键，等同于 CacheKeyWithOptions(text, targetLanguage, TranslateOptions{UserPrompt: userPrompt})
+func CacheKey(text, targetLanguage, userPrompt string) string {
+	return CacheKeyWithOptions(text, targetLanguage, TranslateOptions{UserPrompt: userPrompt})
+}
+
+// canonicalCacheLanguage 将语言代码或名称统一为 commonLanguages 中的英文名称（如 zh、zh-CN、uni -> Uni），
+// 无法识别的语言原样使用
+func canonicalCacheLanguage(language string) string {
+	language = strings.TrimSpace(language)
+	lower := strings.ToLower(language)
+	if lower == "zh-cn" {
+		lower = "zh"
+	}
+	for code, name := range commonLanguages {
+		if strings.ToLower(code) == lower || strings.ToLower(name) == lower {
+			return name
+		}
+	}
+	return language
+}
+
+// SelectiveCache 对指定的缓存键跳过读取（视为未命中），其余读取和所有写入交给底层缓存
+// 用于只重新翻译部分文本块：新译文写入时覆盖旧的缓存条目
+type SelectiveCache struct {
+	CacheStore
+	bypass map[string]bool
+}
+
+// BypassCacheFor 返回对指定原文不读取缓存的包装，缓存键与翻译和 PrimeCache 使用的相同
+func BypassCacheFor(cache CacheStore, config ProviderConfig, docType DocumentType, texts []string, targetLanguage, userPrompt string) *SelectiveCache {
+	targetLanguage = translationTargetLanguage(docType, targetLanguage)
+	base := &BaseProvider{Config: config}
+	bypass := make(map[string]bool, len(texts))
+	for _, text := range texts {
+		if block := prepareBlock(text, nil); block.body != "" {
+			bypass[CacheKeyWithOptions(block.body, targetLanguage, base.translateOptions(userPrompt))] = true
+		}
+	}
+	return &SelectiveCache{CacheStore: cache, bypass: bypass}
+}
+
+// Get 获取缓存，需要重新翻译的文本视为未命中
+func (c *SelectiveCache) Get(key string) (string, bool) {
+	if c.bypass[key] {
+		return "", false
+	}
+	return c.CacheStore.Get(key)
+}
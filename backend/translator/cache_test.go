@@ -0,0 +1,205 @@
+package translator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestMemoryCacheDisable(t *testing.T) {
+	cache := NewMemoryCache()
+	cache.Set("key", "value")
+
+	cache.DisableCache()
+	if _, ok := cache.Get("key"); ok {
+		t.Error("禁用缓存后不应命中")
+	}
+	cache.Set("other", "value")
+
+	cache.EnableCache()
+	if got, ok := cache.Get("key"); !ok || got != "value" {
+		t.Errorf("启用缓存后 Get = %q, %v", got, ok)
+	}
+	if _, ok := cache.Get("other"); ok {
+		t.Error("禁用期间不应写入缓存")
+	}
+}
+
+func TestMemoryCacheGetAfterSet(t *testing.T) {
+	cache := NewMemoryCache().WithMaxEntries(2)
+	if _, ok := cache.Get("a"); ok {
+		t.Error("空缓存不应命中")
+	}
+	cache.Set("a", "1")
+	cache.Set("b", "2")
+	if got, ok := cache.Get("a"); !ok || got != "1" {
+		t.Errorf("Get(a) = %q, %v，期望 1", got, ok)
+	}
+
+	// 超出上限时淘汰最久未使用的 b
+	cache.Set("c", "3")
+	if _, ok := cache.Get("b"); ok {
+		t.Error("b 应被淘汰")
+	}
+	if got, ok := cache.Get("a"); !ok || got != "1" {
+		t.Errorf("最近使用的 a 不应被淘汰: %q, %v", got, ok)
+	}
+	if cache.Len() != 2 {
+		t.Errorf("Len = %d，期望 2", cache.Len())
+	}
+}
+
+// TestTranslateWithMemoryCache 缓存目录不可写时回退到内存缓存，相同文本第二次翻译命中缓存
+func TestTranslateWithMemoryCache(t *testing.T) {
+	blocker := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(blocker, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	cache, err := NewCache(filepath.Join(blocker, "cache"))
+	if err != nil {
+		t.Fatalf("NewCache: %v", err)
+	}
+	if !cache.IsMemoryFallback() {
+		t.Fatal("缓存目录无法创建时应回退到内存缓存")
+	}
+
+	stub := newOpenAIStub(t, nil)
+	client, err := NewTranslatorClient(stub.Config(), cache)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		got, err := client.Translate("Hello, world.", "Uni", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != "[译] Hello, world." {
+			t.Errorf("第 %d 次翻译 = %q", i+1, got)
+		}
+	}
+	if n := len(stub.Requests()); n != 1 {
+		t.Errorf("翻译服务收到 %d 次请求，第二次应命中内存缓存", n)
+	}
+}
+
+// TestCacheToggleConcurrently 翻译进行中切换缓存开关，配合 -race 检查数据竞争
+func TestCacheToggleConcurrently(t *testing.T) {
+	disk, err := NewCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewCache: %v", err)
+	}
+	for name, cache := range map[string]CacheStore{"memory": NewMemoryCache(), "disk": disk} {
+		t.Run(name, func(t *testing.T) {
+			var wg sync.WaitGroup
+			for i := 0; i < 4; i++ {
+				wg.Add(2)
+				go func() {
+					defer wg.Done()
+					for j := 0; j < 100; j++ {
+						cache.Set("key", "value")
+						cache.Get("key")
+					}
+				}()
+				go func() {
+					defer wg.Done()
+					for j := 0; j < 100; j++ {
+						cache.DisableCache()
+						cache.EnableCache()
+					}
+				}()
+			}
+			wg.Wait()
+		})
+	}
+}
+
+func TestCacheKeyWithOptions(t *testing.T) {
+	base := CacheKeyWithOptions("Hello,  world.", "Uni", TranslateOptions{})
+	for _, same := range []struct {
+		text, language string
+	}{
+		{"Hello, world.", "zh"},
+		{"Hello,\t world.", "zh-CN"},
+		{" Hello, world. ", "uni"},
+	} {
+		if got := CacheKeyWithOptions(same.text, same.language, TranslateOptions{}); got != base {
+			t.Errorf("CacheKeyWithOptions(%q, %q) 与规范化后相同的文本不一致", same.text, same.language)
+		}
+	}
+	if CacheKey("Hello, world.", "Uni", "") != base {
+		t.Error("CacheKey 应等同于只带提示词的 CacheKeyWithOptions")
+	}
+	for name, opts := range map[string]TranslateOptions{
+		"提示词": {UserPrompt: "be brief"},
+		"术语表": {Glossary: "world => 世界"},
+		"语气":  {Formality: "formal"},
+		"可信度": {SelfRateConfidence: true},
+	} {
+		if CacheKeyWithOptions("Hello, world.", "Uni", opts) == base {
+			t.Errorf("%s不同时缓存键应不同", name)
+		}
+	}
+}
+
+func TestPDFAndEPUBShareCacheEntry(t *testing.T) {
+	t.Chdir(t.TempDir())
+	sentence := "The same sentence appears in both editions of the paper."
+	stub := newOpenAIStub(t, nil)
+	cache := NewMemoryCache()
+	client, err := NewTranslatorClient(stub.Config(), cache)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dt := &DocumentTranslator{Client: client, PDFMathTranslator: NewPDFMathTranslator()}
+
+	epub := writeTestEPUB(t, t.TempDir(), []string{sentence})
+	if _, err := dt.TranslateDocument(epub, filepath.Join(t.TempDir(), "out.epub"), "zh", "", false, "monolingual", nil); err != nil {
+		t.Fatal(err)
+	}
+	requests := len(stub.Requests())
+	entries := cache.Len()
+	if requests == 0 || entries == 0 {
+		t.Fatalf("EPUB 翻译后请求 %d 次、缓存 %d 条", requests, entries)
+	}
+
+	// 默认的 ledongthuc 提取会丢失测试PDF中的空格，这里使用保留空格的 pdfcpu 后端，只比较缓存键
+	pdf := writeTestPDF(t, t.TempDir(), []string{sentence})
+	dt = &DocumentTranslator{Client: client, PDFMathTranslator: NewPDFMathTranslator(), OutputFormats: []string{string(OutputFormatText)}, TextExtractor: TextExtractorPDFCPU}
+	output, err := dt.TranslateDocument(pdf, filepath.Join(t.TempDir(), "paper.pdf"), "Uni", "", false, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(stub.Requests()); n != requests {
+		t.Errorf("PDF 翻译又请求了 %d 次，相同句子应命中 EPUB 写入的缓存", n-requests)
+	}
+	if n := cache.Len(); n != entries {
+		t.Errorf("缓存条目 %d -> %d，相同句子应共用一个条目", entries, n)
+	}
+	if content, _ := os.ReadFile(output); !strings.Contains(string(content), "[译] ") {
+		t.Errorf("PDF 输出缺少缓存中的译文: %q", content)
+	}
+}
+
+// TestMemoryCacheResizeConcurrently 读取缓存时修改条目上限，配合 -race 检查数据竞争
+func TestMemoryCacheResizeConcurrently(t *testing.T) {
+	cache := NewMemoryCache()
+	cache.Set("key", "value")
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for j := 0; j < 100; j++ {
+			cache.Get("key")
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for j := 0; j < 100; j++ {
+			cache.WithMaxEntries(j % 2)
+		}
+	}()
+	wg.Wait()
+}
@@ -0,0 +1,122 @@
+package translator
+
+import (
+	"sync"
+)
+
+// CacheWarmProgress 缓存预热进度
+type CacheWarmProgress struct {
+	Total  int `json:"total"`  // 去重后的文本数
+	Cached int `json:"cached"` // 预热前已在缓存中、跳过的文本数
+	Warmed int `json:"warmed"` // 本次翻译并写入缓存的文本数
+	Failed int `json:"failed"` // 翻译失败的文本数
+	Done   int `json:"done"`   // 已处理的文本数（含跳过、被过滤和失败）
+}
+
+// CacheWarmer 按已知原文预先翻译并写入缓存，之后翻译相似文档时直接命中缓存
+// 已在缓存中的文本会被跳过，中断后再次预热即可从未完成的部分继续
+// 翻译请求经由 TranslatorClient 发出，遵循其并发数、批大小、重试和任务上下文
+type CacheWarmer struct {
+	Client         *TranslatorClient
+	Cache          CacheStore   // 翻译客户端使用的缓存，用于判断是否已缓存
+	DocType        DocumentType // 预热结果所针对的文档类型，PDF 流程的目标语言会转换为语言代码
+	TargetLanguage string
+	UserPrompt     string
+
+	// OnProgress 每处理完一个文本后回调
+	OnProgress func(progress CacheWarmProgress)
+}
+
+// NewCacheWarmer 创建缓存预热器，cache 应与创建 client 时使用的缓存相同
+func NewCacheWarmer(client *TranslatorClient, cache CacheStore, targetLanguage, userPrompt string) *CacheWarmer {
+	return &CacheWarmer{
+		Client:         client,
+		Cache:          cache,
+		TargetLanguage: targetLanguage,
+		UserPrompt:     userPrompt,
+	}
+}
+
+// WithDocumentType 设置预热结果所针对的文档类型
+func (w *CacheWarmer) WithDocumentType(docType DocumentType) *CacheWarmer {
+	w.DocType = docType
+	return w
+}
+
+// WithProgress 设置进度回调
+func (w *CacheWarmer) WithProgress(onProgress func(progress CacheWarmProgress)) *CacheWarmer {
+	w.OnProgress = onProgress
+	return w
+}
+
+// split 将去重后的文本分为待翻译、已缓存和被过滤三类
+func (w *CacheWarmer) split(texts []string) (pending []string, cached, skipped int) {
+	targetLanguage := translationTargetLanguage(w.DocType, w.TargetLanguage)
+	base := &BaseProvider{Config: w.Client.Provider.GetConfig()}
+
+	unique, _ := UniqueBlocks(texts)
+	for _, text := range unique {
+		if !w.Client.Filter.ShouldTranslate(text) {
+			skipped++
+			continue
+		}
+		// 与翻译时相同的缓存键：列表标记、图表编号标签和行内公式不发送给翻译服务
+		body := prepareBlock(text, w.Client.CaptionLabels).body
+		if w.Cache != nil {
+			if _, ok := w.Cache.Get(CacheKeyWithOptions(body, targetLanguage, base.translateOptions(w.UserPrompt))); ok {
+				cached++
+				continue
+			}
+		}
+		pending = append(pending, text)
+	}
+	return pending, cached, skipped
+}
+
+// Warm 翻译尚未缓存的文本并写入缓存，返回最终进度
+// 客户端的任务上下文被取消时停止发起新的请求，并返回上下文的错误
+func (w *CacheWarmer) Warm(texts []string) (CacheWarmProgress, error) {
+	pending, cached, skipped := w.split(texts)
+	progress := CacheWarmProgress{
+		Total:  len(pending) + cached + skipped,
+		Cached: cached,
+		Done:   cached + skipped,
+	}
+	w.report(progress)
+	if len(pending) == 0 {
+		return progress, nil
+	}
+
+	var mu sync.Mutex
+	onResult := w.Client.OnResult
+	w.Client.OnResult = func(result TranslateResult) {
+		if onResult != nil {
+			onResult(result)
+		}
+
+		mu.Lock()
+		if result.Err != nil {
+			progress.Failed++
+		} else {
+			progress.Warmed++
+		}
+		progress.Done++
+		current := progress
+		mu.Unlock()
+		w.report(current)
+	}
+	defer func() { w.Client.OnResult = onResult }()
+
+	w.Client.TranslateBlocks(pending, translationTargetLanguage(w.DocType, w.TargetLanguage), w.UserPrompt, nil)
+
+	mu.Lock()
+	defer mu.Unlock()
+	return progress, w.Client.Context().Err()
+}
+
+// report 调用进度回调
+func (w *CacheWarmer) report(progress CacheWarmProgress) {
+	if w.OnProgress != nil {
+		w.OnProgress(progress)
+	}
+}
@@ -0,0 +1,71 @@
+package translator
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestCacheWarmerSkipsCachedStrings(t *testing.T) {
+	stub := newOpenAIStub(t, nil)
+	cache := NewMemoryCache()
+	client, err := NewTranslatorClient(stub.Config(), cache)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var reports []CacheWarmProgress
+	warmer := NewCacheWarmer(client, cache, "Uni", "").WithProgress(func(p CacheWarmProgress) { reports = append(reports, p) })
+	texts := []string{"The first sentence.", "The second sentence.", "The third sentence.", "The first sentence."}
+
+	progress, err := warmer.Warm(texts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if progress != (CacheWarmProgress{Total: 3, Warmed: 3, Done: 3}) {
+		t.Errorf("首次预热进度 = %+v", progress)
+	}
+	if cache.Len() != 3 || len(stub.Requests()) != 3 {
+		t.Errorf("缓存 %d 条、请求 %d 次，期望均为 3", cache.Len(), len(stub.Requests()))
+	}
+	if last := reports[len(reports)-1]; last.Done != 3 {
+		t.Errorf("最后一次进度回调 = %+v", last)
+	}
+
+	// 再次预热全部命中缓存，不再请求翻译服务
+	progress, err = warmer.Warm(texts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if progress != (CacheWarmProgress{Total: 3, Cached: 3, Done: 3}) {
+		t.Errorf("再次预热进度 = %+v", progress)
+	}
+	if n := len(stub.Requests()); n != 3 {
+		t.Errorf("再次预热后共请求 %d 次，期望仍为 3", n)
+	}
+
+	// 预热的结果在之后的翻译中直接命中缓存
+	if translated, err := client.Translate("The second sentence.", "Uni", ""); err != nil || translated != "[译] The second sentence." || len(stub.Requests()) != 3 {
+		t.Errorf("翻译已预热的文本 = %q, err = %v, 请求 %d 次", translated, err, len(stub.Requests()))
+	}
+}
+
+func TestCacheWarmerCanceled(t *testing.T) {
+	stub := newOpenAIStub(t, nil)
+	cache := NewMemoryCache()
+	client, err := NewTranslatorClient(stub.Config(), cache)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	client.WithContext(ctx)
+
+	progress, err := NewCacheWarmer(client, cache, "Uni", "").Warm([]string{"One sentence here.", "Another sentence here."})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v，期望 context.Canceled", err)
+	}
+	if progress.Warmed != 0 || cache.Len() != 0 || len(stub.Requests()) != 0 {
+		t.Errorf("取消后 progress = %+v，缓存 %d 条，请求 %d 次", progress, cache.Len(), len(stub.Requests()))
+	}
+}
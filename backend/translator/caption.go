@@ -0,0 +1,56 @@
+package translator
+
+import (
+	"regexp"
+	"strings"
+)
+
+// captionLabelRegex 匹配图表标题开头的编号标签，如 "Figure 3:"、"Table 2."、"Fig. 1a."、"图 3："
+// 第1组为完整标签（含分隔符和空白），第2组为标签词，第3组为正文
+// 标签后必须有分隔符，避免把 "Table 2 shows ..." 这样的正文句子拆开
+var captionLabelRegex = regexp.MustCompile(`^(\s*((?i:figure|fig\.?|table|tab\.?|equation|eq\.?|algorithm|listing|scheme|chart)|图|表|公式|算法)\s*[A-Z]?\d+(?:[.\-]\d+)*[a-z]?\s*[:：.．|]\s*)(\S[\s\S]*)$`)
+
+// SplitCaption 拆分图表标题的编号标签和描述
+// 例如 "Table 2: Summary of runs" 返回 ("Table 2: ", "Summary of runs")；不是图表标题时 label 为空
+func SplitCaption(text string) (label, body string) {
+	matches := captionLabelRegex.FindStringSubmatch(text)
+	if matches == nil {
+		return "", text
+	}
+	return matches[1], matches[3]
+}
+
+// TranslateCaptionLabel 按配置替换标签词（如 Figure -> 图），编号和分隔符保持不变
+// 没有对应配置时原样返回
+func TranslateCaptionLabel(label string, labels map[string]string) string {
+	if len(labels) == 0 {
+		return label
+	}
+
+	loc := captionLabelRegex.FindStringSubmatchIndex(label + "x")
+	if loc == nil {
+		return label
+	}
+	word := label[loc[4]:loc[5]]
+
+	replacement, ok := labels[word]
+	if !ok {
+		for key, value := range labels {
+			if strings.EqualFold(key, word) {
+				replacement, ok = value, true
+				break
+			}
+		}
+	}
+	if !ok {
+		return label
+	}
+	return label[:loc[4]] + replacement + label[loc[5]:]
+}
+
+// splitUntranslatedPrefix 拆分不发送给翻译服务的前缀（列表标记、图表编号标签）和正文
+func splitUntranslatedPrefix(text string) (marker, label, body string) {
+	marker, body = SplitListMarker(text)
+	label, body = SplitCaption(body)
+	return marker, label, body
+}
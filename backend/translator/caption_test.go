@@ -0,0 +1,59 @@
+package translator
+
+import "testing"
+
+func TestSplitCaption(t *testing.T) {
+	tests := []struct {
+		text  string
+		label string
+		body  string
+	}{
+		{"Table 2: Summary of runs", "Table 2: ", "Summary of runs"},
+		{"Figure 3. Results on the test set", "Figure 3. ", "Results on the test set"},
+		{"Fig. 1a: Overview", "Fig. 1a: ", "Overview"},
+		{"图 3：实验结果", "图 3：", "实验结果"},
+		{"Table 2 shows the summary of runs.", "", "Table 2 shows the summary of runs."},
+		{"Plain paragraph", "", "Plain paragraph"},
+	}
+	for _, tt := range tests {
+		label, body := SplitCaption(tt.text)
+		if label != tt.label || body != tt.body {
+			t.Errorf("SplitCaption(%q) = (%q, %q)，期望 (%q, %q)", tt.text, label, body, tt.label, tt.body)
+		}
+	}
+}
+
+func TestTranslateCaptionLabel(t *testing.T) {
+	labels := map[string]string{"figure": "图", "Table": "表"}
+	tests := map[string]string{
+		"Figure 3: ": "图 3: ",
+		"Table 2. ":  "表 2. ",
+		"Scheme 1: ": "Scheme 1: ",
+	}
+	for label, want := range tests {
+		if got := TranslateCaptionLabel(label, labels); got != want {
+			t.Errorf("TranslateCaptionLabel(%q) = %q，期望 %q", label, got, want)
+		}
+	}
+	if got := TranslateCaptionLabel("Figure 3: ", nil); got != "Figure 3: " {
+		t.Errorf("未配置译法时标签被修改: %q", got)
+	}
+}
+
+func TestTranslateBlocksKeepsCaptionLabel(t *testing.T) {
+	client, stub := newStubClient(t)
+	results := client.TranslateBlocks([]string{"Table 2: Summary of runs"}, "French", "", nil)
+	if got := results[0].Translated; got != "Table 2: [French] Summary of runs" {
+		t.Fatalf("译文 = %q", got)
+	}
+	if len(stub.calls) != 1 || stub.calls[0] != "Summary of runs" {
+		t.Fatalf("发送给翻译服务的文本 = %q", stub.calls)
+	}
+
+	client, _ = newStubClient(t)
+	client.WithCaptionLabels(map[string]string{"Table": "Tableau"})
+	results = client.TranslateBlocks([]string{"Table 2: Summary of runs"}, "French", "", nil)
+	if got := results[0].Translated; got != "Tableau 2: [French] Summary of runs" {
+		t.Fatalf("配置标签译法后译文 = %q", got)
+	}
+}
@@ -0,0 +1,119 @@
+package translator
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// CharacterMap PDF 文本中八进制转义（如 \050）到字符的映射
+// 不同 PDF 的字体编码不同，同一编码可能代表不同的字符，可通过配置覆盖默认映射
+type CharacterMap map[string]string
+
+// defaultCharacterMap 内置的常见 PDF 字符编码
+var defaultCharacterMap = CharacterMap{
+	`\002`: "ﬁ",  // fi连字
+	`\003`: "ﬂ",  // fl连字
+	`\001`: "ﬀ",  // ff连字
+	`\004`: "ﬃ",  // ffi连字
+	`\005`: "ﬄ",  // ffl连字
+	`\013`: "–",  // en dash
+	`\014`: "—",  // em dash
+	`\015`: "'",  // left single quotation mark
+	`\016`: "'",  // right single quotation mark
+	`\017`: "\"", // left double quotation mark
+	`\020`: "\"", // right double quotation mark
+	`\021`: "•",  // bullet
+	`\022`: "…",  // horizontal ellipsis
+	`\050`: "(",  // left parenthesis
+	`\051`: ")",  // right parenthesis
+	`\052`: "*",  // asterisk
+	`\053`: "+",  // plus sign
+	`\054`: ",",  // comma
+	`\055`: "-",  // hyphen-minus
+	`\056`: ".",  // full stop
+	`\057`: "/",  // solidus
+}
+
+// DefaultCharacterMap 返回内置映射的副本
+func DefaultCharacterMap() CharacterMap {
+	m := make(CharacterMap, len(defaultCharacterMap))
+	for code, char := range defaultCharacterMap {
+		m[code] = char
+	}
+	return m
+}
+
+// ParseCharacterMap 解析 JSON 格式的映射（如 {"\\050": "["}），与内置映射合并，配置中的条目优先
+// 键可以省略开头的反斜杠（如 "050"）
+func ParseCharacterMap(data []byte) (CharacterMap, error) {
+	var overrides map[string]string
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return nil, fmt.Errorf("字符映射格式错误: %w", err)
+	}
+
+	m := DefaultCharacterMap()
+	for code, char := range overrides {
+		digits := strings.TrimPrefix(code, `\`)
+		if len(digits) == 0 || len(digits) > 3 || strings.Trim(digits, "01234567") != "" {
+			return nil, fmt.Errorf("字符映射的键 %q 不是八进制转义（如 \\050）", code)
+		}
+		m[`\`+strings.Repeat("0", 3-len(digits))+digits] = char
+	}
+	return m, nil
+}
+
+// LoadCharacterMap 从 JSON 文件加载映射
+func LoadCharacterMap(path string) (CharacterMap, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取字符映射失败: %w", err)
+	}
+	return ParseCharacterMap(data)
+}
+
+var (
+	envCharacterMapOnce sync.Once
+	envCharacterMap     CharacterMap
+)
+
+// CharacterMapFromEnv 返回环境变量 PDF_CHARACTER_MAP 指定的映射文件，未设置或加载失败时返回内置映射
+func CharacterMapFromEnv() CharacterMap {
+	envCharacterMapOnce.Do(func() {
+		envCharacterMap = defaultCharacterMap
+		path := os.Getenv("PDF_CHARACTER_MAP")
+		if path == "" {
+			return
+		}
+		m, err := LoadCharacterMap(path)
+		if err != nil {
+			log.Printf("警告：%v，使用内置字符映射", err)
+			return
+		}
+		envCharacterMap = m
+	})
+	return envCharacterMap
+}
+
+// Apply 替换文本中的转义编码
+func (m CharacterMap) Apply(text string) string {
+	if !strings.Contains(text, `\`) {
+		return text
+	}
+
+	codes := make([]string, 0, len(m))
+	for code := range m {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	pairs := make([]string, 0, len(codes)*2)
+	for _, code := range codes {
+		pairs = append(pairs, code, m[code])
+	}
+	return strings.NewReplacer(pairs...).Replace(text)
+}
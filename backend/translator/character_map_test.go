@@ -0,0 +1,45 @@
+package translator
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestCharacterMapOverride(t *testing.T) {
+	m, err := ParseCharacterMap([]byte(`{"\\050": "[", "51": "]"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := m.Apply(`\050see\051 \013 \055`); got != "[see] – -" {
+		t.Errorf("Apply = %q", got)
+	}
+	// 内置映射不受影响
+	if got := DefaultCharacterMap().Apply(`\050see\051`); got != "(see)" {
+		t.Errorf("默认映射 Apply = %q", got)
+	}
+
+	for _, data := range []string{`{"\\089": "x"}`, `{"": "x"}`, `{"\\0500": "x"}`, `not json`} {
+		if _, err := ParseCharacterMap([]byte(data)); err == nil {
+			t.Errorf("ParseCharacterMap(%s) 应返回错误", data)
+		}
+	}
+}
+
+func TestCleanPDFTextUsesCharacterMap(t *testing.T) {
+	dir := t.TempDir()
+	p := newTestFlowProcessor(t, writeTestPDF(t, dir, []string{"Page"}), filepath.Join(dir, "out.pdf"))
+	m, err := ParseCharacterMap([]byte(`{"\\050": "["}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.CharacterMap = m
+	if got := p.cleanPDFText(`\050a\051 \014 b`); got != "[a) — b" {
+		t.Errorf("cleanPDFText = %q", got)
+	}
+
+	// 优化处理器的文本清理使用同一映射
+	opp := &OptimizedPDFProcessor{baseProcessor: p}
+	if got := opp.cleanPDFText(`(\050a\051 \014 b)`); got != "[a) — b" {
+		t.Errorf("OptimizedPDFProcessor.cleanPDFText = %q", got)
+	}
+}
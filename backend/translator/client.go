@@ -1,7 +1,11 @@
 package translator
 
 import (
+	"context"
 	"fmt"
+	"log"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -10,19 +14,46 @@ type TranslatorClient struct {
 	Provider      Provider
 	RetryTimes    int
 	RetryInterval time.Duration
+	Filter        *BlockFilter      // 文本块过滤规则，被过滤的文本块保留原文
+	CaptionLabels map[string]string // 图表标签词的固定译法（如 Figure -> 图），为空时保留原标签
+	Concurrency   int               // 并发翻译请求数
+	BatchSize     int               // 支持批量接口时每次请求的文本块数
+	Glossary      Glossary          // 术语表，翻译后在译文中落实规定译法
+	Edits         map[string]string // 人工修改的译文：原文 -> 译文，直接使用，不请求翻译服务
+	Granularity   Granularity       // 发送给翻译服务的文本单位：文本块（默认）、句子或页面
+	Pages         map[string]int    // 原文所在的页码，按页翻译时用于分组，没有页码的文本块逐块翻译
+	ctx           context.Context   // 任务上下文，取消或超时后不再发起新的翻译请求
+
+	// OnResult 每个文本块得到结果（含失败回退和被过滤的文本块）后回调
+	OnResult func(result TranslateResult)
+}
+
+// TranslateResult 单个文本块的翻译结果
+type TranslateResult struct {
+	Index        int    // 文本块在输入列表中的索引
+	Original     string // 原文
+	Translated   string // 译文（失败时为原文）
+	Err          error  // 翻译错误
+	UsedFallback bool   // 是否因失败而回退为原文
+
+	Confidence float64 // 翻译服务自评的可信度（0~1），未启用自评或服务未报告时为 1
 }
 
 // NewTranslatorClient 创建翻译客户端
-func NewTranslatorClient(config ProviderConfig, cache *Cache) (*TranslatorClient, error) {
+func NewTranslatorClient(config ProviderConfig, cache CacheStore) (*TranslatorClient, error) {
 	provider, err := NewProvider(config, cache)
 	if err != nil {
 		return nil, err
 	}
 
+	concurrency, batchSize := DefaultThroughput(config.Type)
 	return &TranslatorClient{
 		Provider:      provider,
 		RetryTimes:    5,
 		RetryInterval: 2 * time.Second,
+		Filter:        NewBlockFilter(),
+		Concurrency:   concurrency,
+		BatchSize:     batchSize,
 	}, nil
 }
 
@@ -33,27 +64,155 @@ func (c *TranslatorClient) WithRetry(times int, interval time.Duration) *Transla
 	return c
 }
 
+// WithCaptionLabels 设置图表标签词的固定译法
+func (c *TranslatorClient) WithCaptionLabels(labels map[string]string) *TranslatorClient {
+	c.CaptionLabels = labels
+	return c
+}
+
+// WithThroughput 设置并发数和批大小，超出服务端上限时按上限处理
+func (c *TranslatorClient) WithThroughput(concurrency, batchSize int) *TranslatorClient {
+	c.Concurrency, c.BatchSize = ClampThroughput(c.Provider.GetConfig().Type, concurrency, batchSize)
+	return c
+}
+
+// WithGlossary 设置术语表（提示词中的术语表通过 ProviderConfig.Extra["glossary"] 配置）
+func (c *TranslatorClient) WithGlossary(glossary Glossary) *TranslatorClient {
+	c.Glossary = glossary
+	return c
+}
+
+// WithEdits 设置人工修改的译文，这些文本块直接使用修改后的译文
+func (c *TranslatorClient) WithEdits(edits map[string]string) *TranslatorClient {
+	c.Edits = edits
+	return c
+}
+
+// WithGranularity 设置翻译粒度，按页翻译时需要通过 Pages 提供各文本块的页码
+func (c *TranslatorClient) WithGranularity(granularity Granularity) *TranslatorClient {
+	c.Granularity = granularity
+	return c
+}
+
+// ContextSetter 支持为请求绑定上下文的提供商
+type ContextSetter interface {
+	SetContext(ctx context.Context)
+}
+
+// WithContext 设置任务上下文：取消或超时后中止进行中的请求，剩余文本块不再翻译
+func (c *TranslatorClient) WithContext(ctx context.Context) *TranslatorClient {
+	c.ctx = ctx
+	if setter, ok := c.Provider.(ContextSetter); ok {
+		setter.SetContext(ctx)
+	}
+	return c
+}
+
+// Context 返回任务上下文，未设置时返回 context.Background()
+func (c *TranslatorClient) Context() context.Context {
+	if c.ctx == nil {
+		return context.Background()
+	}
+	return c.ctx
+}
+
+// sleep 等待指定时间，任务上下文结束时提前返回其错误
+func (c *TranslatorClient) sleep(d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-c.Context().Done():
+		return c.Context().Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// WithFilter 设置文本块过滤规则
+func (c *TranslatorClient) WithFilter(filter *BlockFilter) *TranslatorClient {
+	c.Filter = filter
+	return c
+}
+
 // Translate 翻译文本（带重试）
+// 译文为空白或长度异常偏短时只重试一次，仍无效则返回错误
 func (c *TranslatorClient) Translate(text, targetLanguage, userPrompt string) (string, error) {
+	translated, _, err := c.translateScored(text, targetLanguage, userPrompt)
+	return translated, err
+}
+
+// translateScored 翻译文本（带重试），同时返回翻译服务自评的可信度
+// 提供商配置启用 SelfRateConfidence 时解析 JSON 回复，无法解析时可信度为 1
+func (c *TranslatorClient) translateScored(text, targetLanguage, userPrompt string) (_ string, _ float64, err error) {
+	defer func() { RecordTranslation(c.Provider.GetConfig().Type, err) }()
+
 	var lastErr error
+	invalidRetried := false
 	for attempt := 0; attempt <= c.RetryTimes; attempt++ {
 		if attempt > 0 {
-			time.Sleep(c.RetryInterval)
+			if err := c.sleep(c.RetryInterval); err != nil {
+				return "", 0, err
+			}
+		}
+		if err := c.Context().Err(); err != nil {
+			return "", 0, err
 		}
 
 		result, err := c.Provider.Translate(text, targetLanguage, userPrompt)
 		if err == nil {
-			return result, nil
+			confidence := 1.0
+			if c.Provider.GetConfig().SelfRateConfidence {
+				result, confidence = parseConfidenceResponse(result)
+			}
+			// 拒绝翻译时重试通常得到相同结果，直接返回错误
+			if isRefusalFor(text, result) {
+				return "", 0, refusalError(result)
+			}
+			err = validateTranslation(text, result, targetLanguage)
+			if err == nil {
+				return result, confidence, nil
+			}
+			if invalidRetried {
+				return "", 0, err
+			}
+			invalidRetried = true
+			log.Printf("警告：%v，重试一次", err)
 		}
 
 		lastErr = err
 	}
 
-	return "", fmt.Errorf("翻译失败（重试 %d 次后）: %w", c.RetryTimes, lastErr)
+	return "", 0, fmt.Errorf("翻译失败（重试 %d 次后）: %w", c.RetryTimes, lastErr)
 }
 
 // TranslateBatch 批量翻译
+// 提供商支持批量接口时一次请求完成（带重试），否则逐段翻译
 func (c *TranslatorClient) TranslateBatch(texts []string, targetLanguage, userPrompt string) ([]string, error) {
+	if batcher, ok := c.Provider.(BatchProvider); ok {
+		var lastErr error
+		for attempt := 0; attempt <= c.RetryTimes; attempt++ {
+			if attempt > 0 {
+				if err := c.sleep(c.RetryInterval); err != nil {
+					return nil, err
+				}
+			}
+			if err := c.Context().Err(); err != nil {
+				return nil, err
+			}
+
+			results, err := batcher.TranslateBatch(texts, targetLanguage, userPrompt)
+			if err == nil {
+				// 批量失败时调用方逐块重试，只在成功时计数，避免重复统计
+				for range texts {
+					RecordTranslation(c.Provider.GetConfig().Type, nil)
+				}
+				return results, nil
+			}
+			lastErr = err
+		}
+		return nil, fmt.Errorf("批量翻译失败（重试 %d 次后）: %w", c.RetryTimes, lastErr)
+	}
+
 	results := make([]string, len(texts))
 
 	for i, text := range texts {
@@ -69,8 +228,225 @@ func (c *TranslatorClient) TranslateBatch(texts []string, targetLanguage, userPr
 		results[i] = translated
 
 		// 避免请求过快
-		time.Sleep(100 * time.Millisecond)
+		if err := c.sleep(100 * time.Millisecond); err != nil {
+			return nil, err
+		}
 	}
 
 	return results, nil
 }
+
+// TranslateBlocks 逐块翻译，单个文本块失败不会中断整个批次
+// 相同的文本块只翻译一次，结果回填到所有出现位置
+// 失败的文本块回退为原文，并在结果中记录错误
+// 按 Concurrency 并发请求；提供商支持批量接口时每次请求最多包含 BatchSize 个文本块
+// Granularity 为句子时拆分为句子翻译后拼接，为页面时同一页的文本块合并为一次请求
+func (c *TranslatorClient) TranslateBlocks(texts []string, targetLanguage, userPrompt string, progressCallback func(float64)) []TranslateResult {
+	if c.Granularity == GranularitySentence {
+		return c.translateSentences(texts, targetLanguage, userPrompt, progressCallback)
+	}
+	return c.translateUnits(texts, targetLanguage, userPrompt, progressCallback, c.OnResult)
+}
+
+// translateUnits 去重后按批次并发翻译，每个去重后的文本得到结果后调用 onResult
+func (c *TranslatorClient) translateUnits(texts []string, targetLanguage, userPrompt string, progressCallback func(float64), onResult func(TranslateResult)) []TranslateResult {
+	results := make([]TranslateResult, len(texts))
+	unique, indexMap := UniqueBlocks(texts)
+	batches, translate := c.planBatches(unique), c.translateBatchBlocks
+	if c.Granularity == GranularityPage {
+		batches, translate = c.planPages(unique), c.translatePageBlocks
+	}
+
+	var mu sync.Mutex
+	done := 0
+	NewTranslatePool(c.Concurrency).Run(len(batches), func(b int) {
+		translated, confidences, errs := translate(unique, batches[b], indexMap, targetLanguage, userPrompt)
+
+		mu.Lock()
+		defer mu.Unlock()
+		for k, u := range batches[b] {
+			if _, edited := c.Edits[unique[u]]; errs[k] == nil && !edited && len(c.Glossary) > 0 {
+				translated[k] = c.Glossary.Enforce(unique[u], translated[k])
+			}
+
+			// 回填到所有出现位置
+			for _, i := range indexMap[u] {
+				results[i] = TranslateResult{
+					Index:        i,
+					Original:     texts[i],
+					Translated:   translated[k],
+					Err:          errs[k],
+					UsedFallback: errs[k] != nil,
+					Confidence:   confidences[k],
+				}
+			}
+			if onResult != nil {
+				onResult(results[indexMap[u][0]])
+			}
+
+			// 更新进度
+			done++
+			if progressCallback != nil {
+				progressCallback(float64(done) / float64(len(unique)))
+			}
+		}
+	})
+
+	return results
+}
+
+// planBatches 将去重后的文本块分组，每组为一次请求
+// 被过滤的文本块、人工修改过的文本块和不支持批量接口的提供商每组只有一个文本块
+func (c *TranslatorClient) planBatches(unique []string) [][]int {
+	batchSize := c.BatchSize
+	if _, ok := c.Provider.(BatchProvider); !ok || batchSize < 1 {
+		batchSize = 1
+	}
+
+	var batches [][]int
+	var current []int
+	for u, text := range unique {
+		if _, edited := c.Edits[text]; edited || !c.Filter.ShouldTranslate(text) {
+			batches = append(batches, []int{u})
+			continue
+		}
+		current = append(current, u)
+		if len(current) >= batchSize {
+			batches = append(batches, current)
+			current = nil
+		}
+	}
+	if len(current) > 0 {
+		batches = append(batches, current)
+	}
+	return batches
+}
+
+// translateBatchBlocks 翻译一组文本块，批量请求失败时逐块重试
+// 返回译文、自评可信度（失败和未报告时为 1）和错误
+func (c *TranslatorClient) translateBatchBlocks(unique []string, batch []int, indexMap [][]int, targetLanguage, userPrompt string) ([]string, []float64, []error) {
+	translated := make([]string, len(batch))
+	confidences := make([]float64, len(batch))
+	errs := make([]error, len(batch))
+	for k := range confidences {
+		confidences[k] = 1.0
+	}
+
+	// 任务已取消或超时：剩余文本块不再请求，回退为原文
+	if err := c.Context().Err(); err != nil {
+		for k, u := range batch {
+			translated[k], errs[k] = unique[u], err
+		}
+		return translated, confidences, errs
+	}
+
+	if len(batch) > 1 {
+		blocks := make([]preparedBlock, len(batch))
+		bodies := make([]string, len(batch))
+		for k, u := range batch {
+			blocks[k] = prepareBlock(unique[u], c.CaptionLabels)
+			bodies[k] = blocks[k].body
+		}
+
+		results, err := c.TranslateBatch(bodies, targetLanguage, userPrompt)
+		if err == nil && len(results) == len(batch) {
+			for k, u := range batch {
+				if c.Provider.GetConfig().SelfRateConfidence {
+					results[k], confidences[k] = parseConfidenceResponse(results[k])
+				}
+				if isRefusalFor(bodies[k], results[k]) {
+					errs[k] = refusalError(results[k])
+					log.Printf("警告：翻译第 %d 个文本块失败: %v", indexMap[u][0]+1, errs[k])
+					translated[k] = unique[u]
+					continue
+				}
+				// 空白或长度异常的译文逐块重新翻译
+				if err := validateTranslation(bodies[k], results[k], targetLanguage); err != nil {
+					log.Printf("警告：批量翻译中第 %d 个文本块%v，单独重新翻译", indexMap[u][0]+1, err)
+					translated[k], confidences[k], errs[k] = c.translateBlock(unique[u], indexMap[u][0], targetLanguage, userPrompt)
+					continue
+				}
+				if translated[k], errs[k] = blocks[k].finish(results[k]); errs[k] != nil {
+					log.Printf("警告：翻译第 %d 个文本块失败: %v", indexMap[u][0]+1, errs[k])
+					translated[k], confidences[k] = unique[u], 1.0
+				}
+			}
+			return translated, confidences, errs
+		}
+		log.Printf("警告：批量翻译 %d 个文本块失败，改为逐块翻译: %v", len(batch), err)
+	}
+
+	for k, u := range batch {
+		translated[k], confidences[k], errs[k] = c.translateBlock(unique[u], indexMap[u][0], targetLanguage, userPrompt)
+	}
+	return translated, confidences, errs
+}
+
+// translateBlock 翻译单个文本块，被过滤的文本块原样返回，人工修改过的文本块返回修改后的译文，失败时回退为原文
+// 同时返回翻译服务自评的可信度，未翻译和失败时为 1
+func (c *TranslatorClient) translateBlock(text string, index int, targetLanguage, userPrompt string) (string, float64, error) {
+	if edited, ok := c.Edits[text]; ok {
+		return edited, 1.0, nil
+	}
+	if !c.Filter.ShouldTranslate(text) {
+		return text, 1.0, nil
+	}
+
+	block := prepareBlock(text, c.CaptionLabels)
+	if block.formulaOnly() {
+		return text, 1.0, nil
+	}
+
+	translated, confidence, err := c.translateScored(block.body, targetLanguage, userPrompt)
+	if err == nil {
+		translated, err = block.finish(translated)
+	}
+	if err != nil {
+		log.Printf("警告：翻译第 %d 个文本块失败: %v", index+1, err)
+		return text, 1.0, err
+	}
+	return translated, confidence, nil
+}
+
+// preparedBlock 拆分出不翻译部分后的文本块
+type preparedBlock struct {
+	prefix string   // 列表标记和图表编号标签，翻译后加回，保证交叉引用的编号不变
+	body   string   // 发送给翻译服务的正文，行内公式已替换为占位符
+	tokens []string // 占位符对应的原始公式
+}
+
+// prepareBlock 规范化文本，拆分列表标记、图表编号标签，并将行内公式替换为占位符
+func prepareBlock(text string, captionLabels map[string]string) preparedBlock {
+	marker, label, body := splitUntranslatedPrefix(NormalizeText(text))
+	masked, tokens := ProtectInlineFormulas(body)
+	return preparedBlock{
+		prefix: marker + TranslateCaptionLabel(label, captionLabels),
+		body:   masked,
+		tokens: tokens,
+	}
+}
+
+// formulaOnly 正文是否只有公式，无需翻译
+func (b preparedBlock) formulaOnly() bool {
+	return len(b.tokens) > 0 && strings.TrimSpace(tokenPlaceholderPattern.ReplaceAllString(b.body, "")) == ""
+}
+
+// finish 还原公式并加回前缀
+func (b preparedBlock) finish(translated string) (string, error) {
+	restored, err := RestoreTokens(translated, b.tokens)
+	if err != nil {
+		return "", err
+	}
+	return b.prefix + restored, nil
+}
+
+// FailedResults 筛选出翻译失败的结果
+func FailedResults(results []TranslateResult) []TranslateResult {
+	var failed []TranslateResult
+	for _, result := range results {
+		if result.Err != nil {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
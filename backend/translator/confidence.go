@@ -0,0 +1,73 @@
+package translator
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// DefaultConfidenceThreshold 自评可信度低于此值的译文标记为需要人工复核
+const DefaultConfidenceThreshold = 0.6
+
+// LowConfidenceMarker 启用标记时追加在低可信度译文之后的标记
+const LowConfidenceMarker = " (?)"
+
+// confidenceInstruction 要求大模型以 JSON 返回译文和自评可信度的提示词
+const confidenceInstruction = `Respond only with a JSON object of the form {"translation": "<the translated text>", "confidence": <a number from 0 to 1 rating how confident you are that the translation is accurate>}.`
+
+// parseConfidenceResponse 解析带自评可信度的回复 {"translation": ..., "confidence": ...}
+// 回复不是该格式时原样作为译文，可信度为 1；可信度缺失时为 1，超出 0~1 时截断
+func parseConfidenceResponse(raw string) (string, float64) {
+	body := strings.TrimSpace(raw)
+	// 部分模型会把 JSON 放在代码块中
+	if strings.HasPrefix(body, "```") {
+		body = strings.TrimPrefix(strings.TrimPrefix(body, "```json"), "```")
+		body = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(body), "```"))
+	}
+	if !strings.HasPrefix(body, "{") {
+		return raw, 1.0
+	}
+
+	var resp struct {
+		Translation *string  `json:"translation"`
+		Confidence  *float64 `json:"confidence"`
+	}
+	if err := json.Unmarshal([]byte(body), &resp); err != nil || resp.Translation == nil {
+		return raw, 1.0
+	}
+
+	confidence := 1.0
+	if resp.Confidence != nil {
+		confidence = min(1.0, *resp.Confidence)
+		if confidence < 0 {
+			confidence = 0
+		}
+	}
+	return *resp.Translation, confidence
+}
+
+// ResultConfidences 返回自评可信度低于 1 的文本块：原文 -> 可信度
+func ResultConfidences(results []TranslateResult) map[string]float64 {
+	confidences := make(map[string]float64)
+	for _, result := range results {
+		if result.Err == nil && result.Confidence < 1 {
+			confidences[result.Original] = result.Confidence
+		}
+	}
+	return confidences
+}
+
+// MarkLowConfidence 返回译文映射的副本，可信度低于 threshold 的译文后追加 LowConfidenceMarker
+// threshold 不大于 0 时使用 DefaultConfidenceThreshold
+func MarkLowConfidence(translations map[string]string, confidences map[string]float64, threshold float64) map[string]string {
+	if threshold <= 0 {
+		threshold = DefaultConfidenceThreshold
+	}
+	marked := make(map[string]string, len(translations))
+	for original, translation := range translations {
+		if confidence, ok := confidences[original]; ok && confidence < threshold && translation != original {
+			translation += LowConfidenceMarker
+		}
+		marked[original] = translation
+	}
+	return marked
+}
@@ -0,0 +1,82 @@
+package translator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseConfidenceResponse(t *testing.T) {
+	tests := []struct {
+		raw         string
+		translation string
+		confidence  float64
+	}{
+		{`{"translation": "你好", "confidence": 0.4}`, "你好", 0.4},
+		{"```json\n{\"translation\": \"你好\", \"confidence\": 0.7}\n```", "你好", 0.7},
+		{`{"translation": "你好"}`, "你好", 1},
+		{`{"translation": "你好", "confidence": 1.5}`, "你好", 1},
+		{`{"translation": "你好", "confidence": -1}`, "你好", 0},
+		{"你好", "你好", 1},
+		{`{"text": "你好"}`, `{"text": "你好"}`, 1},
+	}
+	for _, tt := range tests {
+		translation, confidence := parseConfidenceResponse(tt.raw)
+		if translation != tt.translation || confidence != tt.confidence {
+			t.Errorf("parseConfidenceResponse(%q) = %q, %v，期望 %q, %v", tt.raw, translation, confidence, tt.translation, tt.confidence)
+		}
+	}
+}
+
+func TestSelfRatedConfidenceMarksLowBlocks(t *testing.T) {
+	stub := newOpenAIStub(t, func(req stubRequest) string {
+		if strings.Contains(req.User, "ambiguous") {
+			return `{"translation": "[译] ` + req.User + `", "confidence": 0.4}`
+		}
+		return `{"translation": "[译] ` + req.User + `", "confidence": 0.9}`
+	})
+	config := stub.Config()
+	config.SelfRateConfidence = true
+	client, err := NewTranslatorClient(config, NewMemoryCache())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	texts := []string{"A clear sentence", "An ambiguous sentence"}
+	results := client.TranslateBlocks(texts, "Uni", "", nil)
+	for _, result := range results {
+		if result.Err != nil || result.Translated != "[译] "+result.Original {
+			t.Fatalf("%q: 译文 = %q, err = %v", result.Original, result.Translated, result.Err)
+		}
+	}
+	if requests := stub.Requests(); !strings.Contains(requests[0].System, `"confidence"`) {
+		t.Errorf("系统提示词未要求返回可信度: %q", requests[0].System)
+	}
+
+	confidences := ResultConfidences(results)
+	if confidences["An ambiguous sentence"] != 0.4 || confidences["A clear sentence"] != 0.9 {
+		t.Errorf("ResultConfidences = %v", confidences)
+	}
+	translations := map[string]string{texts[0]: "[译] " + texts[0], texts[1]: "[译] " + texts[1]}
+	marked := MarkLowConfidence(translations, confidences, 0)
+	if marked[texts[1]] != "[译] "+texts[1]+LowConfidenceMarker || marked[texts[0]] != translations[texts[0]] {
+		t.Errorf("MarkLowConfidence = %v，期望只标记可信度 0.4 的文本块", marked)
+	}
+}
+
+func TestConfidenceDefaultsToOne(t *testing.T) {
+	// 服务回复纯文本（无法自评）时可信度为 1
+	stub := newOpenAIStub(t, nil)
+	config := stub.Config()
+	config.SelfRateConfidence = true
+	client, err := NewTranslatorClient(config, NewMemoryCache())
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := client.TranslateBlocks([]string{"A plain reply"}, "Uni", "", nil)
+	if results[0].Err != nil || results[0].Translated != "[译] A plain reply" || results[0].Confidence != 1 {
+		t.Errorf("result = %+v，期望可信度为 1", results[0])
+	}
+	if len(ResultConfidences(results)) != 0 {
+		t.Error("可信度为 1 的文本块不应列出")
+	}
+}
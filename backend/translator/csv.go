@@ -0,0 +1,281 @@
+package translator
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// utf8BOM UTF-8 字节顺序标记，Excel 导出的 CSV 常带有
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// CSVDocument 表示一个 CSV/TSV 表格，只翻译选定的列
+// 写回时只改写被翻译的字段，其余字段、引号、分隔符和换行保持原样
+type CSVDocument struct {
+	Path      string
+	Delimiter rune  // 字段分隔符：CSV 为逗号，TSV 为制表符
+	Header    bool  // 首行为表头，表头不翻译
+	Columns   []int // 需要翻译的列（从 0 开始），为空时翻译所有列
+
+	data    []byte      // 文件内容（不含 BOM）
+	bom     bool        // 原文件是否带有 UTF-8 BOM
+	records []csvRecord // 解析出的记录
+
+	translations map[string]string // 已插入的译文：原文 -> 译文
+	bilingual    bool              // 双语模式在每个被翻译的列之后插入译文列
+}
+
+// csvRecord 一行记录及各字段在文件内容中的位置
+type csvRecord struct {
+	fields []string
+	spans  [][2]int // 各字段在 data 中的起止偏移（含引号）
+}
+
+// OpenCSV 打开 CSV 或 TSV 文件（按扩展名选择分隔符），字段中可以包含换行、分隔符和转义的引号
+func OpenCSV(path string) (*CSVDocument, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取文件失败: %w", err)
+	}
+
+	doc := &CSVDocument{Path: path, Delimiter: ','}
+	if strings.ToLower(filepath.Ext(path)) == ".tsv" {
+		doc.Delimiter = '\t'
+	}
+	if bytes.HasPrefix(data, utf8BOM) {
+		doc.bom = true
+		data = data[len(utf8BOM):]
+	}
+	doc.data = data
+
+	if doc.records, err = parseCSVRecords(data, doc.Delimiter); err != nil {
+		return nil, err
+	}
+	if len(doc.records) == 0 {
+		return nil, fmt.Errorf("表格中没有数据")
+	}
+	return doc, nil
+}
+
+// parseCSVRecords 解析所有记录，并通过 FieldPos 和 InputOffset 计算每个字段在原始内容中的位置
+func parseCSVRecords(data []byte, delimiter rune) ([]csvRecord, error) {
+	// 行首偏移，用于将 FieldPos 返回的行列号换算为偏移
+	lineStarts := []int{0}
+	for i, b := range data {
+		if b == '\n' {
+			lineStarts = append(lineStarts, i+1)
+		}
+	}
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	var records []csvRecord
+	for {
+		fields, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("解析表格失败: %w", err)
+		}
+
+		// 记录结束位置（不含行尾换行）
+		end := int(reader.InputOffset())
+		if end > 0 && data[end-1] == '\n' {
+			end--
+			if end > 0 && data[end-1] == '\r' {
+				end--
+			}
+		}
+
+		record := csvRecord{fields: fields, spans: make([][2]int, len(fields))}
+		for i := range fields {
+			line, column := reader.FieldPos(i)
+			record.spans[i][0] = lineStarts[line-1] + column - 1
+		}
+		for i := range fields {
+			if i+1 < len(fields) {
+				record.spans[i][1] = record.spans[i+1][0] - len(string(delimiter))
+			} else {
+				record.spans[i][1] = end
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// SelectColumns 设置需要翻译的列，selectors 为列号（从 0 开始）或表头名称（不区分大小写）
+// 按表头名称选择时首行视为表头；selectors 为空时翻译所有列
+func (d *CSVDocument) SelectColumns(selectors []string, header bool) error {
+	d.Header = header
+	d.Columns = nil
+	for _, selector := range selectors {
+		if index, err := strconv.Atoi(selector); err == nil {
+			if index < 0 {
+				return fmt.Errorf("列号不能为负数: %d", index)
+			}
+			d.Columns = append(d.Columns, index)
+			continue
+		}
+
+		d.Header = true
+		index := -1
+		for i, name := range d.records[0].fields {
+			if strings.EqualFold(strings.TrimSpace(name), strings.TrimSpace(selector)) {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			return fmt.Errorf("表头中没有列: %s", selector)
+		}
+		d.Columns = append(d.Columns, index)
+	}
+	return nil
+}
+
+// ParseCSVColumns 解析逗号分隔的列选择（列号或表头名称），去除空白和重复项
+func ParseCSVColumns(value string) []string {
+	var columns []string
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(value, ",") {
+		column := strings.TrimSpace(part)
+		if column == "" || seen[column] {
+			continue
+		}
+		seen[column] = true
+		columns = append(columns, column)
+	}
+	return columns
+}
+
+// translatesColumn 是否翻译该列
+func (d *CSVDocument) translatesColumn(column int) bool {
+	if len(d.Columns) == 0 {
+		return true
+	}
+	for _, c := range d.Columns {
+		if c == column {
+			return true
+		}
+	}
+	return false
+}
+
+// dataRecords 返回需要翻译的记录（跳过表头）
+func (d *CSVDocument) dataRecords() []csvRecord {
+	if d.Header && len(d.records) > 0 {
+		return d.records[1:]
+	}
+	return d.records
+}
+
+// GetTextBlocks 获取选定列中的非空字段（实现 Document 接口）
+func (d *CSVDocument) GetTextBlocks() []string {
+	var blocks []string
+	for _, record := range d.dataRecords() {
+		for i, field := range record.fields {
+			if d.translatesColumn(i) && strings.TrimSpace(field) != "" {
+				blocks = append(blocks, field)
+			}
+		}
+	}
+	return blocks
+}
+
+// InsertTranslation 插入双语翻译（实现 Document 接口）
+// 在每个被翻译的列之后插入译文列，表头为原列名加 "_translated"
+func (d *CSVDocument) InsertTranslation(translations map[string]string) error {
+	d.translations, d.bilingual = translations, true
+	return nil
+}
+
+// InsertMonolingualTranslation 插入单语翻译（实现 Document 接口），被翻译的字段替换为译文
+func (d *CSVDocument) InsertMonolingualTranslation(translations map[string]string) error {
+	d.translations, d.bilingual = translations, false
+	return nil
+}
+
+// Save 保存文档（实现 Document 接口）
+// 只改写被翻译的字段：原字段带引号或译文包含分隔符、引号、换行时加引号，其余内容逐字节保留
+func (d *CSVDocument) Save(outputPath string) error {
+	var buf bytes.Buffer
+	if d.bom {
+		buf.Write(utf8BOM)
+	}
+
+	pos := 0
+	for r, record := range d.records {
+		isHeader := d.Header && r == 0
+		for i, field := range record.fields {
+			if !d.translatesColumn(i) {
+				continue
+			}
+			span := record.spans[i]
+			raw := string(d.data[span[0]:span[1]])
+			quoted := strings.HasPrefix(raw, `"`)
+
+			translated, ok := d.translations[field]
+			if !ok || isHeader {
+				translated = field
+			}
+
+			if d.bilingual {
+				// 原字段保持不变，之后插入译文字段
+				buf.Write(d.data[pos:span[1]])
+				if isHeader {
+					translated = field + "_translated"
+				}
+				buf.WriteRune(d.Delimiter)
+				buf.WriteString(d.quoteField(translated, quoted))
+			} else {
+				buf.Write(d.data[pos:span[0]])
+				if translated == field {
+					buf.WriteString(raw)
+				} else {
+					buf.WriteString(d.quoteField(translated, quoted))
+				}
+			}
+			pos = span[1]
+		}
+	}
+	buf.Write(d.data[pos:])
+
+	tmpPath := outputPath + ".tmp"
+	if err := os.WriteFile(tmpPath, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("写入文件失败: %w", err)
+	}
+	return os.Rename(tmpPath, outputPath)
+}
+
+// quoteField 按 CSV 规则输出字段，原字段带引号或内容需要转义时加引号
+func (d *CSVDocument) quoteField(value string, quoted bool) string {
+	if quoted || strings.ContainsAny(value, string(d.Delimiter)+"\"\r\n") {
+		return `"` + strings.ReplaceAll(value, `"`, `""`) + `"`
+	}
+	return value
+}
+
+// ValidateCSV 验证是否为可解析的 CSV/TSV 文件
+func ValidateCSV(filePath string) error {
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".csv", ".tsv":
+	default:
+		return fmt.Errorf("文件必须是 CSV 或 TSV 格式")
+	}
+
+	if _, err := OpenCSV(filePath); err != nil {
+		return fmt.Errorf("无效的表格文件: %w", err)
+	}
+	return nil
+}
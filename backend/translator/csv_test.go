@@ -0,0 +1,105 @@
+package translator
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testCSV = "id,description,price\r\n" +
+	"1,\"Red shoes, size 42\",\"1,000\"\r\n" +
+	"2,\"Blue hat\nwith \"\"wide\"\" brim\",25\r\n" +
+	"3,,7\r\n"
+
+// readCSV 解析 CSV/TSV 文件的全部记录
+func readCSV(t *testing.T, path string, delimiter rune) [][]string {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	r := csv.NewReader(f)
+	r.Comma = delimiter
+	records, err := r.ReadAll()
+	if err != nil {
+		t.Fatalf("解析 %s 失败: %v", path, err)
+	}
+	return records
+}
+
+func TestCSVTranslatesSelectedColumn(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "products.csv")
+	if err := os.WriteFile(input, []byte(testCSV), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	client, _ := newStubClient(t)
+	dt := &DocumentTranslator{Client: client, PDFMathTranslator: NewPDFMathTranslator(), CSVColumns: []string{"description"}, CSVHeader: true}
+	output, err := dt.TranslateDocument(input, filepath.Join(dir, "out.csv"), "French", "", true, "monolingual", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := [][]string{
+		{"id", "description", "price"},
+		{"1", "[French] Red shoes, size 42", "1,000"},
+		{"2", "[French] Blue hat\nwith \"wide\" brim", "25"},
+		{"3", "", "7"},
+	}
+	got := readCSV(t, output, ',')
+	if len(got) != len(want) {
+		t.Fatalf("输出 %d 行，期望 %d 行: %q", len(got), len(want), got)
+	}
+	for i := range want {
+		if strings.Join(got[i], "|") != strings.Join(want[i], "|") {
+			t.Errorf("第 %d 行 = %q，期望 %q", i+1, got[i], want[i])
+		}
+	}
+
+	// 未翻译的字段逐字节保留，包括引号
+	data, _ := os.ReadFile(output)
+	if !strings.Contains(string(data), `,"1,000"`+"\r\n") || !strings.HasPrefix(string(data), "id,description,price\r\n") {
+		t.Errorf("未翻译的内容被改动:\n%s", data)
+	}
+}
+
+func TestTSVBilingualAddsTranslatedColumn(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "terms.tsv")
+	if err := os.WriteFile(input, []byte("term\tnote\nGood morning\tgreeting\nThank you\tpoliteness\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	client, _ := newStubClient(t)
+	dt := &DocumentTranslator{Client: client, PDFMathTranslator: NewPDFMathTranslator(), CSVColumns: []string{"0"}, CSVHeader: true}
+	output, err := dt.TranslateDocument(input, filepath.Join(dir, "out.tsv"), "French", "", true, "bilingual", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "term\tterm_translated\tnote\nGood morning\t[French] Good morning\tgreeting\nThank you\t[French] Thank you\tpoliteness\n"
+	if data, _ := os.ReadFile(output); string(data) != want {
+		t.Errorf("输出 = %q，期望 %q", data, want)
+	}
+}
+
+func TestCSVSelectColumnsRejectsUnknownHeader(t *testing.T) {
+	input := filepath.Join(t.TempDir(), "products.csv")
+	if err := os.WriteFile(input, []byte(testCSV), 0644); err != nil {
+		t.Fatal(err)
+	}
+	doc, err := OpenCSV(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.SelectColumns([]string{"summary"}, true); err == nil {
+		t.Error("不存在的列名应返回错误")
+	}
+	if got := ParseCSVColumns(" description, 2 ,description,"); strings.Join(got, "|") != "description|2" {
+		t.Errorf("ParseCSVColumns = %q", got)
+	}
+}
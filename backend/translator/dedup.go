@@ -0,0 +1,38 @@
+package translator
+
+// BlockStats 文本块统计信息
+type BlockStats struct {
+	TotalBlocks  int // 文本块总数
+	UniqueBlocks int // 去重后的文本块数
+	SavedBlocks  int // 因去重节省的翻译次数
+}
+
+// UniqueBlocks 对文本块去重，规范化后（见 NormalizeText）相同的文本视为重复
+// unique 按首次出现顺序保存不重复的文本，indexMap[i] 为 unique[i] 在原列表中的所有索引
+func UniqueBlocks(blocks []string) (unique []string, indexMap [][]int) {
+	positions := make(map[string]int)
+
+	for i, block := range blocks {
+		key := NormalizeText(block)
+		if pos, exists := positions[key]; exists {
+			indexMap[pos] = append(indexMap[pos], i)
+			continue
+		}
+
+		positions[key] = len(unique)
+		unique = append(unique, block)
+		indexMap = append(indexMap, []int{i})
+	}
+
+	return unique, indexMap
+}
+
+// NewBlockStats 统计文本块去重情况
+func NewBlockStats(blocks []string) BlockStats {
+	unique, _ := UniqueBlocks(blocks)
+	return BlockStats{
+		TotalBlocks:  len(blocks),
+		UniqueBlocks: len(unique),
+		SavedBlocks:  len(blocks) - len(unique),
+	}
+}
@@ -0,0 +1,41 @@
+package translator
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestUniqueBlocks(t *testing.T) {
+	unique, indexMap := UniqueBlocks([]string{"Header", "Body", " Header ", "Header", "Footer"})
+	if want := []string{"Header", "Body", "Footer"}; !slices.Equal(unique, want) {
+		t.Errorf("unique = %q，期望 %q", unique, want)
+	}
+	want := [][]int{{0, 2, 3}, {1}, {4}}
+	if !slices.EqualFunc(indexMap, want, slices.Equal) {
+		t.Errorf("indexMap = %v，期望 %v", indexMap, want)
+	}
+
+	stats := NewBlockStats([]string{"Header", "Body", "Header"})
+	if stats.TotalBlocks != 3 || stats.UniqueBlocks != 2 || stats.SavedBlocks != 1 {
+		t.Errorf("NewBlockStats = %+v", stats)
+	}
+}
+
+func TestTranslateBlocksTranslatesDuplicatesOnce(t *testing.T) {
+	client, stub := newStubClient(t)
+	texts := []string{"Repeated running header", "Repeated running header", "Repeated running header"}
+
+	results := client.TranslateBlocks(texts, "French", "", nil)
+
+	if stub.Calls() != 1 {
+		t.Errorf("提供商被调用 %d 次，期望 1 次", stub.Calls())
+	}
+	if len(results) != len(texts) {
+		t.Fatalf("得到 %d 个结果，期望 %d 个", len(results), len(texts))
+	}
+	for i, result := range results {
+		if result.Err != nil || result.Index != i || result.Translated != "[French] Repeated running header" {
+			t.Errorf("第 %d 个结果 = %+v", i, result)
+		}
+	}
+}
@@ -14,12 +14,20 @@ type Document interface {
 	Save(outputPath string) error
 }
 
+// AttributeTextProvider 可提供属性文本（如图片替代文本）的文档
+type AttributeTextProvider interface {
+	GetAttributeTextBlocks() []string
+}
+
 // DocumentType 文档类型
 type DocumentType string
 
 const (
 	DocumentTypeEPUB DocumentType = "epub"
 	DocumentTypePDF  DocumentType = "pdf"
+	DocumentTypePPTX DocumentType = "pptx"
+	DocumentTypeCSV  DocumentType = "csv"
+	DocumentTypeTSV  DocumentType = "tsv"
 )
 
 // TranslationMode 翻译模式
@@ -49,6 +57,20 @@ func OpenDocument(filePath string) (Document, DocumentType, error) {
 		}
 		return doc, DocumentTypePDF, nil
 
+	case ".pptx":
+		doc, err := OpenPPTX(filePath)
+		if err != nil {
+			return nil, "", fmt.Errorf("打开 PPTX 文件失败: %w", err)
+		}
+		return doc, DocumentTypePPTX, nil
+
+	case ".csv", ".tsv":
+		doc, err := OpenCSV(filePath)
+		if err != nil {
+			return nil, "", fmt.Errorf("打开表格文件失败: %w", err)
+		}
+		return doc, DocumentType(strings.TrimPrefix(ext, ".")), nil
+
 	default:
 		return nil, "", fmt.Errorf("不支持的文件格式: %s", ext)
 	}
@@ -63,8 +85,12 @@ func ValidateDocument(filePath string) error {
 		return ValidateEPUB(filePath)
 	case ".pdf":
 		return ValidatePDF(filePath)
+	case ".pptx":
+		return ValidatePPTX(filePath)
+	case ".csv", ".tsv":
+		return ValidateCSV(filePath)
 	default:
-		return fmt.Errorf("不支持的文件格式: %s，仅支持 .epub 和 .pdf 文件", ext)
+		return fmt.Errorf("不支持的文件格式: %s，仅支持 .epub、.pdf、.pptx、.csv 和 .tsv 文件", ext)
 	}
 }
 
@@ -120,6 +146,24 @@ func GetDocumentInfo(filePath string) (map[string]interface{}, error) {
 		info["advancedTranslationAvailable"] = IsPDFMathTranslateAvailable()
 		info["recommendedMode"] = string(GetRecommendedTranslationMode(DocumentTypePDF))
 
+	case ".pptx":
+		pptx, err := OpenPPTX(filePath)
+		if err != nil {
+			return nil, err
+		}
+		info["type"] = "PPTX"
+		info["slides"] = len(pptx.GetSlideFiles())
+		info["textBlocks"] = len(pptx.GetTextBlocks())
+
+	case ".csv", ".tsv":
+		table, err := OpenCSV(filePath)
+		if err != nil {
+			return nil, err
+		}
+		info["type"] = strings.ToUpper(strings.TrimPrefix(ext, "."))
+		info["rows"] = len(table.records)
+		info["textBlocks"] = len(table.GetTextBlocks())
+
 	default:
 		return nil, fmt.Errorf("不支持的文件格式: %s", ext)
 	}
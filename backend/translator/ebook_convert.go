@@ -0,0 +1,64 @@
+package translator
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// ebookConvertCommand Calibre 的电子书转换命令
+const ebookConvertCommand = "ebook-convert"
+
+// ErrEbookConverterUnavailable 没有安装电子书转换工具，无法处理 Kindle 格式
+var ErrEbookConverterUnavailable = errors.New("服务端未安装 Calibre（ebook-convert），无法转换 .mobi/.azw3 电子书，请先转换为 EPUB 后上传")
+
+// IsKindleExt 扩展名是否为需要先转换为 EPUB 的 Kindle 电子书格式（.mobi、.azw3）
+func IsKindleExt(ext string) bool {
+	ext = strings.ToLower(ext)
+	return ext == ".mobi" || ext == ".azw3"
+}
+
+// EbookConverterAvailable 是否可以转换 Kindle 电子书（PATH 中有 ebook-convert）
+func EbookConverterAvailable() bool {
+	_, err := exec.LookPath(ebookConvertCommand)
+	return err == nil
+}
+
+// ConvertEbookToEPUB 调用 ebook-convert 将 Kindle 电子书转换为 EPUB，返回 EPUB 路径（与输入同目录、同名）
+// 没有安装转换工具时返回 ErrEbookConverterUnavailable
+func ConvertEbookToEPUB(ctx context.Context, inputPath string) (string, error) {
+	bin, err := exec.LookPath(ebookConvertCommand)
+	if err != nil {
+		return "", ErrEbookConverterUnavailable
+	}
+
+	outputPath := strings.TrimSuffix(inputPath, filepath.Ext(inputPath)) + ".epub"
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, bin, inputPath, outputPath)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		os.Remove(outputPath)
+		if ctx.Err() != nil {
+			return "", fmt.Errorf("转换电子书中止: %w", ctx.Err())
+		}
+		if reason := lastLine(stderr.String()); reason != "" {
+			err = fmt.Errorf("%w: %s", err, reason)
+		}
+		return "", fmt.Errorf("转换电子书为 EPUB 失败（文件可能有 DRM 保护或已损坏）: %w", err)
+	}
+	if _, err := os.Stat(outputPath); err != nil {
+		return "", fmt.Errorf("转换电子书为 EPUB 失败: 没有生成输出文件")
+	}
+	return outputPath, nil
+}
+
+// lastLine 返回输出中最后一个非空行，ebook-convert 的错误原因通常在最后
+func lastLine(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
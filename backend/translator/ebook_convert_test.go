@@ -0,0 +1,51 @@
+package translator
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestConvertKindleEbookToEPUB(t *testing.T) {
+	if !EbookConverterAvailable() {
+		t.Skip("未安装 ebook-convert，跳过 Kindle 电子书转换测试")
+	}
+	dir := t.TempDir()
+	const paragraph = "The first chapter opens on a quiet morning."
+	source := writeTestEPUB(t, dir, []string{paragraph})
+	mobi := filepath.Join(dir, "book.mobi")
+	if out, err := exec.Command(ebookConvertCommand, source, mobi).CombinedOutput(); err != nil {
+		t.Fatalf("生成 .mobi 失败: %v\n%s", err, out)
+	}
+
+	path, err := ConvertEbookToEPUB(context.Background(), mobi)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != filepath.Join(dir, "book.epub") {
+		t.Errorf("EPUB 路径 = %s", path)
+	}
+	epub, err := OpenEPUB(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if blocks := epub.GetTextBlocks(); !slices.Contains(blocks, paragraph) {
+		t.Errorf("转换后的文本块 = %q，期望包含 %q", blocks, paragraph)
+	}
+}
+
+func TestConvertEbookWithoutConverter(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	if EbookConverterAvailable() {
+		t.Fatal("PATH 中没有 ebook-convert 时应不可用")
+	}
+	if _, err := ConvertEbookToEPUB(context.Background(), "book.azw3"); !errors.Is(err, ErrEbookConverterUnavailable) {
+		t.Errorf("ConvertEbookToEPUB 错误 = %v，期望 ErrEbookConverterUnavailable", err)
+	}
+	if !IsKindleExt(".MOBI") || !IsKindleExt(".azw3") || IsKindleExt(".epub") {
+		t.Error("IsKindleExt 判断错误")
+	}
+}
@@ -6,7 +6,6 @@ import (
 	"encoding/xml"
 	"fmt"
 	"io"
-	"os"
 	"path/filepath"
 	"strings"
 )
@@ -16,6 +15,7 @@ type EPUBFile struct {
 	Path     string
 	Files    map[string][]byte
 	Metadata EPUBMetadata
+	order    []string // 压缩包中文件的原始顺序，保存时保持不变（mimetype 需位于开头）
 }
 
 type EPUBMetadata struct {
@@ -51,6 +51,7 @@ func OpenEPUB(path string) (*EPUBFile, error) {
 		}
 
 		epub.Files[f.Name] = content
+		epub.order = append(epub.order, f.Name)
 	}
 
 	// 解析元数据
@@ -98,35 +99,19 @@ func (e *EPUBFile) GetHTMLFiles() []string {
 }
 
 // SaveEPUB 保存 EPUB 文件
+// 先写入临时文件再重命名覆盖，保存失败时不会留下不完整的输出
 func (e *EPUBFile) SaveEPUB(outputPath string) error {
-	// 创建输出目录
-	dir := filepath.Dir(outputPath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return err
-	}
-
-	// 创建 ZIP 文件
-	f, err := os.Create(outputPath)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-
-	w := zip.NewWriter(f)
-	defer w.Close()
+	return writeZipAtomic(outputPath, fileOrder(e.order, e.Files), e.Files)
+}
 
-	// 写入所有文件
-	for name, content := range e.Files {
-		fw, err := w.Create(name)
-		if err != nil {
-			return err
-		}
-		if _, err := fw.Write(content); err != nil {
-			return err
-		}
-	}
+// Snapshot 创建当前文件内容的快照，可通过 Restore 回滚
+func (e *EPUBFile) Snapshot() DocumentSnapshot {
+	return snapshotFiles(e.Files)
+}
 
-	return nil
+// Restore 回滚到快照时的文件内容
+func (e *EPUBFile) Restore(snapshot DocumentSnapshot) {
+	e.Files = snapshotFiles(snapshot)
 }
 
 // extractXMLTag 简单提取 XML 标签内容
@@ -307,6 +292,57 @@ func ExtractTextBlocks(html string) []string {
 	return blocks
 }
 
+// translatableAttributes 需要翻译的属性（如图片替代文本、提示文本）
+var translatableAttributes = map[string]bool{
+	"alt":   true,
+	"title": true,
+}
+
+// ExtractAttributeTextBlocks 提取 alt、title 等可翻译属性的值
+func ExtractAttributeTextBlocks(html string) []string {
+	var blocks []string
+	decoder := xml.NewDecoder(strings.NewReader(html))
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return blocks
+		}
+
+		if t, ok := token.(xml.StartElement); ok {
+			for _, attr := range t.Attr {
+				value := strings.TrimSpace(attr.Value)
+				if translatableAttributes[attr.Name.Local] && shouldExtractText(value) {
+					blocks = append(blocks, value)
+				}
+			}
+		}
+	}
+}
+
+// writeAttributes 写入元素属性，alt、title 属性替换为译文（双语模式保留原文），属性值进行 XML 转义
+func writeAttributes(buf *bytes.Buffer, attrs []xml.Attr, translations map[string]string, bilingual bool) {
+	for _, attr := range attrs {
+		value := attr.Value
+		if translatableAttributes[attr.Name.Local] {
+			original := strings.TrimSpace(value)
+			if trans, ok := translations[original]; ok && trans != "" {
+				if bilingual {
+					value = original + " / " + trans
+				} else {
+					value = trans
+				}
+			}
+		}
+
+		buf.WriteString(" ")
+		buf.WriteString(attributeName(attr.Name))
+		buf.WriteString(`="`)
+		xml.EscapeText(buf, []byte(value))
+		buf.WriteString(`"`)
+	}
+}
+
 // shouldExtractText 判断文本是否应该被提取（过滤掉纯标点符号等）
 func shouldExtractText(text string) bool {
 	// 过滤掉空文本
@@ -358,9 +394,7 @@ func InsertTranslation(html string, translations map[string]string) string {
 		case xml.StartElement:
 			buf.WriteString("<")
 			buf.WriteString(t.Name.Local)
-			for _, attr := range t.Attr {
-				buf.WriteString(fmt.Sprintf(` %s="%s"`, attr.Name.Local, attr.Value))
-			}
+			writeAttributes(&buf, t.Attr, translations, true)
 			buf.WriteString(">")
 			depth++
 
@@ -456,66 +490,98 @@ func (e *EPUBFile) GetTextBlocks() []string {
 			continue
 		}
 
-		blocks := ExtractTextBlocks(htmlContent.Body)
+		// 导航和内联 SVG 中的文本单独提取，避免与周围段落合并
+		body, navs := extractNav(htmlContent.Body)
+		body, svgs := extractInlineSVG(body)
+		blocks := ExtractTextBlocks(body)
 		allBlocks = append(allBlocks, blocks...)
+		for _, nav := range navs {
+			allBlocks = append(allBlocks, ExtractNavTextBlocks(nav)...)
+		}
+		for _, svg := range svgs {
+			allBlocks = append(allBlocks, ExtractSVGTextBlocks(svg)...)
+		}
 	}
 
-	return allBlocks
+	return append(allBlocks, e.getSVGTextBlocks()...)
 }
 
-// InsertTranslation 插入翻译（实现 Document 接口）
-func (e *EPUBFile) InsertTranslation(translations map[string]string) error {
-	htmlFiles := e.GetHTMLFiles()
+// GetAttributeTextBlocks 获取 alt、title 等属性中的可翻译文本
+func (e *EPUBFile) GetAttributeTextBlocks() []string {
+	var allBlocks []string
 
-	for _, filename := range htmlFiles {
-		content := e.Files[filename]
-		htmlContent, err := ParseHTML(content)
+	for _, filename := range e.GetHTMLFiles() {
+		htmlContent, err := ParseHTML(e.Files[filename])
 		if err != nil {
 			continue
 		}
 
-		// 插入翻译
-		translatedBody := InsertTranslation(htmlContent.Body, translations)
-
-		// 重新构建完整的 HTML
-		originalStr := string(content)
-		bodyStart := strings.Index(originalStr, "<body")
-		if bodyStart == -1 {
-			continue
-		}
+		body, _ := extractNav(htmlContent.Body)
+		body, _ = extractInlineSVG(body)
+		allBlocks = append(allBlocks, ExtractAttributeTextBlocks(body)...)
+	}
 
-		bodyStartEnd := strings.Index(originalStr[bodyStart:], ">") + bodyStart + 1
-		bodyEnd := strings.Index(originalStr, "</body>")
-		if bodyEnd == -1 {
-			bodyEnd = len(originalStr)
-		}
+	return allBlocks
+}
 
-		newContent := originalStr[:bodyStartEnd] + translatedBody + originalStr[bodyEnd:]
-		e.Files[filename] = []byte(newContent)
+// InsertTranslation 插入翻译（实现 Document 接口）
+func (e *EPUBFile) InsertTranslation(translations map[string]string) error {
+	if err := e.rewriteSVGFiles(translations, true); err != nil {
+		return err
 	}
-
-	return nil
+	return e.rewriteBodies(func(body string) string {
+		return rewriteWithNav(body, func(html string) string {
+			return rewriteWithInlineSVG(html, func(html string) string {
+				return InsertTranslation(html, translations)
+			}, func(svg string) string {
+				return InsertSVGTranslation(svg, translations, true)
+			})
+		}, func(nav string) string {
+			return InsertNavTranslation(nav, translations, true)
+		})
+	})
 }
 
 // InsertMonolingualTranslation 插入单语翻译（实现 Document 接口）
 func (e *EPUBFile) InsertMonolingualTranslation(translations map[string]string) error {
-	htmlFiles := e.GetHTMLFiles()
+	if err := e.rewriteSVGFiles(translations, false); err != nil {
+		return err
+	}
+	return e.rewriteBodies(func(body string) string {
+		// 插入单语翻译（替换原文）
+		return rewriteWithNav(body, func(html string) string {
+			return rewriteWithInlineSVG(html, func(html string) string {
+				return InsertMonolingualTranslation(html, translations)
+			}, func(svg string) string {
+				return InsertSVGTranslation(svg, translations, false)
+			})
+		}, func(nav string) string {
+			return InsertNavTranslation(nav, translations, false)
+		})
+	})
+}
+
+// rewriteBodies 改写所有 HTML 文件的 body 内容
+// 在副本上修改，全部成功后才写回，出错时文档保持不变
+func (e *EPUBFile) rewriteBodies(rewrite func(body string) string) error {
+	return rewriteFiles(e.Files, e.GetHTMLFiles(), func(filename string, content []byte) (result []byte, err error) {
+		// HTML 处理出现 panic 时转换为错误，避免留下部分修改
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("处理 %s 失败: %v", filename, r)
+			}
+		}()
 
-	for _, filename := range htmlFiles {
-		content := e.Files[filename]
 		htmlContent, err := ParseHTML(content)
 		if err != nil {
-			continue
+			return content, nil
 		}
 
-		// 插入单语翻译（替换原文）
-		translatedBody := InsertMonolingualTranslation(htmlContent.Body, translations)
-
 		// 重新构建完整的 HTML
 		originalStr := string(content)
 		bodyStart := strings.Index(originalStr, "<body")
 		if bodyStart == -1 {
-			continue
+			return content, nil
 		}
 
 		bodyStartEnd := strings.Index(originalStr[bodyStart:], ">") + bodyStart + 1
@@ -524,11 +590,9 @@ func (e *EPUBFile) InsertMonolingualTranslation(translations map[string]string)
 			bodyEnd = len(originalStr)
 		}
 
-		newContent := originalStr[:bodyStartEnd] + translatedBody + originalStr[bodyEnd:]
-		e.Files[filename] = []byte(newContent)
-	}
-
-	return nil
+		translatedBody := rewrite(htmlContent.Body)
+		return []byte(originalStr[:bodyStartEnd] + translatedBody + originalStr[bodyEnd:]), nil
+	})
 }
 
 // Save 保存文档（实现 Document 接口）
@@ -575,9 +639,7 @@ func InsertMonolingualTranslation(html string, translations map[string]string) s
 		case xml.StartElement:
 			buf.WriteString("<")
 			buf.WriteString(t.Name.Local)
-			for _, attr := range t.Attr {
-				buf.WriteString(fmt.Sprintf(` %s="%s"`, attr.Name.Local, attr.Value))
-			}
+			writeAttributes(&buf, t.Attr, translations, false)
 			buf.WriteString(">")
 			depth++
 
@@ -0,0 +1,67 @@
+package translator
+
+import (
+	"encoding/xml"
+	"regexp"
+	"strings"
+)
+
+// navSlotPattern 导航元素占位元素，HTML 改写后可能被写成自闭合或成对标签
+var navSlotPattern = regexp.MustCompile(`<navslot index="(\d+)"\s*(?:/>|>\s*</navslot>)`)
+
+// EPUB 3 导航文档中带前缀属性的命名空间
+const (
+	epubNamespace = "http://www.idpf.org/2007/ops"
+	xmlNamespace  = "http://www.w3.org/XML/1998/namespace"
+)
+
+// extractNav 将 HTML 中的 <nav> 元素（目录、landmarks、page-list 等）替换为占位元素
+// 导航中的 <li> 只能包含一个链接和可选的子列表，不能按段落在后面追加译文，
+// 且 HTML 改写会丢失 epub:type 等带前缀的属性，因此导航元素单独处理
+func extractNav(html string) (string, []string) {
+	return extractElements(html, "nav")
+}
+
+// ExtractNavTextBlocks 提取导航元素中的标签文本（标题、链接文字）
+func ExtractNavTextBlocks(nav string) []string {
+	var blocks []string
+	for _, node := range textNodesWithin(nav, "nav") {
+		blocks = append(blocks, strings.TrimSpace(node.text))
+	}
+	return blocks
+}
+
+// InsertNavTranslation 将导航元素中的标签文本替换为译文，双语模式下写成"原文 / 译文"
+// 只替换文本，href、epub:type、hidden 等属性和列表结构原样保留
+func InsertNavTranslation(nav string, translations map[string]string, bilingual bool) string {
+	return replaceTextNodes(nav, textNodesWithin(nav, "nav"), translations, bilingual)
+}
+
+// rewriteWithNav 分别改写 HTML 和其中的导航元素
+func rewriteWithNav(body string, rewriteHTML, rewriteNav func(string) string) string {
+	html, navs := extractNav(body)
+	if len(navs) == 0 {
+		return rewriteHTML(body)
+	}
+	for i, nav := range navs {
+		navs[i] = rewriteNav(nav)
+	}
+	return restoreElements(rewriteHTML(html), navSlotPattern, navs)
+}
+
+// attributeName 返回写回时使用的属性名，保留 epub:type、xml:lang 等带前缀的名称
+// 前缀在片段内已声明时解析器给出的是命名空间，换回常用前缀；无法识别的命名空间只保留本地名称
+func attributeName(name xml.Name) string {
+	switch name.Space {
+	case "":
+		return name.Local
+	case epubNamespace:
+		return "epub:" + name.Local
+	case xmlNamespace:
+		return "xml:" + name.Local
+	}
+	if strings.Contains(name.Space, "/") {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
@@ -0,0 +1,69 @@
+package translator
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testNav = `<?xml version="1.0" encoding="UTF-8"?>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" xml:lang="en">
+<head><title>Navigation</title></head>
+<body>
+<nav epub:type="toc" id="toc"><h1>Contents</h1><ol><li><a href="ch1.xhtml">Introduction</a></li></ol></nav>
+<nav epub:type="landmarks" hidden=""><ol><li><a epub:type="bibliography" href="ch1.xhtml#refs">Bibliography</a></li></ol></nav>
+<nav epub:type="page-list" hidden=""><ol><li><a href="ch1.xhtml#page1">Page one</a></li></ol></nav>
+</body>
+</html>`
+
+func TestEPUBNavLandmarksTranslated(t *testing.T) {
+	dir := t.TempDir()
+	source := writeTestEPUB(t, dir, []string{"Introduction text."})
+	doc, err := OpenEPUB(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc.Files["OEBPS/nav.xhtml"] = []byte(testNav)
+	input := filepath.Join(dir, "with-nav.epub")
+	if err := doc.Save(input); err != nil {
+		t.Fatal(err)
+	}
+
+	client, _ := newStubClient(t)
+	dt := &DocumentTranslator{Client: client, PDFMathTranslator: NewPDFMathTranslator()}
+	output, err := dt.TranslateDocument(input, filepath.Join(dir, "out.epub"), "French", "", true, "monolingual", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	translated, err := OpenEPUB(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nav := string(translated.Files["OEBPS/nav.xhtml"])
+	for _, want := range []string{
+		`<a epub:type="bibliography" href="ch1.xhtml#refs">[French] Bibliography</a>`,
+		`<nav epub:type="landmarks" hidden="">`,
+		`<a href="ch1.xhtml#page1">[French] Page one</a>`,
+		`<nav epub:type="toc" id="toc"><h1>[French] Contents</h1>`,
+		`xml:lang="en"`,
+	} {
+		if !strings.Contains(nav, want) {
+			t.Errorf("导航文档缺少 %s:\n%s", want, nav)
+		}
+	}
+
+	// 导航文档仍是格式正确的 XML
+	decoder := xml.NewDecoder(strings.NewReader(nav))
+	for {
+		if _, err := decoder.Token(); err != nil {
+			if !errors.Is(err, io.EOF) {
+				t.Errorf("导航文档不是合法的 XML: %v\n%s", err, nav)
+			}
+			break
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package translator
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// textNode 元素中的一段文本在原始内容中的位置，如 SVG <text>（含其中的 <tspan>、<textPath>）中的文本
+type textNode struct {
+	start, end int
+	text       string
+}
+
+// svgTextNodes 按出现顺序返回 SVG 中可翻译的文本节点
+// 使用 RawToken 保留原始偏移，改写时只替换这些区间，其余内容（命名空间、自闭合标签、定位属性）原样保留
+func svgTextNodes(content string) []textNode {
+	return textNodesWithin(content, "text")
+}
+
+// textNodesWithin 按出现顺序返回 name 元素（含其子元素）中可翻译的文本节点
+func textNodesWithin(content, name string) []textNode {
+	decoder := xml.NewDecoder(strings.NewReader(content))
+	decoder.Strict = false
+	decoder.Entity = xml.HTMLEntity
+
+	var nodes []textNode
+	depth := 0 // 位于 name 元素内的层数
+	for {
+		start := decoder.InputOffset()
+		token, err := decoder.RawToken()
+		if err != nil {
+			break
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if depth > 0 || t.Name.Local == name {
+				depth++
+			}
+		case xml.EndElement:
+			if depth > 0 {
+				depth--
+			}
+		case xml.CharData:
+			if depth > 0 && shouldExtractText(string(t)) {
+				nodes = append(nodes, textNode{start: int(start), end: int(decoder.InputOffset()), text: string(t)})
+			}
+		}
+	}
+	return nodes
+}
+
+// ExtractSVGTextBlocks 提取 SVG 中 <text>/<tspan> 的文本
+func ExtractSVGTextBlocks(svg string) []string {
+	var blocks []string
+	for _, node := range svgTextNodes(svg) {
+		blocks = append(blocks, strings.TrimSpace(node.text))
+	}
+	return blocks
+}
+
+// InsertSVGTranslation 将 SVG 文本替换为译文
+// SVG 文本按坐标定位，无法在原文下方追加一行，双语模式下写成"原文 / 译文"
+func InsertSVGTranslation(svg string, translations map[string]string, bilingual bool) string {
+	return replaceTextNodes(svg, svgTextNodes(svg), translations, bilingual)
+}
+
+// replaceTextNodes 将文本节点替换为译文，双语模式下写成"原文 / 译文"，节点以外的内容原样保留
+func replaceTextNodes(content string, nodes []textNode, translations map[string]string, bilingual bool) string {
+	if len(nodes) == 0 {
+		return content
+	}
+
+	var buf bytes.Buffer
+	last := 0
+	for _, node := range nodes {
+		original := strings.TrimSpace(node.text)
+		trans, ok := translations[original]
+		if !ok || trans == "" {
+			continue
+		}
+		if bilingual {
+			trans = original + " / " + trans
+		}
+
+		// 保留文本两侧的空白，避免影响 xml:space="preserve" 下的排版
+		leading := node.text[:len(node.text)-len(strings.TrimLeft(node.text, " \t\r\n"))]
+		trailing := node.text[len(strings.TrimRight(node.text, " \t\r\n")):]
+
+		buf.WriteString(content[last:node.start])
+		buf.WriteString(leading)
+		xml.EscapeText(&buf, []byte(trans))
+		buf.WriteString(trailing)
+		last = node.end
+	}
+	buf.WriteString(content[last:])
+	return buf.String()
+}
+
+// svgSlotPattern 内联 SVG 占位元素，HTML 改写后可能被写成自闭合或成对标签
+var svgSlotPattern = regexp.MustCompile(`<svgslot index="(\d+)"\s*(?:/>|>\s*</svgslot>)`)
+
+// extractInlineSVG 将 HTML 中的内联 <svg> 元素替换为占位元素
+// HTML 改写只保留标签的本地名称，会丢失 SVG 命名空间前缀，因此内联 SVG 单独处理
+func extractInlineSVG(html string) (string, []string) {
+	return extractElements(html, "svg")
+}
+
+// extractElements 将 HTML 中最外层的 name 元素替换为 <{name}slot index="N"/> 占位元素，返回替换后的 HTML 和各元素的原始内容
+func extractElements(html, name string) (string, []string) {
+	decoder := xml.NewDecoder(strings.NewReader(html))
+	decoder.Strict = false
+	decoder.AutoClose = xml.HTMLAutoClose
+	decoder.Entity = xml.HTMLEntity
+
+	var buf strings.Builder
+	var elements []string
+	last, elementStart, depth := 0, 0, 0
+	for {
+		start := int(decoder.InputOffset())
+		token, err := decoder.RawToken()
+		if err != nil {
+			break
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if depth == 0 && t.Name.Local == name {
+				elementStart = start
+			}
+			if depth > 0 || t.Name.Local == name {
+				depth++
+			}
+		case xml.EndElement:
+			if depth == 0 {
+				continue
+			}
+			depth--
+			if depth == 0 {
+				end := int(decoder.InputOffset())
+				buf.WriteString(html[last:elementStart])
+				fmt.Fprintf(&buf, `<%sslot index="%d"/>`, name, len(elements))
+				elements = append(elements, html[elementStart:end])
+				last = end
+			}
+		}
+	}
+	if len(elements) == 0 {
+		return html, nil
+	}
+	buf.WriteString(html[last:])
+	return buf.String(), elements
+}
+
+// restoreInlineSVG 将占位元素替换回（已翻译的）内联 SVG
+func restoreInlineSVG(html string, svgs []string) string {
+	return restoreElements(html, svgSlotPattern, svgs)
+}
+
+// restoreElements 将 pattern 匹配的占位元素替换回 elements 中对应的内容
+func restoreElements(html string, pattern *regexp.Regexp, elements []string) string {
+	return pattern.ReplaceAllStringFunc(html, func(slot string) string {
+		index, err := strconv.Atoi(pattern.FindStringSubmatch(slot)[1])
+		if err != nil || index >= len(elements) {
+			return slot
+		}
+		return elements[index]
+	})
+}
+
+// rewriteWithInlineSVG 分别改写 HTML 和其中的内联 SVG
+func rewriteWithInlineSVG(body string, rewriteHTML, rewriteSVG func(string) string) string {
+	html, svgs := extractInlineSVG(body)
+	if len(svgs) == 0 {
+		return rewriteHTML(body)
+	}
+	for i, svg := range svgs {
+		svgs[i] = rewriteSVG(svg)
+	}
+	return restoreInlineSVG(rewriteHTML(html), svgs)
+}
+
+// GetSVGFiles 获取所有独立的 SVG 图像文件
+func (e *EPUBFile) GetSVGFiles() []string {
+	var svgFiles []string
+	for name := range e.Files {
+		if strings.ToLower(filepath.Ext(name)) == ".svg" {
+			svgFiles = append(svgFiles, name)
+		}
+	}
+	return svgFiles
+}
+
+// getSVGTextBlocks 获取独立 SVG 文件中的文本
+func (e *EPUBFile) getSVGTextBlocks() []string {
+	var blocks []string
+	for _, filename := range e.GetSVGFiles() {
+		blocks = append(blocks, ExtractSVGTextBlocks(string(e.Files[filename]))...)
+	}
+	return blocks
+}
+
+// rewriteSVGFiles 改写所有独立 SVG 文件中的文本
+func (e *EPUBFile) rewriteSVGFiles(translations map[string]string, bilingual bool) error {
+	return rewriteFiles(e.Files, e.GetSVGFiles(), func(filename string, content []byte) ([]byte, error) {
+		return []byte(InsertSVGTranslation(string(content), translations, bilingual)), nil
+	})
+}
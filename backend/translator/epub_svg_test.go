@@ -0,0 +1,80 @@
+package translator
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testSVG = `<?xml version="1.0" encoding="UTF-8"?>
+<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" width="200" height="100">
+  <rect x="0" y="0" width="200" height="100"/>
+  <text x="10" y="20" font-size="12">Hello</text>
+  <text x="10" y="60"><tspan x="10" dy="1.2em">Second line</tspan></text>
+  <use xlink:href="#logo"/>
+</svg>`
+
+func TestInsertSVGTranslation(t *testing.T) {
+	if blocks := ExtractSVGTextBlocks(testSVG); strings.Join(blocks, "|") != "Hello|Second line" {
+		t.Fatalf("ExtractSVGTextBlocks = %q", blocks)
+	}
+
+	translations := map[string]string{"Hello": "Bonjour", "Second line": "Deuxième & ligne"}
+	got := InsertSVGTranslation(testSVG, translations, false)
+	for _, want := range []string{
+		`<text x="10" y="20" font-size="12">Bonjour</text>`,
+		`<tspan x="10" dy="1.2em">Deuxième &amp; ligne</tspan>`,
+		`<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" width="200" height="100">`,
+		`<rect x="0" y="0" width="200" height="100"/>`,
+		`<use xlink:href="#logo"/>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("输出缺少 %s:\n%s", want, got)
+		}
+	}
+
+	if got := InsertSVGTranslation(testSVG, translations, true); !strings.Contains(got, ">Hello / Bonjour</text>") {
+		t.Errorf("双语模式应写成 原文 / 译文:\n%s", got)
+	}
+}
+
+func TestEPUBSVGTranslated(t *testing.T) {
+	dir := t.TempDir()
+	source := writeTestEPUB(t, dir, []string{
+		`A diagram follows.</p><svg xmlns="http://www.w3.org/2000/svg" width="100" height="40"><text x="5" y="30">Hello</text></svg><p>After the diagram.`,
+	})
+	doc, err := OpenEPUB(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc.Files["OEBPS/images/cover.svg"] = []byte(testSVG)
+	input := filepath.Join(dir, "with-svg.epub")
+	if err := doc.Save(input); err != nil {
+		t.Fatal(err)
+	}
+
+	client, _ := newStubClient(t)
+	dt := &DocumentTranslator{Client: client, PDFMathTranslator: NewPDFMathTranslator()}
+	output, err := dt.TranslateDocument(input, filepath.Join(dir, "out.epub"), "French", "", true, "monolingual", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	translated, err := OpenEPUB(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	html := string(translated.Files["OEBPS/ch1.xhtml"])
+	for _, want := range []string{
+		`<svg xmlns="http://www.w3.org/2000/svg" width="100" height="40"><text x="5" y="30">[French] Hello</text></svg>`,
+		`[French] After the diagram.`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("章节缺少 %s:\n%s", want, html)
+		}
+	}
+	cover := string(translated.Files["OEBPS/images/cover.svg"])
+	if !strings.Contains(cover, `<text x="10" y="20" font-size="12">[French] Hello</text>`) || !strings.Contains(cover, `<rect x="0" y="0" width="200" height="100"/>`) {
+		t.Errorf("SVG 文件未正确翻译:\n%s", cover)
+	}
+}
@@ -0,0 +1,38 @@
+package translator
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEPUBImageAltTranslated(t *testing.T) {
+	input := writeTestEPUB(t, t.TempDir(), []string{
+		`The cat sat on the mat.</p><p><img src="images/cat.jpg" alt="A cat" title="Photo of a cat"/>`,
+	})
+	doc, err := OpenEPUB(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blocks := doc.GetAttributeTextBlocks()
+	if strings.Join(blocks, "|") != "A cat|Photo of a cat" {
+		t.Fatalf("GetAttributeTextBlocks = %q", blocks)
+	}
+
+	client, _ := newStubClient(t)
+	dt := &DocumentTranslator{Client: client, PDFMathTranslator: NewPDFMathTranslator()}
+	output, err := dt.TranslateDocument(input, filepath.Join(t.TempDir(), "out.epub"), "French", "", true, "monolingual", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	translated, err := OpenEPUB(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	html := string(translated.Files["OEBPS/ch1.xhtml"])
+	for _, want := range []string{`alt="[French] A cat"`, `title="[French] Photo of a cat"`, `src="images/cat.jpg"`} {
+		if !strings.Contains(html, want) {
+			t.Errorf("输出缺少 %s:\n%s", want, html)
+		}
+	}
+}
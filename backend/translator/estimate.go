@@ -0,0 +1,105 @@
+package translator
+
+import (
+	"fmt"
+	"math"
+	"unicode"
+)
+
+// Estimate 翻译量预估（不调用翻译服务）
+type Estimate struct {
+	BlockCount      int `json:"blockCount"`      // 需要翻译的文本块数（已按过滤规则排除）
+	TotalChars      int `json:"totalChars"`      // 需要翻译的字符总数
+	EstimatedTokens int `json:"estimatedTokens"` // 去重且未命中缓存的文本块的预估 token 数
+	UniqueBlocks    int `json:"uniqueBlocks"`    // 去重后的文本块数
+	CacheHits       int `json:"cacheHits"`       // 去重后已有缓存、无需请求的文本块数
+}
+
+// ExtractTranslatableBlocks 按翻译流程提取文档中的文本块
+func ExtractTranslatableBlocks(inputPath string) ([]string, DocumentType, error) {
+	doc, docType, err := OpenDocument(inputPath)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if docType == DocumentTypePDF {
+		parser := NewPDFParser("", "")
+		content, err := parser.ParsePDF(inputPath)
+		if err != nil {
+			return nil, docType, fmt.Errorf("解析PDF失败: %w", err)
+		}
+		return parser.GetTextForTranslation(content), docType, nil
+	}
+
+	blocks := doc.GetTextBlocks()
+	if provider, ok := doc.(AttributeTextProvider); ok {
+		blocks = append(blocks, provider.GetAttributeTextBlocks()...)
+	}
+	return blocks, docType, nil
+}
+
+// EstimateDocument 预估翻译文档所需的文本块、字符和 token 数量
+// 使用与翻译时相同的缓存键查询缓存，cache 为 nil 时不统计缓存命中
+func EstimateDocument(inputPath, targetLanguage, userPrompt string, config ProviderConfig, cache CacheStore, filter *BlockFilter) (*Estimate, error) {
+	blocks, docType, err := ExtractTranslatableBlocks(inputPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return EstimateBlocks(blocks, translationTargetLanguage(docType, targetLanguage), userPrompt, config, cache, filter), nil
+}
+
+// translationTargetLanguage 返回翻译时实际传给提供商的目标语言（用于计算缓存键）
+// PDF 流程中目标语言会先转换为语言代码再转换回来
+func translationTargetLanguage(docType DocumentType, targetLanguage string) string {
+	if docType == DocumentTypePDF {
+		return NewPDFMathTranslator().mapLanguageCode((&DocumentTranslator{}).mapLanguageCode(targetLanguage))
+	}
+	return targetLanguage
+}
+
+// EstimateBlocks 预估翻译一组文本块的开销
+func EstimateBlocks(blocks []string, targetLanguage, userPrompt string, config ProviderConfig, cache CacheStore, filter *BlockFilter) *Estimate {
+	base := &BaseProvider{Config: config}
+	estimate := &Estimate{}
+
+	var pending []string
+	for _, block := range blocks {
+		if !filter.ShouldTranslate(block) {
+			continue
+		}
+		pending = append(pending, block)
+		estimate.TotalChars += len([]rune(block))
+	}
+	estimate.BlockCount = len(pending)
+
+	unique, _ := UniqueBlocks(pending)
+	estimate.UniqueBlocks = len(unique)
+	for _, block := range unique {
+		// 列表标记、图表编号标签和行内公式不发送给翻译服务，缓存键与翻译时一致
+		body := prepareBlock(block, nil).body
+		if cache != nil {
+			if _, ok := cache.Get(CacheKeyWithOptions(body, targetLanguage, base.translateOptions(userPrompt))); ok {
+				estimate.CacheHits++
+				continue
+			}
+		}
+		estimate.EstimatedTokens += EstimateTokens(body)
+	}
+
+	return estimate
+}
+
+// EstimateTokens 粗略估算文本的 token 数：中日韩字符按每字1个，其余按每4个字符1个
+func EstimateTokens(text string) int {
+	cjk, other := 0, 0
+	for _, r := range text {
+		if unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) ||
+			unicode.Is(unicode.Katakana, r) || unicode.Is(unicode.Hangul, r) {
+			cjk++
+		} else {
+			other++
+		}
+	}
+	return cjk + int(math.Ceil(float64(other)/4))
+}
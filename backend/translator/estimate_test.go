@@ -0,0 +1,57 @@
+package translator
+
+import "testing"
+
+func TestEstimateDocumentCountsCacheHits(t *testing.T) {
+	input := writeTestEPUB(t, t.TempDir(), []string{
+		"The cat sat on the mat.",
+		"The cat sat on the mat.",
+		"12",
+		"Dogs bark at night.",
+	})
+	config := ProviderConfig{Type: "openai", Model: "gpt-4o-mini"}
+	opts := (&BaseProvider{Config: config}).translateOptions("")
+
+	cache := NewMemoryCache()
+	if err := cache.Set(CacheKeyWithOptions("The cat sat on the mat.", "French", opts), "Le chat"); err != nil {
+		t.Fatal(err)
+	}
+
+	estimate, err := EstimateDocument(input, "French", "", config, cache, NewBlockFilter())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Estimate{
+		BlockCount:      3,
+		TotalChars:      len("The cat sat on the mat.")*2 + len("Dogs bark at night."),
+		EstimatedTokens: EstimateTokens("Dogs bark at night."),
+		UniqueBlocks:    2,
+		CacheHits:       1,
+	}
+	if *estimate != want {
+		t.Fatalf("EstimateDocument = %+v, want %+v", *estimate, want)
+	}
+
+	// 其他目标语言的缓存不计入命中
+	estimate, err = EstimateDocument(input, "German", "", config, cache, NewBlockFilter())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if estimate.CacheHits != 0 || estimate.UniqueBlocks != 2 {
+		t.Fatalf("German: %+v", *estimate)
+	}
+}
+
+func TestEstimateTokens(t *testing.T) {
+	for text, want := range map[string]int{
+		"":         0,
+		"abcd":     1,
+		"abcde":    2,
+		"你好世界":     4,
+		"你好 world": 4,
+	} {
+		if got := EstimateTokens(text); got != want {
+			t.Errorf("EstimateTokens(%q) = %d, want %d", text, got, want)
+		}
+	}
+}
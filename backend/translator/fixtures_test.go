@@ -0,0 +1,132 @@
+package translator
+
+import (
+	"archive/zip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// writeTestPDF 生成每页一段文字的 A4 PDF，返回文件路径
+func writeTestPDF(t testing.TB, dir string, pages []string) string {
+	t.Helper()
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetFont("Helvetica", "", 12)
+	for _, text := range pages {
+		pdf.AddPage()
+		pdf.SetXY(20, 30)
+		pdf.MultiCell(170, 6, text, "", "L", false)
+	}
+	path := filepath.Join(dir, "test.pdf")
+	if err := pdf.OutputFileAndClose(path); err != nil {
+		t.Fatalf("生成测试PDF失败: %v", err)
+	}
+	return path
+}
+
+// newTestFlowProcessor 在临时目录中创建流处理器（工作目录位于当前目录的 cache 下），测试结束时清理
+func newTestFlowProcessor(t testing.TB, inputPath, outputPath string) *PDFFlowProcessor {
+	t.Helper()
+	t.Chdir(t.TempDir())
+	p, err := NewPDFFlowProcessor(inputPath, outputPath)
+	if err != nil {
+		t.Fatalf("NewPDFFlowProcessor: %v", err)
+	}
+	t.Cleanup(func() { p.Cleanup() })
+	return p
+}
+
+// writeTestEPUB 生成每章一段文字的最小 EPUB，返回文件路径
+func writeTestEPUB(t testing.TB, dir string, chapters []string) string {
+	t.Helper()
+	path := filepath.Join(dir, "test.epub")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	add := func(name, content string) {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	add("mimetype", "application/epub+zip")
+	add("META-INF/container.xml", `<?xml version="1.0"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles><rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/></rootfiles>
+</container>`)
+	var manifest, spine string
+	for i := range chapters {
+		manifest += fmt.Sprintf(`<item id="ch%d" href="ch%d.xhtml" media-type="application/xhtml+xml"/>`, i+1, i+1)
+		spine += fmt.Sprintf(`<itemref idref="ch%d"/>`, i+1)
+	}
+	add("OEBPS/content.opf", `<?xml version="1.0"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/"><dc:title>Test Book</dc:title><dc:language>en</dc:language></metadata>
+  <manifest>`+manifest+`</manifest>
+  <spine>`+spine+`</spine>
+</package>`)
+	for i, text := range chapters {
+		add(fmt.Sprintf("OEBPS/ch%d.xhtml", i+1), `<?xml version="1.0" encoding="utf-8"?>
+<html xmlns="http://www.w3.org/1999/xhtml"><head><title>Chapter</title></head>
+<body><p>`+text+`</p></body></html>`)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// writeTestPPTX 生成最小 PPTX，slides 中每项为一张幻灯片的段落，段落中的 "|" 分隔文本运行
+func writeTestPPTX(t testing.TB, dir string, slides [][]string) string {
+	t.Helper()
+	path := filepath.Join(dir, "test.pptx")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	add := func(name, content string) {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	add("[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"/>`)
+	add("ppt/presentation.xml", `<?xml version="1.0" encoding="UTF-8"?>
+<p:presentation xmlns:p="http://schemas.openxmlformats.org/presentationml/2006/main"/>`)
+	for i, paragraphs := range slides {
+		var body strings.Builder
+		for _, paragraph := range paragraphs {
+			body.WriteString(`<a:p>`)
+			for _, run := range strings.Split(paragraph, "|") {
+				body.WriteString(`<a:r><a:rPr lang="en-US" sz="2400"/><a:t>` + run + `</a:t></a:r>`)
+			}
+			body.WriteString(`</a:p>`)
+		}
+		add(fmt.Sprintf("ppt/slides/slide%d.xml", i+1), `<?xml version="1.0" encoding="UTF-8"?>
+<p:sld xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main" xmlns:p="http://schemas.openxmlformats.org/presentationml/2006/main">
+<p:cSld><p:spTree><p:sp><p:nvSpPr><p:cNvPr id="2" name="Title 1"/></p:nvSpPr>
+<p:txBody><a:bodyPr/>`+body.String()+`</p:txBody></p:sp></p:spTree></p:cSld></p:sld>`)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
@@ -0,0 +1,88 @@
+package translator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// FontPolicy 按语言选择字体文件，用于混合文字（如中文正文夹杂日文引文）的文档
+// 未显式配置的语言使用系统字体检测结果，拉丁文字默认使用内置字体
+type FontPolicy struct {
+	Fonts map[string]string // 语言代码（zh, ja, ko 等）-> 字体文件路径
+
+	mu       sync.Mutex
+	detected map[string]string // 系统字体检测结果缓存
+}
+
+// NewFontPolicy 创建默认字体策略
+func NewFontPolicy() *FontPolicy {
+	return &FontPolicy{
+		Fonts:    make(map[string]string),
+		detected: make(map[string]string),
+	}
+}
+
+// WithFont 为指定语言设置字体文件
+func (fp *FontPolicy) WithFont(lang, fontPath string) *FontPolicy {
+	fp.Fonts[strings.ToLower(lang)] = fontPath
+	return fp
+}
+
+// ParseFontPolicy 解析字体策略配置，格式为 "ja=/path/a.ttf;zh=/path/b.ttf"
+func ParseFontPolicy(spec string) (*FontPolicy, error) {
+	policy := NewFontPolicy()
+	for _, entry := range strings.Split(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		lang, fontPath, ok := strings.Cut(entry, "=")
+		lang, fontPath = strings.TrimSpace(lang), strings.TrimSpace(fontPath)
+		if !ok || lang == "" || fontPath == "" {
+			return nil, fmt.Errorf("字体策略格式错误: %q，应为 语言=字体文件", entry)
+		}
+		policy.WithFont(lang, fontPath)
+	}
+	return policy, nil
+}
+
+// FontPath 返回指定语言应使用的字体文件，返回空字符串表示使用内置字体
+func (fp *FontPolicy) FontPath(lang string) string {
+	lang = strings.ToLower(lang)
+	if fontPath, ok := fp.Fonts[lang]; ok {
+		return fontPath
+	}
+	if lang == "" || lang == "en" {
+		return ""
+	}
+
+	fp.mu.Lock()
+	defer fp.mu.Unlock()
+	if fontPath, ok := fp.detected[lang]; ok {
+		return fontPath
+	}
+	fontPath := NewSystemFontDetector().GetSystemFontPath(lang)
+	fp.detected[lang] = fontPath
+	return fontPath
+}
+
+// fontFamilyName 根据字体文件生成注册到 PDF 中的字体名称
+func fontFamilyName(fontPath string) string {
+	return strings.TrimSuffix(filepath.Base(fontPath), filepath.Ext(fontPath))
+}
+
+// addUTF8Font 读取字体文件并注册到 PDF
+// gofpdf 的 AddUTF8Font 会把文件路径拼接在字体目录（默认为 "."）之后，绝对路径会变成相对路径，因此直接传入字体数据
+func addUTF8Font(pdf *gofpdf.Fpdf, name, fontPath string) error {
+	data, err := os.ReadFile(fontPath)
+	if err != nil {
+		return err
+	}
+	pdf.AddUTF8FontFromBytes(name, "", data)
+	return pdf.Error()
+}
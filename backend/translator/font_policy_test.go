@@ -0,0 +1,61 @@
+package translator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jung-kurt/gofpdf"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+func TestFontPolicySelectsFontPerScript(t *testing.T) {
+	dir := t.TempDir()
+	kanaFont := filepath.Join(dir, "KanaFont.ttf")
+	hanFont := filepath.Join(dir, "HanFont.ttf")
+	for _, path := range []string{kanaFont, hanFont} {
+		if err := os.WriteFile(path, goregular.TTF, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	p := newTestFlowProcessor(t, "input.pdf", "")
+	p.FontPolicy = NewFontPolicy().WithFont("ja", kanaFont).WithFont("zh", hanFont)
+	pdf := gofpdf.New("P", "pt", "A4", "")
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"「日本語のテキストです」", "KanaFont"},
+		{"这是同一文档中的中文段落。", "HanFont"},
+		{"English text", ""},
+	}
+	for _, tt := range tests {
+		if got := p.policyFontName(pdf, tt.text); got != tt.want {
+			t.Errorf("policyFontName(%q) = %q，期望 %q", tt.text, got, tt.want)
+		}
+	}
+	if err := pdf.Error(); err != nil {
+		t.Errorf("注册字体失败: %v", err)
+	}
+}
+
+func TestParseFontPolicy(t *testing.T) {
+	policy, err := ParseFontPolicy(" ja=/fonts/a.ttf ; ZH=/fonts/b.ttf;")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := policy.FontPath("ja"); got != "/fonts/a.ttf" {
+		t.Errorf("ja 字体 = %q", got)
+	}
+	if got := policy.FontPath("zh"); got != "/fonts/b.ttf" {
+		t.Errorf("zh 字体 = %q", got)
+	}
+
+	for _, spec := range []string{"ja", "=/fonts/a.ttf", "ja="} {
+		if _, err := ParseFontPolicy(spec); err == nil {
+			t.Errorf("ParseFontPolicy(%q) 应返回错误", spec)
+		}
+	}
+}
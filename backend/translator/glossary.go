@@ -0,0 +1,243 @@
+package translator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// GlossaryEntry 术语表条目
+type GlossaryEntry struct {
+	ID             string    `json:"id"`
+	Source         string    `json:"source"`                   // 原文术语
+	Target         string    `json:"target"`                   // 固定译法
+	TargetLanguage string    `json:"targetLanguage,omitempty"` // 适用的目标语言，为空时适用于所有语言
+	Domain         string    `json:"domain,omitempty"`         // 适用的领域，为空时适用于所有领域
+	CreatedAt      time.Time `json:"createdAt"`
+}
+
+// appliesTo 条目是否适用于指定的目标语言和领域
+func (e GlossaryEntry) appliesTo(targetLanguage, domain string) bool {
+	return (e.TargetLanguage == "" || strings.EqualFold(e.TargetLanguage, targetLanguage)) &&
+		(e.Domain == "" || strings.EqualFold(e.Domain, domain))
+}
+
+// sameScope 两个条目是否针对同一术语和适用范围
+func (e GlossaryEntry) sameScope(other GlossaryEntry) bool {
+	return strings.EqualFold(e.Source, other.Source) &&
+		strings.EqualFold(e.TargetLanguage, other.TargetLanguage) &&
+		strings.EqualFold(e.Domain, other.Domain)
+}
+
+// GlossaryStore 持久化到磁盘的术语表，可在多个任务间共享
+type GlossaryStore struct {
+	mu      sync.RWMutex
+	path    string
+	entries []GlossaryEntry
+}
+
+// NewGlossaryStore 打开术语表文件，文件不存在时创建空术语表
+func NewGlossaryStore(path string) (*GlossaryStore, error) {
+	store := &GlossaryStore{path: path}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取术语表失败: %w", err)
+	}
+	if err := json.Unmarshal(data, &store.entries); err != nil {
+		return nil, fmt.Errorf("术语表文件格式错误: %w", err)
+	}
+	return store, nil
+}
+
+// List 返回适用于指定目标语言和领域的条目，参数为空时不按该条件过滤
+func (s *GlossaryStore) List(targetLanguage, domain string) []GlossaryEntry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entries := make([]GlossaryEntry, 0, len(s.entries))
+	for _, e := range s.entries {
+		if targetLanguage != "" && e.TargetLanguage != "" && !strings.EqualFold(e.TargetLanguage, targetLanguage) {
+			continue
+		}
+		if domain != "" && e.Domain != "" && !strings.EqualFold(e.Domain, domain) {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+// Add 添加条目，相同术语和适用范围的已有条目会被替换
+func (s *GlossaryStore) Add(entry GlossaryEntry) (GlossaryEntry, error) {
+	entry.Source = strings.TrimSpace(entry.Source)
+	entry.Target = strings.TrimSpace(entry.Target)
+	entry.TargetLanguage = strings.TrimSpace(entry.TargetLanguage)
+	entry.Domain = strings.TrimSpace(entry.Domain)
+	if entry.Source == "" || entry.Target == "" {
+		return GlossaryEntry{}, fmt.Errorf("术语和译法不能为空")
+	}
+	entry.ID = uuid.New().String()
+	entry.CreatedAt = time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries := make([]GlossaryEntry, 0, len(s.entries)+1)
+	for _, e := range s.entries {
+		if !e.sameScope(entry) {
+			entries = append(entries, e)
+		}
+	}
+	entries = append(entries, entry)
+	if err := s.saveLocked(entries); err != nil {
+		return GlossaryEntry{}, err
+	}
+	return entry, nil
+}
+
+// Delete 删除条目，条目不存在时返回 false
+func (s *GlossaryStore) Delete(id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, e := range s.entries {
+		if e.ID != id {
+			continue
+		}
+		entries := append(append([]GlossaryEntry(nil), s.entries[:i]...), s.entries[i+1:]...)
+		if err := s.saveLocked(entries); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// saveLocked 写入磁盘（先写临时文件再重命名，避免写入中断损坏术语表），成功后更新内存中的条目
+func (s *GlossaryStore) saveLocked(entries []GlossaryEntry) error {
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("保存术语表失败: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("保存术语表失败: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("保存术语表失败: %w", err)
+	}
+	s.entries = entries
+	return nil
+}
+
+// Glossary 一次翻译适用的术语表
+type Glossary []GlossaryEntry
+
+// NewGlossary 合并多个术语表中适用于指定目标语言和领域的条目，排在前面的术语表优先
+func NewGlossary(targetLanguage, domain string, stores ...*GlossaryStore) Glossary {
+	var glossary Glossary
+	seen := make(map[string]bool)
+	for _, store := range stores {
+		if store == nil {
+			continue
+		}
+		entries := store.List("", "")
+		// 限定了语言或领域的条目比通用条目更具体，优先使用
+		sort.SliceStable(entries, func(i, j int) bool {
+			return specificity(entries[i]) > specificity(entries[j])
+		})
+		for _, e := range entries {
+			key := strings.ToLower(e.Source)
+			if !e.appliesTo(targetLanguage, domain) || seen[key] {
+				continue
+			}
+			seen[key] = true
+			glossary = append(glossary, e)
+		}
+	}
+	sort.SliceStable(glossary, func(i, j int) bool { return glossary[i].Source < glossary[j].Source })
+	return glossary
+}
+
+// specificity 条目限定的条件数
+func specificity(e GlossaryEntry) int {
+	n := 0
+	if e.TargetLanguage != "" {
+		n++
+	}
+	if e.Domain != "" {
+		n++
+	}
+	return n
+}
+
+// PromptText 术语表的文本形式（每行 "原文 => 译法"），用于写入提示词
+func (g Glossary) PromptText() string {
+	lines := make([]string, len(g))
+	for i, e := range g {
+		lines[i] = e.Source + " => " + e.Target
+	}
+	return strings.Join(lines, "\n")
+}
+
+// termPattern 匹配原文术语的正则：忽略大小写，以字母数字结尾的术语要求完整单词匹配
+func termPattern(term string) *regexp.Regexp {
+	pattern := regexp.QuoteMeta(term)
+	if isWordRune(firstRune(term)) {
+		pattern = `\b` + pattern
+	}
+	if isWordRune(lastRune(term)) {
+		pattern += `\b`
+	}
+	return regexp.MustCompile(`(?i)` + pattern)
+}
+
+// isWordRune 是否为 ASCII 字母数字（\b 只对这些字符生效）
+func isWordRune(r rune) bool {
+	return r < 0x80 && (r == '_' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z')
+}
+
+func firstRune(s string) rune {
+	for _, r := range s {
+		return r
+	}
+	return 0
+}
+
+func lastRune(s string) rune {
+	r := []rune(s)
+	if len(r) == 0 {
+		return 0
+	}
+	return r[len(r)-1]
+}
+
+// Enforce 在译文中落实术语表：原文含有术语而译文中没有规定译法时，
+// 将译文中原样保留（未翻译）的术语替换为规定译法
+func (g Glossary) Enforce(source, translation string) string {
+	for _, e := range g {
+		pattern := termPattern(e.Source)
+		if !pattern.MatchString(source) || strings.Contains(translation, e.Target) {
+			continue
+		}
+		translation = pattern.ReplaceAllLiteralString(translation, e.Target)
+	}
+	return translation
+}
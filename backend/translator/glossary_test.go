@@ -0,0 +1,112 @@
+package translator
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGlossaryStorePersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "glossary.json")
+	store, err := NewGlossaryStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Add(GlossaryEntry{Source: " ", Target: "x"}); err == nil {
+		t.Error("空术语应返回错误")
+	}
+	first, err := store.Add(GlossaryEntry{Source: "transformer", Target: "转换器", TargetLanguage: "Uni"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 相同术语和适用范围的条目被替换
+	second, err := store.Add(GlossaryEntry{Source: "Transformer", Target: "变换器", TargetLanguage: "uni"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add(GlossaryEntry{Source: "kernel", Target: "Noyau", TargetLanguage: "French"})
+
+	reopened, err := NewGlossaryStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entries := reopened.List("", ""); len(entries) != 2 {
+		t.Fatalf("重新打开后有 %d 条，期望 2 条", len(entries))
+	}
+	if entries := reopened.List("Uni", ""); len(entries) != 1 || entries[0].Target != "变换器" {
+		t.Errorf("List(Uni) = %+v", entries)
+	}
+
+	if deleted, err := reopened.Delete(first.ID); err != nil || deleted {
+		t.Errorf("已被替换的条目不应存在，deleted = %v, err = %v", deleted, err)
+	}
+	if deleted, err := reopened.Delete(second.ID); err != nil || !deleted {
+		t.Errorf("删除失败，deleted = %v, err = %v", deleted, err)
+	}
+	if again, _ := NewGlossaryStore(path); len(again.List("", "")) != 1 {
+		t.Error("删除未持久化")
+	}
+}
+
+func TestNewGlossaryPriority(t *testing.T) {
+	session, _ := NewGlossaryStore(filepath.Join(t.TempDir(), "session.json"))
+	global, _ := NewGlossaryStore(filepath.Join(t.TempDir(), "global.json"))
+	session.Add(GlossaryEntry{Source: "model", Target: "模式"})
+	global.Add(GlossaryEntry{Source: "model", Target: "模型"})
+	global.Add(GlossaryEntry{Source: "kernel", Target: "核函数", Domain: "ml"})
+	global.Add(GlossaryEntry{Source: "kernel", Target: "内核"})
+	global.Add(GlossaryEntry{Source: "layer", Target: "couche", TargetLanguage: "French"})
+
+	glossary := NewGlossary("Uni", "ml", session, global)
+	want := "kernel => 核函数\nmodel => 模式"
+	if got := glossary.PromptText(); got != want {
+		t.Errorf("PromptText = %q，期望 %q", got, want)
+	}
+	if got := NewGlossary("Uni", "", global).PromptText(); got != "kernel => 内核\nmodel => 模型" {
+		t.Errorf("无领域时 PromptText = %q", got)
+	}
+}
+
+func TestGlossaryEnforce(t *testing.T) {
+	glossary := Glossary{{Source: "GPU", Target: "图形处理器"}, {Source: "net", Target: "网络"}}
+	tests := []struct {
+		source, translation, want string
+	}{
+		{"The GPU is fast", "GPU 很快", "图形处理器 很快"},
+		{"The GPU is fast", "图形处理器很快，GPU", "图形处理器很快，GPU"},
+		{"No terms here", "这里没有 GPU", "这里没有 GPU"},
+		{"The net works", "network 和 net", "network 和 网络"},
+	}
+	for _, tt := range tests {
+		if got := glossary.Enforce(tt.source, tt.translation); got != tt.want {
+			t.Errorf("Enforce(%q, %q) = %q，期望 %q", tt.source, tt.translation, got, tt.want)
+		}
+	}
+}
+
+func TestGlossaryAppliesToTranslation(t *testing.T) {
+	store, _ := NewGlossaryStore(filepath.Join(t.TempDir(), "glossary.json"))
+	if _, err := store.Add(GlossaryEntry{Source: "transformer", Target: "变换器", TargetLanguage: "Uni"}); err != nil {
+		t.Fatal(err)
+	}
+	glossary := NewGlossary("Uni", "", store)
+
+	// 翻译服务原样保留术语，由术语表在译文中替换
+	stub := newOpenAIStub(t, nil)
+	config := stub.Config()
+	config.Extra = map[string]string{"glossary": glossary.PromptText()}
+	client, err := NewTranslatorClient(config, NewMemoryCache())
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.WithGlossary(glossary)
+
+	results := client.TranslateBlocks([]string{"The transformer model"}, "Uni", "", nil)
+	if results[0].Err != nil || results[0].Translated != "[译] The 变换器 model" {
+		t.Errorf("译文 = %q, err = %v", results[0].Translated, results[0].Err)
+	}
+	requests := stub.Requests()
+	if len(requests) != 1 || !strings.Contains(requests[0].System, "transformer => 变换器") {
+		t.Errorf("提示词中应包含术语表，得到 %q", requests)
+	}
+}
@@ -0,0 +1,217 @@
+package translator
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// Granularity 发送给翻译服务的文本单位
+type Granularity string
+
+const (
+	GranularityBlock    Granularity = "block"    // 每个文本块一次请求（默认）
+	GranularitySentence Granularity = "sentence" // 文本块拆分为句子，逐句翻译和缓存后按原顺序拼接
+	GranularityPage     Granularity = "page"     // 同一页的文本块合并为一次请求，要求译文保持行结构
+)
+
+// ParseGranularity 解析翻译粒度，为空时按文本块翻译
+func ParseGranularity(value string) (Granularity, error) {
+	switch granularity := Granularity(strings.ToLower(strings.TrimSpace(value))); granularity {
+	case "":
+		return GranularityBlock, nil
+	case GranularityBlock, GranularitySentence, GranularityPage:
+		return granularity, nil
+	default:
+		return "", fmt.Errorf("不支持的翻译粒度: %s，可选: %s, %s, %s", value,
+			GranularityBlock, GranularitySentence, GranularityPage)
+	}
+}
+
+// pageInstruction 按页翻译时要求译文与原文逐行对应的提示词
+const pageInstruction = "The text is one page of a document with one paragraph per line. Translate it line by line: return exactly as many lines as the input, in the same order, without merging, splitting, numbering or omitting lines."
+
+// TextPages 返回 PDF 中各文本块原文所在的页码（同一原文取首次出现的页），用于按页翻译时分组
+func TextPages(content *PDFContent) map[string]int {
+	pages := make(map[string]int, len(content.TextBlocks))
+	for _, block := range content.TextBlocks {
+		if _, ok := pages[block.Text]; !ok && block.PageNum > 0 {
+			pages[block.Text] = block.PageNum
+		}
+	}
+	return pages
+}
+
+// sentenceUnit 文本块拆分出的句子在待翻译列表中的范围，以及句子之间的原始分隔
+type sentenceUnit struct {
+	start, end int
+	separators []string // separators[k] 为第 k 句与第 k+1 句之间的空白
+}
+
+// translateSentences 按句翻译：可拆分的文本块拆为句子统一去重翻译，再按原顺序拼接回文本块
+// 被过滤或人工修改过的文本块、只有一句的文本块以及含有会被过滤的短句的文本块整体翻译
+// 任一句子失败时整个文本块回退为原文；OnResult 在全部句子完成后按文本块回调
+func (c *TranslatorClient) translateSentences(texts []string, targetLanguage, userPrompt string, progressCallback func(float64)) []TranslateResult {
+	var sentences []string
+	units := make([]sentenceUnit, len(texts))
+	for i, text := range texts {
+		units[i].start = len(sentences)
+		if parts, separators := c.splitBlockSentences(text); len(parts) > 1 {
+			sentences = append(sentences, parts...)
+			units[i].separators = separators
+		} else {
+			sentences = append(sentences, text)
+		}
+		units[i].end = len(sentences)
+	}
+	log.Printf("按句翻译：%d 个文本块拆分为 %d 个句子", len(texts), len(sentences))
+
+	sentenceResults := c.translateUnits(sentences, targetLanguage, userPrompt, progressCallback, nil)
+
+	cjkTarget := isCJKLanguage(sentenceLanguageCode(targetLanguage))
+	results := make([]TranslateResult, len(texts))
+	for i, text := range texts {
+		unit := units[i]
+		result := TranslateResult{Index: i, Original: text, Confidence: 1.0}
+		var b strings.Builder
+		for k := unit.start; k < unit.end; k++ {
+			part := sentenceResults[k]
+			if part.Err != nil && result.Err == nil {
+				result.Err = part.Err
+			}
+			result.Confidence = min(result.Confidence, part.Confidence)
+			if k > unit.start {
+				b.WriteString(joinSeparator(unit.separators[k-unit.start-1], cjkTarget))
+			}
+			b.WriteString(part.Translated)
+		}
+		result.Translated = b.String()
+		if result.Err != nil {
+			result.Translated, result.UsedFallback, result.Confidence = text, true, 1.0
+		}
+		results[i] = result
+		if c.OnResult != nil {
+			c.OnResult(result)
+		}
+	}
+	return results
+}
+
+// splitBlockSentences 将文本块拆分为句子，并返回句子之间的原始空白
+// 被过滤或人工修改过的文本块，以及拆分后有句子会被过滤规则跳过时不拆分
+func (c *TranslatorClient) splitBlockSentences(text string) ([]string, []string) {
+	if _, edited := c.Edits[text]; edited || !c.Filter.ShouldTranslate(text) {
+		return nil, nil
+	}
+
+	language := c.Provider.GetConfig().Extra["sourceLanguage"]
+	if strings.EqualFold(language, "auto") {
+		language = ""
+	}
+	sentences := splitSentences(text, language)
+	if len(sentences) < 2 {
+		return nil, nil
+	}
+	separators := make([]string, 0, len(sentences)-1)
+	pos := 0
+	for k, sentence := range sentences {
+		if !c.Filter.ShouldTranslate(sentence) {
+			return nil, nil
+		}
+		offset := strings.Index(text[pos:], sentence)
+		if offset < 0 {
+			return nil, nil
+		}
+		if k > 0 {
+			separators = append(separators, text[pos:pos+offset])
+		}
+		pos += offset + len(sentence)
+	}
+	return sentences, separators
+}
+
+// joinSeparator 拼接译文句子时使用的分隔：原文在句间换行时保留换行，否则按目标语言决定是否加空格
+func joinSeparator(original string, cjkTarget bool) string {
+	if strings.Contains(original, "\n") {
+		return original
+	}
+	if cjkTarget {
+		return ""
+	}
+	return " "
+}
+
+// planPages 按页分组：同一页的文本块为一组，没有页码、被过滤或人工修改过的文本块单独成组
+func (c *TranslatorClient) planPages(unique []string) [][]int {
+	var batches [][]int
+	pageBatch := make(map[int]int) // 页码 -> batches 中的位置
+	for u, text := range unique {
+		page := c.Pages[text]
+		if _, edited := c.Edits[text]; edited || page <= 0 || !c.Filter.ShouldTranslate(text) {
+			batches = append(batches, []int{u})
+			continue
+		}
+		if b, ok := pageBatch[page]; ok {
+			batches[b] = append(batches[b], u)
+			continue
+		}
+		pageBatch[page] = len(batches)
+		batches = append(batches, []int{u})
+	}
+	return batches
+}
+
+// translatePageBlocks 将同一页的文本块合并为一次请求（每个文本块一行），按行拆分译文
+// 译文行数与原文不一致或请求失败时逐块翻译
+func (c *TranslatorClient) translatePageBlocks(unique []string, batch []int, indexMap [][]int, targetLanguage, userPrompt string) ([]string, []float64, []error) {
+	// 只有公式的文本块不参与合并，保持原文
+	blocks := make([]preparedBlock, len(batch))
+	var lines []string
+	for k, u := range batch {
+		blocks[k] = prepareBlock(unique[u], c.CaptionLabels)
+		if !blocks[k].formulaOnly() {
+			lines = append(lines, strings.Join(strings.Fields(blocks[k].body), " "))
+		}
+	}
+	if len(lines) < 2 || c.Context().Err() != nil {
+		return c.translateBatchBlocks(unique, batch, indexMap, targetLanguage, userPrompt)
+	}
+
+	pagePrompt := strings.TrimSpace(userPrompt + " " + pageInstruction)
+	translated, confidence, err := c.translateScored(strings.Join(lines, "\n"), targetLanguage, pagePrompt)
+	var results []string
+	if err == nil {
+		for _, line := range strings.Split(translated, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				results = append(results, line)
+			}
+		}
+		if len(results) != len(lines) {
+			err = fmt.Errorf("译文有 %d 行，原文有 %d 行", len(results), len(lines))
+		}
+	}
+	if err != nil {
+		log.Printf("警告：按页翻译 %d 个文本块失败，改为逐块翻译: %v", len(batch), err)
+		return c.translateBatchBlocks(unique, batch, indexMap, targetLanguage, userPrompt)
+	}
+
+	out := make([]string, len(batch))
+	confidences := make([]float64, len(batch))
+	errs := make([]error, len(batch))
+	line := 0
+	for k, u := range batch {
+		confidences[k] = 1.0
+		if blocks[k].formulaOnly() {
+			out[k] = unique[u]
+			continue
+		}
+		if out[k], errs[k] = blocks[k].finish(results[line]); errs[k] != nil {
+			// 公式占位符丢失的文本块单独重新翻译
+			out[k], confidences[k], errs[k] = c.translateBlock(unique[u], indexMap[u][0], targetLanguage, userPrompt)
+		} else {
+			confidences[k] = confidence
+		}
+		line++
+	}
+	return out, confidences, errs
+}
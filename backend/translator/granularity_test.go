@@ -0,0 +1,80 @@
+package translator
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSentenceGranularityTranslatesEachSentence(t *testing.T) {
+	stub := newOpenAIStub(t, nil)
+	cache := NewMemoryCache()
+	client, err := NewTranslatorClient(stub.Config(), cache)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.WithGranularity(GranularitySentence)
+
+	block := "The first sentence is here. The second one follows it. The third closes the block."
+	results := client.TranslateBlocks([]string{block}, "French", "", nil)
+	want := "[译] The first sentence is here. [译] The second one follows it. [译] The third closes the block."
+	if len(results) != 1 || results[0].Err != nil || results[0].Translated != want {
+		t.Fatalf("TranslateBlocks = %+v，期望 %q", results, want)
+	}
+
+	requests := stub.Requests()
+	if len(requests) != 3 {
+		t.Fatalf("翻译服务收到 %d 次请求，期望每句一次共 3 次", len(requests))
+	}
+	// 句子可能并发发送，排序后比较
+	sent := userTexts(requests)
+	slices.Sort(sent)
+	if want := []string{"The first sentence is here.", "The second one follows it.", "The third closes the block."}; !slices.Equal(sent, want) {
+		t.Errorf("发送的文本 = %q，期望逐句发送 %q", sent, want)
+	}
+	if n := cache.Len(); n != 3 {
+		t.Errorf("缓存 %d 条，期望每句一条共 3 条", n)
+	}
+
+	// 再次翻译时每句都命中缓存
+	client.TranslateBlocks([]string{block}, "French", "", nil)
+	if n := len(stub.Requests()); n != 3 {
+		t.Errorf("第二次翻译后共收到 %d 次请求，期望全部命中缓存", n)
+	}
+}
+
+func TestPageGranularityMergesPageBlocks(t *testing.T) {
+	stub := newOpenAIStub(t, func(req stubRequest) string {
+		lines := strings.Split(req.User, "\n")
+		for i, line := range lines {
+			lines[i] = "[译] " + line
+		}
+		return strings.Join(lines, "\n")
+	})
+	client, err := NewTranslatorClient(stub.Config(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.WithGranularity(GranularityPage)
+	client.Pages = map[string]int{"Page one first block.": 1, "Page one second block.": 1, "Page two only block.": 2}
+
+	texts := []string{"Page one first block.", "Page two only block.", "Page one second block."}
+	results := client.TranslateBlocks(texts, "French", "", nil)
+	for i, text := range texts {
+		if results[i].Translated != "[译] "+text {
+			t.Errorf("第 %d 块译文 = %q", i+1, results[i].Translated)
+		}
+	}
+	if n := len(stub.Requests()); n != 2 {
+		t.Errorf("翻译服务收到 %d 次请求，期望每页一次共 2 次", n)
+	}
+}
+
+// userTexts 返回各次请求的用户消息
+func userTexts(requests []stubRequest) []string {
+	texts := make([]string, len(requests))
+	for i, req := range requests {
+		texts[i] = req.User
+	}
+	return texts
+}
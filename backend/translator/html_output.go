@@ -0,0 +1,133 @@
+package translator
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// HTMLLayout 双语 HTML 的排版方式
+type HTMLLayout string
+
+const (
+	HTMLLayoutStacked    HTMLLayout = "stacked"      // 原文在上、译文在下
+	HTMLLayoutSideBySide HTMLLayout = "side-by-side" // 原文在左、译文在右（窄屏时自动改为上下排列）
+)
+
+// ParseHTMLLayout 解析排版方式，为空时使用上下排列
+func ParseHTMLLayout(value string) (HTMLLayout, error) {
+	switch layout := HTMLLayout(strings.ToLower(strings.TrimSpace(value))); layout {
+	case "":
+		return HTMLLayoutStacked, nil
+	case HTMLLayoutStacked, HTMLLayoutSideBySide:
+		return layout, nil
+	default:
+		return "", fmt.Errorf("不支持的 HTML 排版方式: %s，可选: %s, %s", value, HTMLLayoutStacked, HTMLLayoutSideBySide)
+	}
+}
+
+// htmlStyle 内嵌样式，生成的 HTML 不依赖任何外部资源
+const htmlStyle = `
+:root { color-scheme: light dark; }
+body {
+  max-width: 72rem;
+  margin: 0 auto;
+  padding: 2rem 1.5rem;
+  font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", "Noto Sans", "PingFang SC", "Microsoft YaHei", sans-serif;
+  font-size: 1rem;
+  line-height: 1.7;
+  color: #1f2328;
+  background: #ffffff;
+}
+header { border-bottom: 1px solid #d0d7de; margin-bottom: 1.5rem; }
+header h1 { font-size: 1.5rem; margin: 0 0 0.5rem; }
+header p { margin: 0 0 1rem; color: #656d76; font-size: 0.875rem; }
+.block { margin: 0 0 1.25rem; }
+.block p { margin: 0; white-space: pre-wrap; overflow-wrap: break-word; }
+.original { color: #656d76; }
+.stacked .original { margin-bottom: 0.4rem; }
+.side-by-side .block { display: grid; grid-template-columns: 1fr 1fr; gap: 1.5rem; padding-bottom: 1.25rem; border-bottom: 1px solid #eaeef2; }
+@media (max-width: 48rem) {
+  .side-by-side .block { grid-template-columns: 1fr; gap: 0.4rem; }
+}
+@media (prefers-color-scheme: dark) {
+  body { color: #e6edf3; background: #0d1117; }
+  header { border-color: #30363d; }
+  header p, .original { color: #8d96a0; }
+  .side-by-side .block { border-color: #21262d; }
+}
+@media print {
+  body { max-width: none; padding: 0; color: #000; background: #fff; }
+  .block { break-inside: avoid; }
+}
+`
+
+// htmlBlock HTML 输出中的一个段落
+type htmlBlock struct {
+	original   string
+	translated string
+}
+
+// renderHTML 生成完整的、自包含的 HTML 文档，所有文本均经过转义
+// bilingual 为 false 时只输出译文，layout 仅对双语输出生效
+func renderHTML(title string, meta []string, blocks []htmlBlock, bilingual bool, layout HTMLLayout) string {
+	if layout == "" {
+		layout = HTMLLayoutStacked
+	}
+
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html>\n<head>\n")
+	b.WriteString("<meta charset=\"utf-8\">\n")
+	b.WriteString("<meta name=\"viewport\" content=\"width=device-width, initial-scale=1\">\n")
+	b.WriteString("<title>" + html.EscapeString(title) + "</title>\n")
+	b.WriteString("<style>" + htmlStyle + "</style>\n")
+	b.WriteString("</head>\n")
+
+	class := "monolingual"
+	if bilingual {
+		class = string(layout)
+	}
+	b.WriteString("<body class=\"" + class + "\">\n<header>\n")
+	b.WriteString("<h1>" + html.EscapeString(title) + "</h1>\n")
+	if len(meta) > 0 {
+		escaped := make([]string, len(meta))
+		for i, line := range meta {
+			escaped[i] = html.EscapeString(line)
+		}
+		b.WriteString("<p>" + strings.Join(escaped, "<br>") + "</p>\n")
+	}
+	b.WriteString("</header>\n<main>\n")
+
+	for _, block := range blocks {
+		if strings.TrimSpace(block.original) == "" && strings.TrimSpace(block.translated) == "" {
+			continue
+		}
+		b.WriteString("<section class=\"block\">\n")
+		if bilingual {
+			b.WriteString("<p class=\"original\">" + html.EscapeString(block.original) + "</p>\n")
+		}
+		b.WriteString("<p class=\"translation\">" + html.EscapeString(block.translated) + "</p>\n")
+		b.WriteString("</section>\n")
+	}
+
+	b.WriteString("</main>\n</body>\n</html>\n")
+	return b.String()
+}
+
+// saveBlocksHTML 将文本块及其译文保存为 HTML 文件
+func saveBlocksHTML(outputPath, title string, blocks []string, translations map[string]string, bilingual bool, layout HTMLLayout) error {
+	items := make([]htmlBlock, 0, len(blocks))
+	for _, block := range blocks {
+		if strings.TrimSpace(block) == "" {
+			continue
+		}
+
+		translated, ok := translations[block]
+		if !ok {
+			translated = block
+		}
+		items = append(items, htmlBlock{original: block, translated: translated})
+	}
+
+	return writeTextFile(outputPath, renderHTML(title, nil, items, bilingual, layout))
+}
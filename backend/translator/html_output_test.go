@@ -0,0 +1,84 @@
+package translator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSaveBilingualHTML(t *testing.T) {
+	dir := t.TempDir()
+	doc := &PDFDocument{Path: filepath.Join(dir, "paper.pdf")}
+	original := []string{"Use <b> & <i> tags", "Line one\nLine two"}
+	translated := []string{"使用 <b> 和 & <i> 标签", "第一行\n第二行"}
+
+	output := filepath.Join(dir, "paper.html")
+	if err := doc.SaveBilingualHTML(output, original, translated, HTMLLayoutSideBySide); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !utf8.Valid(data) {
+		t.Fatal("输出不是有效的 UTF-8")
+	}
+	page := string(data)
+	for _, want := range []string{
+		"<!DOCTYPE html>",
+		`<meta charset="utf-8">`,
+		"<style>",
+		`<body class="side-by-side">`,
+		"Use &lt;b&gt; &amp; &lt;i&gt; tags",
+		"使用 &lt;b&gt; 和 &amp; &lt;i&gt; 标签",
+		"Line one\nLine two",
+		"第一行\n第二行",
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("HTML 缺少 %q", want)
+		}
+	}
+	if strings.Contains(page, "<b>") || strings.Contains(page, "<i>") {
+		t.Error("HTML 中有未转义的内容")
+	}
+	if strings.Contains(page, "<link") || strings.Contains(page, "src=") {
+		t.Error("HTML 引用了外部资源")
+	}
+}
+
+func TestSaveMonolingualHTML(t *testing.T) {
+	dir := t.TempDir()
+	doc := &PDFDocument{Path: filepath.Join(dir, "paper.pdf")}
+	output := filepath.Join(dir, "mono.html")
+	if err := doc.SaveMonolingualHTML(output, []string{"只有译文"}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := string(data)
+	if !strings.Contains(page, `<body class="monolingual">`) || !strings.Contains(page, "只有译文") {
+		t.Errorf("单语 HTML 内容不正确:\n%s", page)
+	}
+	if strings.Contains(page, `class="original"`) {
+		t.Error("单语 HTML 不应包含原文")
+	}
+}
+
+func TestParseHTMLLayout(t *testing.T) {
+	for value, want := range map[string]HTMLLayout{
+		"":             HTMLLayoutStacked,
+		"stacked":      HTMLLayoutStacked,
+		"Side-By-Side": HTMLLayoutSideBySide,
+	} {
+		if got, err := ParseHTMLLayout(value); err != nil || got != want {
+			t.Errorf("ParseHTMLLayout(%q) = (%q, %v)，期望 %q", value, got, err, want)
+		}
+	}
+	if _, err := ParseHTMLLayout("grid"); err == nil {
+		t.Error("不支持的排版方式应返回错误")
+	}
+}
@@ -0,0 +1,136 @@
+package translator
+
+import (
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/image/draw"
+)
+
+// DefaultImageQuality 重新压缩图片时默认的 JPEG 质量
+const DefaultImageQuality = 85
+
+// ImageOptions 重新嵌入图片时的压缩和降采样设置
+type ImageOptions struct {
+	Quality int     // JPEG 质量（1-100），0 表示不重新压缩
+	MaxDPI  float64 // 按图片在页面上的放置尺寸计算的最大分辨率，0 表示不降采样
+}
+
+// enabled 是否需要处理图片
+func (o ImageOptions) enabled() bool {
+	return o.Quality > 0 || o.MaxDPI > 0
+}
+
+// quality 返回有效的 JPEG 质量
+func (o ImageOptions) quality() int {
+	if o.Quality <= 0 {
+		return DefaultImageQuality
+	}
+	return min(o.Quality, 100)
+}
+
+// targetSize 计算放置尺寸（pt）在 MaxDPI 下允许的最大像素尺寸，保持宽高比
+// 图片不超过限制时返回原尺寸
+func (o ImageOptions) targetSize(pixelWidth, pixelHeight int, placedWidth, placedHeight float64) (int, int) {
+	if o.MaxDPI <= 0 || placedWidth <= 0 || placedHeight <= 0 {
+		return pixelWidth, pixelHeight
+	}
+
+	maxWidth := placedWidth / 72 * o.MaxDPI
+	maxHeight := placedHeight / 72 * o.MaxDPI
+	scale := math.Min(maxWidth/float64(pixelWidth), maxHeight/float64(pixelHeight))
+	if scale >= 1 {
+		return pixelWidth, pixelHeight
+	}
+	return max(1, int(math.Round(float64(pixelWidth)*scale))), max(1, int(math.Round(float64(pixelHeight)*scale)))
+}
+
+// OptimizeImage 按放置尺寸降采样并重新压缩图片，结果写入 outputDir，返回应嵌入的文件路径
+// 处理后没有变小（或无需处理）时返回原文件路径
+func OptimizeImage(path string, placedWidth, placedHeight float64, opts ImageOptions, outputDir string) (string, error) {
+	if !opts.enabled() {
+		return path, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	img, format, err := image.Decode(file)
+	file.Close()
+	if err != nil {
+		return "", fmt.Errorf("解码图片失败: %w", err)
+	}
+
+	bounds := img.Bounds()
+	width, height := opts.targetSize(bounds.Dx(), bounds.Dy(), placedWidth, placedHeight)
+	resized := width != bounds.Dx() || height != bounds.Dy()
+	// 只设置 MaxDPI 时，分辨率未超限的图片保持原样
+	if !resized && opts.Quality <= 0 {
+		return path, nil
+	}
+
+	if resized {
+		dst := image.NewRGBA(image.Rect(0, 0, width, height))
+		draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Over, nil)
+		img = dst
+	}
+
+	// 含透明通道的图片保持 PNG，其他图片按 JPEG 压缩
+	ext, encode := ".jpg", func(f *os.File) error {
+		return jpeg.Encode(f, img, &jpeg.Options{Quality: opts.quality()})
+	}
+	if format == "png" && !isOpaque(img) {
+		ext, encode = ".png", func(f *os.File) error {
+			return png.Encode(f, img)
+		}
+	}
+
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return "", err
+	}
+	base := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	outputPath := filepath.Join(outputDir, fmt.Sprintf("%s_%dx%d_q%d%s", base, width, height, opts.quality(), ext))
+	out, err := os.Create(outputPath)
+	if err != nil {
+		return "", err
+	}
+	if err := encode(out); err != nil {
+		out.Close()
+		os.Remove(outputPath)
+		return "", fmt.Errorf("编码图片失败: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		return "", err
+	}
+
+	// 重新压缩后反而更大时（如原图已高度压缩）使用原图
+	if !resized && fileSize(outputPath) >= fileSize(path) {
+		os.Remove(outputPath)
+		return path, nil
+	}
+	return outputPath, nil
+}
+
+// isOpaque 判断图片是否完全不透明
+func isOpaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+	return false
+}
+
+// fileSize 返回文件大小，读取失败时返回 0
+func fileSize(path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
@@ -0,0 +1,112 @@
+package translator
+
+import (
+	"image"
+	"image/color"
+	"image/jpeg"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// writeNoiseJPEG 生成 size×size 的随机噪点 JPEG（难以压缩，便于比较文件大小）
+func writeNoiseJPEG(t *testing.T, path string, size int) {
+	t.Helper()
+	rng := rand.New(rand.NewSource(1))
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			img.Set(x, y, color.RGBA{uint8(rng.Intn(256)), uint8(rng.Intn(256)), uint8(rng.Intn(256)), 255})
+		}
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := jpeg.Encode(f, img, &jpeg.Options{Quality: 95}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// imageSize 返回图片文件的像素尺寸
+func imageSize(t *testing.T, path string) (int, int) {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	cfg, _, err := image.DecodeConfig(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cfg.Width, cfg.Height
+}
+
+func TestOptimizeImage(t *testing.T) {
+	dir := t.TempDir()
+	large := filepath.Join(dir, "large.jpg")
+	small := filepath.Join(dir, "small.jpg")
+	writeNoiseJPEG(t, large, 600)
+	writeNoiseJPEG(t, small, 40)
+	opts := ImageOptions{MaxDPI: 72}
+
+	// 放置为 100pt 见方，72 DPI 下最多 100 像素
+	optimized, err := OptimizeImage(large, 100, 100, opts, filepath.Join(dir, "optimized"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if optimized == large {
+		t.Fatal("高分辨率图片应被降采样")
+	}
+	if w, h := imageSize(t, optimized); w != 100 || h != 100 {
+		t.Errorf("降采样后尺寸 = %dx%d，期望 100x100", w, h)
+	}
+	if fileSize(optimized) >= fileSize(large) {
+		t.Errorf("降采样后文件未变小: %d >= %d", fileSize(optimized), fileSize(large))
+	}
+
+	if got, err := OptimizeImage(small, 100, 100, opts, filepath.Join(dir, "optimized")); err != nil || got != small {
+		t.Errorf("小图片应保持原样，得到 (%q, %v)", got, err)
+	}
+	if got, err := OptimizeImage(large, 100, 100, ImageOptions{}, dir); err != nil || got != large {
+		t.Errorf("未设置选项时应保持原图，得到 (%q, %v)", got, err)
+	}
+}
+
+func TestRegeneratePDFDownsamplesImages(t *testing.T) {
+	dir := t.TempDir()
+	photo := filepath.Join(dir, "photo.jpg")
+	writeNoiseJPEG(t, photo, 600)
+
+	input := filepath.Join(dir, "figure.pdf")
+	pdf := gofpdf.New("P", "pt", "A4", "")
+	pdf.SetFont("Helvetica", "", 12)
+	pdf.AddPage()
+	pdf.Text(72, 72, "Figure 1: Noise")
+	pdf.ImageOptions(photo, 72, 100, 100, 100, false, gofpdf.ImageOptions{ImageType: "JPG"}, 0, "")
+	if err := pdf.OutputFileAndClose(input); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+
+	regenerate := func(name string, maxDPI float64) int64 {
+		output := filepath.Join(dir, name)
+		r := NewPDFRegenerator()
+		r.MaxImageDPI = maxDPI
+		if err := r.RegeneratePDF(input, output, map[string]string{"Figure 1: Noise": "Figure 1 : Bruit"}); err != nil {
+			t.Fatal(err)
+		}
+		return fileSize(output)
+	}
+
+	original := regenerate("original.pdf", 0)
+	downsampled := regenerate("downsampled.pdf", 72)
+	if downsampled*2 >= original {
+		t.Errorf("设置 MaxImageDPI 后输出应明显变小: %d -> %d", original, downsampled)
+	}
+}
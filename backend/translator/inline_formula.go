@@ -0,0 +1,167 @@
+package translator
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// inlineFormulaDetector 文本块内行内公式检测使用的检测器（只读，可并发使用）
+var inlineFormulaDetector = NewEnhancedFormulaDetector()
+
+var (
+	// formulaOperatorPattern 独立的运算符词，如 "="、"+"、"<="
+	formulaOperatorPattern = regexp.MustCompile(`^(?:[=<>+*/^|−]|<=|>=|:=|!=|==|->|=>)$`)
+	// formulaEquationPattern 不含空格的等式或表达式，如 "x=1"、"a+b"、"x_1"、"x^{2}"
+	formulaEquationPattern = regexp.MustCompile(`^[\p{L}\d(\[]+[^\s]*(?:[=<>^_+*]|\\[a-zA-Z]+)[^\s]*$`)
+	// formulaOperandPattern 可作为公式一部分的短词：单个字母、数字、函数调用，如 "L"、"2"、"f(x)"
+	formulaOperandPattern = regexp.MustCompile(`^(?:\p{L}|\d+(?:\.\d+)?|\p{L}?[(\[][\p{L}\d,]{0,6}[)\]])$`)
+	// formulaWordPattern 按空白切分的词
+	formulaWordPattern = regexp.MustCompile(`\S+`)
+	// tokenPlaceholderPattern 占位符格式，与 FormulaProtector 一致（翻译服务可能改为大写）
+	tokenPlaceholderPattern = regexp.MustCompile(`\{[vV]\d+\}`)
+)
+
+// FindInlineFormulas 查找文本中的行内公式，返回每段公式的字节区间 [start, end)
+// 先找出含数学符号、上下标、LaTeX 命令或运算符的词，再向两侧扩展到相邻的变量和数字
+func (efd *EnhancedFormulaDetector) FindInlineFormulas(text string) [][2]int {
+	type word struct {
+		start, end int
+		strong     bool // 明确属于公式
+		operand    bool // 可能属于公式（变量、数字）
+	}
+
+	var words []word
+	for _, loc := range formulaWordPattern.FindAllStringIndex(text, -1) {
+		start, end := loc[0], loc[1]
+		// 句末标点不属于公式
+		trimmed := strings.TrimRight(text[start:end], ".,;:!?，。；：")
+		if trimmed == "" {
+			continue
+		}
+		end = start + len(trimmed)
+		words = append(words, word{
+			start:   start,
+			end:     end,
+			strong:  efd.isFormulaWord(trimmed),
+			operand: formulaOperandPattern.MatchString(trimmed),
+		})
+	}
+
+	var spans [][2]int
+	for i := 0; i < len(words); {
+		if !words[i].strong {
+			i++
+			continue
+		}
+
+		first, last := i, i
+		for last+1 < len(words) && (words[last+1].strong || words[last+1].operand) {
+			last++
+		}
+		for first > 0 && words[first-1].operand && !words[first-1].strong {
+			first--
+		}
+
+		next := last + 1
+
+		// 两端悬空的运算符（如 "a + sign" 中的 "+"）不属于公式
+		isOperator := func(w word) bool { return formulaOperatorPattern.MatchString(text[w.start:w.end]) }
+		for last >= first && isOperator(words[last]) {
+			last--
+		}
+		for first <= last && isOperator(words[first]) {
+			first++
+		}
+		for k := first; k <= last; k++ {
+			if words[k].strong {
+				spans = append(spans, [2]int{words[first].start, words[last].end})
+				break
+			}
+		}
+		i = next
+	}
+	return spans
+}
+
+// isFormulaWord 判断单个词是否明确属于公式
+func (efd *EnhancedFormulaDetector) isFormulaWord(w string) bool {
+	return efd.hasMathRune(w) ||
+		efd.hasLaTeXCommands(w) ||
+		formulaOperatorPattern.MatchString(w) ||
+		formulaEquationPattern.MatchString(w)
+}
+
+// hasMathRune 判断是否包含数学符号或上下标字符
+func (efd *EnhancedFormulaDetector) hasMathRune(w string) bool {
+	for _, r := range w {
+		if efd.mathSymbols[r] || isScriptRune(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// isScriptRune 判断是否为 Unicode 上下标字符（如 ᵢ、²、ₙ）
+func isScriptRune(r rune) bool {
+	return (r >= 0x2070 && r <= 0x209F) || (r >= 0x1D2C && r <= 0x1D6A) ||
+		r == '¹' || r == '²' || r == '³'
+}
+
+// ProtectTokens 将文本中指定区间替换为 {v0}、{v1} 等占位符，返回替换后的文本和原始内容
+// 区间需按顺序排列且互不重叠
+func ProtectTokens(text string, spans [][2]int) (string, []string) {
+	if len(spans) == 0 {
+		return text, nil
+	}
+
+	var masked strings.Builder
+	tokens := make([]string, 0, len(spans))
+	last := 0
+	for i, span := range spans {
+		masked.WriteString(text[last:span[0]])
+		fmt.Fprintf(&masked, "{v%d}", i)
+		tokens = append(tokens, text[span[0]:span[1]])
+		last = span[1]
+	}
+	masked.WriteString(text[last:])
+	return masked.String(), tokens
+}
+
+// RestoreTokens 将占位符还原为原始内容，译文丢失占位符时返回错误
+func RestoreTokens(text string, tokens []string) (string, error) {
+	if len(tokens) == 0 {
+		return text, nil
+	}
+
+	found := make([]bool, len(tokens))
+	restored := tokenPlaceholderPattern.ReplaceAllStringFunc(text, func(placeholder string) string {
+		i, err := strconv.Atoi(placeholder[2 : len(placeholder)-1])
+		if err != nil || i >= len(tokens) {
+			return placeholder
+		}
+		found[i] = true
+		return tokens[i]
+	})
+
+	var missing []string
+	for i, ok := range found {
+		if !ok {
+			missing = append(missing, fmt.Sprintf("{v%d}", i))
+		}
+	}
+	if len(missing) > 0 {
+		return "", fmt.Errorf("译文缺少公式占位符: %s", strings.Join(missing, ", "))
+	}
+	return restored, nil
+}
+
+// ProtectInlineFormulas 将文本中的行内公式替换为占位符
+// 文本中已有占位符（已由 FormulaProtector 按元素保护）时不再处理，避免编号冲突
+func ProtectInlineFormulas(text string) (string, []string) {
+	if tokenPlaceholderPattern.MatchString(text) {
+		return text, nil
+	}
+	return ProtectTokens(text, inlineFormulaDetector.FindInlineFormulas(text))
+}
@@ -0,0 +1,48 @@
+package translator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestProtectInlineFormulas(t *testing.T) {
+	tests := []struct {
+		text   string
+		masked string
+		tokens []string
+	}{
+		{"the loss L = Σ xᵢ is minimized", "the loss {v0} is minimized", []string{"L = Σ xᵢ"}},
+		{"where x=1 and y^{2} hold.", "where {v0} and {v1} hold.", []string{"x=1", "y^{2}"}},
+		{"Use a + sign between terms", "Use a + sign between terms", nil},
+		{"Plain prose without math.", "Plain prose without math.", nil},
+		{"already masked {v0} text", "already masked {v0} text", nil},
+	}
+	for _, tt := range tests {
+		masked, tokens := ProtectInlineFormulas(tt.text)
+		if masked != tt.masked || strings.Join(tokens, "|") != strings.Join(tt.tokens, "|") {
+			t.Errorf("ProtectInlineFormulas(%q) = (%q, %q)，期望 (%q, %q)", tt.text, masked, tokens, tt.masked, tt.tokens)
+		}
+	}
+}
+
+func TestRestoreTokens(t *testing.T) {
+	tokens := []string{"L = Σ xᵢ"}
+	got, err := RestoreTokens("la perte {V0} est minimisée", tokens)
+	if err != nil || got != "la perte L = Σ xᵢ est minimisée" {
+		t.Errorf("RestoreTokens = (%q, %v)", got, err)
+	}
+	if _, err := RestoreTokens("la perte est minimisée", tokens); err == nil {
+		t.Error("译文丢失占位符时应返回错误")
+	}
+}
+
+func TestTranslateBlocksKeepsInlineFormula(t *testing.T) {
+	client, stub := newStubClient(t)
+	results := client.TranslateBlocks([]string{"the loss L = Σ xᵢ is minimized"}, "French", "", nil)
+	if len(stub.calls) != 1 || stub.calls[0] != "the loss {v0} is minimized" {
+		t.Fatalf("发送给翻译服务的文本 = %q", stub.calls)
+	}
+	if got := results[0].Translated; got != "[French] the loss L = Σ xᵢ is minimized" {
+		t.Fatalf("译文 = %q", got)
+	}
+}
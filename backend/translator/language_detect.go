@@ -0,0 +1,98 @@
+package translator
+
+import (
+	"unicode"
+)
+
+// scriptLanguages 文字系统 -> 语言代码（ISO 639-1），拉丁字母无法区分具体语言，按英文处理
+var scriptLanguages = []struct {
+	table *unicode.RangeTable
+	code  string
+}{
+	{unicode.Latin, "en"},
+	{unicode.Han, "zh"},
+	{unicode.Hangul, "ko"},
+	{unicode.Cyrillic, "ru"},
+	{unicode.Arabic, "ar"},
+	{unicode.Greek, "el"},
+	{unicode.Hebrew, "he"},
+	{unicode.Thai, "th"},
+	{unicode.Devanagari, "hi"},
+}
+
+// kanaRatio 假名占汉字和假名总数的比例达到该值时，汉字按日文计数
+const kanaRatio = 0.1
+
+// DetectDominantLanguage 统计所有文本中各文字系统的字符数，返回字符最多的语言代码
+// 没有可识别的文字时返回空字符串
+func DetectDominantLanguage(texts ...string) string {
+	counts := make(map[string]int)
+	kana := 0
+	for _, text := range texts {
+		for _, r := range text {
+			if !unicode.IsLetter(r) {
+				continue
+			}
+			if unicode.In(r, unicode.Hiragana, unicode.Katakana) {
+				kana++
+				continue
+			}
+			for _, script := range scriptLanguages {
+				if unicode.Is(script.table, r) {
+					counts[script.code]++
+					break
+				}
+			}
+		}
+	}
+
+	// 日文混用汉字和假名，假名比例足够高时将汉字计入日文
+	if kana > 0 {
+		if float64(kana) >= float64(kana+counts["zh"])*kanaRatio {
+			counts["ja"] = kana + counts["zh"]
+			delete(counts, "zh")
+		} else {
+			counts["ja"] = kana
+		}
+	}
+
+	// 按固定顺序比较，字符数相同时结果稳定
+	dominant, best := "", 0
+	for _, script := range scriptLanguages {
+		if counts[script.code] > best {
+			dominant, best = script.code, counts[script.code]
+		}
+	}
+	if counts["ja"] > best {
+		dominant = "ja"
+	}
+	return dominant
+}
+
+// DetectDominantLanguage 汇总所有页面的文字，返回文档的主要语言代码
+func (d *PDFDocument) DetectDominantLanguage() string {
+	return DetectDominantLanguage(d.PageTexts...)
+}
+
+// IsTargetLanguage 检测到的语言代码是否与目标语言（名称或代码，如 "English"、"zh-CN"）相同
+// 无法识别的目标语言名称视为不同
+func IsTargetLanguage(detected, targetLanguage string) bool {
+	return detected != "" && detected == sentenceLanguageCode(targetLanguage)
+}
+
+// DetectDocumentLanguage 打开文档并检测其主要语言，用于在任务开始时统一确定源语言
+func DetectDocumentLanguage(inputPath string) (string, error) {
+	doc, _, err := OpenDocument(inputPath)
+	if err != nil {
+		return "", err
+	}
+	return documentLanguage(doc), nil
+}
+
+// documentLanguage 检测已打开文档的主要语言
+func documentLanguage(doc Document) string {
+	if pdfDoc, ok := doc.(*PDFDocument); ok {
+		return pdfDoc.DetectDominantLanguage()
+	}
+	return DetectDominantLanguage(doc.GetTextBlocks()...)
+}
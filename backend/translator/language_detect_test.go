@@ -0,0 +1,45 @@
+package translator
+
+import "testing"
+
+func TestDetectDominantLanguage(t *testing.T) {
+	tests := []struct {
+		name  string
+		texts []string
+		want  string
+	}{
+		{"英文夹少量汉字", []string{"Deep learning for translation.", "The term 翻译 means translation in Uni.", "Results and discussion"}, "en"},
+		{"中文", []string{"机器翻译的研究进展", "基于 Transformer 模型的训练方法"}, "zh"},
+		{"日文", []string{"これは日本語の文書です。機械翻訳"}, "ja"},
+		{"中文夹个别假名", []string{"这是一篇关于机器翻译研究的中文论文，其中只引用了一个の字"}, "zh"},
+		{"俄文", []string{"Машинный перевод", "PDF"}, "ru"},
+		{"无文字", []string{"123 4.5", ""}, ""},
+	}
+	for _, tt := range tests {
+		if got := DetectDominantLanguage(tt.texts...); got != tt.want {
+			t.Errorf("%s: DetectDominantLanguage = %q，期望 %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPDFDocumentDominantLanguage(t *testing.T) {
+	doc := &PDFDocument{PageTexts: []string{
+		"Introduction to neural machine translation systems.",
+		"Table 1 lists 中文 examples alongside English ones.",
+		"Conclusion and future work.",
+	}}
+	if got := doc.DetectDominantLanguage(); got != "en" {
+		t.Errorf("DetectDominantLanguage = %q，期望 en", got)
+	}
+}
+
+func TestDetectDocumentLanguage(t *testing.T) {
+	path := writeTestEPUB(t, t.TempDir(), []string{"Machine translation overview", "参考文献", "Evaluation of the translated output"})
+	got, err := DetectDocumentLanguage(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "en" {
+		t.Errorf("DetectDocumentLanguage = %q，期望 en", got)
+	}
+}
@@ -0,0 +1,275 @@
+package translator
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// commonLanguages 大模型提供商支持的常用语言：ISO 639-1 代码 -> 英文名称
+var commonLanguages = map[string]string{
+	"af": "Afrikaans", "ar": "Arabic", "az": "Azerbaijani", "be": "Belarusian",
+	"bg": "Bulgarian", "bn": "Bengali", "bs": "Bosnian", "ca": "Catalan",
+	"cs": "Czech", "cy": "Welsh", "da": "Danish", "de": "German",
+	"el": "Greek", "en": "English", "eo": "Esperanto", "es": "Spanish",
+	"et": "Estonian", "eu": "Basque", "fa": "Persian", "fi": "Finnish",
+	"fr": "French", "ga": "Irish", "gl": "Galician", "gu": "Gujarati",
+	"he": "Hebrew", "hi": "Hindi", "hr": "Croatian", "hu": "Hungarian",
+	"hy": "Armenian", "id": "Indonesian", "is": "Icelandic", "it": "Italian",
+	"ja": "Japanese", "ka": "Georgian", "kk": "Kazakh", "km": "Khmer",
+	"kn": "Kannada", "ko": "Korean", "lo": "Lao", "lt": "Lithuanian",
+	"lv": "Latvian", "mk": "Macedonian", "ml": "Malayalam", "mn": "Mongolian",
+	"mr": "Marathi", "ms": "Malay", "my": "Burmese", "ne": "Nepali",
+	"nl": "Dutch", "no": "Norwegian", "pa": "Punjabi", "pl": "Polish",
+	"pt": "Portuguese", "ro": "Romanian", "ru": "Russian", "si": "Sinhala",
+	"sk": "Slovak", "sl": "Slovenian", "sq": "Albanian", "sr": "Serbian",
+	"sv": "Swedish", "sw": "Swahili", "ta": "Tamil", "te": "Telugu",
+	"th": "Thai", "tl": "Tagalog", "tr": "Turkish", "uk": "Ukrainian",
+	"ur": "Urdu", "uz": "Uzbek", "vi": "Vietnamese", "zh": "Uni",
+	"zh-TW": "Traditional Uni",
+}
+
+// supportedLanguagesTTL 在线查询的语言列表缓存时长
+const supportedLanguagesTTL = 10 * time.Minute
+
+// languageListCache 在线查询的语言列表缓存：能力接口地址 -> 语言列表
+var languageListCache = struct {
+	sync.Mutex
+	entries map[string]languageListEntry
+}{entries: make(map[string]languageListEntry)}
+
+type languageListEntry struct {
+	languages []string
+	fetchedAt time.Time
+}
+
+// SupportedLanguages 返回提供商支持的语言代码
+// 大模型提供商默认返回常用语言的静态列表，只用于界面展示，不限制可提交的目标语言（见 ValidateLanguagePair）
+func (b *BaseProvider) SupportedLanguages() ([]string, error) {
+	codes := make([]string, 0, len(commonLanguages))
+	for code := range commonLanguages {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes, nil
+}
+
+// SupportedLanguages 查询 NLTranslator 已安装的语言包
+func (p *NLTranslateProvider) SupportedLanguages() ([]string, error) {
+	return p.fetchLanguages(languagesEndpoint(p.Config.APIURL))
+}
+
+// SupportedLanguages 查询 LibreTranslate 的 /languages 接口
+func (p *LibreTranslateProvider) SupportedLanguages() ([]string, error) {
+	endpoint := languagesEndpoint(p.Config.APIURL)
+	if p.Config.APIKey != "" {
+		endpoint += "?api_key=" + url.QueryEscape(p.Config.APIKey)
+	}
+	return p.fetchLanguages(endpoint)
+}
+
+// languagesEndpoint 将翻译接口地址（如 .../translate）转换为同级的 languages 接口地址
+func languagesEndpoint(apiURL string) string {
+	parsed, err := url.Parse(apiURL)
+	if err != nil {
+		return strings.TrimSuffix(apiURL, "/") + "/languages"
+	}
+	parsed.Path = path.Join(path.Dir(strings.TrimSuffix(parsed.Path, "/")), "languages")
+	parsed.RawQuery = ""
+	return parsed.String()
+}
+
+// fetchLanguages 查询语言列表接口，结果缓存一段时间
+// 兼容 ["en", ...]、[{"code": "en"}, ...] 和 {"languages": [...]} 三种格式
+func (b *BaseProvider) fetchLanguages(endpoint string) ([]string, error) {
+	languageListCache.Lock()
+	if entry, ok := languageListCache.entries[endpoint]; ok && time.Since(entry.fetchedAt) < supportedLanguagesTTL {
+		languageListCache.Unlock()
+		return entry.languages, nil
+	}
+	languageListCache.Unlock()
+
+	req, err := http.NewRequest("GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	body, err := b.doRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("查询支持的语言失败: %w", err)
+	}
+
+	var raw json.RawMessage = body
+	var wrapped struct {
+		Languages json.RawMessage `json:"languages"`
+	}
+	if json.Unmarshal(body, &wrapped) == nil && wrapped.Languages != nil {
+		raw = wrapped.Languages
+	}
+
+	var languages []string
+	if err := json.Unmarshal(raw, &languages); err != nil {
+		var objects []struct {
+			Code string `json:"code"`
+		}
+		if err := json.Unmarshal(raw, &objects); err != nil {
+			return nil, fmt.Errorf("解析语言列表失败: %w", err)
+		}
+		languages = languages[:0]
+		for _, obj := range objects {
+			languages = append(languages, obj.Code)
+		}
+	}
+
+	languageListCache.Lock()
+	languageListCache.entries[endpoint] = languageListEntry{languages: languages, fetchedAt: time.Now()}
+	languageListCache.Unlock()
+	return languages, nil
+}
+
+// providerLanguageCode 将语言名称转换为提供商使用的语言代码
+func providerLanguageCode(p Provider, language string) string {
+	switch p.GetConfig().Type {
+	case ProviderNLTranslate:
+		return mapToNLLanguageCode(language)
+	case ProviderTencent:
+		return mapToTencentLanguageCode(language)
+	case ProviderYandex:
+		return mapToYandexLanguageCode(language)
+	default:
+		return mapToLibreTranslateLanguageCode(language)
+	}
+}
+
+// ValidateLanguagePair 在创建任务前校验提供商是否支持目标语言和源语言（源语言为空或 auto 时不校验）
+// 大模型提供商接受任意语言名称，不校验；无法查询语言列表时只记录日志，不阻止任务
+func ValidateLanguagePair(p Provider, targetLanguage, sourceLanguage string) error {
+	if acceptsAnyLanguage(p.GetConfig().Type) {
+		return nil
+	}
+	supported, err := p.SupportedLanguages()
+	if err != nil {
+		log.Printf("警告：无法获取 %s 支持的语言，跳过语言校验: %v", p.GetName(), err)
+		return nil
+	}
+	if len(supported) == 0 {
+		return nil
+	}
+
+	check := func(kind, language string) error {
+		if isLanguageSupported(supported, providerLanguageCode(p, language), language) {
+			return nil
+		}
+		return fmt.Errorf("%s 不支持%s %q，可选语言: %s", p.GetName(), kind, language, strings.Join(supported, ", "))
+	}
+
+	if err := check("目标语言", targetLanguage); err != nil {
+		return err
+	}
+	if sourceLanguage != "" && sourceLanguage != "auto" {
+		return check("源语言", sourceLanguage)
+	}
+	return nil
+}
+
+// acceptsAnyLanguage 提供商是否按提示词翻译，目标语言可以是任意名称（如 "Cantonese"、"Traditional Chinese (Hong Kong)"）
+func acceptsAnyLanguage(providerType ProviderType) bool {
+	switch providerType {
+	case ProviderOpenAI, ProviderDeepSeek, ProviderAzureOpenAI, ProviderClaude, ProviderGemini, ProviderOllama, ProviderCustom:
+		return true
+	default:
+		return false
+	}
+}
+
+// isLanguageSupported 判断语言代码或名称是否在支持列表中
+// 区域代码（如 zh-Hans）在列表只有基础代码（zh）时也视为支持
+func isLanguageSupported(supported []string, code, name string) bool {
+	base := strings.SplitN(code, "-", 2)[0]
+	for _, s := range supported {
+		if strings.EqualFold(s, code) || strings.EqualFold(s, base) {
+			return true
+		}
+		if english, ok := commonLanguages[s]; ok && strings.EqualFold(english, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// LanguageOption 可选的目标语言：Code 为提供商使用的语言代码，Name 为可作为 targetLanguage 提交的名称
+type LanguageOption struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+// providerLanguageTable 返回提供商使用的语言名称 -> 语言代码映射表
+func providerLanguageTable(p Provider) map[string]string {
+	if p.GetConfig().Type == ProviderNLTranslate {
+		return nlLanguageCodes
+	}
+	return libreTranslateLanguageCodes
+}
+
+// ProviderLanguages 返回提供商支持的目标语言
+// 以 SupportedLanguages 的结果为准；在线查询失败或返回空列表时，使用映射表中的语言代码
+func ProviderLanguages(p Provider) []LanguageOption {
+	table := providerLanguageTable(p)
+
+	codes, err := p.SupportedLanguages()
+	if err != nil {
+		log.Printf("警告：无法获取 %s 支持的语言，使用内置映射表: %v", p.GetName(), err)
+	}
+	if len(codes) == 0 {
+		for _, code := range table {
+			codes = append(codes, code)
+		}
+	}
+
+	seen := make(map[string]bool, len(codes))
+	options := make([]LanguageOption, 0, len(codes))
+	for _, code := range codes {
+		if code == "" || seen[code] {
+			continue
+		}
+		seen[code] = true
+		options = append(options, LanguageOption{Code: code, Name: languageDisplayName(table, code)})
+	}
+	sort.Slice(options, func(i, j int) bool { return options[i].Code < options[j].Code })
+	return options
+}
+
+// languageDisplayName 返回语言代码对应的英文名称，优先使用常用语言表，其次使用映射表中的英文名称
+// 都没有时返回代码本身（提交代码同样可以翻译）
+func languageDisplayName(table map[string]string, code string) string {
+	if name, ok := commonLanguages[code]; ok {
+		return name
+	}
+	var names []string
+	for name, mapped := range table {
+		if mapped == code && isASCII(name) && name != code {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return code
+	}
+	sort.Strings(names)
+	return names[0]
+}
+
+// isASCII 判断字符串是否只包含 ASCII 字符
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,41 @@
+package translator
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestValidateLanguagePair(t *testing.T) {
+	libre := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"code":"en"},{"code":"zh"}]`))
+	}))
+	defer libre.Close()
+
+	tests := []struct {
+		provider ProviderType
+		target   string
+		source   string
+		wantErr  bool
+	}{
+		{ProviderOpenAI, "Cantonese", "", false},
+		{ProviderOpenAI, "Traditional Chinese (Hong Kong)", "auto", false},
+		{ProviderClaude, "Klingon", "Old English", false},
+		{ProviderCustom, "Pirate English", "", false},
+		{ProviderTencent, "Japanese", "", false},
+		{ProviderTencent, "Klingon", "", true},
+		{ProviderLibreTranslate, "Uni", "en", false},
+		{ProviderLibreTranslate, "French", "", true},
+		{ProviderLibreTranslate, "Uni", "fr", true},
+	}
+	for _, tt := range tests {
+		p, err := NewProvider(ProviderConfig{Type: tt.provider, APIKey: "key", APIURL: libre.URL + "/translate"}, nil)
+		if err != nil {
+			t.Fatalf("NewProvider(%s): %v", tt.provider, err)
+		}
+		err = ValidateLanguagePair(p, tt.target, tt.source)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ValidateLanguagePair(%s, %q, %q) = %v，期望出错: %v", tt.provider, tt.target, tt.source, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package translator
+
+import "regexp"
+
+// listMarkerRegex 匹配行首的列表标记：项目符号、数字编号、字母编号、罗马数字编号
+var listMarkerRegex = regexp.MustCompile(`^(\s*(?:[•◦▪▫‣·●○■□\-–—*]|\(?\d{1,3}[.)]|\(\d{1,3}\)|\(?[a-zA-Z][.)]|\([a-zA-Z]\)|\(?[ivxlcIVXLC]{1,5}[.)])\s+)(\S[\s\S]*)$`)
+
+// letterMarkerRegex 匹配单个字母加句点的编号（如 "a. "），与人名缩写（如 "A. Smith"）形式相同
+var letterMarkerRegex = regexp.MustCompile(`^\s*([a-zA-Z])\.\s+$`)
+
+// letterLineRegex 匹配以单个字母加句点开头的行
+var letterLineRegex = regexp.MustCompile(`(?m)^\s*([a-zA-Z])\.\s+\S`)
+
+// SplitListMarker 拆分行首的列表标记和正文
+// 例如 "3. The quick brown fox" 返回 ("3. ", "The quick brown fox")；没有列表标记时 marker 为空
+// 单个字母加句点的编号只在同一文本块中后续某行以下一个字母编号开头时才拆分（如 "a. …\nb. …"），
+// 避免把 "A. Smith" 这类人名缩写当作列表标记
+func SplitListMarker(text string) (marker, body string) {
+	matches := listMarkerRegex.FindStringSubmatch(text)
+	if matches == nil {
+		return "", text
+	}
+	if letter := letterMarkerRegex.FindStringSubmatch(matches[1]); letter != nil && !hasNextLetterMarker(matches[2], letter[1][0]) {
+		return "", text
+	}
+	return matches[1], matches[2]
+}
+
+// hasNextLetterMarker 文本中是否有以 letter 的下一个字母加句点开头的行
+func hasNextLetterMarker(text string, letter byte) bool {
+	next := string(letter + 1)
+	for _, m := range letterLineRegex.FindAllStringSubmatch(text, -1) {
+		if m[1] == next {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,28 @@
+package translator
+
+import "testing"
+
+func TestSplitListMarker(t *testing.T) {
+	tests := []struct {
+		text   string
+		marker string
+		body   string
+	}{
+		{"3. The quick brown fox", "3. ", "The quick brown fox"},
+		{"• Bullet item", "• ", "Bullet item"},
+		{"(a) First clause", "(a) ", "First clause"},
+		{"b) Second clause", "b) ", "Second clause"},
+		{"iv. Fourth item", "iv. ", "Fourth item"},
+		{"a. First item\nb. Second item", "a. ", "First item\nb. Second item"},
+		{"A. Smith proposed the method.", "", "A. Smith proposed the method."},
+		{"J. Doe and A. Smith", "", "J. Doe and A. Smith"},
+		{"A. Smith\nC. Jones", "", "A. Smith\nC. Jones"},
+		{"Plain paragraph", "", "Plain paragraph"},
+	}
+	for _, tt := range tests {
+		marker, body := SplitListMarker(tt.text)
+		if marker != tt.marker || body != tt.body {
+			t.Errorf("SplitListMarker(%q) = (%q, %q)，期望 (%q, %q)", tt.text, marker, body, tt.marker, tt.body)
+		}
+	}
+}
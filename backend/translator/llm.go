@@ -19,7 +19,7 @@ type LLMClient struct {
 	RetryTimes    int
 	RetryInterval time.Duration
 	HTTPClient    *http.Client
-	Cache         *Cache
+	Cache         CacheStore
 }
 
 // NewLLMClient 创建 LLM 客户端
@@ -39,7 +39,7 @@ func NewLLMClient(apiKey, apiURL, model string, temperature float64, maxTokens i
 }
 
 // WithCache 设置缓存
-func (c *LLMClient) WithCache(cache *Cache) *LLMClient {
+func (c *LLMClient) WithCache(cache CacheStore) *LLMClient {
 	c.Cache = cache
 	return c
 }
@@ -77,7 +77,7 @@ type openAIResponse struct {
 func (c *LLMClient) Translate(text, targetLanguage, userPrompt string) (string, error) {
 	// 检查缓存
 	if c.Cache != nil {
-		cacheKey := CacheKey(text, targetLanguage, userPrompt)
+		cacheKey := CacheKeyWithOptions(text, targetLanguage, TranslateOptions{UserPrompt: userPrompt})
 		if cached, ok := c.Cache.Get(cacheKey); ok {
 			return cached, nil
 		}
@@ -94,7 +94,7 @@ func (c *LLMClient) Translate(text, targetLanguage, userPrompt string) (string,
 		if err == nil {
 			// 保存到缓存
 			if c.Cache != nil {
-				cacheKey := CacheKey(text, targetLanguage, userPrompt)
+				cacheKey := CacheKeyWithOptions(text, targetLanguage, TranslateOptions{UserPrompt: userPrompt})
 				c.Cache.Set(cacheKey, result)
 			}
 			return result, nil
@@ -152,7 +152,7 @@ func (c *LLMClient) translateOnce(text, targetLanguage, userPrompt string) (stri
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("API 返回错误 (状态码 %d): %s", resp.StatusCode, string(body))
+		return "", &ProviderHTTPError{StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	var apiResp openAIResponse
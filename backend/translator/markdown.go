@@ -0,0 +1,153 @@
+package translator
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Markdown 标记的正则表达式
+var (
+	// 围栏代码块，整体不翻译
+	markdownFencePattern = regexp.MustCompile("(?ms)^[ \t]*(```|~~~)[^\n]*\n.*?^[ \t]*(```|~~~)[ \t]*$")
+	// 行首的标题、引用、列表标记
+	markdownLinePrefixPattern = regexp.MustCompile(`(?m)^[ \t]*(?:(?:#{1,6}|>|[-*+]|\d+[.)])[ \t]+)+`)
+	// 行内代码和自动链接，整体不翻译
+	markdownCodePattern = regexp.MustCompile("`+[^`\n]+?`+|<(?:https?://|mailto:)[^>\\s]+>")
+	// 链接之外的裸 URL（不含末尾的标点），整体不翻译
+	markdownURLPattern = regexp.MustCompile(`https?://[^\s)<>]*[^\s)<>.,;:!?'"]`)
+	// 链接和图片：[文字](地址 "标题")、![说明](地址)、[文字][引用]，只翻译方括号中的文字
+	markdownLinkPattern = regexp.MustCompile(`!?\[([^\]\n]+)\](\([^)\n]*\)|\[[^\]\n]*\])`)
+	// 加粗、删除线和斜体，只翻译其中的文字
+	markdownStrongPattern = regexp.MustCompile(`(\*\*|__|~~)([^\s*_~](?:[^\n]*?[^\s])?)(\*\*|__|~~)`)
+	markdownEmPattern     = regexp.MustCompile(`([*_])([^\s*_](?:[^\n*_]*?[^\s*_])?)([*_])`)
+)
+
+// markdownTokenKind Markdown 标记在译文中的位置要求
+type markdownTokenKind int
+
+const (
+	markdownAtom  markdownTokenKind = iota // 整体保留的内容（代码、链接地址、行首标记）
+	markdownOpen                           // 起始标记（如 **、[），之后不能有空白
+	markdownClose                          // 结束标记（如 **、](url)），之前不能有空白
+)
+
+// markdownSpan 一个需要保护的 Markdown 标记
+type markdownSpan struct {
+	start, end int
+	kind       markdownTokenKind
+}
+
+// ProtectedMarkdown 保护了 Markdown 标记的文本，只有可见文字发送给翻译服务
+type ProtectedMarkdown struct {
+	Masked string   // 标记替换为 {v0}、{v1} 等占位符后的文本
+	tokens []string // 占位符对应的原始标记
+	kinds  []markdownTokenKind
+}
+
+// ProtectMarkdown 解析 Markdown 的行内标记并替换为占位符（使用 ProtectTokens）：
+// 加粗、斜体、删除线的标记符号，链接和图片的方括号及地址，行内代码、围栏代码块、URL 和行首的标题、列表、引用标记
+// 文本中已有占位符时不做处理，避免编号冲突
+func ProtectMarkdown(text string) *ProtectedMarkdown {
+	if tokenPlaceholderPattern.MatchString(text) {
+		return &ProtectedMarkdown{Masked: text}
+	}
+
+	var spans []markdownSpan
+	occupied := func(start, end int) bool {
+		for _, s := range spans {
+			if start < s.end && s.start < end {
+				return true
+			}
+		}
+		return false
+	}
+	addAtoms := func(pattern *regexp.Regexp) {
+		for _, m := range pattern.FindAllStringIndex(text, -1) {
+			if m[1] > m[0] && !occupied(m[0], m[1]) {
+				spans = append(spans, markdownSpan{m[0], m[1], markdownAtom})
+			}
+		}
+	}
+	// 成对的标记：group 为可见文字所在的分组，之前为起始标记，之后为结束标记；任一部分与已保护的内容重叠时整体跳过
+	addPairs := func(pattern *regexp.Regexp, group int, valid func(m []int) bool) {
+		for _, m := range pattern.FindAllStringSubmatchIndex(text, -1) {
+			open := markdownSpan{m[0], m[2*group], markdownOpen}
+			close := markdownSpan{m[2*group+1], m[1], markdownClose}
+			if !valid(m) || occupied(open.start, open.end) || occupied(close.start, close.end) {
+				continue
+			}
+			spans = append(spans, open, close)
+		}
+	}
+
+	addAtoms(markdownFencePattern)
+	addAtoms(markdownCodePattern)
+	addAtoms(markdownLinePrefixPattern)
+	addPairs(markdownLinkPattern, 1, func([]int) bool { return true })
+	addAtoms(markdownURLPattern)
+	addPairs(markdownStrongPattern, 2, func(m []int) bool {
+		return text[m[2]:m[3]] == text[m[6]:m[7]] && emphasisBoundary(text, m)
+	})
+	addPairs(markdownEmPattern, 2, func(m []int) bool {
+		return text[m[2]:m[3]] == text[m[6]:m[7]] && emphasisBoundary(text, m)
+	})
+
+	if len(spans) == 0 {
+		return &ProtectedMarkdown{Masked: text}
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+
+	ranges := make([][2]int, len(spans))
+	kinds := make([]markdownTokenKind, len(spans))
+	for i, s := range spans {
+		ranges[i] = [2]int{s.start, s.end}
+		kinds[i] = s.kind
+	}
+	masked, tokens := ProtectTokens(text, ranges)
+	return &ProtectedMarkdown{Masked: masked, tokens: tokens, kinds: kinds}
+}
+
+// emphasisBoundary 下划线强调只在单词边界生效（snake_case 中的下划线不是强调），星号和波浪线不受限制
+func emphasisBoundary(text string, m []int) bool {
+	if !strings.HasPrefix(text[m[2]:m[3]], "_") {
+		return true
+	}
+	before, _ := utf8.DecodeLastRuneInString(text[:m[0]])
+	after, _ := utf8.DecodeRuneInString(text[m[1]:])
+	isWord := func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }
+	return !isWord(before) && !isWord(after)
+}
+
+// Restore 将译文中的占位符还原为 Markdown 标记，译文丢失占位符时返回错误
+// 翻译服务常在标记内侧加空格（如 "** 粗体 **"），这会使标记失效，还原前先去掉这些空白
+func (p *ProtectedMarkdown) Restore(translated string) (string, error) {
+	if len(p.tokens) == 0 {
+		return translated, nil
+	}
+
+	for i, kind := range p.kinds {
+		placeholder := fmt.Sprintf("{v%d}", i)
+		switch kind {
+		case markdownOpen:
+			translated = regexp.MustCompile(regexp.QuoteMeta(placeholder)+`[ \t]+`).ReplaceAllLiteralString(translated, placeholder)
+		case markdownClose:
+			translated = regexp.MustCompile(`[ \t]+`+regexp.QuoteMeta(placeholder)).ReplaceAllLiteralString(translated, placeholder)
+		}
+	}
+	return RestoreTokens(translated, p.tokens)
+}
+
+// TranslateMarkdown 翻译 Markdown 文本，只发送可见文字，标记符号、链接地址和代码保持原样
+// 译文丢失占位符时返回错误
+func TranslateMarkdown(provider Provider, text, targetLanguage, userPrompt string) (string, error) {
+	protected := ProtectMarkdown(text)
+	translated, err := provider.Translate(protected.Masked, targetLanguage, userPrompt)
+	if err != nil {
+		return "", err
+	}
+	return protected.Restore(translated)
+}
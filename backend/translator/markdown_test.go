@@ -0,0 +1,56 @@
+package translator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestProtectMarkdown(t *testing.T) {
+	tests := []struct {
+		text   string
+		masked string
+	}{
+		{"See [the docs](http://x) now", "See {v0}the docs{v1} now"},
+		{"A **bold** claim", "A {v0}bold{v1} claim"},
+		{"Run `go test` on snake_case_name", "Run {v0} on snake_case_name"},
+		{"## Title", "{v0}Title"},
+		{"Plain prose.", "Plain prose."},
+	}
+	for _, tt := range tests {
+		if got := ProtectMarkdown(tt.text).Masked; got != tt.masked {
+			t.Errorf("ProtectMarkdown(%q).Masked = %q，期望 %q", tt.text, got, tt.masked)
+		}
+	}
+}
+
+func TestTranslateMarkdownKeepsMarkup(t *testing.T) {
+	tests := []struct {
+		text, reply, want string
+	}{
+		// 链接地址不发送给翻译服务，译文保留原地址
+		{"Read [the guide](http://x) first.", "先阅读 {v0} 指南 {v1}。", "先阅读 [指南](http://x)。"},
+		// 标记内侧多出的空格被去掉，加粗仍然有效
+		{"This is **bold** text.", "这是 {v0} 粗体 {v1} 文本。", "这是 **粗体** 文本。"},
+	}
+	for _, tt := range tests {
+		masked := ProtectMarkdown(tt.text).Masked
+		provider := &replyProvider{replies: map[string]string{masked: tt.reply}}
+		got, err := TranslateMarkdown(provider, tt.text, "Uni", "")
+		if err != nil {
+			t.Errorf("TranslateMarkdown(%q): %v", tt.text, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("TranslateMarkdown(%q) = %q，期望 %q", tt.text, got, tt.want)
+		}
+		if strings.Contains(masked, "http://x") || strings.Contains(masked, "**") {
+			t.Errorf("发送给翻译服务的文本含有标记: %q", masked)
+		}
+	}
+
+	// 译文丢失占位符时返回错误
+	provider := &replyProvider{replies: map[string]string{"{v0}bold{v1}": "粗体"}}
+	if _, err := TranslateMarkdown(provider, "**bold**", "Uni", ""); err == nil {
+		t.Error("译文丢失占位符时应返回错误")
+	}
+}
@@ -6,7 +6,7 @@ import (
 )
 
 // TranslateMetadata 翻译 EPUB 元数据
-func TranslateMetadata(epub *EPUBFile, client interface{}, targetLanguage, userPrompt string, cache *Cache) error {
+func TranslateMetadata(epub *EPUBFile, client interface{}, targetLanguage, userPrompt string, cache CacheStore) error {
 	// 查找 OPF 文件
 	var opfPath string
 	var opfContent []byte
@@ -0,0 +1,68 @@
+package translator
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// 翻译相关的 Prometheus 指标，注册在默认的注册表中（与 Go 运行时指标一起通过 /metrics 暴露）
+var (
+	translationsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "translations_total",
+		Help: "Text translations by provider and status (requested, succeeded, failed).",
+	}, []string{"provider", "status"})
+
+	cacheHitsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "translation_cache_hits_total",
+		Help: "Translation cache lookups that found a cached translation.",
+	})
+	cacheMissesTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "translation_cache_misses_total",
+		Help: "Translation cache lookups that found no cached translation.",
+	})
+
+	providerRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "provider_request_duration_seconds",
+		Help:    "HTTP requests to translation providers by provider and status code (error when no response).",
+		Buckets: []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"provider", "code"})
+
+	tokensTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "provider_tokens_total",
+		Help: "Tokens reported by translation providers, by provider and direction (input, output).",
+	}, []string{"provider", "direction"})
+)
+
+// RecordTranslation 记录一次文本翻译请求及其结果；任务取消或超时导致的中止不计为失败
+func RecordTranslation(provider ProviderType, err error) {
+	translationsTotal.WithLabelValues(string(provider), "requested").Inc()
+	switch {
+	case err == nil:
+		translationsTotal.WithLabelValues(string(provider), "succeeded").Inc()
+	case !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded):
+		translationsTotal.WithLabelValues(string(provider), "failed").Inc()
+	}
+}
+
+// recordCacheLookup 记录一次缓存查询是否命中
+func recordCacheLookup(hit bool) {
+	if hit {
+		cacheHitsTotal.Inc()
+	} else {
+		cacheMissesTotal.Inc()
+	}
+}
+
+// recordProviderRequest 记录一次提供商 HTTP 请求的耗时，statusCode 为 0 表示没有收到响应
+func recordProviderRequest(provider ProviderType, statusCode int, start time.Time) {
+	code := "error"
+	if statusCode > 0 {
+		code = strconv.Itoa(statusCode)
+	}
+	providerRequestDuration.WithLabelValues(string(provider), code).Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,61 @@
+package translator
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// counterValue 返回计数器的当前值
+func counterValue(t *testing.T, counter prometheus.Counter) float64 {
+	t.Helper()
+	var m dto.Metric
+	if err := counter.Write(&m); err != nil {
+		t.Fatal(err)
+	}
+	return m.GetCounter().GetValue()
+}
+
+func TestTranslationMetrics(t *testing.T) {
+	stub := newOpenAIStub(t, nil)
+	client, err := NewTranslatorClient(stub.Config(), NewMemoryCache())
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider := string(stub.Config().Type)
+	requested := translationsTotal.WithLabelValues(provider, "requested")
+	succeeded := translationsTotal.WithLabelValues(provider, "succeeded")
+	inputTokens := tokensTotal.WithLabelValues(provider, "input")
+	before := []float64{
+		counterValue(t, requested), counterValue(t, succeeded), counterValue(t, inputTokens),
+		counterValue(t, cacheHitsTotal), counterValue(t, cacheMissesTotal),
+	}
+
+	if _, err := client.Translate("Metrics are counted.", "French", ""); err != nil {
+		t.Fatal(err)
+	}
+	if got := counterValue(t, requested) - before[0]; got != 1 {
+		t.Errorf("translations_total{status=requested} 增加 %v，期望 1", got)
+	}
+	if got := counterValue(t, succeeded) - before[1]; got != 1 {
+		t.Errorf("translations_total{status=succeeded} 增加 %v，期望 1", got)
+	}
+	if got := counterValue(t, inputTokens) - before[2]; got != 10 {
+		t.Errorf("provider_tokens_total{direction=input} 增加 %v，期望 10", got)
+	}
+	if hits, misses := counterValue(t, cacheHitsTotal)-before[3], counterValue(t, cacheMissesTotal)-before[4]; hits != 0 || misses != 1 {
+		t.Errorf("首次翻译缓存命中/未命中 = %v/%v，期望 0/1", hits, misses)
+	}
+
+	// 相同的第二次请求命中缓存，不再请求翻译服务
+	if _, err := client.Translate("Metrics are counted.", "French", ""); err != nil {
+		t.Fatal(err)
+	}
+	if hits, misses := counterValue(t, cacheHitsTotal)-before[3], counterValue(t, cacheMissesTotal)-before[4]; hits != 1 || misses != 1 {
+		t.Errorf("第二次翻译后缓存命中/未命中 = %v/%v，期望 1/1", hits, misses)
+	}
+	if n := len(stub.Requests()); n != 1 {
+		t.Errorf("翻译服务收到 %d 次请求，期望 1", n)
+	}
+}
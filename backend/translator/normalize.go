@@ -0,0 +1,61 @@
+package translator
+
+import (
+	"strings"
+	"unicode"
+)
+
+// ligatureReplacer 将排版连字拆为普通字母（PDF 字体常用连字，EPUB 中通常是普通字母）
+var ligatureReplacer = strings.NewReplacer(
+	"ﬀ", "ff",
+	"ﬁ", "fi",
+	"ﬂ", "fl",
+	"ﬃ", "ffi",
+	"ﬄ", "ffl",
+	"ﬅ", "st",
+	"ﬆ", "st",
+)
+
+// isZeroWidth 判断是否为需要删除的零宽字符和软连字符
+// 零宽连接符（U+200D）和零宽非连接符（U+200C）影响波斯语、印地语和表情符号的显示，予以保留
+func isZeroWidth(r rune) bool {
+	switch r {
+	case '\u200b', '\u2060', '\ufeff', '\u00ad':
+		return true
+	}
+	return false
+}
+
+// NormalizeText 统一规范化提取出的文本，所有格式的文本在翻译和计算缓存键前都经过此函数
+// 拆分连字，删除零宽字符，将不换行空格等各类空白视为普通空格，
+// 连续的空白合并为一个空格（含换行时合并为一个换行），并去掉首尾空白
+func NormalizeText(s string) string {
+	s = ligatureReplacer.Replace(s)
+
+	var b strings.Builder
+	b.Grow(len(s))
+	pendingSpace, pendingBreak := false, false
+	for _, r := range s {
+		switch {
+		case isZeroWidth(r):
+			continue
+		case r == '\n' || r == '\r' || r == '\u2028' || r == '\u2029':
+			pendingBreak = true
+			continue
+		case unicode.IsSpace(r) || unicode.Is(unicode.Zs, r):
+			pendingSpace = true
+			continue
+		}
+
+		if b.Len() > 0 {
+			if pendingBreak {
+				b.WriteByte('\n')
+			} else if pendingSpace {
+				b.WriteByte(' ')
+			}
+		}
+		pendingSpace, pendingBreak = false, false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,38 @@
+package translator
+
+import "testing"
+
+func TestNormalizeText(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"The results are significant.\u00a0", "The results are significant."},
+		{"  The   results\tare significant. ", "The results are significant."},
+		{"The \ufb01rst e\ufb00ect", "The first effect"},
+		{"zero\u200bwidth and soft\u00adhyphen", "zerowidth and softhyphen"},
+		{"first line  \n\n  second line", "first line\nsecond line"},
+		{"emoji \u200d joiner", "emoji \u200d joiner"},
+		{"中文\u3000文本", "中文 文本"},
+	}
+	for _, tt := range tests {
+		if got := NormalizeText(tt.text); got != tt.want {
+			t.Errorf("NormalizeText(%q) = %q，期望 %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestCacheKeyIgnoresWhitespaceVariants(t *testing.T) {
+	// 同一句子从 PDF 提取时带不换行空格，从 EPUB 提取时是普通空格
+	pdfText := "The results\u00a0are significant.\u00a0"
+	epubText := "The results are significant. "
+	if NormalizeText(pdfText) != NormalizeText(epubText) {
+		t.Fatalf("规范化结果不同: %q vs %q", NormalizeText(pdfText), NormalizeText(epubText))
+	}
+	if CacheKey(pdfText, "French", "") != CacheKey(epubText, "French", "") {
+		t.Error("同一句子的缓存键不同")
+	}
+	if CacheKey(epubText, "French", "") == CacheKey("The results are insignificant.", "French", "") {
+		t.Error("不同句子的缓存键相同")
+	}
+}
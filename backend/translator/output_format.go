@@ -0,0 +1,134 @@
+package translator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// OutputFormat 输出格式
+type OutputFormat string
+
+const (
+	OutputFormatPDF           OutputFormat = "pdf"            // 单语PDF
+	OutputFormatBilingualPDF  OutputFormat = "bilingual-pdf"  // 双语PDF
+	OutputFormatText          OutputFormat = "text"           // 单语文本
+	OutputFormatBilingualText OutputFormat = "bilingual-text" // 双语对照文本
+	OutputFormatHTML          OutputFormat = "html"           // 单语网页
+	OutputFormatBilingualHTML OutputFormat = "bilingual-html" // 双语对照网页
+	OutputFormatEPUB          OutputFormat = "epub"           // EPUB（单语/双语由生成模式决定）
+	OutputFormatPPTX          OutputFormat = "pptx"           // PPTX（单语/双语由生成模式决定）
+	OutputFormatCSV           OutputFormat = "csv"            // CSV（双语时在被翻译的列之后插入译文列）
+	OutputFormatTSV           OutputFormat = "tsv"            // TSV（同 CSV）
+	OutputFormatAlignment     OutputFormat = "alignment"      // 原文与译文的对齐数据（JSON，含页码和边界框）
+)
+
+// SupportedOutputFormats 返回指定文档类型支持的输出格式
+func SupportedOutputFormats(docType DocumentType) []OutputFormat {
+	switch docType {
+	case DocumentTypePDF:
+		return []OutputFormat{OutputFormatPDF, OutputFormatBilingualPDF, OutputFormatText, OutputFormatBilingualText, OutputFormatHTML, OutputFormatBilingualHTML, OutputFormatAlignment}
+	case DocumentTypeEPUB:
+		return []OutputFormat{OutputFormatEPUB, OutputFormatText, OutputFormatBilingualText, OutputFormatHTML, OutputFormatBilingualHTML}
+	case DocumentTypePPTX:
+		return []OutputFormat{OutputFormatPPTX, OutputFormatText, OutputFormatBilingualText, OutputFormatHTML, OutputFormatBilingualHTML}
+	case DocumentTypeCSV:
+		return []OutputFormat{OutputFormatCSV, OutputFormatText, OutputFormatBilingualText, OutputFormatHTML, OutputFormatBilingualHTML}
+	case DocumentTypeTSV:
+		return []OutputFormat{OutputFormatTSV, OutputFormatText, OutputFormatBilingualText, OutputFormatHTML, OutputFormatBilingualHTML}
+	default:
+		return nil
+	}
+}
+
+// ParseOutputFormats 解析逗号分隔的输出格式列表，去除空白和重复项
+func ParseOutputFormats(value string) []string {
+	var formats []string
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(value, ",") {
+		format := strings.ToLower(strings.TrimSpace(part))
+		if format == "" || seen[format] {
+			continue
+		}
+		seen[format] = true
+		formats = append(formats, format)
+	}
+	return formats
+}
+
+// ValidateOutputFormats 校验输出格式是否被该文档类型支持
+func ValidateOutputFormats(docType DocumentType, formats []string) error {
+	supported := SupportedOutputFormats(docType)
+	for _, format := range formats {
+		ok := false
+		for _, s := range supported {
+			if OutputFormat(format) == s {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			names := make([]string, len(supported))
+			for i, s := range supported {
+				names[i] = string(s)
+			}
+			return fmt.Errorf("%s 文件不支持输出格式 %s，可选: %s", strings.ToUpper(string(docType)), format, strings.Join(names, ", "))
+		}
+	}
+	return nil
+}
+
+// outputFormatSuffix 返回输出格式对应的文件名后缀
+func outputFormatSuffix(format OutputFormat) string {
+	switch format {
+	case OutputFormatPDF:
+		return "-mono.pdf"
+	case OutputFormatBilingualPDF:
+		return "-dual.pdf"
+	case OutputFormatText:
+		return "-mono.txt"
+	case OutputFormatBilingualText:
+		return "-dual.txt"
+	case OutputFormatHTML:
+		return "-mono.html"
+	case OutputFormatBilingualHTML:
+		return "-dual.html"
+	case OutputFormatEPUB:
+		return ".epub"
+	case OutputFormatPPTX:
+		return ".pptx"
+	case OutputFormatCSV:
+		return ".csv"
+	case OutputFormatTSV:
+		return ".tsv"
+	case OutputFormatAlignment:
+		return "-alignment.json"
+	default:
+		return ""
+	}
+}
+
+// saveBlocksText 将文本块及其译文保存为纯文本文件
+func saveBlocksText(outputPath, title string, blocks []string, translations map[string]string, bilingual bool) error {
+	var content strings.Builder
+
+	content.WriteString(title + "\n")
+	content.WriteString(strings.Repeat("=", 50) + "\n\n")
+
+	for _, block := range blocks {
+		if strings.TrimSpace(block) == "" {
+			continue
+		}
+
+		translated, ok := translations[block]
+		if !ok {
+			translated = block
+		}
+
+		if bilingual {
+			content.WriteString(block + "\n")
+		}
+		content.WriteString(translated + "\n\n")
+	}
+
+	return writeTextFile(outputPath, content.String())
+}
@@ -0,0 +1,107 @@
+package translator
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// DocumentSnapshot 压缩包格式文档（EPUB、PPTX）的文件内容快照
+// 编辑器总是整体替换文件内容而不会修改原切片，因此快照只复制映射
+type DocumentSnapshot map[string][]byte
+
+// SnapshotDocument 可创建快照并回滚的文档
+type SnapshotDocument interface {
+	Snapshot() DocumentSnapshot
+	Restore(snapshot DocumentSnapshot)
+}
+
+// snapshotFiles 复制文件映射
+func snapshotFiles(files map[string][]byte) DocumentSnapshot {
+	snapshot := make(DocumentSnapshot, len(files))
+	for name, content := range files {
+		snapshot[name] = content
+	}
+	return snapshot
+}
+
+// rewriteFiles 对指定文件逐个执行 rewrite，全部成功后才写回 files
+// 任意文件失败时 files 保持不变
+func rewriteFiles(files map[string][]byte, names []string, rewrite func(name string, content []byte) ([]byte, error)) error {
+	updated := make(map[string][]byte, len(names))
+	for _, name := range names {
+		content, err := rewrite(name, files[name])
+		if err != nil {
+			return err
+		}
+		updated[name] = content
+	}
+
+	for name, content := range updated {
+		files[name] = content
+	}
+	return nil
+}
+
+// fileOrder 返回写入压缩包的文件顺序：先按原始顺序，再按名称追加新增的文件
+func fileOrder(order []string, files map[string][]byte) []string {
+	names := make([]string, 0, len(files))
+	seen := make(map[string]bool, len(order))
+	for _, name := range order {
+		if _, ok := files[name]; ok && !seen[name] {
+			names = append(names, name)
+			seen[name] = true
+		}
+	}
+
+	var added []string
+	for name := range files {
+		if !seen[name] {
+			added = append(added, name)
+		}
+	}
+	sort.Strings(added)
+	return append(names, added...)
+}
+
+// writeZipAtomic 将文件按 names 顺序写入压缩包
+// 先写入同目录下的临时文件，成功后再重命名覆盖目标，失败时不会留下不完整的输出
+func writeZipAtomic(outputPath string, names []string, files map[string][]byte) (err error) {
+	dir := filepath.Dir(outputPath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(outputPath)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	w := zip.NewWriter(tmp)
+	for _, name := range names {
+		fw, err := w.Create(name)
+		if err != nil {
+			return err
+		}
+		if _, err := fw.Write(files[name]); err != nil {
+			return err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), outputPath)
+}
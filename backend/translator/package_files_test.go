@@ -0,0 +1,113 @@
+package translator
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// copyFiles 复制文件映射，用于比较修改前后的内容
+func copyFiles(files map[string][]byte) map[string][]byte {
+	copied := make(map[string][]byte, len(files))
+	for name, content := range files {
+		copied[name] = bytes.Clone(content)
+	}
+	return copied
+}
+
+// assertFilesEqual 检查文件内容与期望一致
+func assertFilesEqual(t *testing.T, got, want map[string][]byte) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("文件数 = %d，期望 %d", len(got), len(want))
+	}
+	for name, content := range want {
+		if !bytes.Equal(got[name], content) {
+			t.Errorf("%s 被修改:\n%s", name, got[name])
+		}
+	}
+}
+
+func TestEPUBRewriteErrorLeavesFilesUnchanged(t *testing.T) {
+	doc, err := OpenEPUB(writeTestEPUB(t, t.TempDir(), []string{"First chapter.", "Second chapter."}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	original := copyFiles(doc.Files)
+
+	// 第一章改写成功后，第二章处理时出错
+	calls := 0
+	err = doc.rewriteBodies(func(body string) string {
+		calls++
+		if calls == 2 {
+			panic("forced failure")
+		}
+		return strings.ReplaceAll(body, "chapter", "chapitre")
+	})
+	if err == nil {
+		t.Fatal("改写中途出错时应返回错误")
+	}
+	assertFilesEqual(t, doc.Files, original)
+}
+
+func TestEPUBSnapshotRestore(t *testing.T) {
+	doc, err := OpenEPUB(writeTestEPUB(t, t.TempDir(), []string{"First chapter."}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	original := copyFiles(doc.Files)
+
+	snapshot := doc.Snapshot()
+	if err := doc.InsertMonolingualTranslation(map[string]string{"First chapter.": "Premier chapitre."}); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(doc.Files["OEBPS/ch1.xhtml"], []byte("Premier chapitre.")) {
+		t.Fatalf("未插入译文:\n%s", doc.Files["OEBPS/ch1.xhtml"])
+	}
+
+	doc.Restore(snapshot)
+	assertFilesEqual(t, doc.Files, original)
+}
+
+func TestSaveFailureLeavesNoPartialOutput(t *testing.T) {
+	dir := t.TempDir()
+	doc, err := OpenEPUB(writeTestEPUB(t, dir, []string{"First chapter."}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 目标是非空目录，重命名覆盖失败
+	outputPath := filepath.Join(dir, "out.epub")
+	if err := os.MkdirAll(filepath.Join(outputPath, "keep"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.Save(outputPath); err == nil {
+		t.Fatal("无法覆盖目标时应返回错误")
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), ".tmp") {
+			t.Errorf("保存失败后残留临时文件 %s", entry.Name())
+		}
+	}
+	if _, err := os.Stat(filepath.Join(outputPath, "keep")); err != nil {
+		t.Errorf("保存失败后目标被修改: %v", err)
+	}
+
+	// 正常保存后可以重新打开
+	outputPath = filepath.Join(dir, "saved.epub")
+	if err := doc.Save(outputPath); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := OpenEPUB(outputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertFilesEqual(t, saved.Files, doc.Files)
+}
@@ -0,0 +1,149 @@
+package translator
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+)
+
+// ErrNoPagesReady 尚无已完整翻译的页面
+var ErrNoPagesReady = errors.New("尚无已翻译完成的页面")
+
+// PageProgress 跟踪PDF逐页翻译进度，用于在任务完成前下载已翻译的页面
+// 一页中所有需要翻译的文本块都已返回结果（含失败回退）时，该页视为完成
+type PageProgress struct {
+	mu           sync.Mutex
+	totalPages   int
+	textPages    map[string][]int // 文本块 -> 所在页码（从1开始）
+	remaining    map[int]int      // 页码 -> 尚未翻译的文本块数
+	translations map[string]string
+	completed    int // 从第1页起连续翻译完成的最大页码
+
+	// OnChange 已完成页数增加时回调
+	OnChange func(completedPages, totalPages int)
+}
+
+// NewPageProgress 创建逐页翻译进度跟踪器
+func NewPageProgress() *PageProgress {
+	return &PageProgress{
+		textPages:    make(map[string][]int),
+		remaining:    make(map[int]int),
+		translations: make(map[string]string),
+	}
+}
+
+// Start 根据解析结果登记每页需要翻译的文本块
+// texts 为实际提交翻译的文本，未通过过滤规则的文本块不计入页面进度
+func (pp *PageProgress) Start(content *PDFContent, texts []string, filter *BlockFilter) {
+	pending := make(map[string]bool, len(texts))
+	for _, text := range texts {
+		if filter.ShouldTranslate(text) {
+			pending[text] = true
+		}
+	}
+
+	pp.mu.Lock()
+	pp.totalPages = content.PageCount
+	for _, block := range content.TextBlocks {
+		if !pending[block.Text] {
+			continue
+		}
+		pages := pp.textPages[block.Text]
+		if len(pages) > 0 && pages[len(pages)-1] == block.PageNum {
+			continue
+		}
+		pp.textPages[block.Text] = append(pages, block.PageNum)
+		pp.remaining[block.PageNum]++
+	}
+	changed := pp.advance()
+	pp.mu.Unlock()
+
+	pp.notify(changed)
+}
+
+// MarkTranslated 记录一个文本块的翻译结果，重复记录同一文本块不会重复计数
+func (pp *PageProgress) MarkTranslated(original, translated string) {
+	pp.mu.Lock()
+	if _, done := pp.translations[original]; done {
+		pp.mu.Unlock()
+		return
+	}
+	pp.translations[original] = translated
+	for _, page := range pp.textPages[original] {
+		pp.remaining[page]--
+	}
+	changed := pp.advance()
+	pp.mu.Unlock()
+
+	pp.notify(changed)
+}
+
+// CompletedPages 返回从第1页起连续翻译完成的页数和总页数
+func (pp *PageProgress) CompletedPages() (int, int) {
+	pp.mu.Lock()
+	defer pp.mu.Unlock()
+	return pp.completed, pp.totalPages
+}
+
+// SavePartialPDF 将前 upToPage 页（不超过已完成页数）生成单语译文PDF，返回实际包含的页数
+func (pp *PageProgress) SavePartialPDF(inputPath, outputPath string, upToPage int) (int, error) {
+	pp.mu.Lock()
+	pages := pp.completed
+	translationMap := make(map[string]string, len(pp.translations))
+	for original, translated := range pp.translations {
+		if key := strings.TrimSpace(original); key != "" {
+			translationMap[key] = strings.TrimSpace(translated)
+		}
+	}
+	pp.mu.Unlock()
+
+	if pages == 0 {
+		return 0, ErrNoPagesReady
+	}
+	if upToPage > 0 && upToPage < pages {
+		pages = upToPage
+	}
+
+	trimmedPath := strings.TrimSuffix(outputPath, filepath.Ext(outputPath)) + "-source.pdf"
+	defer os.Remove(trimmedPath)
+	if err := TrimPDFPages(inputPath, trimmedPath, pages); err != nil {
+		return 0, err
+	}
+
+	replacer := NewPDFStylePreservingReplacer()
+	if err := replacer.ReplaceWithStylePreservation(trimmedPath, outputPath, translationMap, GetDefaultStylePreservingConfig()); err != nil {
+		return 0, fmt.Errorf("生成部分译文PDF失败: %w", err)
+	}
+	return pages, nil
+}
+
+// TrimPDFPages 截取PDF的前 pages 页保存为新文件
+func TrimPDFPages(inputPath, outputPath string, pages int) error {
+	if err := api.TrimFile(inputPath, outputPath, []string{fmt.Sprintf("1-%d", pages)}, nil); err != nil {
+		return fmt.Errorf("截取PDF页面失败: %w", err)
+	}
+	return nil
+}
+
+// advance 推进连续完成的页码，返回是否有变化（调用方需持有锁）
+func (pp *PageProgress) advance() bool {
+	before := pp.completed
+	for pp.completed < pp.totalPages && pp.remaining[pp.completed+1] <= 0 {
+		pp.completed++
+	}
+	return pp.completed != before
+}
+
+// notify 在锁外触发进度回调
+func (pp *PageProgress) notify(changed bool) {
+	if !changed || pp.OnChange == nil {
+		return
+	}
+	completed, total := pp.CompletedPages()
+	pp.OnChange(completed, total)
+}
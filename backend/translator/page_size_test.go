@@ -0,0 +1,66 @@
+package translator
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+
+	"github.com/jung-kurt/gofpdf"
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+)
+
+func TestPageFormat(t *testing.T) {
+	tests := []struct {
+		box         BoundingBox
+		orientation string
+		size        gofpdf.SizeType
+	}{
+		{BoundingBox{Width: 612, Height: 792}, "P", gofpdf.SizeType{Wd: 612, Ht: 792}},
+		{BoundingBox{Width: 792, Height: 612}, "L", gofpdf.SizeType{Wd: 612, Ht: 792}},
+		{BoundingBox{X: 10, Y: 20, Width: 499, Height: 709}, "P", gofpdf.SizeType{Wd: 499, Ht: 709}},
+		{BoundingBox{}, "P", gofpdf.SizeType{Wd: 595.28, Ht: 841.89}},
+	}
+	for _, tt := range tests {
+		orientation, size := pageFormat(tt.box)
+		if orientation != tt.orientation || size != tt.size {
+			t.Errorf("pageFormat(%+v) = %s %+v，期望 %s %+v", tt.box, orientation, size, tt.orientation, tt.size)
+		}
+	}
+}
+
+func TestGeneratePDFKeepsPageSize(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "letter.pdf")
+	pdf := gofpdf.New("P", "pt", "Letter", "")
+	pdf.SetFont("Helvetica", "", 12)
+	pdf.AddPage()
+	pdf.Text(72, 100, "A Letter sized page.")
+	pdf.AddPageFormat("L", gofpdf.SizeType{Wd: 500, Ht: 800})
+	pdf.Text(72, 100, "A custom landscape page.")
+	if err := pdf.OutputFileAndClose(input); err != nil {
+		t.Fatal(err)
+	}
+
+	output := filepath.Join(dir, "out.pdf")
+	p := newTestFlowProcessor(t, input, output)
+	if err := p.ProcessPDF(); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.GeneratePDF(); err != nil {
+		t.Fatal(err)
+	}
+
+	dims, err := api.PageDimsFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][2]float64{{612, 792}, {800, 500}}
+	if len(dims) != len(want) {
+		t.Fatalf("输出 %d 页，期望 %d 页", len(dims), len(want))
+	}
+	for i, dim := range dims {
+		if math.Abs(dim.Width-want[i][0]) > 0.5 || math.Abs(dim.Height-want[i][1]) > 0.5 {
+			t.Errorf("第%d页尺寸 = %.2fx%.2f，期望 %.0fx%.0f（而不是A4）", i+1, dim.Width, dim.Height, want[i][0], want[i][1])
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package translator
+
+import "fmt"
+
+// SavePartialResult 任务中途失败时，用已完成的译文生成尽力而为的双语文本
+// 按翻译流程重新提取文本块，已翻译的文本块使用译文，失败和未翻译的文本块保留原文
+// 返回已翻译和总共的文本块数（去重后）
+func SavePartialResult(inputPath, outputPath string, translations map[string]string) (int, int, error) {
+	blocks, _, err := ExtractTranslatableBlocks(inputPath)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	unique, _ := UniqueBlocks(blocks)
+	translated := 0
+	for _, block := range unique {
+		if _, ok := translations[block]; ok {
+			translated++
+		}
+	}
+
+	title := fmt.Sprintf("部分翻译结果 / Partial Translation Result\n任务未完成：已翻译 %d/%d 个文本块，其余文本块保留原文", translated, len(unique))
+	if err := saveBlocksText(outputPath, title, blocks, translations, true); err != nil {
+		return 0, 0, err
+	}
+	return translated, len(unique), nil
+}
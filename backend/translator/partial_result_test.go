@@ -0,0 +1,38 @@
+package translator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSavePartialResult(t *testing.T) {
+	dir := t.TempDir()
+	input := writeTestEPUB(t, dir, []string{"The first chapter.", "The second chapter.", "The last chapter."})
+	// 翻译服务在最后一个文本块失败，只有前两个文本块有译文
+	translations := map[string]string{
+		"The first chapter.":  "Le premier chapitre.",
+		"The second chapter.": "Le deuxième chapitre.",
+	}
+
+	output := filepath.Join(dir, "partial.txt")
+	translated, total, err := SavePartialResult(input, output, translations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if translated != 2 || total != 3 {
+		t.Errorf("已翻译 %d/%d，期望 2/3", translated, total)
+	}
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := string(data)
+	for _, want := range []string{"2/3", "Le premier chapitre.", "Le deuxième chapitre.", "The last chapter."} {
+		if !strings.Contains(text, want) {
+			t.Errorf("部分结果缺少 %q:\n%s", want, text)
+		}
+	}
+}
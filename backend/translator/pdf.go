@@ -1,13 +1,18 @@
 package translator
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/ledongthuc/pdf"
 )
@@ -17,6 +22,17 @@ type PDFDocument struct {
 	Path      string
 	PageTexts []string
 	Metadata  PDFMetadata
+
+	Context context.Context // 重新生成PDF时使用的任务上下文，为空时不限制
+
+	VerticalText  bool          // 重新生成PDF时，原文为竖排的文本以竖排输出 CJK 译文
+	MergeStrategy MergeStrategy // 提取和重新生成时合并被过度分割的文本元素所采用的策略
+	MatchMode     MatchMode     // 重新生成时译文与文本元素的匹配模式
+
+	SourceLanguage string // 原文语言，交错双语输出时用于断句，为空时按通用规则
+	TargetLanguage string // 译文语言
+
+	Confidences map[string]float64 // 翻译服务自评的可信度：原文 -> 可信度，未列出的为 1
 }
 
 type PDFMetadata struct {
@@ -26,7 +42,24 @@ type PDFMetadata struct {
 }
 
 // OpenPDF 打开并解析 PDF 文件
+// 读取失败时会尝试修复一次，修复后仍失败则返回原始错误
 func OpenPDF(path string) (*PDFDocument, error) {
+	doc, err := openPDF(path)
+	if err != nil {
+		doc, _, err = repairAndRetry(path, err, openPDF)
+	}
+	return doc, err
+}
+
+// openPDF 打开并解析 PDF 文件（不修复）
+func openPDF(path string) (doc *PDFDocument, err error) {
+	// 解析库遇到损坏的 xref 等结构时会 panic，转换为错误以便修复
+	defer func() {
+		if r := recover(); r != nil {
+			doc, err = nil, fmt.Errorf("无法打开 PDF 文件: %v", r)
+		}
+	}()
+
 	file, reader, err := pdf.Open(path)
 	if err != nil {
 		// 提供更友好的错误信息
@@ -40,7 +73,7 @@ func OpenPDF(path string) (*PDFDocument, error) {
 	pageCount := reader.NumPage()
 	log.Printf("PDF 总页数: %d", pageCount)
 
-	doc := &PDFDocument{
+	doc = &PDFDocument{
 		Path:      path,
 		PageTexts: make([]string, 0, pageCount),
 		Metadata: PDFMetadata{
@@ -83,7 +116,7 @@ func (d *PDFDocument) GetTextBlocks() []string {
 		// 按段落分割页面文本
 		paragraphs := strings.Split(pageText, "\n\n")
 		for _, para := range paragraphs {
-			para = strings.TrimSpace(para)
+			para = strings.TrimSpace(dehyphenate(para))
 			if para != "" && len(para) > 10 { // 过滤太短的段落
 				// 添加页面信息
 				blockText := fmt.Sprintf("[第%d页] %s", i+1, para)
@@ -95,6 +128,44 @@ func (d *PDFDocument) GetTextBlocks() []string {
 	return blocks
 }
 
+// GetOrderedBlocks 按真实阅读顺序获取文本块
+// 使用流处理器提取文本元素，经聚类和分栏检测后逐页排序，多栏页面按列依次输出
+func (d *PDFDocument) GetOrderedBlocks() ([]ClusteredTextBlock, error) {
+	processor, err := NewPDFFlowProcessor(d.Path, "")
+	if err != nil {
+		return nil, fmt.Errorf("创建PDF流处理器失败: %w", err)
+	}
+	defer processor.Cleanup()
+	processor.MergeStrategy = d.MergeStrategy
+
+	if err := processor.parsePDFStructure(); err != nil {
+		return nil, fmt.Errorf("解析PDF结构失败: %w", err)
+	}
+
+	clusterer := NewTextClusterer()
+	columnDetector := NewColumnDetector()
+
+	var ordered []ClusteredTextBlock
+	for i := range processor.flowData.Pages {
+		page := &processor.flowData.Pages[i]
+		blocks := clusterer.ClusterPageBlocks(page)
+
+		// 多栏页面按列重排；跨栏元素无法归入任何一列时保留 Z 字形顺序
+		if layout := columnDetector.DetectColumns(page); layout.IsMultiColumn {
+			if reordered := columnDetector.ReorderBlocksInColumns(layout, blocks); len(reordered) == len(blocks) {
+				blocks = reordered
+			}
+		}
+
+		for _, block := range GetBlocksByReadingOrder(blocks) {
+			block.PageNumber = page.PageNumber
+			ordered = append(ordered, block)
+		}
+	}
+
+	return ordered, nil
+}
+
 // InsertTranslation 插入翻译（实现 Document 接口）
 func (d *PDFDocument) InsertTranslation(translations map[string]string) error {
 	// PDF 不支持直接编辑，我们将生成文本文件
@@ -163,6 +234,28 @@ func (d *PDFDocument) SaveBilingualText(outputPath string, originalBlocks, trans
 	return writeTextFile(outputPath, content.String())
 }
 
+// hyphenBreakPattern 行尾连字符断词：字母 + "-" + 换行 + 小写字母
+var hyphenBreakPattern = regexp.MustCompile(`(\p{L})-[ \t]*\r?\n[ \t]*(\p{Ll})`)
+
+// dehyphenate 合并跨行断开的单词（"trans-\nformer" -> "transformer"）
+// 只处理行尾的连字符，行内的复合词（如 "well-known"）保持不变
+func dehyphenate(text string) string {
+	return hyphenBreakPattern.ReplaceAllString(text, "$1$2")
+}
+
+// isHyphenatedBreak 判断 a 是否以断词连字符结尾且 b 以小写字母开头
+func isHyphenatedBreak(a, b string) bool {
+	a = strings.TrimRight(a, " \t")
+	b = strings.TrimLeft(b, " \t")
+	if !strings.HasSuffix(a, "-") || b == "" {
+		return false
+	}
+
+	before, _ := utf8.DecodeLastRuneInString(strings.TrimSuffix(a, "-"))
+	first, _ := utf8.DecodeRuneInString(b)
+	return unicode.IsLetter(before) && unicode.IsLower(first)
+}
+
 // cleanPDFText 清理 PDF 文本
 func cleanPDFText(text string) string {
 	// 首先尝试修复常见的编码问题
@@ -174,8 +267,7 @@ func cleanPDFText(text string) string {
 
 	for _, line := range lines {
 		// 移除行内多余的空白字符
-		line = regexp.MustCompile(`[ \t]+`).ReplaceAllString(line, " ")
-		line = strings.TrimSpace(line)
+		line = NormalizeText(line)
 
 		if line == "" {
 			continue
@@ -305,16 +397,48 @@ func fixCommonGarbledChars(text string) string {
 	return result
 }
 
+// ErrEncryptedPDF PDF 已加密，需要密码或使用了不支持的加密方式
+var ErrEncryptedPDF = errors.New("PDF 文件已加密，请移除密码保护后重新上传")
+
+// isEncryptionError 判断 PDF 读取错误是否由加密引起
+func isEncryptionError(err error) bool {
+	return err != nil && (errors.Is(err, pdf.ErrInvalidPassword) || strings.Contains(err.Error(), "encryption"))
+}
+
+// CheckPDFEncryption 检查 PDF 是否因加密而无法读取，是则返回 ErrEncryptedPDF
+// 其他解析错误不在此报告，交由之后的验证和修复流程处理
+func CheckPDFEncryption(r io.ReaderAt, size int64) (err error) {
+	defer func() {
+		if recover() != nil {
+			err = nil
+		}
+	}()
+
+	if _, err := pdf.NewReader(r, size); isEncryptionError(err) {
+		return ErrEncryptedPDF
+	}
+	return nil
+}
+
 // ValidatePDF 验证是否为有效的 PDF 文件
-func ValidatePDF(filePath string) error {
+func ValidatePDF(filePath string) (err error) {
 	ext := strings.ToLower(filepath.Ext(filePath))
 	if ext != ".pdf" {
 		return fmt.Errorf("文件必须是 PDF 格式")
 	}
 
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("无效的 PDF 文件: %v", r)
+		}
+	}()
+
 	// 尝试打开文件验证格式
 	file, _, err := pdf.Open(filePath)
 	if err != nil {
+		if isEncryptionError(err) {
+			return ErrEncryptedPDF
+		}
 		// 提供更友好的错误信息
 		if strings.Contains(err.Error(), "stream not present") {
 			return fmt.Errorf("PDF文件格式不受支持或已损坏。此PDF可能使用了特殊编码、加密或压缩方式。建议：1) 尝试使用其他PDF工具重新保存该文件 2) 确保PDF未加密 3) 使用标准PDF格式")
@@ -358,6 +482,33 @@ func (d *PDFDocument) SaveMonolingualText(outputPath string, translatedBlocks []
 	return writeTextFile(outputPath, content.String())
 }
 
+// SaveBilingualHTML 保存双语对照的 HTML 文件
+func (d *PDFDocument) SaveBilingualHTML(outputPath string, originalBlocks, translatedBlocks []string, layout HTMLLayout) error {
+	blocks := make([]htmlBlock, 0, len(originalBlocks))
+	for i := 0; i < len(originalBlocks) && i < len(translatedBlocks); i++ {
+		blocks = append(blocks, htmlBlock{original: originalBlocks[i], translated: translatedBlocks[i]})
+	}
+	return writeTextFile(outputPath, renderHTML("PDF 翻译结果 / PDF Translation Result", d.htmlMeta(), blocks, true, layout))
+}
+
+// SaveMonolingualHTML 保存单语 HTML 文件
+func (d *PDFDocument) SaveMonolingualHTML(outputPath string, translatedBlocks []string) error {
+	blocks := make([]htmlBlock, 0, len(translatedBlocks))
+	for _, block := range translatedBlocks {
+		blocks = append(blocks, htmlBlock{translated: block})
+	}
+	return writeTextFile(outputPath, renderHTML("PDF 翻译结果 / PDF Translation Result", d.htmlMeta(), blocks, false, ""))
+}
+
+// htmlMeta HTML 输出文件头部显示的文档信息
+func (d *PDFDocument) htmlMeta() []string {
+	return []string{
+		"原文件: " + filepath.Base(d.Path),
+		fmt.Sprintf("总页数: %d", d.Metadata.Pages),
+		"翻译时间: " + time.Now().Format("2006-01-02 15:04:05"),
+	}
+}
+
 // writeTextFile 写入文本文件
 func writeTextFile(filePath, content string) error {
 	file, err := os.Create(filePath)
@@ -415,15 +566,21 @@ func (d *PDFDocument) SaveBilingualPDFWithReplacement(outputPath string, transla
 
 	// 创建PDF重新生成器
 	regenerator := NewPDFRegenerator()
+	regenerator.Context = d.Context
+	regenerator.VerticalText = d.VerticalText
+	regenerator.MergeStrategy = d.MergeStrategy
+	regenerator.MatchMode = d.MatchMode
+	regenerator.Confidences = d.Confidences
 
 	// 构建双语文本映射
+	aligner := NewSentenceAligner().WithLanguages(d.SourceLanguage, d.TargetLanguage)
 	bilingualMappings := make(map[string]string)
 	for original, translation := range translations {
 		switch layout {
 		case BilingualLayoutSideBySide:
 			bilingualMappings[original] = original + " | " + translation
 		case BilingualLayoutInterleaved:
-			bilingualMappings[original] = original + "\n" + translation
+			bilingualMappings[original] = aligner.Interleave(original, translation)
 		default: // BilingualLayoutTopBottom
 			bilingualMappings[original] = original + "\n" + translation
 		}
@@ -482,6 +639,11 @@ func (d *PDFDocument) SaveMonolingualPDFWithReplacement(outputPath string, trans
 
 	// 创建PDF重新生成器
 	regenerator := NewPDFRegenerator()
+	regenerator.Context = d.Context
+	regenerator.VerticalText = d.VerticalText
+	regenerator.MergeStrategy = d.MergeStrategy
+	regenerator.MatchMode = d.MatchMode
+	regenerator.Confidences = d.Confidences
 
 	// 使用重新生成方法
 	err := regenerator.RegeneratePDF(d.Path, outputPath, translations)
@@ -620,6 +782,11 @@ func (d *PDFDocument) SaveMonolingualPDFWithRegeneration(outputPath string, tran
 
 	// 创建PDF重新生成器
 	regenerator := NewPDFRegenerator()
+	regenerator.Context = d.Context
+	regenerator.VerticalText = d.VerticalText
+	regenerator.MergeStrategy = d.MergeStrategy
+	regenerator.MatchMode = d.MatchMode
+	regenerator.Confidences = d.Confidences
 
 	// 使用重新生成方法
 	err := regenerator.RegeneratePDF(d.Path, outputPath, translations)
@@ -654,7 +821,7 @@ func (d *PDFDocument) SaveBilingualPDFWithRegeneration(outputPath string, origin
 				case "side-by-side":
 					bilingualTranslations[original] = original + " | " + translated
 				case "interleaved":
-					bilingualTranslations[original] = original + "\n" + translated
+					bilingualTranslations[original] = NewSentenceAligner().WithLanguages(d.SourceLanguage, d.TargetLanguage).Interleave(original, translated)
 				case "original-only":
 					// 仅保留原文
 					bilingualTranslations[original] = original
@@ -672,6 +839,11 @@ func (d *PDFDocument) SaveBilingualPDFWithRegeneration(outputPath string, origin
 
 	// 创建PDF重新生成器
 	regenerator := NewPDFRegenerator()
+	regenerator.Context = d.Context
+	regenerator.VerticalText = d.VerticalText
+	regenerator.MergeStrategy = d.MergeStrategy
+	regenerator.MatchMode = d.MatchMode
+	regenerator.Confidences = d.Confidences
 
 	// 使用重新生成方法
 	err := regenerator.RegeneratePDF(d.Path, outputPath, bilingualTranslations)
@@ -0,0 +1,153 @@
+package translator
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// AlignmentEntry 对齐数据中的一项：一个已翻译文本块的位置、原文和译文
+type AlignmentEntry struct {
+	Page       int         `json:"page"`
+	BlockID    string      `json:"blockId"` // 文本块 ID，带页码前缀，在整个文档内唯一
+	BBox       BoundingBox `json:"bbox"`    // 原文在页面上的边界框（PDF 坐标，单位为磅）
+	Original   string      `json:"original"`
+	Translated string      `json:"translated"`
+	FontSize   float64     `json:"fontSize"`
+	Confidence float64     `json:"confidence"` // 原文与译文映射的匹配可信度，精确匹配为 1
+	Source     string      `json:"source"`     // 译文所属的待翻译文本块（翻译缓存按此记录），重新翻译时使用
+
+	TranslationConfidence float64 `json:"translationConfidence"` // 翻译服务自评的译文可信度，未启用自评或服务未报告时为 1
+}
+
+// BuildAlignment 从流处理器提取的文本元素生成对齐数据，只包含找到译文的文本块
+// translations 为待翻译文本块 -> 译文；文本块 ID 由页码和解析顺序决定，同一文件和合并策略下保持不变，
+// 文本元素与文本块的匹配按固定顺序进行，同一输入每次生成的对齐数据相同
+func (d *PDFDocument) BuildAlignment(translations map[string]string) ([]AlignmentEntry, error) {
+	processor, err := NewPDFFlowProcessor(d.Path, "")
+	if err != nil {
+		return nil, fmt.Errorf("创建PDF流处理器失败: %w", err)
+	}
+	defer processor.Cleanup()
+	processor.MergeStrategy = d.MergeStrategy
+
+	if err := processor.parsePDFStructure(); err != nil {
+		return nil, fmt.Errorf("解析PDF结构失败: %w", err)
+	}
+
+	sources := make([]string, 0, len(translations))
+	for source := range translations {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+	normalized := make([]string, len(sources))
+	for i, source := range sources {
+		normalized[i] = processor.normalizeText(source)
+	}
+
+	entries := make([]AlignmentEntry, 0)
+	for _, page := range processor.flowData.Pages {
+		for _, element := range page.TextElements {
+			if len(strings.TrimSpace(element.Content)) < 2 || processor.isNumericOrSymbol(element.Content) {
+				continue
+			}
+			source, confidence := processor.alignmentSource(element.Content, translations, sources, normalized)
+			if source == "" {
+				continue
+			}
+			translationConfidence, ok := d.Confidences[source]
+			if !ok {
+				translationConfidence = 1.0
+			}
+			entries = append(entries, AlignmentEntry{
+				Page:       page.PageNumber,
+				BlockID:    fmt.Sprintf("p%d_%s", page.PageNumber, element.ID),
+				BBox:       element.BoundingBox,
+				Original:   element.Content,
+				Translated: translations[source],
+				FontSize:   element.Font.Size,
+				Confidence: confidence,
+				Source:     source,
+
+				TranslationConfidence: translationConfidence,
+			})
+		}
+	}
+
+	return entries, nil
+}
+
+// SaveAlignmentJSON 将对齐数据保存为 JSON 文件（alignment.json）
+func (d *PDFDocument) SaveAlignmentJSON(outputPath string, translations map[string]string) error {
+	entries, err := d.BuildAlignment(translations)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化对齐数据失败: %w", err)
+	}
+	return os.WriteFile(outputPath, data, 0644)
+}
+
+// LoadAlignment 读取 SaveAlignmentJSON 保存的对齐数据
+func LoadAlignment(path string) ([]AlignmentEntry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取对齐数据失败: %w", err)
+	}
+
+	var entries []AlignmentEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("解析对齐数据失败: %w", err)
+	}
+	return entries, nil
+}
+
+// alignmentSource 找出文本元素所属的待翻译文本块，并估计匹配可信度
+// 依次尝试：精确匹配或标准化后相同（可信度 1）、元素是文本块的一部分（可信度为 1/包含它的文本块数）、
+// 相似度匹配（可信度为相似度，需高于 0.8）；sources 已排序，normalized 为对应的标准化文本
+func (p *PDFFlowProcessor) alignmentSource(text string, translations map[string]string, sources, normalized []string) (string, float64) {
+	if _, ok := translations[text]; ok {
+		return text, 1.0
+	}
+
+	clean := p.normalizeText(text)
+	if len(clean) < 3 {
+		return "", 0
+	}
+
+	contained, containing := "", 0
+	for i, norm := range normalized {
+		if norm == clean {
+			return sources[i], 1.0
+		}
+		if strings.Contains(norm, clean) {
+			if containing == 0 {
+				contained = sources[i]
+			}
+			containing++
+		}
+	}
+	if containing > 0 {
+		return contained, 1.0 / float64(containing)
+	}
+
+	best, bestScore := "", 0.8
+	for i, norm := range normalized {
+		lenRatio := float64(len(clean)) / float64(len(norm))
+		if lenRatio < 0.3 || lenRatio > 3.0 {
+			continue
+		}
+		if score := p.calculateSimilarity(clean, norm); score > bestScore {
+			best, bestScore = sources[i], score
+		}
+	}
+	if best == "" {
+		return "", 0
+	}
+	return best, bestScore
+}
@@ -0,0 +1,59 @@
+package translator
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+func TestSaveAlignmentJSON(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	input := filepath.Join(dir, "paper.pdf")
+	pdf := gofpdf.New("P", "pt", "A4", "")
+	pdf.SetFont("Helvetica", "", 12)
+	pdf.AddPage()
+	pdf.Text(72, 100, "Introduction to the method")
+	pdf.Text(72, 200, "Results are promising")
+	pdf.AddPage()
+	pdf.Text(72, 100, "Untranslated footnote text")
+	if err := pdf.OutputFileAndClose(input); err != nil {
+		t.Fatal(err)
+	}
+
+	translations := map[string]string{
+		"Introduction to the method": "方法简介",
+		"Results are promising":      "结果令人鼓舞",
+	}
+	output := filepath.Join(dir, "paper-alignment.json")
+	doc := &PDFDocument{Path: input}
+	if err := doc.SaveAlignmentJSON(output, translations); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entries []AlignmentEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		t.Fatalf("对齐数据不是有效的 JSON: %v", err)
+	}
+	if len(entries) != len(translations) {
+		t.Fatalf("对齐数据 %d 项，期望每个已翻译文本块一项（%d）: %+v", len(entries), len(translations), entries)
+	}
+	for _, e := range entries {
+		if e.Page != 1 || e.BlockID == "" || translations[e.Original] != e.Translated {
+			t.Errorf("对齐项 = %+v", e)
+		}
+		if e.BBox.X <= 0 || e.BBox.Y <= 0 || e.BBox.Width <= 0 || e.BBox.Height <= 0 {
+			t.Errorf("%q 的边界框为空: %+v", e.Original, e.BBox)
+		}
+		if e.FontSize != 12 || e.Confidence != 1 {
+			t.Errorf("%q 的字号 = %v、可信度 = %v，期望 12 和 1", e.Original, e.FontSize, e.Confidence)
+		}
+	}
+}
@@ -0,0 +1,302 @@
+package translator
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// markupAnnotationTypes 保留并翻译内容的标记注释类型（评论、文本框、高亮等）
+var markupAnnotationTypes = map[string]bool{
+	"Text":      true,
+	"FreeText":  true,
+	"Highlight": true,
+	"Underline": true,
+	"Squiggly":  true,
+	"StrikeOut": true,
+	"Square":    true,
+	"Circle":    true,
+	"Caret":     true,
+}
+
+// markupAnnotation 解析标记注释，不是标记注释或没有矩形时返回 false
+// 矩形和 QuadPoints 转换为相对页面左上角的坐标，与链接注释一致
+func (p *PDFFlowProcessor) markupAnnotation(ctx *model.Context, obj types.Object, mediaBox BoundingBox) (AnnotationFlow, bool) {
+	annot, err := ctx.DereferenceDict(obj)
+	if err != nil || annot == nil {
+		return AnnotationFlow{}, false
+	}
+	subtype := annot.NameEntry("Subtype")
+	if subtype == nil || !markupAnnotationTypes[*subtype] {
+		return AnnotationFlow{}, false
+	}
+	rect, err := ctx.DereferenceArray(annot["Rect"])
+	if err != nil || len(rect) < 4 {
+		return AnnotationFlow{}, false
+	}
+
+	result := AnnotationFlow{Type: *subtype, Rect: p.linkRectFromPDF(rect, mediaBox)}
+	text := func(key string) string {
+		s, err := ctx.DereferenceText(annot[key])
+		if err != nil {
+			return ""
+		}
+		return s
+	}
+	result.Contents = text("Contents")
+	result.Author = text("T")
+	result.Subject = text("Subj")
+	result.DefaultAppearance = text("DA")
+	if modDate, ok := types.DateTime(text("M"), true); ok {
+		result.ModDate = modDate
+	}
+	if flags := annot.IntEntry("F"); flags != nil {
+		result.Flags = *flags
+	}
+	if icon := annot.NameEntry("Name"); icon != nil {
+		result.Icon = *icon
+	}
+	if ca, err := ctx.DereferenceNumber(annot["CA"]); err == nil && annot["CA"] != nil {
+		result.Opacity = ca
+	}
+	if color, err := ctx.DereferenceArray(annot["C"]); err == nil {
+		for _, c := range color {
+			result.Color = append(result.Color, p.getFloatValue(c))
+		}
+	}
+	if quads, err := ctx.DereferenceArray(annot["QuadPoints"]); err == nil && len(quads)%8 == 0 {
+		if mediaBox.Height <= 0 {
+			mediaBox = BoundingBox{Width: 595.28, Height: 841.89}
+		}
+		for i := 0; i+1 < len(quads); i += 2 {
+			result.QuadPoints = append(result.QuadPoints,
+				p.getFloatValue(quads[i])-mediaBox.X,
+				mediaBox.Y+mediaBox.Height-p.getFloatValue(quads[i+1]))
+		}
+	}
+	return result, true
+}
+
+// translateAnnotations 将标记注释的内容替换为译文，返回翻译的注释数量
+// 注释内容是独立的文本，只使用原文完全相同或标准化后相同的译文，不做相似度匹配
+func (p *PDFFlowProcessor) translateAnnotations(index *translationIndex) int {
+	translated := 0
+	for i := range p.flowData.Pages {
+		annots := p.flowData.Pages[i].Annotations
+		for j := range annots {
+			if !markupAnnotationTypes[annots[j].Type] || strings.TrimSpace(annots[j].Contents) == "" {
+				continue
+			}
+			if translation := p.annotationTranslation(annots[j].Contents, index); translation != "" {
+				annots[j].Contents = translation
+				translated++
+			}
+		}
+	}
+	return translated
+}
+
+// annotationTranslation 查找注释内容的译文，没有对应译文时返回空字符串
+func (p *PDFFlowProcessor) annotationTranslation(text string, index *translationIndex) string {
+	if translation, ok := index.exact[text]; ok {
+		return translation
+	}
+	if i, ok := index.normalized[p.normalizeText(text)]; ok {
+		return index.entries[i].translation
+	}
+	return ""
+}
+
+// writeAnnotations 将标记注释写入 path 指定的PDF，annotations 的键为输出页码
+// 注释外观由阅读器根据注释属性重新生成
+func (p *PDFFlowProcessor) writeAnnotations(path string, annotations map[int][]AnnotationFlow) error {
+	ctx, err := api.ReadContextFile(path)
+	if err != nil {
+		return fmt.Errorf("读取生成的PDF失败: %w", err)
+	}
+
+	for pageNum, annots := range annotations {
+		if pageNum < 1 || pageNum > ctx.PageCount {
+			continue
+		}
+		pageDict, pageRef, _, err := ctx.PageDict(pageNum, false)
+		if err != nil {
+			return err
+		}
+		pageHeight := 841.89
+		if mediaBox, err := ctx.DereferenceArray(pageDict["MediaBox"]); err == nil && len(mediaBox) >= 4 {
+			pageHeight = p.getFloatValue(mediaBox[3]) - p.getFloatValue(mediaBox[1])
+		}
+
+		for _, annot := range annots {
+			annotDict := types.Dict{
+				"Type":    types.Name("Annot"),
+				"Subtype": types.Name(annot.Type),
+				"Rect":    p.widgetRectToPDF(ctx, pageDict, annot.Rect),
+				"P":       *pageRef,
+				"F":       types.Integer(annot.Flags),
+			}
+			if annot.Contents != "" {
+				annotDict["Contents"] = pdfTextString(annot.Contents)
+			}
+			if annot.Author != "" {
+				annotDict["T"] = pdfTextString(annot.Author)
+			}
+			if annot.Subject != "" {
+				annotDict["Subj"] = pdfTextString(annot.Subject)
+			}
+			if !annot.ModDate.IsZero() {
+				annotDict["M"] = types.StringLiteral(types.DateString(annot.ModDate))
+			}
+			if annot.Icon != "" {
+				annotDict["Name"] = types.Name(annot.Icon)
+			}
+			if annot.DefaultAppearance != "" {
+				annotDict["DA"] = types.StringLiteral(annot.DefaultAppearance)
+			}
+			if annot.Opacity > 0 {
+				annotDict["CA"] = types.Float(annot.Opacity)
+			}
+			if len(annot.Color) > 0 {
+				annotDict["C"] = types.NewNumberArray(annot.Color...)
+			}
+			if len(annot.QuadPoints) > 0 {
+				quads := make([]float64, len(annot.QuadPoints))
+				for i := 0; i+1 < len(quads); i += 2 {
+					quads[i], quads[i+1] = annot.QuadPoints[i], pageHeight-annot.QuadPoints[i+1]
+				}
+				annotDict["QuadPoints"] = types.NewNumberArray(quads...)
+			}
+
+			annotRef, err := ctx.IndRefForNewObject(annotDict)
+			if err != nil {
+				return err
+			}
+			if err := appendPageAnnot(ctx, pageDict, *annotRef); err != nil {
+				return err
+			}
+		}
+	}
+
+	tmpPath := path + ".annot.tmp"
+	if err := api.WriteContextFile(ctx, tmpPath); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("写入注释失败: %w", err)
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// outputAnnotations 按输出页码收集流数据中的标记注释
+func (p *PDFFlowProcessor) outputAnnotations(pageMap map[int]int) map[int][]AnnotationFlow {
+	annotations := make(map[int][]AnnotationFlow)
+	for _, page := range p.flowData.Pages {
+		for _, annot := range page.Annotations {
+			if markupAnnotationTypes[annot.Type] {
+				annotations[pageMap[page.PageNumber]] = append(annotations[pageMap[page.PageNumber]], annot)
+			}
+		}
+	}
+	return annotations
+}
+
+// readMarkupAnnotations 读取PDF中各页的标记注释，键为页码
+func readMarkupAnnotations(path string) (map[int][]AnnotationFlow, error) {
+	ctx, err := api.ReadContextFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// 只用到坐标换算，不需要工作目录
+	p := &PDFFlowProcessor{}
+	annotations := make(map[int][]AnnotationFlow)
+	for pageNum := 1; pageNum <= ctx.PageCount; pageNum++ {
+		pageDict, _, inherited, err := ctx.PageDict(pageNum, false)
+		if err != nil || pageDict == nil {
+			continue
+		}
+		annots, err := ctx.DereferenceArray(pageDict["Annots"])
+		if err != nil || len(annots) == 0 {
+			continue
+		}
+		mediaBox := BoundingBox{}
+		if inherited != nil && inherited.MediaBox != nil {
+			mediaBox = BoundingBox{
+				X:      inherited.MediaBox.LL.X,
+				Y:      inherited.MediaBox.LL.Y,
+				Width:  inherited.MediaBox.Width(),
+				Height: inherited.MediaBox.Height(),
+			}
+		}
+		for i, obj := range annots {
+			if annot, ok := p.markupAnnotation(ctx, obj, mediaBox); ok {
+				annot.ID = fmt.Sprintf("annot_%d_%d", pageNum, i)
+				annotations[pageNum] = append(annotations[pageNum], annot)
+			}
+		}
+	}
+	return annotations, nil
+}
+
+// AnnotationTexts 返回PDF中标记注释（评论、高亮等）的内容，按页码顺序排列并去重，读取失败时返回空
+func AnnotationTexts(path string) []string {
+	annotations, err := readMarkupAnnotations(path)
+	if err != nil {
+		log.Printf("警告：读取PDF注释失败: %v", err)
+		return nil
+	}
+
+	pageNums := make([]int, 0, len(annotations))
+	for pageNum := range annotations {
+		pageNums = append(pageNums, pageNum)
+	}
+	sort.Ints(pageNums)
+
+	var texts []string
+	seen := make(map[string]bool)
+	for _, pageNum := range pageNums {
+		for _, annot := range annotations[pageNum] {
+			if strings.TrimSpace(annot.Contents) != "" && !seen[annot.Contents] {
+				seen[annot.Contents] = true
+				texts = append(texts, annot.Contents)
+			}
+		}
+	}
+	return texts
+}
+
+// CarryMarkupAnnotations 将原PDF的标记注释复制到重新生成的PDF中，注释内容替换为译文
+// 输出与原文页数不同时无法对应页面，不复制注释
+func CarryMarkupAnnotations(inputPath, outputPath string, translations map[string]string) error {
+	annotations, err := readMarkupAnnotations(inputPath)
+	if err != nil || len(annotations) == 0 {
+		return err
+	}
+	inputPages, err := api.PageCountFile(inputPath)
+	if err != nil {
+		return err
+	}
+	outputPages, err := api.PageCountFile(outputPath)
+	if err != nil {
+		return err
+	}
+	if inputPages != outputPages {
+		return fmt.Errorf("输出有 %d 页，原文有 %d 页，无法对应注释所在的页面", outputPages, inputPages)
+	}
+
+	p := &PDFFlowProcessor{}
+	index := p.newTranslationIndex(translations)
+	for _, annots := range annotations {
+		for i := range annots {
+			if translation := p.annotationTranslation(annots[i].Contents, index); translation != "" {
+				annots[i].Contents = translation
+			}
+		}
+	}
+	return p.writeAnnotations(outputPath, annotations)
+}
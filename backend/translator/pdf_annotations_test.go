@@ -0,0 +1,93 @@
+package translator
+
+import (
+	"math"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// addHighlightAnnotation 在 PDF 第1页加入一个带评论的黄色高亮注释
+func addHighlightAnnotation(t *testing.T, path, contents string) {
+	t.Helper()
+	ctx, err := api.ReadContextFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pageDict, pageRef, _, err := ctx.PageDict(1, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	annotRef, err := ctx.IndRefForNewObject(types.Dict{
+		"Type":       types.Name("Annot"),
+		"Subtype":    types.Name("Highlight"),
+		"Rect":       types.NewNumberArray(70, 730, 200, 750),
+		"QuadPoints": types.NewNumberArray(70, 750, 200, 750, 70, 730, 200, 730),
+		"Contents":   types.StringLiteral(contents),
+		"T":          types.StringLiteral("Reviewer"),
+		"C":          types.NewNumberArray(1, 1, 0),
+		"P":          *pageRef,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := appendPageAnnot(ctx, pageDict, *annotRef); err != nil {
+		t.Fatal(err)
+	}
+	if err := api.WriteContextFile(ctx, path); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHighlightCommentSurvivesRegeneration(t *testing.T) {
+	dir := t.TempDir()
+	input := writeTestPDF(t, dir, []string{"The results are conclusive"})
+	addHighlightAnnotation(t, input, "Please verify this claim.")
+	if texts := AnnotationTexts(input); !slices.Equal(texts, []string{"Please verify this claim."}) {
+		t.Fatalf("AnnotationTexts = %q", texts)
+	}
+
+	output := filepath.Join(dir, "out.pdf")
+	p := newTestFlowProcessor(t, input, output)
+	if err := p.ProcessPDF(); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.ApplyTranslations(map[string]string{
+		"The results are conclusive": "Les résultats sont concluants",
+		"Please verify this claim.":  "Veuillez vérifier cette affirmation.",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.GeneratePDF(); err != nil {
+		t.Fatal(err)
+	}
+
+	original, err := readMarkupAnnotations(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	annotations, err := readMarkupAnnotations(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(annotations[1]) != 1 {
+		t.Fatalf("输出第1页有 %d 个标记注释，期望 1: %+v", len(annotations[1]), annotations)
+	}
+	got, want := annotations[1][0], original[1][0]
+	if got.Type != "Highlight" || got.Contents != "Veuillez vérifier cette affirmation." || got.Author != "Reviewer" {
+		t.Errorf("输出的注释 = %+v", got)
+	}
+	if !slices.Equal(got.Color, []float64{1, 1, 0}) {
+		t.Errorf("注释颜色 = %v，期望 [1 1 0]", got.Color)
+	}
+	if math.Abs(got.Rect.X-want.Rect.X) > 0.5 || math.Abs(got.Rect.Y-want.Rect.Y) > 0.5 ||
+		math.Abs(got.Rect.Width-want.Rect.Width) > 0.5 || math.Abs(got.Rect.Height-want.Rect.Height) > 0.5 {
+		t.Errorf("注释矩形 = %+v，期望 %+v", got.Rect, want.Rect)
+	}
+	if len(got.QuadPoints) != 8 {
+		t.Errorf("QuadPoints = %v，期望 8 个坐标", got.QuadPoints)
+	}
+}
@@ -0,0 +1,176 @@
+package translator
+
+import (
+	"encoding/hex"
+	"strings"
+	"unicode/utf16"
+)
+
+// ToUnicodeCMap 字体的 ToUnicode 映射表，用于将字符编码（含双字节 CID）解码为 Unicode
+type ToUnicodeCMap struct {
+	CodeLength int               // 字符编码字节数，Type0/Identity-H 字体通常为 2
+	mapping    map[uint32]string // 字符编码 -> Unicode 文本
+}
+
+// ParseToUnicodeCMap 解析 ToUnicode CMap 流内容，支持 codespacerange、bfchar 和 bfrange
+// 没有任何映射时返回 nil
+func ParseToUnicodeCMap(data string) *ToUnicodeCMap {
+	cmap := &ToUnicodeCMap{mapping: make(map[uint32]string)}
+
+	for _, section := range cmapSections(data, "begincodespacerange", "endcodespacerange") {
+		for _, token := range cmapTokens(section) {
+			if n := len(token) / 2; n > cmap.CodeLength {
+				cmap.CodeLength = n
+			}
+		}
+	}
+
+	for _, section := range cmapSections(data, "beginbfchar", "endbfchar") {
+		tokens := cmapTokens(section)
+		for i := 0; i+1 < len(tokens); i += 2 {
+			if cmap.CodeLength == 0 {
+				cmap.CodeLength = len(tokens[i]) / 2
+			}
+			if code, ok := parseCMapCode(tokens[i]); ok {
+				cmap.mapping[code] = decodeUTF16Hex(tokens[i+1])
+			}
+		}
+	}
+
+	for _, section := range cmapSections(data, "beginbfrange", "endbfrange") {
+		tokens := cmapTokens(section)
+		for i := 0; i+2 < len(tokens); {
+			low, ok1 := parseCMapCode(tokens[i])
+			high, ok2 := parseCMapCode(tokens[i+1])
+			if cmap.CodeLength == 0 {
+				cmap.CodeLength = len(tokens[i]) / 2
+			}
+
+			if tokens[i+2] == "[" {
+				// <low> <high> [<dst1> <dst2> ...]
+				j := i + 3
+				for code := low; j < len(tokens) && tokens[j] != "]"; j++ {
+					if ok1 && ok2 && code <= high {
+						cmap.mapping[code] = decodeUTF16Hex(tokens[j])
+					}
+					code++
+				}
+				i = j + 1
+				continue
+			}
+
+			// <low> <high> <dst>：目标值按偏移递增
+			if ok1 && ok2 && high >= low {
+				dst := []rune(decodeUTF16Hex(tokens[i+2]))
+				for code := low; code <= high && len(dst) > 0; code++ {
+					mapped := make([]rune, len(dst))
+					copy(mapped, dst)
+					mapped[len(mapped)-1] += rune(code - low)
+					cmap.mapping[code] = string(mapped)
+				}
+			}
+			i += 3
+		}
+	}
+
+	if len(cmap.mapping) == 0 {
+		return nil
+	}
+	if cmap.CodeLength <= 0 {
+		cmap.CodeLength = 1
+	}
+	return cmap
+}
+
+// Decode 将十六进制字符串按 CMap 解码为 Unicode 文本
+// 单字节编码中未映射的字节按 Latin1 处理，多字节编码中未映射的编码被跳过
+func (c *ToUnicodeCMap) Decode(hexStr string) string {
+	data, err := hex.DecodeString(strings.Join(strings.Fields(hexStr), ""))
+	if err != nil {
+		return ""
+	}
+
+	var result strings.Builder
+	for i := 0; i+c.CodeLength <= len(data); i += c.CodeLength {
+		var code uint32
+		for _, b := range data[i : i+c.CodeLength] {
+			code = code<<8 | uint32(b)
+		}
+
+		if text, ok := c.mapping[code]; ok {
+			result.WriteString(text)
+		} else if c.CodeLength == 1 && code > 0 {
+			result.WriteRune(rune(code))
+		}
+	}
+	return result.String()
+}
+
+// cmapSections 返回 begin/end 关键字之间的所有内容片段
+func cmapSections(data, begin, end string) []string {
+	var sections []string
+	for {
+		start := strings.Index(data, begin)
+		if start < 0 {
+			return sections
+		}
+		data = data[start+len(begin):]
+
+		stop := strings.Index(data, end)
+		if stop < 0 {
+			return append(sections, data)
+		}
+		sections = append(sections, data[:stop])
+		data = data[stop+len(end):]
+	}
+}
+
+// cmapTokens 提取片段中的十六进制字符串（不含尖括号）和数组括号
+func cmapTokens(section string) []string {
+	var tokens []string
+	for i := 0; i < len(section); i++ {
+		switch section[i] {
+		case '<':
+			end := strings.IndexByte(section[i:], '>')
+			if end < 0 {
+				return tokens
+			}
+			tokens = append(tokens, strings.Join(strings.Fields(section[i+1:i+end]), ""))
+			i += end
+		case '[', ']':
+			tokens = append(tokens, string(section[i]))
+		}
+	}
+	return tokens
+}
+
+// parseCMapCode 将十六进制编码解析为整数
+func parseCMapCode(token string) (uint32, bool) {
+	data, err := hex.DecodeString(token)
+	if err != nil || len(data) == 0 || len(data) > 4 {
+		return 0, false
+	}
+
+	var code uint32
+	for _, b := range data {
+		code = code<<8 | uint32(b)
+	}
+	return code, true
+}
+
+// decodeUTF16Hex 将 UTF-16BE 十六进制字符串解码为文本
+func decodeUTF16Hex(token string) string {
+	data, err := hex.DecodeString(token)
+	if err != nil {
+		return ""
+	}
+	if len(data)%2 != 0 {
+		data = append([]byte{0}, data...)
+	}
+
+	units := make([]uint16, 0, len(data)/2)
+	for i := 0; i+1 < len(data); i += 2 {
+		units = append(units, uint16(data[i])<<8|uint16(data[i+1]))
+	}
+	return string(utf16.Decode(units))
+}
@@ -0,0 +1,51 @@
+package translator
+
+import "testing"
+
+const testToUnicodeCMap = `/CIDInit /ProcSet findresource begin
+12 dict begin
+begincmap
+1 begincodespacerange
+<0000> <FFFF>
+endcodespacerange
+2 beginbfchar
+<0102> <4E2D>
+<0103> <6587>
+endbfchar
+1 beginbfrange
+<0200> <0202> <0041>
+endbfrange
+endcmap
+end end`
+
+func TestToUnicodeCMapDecodesTwoByteCodes(t *testing.T) {
+	cmap := ParseToUnicodeCMap(testToUnicodeCMap)
+	if cmap == nil {
+		t.Fatal("ParseToUnicodeCMap 返回 nil")
+	}
+	if cmap.CodeLength != 2 {
+		t.Errorf("CodeLength = %d，期望 2", cmap.CodeLength)
+	}
+	if got := cmap.Decode("0102 0103 0201"); got != "中文B" {
+		t.Errorf("Decode = %q，期望 中文B", got)
+	}
+}
+
+func TestParseContentElementsUsesToUnicode(t *testing.T) {
+	p := newTestFlowProcessor(t, "input.pdf", "")
+	ops, err := p.parseOperations("BT /F1 12 Tf 72 700 Td <010201030200> Tj ET")
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := &PDFPageFlow{ContentStreams: []ContentStreamFlow{{ParsedOps: ops}}}
+	fonts := map[string]FontResource{"F1": {Name: "F1", Subtype: "Type0", Encoding: "Identity-H", ToUnicode: testToUnicodeCMap}}
+	if err := p.parseContentElements(page, fonts); err != nil {
+		t.Fatal(err)
+	}
+	if len(page.TextElements) != 1 {
+		t.Fatalf("文本元素 %d 个，期望 1", len(page.TextElements))
+	}
+	if got := page.TextElements[0].Content; got != "中文A" {
+		t.Errorf("解码结果 = %q，期望 中文A", got)
+	}
+}
@@ -132,11 +132,13 @@ func (cd *ColumnDetector) smoothHistogram(histogram map[int]int) map[int]float64
 		sum := 0
 		count := 0
 		
+		// 窗口内没有文本的位置按 0 计入，否则间隙两侧的密度会把间隙填平
 		for offset := -windowSize; offset <= windowSize; offset++ {
-			if val, ok := histogram[bin+offset]; ok {
-				sum += val
-				count++
+			if bin+offset < 0 {
+				continue
 			}
+			sum += histogram[bin+offset]
+			count++
 		}
 		
 		if count > 0 {
@@ -0,0 +1,52 @@
+package translator
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jung-kurt/gofpdf"
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+)
+
+// 译文 PDF 由重新生成页面得到，不在原文件中改写内容流；
+// 这里确认 FlateDecode 压缩的内容流能被正确读取，替换文字后的文件可以重新打开并通过校验
+func TestRegenerateFlateEncodedPDF(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "compressed.pdf")
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetCompression(true)
+	pdf.SetFont("Helvetica", "", 12)
+	pdf.AddPage()
+	pdf.Text(20, 30, "Hello world")
+	if err := pdf.OutputFileAndClose(input); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(data, []byte("/FlateDecode")) || bytes.Contains(data, []byte("(Hello world)")) {
+		t.Fatal("测试PDF的内容流应为 FlateDecode 压缩")
+	}
+
+	output := filepath.Join(dir, "out.pdf")
+	t.Chdir(dir)
+	r := NewPDFRegenerator()
+	r.TargetLanguage = "fr"
+	if err := r.RegeneratePDF(input, output, map[string]string{"Hello world": "Bonjour le monde"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := api.ValidateFile(output, nil); err != nil {
+		t.Fatalf("输出文件未通过校验: %v", err)
+	}
+	modes := textRenderModes(t, output)
+	if _, ok := modes["Bonjour le monde"]; !ok {
+		t.Errorf("重新打开后未找到译文，文本: %v", modes)
+	}
+	if _, ok := modes["Hello world"]; ok {
+		t.Errorf("原文不应出现在输出中，文本: %v", modes)
+	}
+}
@@ -1,13 +1,16 @@
 package translator
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 	"translator-web/pdf"
 
@@ -30,6 +33,53 @@ type PDFFlowProcessor struct {
 	UniFontName  string            // 添加通用字体名称字段
 	imageDir     string            // 图片临时目录
 	imageMapping map[string]string // 图片名称到文件路径的映射
+
+	layoutAdjuster *LayoutAdjuster // 译文排版调整器（换行、字号和行距）
+	textClusterer  *TextClusterer  // 文本聚类器，用于识别段落对齐方式
+	TargetLanguage string          // 目标语言，写入输出文档信息
+	TranslateTitle bool            // 是否将文档标题替换为译文
+	ctxMu          sync.Mutex      // 串行化对 pdfcpu 上下文的访问（并行解析页面时使用）
+
+	PreserveOriginalTextLayer bool // 在译文下保留不可见的原文层（Tr 3），便于搜索和复制原文
+
+	FontPolicy  *FontPolicy       // 按语言选择字体，为空时所有 CJK 文本使用同一通用字体
+	policyFonts map[string]string // 已注册到输出 PDF 的字体文件 -> 字体名称（空字符串表示注册失败）
+
+	KeepWorkDir bool // Cleanup 时保留临时工作目录用于调试，默认取自 KeepWorkDirFromEnv
+
+	CharacterMap CharacterMap // PDF 字符编码映射，为空时使用 CharacterMapFromEnv
+	ImageOptions ImageOptions // 重新嵌入图片时的压缩和降采样设置，默认保持原图
+
+	Context context.Context // 任务上下文，取消或超时后停止逐页解析和生成，为空时不限制
+
+	VerticalText bool // 原文为竖排且译文为 CJK 文字时以竖排输出（逐字堆叠、从右到左分列），默认按横排输出
+
+	MergeStrategy MergeStrategy // 合并被过度分割的文本元素时采用的策略，为空时使用默认阈值
+
+	Confidences map[string]float64 // 翻译服务自评的可信度：原文 -> 可信度，未列出的为 1
+
+	MatchMode       MatchMode     // 译文与文本元素的匹配模式，为空时依次尝试所有匹配方式
+	MatchStrategies MatchStrategy // 启用的匹配方式，不为 0 时优先于 MatchMode
+	MatchThreshold  float64       // 相似度匹配的阈值，为 0 时使用 0.8
+
+	streamBatchSize int                 // 分批处理时每批的页数，为 0 时所有页面都在内存中（见 ProcessPDFStreaming）
+	pageBoxes       map[int]BoundingBox // 分批处理时各页的 MediaBox，供查找不在当前批次的页面尺寸
+}
+
+// contextErr 任务上下文已取消或超时时返回其错误
+func (p *PDFFlowProcessor) contextErr() error {
+	if p.Context == nil {
+		return nil
+	}
+	return p.Context.Err()
+}
+
+// characterMap 返回文本清理使用的字符编码映射
+func (p *PDFFlowProcessor) characterMap() CharacterMap {
+	if p.CharacterMap != nil {
+		return p.CharacterMap
+	}
+	return CharacterMapFromEnv()
 }
 
 // PDFFlowData PDF流数据结构
@@ -39,6 +89,7 @@ type PDFFlowData struct {
 	Resources    PDFResourcesFlow    `json:"resources"`
 	ProcessTime  time.Time           `json:"process_time"`
 	OriginalSize int64               `json:"original_size"`
+	FormFields   []FormFieldFlow     `json:"form_fields,omitempty"` // AcroForm 表单域
 }
 
 // PDFDocumentMetadata PDF文档元数据
@@ -80,21 +131,34 @@ type BoundingBox struct {
 
 // TextElementFlow 文本元素流
 type TextElementFlow struct {
-	ID           string          `json:"id"`
-	Content      string          `json:"content"`
-	Position     PositionFlow    `json:"position"`
-	Font         FontFlow        `json:"font"`
-	Color        ColorFlow       `json:"color"`
-	Transform    TransformMatrix `json:"transform"`
-	BoundingBox  BoundingBox     `json:"bounding_box"`
-	TextState    TextStateFlow   `json:"text_state"`
-	IsFormula    bool            `json:"is_formula"`
-	Language     string          `json:"language"`
-	Confidence   float64         `json:"confidence"`
-	OriginalOps  []string        `json:"original_ops"`
-	Dependencies []string        `json:"dependencies"`
-	OriginalBoundingBox BoundingBox `json:"original_bounding_box"`
-}
+	ID                  string          `json:"id"`
+	Content             string          `json:"content"`
+	Position            PositionFlow    `json:"position"`
+	Font                FontFlow        `json:"font"`
+	Color               ColorFlow       `json:"color"`
+	Transform           TransformMatrix `json:"transform"`
+	BoundingBox         BoundingBox     `json:"bounding_box"`
+	TextState           TextStateFlow   `json:"text_state"`
+	IsFormula           bool            `json:"is_formula"`
+	Language            string          `json:"language"`
+	Confidence          float64         `json:"confidence"`
+	OriginalOps         []string        `json:"original_ops"`
+	Dependencies        []string        `json:"dependencies"`
+	OriginalBoundingBox BoundingBox     `json:"original_bounding_box"`
+	ScriptPosition      string          `json:"script_position,omitempty"`  // superscript, subscript 或空（基线文本）
+	OriginalContent     string          `json:"original_content,omitempty"` // 翻译前的原文
+	Alignment           string          `json:"alignment,omitempty"`        // 所在段落的对齐方式：left, center, right, justify
+	Vertical            bool            `json:"vertical,omitempty"`         // 原文为竖排（字体 WMode 为 1 或变换矩阵旋转了 90 度）
+}
+
+// 上下标位置
+const (
+	ScriptSuperscript = "superscript"
+	ScriptSubscript   = "subscript"
+)
+
+// scriptSizeRatio 上下标相对正文的字体缩放比例
+const scriptSizeRatio = 0.65
 
 // PositionFlow 位置流信息
 type PositionFlow struct {
@@ -119,6 +183,8 @@ type FontFlow struct {
 	Metrics      FontMetrics        `json:"metrics"`
 	CharWidths   map[rune]float64   `json:"char_widths"`
 	KerningPairs map[string]float64 `json:"kerning_pairs"`
+	ToUnicode    *ToUnicodeCMap     `json:"-"`                  // ToUnicode 映射，用于解码十六进制（CID）文本
+	Vertical     bool               `json:"vertical,omitempty"` // 竖排书写模式（WMode 1）
 }
 
 // FontMetrics 字体度量信息
@@ -229,6 +295,16 @@ type AnnotationFlow struct {
 	Author   string      `json:"author"`
 	Subject  string      `json:"subject"`
 	ModDate  time.Time   `json:"mod_date"`
+	URI      string      `json:"uri,omitempty"`  // 外部链接地址
+	Dest     *LinkDest   `json:"dest,omitempty"` // 文档内跳转（GoTo）的目标
+
+	// 标记注释（评论、高亮等）的外观属性
+	Color             []float64 `json:"color,omitempty"`       // 注释颜色（C），按分量个数为灰度、RGB 或 CMYK
+	Opacity           float64   `json:"opacity,omitempty"`     // 不透明度（CA），0 表示未设置
+	QuadPoints        []float64 `json:"quad_points,omitempty"` // 高亮等文本标记覆盖的四边形，相对页面左上角
+	Flags             int       `json:"flags,omitempty"`
+	Icon              string    `json:"icon,omitempty"` // 评论注释的图标名称（Name）
+	DefaultAppearance string    `json:"default_appearance,omitempty"`
 }
 
 // ContentStreamFlow 内容流
@@ -296,6 +372,7 @@ type FontResource struct {
 	FontBBox  BoundingBox       `json:"font_bbox"`
 	CharProcs map[string]string `json:"char_procs,omitempty"`
 	ToUnicode string            `json:"to_unicode,omitempty"`
+	Vertical  bool              `json:"vertical,omitempty"` // 编码 CMap 为竖排书写模式（如 Identity-V）
 }
 
 // ImageResource 图像资源
@@ -371,16 +448,15 @@ type FontManager struct {
 // NewPDFFlowProcessor 创建PDF流处理器
 func NewPDFFlowProcessor(inputPath, outputPath string) (*PDFFlowProcessor, error) {
 	// 创建工作目录 - 使用项目目录下的cache目录
-	currentDir, _ := os.Getwd()
-	cacheDir := filepath.Join(currentDir, "cache")
+	cacheDir := flowCacheDir()
 	if err := os.MkdirAll(cacheDir, 0755); err != nil {
 		return nil, fmt.Errorf("创建缓存目录失败: %w", err)
 	}
 
-	// 在cache目录下创建具体的工作目录
+	// 在cache目录下创建具体的工作目录，同一秒内创建的多个处理器使用不同目录
 	sessionID := fmt.Sprintf("session_%d", time.Now().Unix())
-	workDir := filepath.Join(cacheDir, fmt.Sprintf("pdf_flow_%s", sessionID))
-	if err := os.MkdirAll(workDir, 0755); err != nil {
+	workDir, err := os.MkdirTemp(cacheDir, fmt.Sprintf("%s%s_", flowWorkDirPrefix, sessionID))
+	if err != nil {
 		return nil, fmt.Errorf("创建工作目录失败: %w", err)
 	}
 
@@ -408,6 +484,11 @@ func NewPDFFlowProcessor(inputPath, outputPath string) (*PDFFlowProcessor, error
 		sessionID:    sessionID,
 		imageDir:     imageDir,
 		imageMapping: make(map[string]string),
+
+		layoutAdjuster: NewLayoutAdjuster(),
+		textClusterer:  NewTextClusterer(),
+
+		KeepWorkDir: KeepWorkDirFromEnv(),
 	}
 
 	// 记录初始化信息
@@ -511,97 +592,174 @@ func (p *PDFFlowProcessor) ApplyTranslations(translations map[string]string) err
 
 	// 2. 预处理翻译映射 - 创建更多的匹配模式
 	enhancedTranslations := p.enhanceTranslationMappings(translations)
+
+	// 页眉页脚在各页只有页码等数字不同，复用已翻译的译文
+	if p.textClusterer != nil {
+		var candidates []runningCandidate
+		err := p.forEachPageBatch(false, func() error {
+			pages := make([]*PDFPageFlow, len(p.flowData.Pages))
+			for i := range p.flowData.Pages {
+				pages[i] = &p.flowData.Pages[i]
+			}
+			candidates = append(candidates, pageRunningCandidates(pages)...)
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("识别页眉页脚失败: %w", err)
+		}
+		running := detectRunning(candidates)
+		enhancedTranslations = ReuseRunningTranslations(running, enhancedTranslations)
+		p.logger.Info("识别页眉页脚", map[string]interface{}{
+			"数量": len(running),
+		})
+	}
 	p.logger.Info("翻译映射增强完成", map[string]interface{}{
 		"原始映射数": len(translations),
 		"增强映射数": len(enhancedTranslations),
 	})
+	index := p.newTranslationIndex(enhancedTranslations)
+
+	// 文本元素按译文匹配，可信度也按译文查找（同一译文取最低值）
+	confidenceByTranslation := make(map[string]float64)
+	for original, confidence := range p.Confidences {
+		translation, ok := translations[original]
+		if !ok {
+			continue
+		}
+		if existing, ok := confidenceByTranslation[translation]; !ok || confidence < existing {
+			confidenceByTranslation[translation] = confidence
+		}
+	}
 
 	// 3. 应用翻译到文本元素
 	translatedCount := 0
 	totalElements := 0
-
-	for pageIdx := range p.flowData.Pages {
-		page := &p.flowData.Pages[pageIdx]
-		pageStartTime := time.Now()
-		pageTranslatedCount := 0
-
-		for elemIdx := range page.TextElements {
-			element := &page.TextElements[elemIdx]
-			totalElements++
-
-			// 跳过过短的文本或纯数字/符号
-			if len(strings.TrimSpace(element.Content)) < 2 || p.isNumericOrSymbol(element.Content) {
-				continue
+	annotTranslated := 0
+
+	// 分批处理时逐批加载页面，应用翻译并重新计算布局后写回
+	err := p.forEachPageBatch(true, func() error {
+		for pageIdx := range p.flowData.Pages {
+			page := &p.flowData.Pages[pageIdx]
+			pageStartTime := time.Now()
+			pageTranslatedCount := 0
+
+			// 识别段落对齐方式，供重新排版时使用
+			if p.textClusterer != nil {
+				p.textClusterer.ApplyBlockAlignment(page, p.textClusterer.ClusterPageBlocks(page))
 			}
 
-			if translation := p.findBestTranslation(element.Content, enhancedTranslations); translation != "" {
-				// 记录翻译前的状态
-				originalContent := element.Content
-				originalBounds := element.BoundingBox
-				element.OriginalBoundingBox = originalBounds
+			for elemIdx := range page.TextElements {
+				element := &page.TextElements[elemIdx]
+				totalElements++
 
-				// 计算新文本的尺寸
-				newBounds, err := p.calculateTextBounds(translation, element.Font)
-				if err != nil {
-					p.logger.Warn("计算文本边界失败", map[string]interface{}{
-						"页码":   page.PageNumber,
-						"元素ID": element.ID,
-						"错误":   err.Error(),
-					})
+				// 跳过过短的文本或纯数字/符号
+				if len(strings.TrimSpace(element.Content)) < 2 || p.isNumericOrSymbol(element.Content) {
 					continue
 				}
 
-				// 更新文本内容和边界
-				element.Content = translation
-				element.BoundingBox = newBounds
+				if translation := p.findBestTranslation(element.Content, index); translation != "" {
+					// 记录翻译前的状态
+					originalContent := element.Content
+					originalBounds := element.BoundingBox
+					element.OriginalBoundingBox = originalBounds
+					element.OriginalContent = originalContent
+
+					// 计算新文本的尺寸
+					newBounds, err := p.calculateTextBounds(translation, element.Font)
+					if err != nil {
+						p.logger.Warn("计算文本边界失败", map[string]interface{}{
+							"页码":   page.PageNumber,
+							"元素ID": element.ID,
+							"错误":   err.Error(),
+						})
+						continue
+					}
+
+					// 更新文本内容和边界
+					element.Content = translation
+					element.BoundingBox = newBounds
 
-				// 保持原始位置
-				element.BoundingBox.X = originalBounds.X
-				element.BoundingBox.Y = originalBounds.Y
+					// 保持原始位置
+					element.BoundingBox.X = originalBounds.X
+					element.BoundingBox.Y = originalBounds.Y
 
-				// 标记为已翻译
-				element.Language = "zh"
-				element.Confidence = 1.0
+					// 标记为已翻译
+					element.Language = "zh"
+					element.Confidence = 1.0
+					if confidence, ok := confidenceByTranslation[translation]; ok {
+						element.Confidence = confidence
+					}
 
-				// 记录翻译日志
-				p.logger.LogTranslation(page.PageNumber, element.ID, originalContent, translation)
+					// 记录翻译日志
+					p.logger.LogTranslation(page.PageNumber, element.ID, originalContent, translation)
 
-				// 记录边界变化
-				p.logger.Debug("文本边界变化", map[string]interface{}{
-					"页码":   page.PageNumber,
-					"元素ID": element.ID,
-					"原宽度":  fmt.Sprintf("%.2f", originalBounds.Width),
-					"新宽度":  fmt.Sprintf("%.2f", newBounds.Width),
-					"原高度":  fmt.Sprintf("%.2f", originalBounds.Height),
-					"新高度":  fmt.Sprintf("%.2f", newBounds.Height),
-					"宽度变化": fmt.Sprintf("%+.2f", newBounds.Width-originalBounds.Width),
-					"高度变化": fmt.Sprintf("%+.2f", newBounds.Height-originalBounds.Height),
-				})
+					// 记录边界变化
+					p.logger.Debug("文本边界变化", map[string]interface{}{
+						"页码":   page.PageNumber,
+						"元素ID": element.ID,
+						"原宽度":  fmt.Sprintf("%.2f", originalBounds.Width),
+						"新宽度":  fmt.Sprintf("%.2f", newBounds.Width),
+						"原高度":  fmt.Sprintf("%.2f", originalBounds.Height),
+						"新高度":  fmt.Sprintf("%.2f", newBounds.Height),
+						"宽度变化": fmt.Sprintf("%+.2f", newBounds.Width-originalBounds.Width),
+						"高度变化": fmt.Sprintf("%+.2f", newBounds.Height-originalBounds.Height),
+					})
 
-				translatedCount++
-				pageTranslatedCount++
+					translatedCount++
+					pageTranslatedCount++
+				}
 			}
+
+			// 记录页面翻译完成
+			pageTime := time.Since(pageStartTime)
+			p.logger.Debug("页面翻译完成", map[string]interface{}{
+				"页码":    page.PageNumber,
+				"翻译元素数": pageTranslatedCount,
+				"总元素数":  len(page.TextElements),
+				"翻译率":   fmt.Sprintf("%.1f%%", float64(pageTranslatedCount)/float64(len(page.TextElements))*100),
+				"耗时":    pageTime.String(),
+			})
 		}
 
-		// 记录页面翻译完成
-		pageTime := time.Since(pageStartTime)
-		p.logger.Debug("页面翻译完成", map[string]interface{}{
-			"页码":    page.PageNumber,
-			"翻译元素数": pageTranslatedCount,
-			"总元素数":  len(page.TextElements),
-			"翻译率":   fmt.Sprintf("%.1f%%", float64(pageTranslatedCount)/float64(len(page.TextElements))*100),
-			"耗时":    pageTime.String(),
+		// 翻译评论、高亮等标记注释的内容
+		annotTranslated += p.translateAnnotations(index)
+
+		// 4. 重新计算布局
+		layoutStartTime := time.Now()
+		if err := p.recalculateLayout(); err != nil {
+			p.logger.LogError("重新计算布局", err, nil)
+			return fmt.Errorf("重新计算布局失败: %w", err)
+		}
+		p.logger.LogOperationTiming("重新计算布局", time.Since(layoutStartTime))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if annotTranslated > 0 {
+		p.logger.Info("注释翻译完成", map[string]interface{}{
+			"翻译注释数量": annotTranslated,
 		})
 	}
 
-	// 4. 重新计算布局
-	layoutStartTime := time.Now()
-	if err := p.recalculateLayout(); err != nil {
-		p.logger.LogError("重新计算布局", err, nil)
-		return fmt.Errorf("重新计算布局失败: %w", err)
+	// 翻译表单域的文本值
+	if formTranslated := p.translateFormFields(index); formTranslated > 0 {
+		p.logger.Info("表单域翻译完成", map[string]interface{}{
+			"翻译域数量": formTranslated,
+			"表单域总数": len(p.flowData.FormFields),
+		})
+	}
+
+	// 翻译文档标题
+	if p.TranslateTitle && p.flowData.Metadata.Title != "" {
+		if translation := p.findBestTranslation(p.flowData.Metadata.Title, index); translation != "" {
+			if p.flowData.Metadata.CustomProps == nil {
+				p.flowData.Metadata.CustomProps = make(map[string]string)
+			}
+			p.flowData.Metadata.CustomProps["OriginalTitle"] = p.flowData.Metadata.Title
+			p.flowData.Metadata.Title = translation
+		}
 	}
-	layoutTime := time.Since(layoutStartTime)
-	p.logger.LogOperationTiming("重新计算布局", layoutTime)
 
 	// 5. 保存更新后的流数据
 	saveStartTime := time.Now()
@@ -749,28 +907,9 @@ func (p *PDFFlowProcessor) removeLigatures(text string) string {
 	return result
 }
 
-// splitIntoSentences 将文本分割为句子
+// splitIntoSentences 将文本分割为句子（原文语言未知，按通用规则断句）
 func (p *PDFFlowProcessor) splitIntoSentences(text string) []string {
-	// 按句号、感叹号、问号分割
-	sentences := []string{}
-	current := ""
-
-	for _, r := range text {
-		current += string(r)
-		if r == '.' || r == '!' || r == '?' {
-			if len(strings.TrimSpace(current)) > 0 {
-				sentences = append(sentences, strings.TrimSpace(current))
-				current = ""
-			}
-		}
-	}
-
-	// 添加剩余部分
-	if len(strings.TrimSpace(current)) > 0 {
-		sentences = append(sentences, strings.TrimSpace(current))
-	}
-
-	return sentences
+	return splitSentences(text, "")
 }
 
 // splitIntoPhrases 将文本分割为短语
@@ -918,33 +1057,55 @@ func (p *PDFFlowProcessor) GeneratePDF() error {
 	}
 	p.logger.LogOperationTiming("设置字体", time.Since(fontSetupStart))
 
-	// 4. 逐页生成内容
+	// 4. 逐页生成内容（原页码 -> 输出页码，用于重建文档内跳转链接），分批处理时逐批加载页面
+	pageMap := p.outputPageMap()
+	pageCount := p.pageCount()
 	totalElements := 0
-	for _, page := range p.flowData.Pages {
-		pageStartTime := time.Now()
+	annotations := make(map[int][]AnnotationFlow)
+	err := p.forEachPageBatch(false, func() error {
+		for _, page := range p.flowData.Pages {
+			if err := p.contextErr(); err != nil {
+				return fmt.Errorf("生成页面中止: %w", err)
+			}
+			pageStartTime := time.Now()
 
-		if err := p.generatePage(pdf, page); err != nil {
-			p.logger.LogError("生成页面", err, map[string]interface{}{
-				"页码": page.PageNumber,
-			})
-			return fmt.Errorf("生成页面%d失败: %w", page.PageNumber, err)
-		}
+			if err := p.generatePage(pdf, page); err != nil {
+				p.logger.LogError("生成页面", err, map[string]interface{}{
+					"页码": page.PageNumber,
+				})
+				return fmt.Errorf("生成页面%d失败: %w", page.PageNumber, err)
+			}
+			p.renderLinks(pdf, page, pageMap)
 
-		pageElements := len(page.TextElements) + len(page.ImageElements) + len(page.GraphicsElements)
-		totalElements += pageElements
+			pageElements := len(page.TextElements) + len(page.ImageElements) + len(page.GraphicsElements)
+			totalElements += pageElements
 
-		pageTime := time.Since(pageStartTime)
-		p.logger.LogPageProcessing(page.PageNumber, len(p.flowData.Pages),
-			len(page.TextElements), len(page.ImageElements), len(page.GraphicsElements))
+			pageTime := time.Since(pageStartTime)
+			p.logger.LogPageProcessing(page.PageNumber, pageCount,
+				len(page.TextElements), len(page.ImageElements), len(page.GraphicsElements))
 
-		p.logger.Debug("页面生成耗时", map[string]interface{}{
-			"页码":  page.PageNumber,
-			"耗时":  pageTime.String(),
-			"元素数": pageElements,
-		})
+			p.logger.Debug("页面生成耗时", map[string]interface{}{
+				"页码":  page.PageNumber,
+				"耗时":  pageTime.String(),
+				"元素数": pageElements,
+			})
+		}
+
+		for pageNum, annots := range p.outputAnnotations(pageMap) {
+			annotations[pageNum] = append(annotations[pageNum], annots...)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
+	// 分批处理时页面已全部写入 pdf，逐页保存的流数据不再需要
+	p.removePageFlows()
 
-	// 5. 保存PDF文件
+	// 5. 写入文档信息
+	p.setDocumentInfo(pdf)
+
+	// 6. 保存PDF文件
 	saveStartTime := time.Now()
 	if err := pdf.OutputFileAndClose(p.outputPath); err != nil {
 		p.logger.LogError("保存PDF文件", err, map[string]interface{}{
@@ -955,6 +1116,24 @@ func (p *PDFFlowProcessor) GeneratePDF() error {
 	saveTime := time.Since(saveStartTime)
 	p.logger.LogOperationTiming("保存PDF文件", saveTime)
 
+	// 7. 重新写入表单域，失败时保留不含表单的PDF
+	if len(p.flowData.FormFields) > 0 {
+		if err := p.writeFormFields(); err != nil {
+			p.logger.Warn("写入表单域失败", map[string]interface{}{
+				"错误": err.Error(),
+			})
+		}
+	}
+
+	// 8. 重新写入评论、高亮等标记注释，失败时保留不含注释的PDF
+	if len(annotations) > 0 {
+		if err := p.writeAnnotations(p.outputPath, annotations); err != nil {
+			p.logger.Warn("写入注释失败", map[string]interface{}{
+				"错误": err.Error(),
+			})
+		}
+	}
+
 	// 记录文件信息
 	if info, err := os.Stat(p.outputPath); err == nil {
 		p.logger.LogFileOperation("生成PDF", p.outputPath, info.Size())
@@ -963,16 +1142,16 @@ func (p *PDFFlowProcessor) GeneratePDF() error {
 	// 记录生成统计
 	totalTime := time.Since(startTime)
 	p.logger.LogStatistics(map[string]interface{}{
-		"总页数":  len(p.flowData.Pages),
+		"总页数":  pageCount,
 		"总元素数": totalElements,
 		"总耗时":  totalTime.String(),
-		"平均每页": fmt.Sprintf("%.2fs", totalTime.Seconds()/float64(len(p.flowData.Pages))),
+		"平均每页": fmt.Sprintf("%.2fs", totalTime.Seconds()/float64(pageCount)),
 		"输出文件": p.outputPath,
 	})
 
 	p.logger.Info("PDF生成完成", map[string]interface{}{
 		"输出文件": p.outputPath,
-		"总页数":  len(p.flowData.Pages),
+		"总页数":  pageCount,
 		"总耗时":  totalTime.String(),
 	})
 
@@ -980,11 +1159,12 @@ func (p *PDFFlowProcessor) GeneratePDF() error {
 }
 
 // Cleanup 清理临时文件
+// 默认删除临时工作目录；KeepWorkDir 为 true 时保留目录和日志用于调试
 func (p *PDFFlowProcessor) Cleanup() error {
 	if p.logger != nil {
 		p.logger.Info("开始清理临时文件", map[string]interface{}{
 			"工作目录": p.workDir,
-			"保留调试": true,
+			"保留调试": p.KeepWorkDir,
 		})
 
 		// 关闭日志记录器
@@ -993,14 +1173,20 @@ func (p *PDFFlowProcessor) Cleanup() error {
 		}
 	}
 
-	if p.workDir != "" {
-		// 保留临时工作目录用于调试
+	if p.workDir == "" {
+		return nil
+	}
+
+	if p.KeepWorkDir {
 		log.Printf("保留临时工作目录用于调试: %s", p.workDir)
 		if p.logger != nil {
 			log.Printf("日志文件位置: %s", p.logger.GetLogFilePath())
 		}
-		// 暂时不删除临时目录，用于调试
-		// return os.RemoveAll(p.workDir)
+		return nil
+	}
+
+	if err := os.RemoveAll(p.workDir); err != nil {
+		return fmt.Errorf("删除临时工作目录失败: %w", err)
 	}
 	return nil
 }
@@ -1010,10 +1196,68 @@ func (p *PDFFlowProcessor) parsePDFStructure() error {
 	startTime := time.Now()
 	p.logger.Info("开始解析PDF结构", nil)
 
-	// 使用pdfcpu解析PDF
+	ctx, err := p.readPDFContext()
+	if err != nil {
+		return err
+	}
+
+	// 解析每一页
+	pageCount := ctx.PageCount
+	p.logger.Info("开始解析页面", map[string]interface{}{
+		"总页数": pageCount,
+	})
+
+	parsed := p.parsePages(ctx, pageCount)
+	if err := p.contextErr(); err != nil {
+		return fmt.Errorf("解析页面中止: %w", err)
+	}
+	for i, pageFlow := range parsed {
+		if pageFlow == nil {
+			// 解析失败的页面以空白页占位，保持页数和页码（链接目标等依赖页码）不变
+			pageFlow = blankPageFlow(i+1, parsed[:i])
+			p.logger.Warn("页面解析失败，以空白页代替", map[string]interface{}{
+				"页码": i + 1,
+			})
+		}
+		p.flowData.Pages = append(p.flowData.Pages, *pageFlow)
+	}
+
+	// 提取表单域（依赖页面尺寸）
+	if err := p.extractFormFields(ctx); err != nil {
+		p.logger.Warn("提取表单域失败", map[string]interface{}{
+			"错误": err.Error(),
+		})
+	}
+
+	totalTime := time.Since(startTime)
+	p.logger.LogOperationTiming("PDF结构解析", totalTime, map[string]interface{}{
+		"页数": len(p.flowData.Pages),
+	})
+
+	p.logger.Info("PDF结构解析完成", map[string]interface{}{
+		"解析页数": len(p.flowData.Pages),
+		"总页数":  pageCount,
+		"总耗时":  totalTime.String(),
+	})
+
+	return nil
+}
+
+// readPDFContext 读取PDF上下文，初始化流数据并提取文档元数据，页面由调用方解析
+func (p *PDFFlowProcessor) readPDFContext() (*model.Context, error) {
+	// 使用pdfcpu解析PDF，读取失败时修复一次后重试
 	ctx, err := api.ReadContextFile(p.inputPath)
 	if err != nil {
-		return fmt.Errorf("读取PDF上下文失败: %w", err)
+		repairedCtx, repairedPath, repairErr := repairAndRetry(p.inputPath, err, api.ReadContextFile)
+		if repairErr != nil {
+			return nil, fmt.Errorf("读取PDF上下文失败: %w", repairErr)
+		}
+		p.logger.Warn("PDF读取失败，已使用修复后的文件", map[string]interface{}{
+			"原始错误": err.Error(),
+			"修复文件": repairedPath,
+		})
+		ctx = repairedCtx
+		p.inputPath = repairedPath
 	}
 
 	// 初始化流数据
@@ -1046,47 +1290,8 @@ func (p *PDFFlowProcessor) parsePDFStructure() error {
 		p.logger.LogFileOperation("读取输入文件", p.inputPath, info.Size())
 	}
 
-	// 解析每一页
-	pageCount := ctx.PageCount
-	p.flowData.Metadata.PageCount = pageCount
-
-	p.logger.Info("开始解析页面", map[string]interface{}{
-		"总页数": pageCount,
-	})
-
-	for pageNum := 1; pageNum <= pageCount; pageNum++ {
-		pageStartTime := time.Now()
-
-		pageFlow, err := p.parsePage(ctx, pageNum)
-		if err != nil {
-			p.logger.Warn("解析页面失败", map[string]interface{}{
-				"页码": pageNum,
-				"错误": err.Error(),
-			})
-			continue
-		}
-
-		p.flowData.Pages = append(p.flowData.Pages, *pageFlow)
-
-		pageTime := time.Since(pageStartTime)
-		p.logger.Debug("页面解析完成", map[string]interface{}{
-			"页码": pageNum,
-			"耗时": pageTime.String(),
-		})
-	}
-
-	totalTime := time.Since(startTime)
-	p.logger.LogOperationTiming("PDF结构解析", totalTime, map[string]interface{}{
-		"页数": len(p.flowData.Pages),
-	})
-
-	p.logger.Info("PDF结构解析完成", map[string]interface{}{
-		"解析页数": len(p.flowData.Pages),
-		"总页数":  pageCount,
-		"总耗时":  totalTime.String(),
-	})
-
-	return nil
+	p.flowData.Metadata.PageCount = ctx.PageCount
+	return ctx, nil
 }
 
 // extractMetadata 提取文档元数据
@@ -1101,62 +1306,162 @@ func (p *PDFFlowProcessor) extractMetadata(ctx *model.Context) error {
 		}
 	}
 
+	// 文档信息字典通常由 trailer 引用
+	if ctx.Info != nil {
+		if infoDict, err := ctx.DereferenceDict(*ctx.Info); err == nil && infoDict != nil {
+			p.extractInfoDict(infoDict)
+		}
+	}
+
 	return nil
 }
 
-// extractInfoDict 提取信息字典
+// setDocumentInfo 将原文档的标题、作者等信息写入生成的PDF，并注明机器翻译信息
+func (p *PDFFlowProcessor) setDocumentInfo(pdf *gofpdf.Fpdf) {
+	meta := p.flowData.Metadata
+
+	if meta.Title != "" {
+		pdf.SetTitle(meta.Title, true)
+	}
+	if meta.Author != "" {
+		pdf.SetAuthor(meta.Author, true)
+	}
+	if meta.Subject != "" {
+		pdf.SetSubject(meta.Subject, true)
+	}
+	if meta.Creator != "" {
+		pdf.SetCreator(meta.Creator, true)
+	}
+	if !meta.CreationDate.IsZero() {
+		pdf.SetCreationDate(meta.CreationDate)
+	}
+
+	now := time.Now()
+	pdf.SetModificationDate(now)
+
+	producer := "translator-web (machine translated"
+	if p.TargetLanguage != "" {
+		producer += " to " + p.TargetLanguage
+	}
+	producer += ", " + now.Format(time.RFC3339) + ")"
+	pdf.SetProducer(producer, true)
+}
+
+// extractInfoDict 提取信息字典，文本字符串可能是 UTF-16BE 或 PDFDocEncoding 编码，统一解码为 UTF-8
 func (p *PDFFlowProcessor) extractInfoDict(infoDict types.Dict) {
-	if title, found := infoDict.Find("Title"); found {
-		if titleStr, ok := title.(types.StringLiteral); ok {
-			p.flowData.Metadata.Title = string(titleStr)
+	fields := []struct {
+		key    string
+		target *string
+	}{
+		{"Title", &p.flowData.Metadata.Title},
+		{"Author", &p.flowData.Metadata.Author},
+		{"Subject", &p.flowData.Metadata.Subject},
+		{"Creator", &p.flowData.Metadata.Creator},
+		{"Producer", &p.flowData.Metadata.Producer},
+	}
+	for _, field := range fields {
+		if obj, found := infoDict.Find(field.key); found {
+			if value, ok := pdfTextStringValue(obj); ok {
+				*field.target = value
+			}
 		}
 	}
+}
 
-	if author, found := infoDict.Find("Author"); found {
-		if authorStr, ok := author.(types.StringLiteral); ok {
-			p.flowData.Metadata.Author = string(authorStr)
-		}
+// parsePage 解析单个页面
+// parsePages 使用有限的工作协程并行解析所有页面，结果按页码顺序返回（解析失败的页面为 nil）
+func (p *PDFFlowProcessor) parsePages(ctx *model.Context, pageCount int) []*PDFPageFlow {
+	return p.parsePageRange(ctx, 1, pageCount)
+}
+
+// parsePageRange 并行解析 first~last 页，结果按页码排列（下标 0 为第 first 页），解析失败的页面为 nil
+func (p *PDFFlowProcessor) parsePageRange(ctx *model.Context, first, last int) []*PDFPageFlow {
+	count := last - first + 1
+	if count <= 0 {
+		return nil
 	}
+	results := make([]*PDFPageFlow, count)
 
-	if subject, found := infoDict.Find("Subject"); found {
-		if subjectStr, ok := subject.(types.StringLiteral); ok {
-			p.flowData.Metadata.Subject = string(subjectStr)
-		}
+	workers := runtime.GOMAXPROCS(0)
+	if workers > count {
+		workers = count
+	}
+
+	pages := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for pageNum := range pages {
+				pageStartTime := time.Now()
+
+				pageFlow, err := p.parsePage(ctx, pageNum)
+				if err != nil {
+					p.logger.Warn("解析页面失败", map[string]interface{}{
+						"页码": pageNum,
+						"错误": err.Error(),
+					})
+					continue
+				}
+				results[pageNum-first] = pageFlow
+
+				p.logger.Debug("页面解析完成", map[string]interface{}{
+					"页码": pageNum,
+					"耗时": time.Since(pageStartTime).String(),
+				})
+			}
+		}()
 	}
 
-	if creator, found := infoDict.Find("Creator"); found {
-		if creatorStr, ok := creator.(types.StringLiteral); ok {
-			p.flowData.Metadata.Creator = string(creatorStr)
+	for pageNum := first; pageNum <= last; pageNum++ {
+		// 任务已取消或超时，不再分发剩余页面
+		if p.contextErr() != nil {
+			break
 		}
+		pages <- pageNum
 	}
+	close(pages)
+	wg.Wait()
+
+	return results
+}
 
-	if producer, found := infoDict.Find("Producer"); found {
-		if producerStr, ok := producer.(types.StringLiteral); ok {
-			p.flowData.Metadata.Producer = string(producerStr)
+// blankPageFlow 创建空白页面，尺寸沿用之前最近一个解析成功的页面，都没有时生成时使用默认的A4
+func blankPageFlow(pageNum int, previous []*PDFPageFlow) *PDFPageFlow {
+	page := &PDFPageFlow{
+		PageNumber:       pageNum,
+		TextElements:     make([]TextElementFlow, 0),
+		ImageElements:    make([]ImageElementFlow, 0),
+		GraphicsElements: make([]GraphicsElementFlow, 0),
+		Annotations:      make([]AnnotationFlow, 0),
+		ContentStreams:   make([]ContentStreamFlow, 0),
+	}
+	for i := len(previous) - 1; i >= 0; i-- {
+		if previous[i] != nil {
+			page.MediaBox = previous[i].MediaBox
+			page.Rotation = previous[i].Rotation
+			break
 		}
 	}
+	return page
 }
 
-// parsePage 解析单个页面
+// parsePage 解析单个页面，对 pdfcpu 上下文的访问通过 ctxMu 串行化
 func (p *PDFFlowProcessor) parsePage(ctx *model.Context, pageNum int) (*PDFPageFlow, error) {
 	p.logger.Debug("开始解析页面", map[string]interface{}{
 		"页码": pageNum,
 	})
 
 	// 获取页面字典
-	pageDict, _, _, err := ctx.PageDict(pageNum, false)
+	p.ctxMu.Lock()
+	pageDict, _, inheritedAttrs, err := ctx.PageDict(pageNum, false)
+	p.ctxMu.Unlock()
 	if err != nil {
 		return nil, fmt.Errorf("获取页面字典失败: %w", err)
 	}
 
-	pageFlow := &PDFPageFlow{
-		PageNumber:       pageNum,
-		TextElements:     make([]TextElementFlow, 0),
-		ImageElements:    make([]ImageElementFlow, 0),
-		GraphicsElements: make([]GraphicsElementFlow, 0),
-		Annotations:      make([]AnnotationFlow, 0),
-		ContentStreams:   make([]ContentStreamFlow, 0),
-	}
+	pageFlow := blankPageFlow(pageNum, nil)
 
 	// 提取页面边界
 	if err := p.extractPageBounds(pageDict, pageFlow); err != nil {
@@ -1165,6 +1470,16 @@ func (p *PDFFlowProcessor) parsePage(ctx *model.Context, pageNum int) (*PDFPageF
 			"错误": err.Error(),
 		})
 	}
+	// 页面未定义 MediaBox 时使用从页面树继承的 MediaBox
+	if pageFlow.MediaBox.Height <= 0 && inheritedAttrs != nil && inheritedAttrs.MediaBox != nil {
+		mediaBox := inheritedAttrs.MediaBox
+		pageFlow.MediaBox = BoundingBox{
+			X:      mediaBox.LL.X,
+			Y:      mediaBox.LL.Y,
+			Width:  mediaBox.Width(),
+			Height: mediaBox.Height(),
+		}
+	}
 
 	// 提取内容流
 	streamStart := time.Now()
@@ -1180,9 +1495,18 @@ func (p *PDFFlowProcessor) parsePage(ctx *model.Context, pageNum int) (*PDFPageF
 		"耗时":  time.Since(streamStart).String(),
 	})
 
+	// 提取页面字体资源（用于字宽计算）
+	var inheritedResources types.Dict
+	if inheritedAttrs != nil {
+		inheritedResources = inheritedAttrs.Resources
+	}
+	p.ctxMu.Lock()
+	fonts := p.extractFontResources(ctx, pageDict, inheritedResources)
+	p.ctxMu.Unlock()
+
 	// 解析内容流中的元素
 	parseStart := time.Now()
-	if err := p.parseContentElements(pageFlow); err != nil {
+	if err := p.parseContentElements(pageFlow, fonts); err != nil {
 		p.logger.Warn("解析内容元素失败", map[string]interface{}{
 			"页码": pageNum,
 			"错误": err.Error(),
@@ -1197,7 +1521,10 @@ func (p *PDFFlowProcessor) parsePage(ctx *model.Context, pageNum int) (*PDFPageF
 	})
 
 	// 提取注释
-	if err := p.extractAnnotations(ctx, pageDict, pageFlow); err != nil {
+	p.ctxMu.Lock()
+	err = p.extractAnnotations(ctx, pageDict, pageFlow)
+	p.ctxMu.Unlock()
+	if err != nil {
 		p.logger.Warn("提取注释失败", map[string]interface{}{
 			"页码": pageNum,
 			"错误": err.Error(),
@@ -1278,6 +1605,113 @@ func (p *PDFFlowProcessor) extractPageBounds(pageDict types.Dict, pageFlow *PDFP
 	return nil
 }
 
+// extractFontResources 提取页面资源字典中的字体，键为资源名（不含前导斜杠）
+func (p *PDFFlowProcessor) extractFontResources(ctx *model.Context, pageDict, inheritedResources types.Dict) map[string]FontResource {
+	fonts := make(map[string]FontResource)
+
+	resources := inheritedResources
+	if resObj, found := pageDict.Find("Resources"); found {
+		if dict, err := ctx.DereferenceDict(resObj); err == nil && dict != nil {
+			resources = dict
+		}
+	}
+	if resources == nil {
+		return fonts
+	}
+
+	fontObj, found := resources.Find("Font")
+	if !found {
+		return fonts
+	}
+	fontDict, err := ctx.DereferenceDict(fontObj)
+	if err != nil || fontDict == nil {
+		return fonts
+	}
+
+	for name, obj := range fontDict {
+		dict, err := ctx.DereferenceDict(obj)
+		if err != nil || dict == nil {
+			continue
+		}
+
+		font := FontResource{Name: name, Type: "Font"}
+		if subtype := dict.NameEntry("Subtype"); subtype != nil {
+			font.Subtype = *subtype
+		}
+		if baseFont := dict.NameEntry("BaseFont"); baseFont != nil {
+			font.BaseFont = *baseFont
+		}
+		if firstChar := dict.IntEntry("FirstChar"); firstChar != nil {
+			font.FirstChar = *firstChar
+		}
+		if lastChar := dict.IntEntry("LastChar"); lastChar != nil {
+			font.LastChar = *lastChar
+		}
+		if encoding := dict.NameEntry("Encoding"); encoding != nil {
+			font.Encoding = *encoding
+			font.Vertical = strings.HasSuffix(font.Encoding, "-V")
+		} else if encodingObj, found := dict.Find("Encoding"); found {
+			// 嵌入的 CMap 流通过 WMode 声明书写方向
+			if streamDict, _, err := ctx.DereferenceStreamDict(encodingObj); err == nil && streamDict != nil {
+				if wmode := streamDict.IntEntry("WMode"); wmode != nil && *wmode == 1 {
+					font.Vertical = true
+				}
+			}
+		}
+		if toUnicodeObj, found := dict.Find("ToUnicode"); found {
+			if streamDict, _, err := ctx.DereferenceStreamDict(toUnicodeObj); err == nil && streamDict != nil {
+				if content, err := p.decodeStreamContent(streamDict); err == nil {
+					font.ToUnicode = content
+				}
+			}
+		}
+		if widthsObj, found := dict.Find("Widths"); found {
+			if widths, err := ctx.DereferenceArray(widthsObj); err == nil {
+				for _, w := range widths {
+					width, err := ctx.DereferenceNumber(w)
+					if err != nil {
+						width = 0
+					}
+					font.Widths = append(font.Widths, width)
+				}
+			}
+		}
+
+		fonts[name] = font
+	}
+
+	return fonts
+}
+
+// fontCharWidths 将字体资源的 Widths 数组转换为字符宽度表（千分之一文本空间单位）
+func fontCharWidths(font FontResource) map[rune]float64 {
+	if len(font.Widths) == 0 {
+		return nil
+	}
+
+	widths := make(map[rune]float64, len(font.Widths))
+	for i, w := range font.Widths {
+		if w > 0 {
+			widths[rune(font.FirstChar+i)] = w
+		}
+	}
+	return widths
+}
+
+// fontToUnicode 解析字体资源的 ToUnicode CMap
+// Type0/Identity 编码的字体即使 CMap 未声明 codespacerange 也按双字节编码处理
+func fontToUnicode(font FontResource) *ToUnicodeCMap {
+	if font.ToUnicode == "" {
+		return nil
+	}
+
+	cmap := ParseToUnicodeCMap(font.ToUnicode)
+	if cmap != nil && cmap.CodeLength == 1 && (font.Subtype == "Type0" || strings.HasPrefix(font.Encoding, "Identity")) {
+		cmap.CodeLength = 2
+	}
+	return cmap
+}
+
 // getFloatValue 获取浮点值
 func (p *PDFFlowProcessor) getFloatValue(obj types.Object) float64 {
 	switch v := obj.(type) {
@@ -1291,12 +1725,29 @@ func (p *PDFFlowProcessor) getFloatValue(obj types.Object) float64 {
 }
 
 // extractContentStreams 提取内容流
+// 没有 Contents、Contents 为 null 或空数组的页面是合法的空白页面，ContentStreams 保持为空
 func (p *PDFFlowProcessor) extractContentStreams(ctx *model.Context, pageDict types.Dict, pageFlow *PDFPageFlow) error {
 	contentsObj, found := pageDict.Find("Contents")
-	if !found {
+	if !found || contentsObj == nil {
 		return nil
 	}
 
+	// Contents 也可以是指向内容流数组的间接引用
+	if ref, ok := contentsObj.(types.IndirectRef); ok {
+		p.ctxMu.Lock()
+		obj, err := ctx.Dereference(ref)
+		p.ctxMu.Unlock()
+		if err != nil {
+			return fmt.Errorf("解引用内容流失败: %w", err)
+		}
+		if obj == nil {
+			return nil
+		}
+		if arr, ok := obj.(types.Array); ok {
+			contentsObj = arr
+		}
+	}
+
 	switch obj := contentsObj.(type) {
 	case types.IndirectRef:
 		// 单个内容流
@@ -1325,15 +1776,11 @@ func (p *PDFFlowProcessor) extractContentStreams(ctx *model.Context, pageDict ty
 
 // extractSingleContentStream 提取单个内容流
 func (p *PDFFlowProcessor) extractSingleContentStream(ctx *model.Context, ref types.IndirectRef, index int) (*ContentStreamFlow, error) {
-	streamDict, _, err := ctx.DereferenceStreamDict(ref)
-	if err != nil {
-		return nil, fmt.Errorf("解引用内容流失败: %w", err)
-	}
-
-	// 解码流内容
-	content, err := p.decodeStreamContent(streamDict)
+	p.ctxMu.Lock()
+	content, err := p.readContentStream(ctx, ref)
+	p.ctxMu.Unlock()
 	if err != nil {
-		return nil, fmt.Errorf("解码流内容失败: %w", err)
+		return nil, err
 	}
 
 	// 解析PDF操作符
@@ -1354,6 +1801,25 @@ func (p *PDFFlowProcessor) extractSingleContentStream(ctx *model.Context, ref ty
 	return stream, nil
 }
 
+// readContentStream 解引用并解码内容流
+func (p *PDFFlowProcessor) readContentStream(ctx *model.Context, ref types.IndirectRef) (string, error) {
+	streamDict, _, err := ctx.DereferenceStreamDict(ref)
+	if err != nil {
+		return "", fmt.Errorf("解引用内容流失败: %w", err)
+	}
+	// 指向 null 或长度为 0 的内容流视为空内容
+	if streamDict == nil || (streamDict.Content == nil && len(streamDict.Raw) == 0) {
+		return "", nil
+	}
+
+	// 解码流内容
+	content, err := p.decodeStreamContent(streamDict)
+	if err != nil {
+		return "", fmt.Errorf("解码流内容失败: %w", err)
+	}
+	return content, nil
+}
+
 // decodeStreamContent 解码流内容
 func (p *PDFFlowProcessor) decodeStreamContent(streamDict *types.StreamDict) (string, error) {
 	if streamDict.Content == nil {
@@ -1504,6 +1970,14 @@ func (p *PDFFlowProcessor) tokenizePDFContent(content string) []string {
 				if current.Len() > 0 {
 					tokens = append(tokens, current.String())
 					current.Reset()
+
+					// 内联图像：ID 与 EI 之间是原始二进制数据，整体作为一个标记，避免被误解析
+					if tokens[len(tokens)-1] == "ID" && inInlineImage(tokens) {
+						data, next := readInlineImageData(content, i+1)
+						tokens = append(tokens, data, "EI")
+						i = next
+						continue
+					}
 				}
 			} else {
 				current.WriteByte(char)
@@ -1514,12 +1988,53 @@ func (p *PDFFlowProcessor) tokenizePDFContent(content string) []string {
 		i++
 	}
 
-	// 添加最后一个标记
-	if current.Len() > 0 {
-		tokens = append(tokens, current.String())
+	// 添加最后一个标记
+	if current.Len() > 0 {
+		tokens = append(tokens, current.String())
+	}
+
+	return tokens
+}
+
+// inInlineImage 判断刚读到的 ID 是否属于内联图像（BI 之后尚未遇到其他操作符）
+func inInlineImage(tokens []string) bool {
+	for i := len(tokens) - 2; i >= 0; i-- {
+		switch tokens[i] {
+		case "BI":
+			return true
+		case "EI", "Do", "Tj", "TJ", "ET", "Q":
+			return false
+		}
+	}
+	return false
+}
+
+// readInlineImageData 读取内联图像数据，返回数据和 EI 之后的位置
+// EI 前后必须是空白字符（或内容结尾），以免把数据中的 "EI" 字节误判为结束标记
+func readInlineImageData(content string, start int) (string, int) {
+	isSpace := func(c byte) bool {
+		return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == 0
+	}
+
+	for pos := start; pos+2 <= len(content); pos++ {
+		if content[pos] != 'E' || content[pos+1] != 'I' {
+			continue
+		}
+		if pos > start && !isSpace(content[pos-1]) {
+			continue
+		}
+		if pos+2 < len(content) && !isSpace(content[pos+2]) {
+			continue
+		}
+
+		end := pos
+		if end > start && isSpace(content[end-1]) {
+			end--
+		}
+		return content[start:end], pos + 2
 	}
 
-	return tokens
+	return content[start:], len(content)
 }
 
 // findNextOperator 查找下一个PDF操作符
@@ -1649,16 +2164,22 @@ func (p *PDFFlowProcessor) splitPDFOperationLine(line string) []string {
 }
 
 // parseContentElements 解析内容元素
-func (p *PDFFlowProcessor) parseContentElements(pageFlow *PDFPageFlow) error {
+func (p *PDFFlowProcessor) parseContentElements(pageFlow *PDFPageFlow, fonts map[string]FontResource) error {
 	textElementID := 0
 	imageElementID := 0
 	graphicsElementID := 0
 
+	// 已解析的 ToUnicode 映射，按字体资源名缓存
+	cmaps := make(map[string]*ToUnicodeCMap)
+
 	// 当前状态
 	currentTransform := TransformMatrix{A: 1, D: 1} // 单位矩阵
+	textMatrix := TransformMatrix{A: 1, D: 1}       // 文本矩阵（Tm），决定下一段文本的位置
+	lineMatrix := TransformMatrix{A: 1, D: 1}       // 文本行矩阵，Td、T* 等相对它换行
 	currentTextState := TextStateFlow{Scale: 1.0}
 	currentFont := FontFlow{Name: "default", Size: 12}
 	currentColor := ColorFlow{Space: "RGB", Values: []float64{0, 0, 0}, Alpha: 1.0}
+	var inlineImageParams []string // 当前内联图像的参数
 
 	// 状态栈
 	type State struct {
@@ -1692,9 +2213,42 @@ func (p *PDFFlowProcessor) parseContentElements(pageFlow *PDFPageFlow) error {
 					currentColor = state.Color
 				}
 
+			case "BT":
+				// 文本对象开始，文本矩阵和行矩阵重置为单位矩阵
+				textMatrix = TransformMatrix{A: 1, D: 1}
+				lineMatrix = textMatrix
+
+			case "Td", "TD":
+				// 相对当前行起点移动，TD 同时把行间距设为 -ty
+				if len(op.Operands) >= 2 {
+					tx, _ := p.parseFloat(op.Operands[0])
+					ty, _ := p.parseFloat(op.Operands[1])
+					if op.Operator == "TD" {
+						currentTextState.Leading = -ty
+					}
+					lineMatrix = p.multiplyMatrices(TransformMatrix{A: 1, D: 1, E: tx, F: ty}, lineMatrix)
+					textMatrix = lineMatrix
+				}
+
+			case "Tm":
+				// 直接设置文本矩阵
+				if len(op.Operands) >= 6 {
+					lineMatrix = p.parseTransformMatrix(op.Operands)
+					textMatrix = lineMatrix
+				}
+
+			case "T*":
+				// 按行间距移到下一行
+				lineMatrix = p.multiplyMatrices(TransformMatrix{A: 1, D: 1, F: -currentTextState.Leading}, lineMatrix)
+				textMatrix = lineMatrix
+
 			case "Tj", "TJ", "'", "\"":
-				// 文本显示操作符
-				element, err := p.parseTextElement(op, textElementID, currentTransform, currentTextState, currentFont, currentColor)
+				// 文本显示操作符，' 和 " 先移到下一行
+				if op.Operator == "'" || op.Operator == "\"" {
+					lineMatrix = p.multiplyMatrices(TransformMatrix{A: 1, D: 1, F: -currentTextState.Leading}, lineMatrix)
+					textMatrix = lineMatrix
+				}
+				element, err := p.parseTextElement(op, textElementID, p.multiplyMatrices(textMatrix, currentTransform), currentTextState, currentFont, currentColor)
 				if err != nil {
 					log.Printf("警告：解析文本元素失败: %v", err)
 					continue
@@ -1702,6 +2256,8 @@ func (p *PDFFlowProcessor) parseContentElements(pageFlow *PDFPageFlow) error {
 				if element != nil {
 					pageFlow.TextElements = append(pageFlow.TextElements, *element)
 					textElementID++
+					// 文本显示后，下一段文本紧接在其后
+					textMatrix = p.multiplyMatrices(TransformMatrix{A: 1, D: 1, E: element.BoundingBox.Width}, textMatrix)
 				}
 
 			case "Do":
@@ -1716,6 +2272,21 @@ func (p *PDFFlowProcessor) parseContentElements(pageFlow *PDFPageFlow) error {
 					imageElementID++
 				}
 
+			case "ID":
+				// 内联图像参数（BI 与 ID 之间的字典项）
+				inlineImageParams = op.Operands
+
+			case "EI":
+				// 内联图像数据结束
+				var data string
+				if len(op.Operands) > 0 {
+					data = op.Operands[0]
+				}
+				element := p.parseInlineImageElement(inlineImageParams, data, imageElementID, currentTransform)
+				pageFlow.ImageElements = append(pageFlow.ImageElements, *element)
+				imageElementID++
+				inlineImageParams = nil
+
 			case "m", "l", "c", "v", "y", "h", "re", "S", "s", "f", "F", "f*", "B", "B*", "b", "b*", "n":
 				// 图形操作符
 				element, err := p.parseGraphicsElement(op, graphicsElementID, currentTransform)
@@ -1743,6 +2314,18 @@ func (p *PDFFlowProcessor) parseContentElements(pageFlow *PDFPageFlow) error {
 					if size, err := p.parseFloat(op.Operands[1]); err == nil {
 						currentFont.Size = size
 					}
+					currentFont.CharWidths = nil
+					currentFont.ToUnicode = nil
+					currentFont.Vertical = false
+					fontName := strings.TrimPrefix(op.Operands[0], "/")
+					if res, ok := fonts[fontName]; ok {
+						currentFont.CharWidths = fontCharWidths(res)
+						currentFont.Vertical = res.Vertical
+						if _, parsed := cmaps[fontName]; !parsed {
+							cmaps[fontName] = fontToUnicode(res)
+						}
+						currentFont.ToUnicode = cmaps[fontName]
+					}
 				}
 
 			case "Tc":
@@ -1769,6 +2352,14 @@ func (p *PDFFlowProcessor) parseContentElements(pageFlow *PDFPageFlow) error {
 					}
 				}
 
+			case "Ts":
+				// 文本上升（上下标）
+				if len(op.Operands) >= 1 {
+					if rise, err := p.parseFloat(op.Operands[0]); err == nil {
+						currentTextState.Rise = rise
+					}
+				}
+
 			case "TL":
 				// 行间距
 				if len(op.Operands) >= 1 {
@@ -1806,7 +2397,7 @@ func (p *PDFFlowProcessor) parseTextElement(op PDFOperation, id int, transform T
 	}
 
 	// 提取文本内容
-	content := p.extractTextFromOperands(op.Operands, op.Operator)
+	content := p.extractTextFromOperands(op.Operands, op.Operator, font)
 
 	// 记录文本提取日志
 	p.logger.Debug("解析文本元素", map[string]interface{}{
@@ -1870,16 +2461,18 @@ func (p *PDFFlowProcessor) parseTextElement(op PDFOperation, id int, transform T
 			X: posX,
 			Y: posY,
 		},
-		Font:         font,
-		Color:        color,
-		Transform:    transform,
-		BoundingBox:  bounds,
-		TextState:    textState,
-		IsFormula:    p.isFormula(content, font.Name),
-		Language:     p.detectLanguage(content),
-		Confidence:   1.0,
-		OriginalOps:  []string{fmt.Sprintf("%s %s", strings.Join(op.Operands, " "), op.Operator)},
-		Dependencies: make([]string, 0),
+		Font:           font,
+		Color:          color,
+		Transform:      transform,
+		BoundingBox:    bounds,
+		TextState:      textState,
+		IsFormula:      p.isFormula(content, font.Name),
+		Language:       p.detectLanguage(content),
+		Confidence:     1.0,
+		OriginalOps:    []string{fmt.Sprintf("%s %s", strings.Join(op.Operands, " "), op.Operator)},
+		Dependencies:   make([]string, 0),
+		ScriptPosition: p.detectScriptPosition(textState.Rise),
+		Vertical:       font.Vertical || isVerticalTransform(transform),
 	}
 
 	// 更新边界框的位置
@@ -1900,6 +2493,66 @@ func (p *PDFFlowProcessor) parseTextElement(op PDFOperation, id int, transform T
 	return element, nil
 }
 
+// detectScriptPosition 根据文本上升值判断上下标
+func (p *PDFFlowProcessor) detectScriptPosition(rise float64) string {
+	switch {
+	case rise > 0:
+		return ScriptSuperscript
+	case rise < 0:
+		return ScriptSubscript
+	default:
+		return ""
+	}
+}
+
+// parseInlineImageElement 根据内联图像参数（BI ... ID）和数据创建图像元素
+// 内联图像绘制在单位正方形内，实际位置和尺寸由当前变换矩阵决定
+func (p *PDFFlowProcessor) parseInlineImageElement(params []string, data string, id int, transform TransformMatrix) *ImageElementFlow {
+	element := &ImageElementFlow{
+		ID:   fmt.Sprintf("image_%d", id),
+		Name: fmt.Sprintf("inline_%d", id),
+		Position: PositionFlow{
+			X: transform.E,
+			Y: transform.F,
+		},
+		Size: SizeFlow{
+			Width:  transform.A,
+			Height: transform.D,
+		},
+		Transform:   transform,
+		BoundingBox: BoundingBox{X: transform.E, Y: transform.F, Width: transform.A, Height: transform.D},
+		Format:      "raw",
+		DataSize:    int64(len(data)),
+		Inline:      true,
+	}
+
+	// 参数按 键 值 成对出现，键可能使用缩写形式
+	for i := 0; i+1 < len(params); i += 2 {
+		value := params[i+1]
+		switch params[i] {
+		case "/W", "/Width":
+			element.Width, _ = strconv.Atoi(value)
+		case "/H", "/Height":
+			element.Height, _ = strconv.Atoi(value)
+		case "/BPC", "/BitsPerComponent":
+			element.BitsPerComponent, _ = strconv.Atoi(value)
+		case "/CS", "/ColorSpace":
+			element.ColorSpace = strings.TrimPrefix(value, "/")
+		case "/F", "/Filter":
+			element.Format = strings.Trim(value, "/[] ")
+		}
+	}
+
+	p.logger.Debug("创建内联图像元素", map[string]interface{}{
+		"ID":   element.ID,
+		"宽度":   element.Width,
+		"高度":   element.Height,
+		"数据大小": element.DataSize,
+	})
+
+	return element
+}
+
 // parseImageElement 解析图像元素
 func (p *PDFFlowProcessor) parseImageElement(op PDFOperation, id int, transform TransformMatrix) (*ImageElementFlow, error) {
 	if len(op.Operands) == 0 {
@@ -1978,7 +2631,7 @@ func (p *PDFFlowProcessor) parseGraphicsElement(op PDFOperation, id int, transfo
 }
 
 // extractTextFromOperands 从操作数中提取文本
-func (p *PDFFlowProcessor) extractTextFromOperands(operands []string, operator string) string {
+func (p *PDFFlowProcessor) extractTextFromOperands(operands []string, operator string, font FontFlow) string {
 	if len(operands) == 0 {
 		p.logger.Debug("操作数为空", map[string]interface{}{
 			"操作符": operator,
@@ -2002,7 +2655,7 @@ func (p *PDFFlowProcessor) extractTextFromOperands(operands []string, operator s
 	switch operator {
 	case "Tj":
 		// 简单文本显示: (text) Tj
-		result = p.cleanPDFText(operands[0])
+		result = p.decodeTextOperand(operands[0], font)
 		p.logger.Debug("Tj操作符提取结果", map[string]interface{}{
 			"原始":  p.logger.truncateString(operands[0], 100),
 			"清理后": p.logger.truncateString(result, 100),
@@ -2010,7 +2663,7 @@ func (p *PDFFlowProcessor) extractTextFromOperands(operands []string, operator s
 
 	case "TJ":
 		// 数组文本显示: [(text1) offset (text2) ...] TJ
-		result = p.extractTextFromTJArray(operands[0])
+		result = p.extractTextFromTJArray(operands[0], font)
 		p.logger.Debug("TJ操作符提取结果", map[string]interface{}{
 			"原始":  p.logger.truncateString(operands[0], 100),
 			"清理后": p.logger.truncateString(result, 100),
@@ -2018,7 +2671,7 @@ func (p *PDFFlowProcessor) extractTextFromOperands(operands []string, operator s
 
 	case "'":
 		// 移动到下一行并显示文本: (text) '
-		result = p.cleanPDFText(operands[0])
+		result = p.decodeTextOperand(operands[0], font)
 		p.logger.Debug("'操作符提取结果", map[string]interface{}{
 			"原始":  p.logger.truncateString(operands[0], 100),
 			"清理后": p.logger.truncateString(result, 100),
@@ -2027,7 +2680,7 @@ func (p *PDFFlowProcessor) extractTextFromOperands(operands []string, operator s
 	case "\"":
 		// 设置词间距、字符间距并显示文本: aw ac (text) "
 		if len(operands) >= 3 {
-			result = p.cleanPDFText(operands[2])
+			result = p.decodeTextOperand(operands[2], font)
 			p.logger.Debug("\"操作符提取结果", map[string]interface{}{
 				"词间距":  operands[0],
 				"字符间距": operands[1],
@@ -2091,34 +2744,8 @@ func (p *PDFFlowProcessor) cleanPDFText(text string) string {
 	text = strings.ReplaceAll(text, "\\r", "\r")
 	text = strings.ReplaceAll(text, "\\t", "\t")
 
-	// 处理特殊字符编码 - 扩展更多常见的PDF字符编码
-	replacements := map[string]string{
-		"\\002": "ﬁ",  // fi连字
-		"\\003": "ﬂ",  // fl连字
-		"\\001": "ﬀ",  // ff连字
-		"\\004": "ﬃ",  // ffi连字
-		"\\005": "ﬄ",  // ffl连字
-		"\\013": "–",  // en dash
-		"\\014": "—",  // em dash
-		"\\015": "'",  // left single quotation mark
-		"\\016": "'",  // right single quotation mark
-		"\\017": "\"", // left double quotation mark
-		"\\020": "\"", // right double quotation mark
-		"\\021": "•",  // bullet
-		"\\022": "…",  // horizontal ellipsis
-		"\\050": "(",  // left parenthesis
-		"\\051": ")",  // right parenthesis
-		"\\052": "*",  // asterisk
-		"\\053": "+",  // plus sign
-		"\\054": ",",  // comma
-		"\\055": "-",  // hyphen-minus
-		"\\056": ".",  // full stop
-		"\\057": "/",  // solidus
-	}
-
-	for encoded, decoded := range replacements {
-		text = strings.ReplaceAll(text, encoded, decoded)
-	}
+	// 处理特殊字符编码（可通过 CharacterMap 配置）
+	text = p.characterMap().Apply(text)
 
 	// 处理八进制编码
 	text = p.decodeOctalEscapes(text)
@@ -2171,7 +2798,7 @@ func (p *PDFFlowProcessor) isCompletelyWrapped(text string, open, close rune) bo
 }
 
 // extractTextFromTJArray 从TJ数组中提取文本 - 改进版本，正确处理间距
-func (p *PDFFlowProcessor) extractTextFromTJArray(arrayStr string) string {
+func (p *PDFFlowProcessor) extractTextFromTJArray(arrayStr string, font FontFlow) string {
 	if arrayStr == "" {
 		return ""
 	}
@@ -2213,7 +2840,7 @@ func (p *PDFFlowProcessor) extractTextFromTJArray(arrayStr string) string {
 				cleanedText := p.cleanPDFText(textContent)
 				if cleanedText != "" {
 					// 改进的间距逻辑：基于偏移量和文本内容决定是否添加空格
-					shouldAddSpace := p.shouldAddSpaceBetweenTexts(result.String(), cleanedText, lastOffset, lastWasText)
+					shouldAddSpace := p.shouldAddSpaceBetweenTexts(result.String(), cleanedText, lastOffset, lastWasText, font)
 					if shouldAddSpace {
 						result.WriteString(" ")
 					}
@@ -2244,8 +2871,8 @@ func (p *PDFFlowProcessor) extractTextFromTJArray(arrayStr string) string {
 			if inAngleBrackets == 0 {
 				// 处理十六进制文本
 				hexText := current.String()
-				if decoded := p.hexToText(hexText); decoded != "" {
-					shouldAddSpace := p.shouldAddSpaceBetweenTexts(result.String(), decoded, lastOffset, lastWasText)
+				if decoded := p.decodeHexText(hexText, font); decoded != "" {
+					shouldAddSpace := p.shouldAddSpaceBetweenTexts(result.String(), decoded, lastOffset, lastWasText, font)
 					if shouldAddSpace {
 						result.WriteString(" ")
 					}
@@ -2281,9 +2908,10 @@ func (p *PDFFlowProcessor) extractTextFromTJArray(arrayStr string) string {
 				}
 				i-- // 因为循环末尾会i++
 
-				// 尝试解析偏移量
-				if offset := p.parseOffset(offsetStr); offset != 0 {
-					lastOffset = offset
+				// 解析偏移量（偏移为0时同样记录，避免沿用上一个偏移量）
+				offset := p.parseOffset(offsetStr)
+				lastOffset = offset
+				if offset != 0 {
 					p.logger.Debug("解析偏移量", map[string]interface{}{
 						"偏移字符串": offsetStr,
 						"偏移值":   offset,
@@ -2310,8 +2938,9 @@ func (p *PDFFlowProcessor) extractTextFromTJArray(arrayStr string) string {
 }
 
 // shouldAddSpaceBetweenTexts 智能判断是否应该在两个文本片段之间添加空格
-func (p *PDFFlowProcessor) shouldAddSpaceBetweenTexts(previousText, currentText string, lastOffset float64, lastWasText bool) bool {
-	if !lastWasText || len(previousText) == 0 || len(currentText) == 0 {
+// 片段之间有TJ偏移量时按字体空格宽度判断，相邻片段则按字符类型判断
+func (p *PDFFlowProcessor) shouldAddSpaceBetweenTexts(previousText, currentText string, lastOffset float64, lastWasText bool, font FontFlow) bool {
+	if len(previousText) == 0 || len(currentText) == 0 {
 		return false
 	}
 
@@ -2326,9 +2955,14 @@ func (p *PDFFlowProcessor) shouldAddSpaceBetweenTexts(previousText, currentText
 	lastChar := prevRunes[len(prevRunes)-1]
 	firstChar := currRunes[0]
 
-	// 1. 如果偏移量很大（通常表示需要空格），添加空格
-	if lastOffset < -100 { // 负偏移量通常表示间距
-		return true
+	// 已有空白时不重复添加
+	if lastChar == ' ' || firstChar == ' ' {
+		return false
+	}
+
+	// 1. 片段之间有偏移量：间距达到空格宽度阈值时添加空格，否则视为字距调整
+	if !lastWasText {
+		return p.isTJWordGap(lastOffset, font)
 	}
 
 	// 2. 标点符号规则
@@ -2363,6 +2997,41 @@ func (p *PDFFlowProcessor) shouldAddSpaceBetweenTexts(previousText, currentText
 	return false
 }
 
+// TJ 偏移量换算为空格的阈值参数
+const (
+	defaultSpaceWidthFactor = 0.25 // 无字宽信息时，空格宽度约为字号的 1/4
+	tjSpaceGapRatio         = 0.5  // 间距达到空格宽度的一半视为词间距
+	minTJSpaceGap           = 1.0  // 小于 1pt 的间距视为字距调整
+)
+
+// spaceWidthFactor 返回字体空格字形宽度与字号的比值
+func (p *PDFFlowProcessor) spaceWidthFactor(font FontFlow) float64 {
+	if w, ok := font.CharWidths[' ']; ok && w > 0 {
+		return w / 1000
+	}
+	return defaultSpaceWidthFactor
+}
+
+// isTJWordGap 判断TJ数组中的偏移量是否构成词间距
+// 偏移量以千分之一文本空间单位表示，负值表示向右移动；换算为实际间距后与字体空格宽度比较
+func (p *PDFFlowProcessor) isTJWordGap(offset float64, font FontFlow) bool {
+	if offset >= 0 {
+		return false
+	}
+
+	fontSize := font.Size
+	if fontSize <= 0 {
+		fontSize = 12
+	}
+
+	gap := -offset / 1000 * fontSize
+	threshold := fontSize * p.spaceWidthFactor(font) * tjSpaceGapRatio
+	if threshold < minTJSpaceGap {
+		threshold = minTJSpaceGap
+	}
+	return gap >= threshold
+}
+
 // parseOffset 解析偏移量字符串
 func (p *PDFFlowProcessor) parseOffset(offsetStr string) float64 {
 	if offsetStr == "" {
@@ -2512,6 +3181,27 @@ func (p *PDFFlowProcessor) hexToText(hex string) string {
 	return result.String()
 }
 
+// decodeTextOperand 解码文本操作数，十六进制字符串优先使用字体的 ToUnicode 映射
+func (p *PDFFlowProcessor) decodeTextOperand(operand string, font FontFlow) string {
+	text := strings.TrimSpace(operand)
+	if font.ToUnicode != nil && strings.HasPrefix(text, "<") && strings.HasSuffix(text, ">") && p.isCompletelyWrapped(text, '<', '>') {
+		if decoded := font.ToUnicode.Decode(text[1 : len(text)-1]); decoded != "" {
+			return decoded
+		}
+	}
+	return p.cleanPDFText(operand)
+}
+
+// decodeHexText 解码十六进制文本，有 ToUnicode 映射时按 CMap 解码，否则按单字节处理
+func (p *PDFFlowProcessor) decodeHexText(hex string, font FontFlow) string {
+	if font.ToUnicode != nil {
+		if decoded := font.ToUnicode.Decode(hex); decoded != "" {
+			return decoded
+		}
+	}
+	return p.hexToText(hex)
+}
+
 // decodeUnicodeEscapes 解码Unicode转义序列
 func (p *PDFFlowProcessor) decodeUnicodeEscapes(text string) string {
 	result := strings.Builder{}
@@ -2547,30 +3237,8 @@ func (p *PDFFlowProcessor) decodeUnicodeEscapes(text string) string {
 
 // normalizeWhitespace 规范化空白字符
 func (p *PDFFlowProcessor) normalizeWhitespace(text string) string {
-	// 将多个连续的空白字符替换为单个空格
-	// 但保留换行符的语义
-	result := strings.Builder{}
-	lastWasSpace := false
-
-	for _, r := range text {
-		if r == ' ' || r == '\t' {
-			if !lastWasSpace {
-				result.WriteRune(' ')
-				lastWasSpace = true
-			}
-		} else if r == '\n' || r == '\r' {
-			// 保留换行符，但避免重复
-			if !lastWasSpace {
-				result.WriteRune(' ') // 将换行转换为空格，保持文本连续性
-				lastWasSpace = true
-			}
-		} else {
-			result.WriteRune(r)
-			lastWasSpace = false
-		}
-	}
-
-	return strings.TrimSpace(result.String())
+	// 将换行转换为空格，保持文本连续性
+	return strings.ReplaceAll(NormalizeText(text), "\n", " ")
 }
 
 // parseTransformMatrix 解析变换矩阵
@@ -2711,6 +3379,13 @@ func (p *PDFFlowProcessor) detectLanguage(text string) string {
 		if r >= 0x4e00 && r <= 0x9fff {
 			UniCount++
 		}
+		// 假名和谚文可直接确定语言
+		if (r >= 0x3040 && r <= 0x30ff) || (r >= 0x31f0 && r <= 0x31ff) {
+			return "ja"
+		}
+		if r >= 0xac00 && r <= 0xd7af {
+			return "ko"
+		}
 	}
 
 	if totalCount > 0 && float64(UniCount)/float64(totalCount) > 0.3 {
@@ -2721,8 +3396,22 @@ func (p *PDFFlowProcessor) detectLanguage(text string) string {
 }
 
 // extractAnnotations 提取注释
+// 目前提取链接注释（外部链接和文档内跳转），其他类型的注释不保留
 func (p *PDFFlowProcessor) extractAnnotations(ctx *model.Context, pageDict types.Dict, pageFlow *PDFPageFlow) error {
-	// 简化实现，实际需要更复杂的注释解析
+	annots, err := ctx.DereferenceArray(pageDict["Annots"])
+	if err != nil || len(annots) == 0 {
+		return err
+	}
+
+	for i, annot := range annots {
+		if link, ok := p.linkAnnotation(ctx, annot, pageFlow.MediaBox); ok {
+			link.ID = fmt.Sprintf("link_%d_%d", pageFlow.PageNumber, i)
+			pageFlow.Annotations = append(pageFlow.Annotations, link)
+		} else if markup, ok := p.markupAnnotation(ctx, annot, pageFlow.MediaBox); ok {
+			markup.ID = fmt.Sprintf("annot_%d_%d", pageFlow.PageNumber, i)
+			pageFlow.Annotations = append(pageFlow.Annotations, markup)
+		}
+	}
 	return nil
 }
 
@@ -2819,25 +3508,25 @@ func (p *PDFFlowProcessor) extractResources() error {
 
 		fileName := file.Name()
 		filePath := filepath.Join(resourcesDir, fileName)
-		
+
 		// pdfcpu 提取的文件名通常格式为: page_页码_对象ID_名称.ext 或 page_页码_名称.ext
 		// 我们尝试匹配名称部分
-		
+
 		// 将提取的图像添加到 Resources.Images
 		// 简单的匹配策略：如果资源名包含在文件名中
 		for name, imgRes := range p.flowData.Resources.Images {
 			// 移除名称前的 / (如果存在)
 			cleanName := strings.TrimPrefix(name, "/")
-			
+
 			// 检查文件名是否包含此名称 (忽略大小写)
 			if strings.Contains(strings.ToLower(fileName), strings.ToLower(cleanName)) {
 				imgRes.FilePath = filePath
 				p.flowData.Resources.Images[name] = imgRes
 				mappedCount++
-				
+
 				p.logger.Debug("关联图像资源", map[string]interface{}{
 					"资源名": name,
-					"文件": fileName,
+					"文件":  fileName,
 				})
 			}
 		}
@@ -2847,25 +3536,28 @@ func (p *PDFFlowProcessor) extractResources() error {
 	p.logger.LogOperationTiming("提取资源文件", duration)
 
 	p.logger.Info("资源文件提取完成", map[string]interface{}{
-		"资源目录": resourcesDir,
+		"资源目录":  resourcesDir,
 		"提取文件数": len(files),
 		"关联资源数": mappedCount,
-		"耗时":   duration.String(),
+		"耗时":    duration.String(),
 	})
 
 	return nil
 }
 
 // findBestTranslation 查找最佳翻译 - 改进版本
-func (p *PDFFlowProcessor) findBestTranslation(text string, translations map[string]string) string {
+// 依次尝试精确匹配、标准化匹配、相似度匹配、包含关系匹配和关键短语匹配，查找通过索引进行；
+// 只使用 matchStrategies 启用的匹配方式
+func (p *PDFFlowProcessor) findBestTranslation(text string, index *translationIndex) string {
 	// 跳过空文本或过短的文本
 	cleanText := strings.TrimSpace(text)
 	if len(cleanText) < 3 {
 		return ""
 	}
+	strategies := p.matchStrategies()
 
 	// 1. 精确匹配
-	if translation, exists := translations[text]; exists {
+	if translation, exists := index.exact[text]; exists && strategies&MatchExact != 0 {
 		p.logger.Debug("找到精确匹配", map[string]interface{}{
 			"原文": text,
 			"翻译": translation,
@@ -2875,75 +3567,51 @@ func (p *PDFFlowProcessor) findBestTranslation(text string, translations map[str
 
 	// 2. 清理后的精确匹配
 	cleanText = p.normalizeText(text)
-	for original, translation := range translations {
-		if p.normalizeText(original) == cleanText {
-			p.logger.Debug("找到标准化匹配", map[string]interface{}{
-				"原文":  text,
-				"标准化": cleanText,
-				"翻译":  translation,
-			})
-			return translation
-		}
+	if i, exists := index.normalized[cleanText]; exists && strategies&MatchNormalized != 0 {
+		translation := index.entries[i].translation
+		p.logger.Debug("找到标准化匹配", map[string]interface{}{
+			"原文":  text,
+			"标准化": cleanText,
+			"翻译":  translation,
+		})
+		return translation
 	}
 
-	// 3. 改进的相似度匹配 - 更严格的匹配
-	bestMatch := ""
-	bestScore := 0.0
-	bestOriginal := ""
-
-	for original, translation := range translations {
-		// 只对长度相近的文本进行相似度计算
-		lenRatio := float64(len(cleanText)) / float64(len(original))
-		if lenRatio < 0.3 || lenRatio > 3.0 {
-			continue // 长度差异太大，跳过
-		}
-
-		score := p.calculateSimilarity(cleanText, original)
-		if score > bestScore && score > 0.8 { // 提高阈值以确保准确性
-			bestScore = score
-			bestMatch = translation
-			bestOriginal = original
+	// 3. 改进的相似度匹配 - 更严格的匹配（默认阈值 0.8，只比较长度相近的条目）
+	if strategies&MatchSimilarity != 0 {
+		if entry, score, ok := index.similarityMatch(p, cleanText, p.matchThreshold()); ok {
+			p.logger.Debug("找到相似度匹配", map[string]interface{}{
+				"原文":  text,
+				"匹配源": entry.original,
+				"翻译":  entry.translation,
+				"相似度": fmt.Sprintf("%.2f", score),
+			})
+			return entry.translation
 		}
 	}
 
-	if bestMatch != "" {
-		p.logger.Debug("找到相似度匹配", map[string]interface{}{
-			"原文":  text,
-			"匹配源": bestOriginal,
-			"翻译":  bestMatch,
-			"相似度": fmt.Sprintf("%.2f", bestScore),
-		})
-		return bestMatch
-	}
-
-	// 4. 包含关系匹配 - 更严格的条件
-	for original, translation := range translations {
-		// 检查当前文本是否是原文的主要部分
-		if len(cleanText) > 10 && len(original) > 10 {
-			if strings.Contains(original, cleanText) {
-				// 确保匹配的部分占原文的主要部分
-				if float64(len(cleanText))/float64(len(original)) > 0.6 {
-					p.logger.Debug("找到主要部分匹配", map[string]interface{}{
-						"原文":  text,
-						"源文本": original,
-						"翻译":  translation,
-						"匹配率": fmt.Sprintf("%.1f%%", float64(len(cleanText))/float64(len(original))*100),
-					})
-					return translation
-				}
-			}
+	// 4. 包含关系匹配 - 当前文本是原文的主要部分（占 60% 以上）
+	if strategies&MatchSubstring != 0 {
+		if entry, ok := index.containmentMatch(cleanText); ok {
+			p.logger.Debug("找到主要部分匹配", map[string]interface{}{
+				"原文":  text,
+				"源文本": entry.original,
+				"翻译":  entry.translation,
+				"匹配率": fmt.Sprintf("%.1f%%", float64(len(cleanText))/float64(len(entry.original))*100),
+			})
+			return entry.translation
 		}
 	}
 
 	// 5. 关键短语匹配 - 检查是否包含相同的关键短语
-	for original, translation := range translations {
-		if p.hasSignificantOverlap(cleanText, original) {
+	if strategies&MatchKeywordOverlap != 0 {
+		if entry, ok := index.overlapMatch(p, cleanText); ok {
 			p.logger.Debug("找到关键短语匹配", map[string]interface{}{
 				"原文":   text,
-				"关键词源": original,
-				"翻译":   translation,
+				"关键词源": entry.original,
+				"翻译":   entry.translation,
 			})
-			return translation
+			return entry.translation
 		}
 	}
 
@@ -2975,28 +3643,7 @@ func (p *PDFFlowProcessor) calculateSimilarity(text1, text2 string) float64 {
 
 // longestCommonSubsequence 计算最长公共子序列长度
 func (p *PDFFlowProcessor) longestCommonSubsequence(s1, s2 string) int {
-	m, n := len(s1), len(s2)
-	if m == 0 || n == 0 {
-		return 0
-	}
-
-	// 动态规划表
-	dp := make([][]int, m+1)
-	for i := range dp {
-		dp[i] = make([]int, n+1)
-	}
-
-	for i := 1; i <= m; i++ {
-		for j := 1; j <= n; j++ {
-			if s1[i-1] == s2[j-1] {
-				dp[i][j] = dp[i-1][j-1] + 1
-			} else {
-				dp[i][j] = max(dp[i-1][j], dp[i][j-1])
-			}
-		}
-	}
-
-	return dp[m][n]
+	return lcsLength([]byte(s1), []byte(s2))
 }
 
 // containsKeywords 检查是否包含关键词
@@ -3303,9 +3950,7 @@ func (p *PDFFlowProcessor) setupFonts(pdf *gofpdf.Fpdf) error {
 		}
 
 		// 添加UTF8字体
-		pdf.AddUTF8Font(fontName, "", fontPath)
-
-		if err := pdf.Error(); err != nil {
+		if err := addUTF8Font(pdf, fontName, fontPath); err != nil {
 			log.Printf("警告：添加通用字体失败: %v", err)
 			// 尝试使用内置字体作为备用
 			p.UniFontName = "Arial"
@@ -3322,13 +3967,50 @@ func (p *PDFFlowProcessor) setupFonts(pdf *gofpdf.Fpdf) error {
 	return nil
 }
 
+// policyFontName 按字体策略返回文本应使用的字体名称，首次使用时注册到输出 PDF
+// 策略未指定字体或字体无法加载时返回空字符串
+func (p *PDFFlowProcessor) policyFontName(pdf *gofpdf.Fpdf, text string) string {
+	if p.FontPolicy == nil {
+		return ""
+	}
+	fontPath := p.FontPolicy.FontPath(p.detectLanguage(text))
+	if fontPath == "" {
+		return ""
+	}
+
+	if p.policyFonts == nil {
+		p.policyFonts = make(map[string]string)
+	}
+	if name, ok := p.policyFonts[fontPath]; ok {
+		return name
+	}
+
+	name := ""
+	if _, err := os.Stat(fontPath); err != nil {
+		log.Printf("警告：字体文件不存在: %s", fontPath)
+	} else {
+		name = fontFamilyName(fontPath)
+		if err := addUTF8Font(pdf, name, fontPath); err != nil {
+			log.Printf("警告：添加字体 %s 失败: %v", fontPath, err)
+			pdf.ClearError()
+			name = ""
+		}
+	}
+	p.policyFonts[fontPath] = name
+	return name
+}
+
 // generatePage 生成页面
 func (p *PDFFlowProcessor) generatePage(pdf *gofpdf.Fpdf, page PDFPageFlow) error {
-	pdf.AddPage()
+	// 按原页面的尺寸和方向添加页面，MediaBox 缺失时使用默认的A4
+	orientation, size := pageFormat(page.MediaBox)
+	pdf.AddPageFormat(orientation, size)
 
-	// 设置页面尺寸
-	if page.MediaBox.Width > 0 && page.MediaBox.Height > 0 {
-		// 这里可以设置自定义页面尺寸，但gofpdf的API有限制
+	// MediaBox 原点不在 (0,0) 时平移坐标，使页面内容相对页面边界定位
+	if page.MediaBox.X != 0 || page.MediaBox.Y != 0 {
+		pdf.TransformBegin()
+		pdf.TransformTranslate(-page.MediaBox.X, -page.MediaBox.Y)
+		defer pdf.TransformEnd()
 	}
 
 	// 按Y坐标排序文本元素，确保正确的渲染顺序
@@ -3362,9 +4044,12 @@ func (p *PDFFlowProcessor) generatePage(pdf *gofpdf.Fpdf, page PDFPageFlow) erro
 
 	// 渲染文本元素
 	for i, element := range sortedTextElements {
-		if err := p.renderTextElement(pdf, element, i); err != nil {
+		if err := p.renderTextElement(pdf, element, i, page.MediaBox); err != nil {
 			log.Printf("警告：渲染文本元素失败: %v", err)
 		}
+		if p.PreserveOriginalTextLayer {
+			p.renderOriginalTextLayer(pdf, element)
+		}
 	}
 
 	// 渲染图像元素
@@ -3384,8 +4069,21 @@ func (p *PDFFlowProcessor) generatePage(pdf *gofpdf.Fpdf, page PDFPageFlow) erro
 	return nil
 }
 
-// renderTextElement 渲染文本元素
-func (p *PDFFlowProcessor) renderTextElement(pdf *gofpdf.Fpdf, element TextElementFlow, index int) error {
+// pageFormat 返回与 MediaBox 尺寸一致的页面方向和尺寸，MediaBox 缺失时返回A4纵向
+// gofpdf 的横向页面会交换宽高，因此尺寸始终按短边为宽传入
+func pageFormat(mediaBox BoundingBox) (string, gofpdf.SizeType) {
+	width, height := mediaBox.Width, mediaBox.Height
+	if width <= 0 || height <= 0 {
+		return "P", gofpdf.SizeType{Wd: 595.28, Ht: 841.89}
+	}
+	if width > height {
+		return "L", gofpdf.SizeType{Wd: height, Ht: width}
+	}
+	return "P", gofpdf.SizeType{Wd: width, Ht: height}
+}
+
+// renderTextElement 渲染文本元素，mediaBox 为所在页面的 MediaBox（用于限制文本位置）
+func (p *PDFFlowProcessor) renderTextElement(pdf *gofpdf.Fpdf, element TextElementFlow, index int, mediaBox BoundingBox) error {
 	// 设置字体
 	fontName := "Arial"
 	fontSize := element.Font.Size
@@ -3398,7 +4096,15 @@ func (p *PDFFlowProcessor) renderTextElement(pdf *gofpdf.Fpdf, element TextEleme
 		fontSize = 72
 	}
 
-	if p.containsUni(element.Content) {
+	// 上下标使用较小字号
+	if element.ScriptPosition != "" {
+		fontSize *= scriptSizeRatio
+	}
+
+	if policyFont := p.policyFontName(pdf, element.Content); policyFont != "" {
+		// 字体策略为该语言指定了字体
+		fontName = policyFont
+	} else if p.containsUni(element.Content) {
 		// 使用已添加的通用字体
 		if p.UniFontName != "" && p.UniFontName != "Arial" {
 			fontName = p.UniFontName
@@ -3427,14 +4133,33 @@ func (p *PDFFlowProcessor) renderTextElement(pdf *gofpdf.Fpdf, element TextEleme
 		return nil // 跳过空内容
 	}
 
+	// 原文为竖排时按竖排输出译文
+	if p.useVerticalLayout(element, content) {
+		p.renderVerticalText(pdf, element, content, fontSize, mediaBox)
+		return nil
+	}
+
+	// 已翻译的元素按原始边界框重新排版
+	layout := p.adjustTranslatedLayout(element, content, fontSize)
+	if layout != nil {
+		fontSize = layout.FontSize
+		pdf.SetFont(fontName, "", fontSize)
+	}
+
 	// 处理过长的文本
 	maxWidth := element.BoundingBox.Width
 	if maxWidth <= 0 {
 		maxWidth = 500 // 默认最大宽度
 	}
 
+	// 含换行符的文本（如双语对照的原文和译文）按行输出，换行符处另起一行
+	if layout == nil && strings.Contains(content, "\n") {
+		layout = p.fitTextLayout(element, content, fontSize, maxWidth)
+		pdf.SetFont(fontName, "", layout.FontSize)
+	}
+
 	// 如果文本太长，进行智能截断或分行处理
-	if len(content) > 200 { // 如果文本超过200个字符
+	if layout == nil && len(content) > 200 { // 如果文本超过200个字符
 		// 尝试在合适的位置截断
 		if strings.Contains(content, "\n") {
 			// 如果包含换行符，只取第一行
@@ -3456,41 +4181,39 @@ func (p *PDFFlowProcessor) renderTextElement(pdf *gofpdf.Fpdf, element TextEleme
 		}
 	}
 
-	// 检查文本宽度是否超出边界
+	// 检查文本宽度是否超出边界，超出时按字体度量换行并缩小字号，放入原始边界框
 	textWidth := pdf.GetStringWidth(content)
-	if textWidth > maxWidth && maxWidth > 50 {
-		// 如果文本宽度超出，尝试缩小字体
-		newSize := fontSize * (maxWidth / textWidth) * 0.85 // 留15%边距
-		if newSize < 8 {                                    // 最小字体大小
-			newSize = 8
-		}
-		if newSize < fontSize {
-			pdf.SetFont(fontName, "", newSize)
-			p.logger.Debug("调整字体大小", map[string]interface{}{
-				"原始大小": fontSize,
-				"新大小":  newSize,
-				"文本宽度": textWidth,
-				"最大宽度": maxWidth,
-			})
-		}
+	if layout == nil && textWidth > maxWidth && maxWidth > 50 {
+		layout = p.fitTextLayout(element, content, fontSize, maxWidth)
+		pdf.SetFont(fontName, "", layout.FontSize)
+		p.logger.Debug("调整字体大小", map[string]interface{}{
+			"原始大小": fontSize,
+			"新大小":  layout.FontSize,
+			"行数":   len(layout.Lines),
+			"文本宽度": textWidth,
+			"最大宽度": maxWidth,
+		})
 	}
 
 	// 智能位置调整 - 避免文本重叠
 	posX := element.Position.X
 	posY := element.Position.Y
 
-	// 确保位置在合理范围内
-	if posX < 0 {
-		posX = 50
+	// 确保位置在合理范围内（按页面尺寸计算，A4页面约为 500 和 750）
+	pageWidth, pageHeight := pdf.GetPageSize()
+	maxX := mediaBox.X + pageWidth - 95
+	maxY := mediaBox.Y + pageHeight - 92
+	if posX < mediaBox.X {
+		posX = mediaBox.X + 50
 	}
-	if posY < 0 {
-		posY = 50
+	if posY < mediaBox.Y {
+		posY = mediaBox.Y + 50
 	}
-	if posX > 500 {
-		posX = 500
+	if posX > maxX {
+		posX = maxX
 	}
-	if posY > 750 {
-		posY = 750
+	if posY > maxY {
+		posY = maxY
 	}
 
 	// 如果位置看起来不合理（比如都堆叠在同一位置），进行调整
@@ -3508,8 +4231,18 @@ func (p *PDFFlowProcessor) renderTextElement(pdf *gofpdf.Fpdf, element TextEleme
 		})
 	}
 
-	// 设置位置并输出文本
-	pdf.SetXY(posX, posY)
+	// 上下标相对基线升高或降低（gofpdf坐标系Y轴向下）
+	posY -= p.scriptOffset(element)
+
+	// 按调整后的布局逐行输出
+	if layout != nil {
+		lineHeight := layout.FontSize * layout.LineSpacing
+		for i, line := range layout.Lines {
+			lastLine := i == len(layout.Lines)-1
+			p.drawAlignedLine(pdf, line, posX, posY+float64(i)*lineHeight, layout.BoundingBox.Width, lineHeight, element.Alignment, lastLine)
+		}
+		return nil
+	}
 
 	// 计算合适的单元格尺寸
 	cellWidth := element.BoundingBox.Width
@@ -3521,11 +4254,175 @@ func (p *PDFFlowProcessor) renderTextElement(pdf *gofpdf.Fpdf, element TextEleme
 		cellHeight = fontSize * 1.2
 	}
 
+	// Cell 按字号在单元格内垂直居中，上下标先按正文字号对齐到正文基线，再由 scriptOffset 升降
+	if element.ScriptPosition != "" {
+		bodySize := fontSize / scriptSizeRatio
+		if element.BoundingBox.Height <= 0 {
+			cellHeight = bodySize * 1.2
+		}
+		posY += 0.3 * (bodySize - fontSize)
+	}
+
+	// 设置位置并输出文本
+	pdf.SetXY(posX, posY)
 	pdf.Cell(cellWidth, cellHeight, content)
 
 	return nil
 }
 
+// fitTextLayout 使用 FitTextToBox 在元素的边界框内换行并缩小字号（换行符处强制换行）
+func (p *PDFFlowProcessor) fitTextLayout(element TextElementFlow, content string, fontSize, maxWidth float64) *AdjustedLayout {
+	box := element.BoundingBox
+	box.Width = maxWidth
+	font := element.Font
+	font.Size = fontSize
+	newSize, lines := FitTextToBox(content, box, font)
+	return &AdjustedLayout{
+		Text:        content,
+		Lines:       lines,
+		BoundingBox: box,
+		FontSize:    newSize,
+		LineSpacing: fitLineSpacing,
+		Adjusted:    true,
+		Method:      "fit",
+	}
+}
+
+// adjustTranslatedLayout 对已翻译的元素调用布局调整器，在原始边界框内换行并调整字号和行距
+// 未翻译或缺少原始边界框时返回 nil
+func (p *PDFFlowProcessor) adjustTranslatedLayout(element TextElementFlow, content string, fontSize float64) *AdjustedLayout {
+	box := element.OriginalBoundingBox
+	if p.layoutAdjuster == nil || element.OriginalContent == "" || box.Width <= 0 {
+		return nil
+	}
+	if box.Height <= 0 {
+		box.Height = fontSize * 1.2
+	}
+
+	font := element.Font
+	font.Size = fontSize
+	layout, err := p.layoutAdjuster.AdjustTextLayout(box, element.OriginalContent, content, font, element.Language)
+	if err != nil || layout == nil || len(layout.Lines) == 0 {
+		return nil
+	}
+
+	if layout.Overflow {
+		p.logger.Warn("译文超出原始边界框", map[string]interface{}{
+			"元素ID": element.ID,
+			"行数":   len(layout.Lines),
+			"字号":   layout.FontSize,
+			"所需高度": fmt.Sprintf("%.2f", layout.CalculateActualHeight()),
+			"可用高度": fmt.Sprintf("%.2f", box.Height),
+		})
+	}
+	return layout
+}
+
+// renderOriginalTextLayer 在译文位置以不可见模式（Tr 3）写入原文
+// 原文不显示，但可被搜索和复制，便于校对
+func (p *PDFFlowProcessor) renderOriginalTextLayer(pdf *gofpdf.Fpdf, element TextElementFlow) {
+	original := strings.TrimSpace(element.OriginalContent)
+	if original == "" || original == strings.TrimSpace(element.Content) {
+		return
+	}
+
+	fontName := "Arial"
+	if policyFont := p.policyFontName(pdf, original); policyFont != "" {
+		fontName = policyFont
+	} else if p.containsUni(original) && p.UniFontName != "" {
+		fontName = p.UniFontName
+	}
+	fontSize := element.Font.Size
+	if fontSize <= 0 || fontSize > 72 {
+		fontSize = 12
+	}
+
+	box := element.OriginalBoundingBox
+	if box.Width <= 0 {
+		box.Width = pdf.GetStringWidth(original) + 10
+	}
+
+	pdf.SetFont(fontName, "", fontSize)
+	pdf.SetTextRenderingMode(3)
+	pdf.SetXY(element.Position.X, element.Position.Y-p.scriptOffset(element))
+	pdf.Cell(box.Width, fontSize*1.2, original)
+	pdf.SetTextRenderingMode(0)
+}
+
+// drawAlignedLine 按段落对齐方式输出一行文本
+// 两端对齐时将剩余空间平均分配到词间（无空格的 CJK 文本分配到字间），段落末行保持左对齐
+func (p *PDFFlowProcessor) drawAlignedLine(pdf *gofpdf.Fpdf, line string, x, y, width, lineHeight float64, alignment string, lastLine bool) {
+	switch alignment {
+	case "center":
+		pdf.SetXY(x, y)
+		pdf.CellFormat(width, lineHeight, line, "", 0, "C", false, 0, "")
+		return
+	case "right":
+		pdf.SetXY(x, y)
+		pdf.CellFormat(width, lineHeight, line, "", 0, "R", false, 0, "")
+		return
+	case "justify":
+		if !lastLine {
+			// 每个 Cell 都会在左侧留出单元格边距，两端对齐的文本与左、右对齐一样落在边距之内
+			if pieces, gap, ok := justifyLine(line, width-2*pdf.GetCellMargin(), pdf.GetStringWidth); ok {
+				cursor := x
+				for _, piece := range pieces {
+					pieceWidth := pdf.GetStringWidth(piece)
+					pdf.SetXY(cursor, y)
+					pdf.Cell(pieceWidth, lineHeight, piece)
+					cursor += pieceWidth + gap
+				}
+				return
+			}
+		}
+	}
+
+	pdf.SetXY(x, y)
+	pdf.Cell(width, lineHeight, line)
+}
+
+// justifyLine 将一行拆分为词（或 CJK 字符），并计算填满宽度所需的间距
+// 无法拆分或文本已超出宽度时返回 false
+func justifyLine(line string, width float64, stringWidth func(string) float64) ([]string, float64, bool) {
+	pieces := strings.Fields(line)
+	if len(pieces) < 2 {
+		pieces = pieces[:0]
+		for _, r := range strings.TrimSpace(line) {
+			pieces = append(pieces, string(r))
+		}
+	}
+	if len(pieces) < 2 {
+		return nil, 0, false
+	}
+
+	used := 0.0
+	for _, piece := range pieces {
+		used += stringWidth(piece)
+	}
+	gap := (width - used) / float64(len(pieces)-1)
+	if gap < 0 {
+		return nil, 0, false
+	}
+	return pieces, gap, true
+}
+
+// scriptOffset 计算上下标相对基线的偏移量，正值表示升高
+func (p *PDFFlowProcessor) scriptOffset(element TextElementFlow) float64 {
+	if element.ScriptPosition == "" {
+		return 0
+	}
+
+	offset := element.TextState.Rise
+	if offset == 0 {
+		// 没有具体的上升值时，按正文字号估算
+		offset = element.Font.Size * 0.33
+		if element.ScriptPosition == ScriptSubscript {
+			offset = -offset
+		}
+	}
+	return offset
+}
+
 // renderImageElement 渲染图像元素
 func (p *PDFFlowProcessor) renderImageElement(pdf *gofpdf.Fpdf, element ImageElementFlow) error {
 	// 尝试渲染图像元素
@@ -3591,6 +4488,19 @@ func (p *PDFFlowProcessor) renderImageElement(pdf *gofpdf.Fpdf, element ImageEle
 		posY = 50
 	}
 
+	// 按放置尺寸降采样、重新压缩图片
+	if p.ImageOptions.enabled() {
+		optimized, err := OptimizeImage(imagePath, width, height, p.ImageOptions, filepath.Join(p.imageDir, "optimized"))
+		if err != nil {
+			p.logger.Warn("图片压缩失败，使用原图", map[string]interface{}{
+				"图像名称": element.Name,
+				"错误":   err.Error(),
+			})
+		} else {
+			imagePath = optimized
+		}
+	}
+
 	// 使用gofpdf的Image方法嵌入图片
 	// 参数：文件路径, X坐标, Y坐标, 宽度, 高度, 是否流式, 图片类型(自动检测), 链接, 链接目标
 	pdf.Image(imagePath, posX, posY, width, height, false, "", 0, "")
@@ -3598,8 +4508,8 @@ func (p *PDFFlowProcessor) renderImageElement(pdf *gofpdf.Fpdf, element ImageEle
 	p.logger.Debug("图片渲染成功", map[string]interface{}{
 		"图像名称": element.Name,
 		"文件路径": imagePath,
-		"位置":    fmt.Sprintf("(%.1f, %.1f)", posX, posY),
-		"尺寸":    fmt.Sprintf("%.1f x %.1f", width, height),
+		"位置":   fmt.Sprintf("(%.1f, %.1f)", posX, posY),
+		"尺寸":   fmt.Sprintf("%.1f x %.1f", width, height),
 	})
 
 	return nil
@@ -3806,7 +4716,7 @@ func (lm *LayoutManager) boundsOverlap(a, b BoundingBox) bool {
 
 // mergeAdjacentTextElements 合并相邻的文本元素
 func (p *PDFFlowProcessor) mergeAdjacentTextElements(pageFlow *PDFPageFlow) {
-	if len(pageFlow.TextElements) <= 1 {
+	if len(pageFlow.TextElements) <= 1 || p.MergeStrategy == MergeStrategyNone {
 		return
 	}
 
@@ -3827,10 +4737,19 @@ func (p *PDFFlowProcessor) mergeAdjacentTextElements(pageFlow *PDFPageFlow) {
 				separator = " "
 			}
 
+			if p.isLineBreak(current, next) && isHyphenatedBreak(current.Content, next.Content) {
+				// 跨行断词：去掉行尾连字符直接拼接
+				current.Content = strings.TrimSuffix(strings.TrimRight(current.Content, " \t"), "-")
+				next.Content = strings.TrimLeft(next.Content, " \t")
+				separator = ""
+			}
+
 			current.Content += separator + next.Content
 
-			// 更新边界框
-			current.BoundingBox.Width = next.BoundingBox.X + next.BoundingBox.Width - current.BoundingBox.X
+			// 更新边界框（换行合并时下一行可能比当前文本短）
+			if width := next.BoundingBox.X + next.BoundingBox.Width - current.BoundingBox.X; width > current.BoundingBox.Width {
+				current.BoundingBox.Width = width
+			}
 			if next.BoundingBox.Y < current.BoundingBox.Y {
 				current.BoundingBox.Height += current.BoundingBox.Y - next.BoundingBox.Y
 				current.BoundingBox.Y = next.BoundingBox.Y
@@ -3849,9 +4768,19 @@ func (p *PDFFlowProcessor) mergeAdjacentTextElements(pageFlow *PDFPageFlow) {
 		"原始数量":  originalCount,
 		"合并后数量": len(merged),
 		"减少比例":  fmt.Sprintf("%.1f%%", float64(originalCount-len(merged))/float64(originalCount)*100),
+		"合并策略":  string(p.MergeStrategy),
 	})
 }
 
+// isLineBreak 检查 b 是否位于 a 的下一行（同一行内的复合词连字符不做断词处理）
+func (p *PDFFlowProcessor) isLineBreak(a, b TextElementFlow) bool {
+	yDiff := a.Position.Y - b.Position.Y
+	if yDiff < 0 {
+		yDiff = -yDiff
+	}
+	return yDiff > a.Font.Size*0.5
+}
+
 // shouldMergeTextElements 检查是否应该合并两个文本元素
 func (p *PDFFlowProcessor) shouldMergeTextElements(a, b TextElementFlow) bool {
 	// 检查字体是否相似
@@ -3859,6 +4788,16 @@ func (p *PDFFlowProcessor) shouldMergeTextElements(a, b TextElementFlow) bool {
 		return false
 	}
 
+	// 上下标不与正文合并
+	if a.ScriptPosition != b.ScriptPosition {
+		return false
+	}
+
+	// 竖排文本不与横排文本合并
+	if a.Vertical != b.Vertical {
+		return false
+	}
+
 	// 检查颜色是否相同
 	if !p.isSimilarColor(a.Color, b.Color) {
 		return false
@@ -3869,8 +4808,8 @@ func (p *PDFFlowProcessor) shouldMergeTextElements(a, b TextElementFlow) bool {
 		return false
 	}
 
-	// 检查文本内容是否适合合并
-	if !p.isContentMergeable(a.Content, b.Content) {
+	// 检查文本内容是否适合合并（激进和按行合并的策略不检查）
+	if p.MergeStrategy.thresholds().CheckContent && !p.isContentMergeable(a.Content, b.Content) {
 		return false
 	}
 
@@ -3890,7 +4829,7 @@ func (p *PDFFlowProcessor) isSimilarFont(a, b FontFlow) bool {
 		sizeDiff = -sizeDiff
 	}
 
-	return sizeDiff <= 1.0
+	return sizeDiff <= p.MergeStrategy.thresholds().MaxSizeDiff
 }
 
 // isSimilarColor 检查颜色是否相似
@@ -3919,25 +4858,30 @@ func (p *PDFFlowProcessor) isSimilarColor(a, b ColorFlow) bool {
 
 // isAdjacentPosition 检查位置是否相邻
 func (p *PDFFlowProcessor) isAdjacentPosition(a, b TextElementFlow) bool {
-	// 垂直距离检查
+	thresholds := p.MergeStrategy.thresholds()
+
+	// 垂直距离检查（按行合并时即基线容差）
 	yDiff := a.Position.Y - b.Position.Y
 	if yDiff < 0 {
 		yDiff = -yDiff
 	}
 
 	// 如果垂直距离太大，不合并
-	if yDiff > a.Font.Size*1.5 {
+	if yDiff > a.Font.Size*thresholds.MaxYGap {
 		return false
 	}
 
-	// 水平距离检查
+	// 水平距离检查，换行时下一行应从当前文本的左边界附近开始
 	xGap := b.Position.X - (a.Position.X + a.BoundingBox.Width)
+	if p.isLineBreak(a, b) {
+		xGap = b.Position.X - a.Position.X
+	}
 	if xGap < 0 {
 		xGap = -xGap
 	}
 
 	// 如果水平距离太大，不合并
-	if xGap > a.Font.Size*2 {
+	if xGap > a.Font.Size*thresholds.MaxXGap {
 		return false
 	}
 
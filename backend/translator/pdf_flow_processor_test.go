@@ -0,0 +1,332 @@
+package translator
+
+import (
+	"bytes"
+	"math"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/jung-kurt/gofpdf"
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+)
+
+// textBaselines 返回未压缩的 gofpdf 输出中各段文本的基线 Y 坐标（PDF 坐标系，向上为正）
+func textBaselines(t *testing.T, pdf *gofpdf.Fpdf) map[string]float64 {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	baselines := make(map[string]float64)
+	re := regexp.MustCompile(`BT [\d.]+ ([\d.]+) Td \((.*?)\) ?Tj ET`)
+	for _, m := range re.FindAllStringSubmatch(buf.String(), -1) {
+		y, _ := strconv.ParseFloat(m[1], 64)
+		baselines[m[2]] = y
+	}
+	return baselines
+}
+
+func TestParseContentElementsDetectsTextRise(t *testing.T) {
+	p := newTestFlowProcessor(t, "input.pdf", "")
+	ops, err := p.parseOperations("BT /F1 12 Tf 72 700 Td (E = mc) Tj 5 Ts (2) Tj -3 Ts (i) Tj ET")
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := &PDFPageFlow{ContentStreams: []ContentStreamFlow{{ParsedOps: ops}}}
+	if err := p.parseContentElements(page, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(page.TextElements) != 3 {
+		t.Fatalf("文本元素 %d 个，期望 3", len(page.TextElements))
+	}
+	want := []string{"", ScriptSuperscript, ScriptSubscript}
+	for i, elem := range page.TextElements {
+		if elem.ScriptPosition != want[i] {
+			t.Errorf("%q 的 ScriptPosition = %q，期望 %q", elem.Content, elem.ScriptPosition, want[i])
+		}
+	}
+}
+
+func TestRenderTextElementRaisesSuperscript(t *testing.T) {
+	p := newTestFlowProcessor(t, "input.pdf", "")
+	pdf := gofpdf.New("P", "pt", "A4", "")
+	pdf.SetCompression(false)
+	pdf.AddPage()
+
+	base := TextElementFlow{
+		Content:  "x",
+		Position: PositionFlow{X: 100, Y: 200},
+		Font:     FontFlow{Name: "Helvetica", Size: 12},
+	}
+	sup := base
+	sup.Content = "2"
+	sup.Position.X = 110
+	sup.TextState.Rise = 4
+	sup.ScriptPosition = ScriptSuperscript
+	sub := base
+	sub.Content = "i"
+	sub.Position.X = 120
+	sub.TextState.Rise = -3
+	sub.ScriptPosition = ScriptSubscript
+
+	mediaBox := BoundingBox{Width: 595, Height: 842}
+	for i, elem := range []TextElementFlow{base, sup, sub} {
+		if err := p.renderTextElement(pdf, elem, i, mediaBox); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	y := textBaselines(t, pdf)
+	if len(y) != 3 {
+		t.Fatalf("输出中的文本: %v", y)
+	}
+	// 相对正文基线的偏移等于 Ts 指定的上升值
+	if rise := y["2"] - y["x"]; math.Abs(rise-4) > 0.05 {
+		t.Errorf("上标基线比正文高 %.2f，期望 4", rise)
+	}
+	if rise := y["i"] - y["x"]; math.Abs(rise+3) > 0.05 {
+		t.Errorf("下标基线比正文高 %.2f，期望 -3", rise)
+	}
+}
+
+func TestTJOffsetSpacingScalesWithFont(t *testing.T) {
+	p := newTestFlowProcessor(t, "input.pdf", "")
+	const array = "[(Hello) -150 (world)]"
+	tests := []struct {
+		name string
+		font FontFlow
+		want string
+	}{
+		// 同一偏移量在 6pt 下为 0.9pt 的字距调整，在 24pt 下为 3.6pt 的词间距
+		{"6pt", FontFlow{Size: 6}, "Helloworld"},
+		{"24pt", FontFlow{Size: 24}, "Hello world"},
+		// 空格较宽的字体（600/1000）需要更大的间距
+		{"24pt wide space", FontFlow{Size: 24, CharWidths: map[rune]float64{' ': 600}}, "Helloworld"},
+	}
+	for _, tt := range tests {
+		if got := p.extractTextFromTJArray(array, tt.font); got != tt.want {
+			t.Errorf("%s: extractTextFromTJArray = %q，期望 %q", tt.name, got, tt.want)
+		}
+	}
+
+	// 字距调整（小偏移量）在任何字号下都不插入空格
+	if got := p.extractTextFromTJArray("[(W) 80 (ave) -20 (s)]", FontFlow{Size: 24}); got != "Waves" {
+		t.Errorf("字距调整: %q，期望 Waves", got)
+	}
+}
+
+func TestTranslatedTextWrapsIntoOriginalBox(t *testing.T) {
+	p := newTestFlowProcessor(t, "input.pdf", "")
+	box := BoundingBox{X: 72, Y: 100, Width: 120, Height: 60}
+	translation := "这是一段比英文原文长得多的中文译文，需要分成多行才能放进原来的边界框里。"
+	element := TextElementFlow{
+		Content:             translation,
+		OriginalContent:     "Short source.",
+		Font:                FontFlow{Name: "Helvetica", Size: 10},
+		OriginalBoundingBox: box,
+		Language:            "zh",
+	}
+
+	layout := p.adjustTranslatedLayout(element, translation, element.Font.Size)
+	if layout == nil {
+		t.Fatal("已翻译的元素应按原始边界框排版")
+	}
+	if len(layout.Lines) < 2 {
+		t.Fatalf("译文应换成多行，得到 %q", layout.Lines)
+	}
+	if layout.Overflow || layout.CalculateActualHeight() > box.Height {
+		t.Errorf("%d 行高 %.2f，超出边界框高度 %.2f", len(layout.Lines), layout.CalculateActualHeight(), box.Height)
+	}
+	metrics := GetGlobalFontMetrics()
+	for _, line := range layout.Lines {
+		if w := metrics.CalculateTextWidth(line, element.Font.Name, layout.FontSize); w > box.Width {
+			t.Errorf("行 %q 宽 %.2f，超出边界框宽度 %.2f", line, w, box.Width)
+		}
+	}
+	if joined := strings.Join(layout.Lines, ""); joined != translation {
+		t.Errorf("换行后内容不完整: %q", joined)
+	}
+}
+
+// textRuns 返回未压缩的 gofpdf 输出中各段文本的起点 X 坐标，按输出顺序排列
+func textRuns(t *testing.T, pdf *gofpdf.Fpdf) ([]string, []float64) {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var texts []string
+	var xs []float64
+	re := regexp.MustCompile(`BT ([\d.]+) [\d.]+ Td \((.*?)\) ?Tj ET`)
+	for _, m := range re.FindAllStringSubmatch(buf.String(), -1) {
+		x, _ := strconv.ParseFloat(m[1], 64)
+		texts = append(texts, m[2])
+		xs = append(xs, x)
+	}
+	return texts, xs
+}
+
+func TestJustifiedLinesFillBoxWidth(t *testing.T) {
+	p := newTestFlowProcessor(t, "input.pdf", "")
+	pdf := gofpdf.New("P", "pt", "A4", "")
+	pdf.SetCompression(false)
+	pdf.AddPage()
+	pdf.SetFont("Helvetica", "", 12)
+
+	const x, width = 100.0, 300.0
+	p.drawAlignedLine(pdf, "The quick brown fox", x, 100, width, 14, "justify", false)
+	p.drawAlignedLine(pdf, "jumps over.", x, 114, width, 14, "justify", true)
+	p.drawAlignedLine(pdf, "reference", x, 128, width, 14, "right", true)
+
+	texts, xs := textRuns(t, pdf)
+	if len(texts) != 6 {
+		t.Fatalf("输出中的文本: %q", texts)
+	}
+	left := xs[4]
+	right := xs[5] + pdf.GetStringWidth(texts[5])
+
+	// 非末行逐词输出，首词贴左边、末词贴右边
+	if got := strings.Join(texts[:4], " "); got != "The quick brown fox" {
+		t.Fatalf("两端对齐行的词: %q", texts[:4])
+	}
+	if math.Abs(xs[0]-left) > 0.05 {
+		t.Errorf("首词起点 %.2f，期望与左对齐文本相同的 %.2f", xs[0], left)
+	}
+	if end := xs[3] + pdf.GetStringWidth(texts[3]); math.Abs(end-right) > 0.05 {
+		t.Errorf("末词终点 %.2f，期望与右对齐文本相同的 %.2f", end, right)
+	}
+
+	// 段落末行整行左对齐输出
+	if texts[4] != "jumps over." {
+		t.Errorf("末行应整行输出，得到 %q", texts[4])
+	}
+	if left-x > width/10 {
+		t.Errorf("末行起点 %.2f 偏离左边界 %.2f", left, x)
+	}
+}
+
+func TestInlineImageDoesNotBreakFollowingText(t *testing.T) {
+	p := newTestFlowProcessor(t, "input.pdf", "")
+	// 图像数据中含有括号、Tj 和不在空白之间的 EI，不能被当作操作符解析
+	content := "q 20 0 0 10 72 650 cm BI /W 4 /H 2 /BPC 8 /CS /G ID \x00(\xff) TjEI\x7f\x80 EI Q " +
+		"BT /F1 12 Tf 72 600 Td (After the image) Tj ET"
+	ops, err := p.parseOperations(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := &PDFPageFlow{ContentStreams: []ContentStreamFlow{{ParsedOps: ops}}}
+	if err := p.parseContentElements(page, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(page.ImageElements) != 1 {
+		t.Fatalf("图像元素 %d 个，期望 1", len(page.ImageElements))
+	}
+	img := page.ImageElements[0]
+	if !img.Inline || img.Width != 4 || img.Height != 2 || img.ColorSpace != "G" || img.DataSize != 11 {
+		t.Errorf("内联图像 = %+v", img)
+	}
+	if img.Position.X != 72 || img.Position.Y != 650 || img.Size.Width != 20 {
+		t.Errorf("内联图像位置 (%.0f, %.0f) 宽 %.0f，期望 (72, 650) 宽 20", img.Position.X, img.Position.Y, img.Size.Width)
+	}
+
+	if len(page.TextElements) != 1 || page.TextElements[0].Content != "After the image" {
+		var texts []string
+		for _, elem := range page.TextElements {
+			texts = append(texts, elem.Content)
+		}
+		t.Fatalf("图像之后的文本: %q", texts)
+	}
+}
+
+func TestMergeAdjacentTextElementsJoinsHyphenatedWords(t *testing.T) {
+	p := newTestFlowProcessor(t, "input.pdf", "")
+	ops, err := p.parseOperations("BT /F1 10 Tf 72 700 Td (The inter-) Tj 0 -12 Td (national standard is well-known.) Tj ET")
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := &PDFPageFlow{ContentStreams: []ContentStreamFlow{{ParsedOps: ops}}}
+	if err := p.parseContentElements(page, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(page.TextElements) != 1 {
+		t.Fatalf("文本元素: %+v", page.TextElements)
+	}
+	if got := page.TextElements[0].Content; got != "The international standard is well-known." {
+		t.Errorf("合并结果 = %q", got)
+	}
+}
+
+// writeBlankPagePDF 生成三页PDF，第2页为横向 Letter 尺寸且删除了 Contents（没有内容流的空白页）
+func writeBlankPagePDF(t *testing.T, dir string) string {
+	t.Helper()
+	pdf := gofpdf.New("P", "pt", "A4", "")
+	pdf.SetFont("Helvetica", "", 12)
+	pdf.AddPage()
+	pdf.Text(72, 100, "First page text")
+	pdf.AddPageFormat("L", gofpdf.SizeType{Wd: 612, Ht: 792})
+	pdf.AddPage()
+	pdf.Text(72, 100, "Third page text")
+	path := filepath.Join(dir, "blank.pdf")
+	if err := pdf.OutputFileAndClose(path); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, err := api.ReadContextFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pageDict, _, _, err := ctx.PageDict(2, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pageDict.Delete("Contents")
+	if err := api.WriteContextFile(ctx, path); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestBlankPageRoundTrips(t *testing.T) {
+	dir := t.TempDir()
+	input := writeBlankPagePDF(t, dir)
+	output := filepath.Join(dir, "out.pdf")
+	p := newTestFlowProcessor(t, input, output)
+	if err := p.ProcessPDF(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(p.flowData.Pages) != 3 {
+		t.Fatalf("解析得到 %d 页，期望 3", len(p.flowData.Pages))
+	}
+	blank := p.flowData.Pages[1]
+	if blank.PageNumber != 2 || len(blank.ContentStreams) != 0 || len(blank.TextElements) != 0 {
+		t.Errorf("空白页 = 第 %d 页，%d 个内容流，%d 个文本元素", blank.PageNumber, len(blank.ContentStreams), len(blank.TextElements))
+	}
+	if blank.MediaBox.Width != 792 || blank.MediaBox.Height != 612 {
+		t.Errorf("空白页尺寸 = %.0fx%.0f，期望 792x612", blank.MediaBox.Width, blank.MediaBox.Height)
+	}
+
+	if err := p.GeneratePDF(); err != nil {
+		t.Fatal(err)
+	}
+	regenerated := newTestFlowProcessor(t, output, "")
+	if err := regenerated.parsePDFStructure(); err != nil {
+		t.Fatal(err)
+	}
+	pages := regenerated.flowData.Pages
+	if len(pages) != 3 {
+		t.Fatalf("输出 %d 页，期望 3", len(pages))
+	}
+	for i, want := range []string{"First page text", "", "Third page text"} {
+		if got := pageText(&pages[i]); !strings.Contains(got, want) || (want == "" && got != "") {
+			t.Errorf("输出第 %d 页文本 = %q，期望 %q", i+1, got, want)
+		}
+	}
+	if box := pages[1].MediaBox; math.Abs(box.Width-792) > 1 || math.Abs(box.Height-612) > 1 {
+		t.Errorf("输出空白页尺寸 = %.0fx%.0f，期望 792x612", box.Width, box.Height)
+	}
+}
@@ -6,7 +6,9 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
+	"unicode/utf8"
 
 	"github.com/golang/freetype/truetype"
 	"golang.org/x/image/math/fixed"
@@ -409,6 +411,20 @@ func (fmc *FontMetricsCalculator) WrapText(text string, fontName string, fontSiz
 		return []string{}
 	}
 	
+	// 换行符是强制换行（如双语对照中原文与译文之间），各段分别按宽度换行，空段保留为空行
+	if strings.ContainsAny(text, "\r\n") {
+		lines := []string{}
+		for _, paragraph := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+			paragraph = strings.TrimRight(paragraph, "\r")
+			if wrapped := fmc.WrapText(paragraph, fontName, fontSize, maxWidth); len(wrapped) > 0 {
+				lines = append(lines, wrapped...)
+			} else {
+				lines = append(lines, "")
+			}
+		}
+		return lines
+	}
+
 	words := splitWords(text)
 	lines := []string{}
 	currentLine := ""
@@ -416,7 +432,7 @@ func (fmc *FontMetricsCalculator) WrapText(text string, fontName string, fontSiz
 	for _, word := range words {
 		testLine := currentLine
 		if testLine != "" {
-			testLine += " "
+			testLine += wordSeparator(testLine, word)
 		}
 		testLine += word
 		
@@ -438,6 +454,21 @@ func (fmc *FontMetricsCalculator) WrapText(text string, fontName string, fontSiz
 	return lines
 }
 
+// wordSeparator 返回同一行中相邻两个单词之间的分隔符，中日韩文字和全角标点前后不加空格
+func wordSeparator(previous, word string) string {
+	last, _ := utf8.DecodeLastRuneInString(previous)
+	first, _ := utf8.DecodeRuneInString(word)
+	if isCJKRune(last) || isCJKRune(first) || isFullWidthPunct(last) || isFullWidthPunct(first) {
+		return ""
+	}
+	return " "
+}
+
+// isFullWidthPunct 判断字符是否为中日韩标点或全角符号（如 。、，）
+func isFullWidthPunct(r rune) bool {
+	return (r >= 0x3000 && r <= 0x303f) || (r >= 0xff00 && r <= 0xffef)
+}
+
 // splitWords 分割单词（支持中英文）
 func splitWords(text string) []string {
 	words := []string{}
@@ -0,0 +1,29 @@
+package translator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWrapTextKeepsCJKUnspaced(t *testing.T) {
+	metrics := GetGlobalFontMetrics()
+	tests := []string{
+		"这是一段需要换行的中文译文，每一行都不应出现多余的空格。",
+		"日本語のテキストも、文字の間に空白を入れずに折り返します。",
+		"The quick brown fox jumps over the lazy dog again and again.",
+	}
+	for _, text := range tests {
+		lines := metrics.WrapText(text, "Helvetica", 10, 80)
+		if len(lines) < 2 {
+			t.Errorf("%q 应换成多行，得到 %q", text, lines)
+			continue
+		}
+		sep := ""
+		if !isCJKRune([]rune(text)[0]) {
+			sep = " "
+		}
+		if joined := strings.Join(lines, sep); joined != text {
+			t.Errorf("换行后内容改变:\n得到 %q\n原文 %q", joined, text)
+		}
+	}
+}
@@ -0,0 +1,537 @@
+package translator
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// FormFieldFlow 表单域（AcroForm 终端域）
+type FormFieldFlow struct {
+	Name              string           `json:"name"` // 完整域名，层级之间用 "." 连接
+	Type              string           `json:"type"` // Tx（文本）、Btn（按钮）、Ch（选择）、Sig（签名）
+	Flags             int              `json:"flags"`
+	Value             string           `json:"value"`
+	DefaultValue      string           `json:"default_value"`
+	OriginalValue     string           `json:"original_value,omitempty"` // 翻译前的值
+	Options           []string         `json:"options,omitempty"`
+	DefaultAppearance string           `json:"default_appearance,omitempty"`
+	Quadding          int              `json:"quadding"`
+	MaxLen            int              `json:"max_len,omitempty"`
+	Widgets           []FormWidgetFlow `json:"widgets"`
+}
+
+// FormWidgetFlow 表单域在页面上的控件
+type FormWidgetFlow struct {
+	PageNumber int         `json:"page_number"`
+	Rect       BoundingBox `json:"rect"` // 相对页面左上角的位置（Y为控件顶部到页面顶部的距离）
+	Flags      int         `json:"flags"`
+	State      string      `json:"state,omitempty"` // 按钮的外观状态（AS）
+}
+
+// formFieldAttrs 可由子域继承的域属性
+type formFieldAttrs struct {
+	fieldType  string
+	flags      int
+	value      types.Object
+	defaultVal types.Object
+	da         string
+	quadding   int
+}
+
+// defaultAppearanceFontSize 匹配默认外观字符串中的字号，如 "/Helv 12 Tf"
+var defaultAppearanceFontSize = regexp.MustCompile(`/\S+\s+([\d.]+)\s+Tf`)
+
+// extractFormFields 解析文档的 AcroForm 表单域，需要在页面解析之后调用（使用页面尺寸换算控件位置）
+func (p *PDFFlowProcessor) extractFormFields(ctx *model.Context) error {
+	acroFormObj, found := ctx.RootDict.Find("AcroForm")
+	if !found {
+		return nil
+	}
+	acroForm, err := ctx.DereferenceDict(acroFormObj)
+	if err != nil || acroForm == nil {
+		return err
+	}
+	fields, err := ctx.DereferenceArray(acroForm["Fields"])
+	if err != nil {
+		return err
+	}
+
+	// 控件对象号 -> 页码
+	widgetPages := make(map[int]int)
+	for pageNum := 1; pageNum <= ctx.PageCount; pageNum++ {
+		pageDict, _, _, err := ctx.PageDict(pageNum, false)
+		if err != nil || pageDict == nil {
+			continue
+		}
+		annots, err := ctx.DereferenceArray(pageDict["Annots"])
+		if err != nil {
+			continue
+		}
+		for _, annot := range annots {
+			if ref, ok := annot.(types.IndirectRef); ok {
+				widgetPages[ref.ObjectNumber.Value()] = pageNum
+			}
+		}
+	}
+
+	da := ""
+	if s, err := ctx.DereferenceText(acroForm["DA"]); err == nil {
+		da = s
+	}
+	for _, field := range fields {
+		p.walkFormField(ctx, field, "", formFieldAttrs{da: da}, widgetPages)
+	}
+
+	if len(p.flowData.FormFields) > 0 {
+		p.logger.Info("提取表单域完成", map[string]interface{}{
+			"表单域数量": len(p.flowData.FormFields),
+		})
+	}
+	return nil
+}
+
+// walkFormField 递归遍历域树，收集终端域及其控件
+func (p *PDFFlowProcessor) walkFormField(ctx *model.Context, obj types.Object, parentName string, inherited formFieldAttrs, widgetPages map[int]int) {
+	dict, err := ctx.DereferenceDict(obj)
+	if err != nil || dict == nil {
+		return
+	}
+
+	name := parentName
+	if partial, err := ctx.DereferenceText(dict["T"]); err == nil && partial != "" {
+		if name != "" {
+			name += "."
+		}
+		name += partial
+	}
+
+	attrs := inherited
+	if ft := dict.NameEntry("FT"); ft != nil {
+		attrs.fieldType = *ft
+	}
+	if ff := dict.IntEntry("Ff"); ff != nil {
+		attrs.flags = *ff
+	}
+	if v, found := dict.Find("V"); found {
+		attrs.value = v
+	}
+	if dv, found := dict.Find("DV"); found {
+		attrs.defaultVal = dv
+	}
+	if s, err := ctx.DereferenceText(dict["DA"]); err == nil && s != "" {
+		attrs.da = s
+	}
+	if q := dict.IntEntry("Q"); q != nil {
+		attrs.quadding = *q
+	}
+
+	// 子节点中含有域名的是子域，否则是当前域的控件
+	kids, _ := ctx.DereferenceArray(dict["Kids"])
+	var widgets []types.Object
+	for _, kid := range kids {
+		kidDict, err := ctx.DereferenceDict(kid)
+		if err != nil || kidDict == nil {
+			continue
+		}
+		if _, isField := kidDict.Find("T"); isField {
+			p.walkFormField(ctx, kid, name, attrs, widgetPages)
+		} else {
+			widgets = append(widgets, kid)
+		}
+	}
+	if len(kids) > 0 && len(widgets) == 0 {
+		return
+	}
+	if len(kids) == 0 {
+		// 域与控件合并在同一个字典中
+		widgets = []types.Object{obj}
+	}
+	if name == "" || attrs.fieldType == "" {
+		return
+	}
+
+	field := FormFieldFlow{
+		Name:              name,
+		Type:              attrs.fieldType,
+		Flags:             attrs.flags,
+		Value:             p.formValueString(ctx, attrs.value),
+		DefaultValue:      p.formValueString(ctx, attrs.defaultVal),
+		DefaultAppearance: attrs.da,
+		Quadding:          attrs.quadding,
+	}
+	if maxLen := dict.IntEntry("MaxLen"); maxLen != nil {
+		field.MaxLen = *maxLen
+	}
+	if opts, err := ctx.DereferenceArray(dict["Opt"]); err == nil {
+		for _, opt := range opts {
+			// 选项可以是 [导出值 显示文本] 数组，保留导出值以便与域值对应
+			if pair, ok := opt.(types.Array); ok && len(pair) > 0 {
+				opt = pair[0]
+			}
+			if s, err := ctx.DereferenceText(opt); err == nil {
+				field.Options = append(field.Options, s)
+			}
+		}
+	}
+
+	for _, widgetObj := range widgets {
+		widgetDict, err := ctx.DereferenceDict(widgetObj)
+		if err != nil || widgetDict == nil {
+			continue
+		}
+		widget := FormWidgetFlow{}
+		if ref, ok := widgetObj.(types.IndirectRef); ok {
+			widget.PageNumber = widgetPages[ref.ObjectNumber.Value()]
+		}
+		if widget.PageNumber == 0 {
+			if pageRef, ok := widgetDict["P"].(types.IndirectRef); ok {
+				widget.PageNumber = p.pageNumberForRef(ctx, pageRef)
+			}
+		}
+		if widget.PageNumber == 0 {
+			continue
+		}
+		if f := widgetDict.IntEntry("F"); f != nil {
+			widget.Flags = *f
+		}
+		if as := widgetDict.NameEntry("AS"); as != nil {
+			widget.State = *as
+		}
+		rect, err := ctx.DereferenceArray(widgetDict["Rect"])
+		if err != nil || len(rect) < 4 {
+			continue
+		}
+		widget.Rect = p.widgetRectFromPDF(widget.PageNumber, rect)
+		field.Widgets = append(field.Widgets, widget)
+	}
+
+	if len(field.Widgets) > 0 {
+		p.flowData.FormFields = append(p.flowData.FormFields, field)
+	}
+}
+
+// formValueString 将域值（文本或名称）转换为字符串
+func (p *PDFFlowProcessor) formValueString(ctx *model.Context, obj types.Object) string {
+	if obj == nil {
+		return ""
+	}
+	obj, err := ctx.Dereference(obj)
+	if err != nil || obj == nil {
+		return ""
+	}
+	if name, ok := obj.(types.Name); ok {
+		return name.Value()
+	}
+	if s, err := model.Text(obj); err == nil {
+		return s
+	}
+	return ""
+}
+
+// pageNumberForRef 根据页面对象引用查找页码，未找到返回0
+func (p *PDFFlowProcessor) pageNumberForRef(ctx *model.Context, pageRef types.IndirectRef) int {
+	for pageNum := 1; pageNum <= ctx.PageCount; pageNum++ {
+		if _, ref, _, err := ctx.PageDict(pageNum, false); err == nil && ref != nil && ref.ObjectNumber == pageRef.ObjectNumber {
+			return pageNum
+		}
+	}
+	return 0
+}
+
+// widgetRectFromPDF 将PDF坐标系（左下角原点）的控件矩形转换为相对页面左上角的位置
+func (p *PDFFlowProcessor) widgetRectFromPDF(pageNumber int, rect types.Array) BoundingBox {
+	x1, y1 := p.getFloatValue(rect[0]), p.getFloatValue(rect[1])
+	x2, y2 := p.getFloatValue(rect[2]), p.getFloatValue(rect[3])
+	if x1 > x2 {
+		x1, x2 = x2, x1
+	}
+	if y1 > y2 {
+		y1, y2 = y2, y1
+	}
+
+	mediaBox := p.pageMediaBox(pageNumber)
+	return BoundingBox{
+		X:      x1 - mediaBox.X,
+		Y:      mediaBox.Y + mediaBox.Height - y2,
+		Width:  x2 - x1,
+		Height: y2 - y1,
+	}
+}
+
+// pageMediaBox 返回已解析页面的 MediaBox，缺失时使用A4尺寸
+func (p *PDFFlowProcessor) pageMediaBox(pageNumber int) BoundingBox {
+	for _, page := range p.flowData.Pages {
+		if page.PageNumber == pageNumber && page.MediaBox.Height > 0 {
+			return page.MediaBox
+		}
+	}
+	if mediaBox, ok := p.pageBoxes[pageNumber]; ok && mediaBox.Height > 0 {
+		return mediaBox
+	}
+	return BoundingBox{Width: 595.28, Height: 841.89}
+}
+
+// translateFormFields 翻译文本域的值和默认值，其余类型的域保持原值
+func (p *PDFFlowProcessor) translateFormFields(index *translationIndex) int {
+	translated := 0
+	for i := range p.flowData.FormFields {
+		field := &p.flowData.FormFields[i]
+		if field.Type != "Tx" || strings.TrimSpace(field.Value) == "" {
+			continue
+		}
+		translation := p.findBestTranslation(field.Value, index)
+		if translation == "" {
+			continue
+		}
+		if field.DefaultValue == field.Value {
+			field.DefaultValue = translation
+		}
+		field.OriginalValue = field.Value
+		field.Value = translation
+		translated++
+	}
+	return translated
+}
+
+// writeFormFields 将表单域重新写入生成的PDF，使表单保持可填写
+// 控件外观由阅读器根据 NeedAppearances 重新生成
+func (p *PDFFlowProcessor) writeFormFields() error {
+	ctx, err := api.ReadContextFile(p.outputPath)
+	if err != nil {
+		return fmt.Errorf("读取生成的PDF失败: %w", err)
+	}
+
+	// 页码 -> 页面字典和引用
+	pageRefs := make(map[int]*types.IndirectRef)
+	pageDicts := make(map[int]types.Dict)
+	for pageNum := 1; pageNum <= ctx.PageCount; pageNum++ {
+		pageDict, pageRef, _, err := ctx.PageDict(pageNum, false)
+		if err != nil {
+			return err
+		}
+		pageRefs[pageNum], pageDicts[pageNum] = pageRef, pageDict
+	}
+
+	var rootFields types.Array
+	parents := make(map[string]*types.IndirectRef) // 中间层级域名 -> 引用
+
+	for _, field := range p.flowData.FormFields {
+		parentName, partialName := "", field.Name
+		if idx := strings.LastIndex(field.Name, "."); idx >= 0 {
+			parentName, partialName = field.Name[:idx], field.Name[idx+1:]
+		}
+
+		fieldDict := types.Dict{
+			"FT": types.Name(field.Type),
+			"T":  pdfTextString(partialName),
+			"DA": types.StringLiteral(normalizeDefaultAppearance(field.DefaultAppearance)),
+		}
+		if field.Flags != 0 {
+			fieldDict["Ff"] = types.Integer(field.Flags)
+		}
+		if field.Quadding != 0 {
+			fieldDict["Q"] = types.Integer(field.Quadding)
+		}
+		if field.MaxLen > 0 {
+			fieldDict["MaxLen"] = types.Integer(field.MaxLen)
+		}
+		if field.Value != "" {
+			fieldDict["V"] = formValueObject(field.Type, field.Value)
+		}
+		if field.DefaultValue != "" {
+			fieldDict["DV"] = formValueObject(field.Type, field.DefaultValue)
+		}
+		if len(field.Options) > 0 {
+			opts := make(types.Array, 0, len(field.Options))
+			for _, opt := range field.Options {
+				opts = append(opts, pdfTextString(opt))
+			}
+			fieldDict["Opt"] = opts
+		}
+
+		fieldRef, err := ctx.IndRefForNewObject(fieldDict)
+		if err != nil {
+			return err
+		}
+
+		// 单个控件与域合并，多个控件作为子节点
+		var kids types.Array
+		for _, widget := range field.Widgets {
+			pageRef := pageRefs[widget.PageNumber]
+			if pageRef == nil {
+				continue
+			}
+			widgetDict := fieldDict
+			if len(field.Widgets) > 1 {
+				widgetDict = types.Dict{"Parent": *fieldRef}
+			}
+			widgetDict["Type"] = types.Name("Annot")
+			widgetDict["Subtype"] = types.Name("Widget")
+			widgetDict["P"] = *pageRef
+			widgetDict["F"] = types.Integer(widget.Flags)
+			widgetDict["Rect"] = p.widgetRectToPDF(ctx, pageDicts[widget.PageNumber], widget.Rect)
+			if widget.State != "" {
+				widgetDict["AS"] = types.Name(widget.State)
+			}
+
+			widgetRef := fieldRef
+			if len(field.Widgets) > 1 {
+				if widgetRef, err = ctx.IndRefForNewObject(widgetDict); err != nil {
+					return err
+				}
+				kids = append(kids, *widgetRef)
+			}
+			if err := appendPageAnnot(ctx, pageDicts[widget.PageNumber], *widgetRef); err != nil {
+				return err
+			}
+		}
+		if len(kids) > 0 {
+			fieldDict["Kids"] = kids
+		}
+
+		// 按域名层级挂到父域下
+		if parentName == "" {
+			rootFields = append(rootFields, *fieldRef)
+			continue
+		}
+		parentRef, err := formParentRef(ctx, parents, parentName, &rootFields)
+		if err != nil {
+			return err
+		}
+		fieldDict["Parent"] = *parentRef
+		parentDict, err := ctx.DereferenceDict(*parentRef)
+		if err != nil {
+			return err
+		}
+		parentDict["Kids"] = append(parentDict.ArrayEntry("Kids"), *fieldRef)
+	}
+
+	fontRef, err := ctx.IndRefForNewObject(types.Dict{
+		"Type":     types.Name("Font"),
+		"Subtype":  types.Name("Type1"),
+		"BaseFont": types.Name("Helvetica"),
+		"Encoding": types.Name("WinAnsiEncoding"),
+	})
+	if err != nil {
+		return err
+	}
+	ctx.RootDict["AcroForm"] = types.Dict{
+		"Fields":          rootFields,
+		"NeedAppearances": types.Boolean(true),
+		"DA":              types.StringLiteral("/Helv 0 Tf 0 g"),
+		"DR":              types.Dict{"Font": types.Dict{"Helv": *fontRef}},
+	}
+
+	tmpPath := p.outputPath + ".form.tmp"
+	if err := api.WriteContextFile(ctx, tmpPath); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("写入表单域失败: %w", err)
+	}
+	return os.Rename(tmpPath, p.outputPath)
+}
+
+// widgetRectToPDF 将相对页面左上角的位置转换为输出页面的PDF矩形
+func (p *PDFFlowProcessor) widgetRectToPDF(ctx *model.Context, pageDict types.Dict, rect BoundingBox) types.Array {
+	pageHeight := 841.89
+	if mediaBox, err := ctx.DereferenceArray(pageDict["MediaBox"]); err == nil && len(mediaBox) >= 4 {
+		pageHeight = p.getFloatValue(mediaBox[3]) - p.getFloatValue(mediaBox[1])
+	}
+	top := pageHeight - rect.Y
+	return types.NewNumberArray(rect.X, top-rect.Height, rect.X+rect.Width, top)
+}
+
+// formParentRef 返回中间层级域的引用，不存在时逐级创建
+func formParentRef(ctx *model.Context, parents map[string]*types.IndirectRef, name string, rootFields *types.Array) (*types.IndirectRef, error) {
+	if ref, ok := parents[name]; ok {
+		return ref, nil
+	}
+
+	parentName, partialName := "", name
+	if idx := strings.LastIndex(name, "."); idx >= 0 {
+		parentName, partialName = name[:idx], name[idx+1:]
+	}
+
+	dict := types.Dict{"T": pdfTextString(partialName)}
+	ref, err := ctx.IndRefForNewObject(dict)
+	if err != nil {
+		return nil, err
+	}
+	parents[name] = ref
+
+	if parentName == "" {
+		*rootFields = append(*rootFields, *ref)
+		return ref, nil
+	}
+	grandParent, err := formParentRef(ctx, parents, parentName, rootFields)
+	if err != nil {
+		return nil, err
+	}
+	dict["Parent"] = *grandParent
+	grandParentDict, err := ctx.DereferenceDict(*grandParent)
+	if err != nil {
+		return nil, err
+	}
+	grandParentDict["Kids"] = append(grandParentDict.ArrayEntry("Kids"), *ref)
+	return ref, nil
+}
+
+// appendPageAnnot 将注释引用加入页面的 Annots 数组
+func appendPageAnnot(ctx *model.Context, pageDict types.Dict, ref types.IndirectRef) error {
+	annotsObj, found := pageDict.Find("Annots")
+	if !found {
+		pageDict["Annots"] = types.Array{ref}
+		return nil
+	}
+	if annotsRef, ok := annotsObj.(types.IndirectRef); ok {
+		annots, err := ctx.DereferenceArray(annotsRef)
+		if err != nil {
+			return err
+		}
+		entry, found := ctx.FindTableEntryForIndRef(&annotsRef)
+		if !found {
+			return fmt.Errorf("页面注释数组不存在")
+		}
+		entry.Object = append(annots, ref)
+		return nil
+	}
+	annots, _ := annotsObj.(types.Array)
+	pageDict["Annots"] = append(annots, ref)
+	return nil
+}
+
+// normalizeDefaultAppearance 将默认外观改为使用表单资源中的 Helv 字体，保留原字号
+func normalizeDefaultAppearance(da string) string {
+	size := "0"
+	if m := defaultAppearanceFontSize.FindStringSubmatch(da); m != nil {
+		size = m[1]
+	}
+	return "/Helv " + size + " Tf 0 g"
+}
+
+// formValueObject 按域类型生成域值对象：按钮使用名称，其余使用文本
+func formValueObject(fieldType, value string) types.Object {
+	if fieldType == "Btn" {
+		return types.Name(value)
+	}
+	return pdfTextString(value)
+}
+
+// pdfTextString 生成PDF文本字符串，非ASCII文本使用 UTF-16BE 编码
+func pdfTextString(s string) types.Object {
+	for _, r := range s {
+		if r > 0x7E || r < 0x20 {
+			return types.NewHexLiteral([]byte(types.EncodeUTF16String(s)))
+		}
+	}
+	escaped, err := types.Escape(s)
+	if err != nil {
+		return types.NewHexLiteral([]byte(types.EncodeUTF16String(s)))
+	}
+	return types.StringLiteral(*escaped)
+}
@@ -0,0 +1,79 @@
+package translator
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeRawPDF 按顺序写入对象（对象号从1开始，第1个对象为文档目录）并生成交叉引用表
+func writeRawPDF(t *testing.T, path string, objects []string) {
+	t.Helper()
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRegeneratePDFKeepsFormField(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "form.pdf")
+	content := "BT /F1 12 Tf 72 700 Td (Please enter your name below.) Tj ET"
+	writeRawPDF(t, input, []string{
+		"<< /Type /Catalog /Pages 2 0 R /AcroForm << /Fields [5 0 R] /DA (/Helv 12 Tf) /DR << /Font << /Helv 6 0 R >> >> >> >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R /Resources << /Font << /F1 6 0 R >> >> /Annots [5 0 R] >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content),
+		"<< /Type /Annot /Subtype /Widget /FT /Tx /T (name) /V (Your name) /DV (Your name) /Rect [72 640 300 660] /P 3 0 R /F 4 >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+	})
+
+	output := filepath.Join(dir, "out.pdf")
+	t.Chdir(dir)
+	r := NewPDFRegenerator()
+	r.TargetLanguage = "fr"
+	translations := map[string]string{
+		"Please enter your name below.": "Veuillez saisir votre nom ci-dessous.",
+		"Your name":                     "Votre nom",
+	}
+	if err := r.RegeneratePDF(input, output, translations); err != nil {
+		t.Fatal(err)
+	}
+
+	p := newTestFlowProcessor(t, output, "")
+	ctx, err := p.readPDFContext()
+	if err != nil {
+		t.Fatalf("读取输出PDF失败: %v", err)
+	}
+	if err := p.extractFormFields(ctx); err != nil {
+		t.Fatal(err)
+	}
+	fields := p.flowData.FormFields
+	if len(fields) != 1 {
+		t.Fatalf("输出PDF中的表单域: %+v", fields)
+	}
+	field := fields[0]
+	if field.Name != "name" || field.Type != "Tx" {
+		t.Errorf("表单域 = %s (%s)，期望 name (Tx)", field.Name, field.Type)
+	}
+	if field.Value != "Votre nom" || field.DefaultValue != "Votre nom" {
+		t.Errorf("表单域的值 = %q，默认值 = %q，期望译文", field.Value, field.DefaultValue)
+	}
+	if len(field.Widgets) != 1 || field.Widgets[0].PageNumber != 1 {
+		t.Errorf("表单域控件 = %+v，期望位于第1页", field.Widgets)
+	}
+}
@@ -103,6 +103,7 @@ func (pri *PDFReplacementIntegration) TranslatePDFWithReplacement(request PDFRep
 		}
 	}
 
+	pri.translatorIntegration.Client.Pages = TextPages(content)
 	translations, err := pri.translatorIntegration.TranslateTexts(texts, request.TargetLanguage, request.UserPrompt, translationProgressCallback)
 	if err != nil {
 		return nil, fmt.Errorf("翻译失败: %w", err)
@@ -268,13 +269,14 @@ func (pri *PDFReplacementIntegration) performBasicContentReplacement(request PDF
 		}
 
 		// 对于双语模式，需要构建双语文本映射
+		aligner := NewSentenceAligner().WithLanguages("", request.TargetLanguage)
 		bilingualMappings := make(map[string]string)
 		for original, translation := range translations {
 			switch request.BilingualLayout {
 			case BilingualLayoutSideBySide:
 				bilingualMappings[original] = original + " | " + translation
 			case BilingualLayoutInterleaved:
-				bilingualMappings[original] = original + "\n" + translation
+				bilingualMappings[original] = aligner.Interleave(original, translation)
 			default: // BilingualLayoutTopBottom
 				bilingualMappings[original] = original + "\n" + translation
 			}
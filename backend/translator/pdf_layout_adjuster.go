@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"math"
+	"strings"
 )
 
 // LayoutAdjuster 布局调整器
@@ -53,8 +54,8 @@ func (la *LayoutAdjuster) AdjustTextLayout(
 	log.Printf("宽度对比: 原文=%.2f, 译文=%.2f, 容器=%.2f", 
 		originalWidth, translatedWidth, originalBox.Width)
 	
-	// 2. 检查是否需要调整
-	if translatedWidth <= originalBox.Width {
+	// 2. 检查是否需要调整（含换行符的文本需要分行输出）
+	if translatedWidth <= originalBox.Width && !strings.Contains(translatedText, "\n") {
 		// 单行即可容纳
 		return &AdjustedLayout{
 			Text:        translatedText,
@@ -144,54 +145,78 @@ func (la *LayoutAdjuster) adjustWithShrinking(
 ) (*AdjustedLayout, error) {
 	
 	baseSpacing := la.getBaseLineSpacing(targetLang)
-	currentFontSize := font.Size
-	minFontSize := font.Size * 0.7 // 最小缩小到70%
-	
-	for i := 0; i < la.maxIterations; i++ {
-		// 换行
-		lines := la.fontMetrics.WrapText(text, font.Name, currentFontSize, originalBox.Width)
-		
-		// 计算实际高度
-		actualHeight := float64(len(lines)) * currentFontSize * baseSpacing
-		
-		log.Printf("缩小尝试 %d: 字号=%.2f, 行数=%d, 高度=%.2f/%.2f", 
-			i+1, currentFontSize, len(lines), actualHeight, originalBox.Height)
-		
-		if actualHeight <= originalBox.Height {
-			// 成功容纳
-			return &AdjustedLayout{
-				Text:        text,
-				Lines:       lines,
-				BoundingBox: originalBox,
-				FontSize:    currentFontSize,
-				LineSpacing: baseSpacing,
-				Adjusted:    true,
-				Method:      "shrink",
-			}, nil
-		}
-		
-		// 减小字号
-		currentFontSize -= 0.5
-		if currentFontSize < minFontSize {
-			break
-		}
-	}
-	
-	// 无法完全容纳，返回最小字号
-	lines := la.fontMetrics.WrapText(text, font.Name, minFontSize, originalBox.Width)
-	
+	fontSize, lines, ok := fitTextToBox(la.fontMetrics, text, originalBox, font, baseSpacing, font.Size*0.7) // 最小缩小到70%
+	log.Printf("缩小字号: %.2f -> %.2f, 行数=%d, 容纳=%v", font.Size, fontSize, len(lines), ok)
+
 	return &AdjustedLayout{
 		Text:        text,
 		Lines:       lines,
 		BoundingBox: originalBox,
-		FontSize:    minFontSize,
+		FontSize:    fontSize,
 		LineSpacing: baseSpacing,
 		Adjusted:    true,
 		Method:      "shrink",
-		Overflow:    true,
+		Overflow:    !ok,
 	}, nil
 }
 
+// fitFontSizeFloor FitTextToBox 缩小字号的下限
+const fitFontSizeFloor = 6.0
+
+// fitLineSpacing FitTextToBox 使用的行距系数，与 GetFontMetrics 的行高一致
+const fitLineSpacing = 1.2
+
+// FitTextToBox 计算文本在边界框内能使用的最大字号及该字号下的换行结果
+// 在下限字号和 font.Size 之间二分查找，按字体度量换行后每行宽度和总高度都不超出边界框；
+// 下限字号仍放不下时返回下限字号
+func FitTextToBox(text string, box BoundingBox, font FontFlow) (float64, []string) {
+	fontSize, lines, _ := fitTextToBox(GetGlobalFontMetrics(), text, box, font, fitLineSpacing, fitFontSizeFloor)
+	return fontSize, lines
+}
+
+// fitTextToBox FitTextToBox 的实现，可指定行距系数和字号下限，ok 表示找到了能放下的字号
+// 边界框的高度至少按原字号的一行计算，避免边界框比行高略矮的单行文本被缩小
+func fitTextToBox(metrics *FontMetricsCalculator, text string, box BoundingBox, font FontFlow, lineSpacing, minSize float64) (float64, []string, bool) {
+	maxSize := font.Size
+	if maxSize <= 0 {
+		maxSize = 12
+	}
+	minSize = math.Min(minSize, maxSize)
+	height := math.Max(box.Height, maxSize*lineSpacing)
+
+	fits := func(size float64) ([]string, bool) {
+		lines := metrics.WrapText(text, font.Name, size, box.Width)
+		if float64(len(lines))*size*lineSpacing > height {
+			return lines, false
+		}
+		for _, line := range lines {
+			if metrics.CalculateTextWidth(line, font.Name, size) > box.Width {
+				return lines, false
+			}
+		}
+		return lines, true
+	}
+
+	if lines, ok := fits(maxSize); ok {
+		return maxSize, lines, true
+	}
+	lines, ok := fits(minSize)
+	if !ok {
+		return minSize, lines, false
+	}
+	low, high := minSize, maxSize
+	for high-low > 0.1 {
+		mid := (low + high) / 2
+		if midLines, ok := fits(mid); ok {
+			low, lines = mid, midLines
+		} else {
+			high = mid
+		}
+	}
+	// 向下取整后字号更小，原来的换行结果仍能放下
+	return math.Max(minSize, math.Floor(low*10)/10), lines, true
+}
+
 // adjustWithTruncation 使用截断调整
 func (la *LayoutAdjuster) adjustWithTruncation(
 	originalBox BoundingBox,
@@ -250,7 +275,7 @@ type AdjustedLayout struct {
 	FontSize    float64     // 字体大小
 	LineSpacing float64     // 行距系数
 	Adjusted    bool        // 是否进行了调整
-	Method      string      // 调整方法: "wrap", "shrink", "truncate"
+	Method      string      // 调整方法: "wrap", "shrink", "truncate", "fit"（FitTextToBox）
 	Overflow    bool        // 是否仍然溢出
 }
 
@@ -0,0 +1,39 @@
+package translator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFitTextToBoxShrinksLongTranslation(t *testing.T) {
+	box := BoundingBox{Width: 200, Height: 30}
+	font := FontFlow{Name: "Helvetica", Size: 12}
+
+	shortSize, shortLines := FitTextToBox("Résultats", box, font)
+	if shortSize != 12 || len(shortLines) != 1 {
+		t.Errorf("短译文字号 = %.1f，%d 行，期望保持 12 且不换行", shortSize, len(shortLines))
+	}
+
+	long := "Les résultats expérimentaux montrent une amélioration nette de la précision sur tous les jeux de données évalués."
+	longSize, longLines := FitTextToBox(long, box, font)
+	if longSize >= shortSize || longSize < fitFontSizeFloor {
+		t.Errorf("长译文字号 = %.1f，期望小于 %.1f 且不低于 %.1f", longSize, shortSize, fitFontSizeFloor)
+	}
+	if strings.Join(longLines, " ") != long {
+		t.Errorf("换行结果 = %q，拼接后应为原译文", longLines)
+	}
+	metrics := GetGlobalFontMetrics()
+	for _, line := range longLines {
+		if w := metrics.CalculateTextWidth(line, font.Name, longSize); w > box.Width {
+			t.Errorf("行 %q 宽 %.1f，超出边界框 %.1f", line, w, box.Width)
+		}
+	}
+	if h := float64(len(longLines)) * longSize * fitLineSpacing; h > box.Height {
+		t.Errorf("%d 行总高 %.1f，超出边界框 %.1f", len(longLines), h, box.Height)
+	}
+
+	// 下限字号仍放不下时使用下限字号
+	if size, _ := FitTextToBox(strings.Repeat(long+" ", 10), box, font); size != fitFontSizeFloor {
+		t.Errorf("放不下的译文字号 = %.1f，期望下限 %.1f", size, fitFontSizeFloor)
+	}
+}
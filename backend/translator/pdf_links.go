@@ -0,0 +1,179 @@
+package translator
+
+import (
+	"math"
+
+	"github.com/jung-kurt/gofpdf"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// LinkDest 文档内跳转的目标位置
+type LinkDest struct {
+	PageNumber int     `json:"page_number"` // 原文档中的目标页码
+	Top        float64 `json:"top"`         // 目标位置的Y坐标（PDF坐标系），HasTop 为 false 时跳转到页面顶部
+	HasTop     bool    `json:"has_top"`
+}
+
+// linkAnnotation 解析链接注释，不是链接或没有可用目标时返回 false
+func (p *PDFFlowProcessor) linkAnnotation(ctx *model.Context, obj types.Object, mediaBox BoundingBox) (AnnotationFlow, bool) {
+	annot, err := ctx.DereferenceDict(obj)
+	if err != nil || annot == nil || annot.NameEntry("Subtype") == nil || *annot.NameEntry("Subtype") != "Link" {
+		return AnnotationFlow{}, false
+	}
+	rect, err := ctx.DereferenceArray(annot["Rect"])
+	if err != nil || len(rect) < 4 {
+		return AnnotationFlow{}, false
+	}
+
+	link := AnnotationFlow{Type: "Link", Rect: p.linkRectFromPDF(rect, mediaBox)}
+	if destObj, found := annot.Find("Dest"); found {
+		link.Dest = p.resolveLinkDest(ctx, destObj)
+	} else if action, err := ctx.DereferenceDict(annot["A"]); err == nil && action != nil {
+		switch s := action.NameEntry("S"); {
+		case s == nil:
+		case *s == "GoTo":
+			link.Dest = p.resolveLinkDest(ctx, action["D"])
+		case *s == "URI":
+			if uri, err := ctx.DereferenceStringEntryBytes(action, "URI"); err == nil {
+				link.URI = string(uri)
+			}
+		}
+	}
+
+	if link.Dest == nil && link.URI == "" {
+		return AnnotationFlow{}, false
+	}
+	return link, true
+}
+
+// linkRectFromPDF 将链接矩形转换为相对页面左上角的位置
+// 与 widgetRectFromPDF 不同，这里在逐页解析时调用，直接使用当前页面的 MediaBox
+func (p *PDFFlowProcessor) linkRectFromPDF(rect types.Array, mediaBox BoundingBox) BoundingBox {
+	x1, y1 := p.getFloatValue(rect[0]), p.getFloatValue(rect[1])
+	x2, y2 := p.getFloatValue(rect[2]), p.getFloatValue(rect[3])
+	if x1 > x2 {
+		x1, x2 = x2, x1
+	}
+	if y1 > y2 {
+		y1, y2 = y2, y1
+	}
+	if mediaBox.Height <= 0 {
+		mediaBox = BoundingBox{Width: 595.28, Height: 841.89}
+	}
+	return BoundingBox{
+		X:      x1 - mediaBox.X,
+		Y:      mediaBox.Y + mediaBox.Height - y2,
+		Width:  x2 - x1,
+		Height: y2 - y1,
+	}
+}
+
+// resolveLinkDest 解析跳转目标：显式目标数组，或通过名称树/Dests 字典查找的命名目标
+func (p *PDFFlowProcessor) resolveLinkDest(ctx *model.Context, obj types.Object) *LinkDest {
+	obj, err := ctx.Dereference(obj)
+	if err != nil || obj == nil {
+		return nil
+	}
+
+	var dest types.Array
+	switch o := obj.(type) {
+	case types.Array:
+		dest = o
+	case types.Dict:
+		// 命名目标可以是包含 D 项的字典
+		if dest, err = ctx.DereferenceArray(o["D"]); err != nil {
+			return nil
+		}
+	default:
+		name, err := ctx.DestName(o)
+		if err != nil || name == "" {
+			return nil
+		}
+		_ = ctx.LocateNameTree("Dests", false)
+		if dest, err = ctx.DereferenceDestArray(name); err != nil {
+			return nil
+		}
+	}
+	if len(dest) == 0 {
+		return nil
+	}
+
+	result := &LinkDest{}
+	switch page := dest[0].(type) {
+	case types.IndirectRef:
+		result.PageNumber = p.pageNumberForRef(ctx, page)
+	case types.Integer:
+		// 远程跳转风格的目标使用从0开始的页索引
+		result.PageNumber = page.Value() + 1
+	}
+	if result.PageNumber < 1 || result.PageNumber > ctx.PageCount {
+		return nil
+	}
+
+	// 目标类型决定Y坐标所在的位置：[page /XYZ left top zoom]、[page /FitH top]、[page /FitR left bottom right top]
+	if len(dest) > 1 {
+		if kind, ok := dest[1].(types.Name); ok {
+			topIndex := -1
+			switch kind.Value() {
+			case "XYZ":
+				topIndex = 3
+			case "FitH", "FitBH":
+				topIndex = 2
+			case "FitR":
+				topIndex = 5
+			}
+			if topIndex > 0 && topIndex < len(dest) && dest[topIndex] != nil {
+				if _, isNull := dest[topIndex].(types.Name); !isNull {
+					result.Top = p.getFloatValue(dest[topIndex])
+					result.HasTop = true
+				}
+			}
+		}
+	}
+	return result
+}
+
+// outputPageNumbers 原文档页码 -> 生成文档中的页码（按生成顺序，每个流页面对应一页输出）
+func outputPageNumbers(pages []PDFPageFlow) map[int]int {
+	pageMap := make(map[int]int, len(pages))
+	for i, page := range pages {
+		pageMap[page.PageNumber] = i + 1
+	}
+	return pageMap
+}
+
+// renderLinks 在当前页面重建链接注释，文档内跳转的目标页通过 pageMap 换算为输出页码
+func (p *PDFFlowProcessor) renderLinks(pdf *gofpdf.Fpdf, page PDFPageFlow, pageMap map[int]int) {
+	for _, annot := range page.Annotations {
+		if annot.Type != "Link" {
+			continue
+		}
+		rect := annot.Rect
+
+		if annot.URI != "" {
+			pdf.LinkString(rect.X, rect.Y, rect.Width, rect.Height, annot.URI)
+			continue
+		}
+		if annot.Dest == nil {
+			continue
+		}
+
+		outPage, ok := pageMap[annot.Dest.PageNumber]
+		if !ok {
+			p.logger.Warn("链接目标页面不在输出文档中，已跳过", map[string]interface{}{
+				"页码":   page.PageNumber,
+				"目标页码": annot.Dest.PageNumber,
+			})
+			continue
+		}
+		y := 0.0
+		if annot.Dest.HasTop {
+			mediaBox := p.pageMediaBox(annot.Dest.PageNumber)
+			y = math.Max(0, mediaBox.Y+mediaBox.Height-annot.Dest.Top)
+		}
+		link := pdf.AddLink()
+		pdf.SetLink(link, y, outPage)
+		pdf.Link(rect.X, rect.Y, rect.Width, rect.Height, link)
+	}
+}
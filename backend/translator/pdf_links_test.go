@@ -0,0 +1,118 @@
+package translator
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// writeLinkedPDF 生成三页PDF，第1页的引用标记链接到第3页的参考文献
+func writeLinkedPDF(t *testing.T, dir string) string {
+	t.Helper()
+	pdf := gofpdf.New("P", "pt", "A4", "")
+	pdf.SetFont("Helvetica", "", 12)
+	refs := pdf.AddLink()
+	for i, text := range []string{"See reference [1] for details.", "Methods and results.", "References"} {
+		pdf.AddPage()
+		pdf.Text(72, 100, text)
+		if i == 0 {
+			pdf.Link(150, 88, 20, 16, refs)
+			pdf.LinkString(72, 200, 100, 16, "https://example.com/paper")
+		}
+	}
+	pdf.SetLink(refs, 300, 3)
+	path := filepath.Join(dir, "linked.pdf")
+	if err := pdf.OutputFileAndClose(path); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// pageLinks 返回流数据中指定页码的链接注释
+func pageLinks(p *PDFFlowProcessor, pageNumber int) []AnnotationFlow {
+	var links []AnnotationFlow
+	for _, page := range p.flowData.Pages {
+		if page.PageNumber != pageNumber {
+			continue
+		}
+		for _, annot := range page.Annotations {
+			if annot.Type == "Link" {
+				links = append(links, annot)
+			}
+		}
+	}
+	return links
+}
+
+func TestInternalLinksSurviveRegeneration(t *testing.T) {
+	dir := t.TempDir()
+	input := writeLinkedPDF(t, dir)
+	output := filepath.Join(dir, "out.pdf")
+	p := newTestFlowProcessor(t, input, output)
+	if err := p.ProcessPDF(); err != nil {
+		t.Fatal(err)
+	}
+
+	links := pageLinks(p, 1)
+	if len(links) != 2 {
+		t.Fatalf("第1页提取到 %d 个链接，期望 2 个", len(links))
+	}
+	var internal *LinkDest
+	for _, link := range links {
+		if link.Dest != nil {
+			internal = link.Dest
+		}
+	}
+	if internal == nil || internal.PageNumber != 3 || !internal.HasTop {
+		t.Fatalf("内部链接目标 = %+v，期望第3页", internal)
+	}
+
+	// 去掉第2页，原第3页在输出中变为第2页
+	p.flowData.Pages = append(p.flowData.Pages[:1], p.flowData.Pages[2:]...)
+	if err := p.saveFlowData(); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.GeneratePDF(); err != nil {
+		t.Fatal(err)
+	}
+
+	regenerated, err := NewPDFFlowProcessor(output, filepath.Join(dir, "again.pdf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer regenerated.Cleanup()
+	if err := regenerated.ProcessPDF(); err != nil {
+		t.Fatal(err)
+	}
+
+	var gotDest *LinkDest
+	var gotURI string
+	for _, link := range pageLinks(regenerated, 1) {
+		if link.Dest != nil {
+			gotDest = link.Dest
+		}
+		if link.URI != "" {
+			gotURI = link.URI
+		}
+	}
+	if gotDest == nil || gotDest.PageNumber != 2 {
+		t.Fatalf("重新生成后的内部链接目标 = %+v，期望输出文档的第2页", gotDest)
+	}
+	if diff := gotDest.Top - internal.Top; diff < -1 || diff > 1 {
+		t.Errorf("目标位置 = %.1f，期望 %.1f", gotDest.Top, internal.Top)
+	}
+	if gotURI != "https://example.com/paper" {
+		t.Errorf("外部链接 = %q", gotURI)
+	}
+}
+
+func TestOutputPageNumbers(t *testing.T) {
+	pageMap := outputPageNumbers([]PDFPageFlow{{PageNumber: 1}, {PageNumber: 3}})
+	if pageMap[1] != 1 || pageMap[3] != 2 {
+		t.Errorf("outputPageNumbers = %v", pageMap)
+	}
+	if _, ok := pageMap[2]; ok {
+		t.Error("被移除的页面不应出现在页码映射中")
+	}
+}
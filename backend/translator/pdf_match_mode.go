@@ -0,0 +1,64 @@
+package translator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MatchMode 将译文对应到 PDF 文本元素时采用的匹配模式
+type MatchMode string
+
+const (
+	MatchModeDefault MatchMode = ""       // 依次尝试所有匹配方式
+	MatchModeStrict  MatchMode = "strict" // 只使用精确匹配和标准化匹配，避免把相似但不同的文本块的译文用错
+)
+
+// ParseMatchMode 解析匹配模式，为空时依次尝试所有匹配方式
+func ParseMatchMode(value string) (MatchMode, error) {
+	switch mode := MatchMode(strings.ToLower(strings.TrimSpace(value))); mode {
+	case MatchModeDefault, MatchModeStrict:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("不支持的匹配模式: %s，可选: %s", value, MatchModeStrict)
+	}
+}
+
+// MatchStrategy findBestTranslation 使用的匹配方式，可以按位组合
+type MatchStrategy uint8
+
+const (
+	MatchExact          MatchStrategy = 1 << iota // 原文完全相同
+	MatchNormalized                               // 标准化后相同
+	MatchSimilarity                               // 相似度高于阈值
+	MatchSubstring                                // 文本是某条原文的主要部分
+	MatchKeywordOverlap                           // 关键词显著重叠
+
+	MatchAllStrategies = MatchExact | MatchNormalized | MatchSimilarity | MatchSubstring | MatchKeywordOverlap
+)
+
+// defaultMatchThreshold 相似度匹配的默认阈值
+const defaultMatchThreshold = 0.8
+
+// strategies 返回匹配模式启用的匹配方式
+func (m MatchMode) strategies() MatchStrategy {
+	if m == MatchModeStrict {
+		return MatchExact | MatchNormalized
+	}
+	return MatchAllStrategies
+}
+
+// matchStrategies 返回处理器启用的匹配方式，MatchStrategies 不为 0 时优先于 MatchMode
+func (p *PDFFlowProcessor) matchStrategies() MatchStrategy {
+	if p.MatchStrategies != 0 {
+		return p.MatchStrategies
+	}
+	return p.MatchMode.strategies()
+}
+
+// matchThreshold 返回相似度匹配的阈值，未设置或超出 (0, 1] 时使用默认值
+func (p *PDFFlowProcessor) matchThreshold() float64 {
+	if p.MatchThreshold <= 0 || p.MatchThreshold > 1 {
+		return defaultMatchThreshold
+	}
+	return p.MatchThreshold
+}
@@ -0,0 +1,43 @@
+package translator
+
+import "testing"
+
+func TestStrictMatchModeSkipsFuzzyMatches(t *testing.T) {
+	translations := map[string]string{
+		"The proposed model achieves high accuracy on the benchmark.": "所提出的模型在基准上取得了很高的准确率。",
+	}
+	exact := "The proposed model achieves high accuracy on the benchmark."
+	normalized := "  The proposed model achieves high accuracy on the benchmark.  "
+	similar := "The proposed model achieves high accuracy on the benchmarks."
+
+	for _, tt := range []struct {
+		mode    MatchMode
+		similar string
+	}{
+		{MatchModeDefault, "所提出的模型在基准上取得了很高的准确率。"},
+		{MatchModeStrict, ""},
+	} {
+		p := newTestFlowProcessor(t, "", "")
+		p.MatchMode = tt.mode
+		index := p.newTranslationIndex(p.enhanceTranslationMappings(translations))
+		for _, text := range []string{exact, normalized} {
+			if got := p.findBestTranslation(text, index); got != translations[exact] {
+				t.Errorf("模式 %q: %q 匹配到 %q，期望精确或标准化匹配", tt.mode, text, got)
+			}
+		}
+		if got := p.findBestTranslation(similar, index); got != tt.similar {
+			t.Errorf("模式 %q: 相似文本匹配到 %q，期望 %q", tt.mode, got, tt.similar)
+		}
+	}
+}
+
+func TestParseMatchMode(t *testing.T) {
+	for value, want := range map[string]MatchMode{"": MatchModeDefault, " Strict ": MatchModeStrict} {
+		if got, err := ParseMatchMode(value); err != nil || got != want {
+			t.Errorf("ParseMatchMode(%q) = (%q, %v)，期望 %q", value, got, err, want)
+		}
+	}
+	if _, err := ParseMatchMode("fuzzy"); err == nil {
+		t.Error("不支持的匹配模式应返回错误")
+	}
+}
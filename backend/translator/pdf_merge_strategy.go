@@ -0,0 +1,50 @@
+package translator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MergeStrategy 合并被过度分割的 PDF 文本元素时采用的策略
+type MergeStrategy string
+
+const (
+	MergeStrategyDefault      MergeStrategy = ""             // 默认阈值
+	MergeStrategyConservative MergeStrategy = "conservative" // 只合并紧邻的碎片，避免跨栏误合并
+	MergeStrategyAggressive   MergeStrategy = "aggressive"   // 放宽距离和字号阈值，不检查内容，尽量减少碎片
+	MergeStrategyLineBased    MergeStrategy = "line-based"   // 只合并基线相同（在容差内）的元素，不跨行合并
+	MergeStrategyNone         MergeStrategy = "none"         // 不合并
+)
+
+// ParseMergeStrategy 解析合并策略，为空时使用默认阈值
+func ParseMergeStrategy(value string) (MergeStrategy, error) {
+	switch strategy := MergeStrategy(strings.ToLower(strings.TrimSpace(value))); strategy {
+	case MergeStrategyDefault, MergeStrategyConservative, MergeStrategyAggressive, MergeStrategyLineBased, MergeStrategyNone:
+		return strategy, nil
+	default:
+		return "", fmt.Errorf("不支持的合并策略: %s，可选: %s, %s, %s, %s", value,
+			MergeStrategyConservative, MergeStrategyAggressive, MergeStrategyLineBased, MergeStrategyNone)
+	}
+}
+
+// mergeThresholds 合并阈值，距离均相对于前一个元素的字号
+type mergeThresholds struct {
+	MaxYGap      float64 // 最大垂直距离
+	MaxXGap      float64 // 最大水平间隙
+	MaxSizeDiff  float64 // 最大字号差（磅）
+	CheckContent bool    // 是否要求内容适合合并（短文本、连字符、标点或小写开头）
+}
+
+// thresholds 返回策略对应的合并阈值
+func (s MergeStrategy) thresholds() mergeThresholds {
+	switch s {
+	case MergeStrategyConservative:
+		return mergeThresholds{MaxYGap: 1.2, MaxXGap: 0.5, MaxSizeDiff: 0.5, CheckContent: true}
+	case MergeStrategyAggressive:
+		return mergeThresholds{MaxYGap: 2.0, MaxXGap: 4.0, MaxSizeDiff: 2.0}
+	case MergeStrategyLineBased:
+		return mergeThresholds{MaxYGap: 0.2, MaxXGap: 2.0, MaxSizeDiff: 1.0}
+	default:
+		return mergeThresholds{MaxYGap: 1.5, MaxXGap: 2.0, MaxSizeDiff: 1.0, CheckContent: true}
+	}
+}
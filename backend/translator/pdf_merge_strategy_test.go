@@ -0,0 +1,70 @@
+package translator
+
+import "testing"
+
+// fragmentedElements 被过度分割的一段文字：同一基线上间隔较大的两个碎片，以及下一行的碎片
+func fragmentedElements() []TextElementFlow {
+	font := FontFlow{Name: "Helvetica", Size: 10}
+	fragment := func(content string, x, y, width float64) TextElementFlow {
+		return TextElementFlow{
+			Content:     content,
+			Position:    PositionFlow{X: x, Y: y},
+			BoundingBox: BoundingBox{X: x, Y: y, Width: width, Height: 10},
+			Font:        font,
+		}
+	}
+	return []TextElementFlow{
+		fragment("Results of the", 72, 700, 60),
+		fragment("Experiment", 147, 700, 50),
+		fragment("Table overview", 72, 686, 65),
+	}
+}
+
+// mergedCount 按指定策略合并后的文本块数
+func mergedCount(t *testing.T, strategy MergeStrategy) int {
+	t.Helper()
+	p := newTestFlowProcessor(t, "input.pdf", "")
+	p.MergeStrategy = strategy
+	page := &PDFPageFlow{TextElements: fragmentedElements()}
+	p.mergeAdjacentTextElements(page)
+	return len(page.TextElements)
+}
+
+func TestMergeStrategies(t *testing.T) {
+	conservative := mergedCount(t, MergeStrategyConservative)
+	aggressive := mergedCount(t, MergeStrategyAggressive)
+	if conservative <= aggressive {
+		t.Errorf("conservative 得到 %d 个文本块，aggressive 得到 %d 个，期望 conservative 更多", conservative, aggressive)
+	}
+
+	tests := []struct {
+		strategy MergeStrategy
+		want     int
+	}{
+		{MergeStrategyConservative, 3},
+		{MergeStrategyAggressive, 1},
+		{MergeStrategyLineBased, 2}, // 只合并同一基线上的碎片
+		{MergeStrategyNone, 3},
+	}
+	for _, tt := range tests {
+		if got := mergedCount(t, tt.strategy); got != tt.want {
+			t.Errorf("%q 策略合并后 %d 个文本块，期望 %d", tt.strategy, got, tt.want)
+		}
+	}
+}
+
+func TestParseMergeStrategy(t *testing.T) {
+	for value, want := range map[string]MergeStrategy{
+		"":             MergeStrategyDefault,
+		"Aggressive":   MergeStrategyAggressive,
+		" line-based ": MergeStrategyLineBased,
+		"none":         MergeStrategyNone,
+	} {
+		if got, err := ParseMergeStrategy(value); err != nil || got != want {
+			t.Errorf("ParseMergeStrategy(%q) = %q, %v，期望 %q", value, got, err, want)
+		}
+	}
+	if _, err := ParseMergeStrategy("greedy"); err == nil {
+		t.Error("不支持的策略应返回错误")
+	}
+}
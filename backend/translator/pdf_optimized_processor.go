@@ -85,6 +85,7 @@ func (opp *OptimizedPDFProcessor) applyOptimizations() error {
 		
 		// 3. 文本聚类
 		blocks := opp.textClusterer.ClusterPageBlocks(page)
+		opp.textClusterer.ApplyBlockAlignment(page, blocks)
 		totalClustered += len(blocks)
 		
 		opp.logger.Info("文本聚类完成", map[string]interface{}{
@@ -254,6 +255,8 @@ func (opp *OptimizedPDFProcessor) cleanPDFText(text string) string {
 	text = trimParentheses(text)
 	// 移除转义字符
 	text = unescapePDFString(text)
+	// 处理特殊字符编码
+	text = opp.baseProcessor.characterMap().Apply(text)
 	return text
 }
 
@@ -0,0 +1,103 @@
+package translator
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// newParseFixture 生成 pageCount 页的测试PDF并读取 pdfcpu 上下文
+func newParseFixture(t testing.TB, pageCount int) (*PDFFlowProcessor, func() []*PDFPageFlow) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Chdir(dir) // 工作目录创建在当前目录的 cache 下
+
+	pages := make([]string, pageCount)
+	for i := range pages {
+		pages[i] = fmt.Sprintf("Page %d of the parallel parsing fixture.", i+1)
+	}
+	path := writeTestPDF(t, dir, pages)
+
+	p, err := NewPDFFlowProcessor(path, "")
+	if err != nil {
+		t.Fatalf("NewPDFFlowProcessor: %v", err)
+	}
+	t.Cleanup(func() { p.Cleanup() })
+	ctx, err := p.readPDFContext()
+	if err != nil {
+		t.Fatalf("readPDFContext: %v", err)
+	}
+	return p, func() []*PDFPageFlow { return p.parsePages(ctx, ctx.PageCount) }
+}
+
+// pageText 返回页面所有文本元素的内容
+func pageText(page *PDFPageFlow) string {
+	var sb strings.Builder
+	for _, elem := range page.TextElements {
+		sb.WriteString(elem.Content)
+	}
+	return sb.String()
+}
+
+// elementCounts 返回页面各类元素的数量：文本、图像、图形、注释、内容流
+func elementCounts(page *PDFPageFlow) [5]int {
+	return [5]int{
+		len(page.TextElements),
+		len(page.ImageElements),
+		len(page.GraphicsElements),
+		len(page.Annotations),
+		len(page.ContentStreams),
+	}
+}
+
+// TestParsePagesMatchesSequential 并行解析的页面顺序、元素数量和文本与单协程解析一致，配合 -race 检查 ctxMu
+func TestParsePagesMatchesSequential(t *testing.T) {
+	const pageCount = 20
+	_, parse := newParseFixture(t, pageCount)
+
+	prev := runtime.GOMAXPROCS(1)
+	sequential := parse()
+	runtime.GOMAXPROCS(max(prev, 4))
+	parallel := parse()
+	runtime.GOMAXPROCS(prev)
+
+	if len(parallel) != pageCount || len(sequential) != pageCount {
+		t.Fatalf("解析页数: 并行 %d，单协程 %d，期望 %d", len(parallel), len(sequential), pageCount)
+	}
+	for i := range parallel {
+		if parallel[i] == nil || sequential[i] == nil {
+			t.Fatalf("第 %d 页解析失败", i+1)
+		}
+		if parallel[i].PageNumber != i+1 || sequential[i].PageNumber != i+1 {
+			t.Errorf("结果第 %d 项的页码: 并行 %d，单协程 %d", i, parallel[i].PageNumber, sequential[i].PageNumber)
+		}
+		got, want := elementCounts(parallel[i]), elementCounts(sequential[i])
+		if got != want {
+			t.Errorf("第 %d 页元素数量不一致: 并行 %v，单协程 %v", i+1, got, want)
+		}
+		if got[0] == 0 {
+			t.Errorf("第 %d 页没有解析出文本元素", i+1)
+		}
+		text := pageText(parallel[i])
+		if want := pageText(sequential[i]); text != want {
+			t.Errorf("第 %d 页文本不一致: 并行 %q，单协程 %q", i+1, text, want)
+		}
+		if !strings.Contains(text, fmt.Sprint(i+1)) {
+			t.Errorf("第 %d 页文本 %q 不含页码", i+1, text)
+		}
+	}
+}
+
+func BenchmarkParsePages(b *testing.B) {
+	_, parse := newParseFixture(b, 40)
+	for _, workers := range []int{1, max(runtime.NumCPU(), 4)} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			prev := runtime.GOMAXPROCS(workers)
+			defer runtime.GOMAXPROCS(prev)
+			for b.Loop() {
+				parse()
+			}
+		})
+	}
+}
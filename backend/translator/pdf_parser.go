@@ -5,6 +5,7 @@ import (
 	"log"
 	"regexp"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/ledongthuc/pdf"
 )
@@ -13,6 +14,7 @@ import (
 type PDFParser struct {
 	FormulaFontRegex *regexp.Regexp
 	FormulaCharRegex *regexp.Regexp
+	Extractor        TextExtractor // 文本提取后端，为空时使用 ledongthuc/pdf 带坐标解析文本对象
 }
 
 // TextBlock 文本块
@@ -33,6 +35,8 @@ type PDFContent struct {
 	TextBlocks []TextBlock       `json:"text_blocks"`
 	PageCount  int               `json:"page_count"`
 	Metadata   map[string]string `json:"metadata"`
+
+	AnnotationTexts []string `json:"annotation_texts,omitempty"` // 评论、高亮等标记注释的内容
 }
 
 // NewPDFParser 创建PDF解析器
@@ -54,6 +58,10 @@ func NewPDFParser(formulaFont, formulaChar string) *PDFParser {
 func (p *PDFParser) ParsePDF(filePath string) (*PDFContent, error) {
 	log.Printf("开始解析PDF文件: %s", filePath)
 
+	if p.Extractor != nil {
+		return p.parseWithExtractor(filePath)
+	}
+
 	// 打开PDF文件
 	file, reader, err := pdf.Open(filePath)
 	if err != nil {
@@ -92,10 +100,75 @@ func (p *PDFParser) ParsePDF(filePath string) (*PDFContent, error) {
 		content.TextBlocks = append(content.TextBlocks, blocks...)
 	}
 
+	content.AnnotationTexts = AnnotationTexts(filePath)
 	log.Printf("PDF解析完成，共%d页，提取%d个文本块", content.PageCount, len(content.TextBlocks))
 	return content, nil
 }
 
+// parseWithExtractor 使用指定的文本提取后端解析PDF，每页按空行分段，每段作为一个文本块（不含坐标）
+func (p *PDFParser) parseWithExtractor(filePath string) (*PDFContent, error) {
+	pages, err := p.Extractor.ExtractPages(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("使用 %s 提取文本失败: %w", p.Extractor.Name(), err)
+	}
+
+	content := &PDFContent{
+		TextBlocks: make([]TextBlock, 0),
+		PageCount:  len(pages),
+		Metadata:   make(map[string]string),
+	}
+	for _, page := range pages {
+		for _, para := range strings.Split(page.Text, "\n\n") {
+			para = strings.TrimSpace(dehyphenate(para))
+			if para == "" {
+				continue
+			}
+			for _, chunk := range chunkParagraph(para, maxExtractedBlockRunes) {
+				content.TextBlocks = append(content.TextBlocks, TextBlock{
+					Text:    chunk,
+					PageNum: page.Page,
+				})
+			}
+		}
+	}
+
+	content.AnnotationTexts = AnnotationTexts(filePath)
+	log.Printf("PDF解析完成（%s），共%d页，提取%d个文本块", p.Extractor.Name(), content.PageCount, len(content.TextBlocks))
+	return content, nil
+}
+
+// maxExtractedBlockRunes 文本提取后端输出的段落超过此长度时按句子拆分，纯文本后端常把整页作为一段
+const maxExtractedBlockRunes = 1200
+
+// chunkParagraph 将过长的段落按句子边界拆分为不超过 limit 个字符的片段，单个句子超长时保持完整
+func chunkParagraph(para string, limit int) []string {
+	if utf8.RuneCountInString(para) <= limit {
+		return []string{para}
+	}
+
+	var chunks []string
+	var current strings.Builder
+	currentLen := 0
+	for _, sentence := range splitSentences(para, "") {
+		n := utf8.RuneCountInString(sentence)
+		if currentLen > 0 && currentLen+1+n > limit {
+			chunks = append(chunks, current.String())
+			current.Reset()
+			currentLen = 0
+		}
+		if currentLen > 0 {
+			current.WriteByte(' ')
+			currentLen++
+		}
+		current.WriteString(sentence)
+		currentLen += n
+	}
+	if currentLen > 0 {
+		chunks = append(chunks, current.String())
+	}
+	return chunks
+}
+
 // extractTextBlocks 提取页面文本块
 func (p *PDFParser) extractTextBlocks(page pdf.Page, pageNum int) ([]TextBlock, error) {
 	var blocks []TextBlock
@@ -288,12 +361,23 @@ func (p *PDFParser) extractMetadata(info pdf.Value) map[string]string {
 func (p *PDFParser) GetTextForTranslation(content *PDFContent) []string {
 	var texts []string
 
+	// 页眉页脚只翻译每组中的一个，其余页的变体复用其译文（见 ReuseRunningTranslations）
+	blockTexts := make([]string, len(content.TextBlocks))
+	for i, block := range content.TextBlocks {
+		blockTexts[i] = block.Text
+	}
+	variants := runningVariants(blockTexts, DetectRunningBlocks(content))
+
 	for _, block := range content.TextBlocks {
 		// 跳过数学公式（可选择性翻译）
 		if block.IsFormula {
 			continue
 		}
 
+		if variants[block.Text] {
+			continue
+		}
+
 		// 过滤掉太短的文本
 		if len(strings.TrimSpace(block.Text)) < 3 {
 			continue
@@ -302,6 +386,9 @@ func (p *PDFParser) GetTextForTranslation(content *PDFContent) []string {
 		texts = append(texts, block.Text)
 	}
 
+	// 评论、高亮等注释的内容一并翻译，生成PDF时写回注释
+	texts = append(texts, content.AnnotationTexts...)
+
 	return texts
 }
 
@@ -0,0 +1,113 @@
+package translator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// RegenerationFailurePolicy PDF 重新生成失败时的处理方式
+type RegenerationFailurePolicy string
+
+const (
+	RegenerationFailureError        RegenerationFailurePolicy = "error"         // 任务失败（默认）
+	RegenerationFailureTextFallback RegenerationFailurePolicy = "text-fallback" // 改为生成纯文本（单语或双语对照，与请求的 PDF 对应）
+	RegenerationFailureHTMLFallback RegenerationFailurePolicy = "html-fallback" // 改为生成网页（单语或双语对照，与请求的 PDF 对应）
+	RegenerationFailureOriginalCopy RegenerationFailurePolicy = "original-copy" // 输出未翻译的原始 PDF 副本
+)
+
+// ParseRegenerationFailurePolicy 解析重新生成失败的处理方式，为空时返回 error
+func ParseRegenerationFailurePolicy(value string) (RegenerationFailurePolicy, error) {
+	switch policy := RegenerationFailurePolicy(strings.ToLower(strings.TrimSpace(value))); policy {
+	case "":
+		return RegenerationFailureError, nil
+	case RegenerationFailureError, RegenerationFailureTextFallback, RegenerationFailureHTMLFallback, RegenerationFailureOriginalCopy:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("不支持的重新生成失败处理方式: %s，可选: %s, %s, %s, %s", value,
+			RegenerationFailureError, RegenerationFailureTextFallback, RegenerationFailureHTMLFallback, RegenerationFailureOriginalCopy)
+	}
+}
+
+// RegenerationFallback 一次 PDF 重新生成失败及其替代输出
+type RegenerationFallback struct {
+	Format   string `json:"format"`   // 请求的输出格式（pdf 或 bilingual-pdf）
+	Artifact string `json:"artifact"` // 实际生成的输出格式；original-copy 时与 Format 相同，但内容是未翻译的原文件
+	Policy   string `json:"policy"`   // 采用的处理方式
+	Path     string `json:"-"`        // 替代输出的文件路径
+	Error    string `json:"error"`    // 重新生成失败的原因
+}
+
+// regenerationFallback 按配置处理 PDF 重新生成失败，生成替代输出
+// 处理方式为 error、任务已取消或替代输出也生成失败时返回错误
+func (pmt *PDFMathTranslator) regenerationFallback(config PDFMathConfig, format OutputFormat, regenErr error, inputPath, outputDir, filename string, pdfDoc *PDFDocument, originalBlocks, translatedBlocks []string) (*RegenerationFallback, error) {
+	policy, _ := ParseRegenerationFailurePolicy(config.OnRegenerationFailure)
+	if policy == RegenerationFailureError || errors.Is(regenErr, context.Canceled) || errors.Is(regenErr, context.DeadlineExceeded) {
+		return nil, regenErr
+	}
+
+	bilingual := format == OutputFormatBilingualPDF
+	artifact := format
+	var save func(path string) error
+	switch policy {
+	case RegenerationFailureTextFallback:
+		if bilingual {
+			artifact = OutputFormatBilingualText
+			save = func(path string) error { return pdfDoc.SaveBilingualText(path, originalBlocks, translatedBlocks) }
+		} else {
+			artifact = OutputFormatText
+			save = func(path string) error { return pdfDoc.SaveMonolingualText(path, translatedBlocks) }
+		}
+	case RegenerationFailureHTMLFallback:
+		if bilingual {
+			artifact = OutputFormatBilingualHTML
+			save = func(path string) error {
+				return pdfDoc.SaveBilingualHTML(path, originalBlocks, translatedBlocks, HTMLLayout(config.HTMLLayout))
+			}
+		} else {
+			artifact = OutputFormatHTML
+			save = func(path string) error { return pdfDoc.SaveMonolingualHTML(path, translatedBlocks) }
+		}
+	case RegenerationFailureOriginalCopy:
+		save = func(path string) error {
+			data, err := os.ReadFile(inputPath)
+			if err != nil {
+				return err
+			}
+			return os.WriteFile(path, data, 0644)
+		}
+	}
+
+	path := filepath.Join(outputDir, filename+outputFormatSuffix(artifact))
+	if err := save(path); err != nil {
+		return nil, fmt.Errorf("%w（%s 替代输出也生成失败: %v）", regenErr, policy, err)
+	}
+
+	log.Printf("警告：%s 重新生成失败，已按 %s 生成替代输出 %s: %s", format, policy, artifact, path)
+	return &RegenerationFallback{
+		Format:   string(format),
+		Artifact: string(artifact),
+		Policy:   string(policy),
+		Path:     path,
+		Error:    regenErr.Error(),
+	}, nil
+}
+
+// pdfBlockPairs 返回待翻译文本块及对应的译文，未翻译的文本块使用原文
+func pdfBlockPairs(texts []string, translations map[string]string) (originalBlocks, translatedBlocks []string) {
+	originalBlocks = make([]string, 0, len(texts))
+	translatedBlocks = make([]string, 0, len(texts))
+	for _, text := range texts {
+		translated, ok := translations[text]
+		if !ok {
+			translated = text
+		}
+		originalBlocks = append(originalBlocks, text)
+		translatedBlocks = append(translatedBlocks, translated)
+	}
+	return originalBlocks, translatedBlocks
+}
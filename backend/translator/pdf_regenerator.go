@@ -1,6 +1,7 @@
 package translator
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
@@ -10,12 +11,34 @@ import (
 
 // PDFRegenerator PDF重新生成器 - 基于PDF流处理器的动态重建
 type PDFRegenerator struct {
-	processor *PDFFlowProcessor // PDF流处理器
+	processor      *PDFFlowProcessor // PDF流处理器
+	TargetLanguage string            // 目标语言，写入输出文档信息
+	TranslateTitle bool              // 是否将文档标题替换为译文
+
+	PreserveOriginalTextLayer bool        // 在译文下保留不可见的原文层，使原文可搜索、可复制
+	FontPolicy                *FontPolicy // 按语言选择字体，为空时使用默认策略
+
+	CharacterMap CharacterMap // PDF 字符编码映射，为空时使用 CharacterMapFromEnv
+
+	ImageQuality int     // 重新嵌入图片时的 JPEG 质量（1-100），0 表示不重新压缩
+	MaxImageDPI  float64 // 按放置尺寸计算的图片最大分辨率，超出时降采样，0 表示不限制
+
+	Context context.Context // 任务上下文，取消或超时后停止重新生成，为空时不限制
+
+	VerticalText  bool          // 原文为竖排时以竖排输出 CJK 译文
+	MergeStrategy MergeStrategy // 合并被过度分割的文本元素时采用的策略
+
+	MatchMode      MatchMode // 译文与文本元素的匹配模式，为空时依次尝试所有匹配方式
+	MatchThreshold float64   // 相似度匹配的阈值，为 0 时使用 0.8
+
+	Confidences map[string]float64 // 翻译服务自评的可信度：原文 -> 可信度，记录在文本元素上
+
+	StreamBatchSize int // 大于 0 时分批处理页面，内存中最多同时保留这么多页（用于页数很多的文档），默认取自 StreamBatchSizeFromEnv
 }
 
 // NewPDFRegenerator 创建PDF重新生成器
 func NewPDFRegenerator() *PDFRegenerator {
-	return &PDFRegenerator{}
+	return &PDFRegenerator{StreamBatchSize: StreamBatchSizeFromEnv()}
 }
 
 // RegeneratePDF 重新生成PDF - 使用PDF流处理器进行动态重建
@@ -28,12 +51,31 @@ func (r *PDFRegenerator) RegeneratePDF(inputPath, outputPath string, translation
 	if err != nil {
 		return fmt.Errorf("创建PDF流处理器失败: %w", err)
 	}
+	processor.TargetLanguage = r.TargetLanguage
+	processor.TranslateTitle = r.TranslateTitle
+	processor.PreserveOriginalTextLayer = r.PreserveOriginalTextLayer
+	processor.FontPolicy = r.FontPolicy
+	processor.CharacterMap = r.CharacterMap
+	processor.ImageOptions = ImageOptions{Quality: r.ImageQuality, MaxDPI: r.MaxImageDPI}
+	processor.Context = r.Context
+	processor.VerticalText = r.VerticalText
+	processor.MergeStrategy = r.MergeStrategy
+	processor.MatchMode = r.MatchMode
+	processor.MatchThreshold = r.MatchThreshold
+	processor.Confidences = r.Confidences
+	if processor.FontPolicy == nil {
+		processor.FontPolicy = NewFontPolicy()
+	}
 	r.processor = processor
 	defer processor.Cleanup() // 确保清理临时文件
 
 	// 2. 解析PDF结构并保存到临时目录
 	log.Printf("解析PDF结构...")
-	if err := processor.ProcessPDF(); err != nil {
+	if r.StreamBatchSize > 0 {
+		if err := processor.ProcessPDFStreaming(r.StreamBatchSize); err != nil {
+			return fmt.Errorf("PDF结构解析失败: %w", err)
+		}
+	} else if err := processor.ProcessPDF(); err != nil {
 		return fmt.Errorf("PDF结构解析失败: %w", err)
 	}
 
@@ -97,7 +139,7 @@ func (r *PDFRegenerator) exportProcessingReport(processor *PDFFlowProcessor, tra
 		fmt.Fprintf(file, "=== PDF流数据统计 ===\n")
 		fmt.Fprintf(file, "文档标题: %s\n", processor.flowData.Metadata.Title)
 		fmt.Fprintf(file, "文档作者: %s\n", processor.flowData.Metadata.Author)
-		fmt.Fprintf(file, "总页数: %d\n", len(processor.flowData.Pages))
+		fmt.Fprintf(file, "总页数: %d\n", processor.pageCount())
 		fmt.Fprintf(file, "原始文件大小: %d 字节\n", processor.flowData.OriginalSize)
 		fmt.Fprintf(file, "处理时间: %s\n", processor.flowData.ProcessTime.Format("2006-01-02 15:04:05"))
 		fmt.Fprintf(file, "\n")
@@ -0,0 +1,161 @@
+package translator
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// readPDFMetadata 读取 PDF 信息字典
+func readPDFMetadata(t *testing.T, path string) PDFDocumentMetadata {
+	t.Helper()
+	p, err := NewPDFFlowProcessor(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Cleanup()
+	ctx, err := p.readPDFContext()
+	if err != nil {
+		t.Fatalf("读取 %s 失败: %v", path, err)
+	}
+	if err := p.extractMetadata(ctx); err != nil {
+		t.Fatal(err)
+	}
+	return p.flowData.Metadata
+}
+
+func TestRegeneratePDFKeepsDocumentInfo(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	input := filepath.Join(dir, "report.pdf")
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetTitle("Annual Report", true)
+	pdf.SetAuthor("Jane Doe", true)
+	pdf.SetFont("Helvetica", "", 12)
+	pdf.AddPage()
+	pdf.Text(20, 30, "Annual Report")
+	if err := pdf.OutputFileAndClose(input); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, translateTitle := range []bool{false, true} {
+		output := filepath.Join(dir, "out.pdf")
+		r := NewPDFRegenerator()
+		r.TargetLanguage = "zh"
+		r.TranslateTitle = translateTitle
+		if err := r.RegeneratePDF(input, output, map[string]string{"Annual Report": "年度报告"}); err != nil {
+			t.Fatal(err)
+		}
+
+		meta := readPDFMetadata(t, output)
+		wantTitle := "Annual Report"
+		if translateTitle {
+			wantTitle = "年度报告"
+		}
+		if meta.Title != wantTitle {
+			t.Errorf("TranslateTitle=%v: 标题 = %q，期望 %q", translateTitle, meta.Title, wantTitle)
+		}
+		if meta.Author != "Jane Doe" {
+			t.Errorf("作者 = %q，期望 Jane Doe", meta.Author)
+		}
+		if !strings.Contains(meta.Producer, "machine translated to zh") {
+			t.Errorf("Producer = %q，应注明机器翻译", meta.Producer)
+		}
+	}
+}
+
+// textRenderModes 返回 PDF 中每段文本及其文本渲染模式（Tr）
+func textRenderModes(t *testing.T, path string) map[string]int {
+	t.Helper()
+	p := newTestFlowProcessor(t, path, "")
+	if err := p.parsePDFStructure(); err != nil {
+		t.Fatalf("解析 %s 失败: %v", path, err)
+	}
+	modes := make(map[string]int)
+	for _, page := range p.flowData.Pages {
+		mode := 0
+		for _, stream := range page.ContentStreams {
+			for _, op := range stream.ParsedOps {
+				switch op.Operator {
+				case "Tr":
+					if len(op.Operands) > 0 {
+						mode, _ = strconv.Atoi(op.Operands[0])
+					}
+				case "Tj", "TJ":
+					modes[p.extractTextFromOperands(op.Operands, op.Operator, FontFlow{})] = mode
+				}
+			}
+		}
+	}
+	return modes
+}
+
+func TestRegeneratePDFPreservesOriginalTextLayer(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "letter.pdf")
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetFont("Helvetica", "", 12)
+	pdf.AddPage()
+	pdf.Text(20, 30, "Hello world")
+	if err := pdf.OutputFileAndClose(input); err != nil {
+		t.Fatal(err)
+	}
+
+	output := filepath.Join(dir, "out.pdf")
+	r := NewPDFRegenerator()
+	r.TargetLanguage = "fr"
+	r.PreserveOriginalTextLayer = true
+	t.Chdir(dir)
+	if err := r.RegeneratePDF(input, output, map[string]string{"Hello world": "Bonjour le monde"}); err != nil {
+		t.Fatal(err)
+	}
+
+	modes := textRenderModes(t, output)
+	if mode, ok := modes["Bonjour le monde"]; !ok || mode == 3 {
+		t.Errorf("译文应可见，文本及渲染模式: %v", modes)
+	}
+	// 原文以不可见模式（Tr 3）写入，可被搜索和复制但不显示
+	if mode, ok := modes["Hello world"]; !ok || mode != 3 {
+		t.Errorf("原文应为不可见文本层，文本及渲染模式: %v", modes)
+	}
+}
+
+func TestBilingualRegenerationBreaksLines(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	input := writeTestPDF(t, dir, []string{"The results are conclusive"})
+	output := filepath.Join(dir, "bilingual.pdf")
+	const translation = "Les resultats sont concluants"
+	if err := NewPDFRegenerator().RegeneratePDF(input, output, map[string]string{
+		"The results are conclusive": "The results are conclusive\n" + translation,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	pages, err := pdfcpuExtractor{}.ExtractPages(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pages) != 1 {
+		t.Fatalf("输出 %d 页，期望 1", len(pages))
+	}
+	text := pages[0].Text
+	if strings.Contains(text, `\n`) {
+		t.Errorf("提取的文本含有字面的 \\n: %q", text)
+	}
+	var originalLine, translationLine = -1, -1
+	for i, line := range strings.Split(text, "\n") {
+		if strings.Contains(line, "The results are conclusive") {
+			originalLine = i
+		}
+		if strings.Contains(line, translation) {
+			translationLine = i
+		}
+	}
+	if originalLine < 0 || translationLine < 0 || originalLine == translationLine {
+		t.Errorf("原文和译文应在不同的行: %q", text)
+	}
+}
@@ -0,0 +1,48 @@
+package translator
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+)
+
+// RepairPDF 使用 pdfcpu 以宽松模式读取并重写 PDF，生成修复后的副本
+// 可修复损坏的 xref 表、缺失的流长度等常见问题，返回修复后的文件路径
+func RepairPDF(inputPath string) (string, error) {
+	repairedPath := strings.TrimSuffix(inputPath, filepath.Ext(inputPath)) + "-repaired.pdf"
+
+	conf := model.NewDefaultConfiguration()
+	conf.ValidationMode = model.ValidationRelaxed
+
+	if err := api.OptimizeFile(inputPath, repairedPath, conf); err != nil {
+		os.Remove(repairedPath)
+		return "", fmt.Errorf("修复PDF失败: %w", err)
+	}
+
+	log.Printf("已修复PDF: %s -> %s", inputPath, repairedPath)
+	return repairedPath, nil
+}
+
+// repairAndRetry 读取失败时修复 PDF 并用修复后的文件重试一次
+// 修复或重试失败时返回原始错误
+func repairAndRetry[T any](inputPath string, originalErr error, open func(path string) (T, error)) (T, string, error) {
+	var zero T
+
+	repairedPath, err := RepairPDF(inputPath)
+	if err != nil {
+		log.Printf("警告：%v", err)
+		return zero, "", originalErr
+	}
+
+	result, err := open(repairedPath)
+	if err != nil {
+		log.Printf("警告：修复后的PDF仍无法读取: %v", err)
+		return zero, "", originalErr
+	}
+	return result, repairedPath, nil
+}
@@ -0,0 +1,60 @@
+package translator
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// breakXref 把 startxref 指向文件之外的位置，使交叉引用表无法定位
+func breakXref(t *testing.T, path string) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	startxref := regexp.MustCompile(`startxref\s+\d+`)
+	broken := startxref.ReplaceAll(data, []byte("startxref\n99999"))
+	if string(broken) == string(data) {
+		t.Fatal("测试PDF中没有交叉引用表")
+	}
+	if err := os.WriteFile(path, broken, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOpenPDFRepairsBrokenXref(t *testing.T) {
+	dir := t.TempDir()
+	path := writeTestPDF(t, dir, []string{"Text that survives a broken xref table."})
+	breakXref(t, path)
+
+	if _, err := openPDF(path); err == nil {
+		t.Fatal("交叉引用表损坏的PDF不修复应无法读取")
+	}
+	doc, err := OpenPDF(path)
+	if err != nil {
+		t.Fatalf("修复后仍无法打开: %v", err)
+	}
+	if text := strings.Join(doc.PageTexts, " "); !strings.Contains(text, "survives a broken xref") {
+		t.Errorf("修复后的文本: %q", text)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "test-repaired.pdf")); err != nil {
+		t.Errorf("应生成修复后的副本: %v", err)
+	}
+}
+
+func TestOpenPDFReturnsOriginalErrorWhenRepairFails(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "garbage.pdf")
+	if err := os.WriteFile(path, []byte("not a pdf at all"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, originalErr := openPDF(path)
+	if originalErr == nil {
+		t.Fatal("无效文件应读取失败")
+	}
+	if _, err := OpenPDF(path); err == nil || err.Error() != originalErr.Error() {
+		t.Errorf("修复失败时应返回原始错误 %q，得到 %v", originalErr, err)
+	}
+}
@@ -0,0 +1,66 @@
+package translator
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// PDFStrategy 生成单语 PDF 译文的方式
+type PDFStrategy string
+
+const (
+	PDFStrategyOverlay    PDFStrategy = "overlay"    // 在原页面上遮盖原文并绘制译文，图片、矢量图形等页面内容原样保留（默认）
+	PDFStrategyRegenerate PDFStrategy = "regenerate" // 根据解析出的文本、图片和图形重新生成页面，适用于覆盖后原文残留或遮挡背景的文档
+	PDFStrategyAuto       PDFStrategy = "auto"       // 先覆盖原文，失败时改为重新生成
+)
+
+// ParsePDFStrategy 解析单语 PDF 的生成方式，为空时返回 overlay
+func ParsePDFStrategy(value string) (PDFStrategy, error) {
+	switch strategy := PDFStrategy(strings.ToLower(strings.TrimSpace(value))); strategy {
+	case "":
+		return PDFStrategyOverlay, nil
+	case PDFStrategyOverlay, PDFStrategyRegenerate, PDFStrategyAuto:
+		return strategy, nil
+	case "inplace":
+		return "", fmt.Errorf("暂不支持直接修改内容流中的文本（inplace），可选: %s, %s, %s",
+			PDFStrategyOverlay, PDFStrategyRegenerate, PDFStrategyAuto)
+	default:
+		return "", fmt.Errorf("不支持的PDF生成方式: %s，可选: %s, %s, %s", value,
+			PDFStrategyOverlay, PDFStrategyRegenerate, PDFStrategyAuto)
+	}
+}
+
+// saveMonolingualPDF 按 config.Strategy 指定的方式生成单语PDF
+func (pmt *PDFMathTranslator) saveMonolingualPDF(inputPath, outputPath string, config PDFMathConfig, pdfDoc *PDFDocument, translationMap map[string]string) error {
+	overlayPDF, regeneratePDF := pmt.overlayPDF, pmt.regeneratePDF
+	if overlayPDF == nil {
+		overlayPDF = NewPDFStylePreservingReplacer().ReplaceWithStylePreservation
+	}
+	if regeneratePDF == nil {
+		regeneratePDF = (*PDFDocument).SaveMonolingualPDFWithRegeneration
+	}
+	overlay := func() error {
+		styleConfig := GetDefaultStylePreservingConfig()
+		styleConfig.DiffOverlay = config.DiffOverlay
+		return overlayPDF(inputPath, outputPath, translationMap, styleConfig)
+	}
+
+	strategy, _ := ParsePDFStrategy(config.Strategy)
+	switch strategy {
+	case PDFStrategyRegenerate:
+		return regeneratePDF(pdfDoc, outputPath, translationMap)
+	case PDFStrategyAuto:
+		err := overlay()
+		if err == nil {
+			return nil
+		}
+		log.Printf("覆盖原文生成单语PDF失败，改为重新生成: %v", err)
+		if regenErr := regeneratePDF(pdfDoc, outputPath, translationMap); regenErr != nil {
+			return fmt.Errorf("%w；重新生成也失败: %v", err, regenErr)
+		}
+		return nil
+	default:
+		return overlay()
+	}
+}
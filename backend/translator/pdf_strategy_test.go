@@ -0,0 +1,85 @@
+package translator
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// strategySpy 记录单语PDF调用了哪种生成实现，输出为输入PDF的副本
+type strategySpy struct {
+	calls       []PDFStrategy
+	overlayErr  error
+	translation map[string]string
+}
+
+func (s *strategySpy) install(t *testing.T, pmt *PDFMathTranslator, inputPath string) {
+	t.Helper()
+	copyInput := func(outputPath string) error {
+		data, err := os.ReadFile(inputPath)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(outputPath, data, 0644)
+	}
+	pmt.overlayPDF = func(_, outputPath string, translationMap map[string]string, _ StylePreservingConfig) error {
+		s.calls = append(s.calls, PDFStrategyOverlay)
+		s.translation = translationMap
+		if s.overlayErr != nil {
+			return s.overlayErr
+		}
+		return copyInput(outputPath)
+	}
+	pmt.regeneratePDF = func(_ *PDFDocument, outputPath string, translationMap map[string]string) error {
+		s.calls = append(s.calls, PDFStrategyRegenerate)
+		s.translation = translationMap
+		return copyInput(outputPath)
+	}
+}
+
+func TestPDFStrategyRouting(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	input := writeTestPDF(t, dir, []string{"Strategy routing text"})
+
+	tests := []struct {
+		strategy   string
+		overlayErr error
+		want       []PDFStrategy
+	}{
+		{"", nil, []PDFStrategy{PDFStrategyOverlay}},
+		{"overlay", nil, []PDFStrategy{PDFStrategyOverlay}},
+		{"regenerate", nil, []PDFStrategy{PDFStrategyRegenerate}},
+		{"auto", nil, []PDFStrategy{PDFStrategyOverlay}},
+		{"auto", errors.New("覆盖失败"), []PDFStrategy{PDFStrategyOverlay, PDFStrategyRegenerate}},
+	}
+	for _, tt := range tests {
+		strategy, err := ParsePDFStrategy(tt.strategy)
+		if err != nil {
+			t.Fatal(err)
+		}
+		client, _ := newStubClient(t)
+		// pdfcpu 保留单词间的空格，译文映射的键与原文一致
+		dt := &DocumentTranslator{Client: client, PDFMathTranslator: NewPDFMathTranslator(), PDFStrategy: strategy, TextExtractor: TextExtractorPDFCPU}
+		spy := &strategySpy{overlayErr: tt.overlayErr}
+		spy.install(t, dt.PDFMathTranslator, input)
+
+		output := filepath.Join(dir, "out-"+tt.strategy+".pdf")
+		if _, err := dt.TranslateDocument(input, output, "French", "", true, "monolingual", nil); err != nil {
+			t.Errorf("strategy=%q: %v", tt.strategy, err)
+			continue
+		}
+		if !slices.Equal(spy.calls, tt.want) {
+			t.Errorf("strategy=%q (overlayErr=%v): 调用 %v，期望 %v", tt.strategy, tt.overlayErr, spy.calls, tt.want)
+		}
+		if spy.translation["Strategy routing text"] != "[French] Strategy routing text" {
+			t.Errorf("strategy=%q: 传入的译文映射 = %q", tt.strategy, spy.translation)
+		}
+	}
+
+	if _, err := ParsePDFStrategy("inplace"); err == nil {
+		t.Error("inplace 在本实现中不受支持，应返回错误")
+	}
+}
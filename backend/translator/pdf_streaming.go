@@ -0,0 +1,235 @@
+package translator
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"time"
+)
+
+// defaultStreamBatchSize 分批处理时每批的默认页数
+const defaultStreamBatchSize = 50
+
+// flowPagesDirName 分批处理时逐页保存流数据的目录（位于工作目录下）
+const flowPagesDirName = "pages"
+
+// StreamBatchSizeFromEnv 返回环境变量 PDF_STREAM_BATCH_SIZE 指定的分批页数，未设置或无效时返回 0（一次处理所有页面）
+func StreamBatchSizeFromEnv() int {
+	size, err := strconv.Atoi(os.Getenv("PDF_STREAM_BATCH_SIZE"))
+	if err != nil || size < 0 {
+		return 0
+	}
+	return size
+}
+
+// ProcessPDFStreaming 分批解析PDF，适用于页数很多、一次性载入会耗尽内存的文档
+// 每解析完 batchSize 页就将各页流数据写入工作目录下单独的 JSON 文件并释放，flow_data.json 只保存文档级数据；
+// 之后的 ApplyTranslations 和 GeneratePDF 同样按批加载页面，解析出的页面数据最多同时保留 batchSize 页，
+// GeneratePDF 完成后删除这些文件。
+// 注意生成输出时 gofpdf 在写出文件前仍将所有页面的内容流、字体和图片保存在内存中，
+// 因此内存占用的上限约为输出文档的大小，分批处理只避免了同时持有所有页面的解析结果。
+// batchSize 不大于 0 时使用 defaultStreamBatchSize
+func (p *PDFFlowProcessor) ProcessPDFStreaming(batchSize int) error {
+	if batchSize <= 0 {
+		batchSize = defaultStreamBatchSize
+	}
+	startTime := time.Now()
+	p.logger.Info("开始分批处理PDF文件", map[string]interface{}{
+		"输入文件": p.inputPath,
+		"每批页数": batchSize,
+	})
+
+	var m1, m2 runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&m1)
+
+	if err := os.MkdirAll(filepath.Join(p.workDir, flowPagesDirName), 0755); err != nil {
+		return fmt.Errorf("创建页面目录失败: %w", err)
+	}
+
+	// 1. 读取文档并分批解析页面
+	ctx, err := p.readPDFContext()
+	if err != nil {
+		p.logger.LogError("解析PDF结构", err, map[string]interface{}{
+			"输入文件": p.inputPath,
+		})
+		return fmt.Errorf("解析PDF结构失败: %w", err)
+	}
+	p.streamBatchSize = batchSize
+	pageCount := ctx.PageCount
+	p.pageBoxes = make(map[int]BoundingBox, pageCount)
+
+	// 上一批最后一页，解析失败的页面以空白页占位时沿用其尺寸
+	var previous *PDFPageFlow
+	for first := 1; first <= pageCount; first += batchSize {
+		last := min(first+batchSize-1, pageCount)
+		parsed := p.parsePageRange(ctx, first, last)
+		if err := p.contextErr(); err != nil {
+			return fmt.Errorf("解析页面中止: %w", err)
+		}
+
+		pages := make([]PDFPageFlow, 0, len(parsed))
+		for i, pageFlow := range parsed {
+			if pageFlow == nil {
+				pageFlow = blankPageFlow(first+i, append([]*PDFPageFlow{previous}, parsed[:i]...))
+				p.logger.Warn("页面解析失败，以空白页代替", map[string]interface{}{
+					"页码": first + i,
+				})
+			}
+			p.pageBoxes[pageFlow.PageNumber] = pageFlow.MediaBox
+			pages = append(pages, *pageFlow)
+		}
+		if err := p.savePageFlows(pages); err != nil {
+			return err
+		}
+		previous = &pages[len(pages)-1]
+
+		p.logger.Info("页面批次解析完成", map[string]interface{}{
+			"起始页": first,
+			"结束页": last,
+			"总页数": pageCount,
+		})
+	}
+
+	// 2. 提取表单域（页面尺寸取自 pageBoxes）
+	if err := p.extractFormFields(ctx); err != nil {
+		p.logger.Warn("提取表单域失败", map[string]interface{}{
+			"错误": err.Error(),
+		})
+	}
+
+	// 3. 提取图片资源，失败时继续处理
+	if err := p.extractImages(); err != nil {
+		p.logger.Warn("提取图片失败", map[string]interface{}{
+			"错误": err.Error(),
+		})
+	}
+
+	// 4. 保存文档级流数据（不含页面）
+	p.flowData.Pages = nil
+	if err := p.saveFlowData(); err != nil {
+		p.logger.LogError("保存流数据", err, nil)
+		return fmt.Errorf("保存流数据失败: %w", err)
+	}
+
+	// 5. 提取资源文件
+	if err := p.extractResources(); err != nil {
+		p.logger.LogError("提取资源文件", err, nil)
+		return fmt.Errorf("提取资源文件失败: %w", err)
+	}
+
+	runtime.GC()
+	runtime.ReadMemStats(&m2)
+	p.logger.LogMemoryUsage("PDF分批解析",
+		float64(m1.Alloc)/1024/1024,
+		float64(m2.Alloc)/1024/1024)
+
+	duration := time.Since(startTime)
+	p.logger.Info("PDF分批解析完成", map[string]interface{}{
+		"总页数": pageCount,
+		"批次数": (pageCount + batchSize - 1) / batchSize,
+		"耗时":  duration.String(),
+	})
+	return nil
+}
+
+// pageCount 返回文档页数，分批处理时页面不在内存中，取自元数据
+func (p *PDFFlowProcessor) pageCount() int {
+	if p.streamBatchSize > 0 {
+		return p.flowData.Metadata.PageCount
+	}
+	return len(p.flowData.Pages)
+}
+
+// outputPageMap 返回原页码到输出页码的映射，分批处理时所有页面按顺序输出
+func (p *PDFFlowProcessor) outputPageMap() map[int]int {
+	if p.streamBatchSize <= 0 {
+		return outputPageNumbers(p.flowData.Pages)
+	}
+	pageMap := make(map[int]int, p.flowData.Metadata.PageCount)
+	for pageNum := 1; pageNum <= p.flowData.Metadata.PageCount; pageNum++ {
+		pageMap[pageNum] = pageNum
+	}
+	return pageMap
+}
+
+// forEachPageBatch 对页面逐批调用 fn，调用期间 p.flowData.Pages 为当前批次的页面
+// 没有分批处理时所有页面已在内存中，只调用一次；分批处理时按批从磁盘加载，save 为 true 时调用后写回
+func (p *PDFFlowProcessor) forEachPageBatch(save bool, fn func() error) error {
+	if p.streamBatchSize <= 0 {
+		return fn()
+	}
+	defer func() { p.flowData.Pages = nil }()
+
+	pageCount := p.flowData.Metadata.PageCount
+	for first := 1; first <= pageCount; first += p.streamBatchSize {
+		if err := p.contextErr(); err != nil {
+			return fmt.Errorf("处理页面中止: %w", err)
+		}
+		pages, err := p.loadPageFlows(first, min(first+p.streamBatchSize-1, pageCount))
+		if err != nil {
+			return err
+		}
+		p.flowData.Pages = pages
+		if err := fn(); err != nil {
+			return err
+		}
+		if save {
+			if err := p.savePageFlows(p.flowData.Pages); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// removePageFlows 删除分批处理时逐页保存的流数据文件
+func (p *PDFFlowProcessor) removePageFlows() {
+	if p.streamBatchSize <= 0 {
+		return
+	}
+	if err := os.RemoveAll(filepath.Join(p.workDir, flowPagesDirName)); err != nil {
+		p.logger.Warn("删除页面流数据失败", map[string]interface{}{
+			"错误": err.Error(),
+		})
+	}
+}
+
+// pageFlowPath 返回分批处理时单页流数据文件的路径
+func (p *PDFFlowProcessor) pageFlowPath(pageNum int) string {
+	return filepath.Join(p.workDir, flowPagesDirName, fmt.Sprintf("page_%05d.json", pageNum))
+}
+
+// savePageFlows 将页面流数据逐页写入单独的文件
+func (p *PDFFlowProcessor) savePageFlows(pages []PDFPageFlow) error {
+	for i := range pages {
+		data, err := json.Marshal(&pages[i])
+		if err != nil {
+			return fmt.Errorf("序列化页面%d流数据失败: %w", pages[i].PageNumber, err)
+		}
+		if err := os.WriteFile(p.pageFlowPath(pages[i].PageNumber), data, 0644); err != nil {
+			return fmt.Errorf("保存页面%d流数据失败: %w", pages[i].PageNumber, err)
+		}
+	}
+	return nil
+}
+
+// loadPageFlows 加载 first~last 页的流数据
+func (p *PDFFlowProcessor) loadPageFlows(first, last int) ([]PDFPageFlow, error) {
+	pages := make([]PDFPageFlow, 0, last-first+1)
+	for pageNum := first; pageNum <= last; pageNum++ {
+		data, err := os.ReadFile(p.pageFlowPath(pageNum))
+		if err != nil {
+			return nil, fmt.Errorf("读取页面%d流数据失败: %w", pageNum, err)
+		}
+		var page PDFPageFlow
+		if err := json.Unmarshal(data, &page); err != nil {
+			return nil, fmt.Errorf("反序列化页面%d流数据失败: %w", pageNum, err)
+		}
+		pages = append(pages, page)
+	}
+	return pages, nil
+}
@@ -0,0 +1,102 @@
+package translator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// streamingFixture 生成 pageCount 页、每页多段文字的测试PDF
+func streamingFixture(t *testing.T, pageCount int) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Chdir(dir)
+	pages := make([]string, pageCount)
+	for i := range pages {
+		text := ""
+		for line := 0; line < 20; line++ {
+			text += fmt.Sprintf("Page %d line %d of the streaming fixture, long enough to be translated.\n", i+1, line+1)
+		}
+		pages[i] = text
+	}
+	return writeTestPDF(t, dir, pages)
+}
+
+// heapAlloc 强制回收后仍在使用的堆内存
+func heapAlloc() uint64 {
+	var m runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&m)
+	return m.HeapAlloc
+}
+
+// retainedBy 返回 fn 执行后新增的仍在使用的堆内存
+func retainedBy(fn func() error) (uint64, error) {
+	before := heapAlloc()
+	err := fn()
+	after := heapAlloc()
+	return after - min(before, after), err
+}
+
+// TestProcessPDFStreamingBoundsPagesInMemory 分批处理时解析结果最多同时保留一批页面，生成后删除逐页文件
+func TestProcessPDFStreamingBoundsPagesInMemory(t *testing.T) {
+	const pageCount, batchSize = 60, 8
+	input := streamingFixture(t, pageCount)
+
+	// 一次处理所有页面时保留的解析结果，作为对照
+	full, err := NewPDFFlowProcessor(input, filepath.Join(t.TempDir(), "full.pdf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer full.Cleanup()
+	fullRetained, err := retainedBy(full.ProcessPDF)
+	if err != nil {
+		t.Fatalf("ProcessPDF: %v", err)
+	}
+	runtime.KeepAlive(full.flowData.Pages)
+
+	output := filepath.Join(t.TempDir(), "streamed.pdf")
+	p, err := NewPDFFlowProcessor(input, output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Cleanup()
+	streamRetained, err := retainedBy(func() error { return p.ProcessPDFStreaming(batchSize) })
+	if err != nil {
+		t.Fatalf("ProcessPDFStreaming: %v", err)
+	}
+	t.Logf("解析后保留的堆内存：一次解析 %d 字节，分批解析 %d 字节", fullRetained, streamRetained)
+
+	if len(p.flowData.Pages) != 0 {
+		t.Errorf("解析完成后内存中仍有 %d 页", len(p.flowData.Pages))
+	}
+	if streamRetained >= fullRetained/2 {
+		t.Errorf("分批解析后保留 %d 字节，一次解析保留 %d 字节，期望明显更少", streamRetained, fullRetained)
+	}
+
+	maxPages := 0
+	if err := p.forEachPageBatch(false, func() error {
+		maxPages = max(maxPages, len(p.flowData.Pages))
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if maxPages > batchSize {
+		t.Errorf("一批加载了 %d 页，超过 %d", maxPages, batchSize)
+	}
+
+	if err := p.ApplyTranslations(map[string]string{}); err != nil {
+		t.Fatalf("ApplyTranslations: %v", err)
+	}
+	if err := p.GeneratePDF(); err != nil {
+		t.Fatalf("GeneratePDF: %v", err)
+	}
+	if pages, err := GetPDFPageCount(output); err != nil || pages != pageCount {
+		t.Errorf("输出页数 = %d (%v)，期望 %d", pages, err, pageCount)
+	}
+	if _, err := os.Stat(filepath.Join(p.workDir, flowPagesDirName)); !os.IsNotExist(err) {
+		t.Errorf("生成完成后逐页流数据应已删除: %v", err)
+	}
+}
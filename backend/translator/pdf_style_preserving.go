@@ -29,6 +29,7 @@ type StylePreservingConfig struct {
 	LineSpacing        float64 // 行间距
 	MarginAdjustment   float64 // 页边距调整
 	ColorPreservation  bool    // 是否保留颜色
+	DiffOverlay        bool    // 只遮罩并重绘译文与原文不同的元素，未改动的元素直接显示底图中的原文
 }
 
 // PageElement 页面元素
@@ -46,6 +47,7 @@ type PageElement struct {
 	OriginalY      float64 `json:"original_y"`       // 原始Y坐标（用于遮罩）
 	OriginalWidth  float64 `json:"original_width"`   // 原始宽度（用于遮罩）
 	OriginalHeight float64 `json:"original_height"`  // 原始高度（用于遮罩）
+	Changed        bool    `json:"changed,omitempty"` // 翻译后文本与原文不同（DiffOverlay 模式下只绘制这些元素）
 }
 
 // ReconstructedPage 重构的页面
@@ -77,7 +79,15 @@ func (r *PDFStylePreservingReplacer) ReplaceWithStylePreservation(inputPath, out
 	translatedPages := r.applyTranslationsWithStyles(pages, translations, config)
 
 	// 3. 重新构建PDF，保留原始样式
-	return r.reconstructPDFWithStyles(translatedPages, outputPath, inputPath, config)
+	if err := r.reconstructPDFWithStyles(translatedPages, outputPath, inputPath, config); err != nil {
+		return err
+	}
+
+	// 4. 复制评论、高亮等标记注释，注释内容使用译文；失败时保留不含注释的PDF
+	if err := CarryMarkupAnnotations(inputPath, outputPath, translations); err != nil {
+		log.Printf("警告：复制PDF注释失败: %v", err)
+	}
+	return nil
 }
 
 // extractPagesWithStyles 提取页面及其样式信息
@@ -206,13 +216,20 @@ func (r *PDFStylePreservingReplacer) mergeTextElements(texts []pdf.Text) []pdf.T
 		}
 
 		if isSameLine && isAdjacent {
-			// 合并
-			current.S += " " + next.S // 加个空格简单的合并
-			current.W += next.W // 累加宽度
-			// 简单的宽度估算更新
+			// 合并：ledongthuc/pdf 按字形返回文本，只在字形间距明显（超过字号的 20%）时补一个空格
+			end := current.X + current.W
 			if current.W == 0 {
-				current.W = r.estimateTextWidth(current.S, current.FontSize)
+				end = current.X + r.estimateTextWidth(current.S, current.FontSize)
 			}
+			if next.X-end > current.FontSize*0.2 && !strings.HasSuffix(current.S, " ") && !strings.HasPrefix(next.S, " ") {
+				current.S += " "
+			}
+			current.S += next.S
+			nextW := next.W
+			if nextW == 0 {
+				nextW = r.estimateTextWidth(next.S, next.FontSize)
+			}
+			current.W = math.Max(end, next.X+nextW) - current.X // 宽度为合并后的横向范围
 		} else {
 			merged = append(merged, current)
 			current = next
@@ -312,6 +329,7 @@ func (r *PDFStylePreservingReplacer) applyMonolingualTranslation(page Reconstruc
 		// 查找翻译
 		if translation, exists := translations[element.Text]; exists {
 			translatedElement.Text = translation
+			translatedElement.Changed = translation != element.Text
 
 			// 调整字体大小以适应翻译文本
 			if config.FontScale != 0 {
@@ -363,6 +381,7 @@ func (r *PDFStylePreservingReplacer) createSideBySideLayout(page ReconstructedPa
 		if translation, exists := translations[element.Text]; exists {
 			translatedElement := element
 			translatedElement.Text = translation
+			translatedElement.Changed = translation != element.Text
 			translatedElement.X = halfWidth + element.X*0.5 // 右半页
 			if config.FontScale != 0 {
 				translatedElement.FontSize *= config.FontScale
@@ -392,6 +411,7 @@ func (r *PDFStylePreservingReplacer) createTopBottomLayout(page ReconstructedPag
 		if translation, exists := translations[element.Text]; exists {
 			translatedElement := element
 			translatedElement.Text = translation
+			translatedElement.Changed = translation != element.Text
 			translatedElement.Y = element.Y - element.FontSize*config.LineSpacing // 下移
 			if config.FontScale != 0 {
 				translatedElement.FontSize *= config.FontScale
@@ -413,19 +433,25 @@ func (r *PDFStylePreservingReplacer) createInterleavedLayout(page ReconstructedP
 		PageHeight: page.PageHeight,
 	}
 
+	aligner := NewSentenceAligner()
+
 	for _, element := range page.Elements {
-		// 创建双语文本
+		// 创建双语文本（按句子交错）
 		bilingualText := element.Text
+		lineCount := 1
 		if translation, exists := translations[element.Text]; exists {
-			bilingualText = element.Text + "\n" + translation
+			lines := aligner.Align(element.Text, translation)
+			bilingualText = strings.Join(lines, "\n")
+			lineCount = len(lines)
 		}
 
 		bilingualElement := element
 		bilingualElement.Text = bilingualText
+		bilingualElement.Changed = bilingualText != element.Text
 		if config.FontScale != 0 {
 			bilingualElement.FontSize *= config.FontScale
 		}
-		bilingualElement.Height *= 2 // 增加高度以容纳两行文本
+		bilingualElement.Height *= float64(lineCount) // 增加高度以容纳交错的多行文本
 		bilingualElement.Width = r.estimateTextWidth(bilingualText, bilingualElement.FontSize)
 
 		interleavedPage.Elements = append(interleavedPage.Elements, bilingualElement)
@@ -440,6 +466,8 @@ func (r *PDFStylePreservingReplacer) reconstructPDFWithStyles(pages []Reconstruc
 
 	// 创建新的PDF文档
 	pdf := gofpdf.New("P", "pt", "A4", "")
+	// 译文按原文坐标绘制，去掉单元格默认的左右内边距，避免整体右移
+	pdf.SetCellMargin(0)
 
 	// 必须添加 gopher 字体支持，虽然我们用系统字体，但防止 panic
 	// gofpdfContrib 需要 gofpdf 的 instance
@@ -464,17 +492,27 @@ func (r *PDFStylePreservingReplacer) reconstructPDFWithStyles(pages []Reconstruc
 		pdf.UseImportedTemplate(tplName, scaleX, scaleY, tX, tY)
 
 		// 渲染页面元素 (Overlay)
+		skipped := 0
 		for _, element := range page.Elements {
+			if !r.shouldRenderElement(element, config) {
+				skipped++
+				continue
+			}
+
 			// 在 Overlay 模式下，我们只渲染那些 "被改动" 或 "是翻译" 的元素。
 			// 原始的未动元素已经在底图上了，不需要重绘防止加粗。
 			
-			// 简单起见，我们渲染所有元素，依靠遮罩遮住底图的文字。
+			// 默认渲染所有元素，依靠遮罩遮住底图的文字。
 			// 这样可以保证样式（特别是字体）的一致性（全部使用新字体）。
+			// DiffOverlay 模式下跳过未改动的元素（见 shouldRenderElement）。
 			// 对于公式，我们在 extract 阶段已经过滤掉了，所以这里 page.Elements 不包含公式
 			// 因此公式部分不会被遮罩，也不会被重绘，从而显示底图的原始矢量公式。
 			
 			r.renderElement(pdf, element, config)
 		}
+		if skipped > 0 {
+			log.Printf("第%d页：%d 个元素未改动，保留底图原文", page.PageNum, skipped)
+		}
 	}
 
 	// 保存PDF
@@ -494,11 +532,8 @@ func (r *PDFStylePreservingReplacer) addFontSupport(pdf *gofpdf.Fpdf) error {
 	if fontPath != "" && r.fileExists(fontPath) {
 		fontName := strings.TrimSuffix(filepath.Base(fontPath), filepath.Ext(fontPath))
 
-		// 使用AddUTF8Font方法，这个方法可以直接处理TTF文件
-		pdf.AddUTF8Font(fontName, "", fontPath)
-
 		// 检查是否成功
-		if err := pdf.Error(); err != nil {
+		if err := addUTF8Font(pdf, fontName, fontPath); err != nil {
 			log.Printf("添加字体失败: %v", err)
 			return err
 		}
@@ -508,6 +543,12 @@ func (r *PDFStylePreservingReplacer) addFontSupport(pdf *gofpdf.Fpdf) error {
 	return nil
 }
 
+// shouldRenderElement 判断元素是否需要遮罩并重绘
+// DiffOverlay 模式下只处理文本发生变化的元素，未改动的元素（含双语布局中的原文）由底图显示，避免重复绘制造成的加粗和遮罩痕迹
+func (r *PDFStylePreservingReplacer) shouldRenderElement(element PageElement, config StylePreservingConfig) bool {
+	return !config.DiffOverlay || element.Changed
+}
+
 // renderElement 渲染页面元素 (Overlay Mode)
 func (r *PDFStylePreservingReplacer) renderElement(pdf *gofpdf.Fpdf, element PageElement, config StylePreservingConfig) {
 	// 1. 绘制遮罩 (Whiteout)
@@ -0,0 +1,144 @@
+package translator
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+
+	"github.com/jung-kurt/gofpdf"
+	"github.com/ledongthuc/pdf"
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+)
+
+// overlayOps 统计输出页面内容流（不含导入的底图模板）中的遮罩矩形和文本
+func overlayOps(t *testing.T, path string) (masks int, texts []string) {
+	t.Helper()
+	p := newTestFlowProcessor(t, path, "")
+	if err := p.parsePDFStructure(); err != nil {
+		t.Fatalf("解析 %s 失败: %v", path, err)
+	}
+	for _, page := range p.flowData.Pages {
+		for _, stream := range page.ContentStreams {
+			for _, op := range stream.ParsedOps {
+				switch op.Operator {
+				case "re":
+					masks++
+				case "Tj", "TJ":
+					texts = append(texts, p.extractTextFromOperands(op.Operands, op.Operator, FontFlow{}))
+				}
+			}
+		}
+	}
+	return masks, texts
+}
+
+func TestDiffOverlaySkipsUnchangedElements(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "input.pdf")
+	pdf := gofpdf.New("P", "pt", "A4", "")
+	pdf.SetFont("Helvetica", "", 12)
+	pdf.AddPage()
+	pdf.Text(72, 100, "Hello world")
+	pdf.Text(72, 140, "GitHub")
+	if err := pdf.OutputFileAndClose(input); err != nil {
+		t.Fatal(err)
+	}
+
+	element := func(text string, y float64) PageElement {
+		return PageElement{Text: text, X: 72, Y: y, Width: 80, Height: 14, FontSize: 12, FontName: "Helvetica",
+			OriginalX: 72, OriginalY: y, OriginalWidth: 80, OriginalHeight: 14}
+	}
+	pages := []ReconstructedPage{{PageNum: 1, PageWidth: 595.28, PageHeight: 841.89,
+		Elements: []PageElement{element("Hello world", 742), element("GitHub", 702)}}}
+	translations := map[string]string{"Hello world": "Bonjour le monde", "GitHub": "GitHub"}
+
+	r := NewPDFStylePreservingReplacer()
+	config := GetDefaultStylePreservingConfig()
+	config.DiffOverlay = true
+	translated := r.applyTranslationsWithStyles(pages, translations, config)
+	if got := translated[0].Elements; !got[0].Changed || got[1].Changed {
+		t.Fatalf("Changed = %v, %v，期望只有译文不同的元素被标记", got[0].Changed, got[1].Changed)
+	}
+
+	for _, diffOverlay := range []bool{false, true} {
+		config.DiffOverlay = diffOverlay
+		output := filepath.Join(dir, "out.pdf")
+		if err := r.reconstructPDFWithStyles(translated, output, input, config); err != nil {
+			t.Fatal(err)
+		}
+		masks, texts := overlayOps(t, output)
+
+		wantMasks, wantTexts := 2, 2
+		if diffOverlay {
+			// 译文与原文相同的元素既不遮罩也不重绘，直接显示底图中的原文
+			wantMasks, wantTexts = 1, 1
+		}
+		if masks != wantMasks || len(texts) != wantTexts {
+			t.Errorf("DiffOverlay=%v: 遮罩 %d 个、文本 %q，期望遮罩 %d 个、文本 %d 段", diffOverlay, masks, texts, wantMasks, wantTexts)
+		}
+		for _, text := range texts {
+			if diffOverlay && text == "GitHub" {
+				t.Errorf("DiffOverlay 模式下未改动的元素被重绘")
+			}
+		}
+	}
+}
+
+// 请求中的"高级替换"接口在本仓库中不存在，保留字体和位置的替换由 ReplaceWithStylePreservation 完成
+func TestReplaceWithStylePreservation(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "input.pdf")
+	pdf := gofpdf.New("P", "pt", "A4", "")
+	pdf.SetFont("Helvetica", "", 12)
+	pdf.AddPage()
+	pdf.Text(72, 100, "Hello world")
+	if err := pdf.OutputFileAndClose(input); err != nil {
+		t.Fatal(err)
+	}
+
+	output := filepath.Join(dir, "out.pdf")
+	r := NewPDFStylePreservingReplacer()
+	if err := r.ReplaceWithStylePreservation(input, output, map[string]string{"Hello world": "Bonjour le monde"}, GetDefaultStylePreservingConfig()); err != nil {
+		t.Fatal(err)
+	}
+	if err := api.ValidateFile(output, nil); err != nil {
+		t.Fatalf("输出文件未通过校验: %v", err)
+	}
+
+	// 用同一解析器读取原文和译文的位置
+	position := func(path, text string) *TextElementFlow {
+		t.Helper()
+		p := newTestFlowProcessor(t, path, "")
+		if err := p.parsePDFStructure(); err != nil {
+			t.Fatal(err)
+		}
+		for i, element := range p.flowData.Pages[0].TextElements {
+			if element.Content == text {
+				return &p.flowData.Pages[0].TextElements[i]
+			}
+		}
+		t.Fatalf("%s 中未找到 %q，文本元素: %+v", filepath.Base(path), text, p.flowData.Pages[0].TextElements)
+		return nil
+	}
+	original, translated := position(input, "Hello world"), position(output, "Bonjour le monde")
+	// 译文绘制在原文的位置，字号不变
+	if math.Abs(translated.Position.X-original.Position.X) > 1 || math.Abs(translated.Position.Y-original.Position.Y) > 3 || translated.Font.Size != original.Font.Size {
+		t.Errorf("译文位置 (%.1f, %.1f)、字号 %.1f，原文 (%.1f, %.1f)、字号 %.1f", translated.Position.X, translated.Position.Y, translated.Font.Size,
+			original.Position.X, original.Position.Y, original.Font.Size)
+	}
+}
+
+func TestMergeTextElementsJoinsGlyphs(t *testing.T) {
+	// ledongthuc/pdf 按字形返回文本：相邻字形直接拼接，明显的间距补一个空格
+	glyph := func(s string, x float64) pdf.Text {
+		return pdf.Text{S: s, X: x, Y: 700, W: 6, FontSize: 12}
+	}
+	texts := []pdf.Text{glyph("H", 72), glyph("i", 78), glyph("y", 90), glyph("o", 96), glyph(" ", 102), glyph("u", 108)}
+	merged := NewPDFStylePreservingReplacer().mergeTextElements(texts)
+	if len(merged) != 1 || merged[0].S != "Hi yo u" {
+		t.Fatalf("mergeTextElements = %+v，期望合并为 \"Hi yo u\"", merged)
+	}
+	if merged[0].W != 42 {
+		t.Errorf("合并后宽度 = %.1f，期望 42", merged[0].W)
+	}
+}
@@ -0,0 +1,83 @@
+package translator
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+func TestGetOrderedBlocksFollowsColumns(t *testing.T) {
+	t.Chdir(t.TempDir())
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetFont("Helvetica", "", 11)
+	pdf.AddPage()
+	for row := 0; row < 3; row++ {
+		y := 30 + float64(row)*40
+		for col, name := range []string{"Left", "Right"} {
+			pdf.SetXY(15+float64(col)*110, y)
+			text := fmt.Sprintf("%s paragraph %d of the column keeps going for several lines of text so it forms a block.", name, row+1)
+			pdf.MultiCell(70, 5, text, "", "L", false)
+		}
+	}
+	path := filepath.Join(t.TempDir(), "columns.pdf")
+	if err := pdf.OutputFileAndClose(path); err != nil {
+		t.Fatal(err)
+	}
+
+	doc, err := OpenPDF(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blocks, err := doc.GetOrderedBlocks()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 记录每段首次出现的位置，左栏三段应全部排在右栏之前
+	var order []string
+	seen := make(map[string]bool)
+	for _, block := range blocks {
+		var text strings.Builder
+		for _, elem := range block.Elements {
+			text.WriteString(elem.Content + " ")
+		}
+		for _, name := range []string{"Left", "Right"} {
+			for row := 1; row <= 3; row++ {
+				key := fmt.Sprintf("%s paragraph %d", name, row)
+				if strings.Contains(text.String(), key) && !seen[key] {
+					seen[key] = true
+					order = append(order, key)
+				}
+			}
+		}
+	}
+	want := []string{
+		"Left paragraph 1", "Left paragraph 2", "Left paragraph 3",
+		"Right paragraph 1", "Right paragraph 2", "Right paragraph 3",
+	}
+	if strings.Join(order, ", ") != strings.Join(want, ", ") {
+		t.Errorf("阅读顺序 %q，期望按栏排列 %q", order, want)
+	}
+}
+
+func TestDehyphenate(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"inter-\nnational", "international"},
+		{"trans- \n former models", "transformer models"},
+		{"a well-known result", "a well-known result"},
+		{"well-\nknown", "wellknown"},
+		{"Anglo-\nSaxon", "Anglo-\nSaxon"},
+		{"pages 3-\n4", "pages 3-\n4"},
+	}
+	for _, tt := range tests {
+		if got := dehyphenate(tt.text); got != tt.want {
+			t.Errorf("dehyphenate(%q) = %q，期望 %q", tt.text, got, tt.want)
+		}
+	}
+}
@@ -36,6 +36,7 @@ type ClusteredTextBlock struct {
 	Alignment    string  // "left", "center", "right", "justify"
 	Indentation  float64 // 缩进
 	LineSpacing  float64 // 行间距
+	PageNumber   int     // 所在页码（从1开始）
 }
 
 // ClusterTextElements 聚类文本元素
@@ -590,6 +591,22 @@ func (tc *TextClusterer) ClusterPageBlocks(page *PDFPageFlow) []ClusteredTextBlo
 	return tc.ClusterTextElements(page.TextElements)
 }
 
+// ApplyBlockAlignment 将文本块的对齐方式写回页面中的对应文本元素
+func (tc *TextClusterer) ApplyBlockAlignment(page *PDFPageFlow, blocks []ClusteredTextBlock) {
+	alignments := make(map[string]string)
+	for _, block := range blocks {
+		for _, elem := range block.Elements {
+			alignments[elem.ID] = block.Alignment
+		}
+	}
+
+	for i := range page.TextElements {
+		if alignment, ok := alignments[page.TextElements[i].ID]; ok {
+			page.TextElements[i].Alignment = alignment
+		}
+	}
+}
+
 // GetBlocksByType 按类型获取文本块
 func GetBlocksByType(blocks []ClusteredTextBlock, blockType string) []ClusteredTextBlock {
 	result := []ClusteredTextBlock{}
@@ -0,0 +1,212 @@
+package translator
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os/exec"
+	"strings"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// PageText 一页提取出的文本
+type PageText struct {
+	Page int    // 页码（从1开始）
+	Text string // 页面文本，段落之间以空行分隔
+}
+
+// TextExtractor PDF 文本提取后端，不同的库对不同 PDF 的提取效果不同
+type TextExtractor interface {
+	Name() string
+	ExtractPages(path string) ([]PageText, error)
+}
+
+// 文本提取后端名称
+const (
+	TextExtractorAuto       = "auto"       // 依次尝试各后端，提取的文本过少时换用下一个
+	TextExtractorLedongthuc = "ledongthuc" // ledongthuc/pdf 逐页提取纯文本
+	TextExtractorPDFCPU     = "pdfcpu"     // pdfcpu 解析内容流，按阅读顺序聚类成段落
+	TextExtractorPdftotext  = "pdftotext"  // 调用外部 pdftotext（poppler-utils）
+)
+
+// autoMinCharsPerPage 自动模式下每页平均至少提取到的字符数（不含空白），低于此值时换用下一个后端
+const autoMinCharsPerPage = 20
+
+// ParseTextExtractor 解析文本提取后端名称，为空时返回 nil（使用默认的带坐标解析）
+func ParseTextExtractor(name string) (TextExtractor, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "":
+		return nil, nil
+	case TextExtractorAuto:
+		// pdftotext 的阅读顺序和空格处理最好，未安装时立即失败；ledongthuc 对部分字体会丢失空格，放在最后
+		return NewAutoTextExtractor(pdftotextExtractor{}, pdfcpuExtractor{}, ledongthucExtractor{}), nil
+	case TextExtractorLedongthuc:
+		return ledongthucExtractor{}, nil
+	case TextExtractorPDFCPU:
+		return pdfcpuExtractor{}, nil
+	case TextExtractorPdftotext:
+		return pdftotextExtractor{}, nil
+	default:
+		return nil, fmt.Errorf("不支持的文本提取后端: %s，可选: %s, %s, %s, %s", name,
+			TextExtractorAuto, TextExtractorLedongthuc, TextExtractorPDFCPU, TextExtractorPdftotext)
+	}
+}
+
+// ledongthucExtractor 使用 ledongthuc/pdf 逐页提取纯文本
+type ledongthucExtractor struct{}
+
+func (ledongthucExtractor) Name() string { return TextExtractorLedongthuc }
+
+// ExtractPages 逐页提取纯文本，单页提取失败时该页为空
+func (ledongthucExtractor) ExtractPages(path string) (pages []PageText, err error) {
+	// 解析库遇到损坏的结构时会 panic，转换为错误
+	defer func() {
+		if r := recover(); r != nil {
+			pages, err = nil, fmt.Errorf("ledongthuc/pdf 解析失败: %v", r)
+		}
+	}()
+
+	file, reader, err := pdf.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("ledongthuc/pdf 打开失败: %w", err)
+	}
+	defer file.Close()
+
+	for i := 1; i <= reader.NumPage(); i++ {
+		page := PageText{Page: i}
+		if p := reader.Page(i); !p.V.IsNull() {
+			if text, err := p.GetPlainText(nil); err == nil {
+				page.Text = cleanPDFText(text)
+			}
+		}
+		pages = append(pages, page)
+	}
+	return pages, nil
+}
+
+// pdfcpuExtractor 使用 pdfcpu 解析内容流，经聚类和分栏检测后按阅读顺序输出段落
+type pdfcpuExtractor struct{}
+
+func (pdfcpuExtractor) Name() string { return TextExtractorPDFCPU }
+
+// ExtractPages 按阅读顺序提取各页的文本块，文本块之间以空行分隔
+func (pdfcpuExtractor) ExtractPages(path string) ([]PageText, error) {
+	blocks, err := (&PDFDocument{Path: path}).GetOrderedBlocks()
+	if err != nil {
+		return nil, err
+	}
+	pageCount, err := GetPDFPageCount(path)
+	if err != nil {
+		pageCount = 0
+	}
+
+	texts := make(map[int][]string)
+	for _, block := range blocks {
+		var parts []string
+		for _, element := range block.Elements {
+			if content := strings.TrimSpace(element.Content); content != "" {
+				parts = append(parts, content)
+			}
+		}
+		if len(parts) > 0 {
+			texts[block.PageNumber] = append(texts[block.PageNumber], strings.Join(parts, "\n"))
+		}
+		if block.PageNumber > pageCount {
+			pageCount = block.PageNumber
+		}
+	}
+
+	pages := make([]PageText, pageCount)
+	for i := range pages {
+		pages[i] = PageText{Page: i + 1, Text: strings.Join(texts[i+1], "\n\n")}
+	}
+	return pages, nil
+}
+
+// pdftotextExtractor 调用外部 pdftotext 提取文本，需要安装 poppler-utils
+type pdftotextExtractor struct{}
+
+func (pdftotextExtractor) Name() string { return TextExtractorPdftotext }
+
+// ExtractPages 调用 pdftotext 提取文本，输出中各页以换页符分隔
+func (pdftotextExtractor) ExtractPages(path string) ([]PageText, error) {
+	bin, err := exec.LookPath("pdftotext")
+	if err != nil {
+		return nil, fmt.Errorf("未找到 pdftotext，请安装 poppler-utils: %w", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(bin, "-enc", "UTF-8", path, "-")
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("pdftotext 执行失败: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	parts := strings.Split(stdout.String(), "\f")
+	// 最后一页之后也有换页符
+	if len(parts) > 1 && strings.TrimSpace(parts[len(parts)-1]) == "" {
+		parts = parts[:len(parts)-1]
+	}
+	pages := make([]PageText, len(parts))
+	for i, part := range parts {
+		pages[i] = PageText{Page: i + 1, Text: cleanPDFText(part)}
+	}
+	return pages, nil
+}
+
+// AutoTextExtractor 依次尝试各后端，提取的文本过少（每页平均不足 autoMinCharsPerPage 个字符）时换用下一个
+// 所有后端的文本都过少时使用提取到文本最多的结果
+type AutoTextExtractor struct {
+	Extractors []TextExtractor
+}
+
+// NewAutoTextExtractor 创建自动选择的文本提取器，按参数顺序尝试
+func NewAutoTextExtractor(extractors ...TextExtractor) *AutoTextExtractor {
+	return &AutoTextExtractor{Extractors: extractors}
+}
+
+func (a *AutoTextExtractor) Name() string { return TextExtractorAuto }
+
+// ExtractPages 返回第一个提取到足够文本的后端的结果
+func (a *AutoTextExtractor) ExtractPages(path string) ([]PageText, error) {
+	var best []PageText
+	bestChars := -1
+	var errs []string
+	for _, extractor := range a.Extractors {
+		pages, err := extractor.ExtractPages(path)
+		if err != nil {
+			log.Printf("文本提取后端 %s 失败: %v", extractor.Name(), err)
+			errs = append(errs, fmt.Sprintf("%s: %v", extractor.Name(), err))
+			continue
+		}
+
+		chars := pageTextChars(pages)
+		if len(pages) > 0 && chars >= autoMinCharsPerPage*len(pages) {
+			log.Printf("使用文本提取后端 %s（%d 页，%d 个字符）", extractor.Name(), len(pages), chars)
+			return pages, nil
+		}
+		log.Printf("文本提取后端 %s 提取的文本过少（%d 页，%d 个字符），尝试下一个", extractor.Name(), len(pages), chars)
+		if chars > bestChars {
+			best, bestChars = pages, chars
+		}
+	}
+
+	if best == nil {
+		return nil, fmt.Errorf("所有文本提取后端都失败了: %s", strings.Join(errs, "; "))
+	}
+	return best, nil
+}
+
+// pageTextChars 统计各页文本的非空白字符数
+func pageTextChars(pages []PageText) int {
+	chars := 0
+	for _, page := range pages {
+		for _, r := range page.Text {
+			if !strings.ContainsRune(" \t\r\n\f", r) {
+				chars++
+			}
+		}
+	}
+	return chars
+}
@@ -0,0 +1,105 @@
+package translator
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// fakeExtractor 返回固定结果的文本提取后端，记录被调用的次数
+type fakeExtractor struct {
+	name  string
+	pages []PageText
+	err   error
+	calls int
+}
+
+func (f *fakeExtractor) Name() string { return f.name }
+
+func (f *fakeExtractor) ExtractPages(path string) ([]PageText, error) {
+	f.calls++
+	return f.pages, f.err
+}
+
+func TestAutoTextExtractorFallsBack(t *testing.T) {
+	text := "A paragraph that is long enough to count as extracted text."
+	empty := &fakeExtractor{name: "empty", pages: []PageText{{Page: 1}, {Page: 2}}}
+	secondary := &fakeExtractor{name: "secondary", pages: []PageText{{Page: 1, Text: text}, {Page: 2, Text: text}}}
+	unused := &fakeExtractor{name: "unused", pages: []PageText{{Page: 1, Text: "x"}}}
+
+	pages, err := NewAutoTextExtractor(empty, secondary, unused).ExtractPages("test.pdf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pages) != 2 || pages[0].Text != text {
+		t.Errorf("ExtractPages = %+v，期望使用第二个后端的结果", pages)
+	}
+	if empty.calls != 1 || secondary.calls != 1 || unused.calls != 0 {
+		t.Errorf("调用次数 = %d/%d/%d，期望 1/1/0", empty.calls, secondary.calls, unused.calls)
+	}
+}
+
+func TestAutoTextExtractorUsesBestWhenAllSparse(t *testing.T) {
+	failing := &fakeExtractor{name: "failing", err: errors.New("未安装")}
+	short := &fakeExtractor{name: "short", pages: []PageText{{Page: 1, Text: "abc"}}}
+	longer := &fakeExtractor{name: "longer", pages: []PageText{{Page: 1, Text: "abc def"}}}
+
+	pages, err := NewAutoTextExtractor(failing, short, longer).ExtractPages("test.pdf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pages) != 1 || pages[0].Text != "abc def" {
+		t.Errorf("ExtractPages = %+v，期望使用文本最多的结果", pages)
+	}
+
+	if _, err := NewAutoTextExtractor(failing).ExtractPages("test.pdf"); err == nil || !strings.Contains(err.Error(), "failing: 未安装") {
+		t.Errorf("所有后端失败时错误 = %v", err)
+	}
+}
+
+func TestParseTextExtractor(t *testing.T) {
+	for name, want := range map[string]string{
+		"":           "",
+		"Auto":       TextExtractorAuto,
+		"ledongthuc": TextExtractorLedongthuc,
+		" pdfcpu ":   TextExtractorPDFCPU,
+		"pdftotext":  TextExtractorPdftotext,
+	} {
+		extractor, err := ParseTextExtractor(name)
+		if err != nil {
+			t.Errorf("ParseTextExtractor(%q): %v", name, err)
+			continue
+		}
+		got := ""
+		if extractor != nil {
+			got = extractor.Name()
+		}
+		if got != want {
+			t.Errorf("ParseTextExtractor(%q) = %q，期望 %q", name, got, want)
+		}
+	}
+	if _, err := ParseTextExtractor("xpdf"); err == nil {
+		t.Error("不支持的后端应返回错误")
+	}
+}
+
+func TestTextExtractorsReadPages(t *testing.T) {
+	path := writeTestPDF(t, t.TempDir(), []string{"First page text", "Second page text"})
+	for _, extractor := range []TextExtractor{ledongthucExtractor{}, pdfcpuExtractor{}} {
+		pages, err := extractor.ExtractPages(path)
+		if err != nil {
+			t.Errorf("%s: %v", extractor.Name(), err)
+			continue
+		}
+		if len(pages) != 2 {
+			t.Errorf("%s: 提取到 %d 页，期望 2", extractor.Name(), len(pages))
+			continue
+		}
+		// ledongthuc 可能丢失空格，去掉空格后比较
+		for i, want := range []string{"Firstpagetext", "Secondpagetext"} {
+			if got := strings.ReplaceAll(pages[i].Text, " ", ""); !strings.Contains(got, want) || pages[i].Page != i+1 {
+				t.Errorf("%s: 第 %d 页 = %+v", extractor.Name(), i+1, pages[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package translator
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// pdfDocEncodingSpecials PDFDocEncoding 中与 Latin-1 不同的字符（PDF 32000-1 附录 D.2），其余字节与 Latin-1 相同
+var pdfDocEncodingSpecials = map[byte]rune{
+	0x18: '˘',
+	0x19: 'ˇ',
+	0x1A: 'ˆ',
+	0x1B: '˙',
+	0x1C: '˝',
+	0x1D: '˛',
+	0x1E: '˚',
+	0x1F: '˜',
+	0x80: '•',
+	0x81: '†',
+	0x82: '‡',
+	0x83: '…',
+	0x84: '—',
+	0x85: '–',
+	0x86: 'ƒ',
+	0x87: '⁄',
+	0x88: '‹',
+	0x89: '›',
+	0x8A: '−',
+	0x8B: '‰',
+	0x8C: '„',
+	0x8D: '“',
+	0x8E: '”',
+	0x8F: '‘',
+	0x90: '’',
+	0x91: '‚',
+	0x92: '™',
+	0x93: 'ﬁ',
+	0x94: 'ﬂ',
+	0x95: 'Ł',
+	0x96: 'Œ',
+	0x97: 'Š',
+	0x98: 'Ÿ',
+	0x99: 'Ž',
+	0x9A: 'ı',
+	0x9B: 'ł',
+	0x9C: 'œ',
+	0x9D: 'š',
+	0x9E: 'ž',
+	0xA0: '€',
+}
+
+// decodePDFTextString 将 PDF 文本字符串的字节解码为 UTF-8
+// 以 FE FF 开头的按 UTF-16BE 解码，以 EF BB BF 开头的按 UTF-8 解码（PDF 2.0），其余按 PDFDocEncoding 解码
+func decodePDFTextString(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xFE, 0xFF}):
+		data = data[2:]
+		units := make([]uint16, 0, len(data)/2)
+		for i := 0; i+1 < len(data); i += 2 {
+			units = append(units, uint16(data[i])<<8|uint16(data[i+1]))
+		}
+		return string(utf16.Decode(units))
+	case bytes.HasPrefix(data, []byte{0xEF, 0xBB, 0xBF}):
+		return strings.ToValidUTF8(string(data[3:]), string(utf8.RuneError))
+	}
+
+	var sb strings.Builder
+	sb.Grow(len(data))
+	for _, b := range data {
+		if r, ok := pdfDocEncodingSpecials[b]; ok {
+			sb.WriteRune(r)
+		} else {
+			sb.WriteRune(rune(b))
+		}
+	}
+	return sb.String()
+}
+
+// pdfTextStringValue 解码字面字符串或十六进制字符串形式的 PDF 文本字符串，其他类型的对象返回 false
+func pdfTextStringValue(obj types.Object) (string, bool) {
+	var data []byte
+	var err error
+	switch s := obj.(type) {
+	case types.StringLiteral:
+		data, err = types.Unescape(s.Value())
+	case types.HexLiteral:
+		data, err = s.Bytes()
+	default:
+		return "", false
+	}
+	if err != nil {
+		return "", false
+	}
+	return decodePDFTextString(data), true
+}
@@ -0,0 +1,56 @@
+package translator
+
+import (
+	"encoding/hex"
+	"path/filepath"
+	"testing"
+
+	"github.com/jung-kurt/gofpdf"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+func TestDecodePDFTextString(t *testing.T) {
+	tests := []struct {
+		data []byte
+		want string
+	}{
+		{[]byte("\xfe\xff\x00C\x00a\x00f\x00\xe9\x00 \x6d\x4b\x8b\xd5"), "Café 测试"},
+		{[]byte("\xef\xbb\xbfCaf\xc3\xa9"), "Café"},
+		{[]byte("Caf\xe9 \x84 r\x8dsum\x8e"), "Café — r“sum”"},
+		{[]byte("Plain title"), "Plain title"},
+	}
+	for _, tt := range tests {
+		if got := decodePDFTextString(tt.data); got != tt.want {
+			t.Errorf("decodePDFTextString(%q) = %q，期望 %q", tt.data, got, tt.want)
+		}
+	}
+}
+
+func TestExtractInfoDictDecodesTextStrings(t *testing.T) {
+	p := newTestFlowProcessor(t, "", "")
+	p.flowData = &PDFFlowData{}
+	p.extractInfoDict(types.Dict{
+		"Title":   types.HexLiteral(hex.EncodeToString([]byte("\xfe\xff\x00R\x00\xe9\x00s\x00u\x00m\x00\xe9"))),
+		"Author":  types.StringLiteral(`\376\377\000Z\000o\000\353`),
+		"Subject": types.StringLiteral(`Caf\351`),
+	})
+	meta := p.flowData.Metadata
+	if meta.Title != "Résumé" || meta.Author != "Zoë" || meta.Subject != "Café" {
+		t.Errorf("元数据 = %q / %q / %q，期望 Résumé / Zoë / Café", meta.Title, meta.Author, meta.Subject)
+	}
+}
+
+func TestUTF16TitleRoundTrips(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "title.pdf")
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	// gofpdf 在 isUTF8 为 true 时以带 BOM 的 UTF-16BE 写入标题
+	pdf.SetTitle("Données spéciales", true)
+	pdf.AddPage()
+	if err := pdf.OutputFileAndClose(path); err != nil {
+		t.Fatal(err)
+	}
+	if title := readPDFMetadata(t, path).Title; title != "Données spéciales" {
+		t.Errorf("标题 = %q，期望 Données spéciales", title)
+	}
+}
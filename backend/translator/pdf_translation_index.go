@@ -0,0 +1,206 @@
+package translator
+
+import (
+	"sort"
+	"strings"
+)
+
+// maxSimilarityCandidates 相似度匹配最多计算的候选条目数，候选按相似度上界从高到低比较
+const maxSimilarityCandidates = 256
+
+// translationIndex 翻译映射的查找索引，供 findBestTranslation 使用
+// 精确和标准化匹配为 O(1) 查找；相似度和包含匹配只比较长度在可能范围内的条目；
+// 关键短语匹配通过关键词倒排索引，只比较至少有一个共同关键词的条目
+type translationIndex struct {
+	exact      map[string]string
+	entries    []translationIndexEntry // 按原文排序，匹配结果与映射的遍历顺序无关
+	normalized map[string]int          // 标准化文本 -> 条目下标，相同时取排序靠前的条目
+	byNormLen  []int                   // 按标准化文本长度排序的条目下标，用于相似度匹配
+	byLen      []int                   // 按原文长度排序的条目下标，用于包含匹配
+	words      map[string][]int        // 关键词 -> 包含该关键词的条目下标（升序）
+}
+
+// translationIndexEntry 索引中的一条翻译映射
+type translationIndexEntry struct {
+	original    string
+	translation string
+	normalized  string
+	words       map[string]bool // 关键词集合
+	wordCount   int             // 关键词个数（含重复），与 hasSignificantOverlap 的计数方式一致
+}
+
+// newTranslationIndex 为翻译映射（通常是增强后的映射）建立查找索引
+func (p *PDFFlowProcessor) newTranslationIndex(translations map[string]string) *translationIndex {
+	originals := make([]string, 0, len(translations))
+	for original := range translations {
+		originals = append(originals, original)
+	}
+	sort.Strings(originals)
+
+	index := &translationIndex{
+		exact:      translations,
+		entries:    make([]translationIndexEntry, len(originals)),
+		normalized: make(map[string]int, len(originals)),
+		byNormLen:  make([]int, 0, len(originals)),
+		byLen:      make([]int, len(originals)),
+		words:      make(map[string][]int),
+	}
+	for i, original := range originals {
+		words := p.extractSignificantWords(original)
+		entry := translationIndexEntry{
+			original:    original,
+			translation: translations[original],
+			normalized:  p.normalizeText(original),
+			words:       make(map[string]bool, len(words)),
+			wordCount:   len(words),
+		}
+		for _, word := range words {
+			if !entry.words[word] {
+				entry.words[word] = true
+				index.words[word] = append(index.words[word], i)
+			}
+		}
+		index.entries[i] = entry
+
+		if _, exists := index.normalized[entry.normalized]; !exists {
+			index.normalized[entry.normalized] = i
+		}
+		if entry.normalized != "" {
+			index.byNormLen = append(index.byNormLen, i)
+		}
+		index.byLen[i] = i
+	}
+
+	sort.SliceStable(index.byNormLen, func(a, b int) bool {
+		return len(index.entries[index.byNormLen[a]].normalized) < len(index.entries[index.byNormLen[b]].normalized)
+	})
+	sort.SliceStable(index.byLen, func(a, b int) bool {
+		return len(index.entries[index.byLen[a]].original) < len(index.entries[index.byLen[b]].original)
+	})
+	return index
+}
+
+// similarityMatch 查找与标准化文本相似度高于 threshold 的条目
+// 相似度为最长公共子序列长度除以较长文本的长度，因此只有长度比在 threshold~1/threshold 之间的条目可能入选；
+// 公共子序列不会超过两段文本的字符重合数，以此作为上界，从上界最高的候选开始比较，上界不超过当前最佳时停止
+func (idx *translationIndex) similarityMatch(p *PDFFlowProcessor, cleanText string, threshold float64) (translationIndexEntry, float64, bool) {
+	n := len(cleanText)
+	if n == 0 {
+		return translationIndexEntry{}, 0, false
+	}
+	lo := sort.Search(len(idx.byNormLen), func(i int) bool {
+		return float64(len(idx.entries[idx.byNormLen[i]].normalized)) > threshold*float64(n)
+	})
+
+	var histogram [256]int
+	for i := 0; i < n; i++ {
+		histogram[cleanText[i]]++
+	}
+
+	type candidate struct {
+		entry int
+		bound float64
+	}
+	var candidates []candidate
+	for _, i := range idx.byNormLen[lo:] {
+		entry := idx.entries[i]
+		m := len(entry.normalized)
+		if float64(n) <= threshold*float64(m) {
+			break
+		}
+		// 与原实现相同的长度比过滤（按原文长度）
+		lenRatio := float64(n) / float64(len(entry.original))
+		if lenRatio < 0.3 || lenRatio > 3.0 {
+			continue
+		}
+
+		remaining := histogram
+		common := 0
+		for j := 0; j < m; j++ {
+			if c := entry.normalized[j]; remaining[c] > 0 {
+				remaining[c]--
+				common++
+			}
+		}
+		if bound := float64(common) / float64(max(n, m)); bound > threshold {
+			candidates = append(candidates, candidate{entry: i, bound: bound})
+		}
+	}
+
+	sort.SliceStable(candidates, func(a, b int) bool {
+		return candidates[a].bound > candidates[b].bound
+	})
+	if len(candidates) > maxSimilarityCandidates {
+		candidates = candidates[:maxSimilarityCandidates]
+	}
+
+	best, bestScore := -1, threshold
+	for _, c := range candidates {
+		if c.bound <= bestScore {
+			break
+		}
+		if score := p.calculateSimilarity(cleanText, idx.entries[c.entry].original); score > bestScore {
+			best, bestScore = c.entry, score
+		}
+	}
+	if best < 0 {
+		return translationIndexEntry{}, 0, false
+	}
+	return idx.entries[best], bestScore, true
+}
+
+// containmentMatch 查找包含该文本、且该文本占其 60% 以上长度的条目，优先选择较短的条目
+func (idx *translationIndex) containmentMatch(cleanText string) (translationIndexEntry, bool) {
+	n := len(cleanText)
+	if n <= 10 {
+		return translationIndexEntry{}, false
+	}
+	lo := sort.Search(len(idx.byLen), func(i int) bool {
+		return len(idx.entries[idx.byLen[i]].original) >= n
+	})
+	for _, i := range idx.byLen[lo:] {
+		entry := idx.entries[i]
+		if float64(n)/float64(len(entry.original)) <= 0.6 {
+			break
+		}
+		if len(entry.original) > 10 && strings.Contains(entry.original, cleanText) {
+			return entry, true
+		}
+	}
+	return translationIndexEntry{}, false
+}
+
+// overlapMatch 查找关键词显著重叠的条目：共同关键词占较少一方关键词数的 50% 以上
+// 通过倒排索引只比较至少有一个共同关键词的条目
+func (idx *translationIndex) overlapMatch(p *PDFFlowProcessor, cleanText string) (translationIndexEntry, bool) {
+	words := p.extractSignificantWords(cleanText)
+	if len(words) == 0 {
+		return translationIndexEntry{}, false
+	}
+
+	seen := make(map[int]bool)
+	var candidates []int
+	for _, word := range words {
+		for _, i := range idx.words[word] {
+			if !seen[i] {
+				seen[i] = true
+				candidates = append(candidates, i)
+			}
+		}
+	}
+	sort.Ints(candidates)
+
+	for _, i := range candidates {
+		entry := idx.entries[i]
+		overlap := 0
+		for _, word := range words {
+			if entry.words[word] {
+				overlap++
+			}
+		}
+		if float64(overlap)/float64(min(len(words), entry.wordCount)) > 0.5 {
+			return entry, true
+		}
+	}
+	return translationIndexEntry{}, false
+}
@@ -0,0 +1,160 @@
+package translator
+
+import (
+	"fmt"
+	"maps"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var indexTestWords = strings.Fields(`the memory index graph vector search query cluster partition
+	disk latency throughput recall neighbor billion points storage layout build update
+	system design result dataset accuracy method compression quantized routing`)
+
+// indexTestMappings 生成 n 条确定的原文 -> 译文映射，原文为 6~11 个单词的句子
+func indexTestMappings(n int) map[string]string {
+	rng := rand.New(rand.NewPCG(1, uint64(n)))
+	translations := make(map[string]string, n)
+	for len(translations) < n {
+		words := make([]string, 6+rng.IntN(6))
+		for i := range words {
+			words[i] = indexTestWords[rng.IntN(len(indexTestWords))]
+		}
+		source := strings.Join(words, " ") + fmt.Sprintf(" %d.", len(translations))
+		translations[source] = fmt.Sprintf("译文%d", len(translations))
+	}
+	return translations
+}
+
+// indexTestQueries 为部分原文生成覆盖各匹配阶段的查询文本：
+// 原样、标准化后相同、个别字符不同、截取主要部分、关键词重排，以及无关文本
+func indexTestQueries(translations map[string]string, limit int) []string {
+	sources := slices.Sorted(maps.Keys(translations))
+	queries := []string{"zzzz qqqq wwww", "ab"}
+	for _, source := range sources[:min(limit, len(sources))] {
+		words := strings.Fields(source)
+		typo := []byte(source)
+		typo[len(typo)/2] = 'x'
+		reordered := append([]string{"unrelated"}, words[len(words)/2:]...)
+		reordered = append(reordered, words[:len(words)/2]...)
+		queries = append(queries,
+			source,
+			"  "+strings.ToUpper(source),
+			string(typo),
+			source[:len(source)*3/4],
+			strings.Join(reordered, " "),
+		)
+	}
+	return queries
+}
+
+// linearMatches 按索引前的实现逐条扫描映射，返回该实现可能返回的全部译文
+// 原实现遍历 map，同一阶段有多条满足条件时返回哪一条取决于遍历顺序
+func linearMatches(p *PDFFlowProcessor, text string, translations map[string]string) map[string]bool {
+	matches := make(map[string]bool)
+	cleanText := strings.TrimSpace(text)
+	if len(cleanText) < 3 {
+		return matches
+	}
+	if translation, exists := translations[text]; exists {
+		matches[translation] = true
+		return matches
+	}
+
+	cleanText = p.normalizeText(text)
+	for original, translation := range translations {
+		if p.normalizeText(original) == cleanText {
+			matches[translation] = true
+		}
+	}
+	if len(matches) > 0 {
+		return matches
+	}
+
+	bestScore := 0.8
+	for original, translation := range translations {
+		lenRatio := float64(len(cleanText)) / float64(len(original))
+		if lenRatio < 0.3 || lenRatio > 3.0 {
+			continue
+		}
+		switch score := p.calculateSimilarity(cleanText, original); {
+		case score > bestScore:
+			bestScore = score
+			clear(matches)
+			matches[translation] = true
+		case score == bestScore && len(matches) > 0:
+			matches[translation] = true
+		}
+	}
+	if len(matches) > 0 {
+		return matches
+	}
+
+	for original, translation := range translations {
+		if len(cleanText) > 10 && len(original) > 10 && strings.Contains(original, cleanText) &&
+			float64(len(cleanText))/float64(len(original)) > 0.6 {
+			matches[translation] = true
+		}
+	}
+	if len(matches) > 0 {
+		return matches
+	}
+
+	for original, translation := range translations {
+		if p.hasSignificantOverlap(cleanText, original) {
+			matches[translation] = true
+		}
+	}
+	return matches
+}
+
+func TestFindBestTranslationMatchesLinearScan(t *testing.T) {
+	p := newTestFlowProcessor(t, "", "")
+	translations := indexTestMappings(100)
+	enhanced := p.enhanceTranslationMappings(translations)
+	index := p.newTranslationIndex(enhanced)
+
+	matched := 0
+	for _, query := range indexTestQueries(translations, 20) {
+		got := p.findBestTranslation(query, index)
+		want := linearMatches(p, query, enhanced)
+		if len(want) == 0 {
+			if got != "" {
+				t.Errorf("%q 匹配到 %q，期望无匹配", query, got)
+			}
+			continue
+		}
+		if !want[got] {
+			t.Errorf("%q 匹配到 %q，期望为 %v 之一", query, got, want)
+		}
+		matched++
+	}
+	if matched == 0 {
+		t.Fatal("没有任何查询匹配到译文")
+	}
+}
+
+func BenchmarkFindBestTranslation(b *testing.B) {
+	p := newTestFlowProcessor(b, "", "")
+	translations := indexTestMappings(1000)
+	enhanced := p.enhanceTranslationMappings(translations)
+	queries := indexTestQueries(translations, 20)
+
+	b.Run("linear", func(b *testing.B) {
+		for b.Loop() {
+			for _, query := range queries {
+				linearMatches(p, query, enhanced)
+			}
+		}
+	})
+	b.Run("indexed", func(b *testing.B) {
+		for b.Loop() {
+			index := p.newTranslationIndex(enhanced)
+			for _, query := range queries {
+				p.findBestTranslation(query, index)
+			}
+		}
+	})
+}
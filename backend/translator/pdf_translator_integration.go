@@ -10,7 +10,10 @@ import (
 
 // PDFTranslatorIntegration PDF翻译集成
 type PDFTranslatorIntegration struct {
-	Client *TranslatorClient
+	Client       *TranslatorClient
+	FailedBlocks []TranslateResult  // 翻译失败（已回退为原文）的文本块
+	Stats        BlockStats         // 文本块统计信息
+	Confidences  map[string]float64 // 自评可信度低于 1 的文本块：原文 -> 可信度
 }
 
 // NewPDFTranslatorIntegration 创建PDF翻译集成
@@ -31,35 +34,36 @@ func (pti *PDFTranslatorIntegration) TranslateTexts(texts []string, targetLangua
 
 	log.Printf("开始翻译 %d 个文本块", total)
 
+	// 按过滤规则跳过的文本（空白、过短、页码、引用等）直接使用原文，人工修改过的除外
+	// positions 记录待翻译文本在 texts 中的位置，用于将结果的索引换回调用方的索引
+	var pending []string
+	var positions []int
 	for i, text := range texts {
-		// 跳过空文本
-		if strings.TrimSpace(text) == "" {
+		if _, edited := pti.Client.Edits[text]; !edited && !pti.Client.Filter.ShouldTranslate(text) {
 			translations[text] = text
 			continue
 		}
+		pending = append(pending, text)
+		positions = append(positions, i)
+	}
 
-		// 跳过太短的文本
-		if len(strings.TrimSpace(text)) < 3 {
-			translations[text] = text
-			continue
-		}
-
-		// 执行翻译
-		translated, err := pti.Client.Translate(text, targetLanguage, userPrompt)
-		if err != nil {
-			log.Printf("警告：翻译第 %d 个文本块失败: %v", i+1, err)
-			translations[text] = text // 使用原文
-		} else {
-			translations[text] = translated
-		}
-
-		// 更新进度
-		if progressCallback != nil {
-			progress := float64(i+1) / float64(total)
-			progressCallback(progress)
-		}
+	results := pti.Client.TranslateBlocks(pending, targetLanguage, userPrompt, progressCallback)
+	for i := range results {
+		results[i].Index = positions[results[i].Index]
+		translations[results[i].Original] = results[i].Translated
+	}
+	if err := pti.Client.Context().Err(); err != nil {
+		return nil, fmt.Errorf("翻译中止: %w", err)
+	}
 
-		log.Printf("翻译进度: %d/%d", i+1, total)
+	pti.FailedBlocks = FailedResults(results)
+	pti.Confidences = ResultConfidences(results)
+	pti.Stats = NewBlockStats(pending)
+	if pti.Stats.SavedBlocks > 0 {
+		log.Printf("去重后需翻译 %d 个文本块，节省 %d 次翻译", pti.Stats.UniqueBlocks, pti.Stats.SavedBlocks)
+	}
+	if len(pti.FailedBlocks) > 0 {
+		log.Printf("警告：%d 个文本块翻译失败，已使用原文", len(pti.FailedBlocks))
 	}
 
 	log.Printf("翻译完成，成功翻译 %d 个文本块", len(translations))
@@ -103,6 +107,7 @@ func (pti *PDFTranslatorIntegration) TranslatePDFWithClient(inputPath, outputDir
 		}
 	}
 
+	pti.Client.Pages = TextPages(content)
 	translations, err := pti.TranslateTexts(texts, targetLanguage, userPrompt, translationProgressCallback)
 	if err != nil {
 		return nil, fmt.Errorf("翻译失败: %w", err)
@@ -0,0 +1,39 @@
+package translator
+
+import "testing"
+
+func TestTranslateTextsReportsFailedBlocksAtInputPositions(t *testing.T) {
+	client, _ := newStubClient(t, "The second paragraph fails to translate.")
+	pti := NewPDFTranslatorIntegration(client)
+
+	texts := []string{
+		"12",                                 // 纯数字，被过滤
+		"The first paragraph is translated.", // 翻译成功
+		"   ",                                // 空白，被过滤
+		"The second paragraph fails to translate.", // 翻译失败
+		"The third paragraph is translated too.",   // 翻译成功
+	}
+	translations, err := pti.TranslateTexts(texts, "zh", "", nil)
+	if err != nil {
+		t.Fatalf("TranslateTexts: %v", err)
+	}
+
+	if len(pti.FailedBlocks) != 1 {
+		t.Fatalf("FailedBlocks = %d 个，期望 1 个: %+v", len(pti.FailedBlocks), pti.FailedBlocks)
+	}
+	failed := pti.FailedBlocks[0]
+	if failed.Index != 3 || texts[failed.Index] != failed.Original {
+		t.Errorf("失败文本块的索引 = %d（%q），期望 3", failed.Index, failed.Original)
+	}
+	if got := translations[texts[3]]; got != texts[3] {
+		t.Errorf("失败的文本块应回退为原文，得到 %q", got)
+	}
+	for _, i := range []int{1, 4} {
+		if !isStubTranslation(translations[texts[i]]) {
+			t.Errorf("texts[%d] 未翻译: %q", i, translations[texts[i]])
+		}
+	}
+	if got := translations[texts[0]]; got != texts[0] {
+		t.Errorf("被过滤的文本块应保留原文，得到 %q", got)
+	}
+}
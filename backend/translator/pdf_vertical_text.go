@@ -0,0 +1,107 @@
+package translator
+
+import (
+	"math"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// verticalColumnSpacing 竖排相邻两列中心线的间距（相对字号）
+const verticalColumnSpacing = 1.2
+
+// verticalGlyph 竖排输出的单个字符：X 为所在列的中心线，Y 为字符格的上边缘
+type verticalGlyph struct {
+	Text string
+	X, Y float64
+}
+
+// isVerticalTransform 判断变换矩阵是否将文本方向旋转了约 90 度（文字沿页面纵向排列）
+func isVerticalTransform(m TransformMatrix) bool {
+	return math.Abs(m.B) > math.Abs(m.A) && math.Abs(m.C) > math.Abs(m.D)
+}
+
+// layoutVerticalText 按竖排规则计算每个字符的位置
+// 字符自上而下逐个堆叠，超过 columnHeight 或遇到换行时另起一列，列从右向左排列
+// x 为第一列的中心线，top 为列的上边缘
+func layoutVerticalText(content string, x, top, fontSize, columnHeight float64) []verticalGlyph {
+	rows := int(columnHeight / fontSize)
+	if rows < 1 {
+		rows = 1
+	}
+
+	var glyphs []verticalGlyph
+	column, row := 0, 0
+	for _, r := range content {
+		if r == '\n' {
+			column, row = column+1, 0
+			continue
+		}
+		if row >= rows {
+			column, row = column+1, 0
+		}
+		// 列首的空白不占位，其余空白占一个字符格
+		if unicode.IsSpace(r) {
+			if row > 0 {
+				row++
+			}
+			continue
+		}
+		glyphs = append(glyphs, verticalGlyph{
+			Text: string(r),
+			X:    x - float64(column)*fontSize*verticalColumnSpacing,
+			Y:    top + float64(row)*fontSize,
+		})
+		row++
+	}
+	return glyphs
+}
+
+// useVerticalLayout 判断元素是否按竖排输出：需启用 VerticalText，原文为竖排，且目标语言为 CJK 语言
+// 未设置目标语言时按译文是否包含 CJK 字符判断
+func (p *PDFFlowProcessor) useVerticalLayout(element TextElementFlow, content string) bool {
+	if !p.VerticalText || !element.Vertical {
+		return false
+	}
+	if p.TargetLanguage != "" {
+		return isCJKLanguage(p.TargetLanguage)
+	}
+	for _, r := range content {
+		if p.isCJK(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// renderVerticalText 以竖排方式输出译文：逐字堆叠，从右到左分列
+// 列高取原文竖排时占用的高度，并限制在页面范围内
+func (p *PDFFlowProcessor) renderVerticalText(pdf *gofpdf.Fpdf, element TextElementFlow, content string, fontSize float64, mediaBox BoundingBox) {
+	original := element.OriginalContent
+	if original == "" {
+		original = content
+	}
+	originalSize := element.Font.Size
+	if originalSize <= 0 {
+		originalSize = fontSize
+	}
+	columnHeight := float64(utf8.RuneCountInString(original)) * originalSize
+
+	pageWidth, pageHeight := pdf.GetPageSize()
+	x := math.Min(math.Max(element.Position.X, mediaBox.X+fontSize), mediaBox.X+pageWidth-fontSize)
+	top := math.Min(math.Max(element.Position.Y, mediaBox.Y), mediaBox.Y+pageHeight-fontSize)
+	columnHeight = math.Max(math.Min(columnHeight, mediaBox.Y+pageHeight-top), fontSize)
+
+	glyphs := layoutVerticalText(content, x, top, fontSize, columnHeight)
+	for _, glyph := range glyphs {
+		// pdf.Text 以基线定位，CJK 字形的基线约在字符格上边缘以下 0.88 个字号处
+		pdf.Text(glyph.X-pdf.GetStringWidth(glyph.Text)/2, glyph.Y+fontSize*0.88, glyph.Text)
+	}
+
+	p.logger.Debug("竖排输出文本", map[string]interface{}{
+		"ID":  element.ID,
+		"字符数": len(glyphs),
+		"列高":  columnHeight,
+	})
+}
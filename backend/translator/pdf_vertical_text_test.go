@@ -0,0 +1,127 @@
+package translator
+
+import (
+	"bytes"
+	"math"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// textPositions 返回输出中每段文本的起点（PDF坐标）
+func textPositions(t *testing.T, pdf *gofpdf.Fpdf) map[string][2]float64 {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	positions := make(map[string][2]float64)
+	re := regexp.MustCompile(`BT ([\d.]+) ([\d.]+) Td \((.*?)\) ?Tj ET`)
+	for _, m := range re.FindAllStringSubmatch(buf.String(), -1) {
+		x, _ := strconv.ParseFloat(m[1], 64)
+		y, _ := strconv.ParseFloat(m[2], 64)
+		positions[m[3]] = [2]float64{x, y}
+	}
+	return positions
+}
+
+func TestLayoutVerticalText(t *testing.T) {
+	glyphs := layoutVerticalText("一二三四\n五", 300, 100, 10, 30)
+	want := []verticalGlyph{
+		{"一", 300, 100}, {"二", 300, 110}, {"三", 300, 120},
+		{"四", 288, 100},
+		{"五", 276, 100},
+	}
+	if len(glyphs) != len(want) {
+		t.Fatalf("glyphs = %+v", glyphs)
+	}
+	for i := range want {
+		if glyphs[i].Text != want[i].Text || math.Abs(glyphs[i].X-want[i].X) > 1e-9 || math.Abs(glyphs[i].Y-want[i].Y) > 1e-9 {
+			t.Errorf("第%d个字符 = %+v，期望 %+v", i, glyphs[i], want[i])
+		}
+	}
+}
+
+func TestParseVerticalText(t *testing.T) {
+	p := newTestFlowProcessor(t, "input.pdf", "")
+	ops, err := p.parseOperations("BT /F1 12 Tf 0 1 -1 0 300 700 Tm (Tate) Tj 1 0 0 1 72 600 Tm (Yoko) Tj ET")
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := &PDFPageFlow{ContentStreams: []ContentStreamFlow{{ParsedOps: ops}}}
+	if err := p.parseContentElements(page, nil); err != nil {
+		t.Fatal(err)
+	}
+	vertical := make(map[string]bool)
+	for _, elem := range page.TextElements {
+		vertical[elem.Content] = elem.Vertical
+	}
+	if !vertical["Tate"] || vertical["Yoko"] {
+		t.Errorf("Vertical = %v，期望仅旋转 90 度的文本为竖排", vertical)
+	}
+}
+
+func TestRenderVerticalTextStacksCharacters(t *testing.T) {
+	render := func(verticalText bool) map[string][2]float64 {
+		p := newTestFlowProcessor(t, "input.pdf", "")
+		p.VerticalText = verticalText
+		p.TargetLanguage = "Uni"
+		pdf := gofpdf.New("P", "pt", "A4", "")
+		pdf.SetCompression(false)
+		pdf.AddPage()
+		elem := TextElementFlow{
+			Content:         "ABC",
+			OriginalContent: "ABCDEF",
+			Position:        PositionFlow{X: 300, Y: 100},
+			Font:            FontFlow{Name: "Helvetica", Size: 12},
+			Vertical:        true,
+		}
+		if err := p.renderTextElement(pdf, elem, 0, BoundingBox{Width: 595, Height: 842}); err != nil {
+			t.Fatal(err)
+		}
+		return textPositions(t, pdf)
+	}
+
+	// 默认不启用竖排，整段按横排输出
+	if horizontal := render(false); len(horizontal) != 1 {
+		t.Errorf("未启用竖排时的输出 = %v，期望一段横排文本", horizontal)
+	}
+
+	stacked := render(true)
+	if len(stacked) != 3 {
+		t.Fatalf("竖排输出 = %v，期望逐字输出 3 段", stacked)
+	}
+	a, b, c := stacked["A"], stacked["B"], stacked["C"]
+	// 字符沿同一中心线自上而下排列（PDF 坐标的 Y 向下递减）
+	if !(a[1] > b[1] && b[1] > c[1]) {
+		t.Errorf("字符未自上而下堆叠: A=%v B=%v C=%v", a, b, c)
+	}
+	if math.Abs(a[0]-b[0]) > 2 || math.Abs(b[0]-c[0]) > 2 {
+		t.Errorf("字符不在同一列: A=%v B=%v C=%v", a, b, c)
+	}
+}
+
+func TestUseVerticalLayout(t *testing.T) {
+	p := &PDFFlowProcessor{VerticalText: true}
+	vertical := TextElementFlow{Vertical: true}
+	tests := []struct {
+		target  string
+		elem    TextElementFlow
+		content string
+		want    bool
+	}{
+		{"ja", vertical, "こんにちは", true},
+		{"English", vertical, "Hello", false},
+		{"", vertical, "你好", true},
+		{"", vertical, "Hello", false},
+		{"ja", TextElementFlow{}, "こんにちは", false},
+	}
+	for _, tt := range tests {
+		p.TargetLanguage = tt.target
+		if got := p.useVerticalLayout(tt.elem, tt.content); got != tt.want {
+			t.Errorf("useVerticalLayout(%q, vertical=%v, %q) = %v，期望 %v", tt.target, tt.elem.Vertical, tt.content, got, tt.want)
+		}
+	}
+}
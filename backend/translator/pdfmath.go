@@ -5,6 +5,7 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 )
 
@@ -13,30 +14,50 @@ type PDFMathTranslator struct {
 	Parser      *PDFParser
 	FontPath    string
 	Integration *PDFTranslatorIntegration
+	Progress    *PageProgress // 逐页翻译进度，为空时不跟踪
+	Source      *SourceCache  // 多种目标语言共享的解析结果，为空时每次重新解析
+
+	// 单语PDF的覆盖和重新生成实现，为空时使用默认实现（见 saveMonolingualPDF）
+	overlayPDF    func(inputPath, outputPath string, translationMap map[string]string, config StylePreservingConfig) error
+	regeneratePDF func(pdfDoc *PDFDocument, outputPath string, translationMap map[string]string) error
 }
 
 // PDFMathConfig PDFMathTranslate配置
 type PDFMathConfig struct {
-	LangIn          string            `json:"lang_in"`
-	LangOut         string            `json:"lang_out"`
-	Service         string            `json:"service"`
-	Thread          int               `json:"thread"`
-	Pages           string            `json:"pages,omitempty"`
-	Output          string            `json:"output"`
-	SkipSubsetFonts bool              `json:"skip_subset_fonts"`
-	IgnoreCache     bool              `json:"ignore_cache"`
-	Compatible      bool              `json:"compatible"`
-	Prompt          string            `json:"prompt,omitempty"`
-	GenerateMode    string            `json:"generate_mode,omitempty"` // 新增：生成模式
-	Envs            map[string]string `json:"envs,omitempty"`
+	LangIn                string            `json:"lang_in"`
+	LangOut               string            `json:"lang_out"`
+	Service               string            `json:"service"`
+	Thread                int               `json:"thread"`
+	Pages                 string            `json:"pages,omitempty"`
+	Output                string            `json:"output"`
+	SkipSubsetFonts       bool              `json:"skip_subset_fonts"`
+	IgnoreCache           bool              `json:"ignore_cache"`
+	Compatible            bool              `json:"compatible"`
+	Prompt                string            `json:"prompt,omitempty"`
+	GenerateMode          string            `json:"generate_mode,omitempty"`           // 新增：生成模式
+	OutputName            string            `json:"output_name,omitempty"`             // 输出文件名（不含扩展名），为空时使用输入文件名
+	OutputFormats         []string          `json:"output_formats,omitempty"`          // 需要生成的输出格式，为空时按生成模式决定
+	HTMLLayout            string            `json:"html_layout,omitempty"`             // 双语 HTML 输出的排版方式：stacked 或 side-by-side
+	VerticalText          bool              `json:"vertical_text,omitempty"`           // 原文为竖排时以竖排输出 CJK 译文
+	MergeStrategy         string            `json:"merge_strategy,omitempty"`          // 合并被过度分割的文本元素时采用的策略
+	MatchMode             string            `json:"match_mode,omitempty"`              // 译文与文本元素的匹配模式：strict 只做精确和标准化匹配，为空时依次尝试所有方式
+	Strategy              string            `json:"strategy,omitempty"`                // 单语PDF的生成方式：overlay（默认）、regenerate 或 auto
+	DiffOverlay           bool              `json:"diff_overlay,omitempty"`            // 单语PDF只遮罩并重绘译文与原文不同的文本，其余保留原页面
+	TextExtractor         string            `json:"text_extractor,omitempty"`          // 文本提取后端：auto、ledongthuc、pdfcpu 或 pdftotext，为空时带坐标解析文本对象
+	OnRegenerationFailure string            `json:"on_regeneration_failure,omitempty"` // PDF重新生成失败时的处理方式：error、text-fallback、html-fallback 或 original-copy
+	ConfidenceThreshold   float64           `json:"confidence_threshold,omitempty"`    // 自评可信度低于此值的译文视为低可信度
+	MarkLowConfidence     bool              `json:"mark_low_confidence,omitempty"`     // 在输出中为低可信度的译文追加标记
+	Envs                  map[string]string `json:"envs,omitempty"`
 }
 
 // PDFMathResult PDFMathTranslate结果
 type PDFMathResult struct {
-	MonoFile string `json:"mono_file"`
-	DualFile string `json:"dual_file"`
-	Success  bool   `json:"success"`
-	Error    string `json:"error,omitempty"`
+	MonoFile  string                 `json:"mono_file"`
+	DualFile  string                 `json:"dual_file"`
+	Artifacts map[string]string      `json:"artifacts,omitempty"` // 输出格式 -> 文件路径
+	Fallbacks []RegenerationFallback `json:"fallbacks,omitempty"` // 重新生成失败后改为生成的替代输出
+	Success   bool                   `json:"success"`
+	Error     string                 `json:"error,omitempty"`
 }
 
 // NewPDFMathTranslator 创建PDF数学翻译器
@@ -70,7 +91,13 @@ func (pmt *PDFMathTranslator) TranslatePDF(inputPath, outputDir string, config P
 		progressCallback(0.1)
 	}
 
-	content, err := pmt.Parser.ParsePDF(inputPath)
+	extractor, err := ParseTextExtractor(config.TextExtractor)
+	if err != nil {
+		return nil, err
+	}
+	pmt.Parser.Extractor = extractor
+
+	content, err := pmt.Source.pdfContent(pmt.Parser, inputPath)
 	if err != nil {
 		// 检查是否是PDF格式问题，提供更友好的错误信息
 		if strings.Contains(err.Error(), "stream not present") || strings.Contains(err.Error(), "PDF文件格式不受支持") {
@@ -94,17 +121,44 @@ func (pmt *PDFMathTranslator) TranslatePDF(inputPath, outputDir string, config P
 		progressCallback(0.3)
 	}
 
+	// 按页翻译时以原文所在页码分组
+	if pmt.Integration != nil && pmt.Integration.Client != nil {
+		pmt.Integration.Client.Pages = TextPages(content)
+	}
+	if pmt.Progress != nil && pmt.Integration != nil && pmt.Integration.Client != nil {
+		client := pmt.Integration.Client
+		pmt.Progress.Start(content, texts, client.Filter)
+		onResult := client.OnResult
+		client.OnResult = func(result TranslateResult) {
+			pmt.Progress.MarkTranslated(result.Original, result.Translated)
+			if onResult != nil {
+				onResult(result)
+			}
+		}
+		defer func() { client.OnResult = onResult }()
+	}
+
 	translations, err := pmt.translateTexts(texts, config)
 	if err != nil {
 		return nil, fmt.Errorf("翻译失败: %w", err)
 	}
+	translations = ReuseRunningTranslations(DetectRunningBlocks(content), translations)
+
+	var confidences map[string]float64
+	if pmt.Integration != nil {
+		confidences = pmt.Integration.Confidences
+	}
+	if config.MarkLowConfidence && len(confidences) > 0 {
+		translations = MarkLowConfidence(translations, confidences, config.ConfidenceThreshold)
+	}
 
 	// 4. 应用翻译结果
 	if progressCallback != nil {
 		progressCallback(0.7)
 	}
 
-	translatedContent := *content // 复制原内容
+	translatedContent := *content // 复制原内容，文本块需单独复制，避免覆盖原文
+	translatedContent.TextBlocks = slices.Clone(content.TextBlocks)
 	pmt.Parser.ApplyTranslations(&translatedContent, translations)
 
 	// 5. 生成输出文件 - 使用文本替换保留样式
@@ -113,24 +167,22 @@ func (pmt *PDFMathTranslator) TranslatePDF(inputPath, outputDir string, config P
 	}
 
 	filename := strings.TrimSuffix(filepath.Base(inputPath), filepath.Ext(inputPath))
+	if config.OutputName != "" {
+		filename = config.OutputName
+	}
 
 	// 构建翻译映射
 	translationMap := make(map[string]string)
-	for _, block := range content.TextBlocks {
+	for i, block := range content.TextBlocks {
 		originalText := strings.TrimSpace(block.Text)
 		if originalText == "" {
 			continue
 		}
 
-		// 查找对应的翻译文本
-		for _, translatedBlock := range translatedContent.TextBlocks {
-			if translatedBlock.PageNum == block.PageNum {
-				translatedText := strings.TrimSpace(translatedBlock.Text)
-				if translatedText != "" {
-					translationMap[originalText] = translatedText
-					break
-				}
-			}
+		// 译文块与原文块一一对应
+		translatedText := strings.TrimSpace(translatedContent.TextBlocks[i].Text)
+		if translatedText != "" {
+			translationMap[originalText] = translatedText
 		}
 	}
 
@@ -142,21 +194,55 @@ func (pmt *PDFMathTranslator) TranslatePDF(inputPath, outputDir string, config P
 			Author: content.Metadata["author"],
 			Pages:  len(content.TextBlocks),
 		},
+		VerticalText:   config.VerticalText,
+		MergeStrategy:  MergeStrategy(config.MergeStrategy),
+		MatchMode:      MatchMode(config.MatchMode),
+		SourceLanguage: config.LangIn,
+		TargetLanguage: config.LangOut,
+		Confidences:    confidences,
+	}
+	if pmt.Integration != nil && pmt.Integration.Client != nil {
+		pdfDoc.Context = pmt.Integration.Client.Context()
+		if err := pdfDoc.Context.Err(); err != nil {
+			return nil, fmt.Errorf("生成输出文件中止: %w", err)
+		}
+	}
+
+	// 指定了输出格式时只生成请求的文件
+	if len(config.OutputFormats) > 0 {
+		artifacts, fallbacks, err := pmt.saveArtifacts(inputPath, outputDir, filename, config, pdfDoc, texts, translations, translationMap)
+		if err != nil {
+			return nil, err
+		}
+		if progressCallback != nil {
+			progressCallback(1.0)
+		}
+		result := &PDFMathResult{
+			MonoFile:  artifacts[string(OutputFormatPDF)],
+			DualFile:  artifacts[string(OutputFormatBilingualPDF)],
+			Artifacts: artifacts,
+			Fallbacks: fallbacks,
+			Success:   true,
+		}
+		log.Printf("PDF翻译完成: %v", artifacts)
+		return result, nil
 	}
 
 	// 根据生成模式决定生成哪些文件
 	var monoFile, dualFile string
+	var fallbacks []RegenerationFallback
+	originalBlocks, translatedBlocks := pdfBlockPairs(texts, translations)
 
 	if config.GenerateMode == "monolingual" {
 		// 单语模式：只生成单语PDF - 使用样式保留替换器 (Overlay技术)
 		monoFile = filepath.Join(outputDir, filename+"-mono.pdf")
-		
-		replacer := NewPDFStylePreservingReplacer()
-		styleConfig := GetDefaultStylePreservingConfig()
-		// 可以根据 config 调整 styleConfig
-		
-		if err := replacer.ReplaceWithStylePreservation(inputPath, monoFile, translationMap, styleConfig); err != nil {
-			return nil, fmt.Errorf("生成单语PDF失败: %w", err)
+
+		if err := pmt.saveMonolingualPDF(inputPath, monoFile, config, pdfDoc, translationMap); err != nil {
+			fallback, err := pmt.regenerationFallback(config, OutputFormatPDF, err, inputPath, outputDir, filename, pdfDoc, originalBlocks, translatedBlocks)
+			if err != nil {
+				return nil, fmt.Errorf("生成单语PDF失败: %w", err)
+			}
+			fallbacks = append(fallbacks, *fallback)
 		}
 		log.Printf("单语模式：生成单语PDF: %s", monoFile)
 	} else {
@@ -165,16 +251,17 @@ func (pmt *PDFMathTranslator) TranslatePDF(inputPath, outputDir string, config P
 		// Replacer 目前主要优化了 Overlay (单语)
 		dualFile = filepath.Join(outputDir, filename+"-dual.pdf")
 		if err := pdfDoc.SaveBilingualPDFWithReplacement(dualFile, translationMap, BilingualLayoutTopBottom); err != nil {
-			return nil, fmt.Errorf("生成双语PDF失败: %w", err)
+			fallback, err := pmt.regenerationFallback(config, OutputFormatBilingualPDF, err, inputPath, outputDir, filename, pdfDoc, originalBlocks, translatedBlocks)
+			if err != nil {
+				return nil, fmt.Errorf("生成双语PDF失败: %w", err)
+			}
+			fallbacks = append(fallbacks, *fallback)
 		}
 
 		// 也生成单语版本作为备选
 		monoFile = filepath.Join(outputDir, filename+"-mono.pdf")
-		
-		replacer := NewPDFStylePreservingReplacer()
-		styleConfig := GetDefaultStylePreservingConfig()
-		
-		if err := replacer.ReplaceWithStylePreservation(inputPath, monoFile, translationMap, styleConfig); err != nil {
+
+		if err := pmt.saveMonolingualPDF(inputPath, monoFile, config, pdfDoc, translationMap); err != nil {
 			log.Printf("警告：生成单语PDF失败: %v", err)
 			// 双语模式下，单语PDF失败不应该导致整个任务失败
 		}
@@ -185,6 +272,18 @@ func (pmt *PDFMathTranslator) TranslatePDF(inputPath, outputDir string, config P
 		progressCallback(1.0)
 	}
 
+	// 重新生成失败时以替代输出作为对应的主输出
+	artifacts := make(map[string]string)
+	for _, fallback := range fallbacks {
+		artifacts[fallback.Artifact] = fallback.Path
+		switch OutputFormat(fallback.Format) {
+		case OutputFormatPDF:
+			monoFile = fallback.Path
+		case OutputFormatBilingualPDF:
+			dualFile = fallback.Path
+		}
+	}
+
 	// 验证生成的文件是否存在
 	if config.GenerateMode == "monolingual" {
 		if _, err := os.Stat(monoFile); os.IsNotExist(err) {
@@ -196,16 +295,74 @@ func (pmt *PDFMathTranslator) TranslatePDF(inputPath, outputDir string, config P
 		}
 	}
 
+	if _, ok := artifacts[string(OutputFormatBilingualPDF)]; !ok && dualFile != "" && filepath.Ext(dualFile) == ".pdf" {
+		artifacts[string(OutputFormatBilingualPDF)] = dualFile
+	}
+	if _, err := os.Stat(monoFile); err == nil && filepath.Ext(monoFile) == ".pdf" {
+		artifacts[string(OutputFormatPDF)] = monoFile
+	}
+
 	result := &PDFMathResult{
-		MonoFile: monoFile,
-		DualFile: dualFile,
-		Success:  true,
+		MonoFile:  monoFile,
+		DualFile:  dualFile,
+		Artifacts: artifacts,
+		Fallbacks: fallbacks,
+		Success:   true,
 	}
 
 	log.Printf("PDF翻译完成: mono=%s, dual=%s", result.MonoFile, result.DualFile)
 	return result, nil
 }
 
+// saveArtifacts 按请求的输出格式逐个生成文件，返回 输出格式 -> 文件路径，以及PDF重新生成失败后生成的替代输出
+func (pmt *PDFMathTranslator) saveArtifacts(inputPath, outputDir, filename string, config PDFMathConfig, pdfDoc *PDFDocument, texts []string, translations, translationMap map[string]string) (map[string]string, []RegenerationFallback, error) {
+	formats, layout := config.OutputFormats, HTMLLayout(config.HTMLLayout)
+	originalBlocks, translatedBlocks := pdfBlockPairs(texts, translations)
+
+	artifacts := make(map[string]string)
+	var fallbacks []RegenerationFallback
+	for _, format := range formats {
+		path := filepath.Join(outputDir, filename+outputFormatSuffix(OutputFormat(format)))
+
+		var err error
+		switch OutputFormat(format) {
+		case OutputFormatPDF:
+			err = pmt.saveMonolingualPDF(inputPath, path, config, pdfDoc, translationMap)
+		case OutputFormatBilingualPDF:
+			err = pdfDoc.SaveBilingualPDFWithReplacement(path, translationMap, BilingualLayoutTopBottom)
+		case OutputFormatText:
+			err = pdfDoc.SaveMonolingualText(path, translatedBlocks)
+		case OutputFormatBilingualText:
+			err = pdfDoc.SaveBilingualText(path, originalBlocks, translatedBlocks)
+		case OutputFormatHTML:
+			err = pdfDoc.SaveMonolingualHTML(path, translatedBlocks)
+		case OutputFormatBilingualHTML:
+			err = pdfDoc.SaveBilingualHTML(path, originalBlocks, translatedBlocks, layout)
+		case OutputFormatAlignment:
+			err = pdfDoc.SaveAlignmentJSON(path, translations)
+		default:
+			err = fmt.Errorf("PDF 不支持输出格式: %s", format)
+		}
+		if err != nil && (OutputFormat(format) == OutputFormatPDF || OutputFormat(format) == OutputFormatBilingualPDF) {
+			fallback, fallbackErr := pmt.regenerationFallback(config, OutputFormat(format), err, inputPath, outputDir, filename, pdfDoc, originalBlocks, translatedBlocks)
+			if fallbackErr == nil {
+				artifacts[fallback.Artifact] = fallback.Path
+				fallbacks = append(fallbacks, *fallback)
+				continue
+			}
+			err = fallbackErr
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("生成 %s 输出失败: %w", format, err)
+		}
+
+		artifacts[format] = path
+		log.Printf("已生成 %s 输出: %s", format, path)
+	}
+
+	return artifacts, fallbacks, nil
+}
+
 // setupFont 设置字体路径 - 保留用于兼容性，现在使用样式保留替换器自动处理字体
 func (pmt *PDFMathTranslator) setupFont(langOut string) {
 	// 使用系统字体检测器
@@ -291,6 +448,9 @@ func (pmt *PDFMathTranslator) MapProviderToService(provider string) string {
 		"custom":         "custom",
 		"nltranslator":   "openai", // 回退到openai
 		"libretranslate": "openai", // 回退到openai
+		"azure-openai":   "openai",
+		"yandex":         "openai", // 回退到openai
+		"tencent":        "openai", // 回退到openai
 	}
 
 	if service, ok := mapping[provider]; ok {
@@ -0,0 +1,265 @@
+package translator
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"log"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// PPTXFile 表示一个 PowerPoint 演示文稿
+type PPTXFile struct {
+	Path  string
+	Files map[string][]byte
+	order []string // 压缩包中文件的原始顺序，保存时保持不变
+}
+
+var (
+	// pptxSlidePattern 幻灯片文件路径
+	pptxSlidePattern = regexp.MustCompile(`^ppt/slides/slide(\d+)\.xml$`)
+	// pptxParagraphPattern DrawingML 段落（文本框、占位符和表格单元格中的 <a:p>）
+	pptxParagraphPattern = regexp.MustCompile(`(?s)<a:p(?:\s[^>]*)?>.*?</a:p>`)
+	// pptxRunTextPattern 文本运行中的 <a:t>
+	pptxRunTextPattern = regexp.MustCompile(`(?s)<a:t(?:\s[^>]*)?>(.*?)</a:t>`)
+	// pptxShapePropsPattern 形状、图片等对象的非可视属性 <p:cNvPr>，其中的 descr（替代文本）和 title 需要翻译
+	pptxShapePropsPattern = regexp.MustCompile(`<p:cNvPr\s[^>]*>`)
+	// pptxAltTextAttrPattern <p:cNvPr> 中的 descr 和 title 属性
+	pptxAltTextAttrPattern = regexp.MustCompile(`(\s(?:descr|title)=")([^"]*)(")`)
+)
+
+// pptxOverflowRatio 译文显示宽度超过原文的倍数时认为文本框可能溢出
+const pptxOverflowRatio = 1.3
+
+// OpenPPTX 打开 PPTX 文件
+func OpenPPTX(path string) (*PPTXFile, error) {
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, fmt.Errorf("打开 PPTX 文件失败: %w", err)
+	}
+	defer r.Close()
+
+	pptx := &PPTXFile{
+		Path:  path,
+		Files: make(map[string][]byte),
+	}
+
+	for _, f := range r.File {
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		pptx.Files[f.Name] = content
+		pptx.order = append(pptx.order, f.Name)
+	}
+
+	if len(pptx.GetSlideFiles()) == 0 {
+		return nil, fmt.Errorf("未找到幻灯片")
+	}
+
+	return pptx, nil
+}
+
+// GetSlideFiles 按幻灯片编号顺序返回幻灯片文件
+func (p *PPTXFile) GetSlideFiles() []string {
+	var slides []string
+	for name := range p.Files {
+		if pptxSlidePattern.MatchString(name) {
+			slides = append(slides, name)
+		}
+	}
+
+	sort.Slice(slides, func(i, j int) bool {
+		return pptxSlideNumber(slides[i]) < pptxSlideNumber(slides[j])
+	})
+	return slides
+}
+
+// pptxSlideNumber 从幻灯片文件路径中解析编号
+func pptxSlideNumber(name string) int {
+	m := pptxSlidePattern.FindStringSubmatch(name)
+	if m == nil {
+		return 0
+	}
+	n, _ := strconv.Atoi(m[1])
+	return n
+}
+
+// GetTextBlocks 获取文本块（实现 Document 接口）
+// 每个段落的所有文本运行合并为一个文本块
+func (p *PPTXFile) GetTextBlocks() []string {
+	var blocks []string
+	for _, slide := range p.GetSlideFiles() {
+		for _, paragraph := range pptxParagraphPattern.FindAllString(string(p.Files[slide]), -1) {
+			if text := pptxParagraphText(paragraph); strings.TrimSpace(text) != "" {
+				blocks = append(blocks, text)
+			}
+		}
+	}
+	return blocks
+}
+
+// GetAttributeTextBlocks 获取图片、形状等对象的替代文本（descr）和标题（title）
+func (p *PPTXFile) GetAttributeTextBlocks() []string {
+	var blocks []string
+	for _, slide := range p.GetSlideFiles() {
+		for _, props := range pptxShapePropsPattern.FindAllString(string(p.Files[slide]), -1) {
+			for _, m := range pptxAltTextAttrPattern.FindAllStringSubmatch(props, -1) {
+				if value := strings.TrimSpace(html.UnescapeString(m[2])); shouldExtractText(value) {
+					blocks = append(blocks, value)
+				}
+			}
+		}
+	}
+	return blocks
+}
+
+// InsertTranslation 插入双语翻译（实现 Document 接口）
+// 译文作为新段落插入到原文段落之后，沿用原段落的格式
+func (p *PPTXFile) InsertTranslation(translations map[string]string) error {
+	return p.replaceParagraphs(translations, true)
+}
+
+// InsertMonolingualTranslation 插入单语翻译（实现 Document 接口）
+func (p *PPTXFile) InsertMonolingualTranslation(translations map[string]string) error {
+	return p.replaceParagraphs(translations, false)
+}
+
+// replaceParagraphs 在所有幻灯片中写入译文，文本框尺寸不变，可能溢出的段落记录日志
+// 在副本上修改，全部成功后才写回
+func (p *PPTXFile) replaceParagraphs(translations map[string]string, bilingual bool) error {
+	return rewriteFiles(p.Files, p.GetSlideFiles(), func(slide string, content []byte) ([]byte, error) {
+		overflow := 0
+		replaced := pptxParagraphPattern.ReplaceAllStringFunc(string(content), func(paragraph string) string {
+			original := pptxParagraphText(paragraph)
+			translated, ok := translations[original]
+			if !ok || strings.TrimSpace(original) == "" || translated == original {
+				return paragraph
+			}
+			if displayWidth(translated) > displayWidth(original)*pptxOverflowRatio {
+				overflow++
+			}
+
+			result := pptxSetParagraphText(paragraph, translated)
+			if bilingual {
+				return paragraph + result
+			}
+			return result
+		})
+
+		if overflow > 0 {
+			log.Printf("警告：幻灯片 %d 中有 %d 段译文明显长于原文，文本框可能溢出", pptxSlideNumber(slide), overflow)
+		}
+		return []byte(pptxReplaceAltText(replaced, translations, bilingual)), nil
+	})
+}
+
+// pptxReplaceAltText 将 <p:cNvPr> 中 descr 和 title 属性的值替换为译文，双语模式为"原文 / 译文"
+// 只改写这两个属性，id、name 等其余属性保持不变
+func pptxReplaceAltText(content string, translations map[string]string, bilingual bool) string {
+	return pptxShapePropsPattern.ReplaceAllStringFunc(content, func(props string) string {
+		return pptxAltTextAttrPattern.ReplaceAllStringFunc(props, func(attr string) string {
+			m := pptxAltTextAttrPattern.FindStringSubmatch(attr)
+			original := strings.TrimSpace(html.UnescapeString(m[2]))
+			translated, ok := translations[original]
+			if !ok || translated == "" || translated == original {
+				return attr
+			}
+			if bilingual {
+				translated = original + " / " + translated
+			}
+
+			var escaped strings.Builder
+			xml.EscapeText(&escaped, []byte(translated))
+			return m[1] + escaped.String() + m[3]
+		})
+	})
+}
+
+// Snapshot 创建当前文件内容的快照，可通过 Restore 回滚
+func (p *PPTXFile) Snapshot() DocumentSnapshot {
+	return snapshotFiles(p.Files)
+}
+
+// Restore 回滚到快照时的文件内容
+func (p *PPTXFile) Restore(snapshot DocumentSnapshot) {
+	p.Files = snapshotFiles(snapshot)
+}
+
+// pptxParagraphText 合并段落中所有文本运行的文本
+func pptxParagraphText(paragraph string) string {
+	var text strings.Builder
+	for _, m := range pptxRunTextPattern.FindAllStringSubmatch(paragraph, -1) {
+		text.WriteString(html.UnescapeString(m[1]))
+	}
+	return text.String()
+}
+
+// pptxSetParagraphText 将译文写入段落的第一个文本运行，清空其余文本运行，保留运行格式
+func pptxSetParagraphText(paragraph, text string) string {
+	first := true
+	return pptxRunTextPattern.ReplaceAllStringFunc(paragraph, func(run string) string {
+		openTag := run[:strings.Index(run, ">")+1]
+		if !first {
+			return openTag + "</a:t>"
+		}
+		first = false
+
+		var escaped strings.Builder
+		xml.EscapeText(&escaped, []byte(text))
+		return openTag + escaped.String() + "</a:t>"
+	})
+}
+
+// displayWidth 估算文本显示宽度，全角字符按两个字符计算
+func displayWidth(text string) float64 {
+	width := 0.0
+	for _, r := range text {
+		if unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hangul, r) || unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r) {
+			width += 2
+		} else {
+			width++
+		}
+	}
+	return width
+}
+
+// Save 保存文档（实现 Document 接口）
+// 按原始顺序写入（[Content_Types].xml 需要位于开头），先写入临时文件再重命名覆盖
+func (p *PPTXFile) Save(outputPath string) error {
+	return writeZipAtomic(outputPath, fileOrder(p.order, p.Files), p.Files)
+}
+
+// ValidatePPTX 验证是否为有效的 PPTX 文件
+func ValidatePPTX(filePath string) error {
+	if strings.ToLower(filepath.Ext(filePath)) != ".pptx" {
+		return fmt.Errorf("文件必须是 PPTX 格式")
+	}
+
+	r, err := zip.OpenReader(filePath)
+	if err != nil {
+		return fmt.Errorf("无效的 PPTX 文件: %w", err)
+	}
+	defer r.Close()
+
+	for _, f := range r.File {
+		if f.Name == "ppt/presentation.xml" {
+			return nil
+		}
+	}
+	return fmt.Errorf("无效的 PPTX 文件: 缺少 ppt/presentation.xml")
+}
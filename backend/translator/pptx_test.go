@@ -0,0 +1,95 @@
+package translator
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPPTXRoundTrip(t *testing.T) {
+	input := writeTestPPTX(t, t.TempDir(), [][]string{
+		{"Quarterly results", "Revenue grew by |twelve percent"},
+		{"Next steps & plans"},
+	})
+	doc, err := OpenPPTX(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(doc.GetTextBlocks(), "|"); got != "Quarterly results|Revenue grew by twelve percent|Next steps & plans" {
+		t.Fatalf("GetTextBlocks = %q", got)
+	}
+
+	client, _ := newStubClient(t)
+	dt := &DocumentTranslator{Client: client, PDFMathTranslator: NewPDFMathTranslator()}
+	output, err := dt.TranslateDocument(input, filepath.Join(t.TempDir(), "out.pptx"), "French", "", true, "monolingual", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	translated, err := OpenPPTX(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string][]string{
+		"ppt/slides/slide1.xml": {"[French] Quarterly results", "[French] Revenue grew by twelve percent"},
+		"ppt/slides/slide2.xml": {"[French] Next steps &amp; plans"},
+	}
+	for slide, texts := range want {
+		content := string(translated.Files[slide])
+		for _, text := range texts {
+			if !strings.Contains(content, "<a:t>"+text+"</a:t>") {
+				t.Errorf("%s 缺少译文 %q:\n%s", slide, text, content)
+			}
+		}
+		// 运行格式保留，多余的文本运行被清空
+		if !strings.Contains(content, `<a:rPr lang="en-US" sz="2400"/>`) || strings.Contains(content, "<a:t>twelve percent</a:t>") {
+			t.Errorf("%s 的文本运行格式未保留:\n%s", slide, content)
+		}
+	}
+	if _, ok := translated.Files["ppt/presentation.xml"]; !ok {
+		t.Error("输出缺少 ppt/presentation.xml")
+	}
+}
+
+func TestPPTXAltTextTranslated(t *testing.T) {
+	dir := t.TempDir()
+	doc, err := OpenPPTX(writeTestPPTX(t, dir, [][]string{{"Quarterly results"}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	const picture = `<p:pic><p:nvPicPr><p:cNvPr id="4" name="Picture 3" descr="Chart of revenue &amp; costs" title="Revenue chart"/>` +
+		`<p:cNvPicPr/></p:nvPicPr><p:blipFill><a:blip r:embed="rId2"/></p:blipFill></p:pic>`
+	slide := "ppt/slides/slide1.xml"
+	doc.Files[slide] = []byte(strings.Replace(string(doc.Files[slide]), "</p:spTree>", picture+"</p:spTree>", 1))
+	input := filepath.Join(dir, "picture.pptx")
+	if err := doc.Save(input); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := strings.Join(doc.GetAttributeTextBlocks(), "|"); got != "Chart of revenue & costs|Revenue chart" {
+		t.Fatalf("GetAttributeTextBlocks = %q", got)
+	}
+
+	client, _ := newStubClient(t)
+	dt := &DocumentTranslator{Client: client, PDFMathTranslator: NewPDFMathTranslator()}
+	for mode, want := range map[string]string{
+		"monolingual": `descr="[French] Chart of revenue &amp; costs" title="[French] Revenue chart"`,
+		"bilingual":   `descr="Chart of revenue &amp; costs / [French] Chart of revenue &amp; costs" title="Revenue chart / [French] Revenue chart"`,
+	} {
+		output, err := dt.TranslateDocument(input, filepath.Join(dir, mode+".pptx"), "French", "", true, mode, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		translated, err := OpenPPTX(output)
+		if err != nil {
+			t.Fatal(err)
+		}
+		content := string(translated.Files[slide])
+		if !strings.Contains(content, `<p:cNvPr id="4" name="Picture 3" `+want+`/>`) {
+			t.Errorf("%s: 替代文本未翻译或其余属性被改动:\n%s", mode, content)
+		}
+		if !strings.Contains(content, `<a:blip r:embed="rId2"/>`) {
+			t.Errorf("%s: 图片关系 ID 被改动:\n%s", mode, content)
+		}
+	}
+}
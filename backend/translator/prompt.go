@@ -0,0 +1,142 @@
+package translator
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"text/template"
+)
+
+// PromptTemplateKey ProviderConfig.Extra 中保存提示词模板的键
+const PromptTemplateKey = "promptTemplate"
+
+// 语气（正式程度）选项
+const (
+	FormalityFormal   = "formal"   // 正式
+	FormalityInformal = "informal" // 非正式
+	FormalityNeutral  = "neutral"  // 中性（默认）
+)
+
+// ValidateFormality 校验语气选项，空值视为中性
+func ValidateFormality(formality string) error {
+	switch formality {
+	case "", FormalityFormal, FormalityInformal, FormalityNeutral:
+		return nil
+	default:
+		return fmt.Errorf("不支持的语气选项: %s，可选: formal、informal、neutral", formality)
+	}
+}
+
+// formalityInstruction 返回语气对应的提示词，中性时返回空字符串
+func formalityInstruction(formality string) string {
+	switch formality {
+	case FormalityFormal:
+		return "Use a formal register and polite forms of address."
+	case FormalityInformal:
+		return "Use an informal, conversational register and familiar forms of address."
+	default:
+		return ""
+	}
+}
+
+// PromptData 提示词模板可用的占位数据
+type PromptData struct {
+	TargetLanguage string // 目标语言
+	Domain         string // 领域（来自 Extra["domain"]）
+	Glossary       string // 术语表（来自 Extra["glossary"]）
+	Formality      string // 语气：formal、informal、neutral
+	Text           string // 待翻译文本
+}
+
+// ParsePromptTemplate 解析提示词模板，模板语法错误时返回错误
+func ParsePromptTemplate(tmpl string) (*template.Template, error) {
+	t, err := template.New("prompt").Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return nil, fmt.Errorf("提示词模板格式错误: %w", err)
+	}
+
+	// 使用示例数据试渲染，提前发现引用不存在字段等错误
+	if _, err := renderPromptTemplate(t, PromptData{}); err != nil {
+		return nil, fmt.Errorf("提示词模板格式错误: %w", err)
+	}
+	return t, nil
+}
+
+// renderPromptTemplate 渲染提示词模板
+func renderPromptTemplate(t *template.Template, data PromptData) (string, error) {
+	var sb strings.Builder
+	if err := t.Execute(&sb, data); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// defaultSystemPrompt 默认系统提示词
+func defaultSystemPrompt(targetLanguage string) string {
+	return fmt.Sprintf("You are a professional translator. Translate the following text to %s. Keep the original meaning and style. Keep placeholders such as {v0} unchanged. Only return the translated text without any explanations.", targetLanguage)
+}
+
+// buildSystemPrompt 构建系统提示词
+// 配置了提示词模板时使用模板渲染，否则使用默认提示词；用户提示词追加在末尾
+func (b *BaseProvider) buildSystemPrompt(text, targetLanguage, userPrompt string) string {
+	systemPrompt := defaultSystemPrompt(targetLanguage)
+	if instruction := formalityInstruction(b.Config.Formality); instruction != "" {
+		systemPrompt += " " + instruction
+	}
+
+	if b.promptTemplate != nil {
+		data := PromptData{
+			TargetLanguage: targetLanguage,
+			Formality:      b.Config.Formality,
+			Text:           text,
+		}
+		if b.Config.Extra != nil {
+			data.Domain = b.Config.Extra["domain"]
+			data.Glossary = b.Config.Extra["glossary"]
+		}
+
+		rendered, err := renderPromptTemplate(b.promptTemplate, data)
+		if err != nil {
+			log.Printf("警告：渲染提示词模板失败，使用默认提示词: %v", err)
+		} else {
+			systemPrompt = rendered
+		}
+	} else if glossary := b.glossary(); glossary != "" {
+		// 未使用模板时将术语表附加到默认提示词（使用模板时由模板通过 {{.Glossary}} 引用）
+		systemPrompt += " Use the following glossary for terminology (source => translation):\n" + glossary + "\n"
+	}
+
+	if userPrompt != "" {
+		systemPrompt += " " + userPrompt
+	}
+	// 回复格式的要求放在最后，不被用户提示词覆盖
+	if b.Config.SelfRateConfidence {
+		systemPrompt += " " + confidenceInstruction
+	}
+	return systemPrompt
+}
+
+// glossary 返回配置的术语表（Extra["glossary"]）
+func (b *BaseProvider) glossary() string {
+	if b.Config.Extra == nil {
+		return ""
+	}
+	return strings.TrimSpace(b.Config.Extra["glossary"])
+}
+
+// translateOptions 返回参与缓存键计算的翻译选项
+func (b *BaseProvider) translateOptions(userPrompt string) TranslateOptions {
+	opts := TranslateOptions{
+		UserPrompt:         userPrompt,
+		Formality:          b.Config.Formality,
+		SelfRateConfidence: b.Config.SelfRateConfidence,
+	}
+	if b.Config.Extra != nil && b.Config.Extra[PromptTemplateKey] != "" {
+		opts.PromptTemplate = b.Config.Extra[PromptTemplateKey]
+		opts.Domain = b.Config.Extra["domain"]
+		opts.Glossary = b.Config.Extra["glossary"]
+	} else {
+		opts.Glossary = b.glossary()
+	}
+	return opts
+}
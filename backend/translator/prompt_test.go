@@ -0,0 +1,87 @@
+package translator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCustomPromptTemplate(t *testing.T) {
+	stub := newOpenAIStub(t, nil)
+	config := stub.Config()
+	config.Extra = map[string]string{
+		PromptTemplateKey: "Translate this {{.Domain}} text into {{.TargetLanguage}}. Terms: {{.Glossary}}",
+		"domain":          "legal",
+		"glossary":        "plaintiff => 原告",
+	}
+	provider, err := NewProvider(config, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := provider.Translate("The plaintiff appealed.", "Uni", "Be concise."); err != nil {
+		t.Fatal(err)
+	}
+
+	requests := stub.Requests()
+	if len(requests) != 1 {
+		t.Fatalf("翻译服务收到 %d 次请求", len(requests))
+	}
+	want := "Translate this legal text into Uni. Terms: plaintiff => 原告 Be concise."
+	if requests[0].System != want {
+		t.Errorf("系统提示词 = %q\n期望 %q", requests[0].System, want)
+	}
+}
+
+func TestInvalidPromptTemplateRejected(t *testing.T) {
+	for _, tmpl := range []string{"{{.TargetLanguage", "{{.Unknown}}"} {
+		config := ProviderConfig{Type: ProviderOpenAI, APIURL: "http://127.0.0.1", Extra: map[string]string{PromptTemplateKey: tmpl}}
+		if _, err := NewProvider(config, nil); err == nil {
+			t.Errorf("模板 %q 应在创建提供商时被拒绝", tmpl)
+		}
+	}
+}
+
+func TestFormalityChangesPromptAndCacheKey(t *testing.T) {
+	stub := newOpenAIStub(t, nil)
+	cache := NewMemoryCache()
+	prompts := make(map[string]string)
+	for _, formality := range []string{"", FormalityFormal} {
+		config := stub.Config()
+		config.Formality = formality
+		provider, err := NewProvider(config, cache)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := provider.Translate("How are you?", "German", ""); err != nil {
+			t.Fatal(err)
+		}
+		requests := stub.Requests()
+		prompts[formality] = requests[len(requests)-1].System
+	}
+
+	if !strings.Contains(prompts[FormalityFormal], formalityInstruction(FormalityFormal)) {
+		t.Errorf("formal 提示词缺少语气要求: %q", prompts[FormalityFormal])
+	}
+	if strings.Contains(prompts[""], formalityInstruction(FormalityFormal)) {
+		t.Errorf("默认提示词不应包含语气要求: %q", prompts[""])
+	}
+	// 缓存键不同，formal 不会命中默认语气的缓存
+	if n := len(stub.Requests()); n != 2 {
+		t.Errorf("翻译服务收到 %d 次请求，期望 2", n)
+	}
+	neutral := CacheKeyWithOptions("How are you?", "German", TranslateOptions{})
+	formal := CacheKeyWithOptions("How are you?", "German", TranslateOptions{Formality: FormalityFormal})
+	if neutral == formal {
+		t.Error("语气不同时缓存键应不同")
+	}
+}
+
+func TestValidateFormality(t *testing.T) {
+	for _, formality := range []string{"", FormalityFormal, FormalityInformal, FormalityNeutral} {
+		if err := ValidateFormality(formality); err != nil {
+			t.Errorf("ValidateFormality(%q): %v", formality, err)
+		}
+	}
+	if err := ValidateFormality("casual"); err == nil {
+		t.Error("不支持的语气应返回错误")
+	}
+}
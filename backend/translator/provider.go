@@ -2,10 +2,14 @@ package translator
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strings"
+	"text/template"
 	"time"
 )
 
@@ -21,15 +25,35 @@ const (
 	ProviderDeepSeek       ProviderType = "deepseek"
 	ProviderNLTranslate    ProviderType = "nltranslator"   // macOS NaturalLanguage 翻译
 	ProviderLibreTranslate ProviderType = "libretranslate" // LibreTranslate 翻译
+	ProviderAzureOpenAI    ProviderType = "azure-openai"   // Azure OpenAI 服务
+	ProviderYandex         ProviderType = "yandex"         // Yandex Translate
+	ProviderTencent        ProviderType = "tencent"        // 腾讯云机器翻译
 )
 
+// Azure OpenAI 默认 API 版本
+const defaultAzureAPIVersion = "2024-02-01"
+
 // Provider AI 提供商接口
 type Provider interface {
 	Translate(text, targetLanguage, userPrompt string) (string, error)
 	GetName() string
 	GetConfig() ProviderConfig
+	HealthCheck() error
+	// SupportedLanguages 返回支持的语言代码，用于在创建任务前校验语言
+	SupportedLanguages() ([]string, error)
+}
+
+// BatchProvider 支持在一次请求中翻译多个文本的提供商
+type BatchProvider interface {
+	TranslateBatch(texts []string, targetLanguage, userPrompt string) ([]string, error)
 }
 
+// 健康检查使用的最小翻译请求
+const (
+	healthCheckText     = "hello"
+	healthCheckLanguage = "Uni"
+)
+
 // ProviderConfig 提供商配置
 type ProviderConfig struct {
 	Type        ProviderType      `json:"type"`
@@ -38,14 +62,42 @@ type ProviderConfig struct {
 	Model       string            `json:"model"`
 	Temperature float64           `json:"temperature"`
 	MaxTokens   int               `json:"maxTokens"`
-	Extra       map[string]string `json:"extra,omitempty"` // 额外参数
+	Extra       map[string]string `json:"extra,omitempty"`     // 额外参数
+	Formality   string            `json:"formality,omitempty"` // 语气：formal、informal、neutral
+
+	SelfRateConfidence bool `json:"selfRateConfidence,omitempty"` // 要求大模型以 JSON 返回译文及自评可信度（0~1）
+
+	CACert             string `json:"caCert,omitempty"`             // 额外信任的 CA 证书（PEM 格式的证书内容），用于自签名证书的自建服务
+	InsecureSkipVerify bool   `json:"insecureSkipVerify,omitempty"` // 跳过 TLS 证书校验（不安全，仅用于测试），需服务端允许（见 AllowInsecureTLS）
+
+	MaxIdleConnsPerHost int `json:"maxIdleConnsPerHost,omitempty"` // 每个主机保留的空闲连接数，为 0 时使用 DefaultMaxIdleConnsPerHost
+	MaxConnsPerHost     int `json:"maxConnsPerHost,omitempty"`     // 每个主机的最大连接数（含使用中的连接），为 0 时不限制
+	IdleConnTimeout     int `json:"idleConnTimeout,omitempty"`     // 空闲连接的保留时间（秒），为 0 时使用 DefaultIdleConnTimeout
 }
 
 // BaseProvider 基础提供商实现
 type BaseProvider struct {
 	Config     ProviderConfig
 	HTTPClient *http.Client
-	Cache      *Cache
+	Cache      CacheStore
+	Usage      *UsageStats // 累计的 token 用量，为空时不统计
+
+	promptTemplate *template.Template // 自定义系统提示词模板
+	ctx            context.Context    // 请求使用的上下文，取消或超时后中止进行中的请求
+}
+
+// SetContext 设置请求使用的上下文（实现 ContextSetter 接口）
+func (b *BaseProvider) SetContext(ctx context.Context) {
+	b.ctx = ctx
+}
+
+// newRequest 创建绑定上下文的 HTTP 请求
+func (b *BaseProvider) newRequest(method, url string, body io.Reader) (*http.Request, error) {
+	ctx := b.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return http.NewRequestWithContext(ctx, method, url, body)
 }
 
 // GetConfig 获取提供商配置
@@ -54,17 +106,34 @@ func (b *BaseProvider) GetConfig() ProviderConfig {
 }
 
 // NewProvider 创建提供商实例
-func NewProvider(config ProviderConfig, cache *Cache) (Provider, error) {
+func NewProvider(config ProviderConfig, cache CacheStore) (Provider, error) {
+	httpClient, err := newHTTPClient(config)
+	if err != nil {
+		return nil, err
+	}
+
 	base := &BaseProvider{
-		Config: config,
-		HTTPClient: &http.Client{
-			Timeout: 60 * time.Second,
-		},
-		Cache: cache,
+		Config:     config,
+		HTTPClient: httpClient,
+		Cache:      cache,
+		Usage:      &UsageStats{provider: config.Type},
+	}
+
+	// 在配置阶段校验提示词模板
+	if config.Extra != nil && config.Extra[PromptTemplateKey] != "" {
+		tmpl, err := ParsePromptTemplate(config.Extra[PromptTemplateKey])
+		if err != nil {
+			return nil, err
+		}
+		base.promptTemplate = tmpl
+	}
+
+	if err := ValidateFormality(config.Formality); err != nil {
+		return nil, err
 	}
 
 	switch config.Type {
-	case ProviderOpenAI, ProviderDeepSeek:
+	case ProviderOpenAI, ProviderDeepSeek, ProviderAzureOpenAI:
 		return &OpenAIProvider{BaseProvider: base}, nil
 	case ProviderClaude:
 		return &ClaudeProvider{BaseProvider: base}, nil
@@ -76,6 +145,10 @@ func NewProvider(config ProviderConfig, cache *Cache) (Provider, error) {
 		return &NLTranslateProvider{BaseProvider: base}, nil
 	case ProviderLibreTranslate:
 		return &LibreTranslateProvider{BaseProvider: base}, nil
+	case ProviderYandex:
+		return &YandexProvider{BaseProvider: base}, nil
+	case ProviderTencent:
+		return &TencentProvider{BaseProvider: base}, nil
 	case ProviderCustom:
 		return &CustomProvider{BaseProvider: base}, nil
 	default:
@@ -83,13 +156,26 @@ func NewProvider(config ProviderConfig, cache *Cache) (Provider, error) {
 	}
 }
 
+// ProviderHTTPError 翻译服务返回了非 200 的 HTTP 状态码
+type ProviderHTTPError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *ProviderHTTPError) Error() string {
+	return fmt.Sprintf("API 返回错误 (状态码 %d): %s", e.StatusCode, e.Body)
+}
+
 // doRequest 执行 HTTP 请求
 func (b *BaseProvider) doRequest(req *http.Request) ([]byte, error) {
+	start := time.Now()
 	resp, err := b.HTTPClient.Do(req)
 	if err != nil {
+		recordProviderRequest(b.Config.Type, 0, start)
 		return nil, fmt.Errorf("API 请求失败: %w", err)
 	}
 	defer resp.Body.Close()
+	defer func() { recordProviderRequest(b.Config.Type, resp.StatusCode, start) }()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
@@ -97,31 +183,74 @@ func (b *BaseProvider) doRequest(req *http.Request) ([]byte, error) {
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API 返回错误 (状态码 %d): %s", resp.StatusCode, string(body))
+		return nil, &ProviderHTTPError{StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	return body, nil
 }
 
+// withoutCache 返回不使用缓存的副本（用于健康检查，避免命中缓存）
+func (b *BaseProvider) withoutCache() *BaseProvider {
+	return &BaseProvider{
+		Config:         b.Config,
+		HTTPClient:     b.HTTPClient,
+		promptTemplate: b.promptTemplate,
+		ctx:            b.ctx,
+	}
+}
+
+// checkTranslation 通过一次最小翻译请求检查提供商是否可用
+func checkTranslation(p Provider) error {
+	result, err := p.Translate(healthCheckText, healthCheckLanguage, "")
+	if err != nil {
+		return fmt.Errorf("%s 连接测试失败: %w", p.GetName(), err)
+	}
+	if result == "" {
+		return fmt.Errorf("%s 连接测试失败: 返回了空的翻译结果", p.GetName())
+	}
+	return nil
+}
+
 // checkCache 检查缓存
 func (b *BaseProvider) checkCache(text, targetLanguage, userPrompt string) (string, bool) {
 	if b.Cache != nil {
-		cacheKey := CacheKey(text, targetLanguage, userPrompt)
-		if cached, ok := b.Cache.Get(cacheKey); ok {
+		cacheKey := CacheKeyWithOptions(text, targetLanguage, b.translateOptions(userPrompt))
+		cached, ok := b.Cache.Get(cacheKey)
+		recordCacheLookup(ok)
+		if ok {
 			return cached, true
 		}
 	}
 	return "", false
 }
 
-// saveCache 保存到缓存
+// saveCache 保存到缓存，拒绝翻译的回复和无效译文（空白、长度异常偏短）不会被缓存
 func (b *BaseProvider) saveCache(text, targetLanguage, userPrompt, result string) {
-	if b.Cache != nil {
-		cacheKey := CacheKey(text, targetLanguage, userPrompt)
+	if b.Cache != nil && !isRefusalFor(text, result) && validateTranslation(text, result, targetLanguage) == nil {
+		cacheKey := CacheKeyWithOptions(text, targetLanguage, b.translateOptions(userPrompt))
 		b.Cache.Set(cacheKey, result)
 	}
 }
 
+// checkBatchCache 批量查询缓存，返回已有结果和需要请求翻译的文本下标
+// 空白文本原样返回，不发送给翻译服务
+func (b *BaseProvider) checkBatchCache(texts []string, targetLanguage, userPrompt string) ([]string, []int) {
+	results := make([]string, len(texts))
+	var pending []int
+	for i, text := range texts {
+		if strings.TrimSpace(text) == "" {
+			results[i] = text
+			continue
+		}
+		if cached, ok := b.checkCache(text, targetLanguage, userPrompt); ok {
+			results[i] = cached
+			continue
+		}
+		pending = append(pending, i)
+	}
+	return results, pending
+}
+
 // OpenAIProvider OpenAI 兼容的提供商（包括 OpenAI、DeepSeek 等）
 type OpenAIProvider struct {
 	*BaseProvider
@@ -131,43 +260,22 @@ func (p *OpenAIProvider) GetName() string {
 	return string(p.Config.Type)
 }
 
+// HealthCheck 检查提供商连通性
+func (p *OpenAIProvider) HealthCheck() error {
+	return checkTranslation(&OpenAIProvider{BaseProvider: p.withoutCache()})
+}
+
 func (p *OpenAIProvider) Translate(text, targetLanguage, userPrompt string) (string, error) {
 	// 检查缓存
 	if cached, ok := p.checkCache(text, targetLanguage, userPrompt); ok {
 		return cached, nil
 	}
 
-	systemPrompt := fmt.Sprintf("You are a professional translator. Translate the following text to %s. Keep the original meaning and style. Only return the translated text without any explanations.", targetLanguage)
-	if userPrompt != "" {
-		systemPrompt += " " + userPrompt
-	}
-
-	reqBody := map[string]interface{}{
-		"model":       p.Config.Model,
-		"temperature": p.Config.Temperature,
-		"messages": []map[string]string{
-			{"role": "system", "content": systemPrompt},
-			{"role": "user", "content": text},
-		},
-	}
-
-	if p.Config.MaxTokens > 0 {
-		reqBody["max_tokens"] = p.Config.MaxTokens
-	}
-
-	jsonData, err := json.Marshal(reqBody)
-	if err != nil {
-		return "", err
-	}
-
-	req, err := http.NewRequest("POST", p.Config.APIURL, bytes.NewBuffer(jsonData))
+	req, err := p.newTranslateRequest(text, targetLanguage, userPrompt, false)
 	if err != nil {
 		return "", err
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+p.Config.APIKey)
-
 	body, err := p.doRequest(req)
 	if err != nil {
 		return "", err
@@ -179,6 +287,7 @@ func (p *OpenAIProvider) Translate(text, targetLanguage, userPrompt string) (str
 				Content string `json:"content"`
 			} `json:"message"`
 		} `json:"choices"`
+		Usage *openAIUsage `json:"usage,omitempty"`
 		Error *struct {
 			Message string `json:"message"`
 		} `json:"error,omitempty"`
@@ -196,11 +305,97 @@ func (p *OpenAIProvider) Translate(text, targetLanguage, userPrompt string) (str
 		return "", fmt.Errorf("API 未返回翻译结果")
 	}
 
+	if resp.Usage != nil {
+		p.Usage.Add(resp.Usage.PromptTokens, resp.Usage.CompletionTokens)
+	}
+
 	result := resp.Choices[0].Message.Content
 	p.saveCache(text, targetLanguage, userPrompt, result)
 	return result, nil
 }
 
+// newTranslateRequest 构建翻译请求，stream 为 true 时请求流式返回并附带用量
+func (p *OpenAIProvider) newTranslateRequest(text, targetLanguage, userPrompt string, stream bool) (*http.Request, error) {
+	systemPrompt := p.buildSystemPrompt(text, targetLanguage, userPrompt)
+
+	reqBody := map[string]interface{}{
+		"model":       p.Config.Model,
+		"temperature": p.Config.Temperature,
+		"messages": []map[string]string{
+			{"role": "system", "content": systemPrompt},
+			{"role": "user", "content": text},
+		},
+	}
+
+	if p.Config.MaxTokens > 0 {
+		reqBody["max_tokens"] = p.Config.MaxTokens
+	}
+	if stream {
+		reqBody["stream"] = true
+		reqBody["stream_options"] = map[string]bool{"include_usage": true}
+	}
+
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.newChatRequest(jsonData)
+}
+
+// isAzure 是否使用 Azure OpenAI 的接口格式
+func (p *OpenAIProvider) isAzure() bool {
+	return p.Config.Type == ProviderAzureOpenAI || (p.Config.Extra != nil && p.Config.Extra["azure"] == "true")
+}
+
+// requestURL 构建请求地址
+// Azure 模式下为 {endpoint}/openai/deployments/{deployment}/chat/completions?api-version=...
+func (p *OpenAIProvider) requestURL() string {
+	if !p.isAzure() {
+		return p.Config.APIURL
+	}
+
+	deployment := p.Config.Model
+	apiVersion := defaultAzureAPIVersion
+	if p.Config.Extra != nil {
+		if d := p.Config.Extra["deployment"]; d != "" {
+			deployment = d
+		}
+		if v := p.Config.Extra["apiVersion"]; v != "" {
+			apiVersion = v
+		}
+	}
+
+	requestURL := strings.TrimRight(p.Config.APIURL, "/")
+	if !strings.Contains(requestURL, "/openai/deployments/") {
+		requestURL += "/openai/deployments/" + url.PathEscape(deployment) + "/chat/completions"
+	}
+	if !strings.Contains(requestURL, "api-version=") {
+		separator := "?"
+		if strings.Contains(requestURL, "?") {
+			separator = "&"
+		}
+		requestURL += separator + "api-version=" + url.QueryEscape(apiVersion)
+	}
+	return requestURL
+}
+
+// newChatRequest 创建聊天补全请求，Azure 模式使用 api-key 请求头认证
+func (p *OpenAIProvider) newChatRequest(jsonData []byte) (*http.Request, error) {
+	req, err := p.newRequest("POST", p.requestURL(), bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if p.isAzure() {
+		req.Header.Set("api-key", p.Config.APIKey)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+p.Config.APIKey)
+	}
+	return req, nil
+}
+
 // NLTranslateProvider macOS NaturalLanguage 翻译提供商
 type NLTranslateProvider struct {
 	*BaseProvider
@@ -210,6 +405,11 @@ func (p *NLTranslateProvider) GetName() string {
 	return "nltranslator"
 }
 
+// HealthCheck 检查提供商连通性
+func (p *NLTranslateProvider) HealthCheck() error {
+	return checkTranslation(&NLTranslateProvider{BaseProvider: p.withoutCache()})
+}
+
 func (p *NLTranslateProvider) Translate(text, targetLanguage, userPrompt string) (string, error) {
 	// 检查缓存
 	if cached, ok := p.checkCache(text, targetLanguage, userPrompt); ok {
@@ -240,7 +440,7 @@ func (p *NLTranslateProvider) Translate(text, targetLanguage, userPrompt string)
 		return "", err
 	}
 
-	req, err := http.NewRequest("POST", p.Config.APIURL, bytes.NewBuffer(jsonData))
+	req, err := p.newRequest("POST", p.Config.APIURL, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return "", err
 	}
@@ -274,34 +474,36 @@ func (p *NLTranslateProvider) Translate(text, targetLanguage, userPrompt string)
 	return result, nil
 }
 
+// nlLanguageCodes 常见语言名称 -> NaturalLanguage 语言代码
+var nlLanguageCodes = map[string]string{
+	"Uni":             "zh-Hans",
+	"zh":              "zh-Hans",
+	"Simplified Uni":  "zh-Hans",
+	"简体通用":            "zh-Hans",
+	"通用":              "zh-Hans",
+	"Traditional Uni": "zh-Hant",
+	"繁体通用":            "zh-Hant",
+	"繁體通用":            "zh-Hant",
+	"English":         "en",
+	"英语":              "en",
+	"英文":              "en",
+	"Japanese":        "ja",
+	"日语":              "ja",
+	"日文":              "ja",
+	"Korean":          "ko",
+	"韩语":              "ko",
+	"韓語":              "ko",
+	"Spanish":         "es",
+	"西班牙语":            "es",
+	"French":          "fr",
+	"法语":              "fr",
+	"German":          "de",
+	"德语":              "de",
+}
+
 // mapToNLLanguageCode 将常见语言名称映射到 NaturalLanguage 语言代码
 func mapToNLLanguageCode(language string) string {
-	languageMap := map[string]string{
-		"Uni":             "zh-Hans",
-		"Simplified Uni":  "zh-Hans",
-		"简体通用":            "zh-Hans",
-		"通用":              "zh-Hans",
-		"Traditional Uni": "zh-Hant",
-		"繁体通用":            "zh-Hant",
-		"繁體通用":            "zh-Hant",
-		"English":         "en",
-		"英语":              "en",
-		"英文":              "en",
-		"Japanese":        "ja",
-		"日语":              "ja",
-		"日文":              "ja",
-		"Korean":          "ko",
-		"韩语":              "ko",
-		"韓語":              "ko",
-		"Spanish":         "es",
-		"西班牙语":            "es",
-		"French":          "fr",
-		"法语":              "fr",
-		"German":          "de",
-		"德语":              "de",
-	}
-
-	if code, ok := languageMap[language]; ok {
+	if code, ok := nlLanguageCodes[language]; ok {
 		return code
 	}
 
@@ -318,41 +520,22 @@ func (p *ClaudeProvider) GetName() string {
 	return "claude"
 }
 
+// HealthCheck 检查提供商连通性
+func (p *ClaudeProvider) HealthCheck() error {
+	return checkTranslation(&ClaudeProvider{BaseProvider: p.withoutCache()})
+}
+
 func (p *ClaudeProvider) Translate(text, targetLanguage, userPrompt string) (string, error) {
 	// 检查缓存
 	if cached, ok := p.checkCache(text, targetLanguage, userPrompt); ok {
 		return cached, nil
 	}
 
-	systemPrompt := fmt.Sprintf("You are a professional translator. Translate the following text to %s. Keep the original meaning and style. Only return the translated text without any explanations.", targetLanguage)
-	if userPrompt != "" {
-		systemPrompt += " " + userPrompt
-	}
-
-	reqBody := map[string]interface{}{
-		"model":       p.Config.Model,
-		"max_tokens":  p.Config.MaxTokens,
-		"temperature": p.Config.Temperature,
-		"system":      systemPrompt,
-		"messages": []map[string]string{
-			{"role": "user", "content": text},
-		},
-	}
-
-	jsonData, err := json.Marshal(reqBody)
-	if err != nil {
-		return "", err
-	}
-
-	req, err := http.NewRequest("POST", p.Config.APIURL, bytes.NewBuffer(jsonData))
+	req, err := p.newTranslateRequest(text, targetLanguage, userPrompt, false)
 	if err != nil {
 		return "", err
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("x-api-key", p.Config.APIKey)
-	req.Header.Set("anthropic-version", "2023-06-01")
-
 	body, err := p.doRequest(req)
 	if err != nil {
 		return "", err
@@ -362,6 +545,7 @@ func (p *ClaudeProvider) Translate(text, targetLanguage, userPrompt string) (str
 		Content []struct {
 			Text string `json:"text"`
 		} `json:"content"`
+		Usage *claudeUsage `json:"usage,omitempty"`
 		Error *struct {
 			Message string `json:"message"`
 		} `json:"error,omitempty"`
@@ -379,11 +563,48 @@ func (p *ClaudeProvider) Translate(text, targetLanguage, userPrompt string) (str
 		return "", fmt.Errorf("API 未返回翻译结果")
 	}
 
+	if resp.Usage != nil {
+		p.Usage.Add(resp.Usage.InputTokens, resp.Usage.OutputTokens)
+	}
+
 	result := resp.Content[0].Text
 	p.saveCache(text, targetLanguage, userPrompt, result)
 	return result, nil
 }
 
+// newTranslateRequest 构建 Messages API 请求，stream 为 true 时以 SSE 流式返回
+func (p *ClaudeProvider) newTranslateRequest(text, targetLanguage, userPrompt string, stream bool) (*http.Request, error) {
+	systemPrompt := p.buildSystemPrompt(text, targetLanguage, userPrompt)
+
+	reqBody := map[string]interface{}{
+		"model":       p.Config.Model,
+		"max_tokens":  p.Config.MaxTokens,
+		"temperature": p.Config.Temperature,
+		"system":      systemPrompt,
+		"messages": []map[string]string{
+			{"role": "user", "content": text},
+		},
+	}
+	if stream {
+		reqBody["stream"] = true
+	}
+
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := p.newRequest("POST", p.Config.APIURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-api-key", p.Config.APIKey)
+	req.Header.Set("anthropic-version", "2023-06-01")
+	return req, nil
+}
+
 // GeminiProvider Google Gemini 提供商
 type GeminiProvider struct {
 	*BaseProvider
@@ -393,17 +614,56 @@ func (p *GeminiProvider) GetName() string {
 	return "gemini"
 }
 
+// HealthCheck 检查提供商连通性
+func (p *GeminiProvider) HealthCheck() error {
+	return checkTranslation(&GeminiProvider{BaseProvider: p.withoutCache()})
+}
+
 func (p *GeminiProvider) Translate(text, targetLanguage, userPrompt string) (string, error) {
 	// 检查缓存
 	if cached, ok := p.checkCache(text, targetLanguage, userPrompt); ok {
 		return cached, nil
 	}
 
-	systemPrompt := fmt.Sprintf("You are a professional translator. Translate the following text to %s. Keep the original meaning and style. Only return the translated text without any explanations.", targetLanguage)
-	if userPrompt != "" {
-		systemPrompt += " " + userPrompt
+	// Gemini API URL 格式: https://generativelanguage.googleapis.com/v1/models/{model}:generateContent?key={apiKey}
+	apiURL := fmt.Sprintf("%s?key=%s", p.Config.APIURL, p.Config.APIKey)
+
+	req, err := p.newTranslateRequest(apiURL, text, targetLanguage, userPrompt)
+	if err != nil {
+		return "", err
+	}
+
+	body, err := p.doRequest(req)
+	if err != nil {
+		return "", err
+	}
+
+	var resp geminiResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return "", fmt.Errorf("解析响应失败: %w", err)
 	}
 
+	if resp.Error != nil {
+		return "", fmt.Errorf("API 错误: %s", resp.Error.Message)
+	}
+
+	if len(resp.Candidates) == 0 || len(resp.Candidates[0].Content.Parts) == 0 {
+		return "", fmt.Errorf("API 未返回翻译结果")
+	}
+
+	if resp.UsageMetadata != nil {
+		p.Usage.Add(resp.UsageMetadata.PromptTokenCount, resp.UsageMetadata.CandidatesTokenCount)
+	}
+
+	result := resp.Candidates[0].Content.Parts[0].Text
+	p.saveCache(text, targetLanguage, userPrompt, result)
+	return result, nil
+}
+
+// newTranslateRequest 构建 generateContent / streamGenerateContent 请求
+func (p *GeminiProvider) newTranslateRequest(apiURL, text, targetLanguage, userPrompt string) (*http.Request, error) {
+	systemPrompt := p.buildSystemPrompt(text, targetLanguage, userPrompt)
+
 	fullPrompt := systemPrompt + "\n\n" + text
 
 	reqBody := map[string]interface{}{
@@ -425,52 +685,34 @@ func (p *GeminiProvider) Translate(text, targetLanguage, userPrompt string) (str
 
 	jsonData, err := json.Marshal(reqBody)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	// Gemini API URL 格式: https://generativelanguage.googleapis.com/v1/models/{model}:generateContent?key={apiKey}
-	apiURL := fmt.Sprintf("%s?key=%s", p.Config.APIURL, p.Config.APIKey)
-
-	req, err := http.NewRequest("POST", apiURL, bytes.NewBuffer(jsonData))
+	req, err := p.newRequest("POST", apiURL, bytes.NewBuffer(jsonData))
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	req.Header.Set("Content-Type", "application/json")
+	return req, nil
+}
 
-	body, err := p.doRequest(req)
-	if err != nil {
-		return "", err
-	}
-
-	var resp struct {
-		Candidates []struct {
-			Content struct {
-				Parts []struct {
-					Text string `json:"text"`
-				} `json:"parts"`
-			} `json:"content"`
-		} `json:"candidates"`
-		Error *struct {
-			Message string `json:"message"`
-		} `json:"error,omitempty"`
-	}
-
-	if err := json.Unmarshal(body, &resp); err != nil {
-		return "", fmt.Errorf("解析响应失败: %w", err)
-	}
-
-	if resp.Error != nil {
-		return "", fmt.Errorf("API 错误: %s", resp.Error.Message)
-	}
-
-	if len(resp.Candidates) == 0 || len(resp.Candidates[0].Content.Parts) == 0 {
-		return "", fmt.Errorf("API 未返回翻译结果")
-	}
-
-	result := resp.Candidates[0].Content.Parts[0].Text
-	p.saveCache(text, targetLanguage, userPrompt, result)
-	return result, nil
+// geminiResponse generateContent 的响应，流式接口的每个事件也是该结构
+type geminiResponse struct {
+	Candidates []struct {
+		Content struct {
+			Parts []struct {
+				Text string `json:"text"`
+			} `json:"parts"`
+		} `json:"content"`
+	} `json:"candidates"`
+	UsageMetadata *struct {
+		PromptTokenCount     int `json:"promptTokenCount"`
+		CandidatesTokenCount int `json:"candidatesTokenCount"`
+	} `json:"usageMetadata,omitempty"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
 }
 
 // OllamaProvider Ollama 本地模型提供商
@@ -482,16 +724,18 @@ func (p *OllamaProvider) GetName() string {
 	return "ollama"
 }
 
+// HealthCheck 检查提供商连通性
+func (p *OllamaProvider) HealthCheck() error {
+	return checkTranslation(&OllamaProvider{BaseProvider: p.withoutCache()})
+}
+
 func (p *OllamaProvider) Translate(text, targetLanguage, userPrompt string) (string, error) {
 	// 检查缓存
 	if cached, ok := p.checkCache(text, targetLanguage, userPrompt); ok {
 		return cached, nil
 	}
 
-	systemPrompt := fmt.Sprintf("You are a professional translator. Translate the following text to %s. Keep the original meaning and style. Only return the translated text without any explanations.", targetLanguage)
-	if userPrompt != "" {
-		systemPrompt += " " + userPrompt
-	}
+	systemPrompt := p.buildSystemPrompt(text, targetLanguage, userPrompt)
 
 	reqBody := map[string]interface{}{
 		"model":  p.Config.Model,
@@ -511,7 +755,7 @@ func (p *OllamaProvider) Translate(text, targetLanguage, userPrompt string) (str
 		return "", err
 	}
 
-	req, err := http.NewRequest("POST", p.Config.APIURL, bytes.NewBuffer(jsonData))
+	req, err := p.newRequest("POST", p.Config.APIURL, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return "", err
 	}
@@ -554,16 +798,18 @@ func (p *CustomProvider) GetName() string {
 	return "custom"
 }
 
+// HealthCheck 检查提供商连通性
+func (p *CustomProvider) HealthCheck() error {
+	return checkTranslation(&CustomProvider{BaseProvider: p.withoutCache()})
+}
+
 func (p *CustomProvider) Translate(text, targetLanguage, userPrompt string) (string, error) {
 	// 检查缓存
 	if cached, ok := p.checkCache(text, targetLanguage, userPrompt); ok {
 		return cached, nil
 	}
 
-	systemPrompt := fmt.Sprintf("You are a professional translator. Translate the following text to %s. Keep the original meaning and style. Only return the translated text without any explanations.", targetLanguage)
-	if userPrompt != "" {
-		systemPrompt += " " + userPrompt
-	}
+	systemPrompt := p.buildSystemPrompt(text, targetLanguage, userPrompt)
 
 	// 自定义提供商使用 OpenAI 兼容格式作为默认
 	reqBody := map[string]interface{}{
@@ -589,7 +835,7 @@ func (p *CustomProvider) Translate(text, targetLanguage, userPrompt string) (str
 		return "", err
 	}
 
-	req, err := http.NewRequest("POST", p.Config.APIURL, bytes.NewBuffer(jsonData))
+	req, err := p.newRequest("POST", p.Config.APIURL, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return "", err
 	}
@@ -670,6 +916,11 @@ func (p *LibreTranslateProvider) GetName() string {
 	return "libretranslate"
 }
 
+// HealthCheck 检查提供商连通性
+func (p *LibreTranslateProvider) HealthCheck() error {
+	return checkTranslation(&LibreTranslateProvider{BaseProvider: p.withoutCache()})
+}
+
 func (p *LibreTranslateProvider) Translate(text, targetLanguage, userPrompt string) (string, error) {
 	// 检查缓存
 	if cached, ok := p.checkCache(text, targetLanguage, userPrompt); ok {
@@ -706,7 +957,7 @@ func (p *LibreTranslateProvider) Translate(text, targetLanguage, userPrompt stri
 		return "", err
 	}
 
-	req, err := http.NewRequest("POST", p.Config.APIURL, bytes.NewBuffer(jsonData))
+	req, err := p.newRequest("POST", p.Config.APIURL, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return "", err
 	}
@@ -740,44 +991,45 @@ func (p *LibreTranslateProvider) Translate(text, targetLanguage, userPrompt stri
 	return result, nil
 }
 
+// libreTranslateLanguageCodes 常见语言名称 -> LibreTranslate 语言代码
+var libreTranslateLanguageCodes = map[string]string{
+	"Uni":             "zh",
+	"Simplified Uni":  "zh",
+	"简体通用":            "zh",
+	"通用":              "zh",
+	"Traditional Uni": "zh",
+	"繁体通用":            "zh",
+	"繁體通用":            "zh",
+	"English":         "en",
+	"英语":              "en",
+	"英文":              "en",
+	"Japanese":        "ja",
+	"日语":              "ja",
+	"日文":              "ja",
+	"Korean":          "ko",
+	"韩语":              "ko",
+	"韓語":              "ko",
+	"Spanish":         "es",
+	"西班牙语":            "es",
+	"French":          "fr",
+	"法语":              "fr",
+	"German":          "de",
+	"德语":              "de",
+	"Italian":         "it",
+	"意大利语":            "it",
+	"Portuguese":      "pt",
+	"葡萄牙语":            "pt",
+	"Russian":         "ru",
+	"俄语":              "ru",
+	"Arabic":          "ar",
+	"阿拉伯语":            "ar",
+	"Hindi":           "hi",
+	"印地语":             "hi",
+}
+
 // mapToLibreTranslateLanguageCode 将常见语言名称映射到 LibreTranslate 语言代码
 func mapToLibreTranslateLanguageCode(language string) string {
-	languageMap := map[string]string{
-		"Uni":             "zh",
-		"Simplified Uni":  "zh",
-		"简体通用":            "zh",
-		"通用":              "zh",
-		"Traditional Uni": "zh",
-		"繁体通用":            "zh",
-		"繁體通用":            "zh",
-		"English":         "en",
-		"英语":              "en",
-		"英文":              "en",
-		"Japanese":        "ja",
-		"日语":              "ja",
-		"日文":              "ja",
-		"Korean":          "ko",
-		"韩语":              "ko",
-		"韓語":              "ko",
-		"Spanish":         "es",
-		"西班牙语":            "es",
-		"French":          "fr",
-		"法语":              "fr",
-		"German":          "de",
-		"德语":              "de",
-		"Italian":         "it",
-		"意大利语":            "it",
-		"Portuguese":      "pt",
-		"葡萄牙语":            "pt",
-		"Russian":         "ru",
-		"俄语":              "ru",
-		"Arabic":          "ar",
-		"阿拉伯语":            "ar",
-		"Hindi":           "hi",
-		"印地语":             "hi",
-	}
-
-	if code, ok := languageMap[language]; ok {
+	if code, ok := libreTranslateLanguageCodes[language]; ok {
 		return code
 	}
 
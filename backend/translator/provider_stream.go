@@ -0,0 +1,374 @@
+package translator
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// StreamProvider 支持流式返回译文的提供商
+type StreamProvider interface {
+	// TranslateStream 翻译文本，每收到一段译文调用 onDelta，返回完整译文
+	TranslateStream(text, targetLanguage, userPrompt string, onDelta func(delta string)) (string, error)
+}
+
+// UsageStats 累计的 token 用量（并发安全），同时计入 provider_tokens_total 指标
+type UsageStats struct {
+	provider     ProviderType
+	mu           sync.Mutex
+	inputTokens  int
+	outputTokens int
+	requests     int
+}
+
+// TokenUsage token 用量快照
+type TokenUsage struct {
+	InputTokens  int `json:"inputTokens"`
+	OutputTokens int `json:"outputTokens"`
+	Requests     int `json:"requests"` // 返回了用量的请求数
+}
+
+// Add 记录一次请求的用量，接收者为空时忽略
+func (u *UsageStats) Add(inputTokens, outputTokens int) {
+	if u == nil {
+		return
+	}
+	tokensTotal.WithLabelValues(string(u.provider), "input").Add(float64(inputTokens))
+	tokensTotal.WithLabelValues(string(u.provider), "output").Add(float64(outputTokens))
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.inputTokens += inputTokens
+	u.outputTokens += outputTokens
+	u.requests++
+}
+
+// Snapshot 返回当前累计的用量
+func (u *UsageStats) Snapshot() TokenUsage {
+	if u == nil {
+		return TokenUsage{}
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return TokenUsage{InputTokens: u.inputTokens, OutputTokens: u.outputTokens, Requests: u.requests}
+}
+
+// TokenUsage 返回提供商累计的 token 用量
+func (b *BaseProvider) TokenUsage() TokenUsage {
+	return b.Usage.Snapshot()
+}
+
+// UsageReporter 能够报告 token 用量的提供商
+type UsageReporter interface {
+	TokenUsage() TokenUsage
+}
+
+// TokenUsage 返回翻译客户端累计的 token 用量，提供商不统计用量时返回零值
+func (c *TranslatorClient) TokenUsage() TokenUsage {
+	if reporter, ok := c.Provider.(UsageReporter); ok {
+		return reporter.TokenUsage()
+	}
+	return TokenUsage{}
+}
+
+// doStreamRequest 发送流式请求，状态码不是 200 时读取响应体作为错误
+func (b *BaseProvider) doStreamRequest(req *http.Request) (io.ReadCloser, error) {
+	req.Header.Set("Accept", "text/event-stream")
+	start := time.Now()
+	resp, err := b.HTTPClient.Do(req)
+	if err != nil {
+		recordProviderRequest(b.Config.Type, 0, start)
+		return nil, fmt.Errorf("API 请求失败: %w", err)
+	}
+	// 流式请求只统计到收到响应头的耗时
+	recordProviderRequest(b.Config.Type, resp.StatusCode, start)
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("API 返回错误 (状态码 %d): %s", resp.StatusCode, string(body))
+	}
+	return resp.Body, nil
+}
+
+// readSSE 逐个读取 Server-Sent Events，回调事件类型和数据（多行 data 以换行连接）
+func readSSE(r io.Reader, onEvent func(event, data string) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+
+	var event string
+	var data []string
+	dispatch := func() error {
+		defer func() { event, data = "", nil }()
+		if len(data) == 0 {
+			return nil
+		}
+		return onEvent(event, strings.Join(data, "\n"))
+	}
+
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		switch {
+		case line == "":
+			if err := dispatch(); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, ":"):
+			// 注释行（心跳）
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("读取流式响应失败: %w", err)
+	}
+	return dispatch()
+}
+
+// openAIUsage OpenAI 兼容接口返回的用量
+type openAIUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+}
+
+// parseOpenAIStream 解析 OpenAI 兼容接口的流式响应，返回完整译文和用量（未返回用量时为 nil）
+func parseOpenAIStream(r io.Reader, onDelta func(string)) (string, *openAIUsage, error) {
+	var text strings.Builder
+	var usage *openAIUsage
+	err := readSSE(r, func(_, data string) error {
+		if data == "[DONE]" {
+			return nil
+		}
+		var chunk struct {
+			Choices []struct {
+				Delta struct {
+					Content string `json:"content"`
+				} `json:"delta"`
+			} `json:"choices"`
+			Usage *openAIUsage `json:"usage,omitempty"`
+			Error *struct {
+				Message string `json:"message"`
+			} `json:"error,omitempty"`
+		}
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return fmt.Errorf("解析流式响应失败: %w", err)
+		}
+		if chunk.Error != nil {
+			return fmt.Errorf("API 错误: %s", chunk.Error.Message)
+		}
+		if chunk.Usage != nil {
+			usage = chunk.Usage
+		}
+		for _, choice := range chunk.Choices {
+			if choice.Delta.Content != "" {
+				text.WriteString(choice.Delta.Content)
+				if onDelta != nil {
+					onDelta(choice.Delta.Content)
+				}
+			}
+		}
+		return nil
+	})
+	return text.String(), usage, err
+}
+
+// claudeUsage Claude Messages API 返回的用量
+type claudeUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+// parseClaudeStream 解析 Claude Messages API 的流式响应，返回完整译文和用量
+// 输入 token 数来自 message_start，输出 token 数以 message_delta 中的累计值为准
+func parseClaudeStream(r io.Reader, onDelta func(string)) (string, claudeUsage, error) {
+	var text strings.Builder
+	var usage claudeUsage
+	err := readSSE(r, func(_, data string) error {
+		var event struct {
+			Type    string `json:"type"`
+			Message struct {
+				Usage claudeUsage `json:"usage"`
+			} `json:"message"`
+			Delta struct {
+				Type string `json:"type"`
+				Text string `json:"text"`
+			} `json:"delta"`
+			Usage *claudeUsage `json:"usage,omitempty"`
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			return fmt.Errorf("解析流式响应失败: %w", err)
+		}
+
+		switch event.Type {
+		case "message_start":
+			usage = event.Message.Usage
+		case "content_block_delta":
+			if event.Delta.Type == "text_delta" && event.Delta.Text != "" {
+				text.WriteString(event.Delta.Text)
+				if onDelta != nil {
+					onDelta(event.Delta.Text)
+				}
+			}
+		case "message_delta":
+			if event.Usage != nil {
+				usage.OutputTokens = event.Usage.OutputTokens
+				if event.Usage.InputTokens > 0 {
+					usage.InputTokens = event.Usage.InputTokens
+				}
+			}
+		case "error":
+			return fmt.Errorf("API 错误: %s", event.Error.Message)
+		}
+		return nil
+	})
+	return text.String(), usage, err
+}
+
+// parseGeminiStream 解析 Gemini streamGenerateContent（alt=sse）的流式响应，返回完整译文和用量
+// 每个事件的 usageMetadata 为截至当前的累计值，以最后一次为准
+func parseGeminiStream(r io.Reader, onDelta func(string)) (string, TokenUsage, error) {
+	var text strings.Builder
+	var usage TokenUsage
+	err := readSSE(r, func(_, data string) error {
+		var chunk geminiResponse
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return fmt.Errorf("解析流式响应失败: %w", err)
+		}
+		if chunk.Error != nil {
+			return fmt.Errorf("API 错误: %s", chunk.Error.Message)
+		}
+		if chunk.UsageMetadata != nil {
+			usage.InputTokens = chunk.UsageMetadata.PromptTokenCount
+			usage.OutputTokens = chunk.UsageMetadata.CandidatesTokenCount
+		}
+		if len(chunk.Candidates) > 0 {
+			for _, part := range chunk.Candidates[0].Content.Parts {
+				if part.Text != "" {
+					text.WriteString(part.Text)
+					if onDelta != nil {
+						onDelta(part.Text)
+					}
+				}
+			}
+		}
+		return nil
+	})
+	return text.String(), usage, err
+}
+
+// TranslateStream 流式翻译（实现 StreamProvider 接口）
+func (p *OpenAIProvider) TranslateStream(text, targetLanguage, userPrompt string, onDelta func(string)) (string, error) {
+	if cached, ok := p.checkCache(text, targetLanguage, userPrompt); ok {
+		if onDelta != nil {
+			onDelta(cached)
+		}
+		return cached, nil
+	}
+
+	req, err := p.newTranslateRequest(text, targetLanguage, userPrompt, true)
+	if err != nil {
+		return "", err
+	}
+	body, err := p.doStreamRequest(req)
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+
+	result, usage, err := parseOpenAIStream(body, onDelta)
+	if err != nil {
+		return "", err
+	}
+	if usage != nil {
+		p.Usage.Add(usage.PromptTokens, usage.CompletionTokens)
+	}
+	if result == "" {
+		return "", fmt.Errorf("API 未返回翻译结果")
+	}
+
+	p.saveCache(text, targetLanguage, userPrompt, result)
+	return result, nil
+}
+
+// TranslateStream 流式翻译（实现 StreamProvider 接口）
+func (p *ClaudeProvider) TranslateStream(text, targetLanguage, userPrompt string, onDelta func(string)) (string, error) {
+	if cached, ok := p.checkCache(text, targetLanguage, userPrompt); ok {
+		if onDelta != nil {
+			onDelta(cached)
+		}
+		return cached, nil
+	}
+
+	req, err := p.newTranslateRequest(text, targetLanguage, userPrompt, true)
+	if err != nil {
+		return "", err
+	}
+	body, err := p.doStreamRequest(req)
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+
+	result, usage, err := parseClaudeStream(body, onDelta)
+	if err != nil {
+		return "", err
+	}
+	p.Usage.Add(usage.InputTokens, usage.OutputTokens)
+	if result == "" {
+		return "", fmt.Errorf("API 未返回翻译结果")
+	}
+
+	p.saveCache(text, targetLanguage, userPrompt, result)
+	return result, nil
+}
+
+// TranslateStream 流式翻译（实现 StreamProvider 接口），使用 streamGenerateContent 接口
+func (p *GeminiProvider) TranslateStream(text, targetLanguage, userPrompt string, onDelta func(string)) (string, error) {
+	if cached, ok := p.checkCache(text, targetLanguage, userPrompt); ok {
+		if onDelta != nil {
+			onDelta(cached)
+		}
+		return cached, nil
+	}
+
+	req, err := p.newTranslateRequest(p.streamURL(), text, targetLanguage, userPrompt)
+	if err != nil {
+		return "", err
+	}
+	body, err := p.doStreamRequest(req)
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+
+	result, usage, err := parseGeminiStream(body, onDelta)
+	if err != nil {
+		return "", err
+	}
+	p.Usage.Add(usage.InputTokens, usage.OutputTokens)
+	if result == "" {
+		return "", fmt.Errorf("API 未返回翻译结果")
+	}
+
+	p.saveCache(text, targetLanguage, userPrompt, result)
+	return result, nil
+}
+
+// streamURL 将 generateContent 地址转换为 streamGenerateContent 地址，以 SSE 格式返回
+func (p *GeminiProvider) streamURL() string {
+	apiURL := strings.Replace(p.Config.APIURL, ":generateContent", ":streamGenerateContent", 1)
+	separator := "?"
+	if strings.Contains(apiURL, "?") {
+		separator = "&"
+	}
+	return apiURL + separator + "alt=sse&key=" + p.Config.APIKey
+}
@@ -0,0 +1,160 @@
+package translator
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// claudeSSE Claude Messages API 流式响应示例（按官方文档的事件顺序）
+const claudeSSE = `event: message_start
+data: {"type":"message_start","message":{"id":"msg_1","type":"message","role":"assistant","content":[],"model":"claude-test","stop_reason":null,"usage":{"input_tokens":25,"output_tokens":1}}}
+
+event: content_block_start
+data: {"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}
+
+event: ping
+data: {"type":"ping"}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Bonjour"}}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":" le monde"}}
+
+event: content_block_stop
+data: {"type":"content_block_stop","index":0}
+
+event: message_delta
+data: {"type":"message_delta","delta":{"stop_reason":"end_turn","stop_sequence":null},"usage":{"output_tokens":15}}
+
+event: message_stop
+data: {"type":"message_stop"}
+
+`
+
+// geminiSSE Gemini streamGenerateContent?alt=sse 流式响应示例，usageMetadata 为累计值
+const geminiSSE = `data: {"candidates":[{"content":{"parts":[{"text":"Bonjour"}],"role":"model"}}],"usageMetadata":{"promptTokenCount":12,"candidatesTokenCount":2,"totalTokenCount":14}}
+
+data: {"candidates":[{"content":{"parts":[{"text":" le monde"}],"role":"model"},"finishReason":"STOP"}],"usageMetadata":{"promptTokenCount":12,"candidatesTokenCount":5,"totalTokenCount":17}}
+
+`
+
+// openAISSE OpenAI 流式响应示例（stream_options.include_usage 时最后一个分块携带用量）
+const openAISSE = `data: {"choices":[{"index":0,"delta":{"role":"assistant","content":""}}]}
+
+data: {"choices":[{"index":0,"delta":{"content":"Bonjour"}}]}
+
+data: {"choices":[{"index":0,"delta":{"content":" le monde"}}]}
+
+data: {"choices":[],"usage":{"prompt_tokens":20,"completion_tokens":4,"total_tokens":24}}
+
+data: [DONE]
+
+`
+
+func TestParseClaudeStream(t *testing.T) {
+	var deltas []string
+	text, usage, err := parseClaudeStream(strings.NewReader(claudeSSE), func(d string) { deltas = append(deltas, d) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text != "Bonjour le monde" || strings.Join(deltas, "|") != "Bonjour| le monde" {
+		t.Errorf("text = %q, deltas = %q", text, deltas)
+	}
+	if usage.InputTokens != 25 || usage.OutputTokens != 15 {
+		t.Errorf("usage = %+v，期望输入 25、输出 15", usage)
+	}
+
+	errorSSE := "event: error\ndata: {\"type\":\"error\",\"error\":{\"type\":\"overloaded_error\",\"message\":\"Overloaded\"}}\n\n"
+	if _, _, err := parseClaudeStream(strings.NewReader(errorSSE), nil); err == nil || !strings.Contains(err.Error(), "Overloaded") {
+		t.Errorf("error 事件应返回错误，得到 %v", err)
+	}
+}
+
+func TestParseGeminiStream(t *testing.T) {
+	var deltas []string
+	text, usage, err := parseGeminiStream(strings.NewReader(geminiSSE), func(d string) { deltas = append(deltas, d) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text != "Bonjour le monde" || len(deltas) != 2 {
+		t.Errorf("text = %q, deltas = %q", text, deltas)
+	}
+	if usage.InputTokens != 12 || usage.OutputTokens != 5 {
+		t.Errorf("usage = %+v，期望输入 12、输出 5", usage)
+	}
+}
+
+func TestParseOpenAIStream(t *testing.T) {
+	text, usage, err := parseOpenAIStream(strings.NewReader(openAISSE), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text != "Bonjour le monde" {
+		t.Errorf("text = %q", text)
+	}
+	if usage == nil || usage.PromptTokens != 20 || usage.CompletionTokens != 4 {
+		t.Errorf("usage = %+v，期望输入 20、输出 4", usage)
+	}
+}
+
+// newSSEServer 启动返回固定 SSE 内容的测试服务器，记录请求地址
+func newSSEServer(t *testing.T, body string, paths *[]string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*paths = append(*paths, r.URL.String())
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestTranslateStreamRecordsUsage(t *testing.T) {
+	tests := []struct {
+		config   ProviderConfig
+		body     string
+		input    int
+		output   int
+		wantPath string
+	}{
+		{ProviderConfig{Type: ProviderClaude, APIKey: "key", Model: "claude-test"}, claudeSSE, 25, 15, ""},
+		{ProviderConfig{Type: ProviderGemini, APIKey: "key", Model: "gemini-test"}, geminiSSE, 12, 5, ":streamGenerateContent?alt=sse&key=key"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.config.Type), func(t *testing.T) {
+			var paths []string
+			server := newSSEServer(t, tt.body, &paths)
+			tt.config.APIURL = server.URL
+			if tt.config.Type == ProviderGemini {
+				tt.config.APIURL = server.URL + "/v1beta/models/gemini-test:generateContent"
+			}
+			provider, err := NewProvider(tt.config, NewMemoryCache())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var streamed strings.Builder
+			result, err := provider.(StreamProvider).TranslateStream("Hello world", "French", "", func(d string) { streamed.WriteString(d) })
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result != "Bonjour le monde" || streamed.String() != result {
+				t.Errorf("result = %q, streamed = %q", result, streamed.String())
+			}
+			if usage := provider.(UsageReporter).TokenUsage(); usage.InputTokens != tt.input || usage.OutputTokens != tt.output || usage.Requests != 1 {
+				t.Errorf("TokenUsage = %+v，期望输入 %d、输出 %d", usage, tt.input, tt.output)
+			}
+			if len(paths) != 1 || !strings.HasSuffix(paths[0], tt.wantPath) {
+				t.Errorf("请求地址 = %q，期望以 %q 结尾", paths, tt.wantPath)
+			}
+
+			// 第二次命中缓存，不再请求
+			if result, err := provider.(StreamProvider).TranslateStream("Hello world", "French", "", nil); err != nil || result != "Bonjour le monde" || len(paths) != 1 {
+				t.Errorf("缓存命中时 result = %q, err = %v, 请求 %d 次", result, err, len(paths))
+			}
+		})
+	}
+}
@@ -0,0 +1,245 @@
+package translator
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 腾讯云机器翻译（TMT）接口参数
+const (
+	defaultTencentURL    = "https://tmt.tencentcloudapi.com"
+	defaultTencentRegion = "ap-guangzhou"
+	tencentService       = "tmt"
+	tencentAPIVersion    = "2018-03-21"
+	tencentAlgorithm     = "TC3-HMAC-SHA256"
+	tencentContentType   = "application/json; charset=utf-8"
+)
+
+// TencentProvider 腾讯云机器翻译提供商
+// 凭证优先使用 Extra["secretId"] 与 APIKey（SecretKey），也可以在 APIKey 中填写 "SecretId:SecretKey"
+// Extra["region"] 指定地域，默认 ap-guangzhou
+type TencentProvider struct {
+	*BaseProvider
+}
+
+func (p *TencentProvider) GetName() string {
+	return "tencent"
+}
+
+// HealthCheck 检查提供商连通性
+func (p *TencentProvider) HealthCheck() error {
+	return checkTranslation(&TencentProvider{BaseProvider: p.withoutCache()})
+}
+
+func (p *TencentProvider) Translate(text, targetLanguage, userPrompt string) (string, error) {
+	// 检查缓存
+	if cached, ok := p.checkCache(text, targetLanguage, userPrompt); ok {
+		return cached, nil
+	}
+
+	var resp struct {
+		TargetText string `json:"TargetText"`
+	}
+	err := p.call("TextTranslate", map[string]interface{}{
+		"SourceText": text,
+		"Source":     p.sourceLanguage(),
+		"Target":     mapToTencentLanguageCode(targetLanguage),
+		"ProjectId":  0,
+	}, &resp)
+	if err != nil {
+		return "", err
+	}
+
+	if resp.TargetText == "" {
+		return "", fmt.Errorf("API 未返回翻译结果")
+	}
+
+	p.saveCache(text, targetLanguage, userPrompt, resp.TargetText)
+	return resp.TargetText, nil
+}
+
+// TranslateBatch 使用 TextTranslateBatch 接口批量翻译未命中缓存的文本
+func (p *TencentProvider) TranslateBatch(texts []string, targetLanguage, userPrompt string) ([]string, error) {
+	results, pending := p.checkBatchCache(texts, targetLanguage, userPrompt)
+	if len(pending) == 0 {
+		return results, nil
+	}
+
+	sourceList := make([]string, 0, len(pending))
+	for _, i := range pending {
+		sourceList = append(sourceList, texts[i])
+	}
+
+	var resp struct {
+		TargetTextList []string `json:"TargetTextList"`
+	}
+	err := p.call("TextTranslateBatch", map[string]interface{}{
+		"SourceTextList": sourceList,
+		"Source":         p.sourceLanguage(),
+		"Target":         mapToTencentLanguageCode(targetLanguage),
+		"ProjectId":      0,
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(resp.TargetTextList) != len(pending) {
+		return nil, fmt.Errorf("API 返回的翻译数量不匹配: 期望 %d，实际 %d", len(pending), len(resp.TargetTextList))
+	}
+
+	for j, i := range pending {
+		results[i] = resp.TargetTextList[j]
+		p.saveCache(texts[i], targetLanguage, userPrompt, resp.TargetTextList[j])
+	}
+	return results, nil
+}
+
+// sourceLanguage 返回源语言代码，未配置时自动检测
+func (p *TencentProvider) sourceLanguage() string {
+	if p.Config.Extra != nil && p.Config.Extra["sourceLanguage"] != "" {
+		return mapToTencentLanguageCode(p.Config.Extra["sourceLanguage"])
+	}
+	return "auto"
+}
+
+// credentials 返回 SecretId 和 SecretKey
+func (p *TencentProvider) credentials() (string, string, error) {
+	if p.Config.Extra != nil && p.Config.Extra["secretId"] != "" {
+		return p.Config.Extra["secretId"], p.Config.APIKey, nil
+	}
+	if secretID, secretKey, ok := strings.Cut(p.Config.APIKey, ":"); ok && secretID != "" && secretKey != "" {
+		return secretID, secretKey, nil
+	}
+	return "", "", fmt.Errorf("腾讯云翻译需要 SecretId 和 SecretKey，请在 API Key 中填写 \"SecretId:SecretKey\"")
+}
+
+// call 调用 TMT 接口，解析 Response 字段到 out
+func (p *TencentProvider) call(action string, params map[string]interface{}, out interface{}) error {
+	secretID, secretKey, err := p.credentials()
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+
+	apiURL := p.Config.APIURL
+	if apiURL == "" {
+		apiURL = defaultTencentURL
+	}
+	parsed, err := url.Parse(apiURL)
+	if err != nil {
+		return fmt.Errorf("无效的 API URL: %w", err)
+	}
+
+	region := defaultTencentRegion
+	if p.Config.Extra != nil && p.Config.Extra["region"] != "" {
+		region = p.Config.Extra["region"]
+	}
+
+	timestamp := time.Now().Unix()
+
+	req, err := p.newRequest("POST", apiURL, bytes.NewBuffer(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", tencentContentType)
+	req.Header.Set("X-TC-Action", action)
+	req.Header.Set("X-TC-Timestamp", strconv.FormatInt(timestamp, 10))
+	req.Header.Set("X-TC-Version", tencentAPIVersion)
+	req.Header.Set("X-TC-Region", region)
+	req.Header.Set("Authorization", tencentAuthorization(secretID, secretKey, parsed.Host, payload, timestamp))
+
+	body, err := p.doRequest(req)
+	if err != nil {
+		return err
+	}
+
+	var resp struct {
+		Response json.RawMessage `json:"Response"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return fmt.Errorf("解析响应失败: %w", err)
+	}
+
+	var apiErr struct {
+		Error *struct {
+			Code    string `json:"Code"`
+			Message string `json:"Message"`
+		} `json:"Error"`
+	}
+	if err := json.Unmarshal(resp.Response, &apiErr); err != nil {
+		return fmt.Errorf("解析响应失败: %w", err)
+	}
+	if apiErr.Error != nil {
+		return fmt.Errorf("翻译错误: %s: %s", apiErr.Error.Code, apiErr.Error.Message)
+	}
+
+	if err := json.Unmarshal(resp.Response, out); err != nil {
+		return fmt.Errorf("解析响应失败: %w", err)
+	}
+	return nil
+}
+
+// tencentAuthorization 按 TC3-HMAC-SHA256 算法生成 Authorization 头
+func tencentAuthorization(secretID, secretKey, host string, payload []byte, timestamp int64) string {
+	date := time.Unix(timestamp, 0).UTC().Format("2006-01-02")
+
+	// 1. 规范请求串
+	hashedPayload := sha256.Sum256(payload)
+	canonicalHeaders := "content-type:" + tencentContentType + "\n" + "host:" + host + "\n"
+	signedHeaders := "content-type;host"
+	canonicalRequest := strings.Join([]string{
+		"POST",
+		"/",
+		"",
+		canonicalHeaders,
+		signedHeaders,
+		hex.EncodeToString(hashedPayload[:]),
+	}, "\n")
+
+	// 2. 待签名字符串
+	credentialScope := date + "/" + tencentService + "/tc3_request"
+	hashedRequest := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		tencentAlgorithm,
+		strconv.FormatInt(timestamp, 10),
+		credentialScope,
+		hex.EncodeToString(hashedRequest[:]),
+	}, "\n")
+
+	// 3. 计算签名
+	secretDate := hmacSHA256([]byte("TC3"+secretKey), date)
+	secretService := hmacSHA256(secretDate, tencentService)
+	secretSigning := hmacSHA256(secretService, "tc3_request")
+	signature := hex.EncodeToString(hmacSHA256(secretSigning, stringToSign))
+
+	return fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		tencentAlgorithm, secretID, credentialScope, signedHeaders, signature)
+}
+
+// hmacSHA256 计算 HMAC-SHA256
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// mapToTencentLanguageCode 将常见语言名称映射到腾讯云翻译语言代码
+func mapToTencentLanguageCode(language string) string {
+	switch language {
+	case "Traditional Uni", "繁体通用", "繁體通用", "zh-tw", "zh-TW", "zh-hk":
+		return "zh-TW"
+	}
+	return mapToLibreTranslateLanguageCode(language)
+}
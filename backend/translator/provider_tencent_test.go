@@ -0,0 +1,112 @@
+package translator
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestTencentAuthorization(t *testing.T) {
+	// 按腾讯云 TC3-HMAC-SHA256 签名步骤独立计算的结果
+	payload := []byte(`{"ProjectId":0,"Source":"auto","SourceText":"Hello","Target":"zh"}`)
+	got := tencentAuthorization("AKIDEXAMPLE", "SecretKeyExample", "tmt.tencentcloudapi.com", payload, 1551113065)
+	want := "TC3-HMAC-SHA256 Credential=AKIDEXAMPLE/2019-02-25/tmt/tc3_request, SignedHeaders=content-type;host, " +
+		"Signature=5c7fcb84623fcb70b14fcd9eec966de7f725fe05dc620ab92710c8a57670b902"
+	if got != want {
+		t.Errorf("tencentAuthorization =\n%s\n期望\n%s", got, want)
+	}
+}
+
+func TestTencentProviderRequest(t *testing.T) {
+	type request struct {
+		Header http.Header
+		Body   map[string]interface{}
+	}
+	var requests []request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, _ := io.ReadAll(r.Body)
+		var body map[string]interface{}
+		json.Unmarshal(raw, &body)
+		requests = append(requests, request{Header: r.Header.Clone(), Body: body})
+
+		// 按请求头中的时间戳重新计算签名，确认签名覆盖实际发送的内容
+		timestamp, _ := strconv.ParseInt(r.Header.Get("X-TC-Timestamp"), 10, 64)
+		if r.Header.Get("Authorization") != tencentAuthorization("AKIDtest", "secret", r.Host, raw, timestamp) {
+			w.Write([]byte(`{"Response":{"Error":{"Code":"AuthFailure.SignatureFailure","Message":"bad signature"}}}`))
+			return
+		}
+
+		switch r.Header.Get("X-TC-Action") {
+		case "TextTranslate":
+			w.Write([]byte(`{"Response":{"TargetText":"你好","RequestId":"1"}}`))
+		case "TextTranslateBatch":
+			w.Write([]byte(`{"Response":{"TargetTextList":["早上好","谢谢"],"RequestId":"2"}}`))
+		}
+	}))
+	defer server.Close()
+
+	provider, err := NewProvider(ProviderConfig{
+		Type:   ProviderTencent,
+		APIURL: server.URL,
+		APIKey: "AKIDtest:secret",
+		Extra:  map[string]string{"region": "ap-shanghai"},
+	}, NewMemoryCache())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := provider.Translate("Hello", "Uni", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "你好" {
+		t.Errorf("Translate = %q", got)
+	}
+	req := requests[0]
+	if req.Header.Get("X-TC-Version") != tencentAPIVersion || req.Header.Get("X-TC-Region") != "ap-shanghai" {
+		t.Errorf("请求头 = %v", req.Header)
+	}
+	if req.Body["SourceText"] != "Hello" || req.Body["Source"] != "auto" || req.Body["Target"] != "zh" {
+		t.Errorf("请求体 = %v", req.Body)
+	}
+
+	// 批量翻译只发送未命中缓存的文本
+	results, err := provider.(BatchProvider).TranslateBatch([]string{"Hello", "Good morning", "Thank you"}, "Uni", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(results, "|") != "你好|早上好|谢谢" {
+		t.Errorf("TranslateBatch = %q", results)
+	}
+	if len(requests) != 2 {
+		t.Fatalf("请求 %d 次，期望 2", len(requests))
+	}
+	if list, _ := json.Marshal(requests[1].Body["SourceTextList"]); string(list) != `["Good morning","Thank you"]` {
+		t.Errorf("SourceTextList = %s", list)
+	}
+}
+
+func TestTencentProviderCredentials(t *testing.T) {
+	for _, config := range []ProviderConfig{
+		{APIKey: "secret", Extra: map[string]string{"secretId": "AKIDtest"}},
+		{APIKey: "AKIDtest:secret"},
+	} {
+		p := &TencentProvider{BaseProvider: &BaseProvider{Config: config}}
+		id, key, err := p.credentials()
+		if err != nil || id != "AKIDtest" || key != "secret" {
+			t.Errorf("credentials(%+v) = %q, %q, %v", config, id, key, err)
+		}
+	}
+
+	provider, err := NewProvider(ProviderConfig{Type: ProviderTencent, APIKey: "secret-only"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := provider.Translate("Hello", "Uni", ""); err == nil || !strings.Contains(err.Error(), "SecretId") {
+		t.Errorf("缺少 SecretId 时应在发送请求前报错，得到 %v", err)
+	}
+}
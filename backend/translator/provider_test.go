@@ -0,0 +1,110 @@
+package translator
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHealthCheck(t *testing.T) {
+	stub := newOpenAIStub(t, nil)
+	provider, err := NewProvider(stub.Config(), NewMemoryCache())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := provider.HealthCheck(); err != nil {
+			t.Fatalf("HealthCheck: %v", err)
+		}
+	}
+	// 连接测试不使用缓存，每次都实际请求翻译服务
+	if n := len(stub.Requests()); n != 2 {
+		t.Errorf("翻译服务收到 %d 次请求，期望 2", n)
+	}
+}
+
+func TestHealthCheckReportsFailure(t *testing.T) {
+	tests := map[string]http.HandlerFunc{
+		"server error": func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, `{"error":{"message":"invalid api key"}}`, http.StatusUnauthorized)
+		},
+		"empty reply": func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"choices":[{"message":{"content":""}}]}`))
+		},
+	}
+	for name, handler := range tests {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(handler)
+			defer server.Close()
+			provider, err := NewProvider(ProviderConfig{Type: ProviderOpenAI, APIURL: server.URL, APIKey: "key"}, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := provider.HealthCheck(); err == nil {
+				t.Error("HealthCheck 应返回错误")
+			}
+		})
+	}
+}
+
+func TestAzureOpenAIRequest(t *testing.T) {
+	stub := newOpenAIStub(t, nil)
+	tests := []struct {
+		name    string
+		config  ProviderConfig
+		wantURL string
+	}{
+		{
+			name:    "默认部署和版本",
+			config:  ProviderConfig{Type: ProviderAzureOpenAI, APIURL: stub.URL + "/", Model: "gpt-4o"},
+			wantURL: "/openai/deployments/gpt-4o/chat/completions?api-version=" + defaultAzureAPIVersion,
+		},
+		{
+			name: "指定部署和版本",
+			config: ProviderConfig{Type: ProviderAzureOpenAI, APIURL: stub.URL, Model: "gpt-4o",
+				Extra: map[string]string{"deployment": "prod translator", "apiVersion": "2024-06-01"}},
+			wantURL: "/openai/deployments/prod%20translator/chat/completions?api-version=2024-06-01",
+		},
+		{
+			name:    "OpenAI 类型启用 azure",
+			config:  ProviderConfig{Type: ProviderOpenAI, APIURL: stub.URL, Model: "gpt-4o", Extra: map[string]string{"azure": "true"}},
+			wantURL: "/openai/deployments/gpt-4o/chat/completions?api-version=" + defaultAzureAPIVersion,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.APIKey = "azure-key"
+			provider, err := NewProvider(tt.config, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := provider.Translate("Hello", "Uni", ""); err != nil {
+				t.Fatal(err)
+			}
+			requests := stub.Requests()
+			req := requests[len(requests)-1]
+			if req.URL != tt.wantURL {
+				t.Errorf("请求地址 = %s，期望 %s", req.URL, tt.wantURL)
+			}
+			if got := req.Header.Get("api-key"); got != "azure-key" {
+				t.Errorf("api-key 头 = %q", got)
+			}
+			if got := req.Header.Get("Authorization"); got != "" {
+				t.Errorf("Azure 请求不应带 Authorization 头: %q", got)
+			}
+		})
+	}
+
+	// 非 Azure 配置使用 Bearer 认证和原始地址
+	provider, err := NewProvider(stub.Config(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := provider.Translate("Hello", "Uni", ""); err != nil {
+		t.Fatal(err)
+	}
+	requests := stub.Requests()
+	if req := requests[len(requests)-1]; req.URL != "/" || req.Header.Get("Authorization") != "Bearer test-key" {
+		t.Errorf("OpenAI 请求: %s %q", req.URL, req.Header.Get("Authorization"))
+	}
+}
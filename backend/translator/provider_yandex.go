@@ -0,0 +1,140 @@
+package translator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Yandex Translate v2 默认接口地址
+const defaultYandexURL = "https://translate.api.cloud.yandex.net/translate/v2/translate"
+
+// maxYandexBatchChars Yandex 单次请求的文本总长度上限（按字符计，留有余量）
+const maxYandexBatchChars = 9000
+
+// YandexProvider Yandex Translate 提供商
+// APIKey 默认按 Api-Key 认证发送；Extra["authType"] 为 "iam" 时按 IAM 令牌发送，此时需要 Extra["folderId"]
+type YandexProvider struct {
+	*BaseProvider
+}
+
+func (p *YandexProvider) GetName() string {
+	return "yandex"
+}
+
+// HealthCheck 检查提供商连通性
+func (p *YandexProvider) HealthCheck() error {
+	return checkTranslation(&YandexProvider{BaseProvider: p.withoutCache()})
+}
+
+func (p *YandexProvider) Translate(text, targetLanguage, userPrompt string) (string, error) {
+	results, err := p.TranslateBatch([]string{text}, targetLanguage, userPrompt)
+	if err != nil {
+		return "", err
+	}
+	return results[0], nil
+}
+
+// TranslateBatch 批量翻译，未命中缓存的文本按长度上限分批发送
+func (p *YandexProvider) TranslateBatch(texts []string, targetLanguage, userPrompt string) ([]string, error) {
+	results, pending := p.checkBatchCache(texts, targetLanguage, userPrompt)
+
+	for start := 0; start < len(pending); {
+		end, size := start, 0
+		for end < len(pending) && (end == start || size+len([]rune(texts[pending[end]])) <= maxYandexBatchChars) {
+			size += len([]rune(texts[pending[end]]))
+			end++
+		}
+
+		batch := make([]string, 0, end-start)
+		for _, i := range pending[start:end] {
+			batch = append(batch, texts[i])
+		}
+
+		translated, err := p.requestTranslations(batch, targetLanguage)
+		if err != nil {
+			return nil, err
+		}
+		for j, i := range pending[start:end] {
+			results[i] = translated[j]
+			p.saveCache(texts[i], targetLanguage, userPrompt, translated[j])
+		}
+		start = end
+	}
+
+	return results, nil
+}
+
+// requestTranslations 发送一次翻译请求
+func (p *YandexProvider) requestTranslations(texts []string, targetLanguage string) ([]string, error) {
+	reqBody := map[string]interface{}{
+		"texts":              texts,
+		"targetLanguageCode": mapToYandexLanguageCode(targetLanguage),
+		"format":             "PLAIN_TEXT",
+	}
+	if p.Config.Extra != nil {
+		if source := p.Config.Extra["sourceLanguage"]; source != "" {
+			reqBody["sourceLanguageCode"] = mapToYandexLanguageCode(source)
+		}
+		if folderID := p.Config.Extra["folderId"]; folderID != "" {
+			reqBody["folderId"] = folderID
+		}
+	}
+
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	apiURL := p.Config.APIURL
+	if apiURL == "" {
+		apiURL = defaultYandexURL
+	}
+
+	req, err := p.newRequest("POST", apiURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if p.Config.Extra != nil && p.Config.Extra["authType"] == "iam" {
+		req.Header.Set("Authorization", "Bearer "+p.Config.APIKey)
+	} else {
+		req.Header.Set("Authorization", "Api-Key "+p.Config.APIKey)
+	}
+
+	body, err := p.doRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Translations []struct {
+			Text string `json:"text"`
+		} `json:"translations"`
+		Message string `json:"message,omitempty"`
+	}
+
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %w", err)
+	}
+
+	if resp.Message != "" {
+		return nil, fmt.Errorf("翻译错误: %s", resp.Message)
+	}
+
+	if len(resp.Translations) != len(texts) {
+		return nil, fmt.Errorf("API 返回的翻译数量不匹配: 期望 %d，实际 %d", len(texts), len(resp.Translations))
+	}
+
+	results := make([]string, len(texts))
+	for i, t := range resp.Translations {
+		results[i] = t.Text
+	}
+	return results, nil
+}
+
+// mapToYandexLanguageCode 将常见语言名称映射到 Yandex 语言代码（ISO 639-1）
+func mapToYandexLanguageCode(language string) string {
+	return mapToLibreTranslateLanguageCode(language)
+}
@@ -0,0 +1,110 @@
+package translator
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// yandexRequest Yandex 桩服务收到的请求
+type yandexRequest struct {
+	Authorization      string
+	Texts              []string `json:"texts"`
+	TargetLanguageCode string   `json:"targetLanguageCode"`
+	SourceLanguageCode string   `json:"sourceLanguageCode"`
+	FolderID           string   `json:"folderId"`
+}
+
+// newYandexStub 启动 Yandex Translate 桩服务，每段文本译为 "[目标语言] 原文"
+func newYandexStub(t *testing.T) (*httptest.Server, func() []yandexRequest) {
+	t.Helper()
+	var mu sync.Mutex
+	var requests []yandexRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req yandexRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		req.Authorization = r.Header.Get("Authorization")
+		mu.Lock()
+		requests = append(requests, req)
+		mu.Unlock()
+
+		var resp struct {
+			Translations []map[string]string `json:"translations"`
+		}
+		for _, text := range req.Texts {
+			resp.Translations = append(resp.Translations, map[string]string{"text": "[" + req.TargetLanguageCode + "] " + text})
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(server.Close)
+	return server, func() []yandexRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]yandexRequest(nil), requests...)
+	}
+}
+
+func TestYandexProviderRequest(t *testing.T) {
+	server, requests := newYandexStub(t)
+	provider, err := NewProvider(ProviderConfig{Type: ProviderYandex, APIURL: server.URL, APIKey: "yandex-key"}, NewMemoryCache())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := provider.Translate("Hello", "Japanese", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "[ja] Hello" {
+		t.Errorf("Translate = %q", got)
+	}
+	req := requests()[0]
+	if req.Authorization != "Api-Key yandex-key" {
+		t.Errorf("Authorization = %q", req.Authorization)
+	}
+	if req.TargetLanguageCode != "ja" || len(req.Texts) != 1 || req.Texts[0] != "Hello" {
+		t.Errorf("请求 = %+v", req)
+	}
+
+	// 批量翻译时已缓存的文本不再发送，其余文本合并为一次请求
+	results, err := provider.(BatchProvider).TranslateBatch([]string{"Hello", "Good morning", "Thank you"}, "Japanese", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(results, "|") != "[ja] Hello|[ja] Good morning|[ja] Thank you" {
+		t.Errorf("TranslateBatch = %q", results)
+	}
+	all := requests()
+	if len(all) != 2 || strings.Join(all[1].Texts, "|") != "Good morning|Thank you" {
+		t.Errorf("批量请求 = %+v", all)
+	}
+}
+
+func TestYandexProviderIAMAuth(t *testing.T) {
+	server, requests := newYandexStub(t)
+	provider, err := NewProvider(ProviderConfig{
+		Type:   ProviderYandex,
+		APIURL: server.URL,
+		APIKey: "iam-token",
+		Extra:  map[string]string{"authType": "iam", "folderId": "b1g-folder", "sourceLanguage": "Uni"},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := provider.Translate("你好", "English", ""); err != nil {
+		t.Fatal(err)
+	}
+	req := requests()[0]
+	if req.Authorization != "Bearer iam-token" {
+		t.Errorf("Authorization = %q", req.Authorization)
+	}
+	if req.FolderID != "b1g-folder" || req.SourceLanguageCode != "zh" || req.TargetLanguageCode != "en" {
+		t.Errorf("请求 = %+v", req)
+	}
+}
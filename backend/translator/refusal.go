@@ -0,0 +1,102 @@
+package translator
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// ErrRefusal 翻译服务因内容策略拒绝翻译，返回的是拒绝说明而不是译文
+var ErrRefusal = errors.New("翻译服务拒绝翻译该内容")
+
+// DefaultRefusalPatterns 常见的拒绝回复（只匹配回复开头，避免误判正文中的类似句子）
+var DefaultRefusalPatterns = []string{
+	`(?i)^(i'm|i am|i’m)?\s*sorry,?\s*(but\s+)?i\s+(can't|can’t|cannot|can not|won't|am unable to|am not able to)\b`,
+	`(?i)^i\s+(can't|can’t|cannot|can not|won't|am unable to|am not able to)\s+(assist|help|translate|comply|provide|fulfill)`,
+	`(?i)^as an ai( language model)?\b`,
+	`^(很)?(抱歉|对不起)[，,]?\s*(但)?我(无法|不能|没法)`,
+	`^我(无法|不能)(协助|帮助|翻译|提供|满足)`,
+}
+
+var (
+	refusalPatternsOnce sync.Once
+	refusalPatterns     []*regexp.Regexp
+)
+
+// loadRefusalPatterns 编译默认规则，并追加环境变量 REFUSAL_PATTERNS 指定文件中的规则（每行一个正则表达式）
+func loadRefusalPatterns() []*regexp.Regexp {
+	refusalPatternsOnce.Do(func() {
+		patterns := append([]string(nil), DefaultRefusalPatterns...)
+		if path := os.Getenv("REFUSAL_PATTERNS"); path != "" {
+			extra, err := readRefusalPatterns(path)
+			if err != nil {
+				log.Printf("警告：读取拒绝回复规则失败: %v，仅使用默认规则", err)
+			}
+			patterns = append(patterns, extra...)
+		}
+
+		for _, pattern := range patterns {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				log.Printf("警告：拒绝回复规则 %q 无效: %v", pattern, err)
+				continue
+			}
+			refusalPatterns = append(refusalPatterns, re)
+		}
+	})
+	return refusalPatterns
+}
+
+// readRefusalPatterns 读取规则文件，忽略空行和 # 开头的注释行
+func readRefusalPatterns(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var patterns []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	return patterns, scanner.Err()
+}
+
+// IsRefusal 判断翻译服务的回复是否为拒绝翻译的说明
+func IsRefusal(text string) bool {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return false
+	}
+	for _, re := range loadRefusalPatterns() {
+		if re.MatchString(text) {
+			return true
+		}
+	}
+	return false
+}
+
+// isRefusalFor 判断 result 是否为针对 source 的拒绝回复
+// 原文本身就像拒绝说明（如小说对白）时，译文相似是正常的，不视为拒绝
+func isRefusalFor(source, result string) bool {
+	return IsRefusal(result) && !IsRefusal(source)
+}
+
+// refusalError 生成包含拒绝内容摘要的错误
+func refusalError(result string) error {
+	summary := []rune(strings.TrimSpace(result))
+	if len(summary) > 80 {
+		summary = append(summary[:80], '…')
+	}
+	return fmt.Errorf("%w: %s", ErrRefusal, string(summary))
+}
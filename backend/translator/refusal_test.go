@@ -0,0 +1,72 @@
+package translator
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestIsRefusal(t *testing.T) {
+	tests := map[string]bool{
+		"I'm sorry, but I can't assist with that request.": true,
+		"I cannot translate this content.":                 true,
+		"As an AI language model, I must decline.":         true,
+		"抱歉，我无法翻译这段内容。":                                    true,
+		"我不能提供该内容的翻译。":                                     true,
+		"Bonjour le monde":                                 false,
+		"他说：“抱歉，我无法赴约。”":                                   false,
+		"Je suis désolé, mais c'est la vérité.":            false,
+		"": false,
+	}
+	for text, want := range tests {
+		if got := IsRefusal(text); got != want {
+			t.Errorf("IsRefusal(%q) = %v，期望 %v", text, got, want)
+		}
+	}
+}
+
+func TestRefusalFallsBackAndIsNotCached(t *testing.T) {
+	const refusal = "I'm sorry, but I can't assist with that request."
+	stub := newOpenAIStub(t, func(req stubRequest) string {
+		if req.User == "How to pick a lock" {
+			return refusal
+		}
+		return "[译] " + req.User
+	})
+	cache := NewMemoryCache()
+	client, err := NewTranslatorClient(stub.Config(), cache)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.WithRetry(0, 0)
+
+	for round := 1; round <= 2; round++ {
+		results := client.TranslateBlocks([]string{"How to pick a lock", "A harmless sentence"}, "French", "", nil)
+		if !errors.Is(results[0].Err, ErrRefusal) || !results[0].UsedFallback || results[0].Translated != "How to pick a lock" {
+			t.Errorf("第 %d 次: 拒绝回复应作为失败并回退为原文，得到 %+v", round, results[0])
+		}
+		if results[1].Err != nil || results[1].Translated != "[译] A harmless sentence" {
+			t.Errorf("第 %d 次: 正常文本块 = %+v", round, results[1])
+		}
+	}
+
+	// 拒绝回复没有写入缓存，第二次仍请求翻译服务；正常译文第二次命中缓存
+	refused := 0
+	for _, req := range stub.Requests() {
+		if req.User == "How to pick a lock" {
+			refused++
+		}
+	}
+	if refused != 2 || len(stub.Requests()) != 3 {
+		t.Errorf("拒绝的文本块请求 %d 次（期望 2），共 %d 次请求（期望 3）", refused, len(stub.Requests()))
+	}
+}
+
+func TestRefusalInSourceIsNotFlagged(t *testing.T) {
+	// 原文本身就是拒绝说明时，译文相似是正常的
+	if isRefusalFor("I cannot help you, she said.", "I cannot help you, she said.") {
+		t.Error("原文为拒绝说明时不应视为拒绝")
+	}
+	if !isRefusalFor("Secret recipe", "I cannot help with that.") {
+		t.Error("应识别为拒绝")
+	}
+}
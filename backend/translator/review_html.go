@@ -0,0 +1,109 @@
+package translator
+
+import (
+	"html"
+	"strings"
+)
+
+// reviewStyle 校对页面在 htmlStyle 之外的样式
+const reviewStyle = `
+.review .block { display: grid; grid-template-columns: 1fr 1fr; gap: 1.5rem; padding-bottom: 1.25rem; border-bottom: 1px solid #eaeef2; }
+.review .translation { padding: 0.25rem 0.5rem; border: 1px dashed #d0d7de; border-radius: 4px; }
+.review .translation:focus { outline: 2px solid #0969da; border-color: transparent; }
+.review .translation.edited { background: #fff8c5; }
+.toolbar { position: sticky; top: 0; display: flex; gap: 1rem; align-items: center; padding: 0.75rem 0; background: inherit; }
+.toolbar button { font: inherit; padding: 0.4rem 1.25rem; cursor: pointer; }
+.toolbar span { color: #656d76; font-size: 0.875rem; }
+@media (max-width: 48rem) {
+  .review .block { grid-template-columns: 1fr; gap: 0.4rem; }
+}
+@media (prefers-color-scheme: dark) {
+  .review .block { border-color: #21262d; }
+  .review .translation { border-color: #30363d; }
+  .review .translation.edited { background: #3b2e00; }
+}
+`
+
+// reviewScript 收集修改过的译文，以 {blockId: 译文} 提交到 data-edits-url
+const reviewScript = `
+(function () {
+  var status = document.getElementById('status');
+  var cells = document.querySelectorAll('.translation[data-block-id]');
+  cells.forEach(function (cell) {
+    cell.dataset.initial = cell.innerText;
+    cell.addEventListener('input', function () {
+      cell.classList.toggle('edited', cell.innerText !== cell.dataset.initial);
+    });
+  });
+  document.getElementById('save').addEventListener('click', function () {
+    var edits = {};
+    var count = 0;
+    cells.forEach(function (cell) {
+      if (cell.innerText !== cell.dataset.initial) {
+        edits[cell.dataset.blockId] = cell.innerText;
+        count++;
+      }
+    });
+    if (count === 0) {
+      status.textContent = '没有修改';
+      return;
+    }
+    status.textContent = '正在保存 ' + count + ' 处修改…';
+    fetch(document.body.dataset.editsUrl, {
+      method: 'POST',
+      credentials: 'same-origin',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify(edits)
+    }).then(function (resp) {
+      return resp.json().then(function (data) {
+        if (!resp.ok) {
+          throw new Error(data.error || resp.statusText);
+        }
+        cells.forEach(function (cell) {
+          cell.dataset.initial = cell.innerText;
+          cell.classList.remove('edited');
+        });
+        status.textContent = '已保存 ' + count + ' 处修改，正在重新生成输出';
+      });
+    }).catch(function (err) {
+      status.textContent = '保存失败: ' + err.message;
+    });
+  });
+})();
+`
+
+// RenderReviewHTML 生成可编辑的左右对照校对页面：每个待翻译文本块一行，译文可直接编辑
+// 同一文本块在对齐数据中的多个文本元素只显示一次，以第一个元素的 ID 提交；
+// 点击保存时将修改过的译文以 {blockId: 译文} 的 JSON 提交到 editsURL
+func RenderReviewHTML(title string, entries []AlignmentEntry, editsURL string) string {
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html>\n<head>\n")
+	b.WriteString("<meta charset=\"utf-8\">\n")
+	b.WriteString("<meta name=\"viewport\" content=\"width=device-width, initial-scale=1\">\n")
+	b.WriteString("<title>" + html.EscapeString(title) + "</title>\n")
+	b.WriteString("<style>" + htmlStyle + reviewStyle + "</style>\n")
+	b.WriteString("</head>\n")
+
+	b.WriteString("<body class=\"review\" data-edits-url=\"" + html.EscapeString(editsURL) + "\">\n<header>\n")
+	b.WriteString("<h1>" + html.EscapeString(title) + "</h1>\n")
+	b.WriteString("<p>左侧为原文，右侧译文可直接编辑，修改后点击保存</p>\n")
+	b.WriteString("<div class=\"toolbar\"><button id=\"save\" type=\"button\">保存</button><span id=\"status\"></span></div>\n")
+	b.WriteString("</header>\n<main>\n")
+
+	seen := make(map[string]bool)
+	for _, entry := range entries {
+		if entry.Source == "" || seen[entry.Source] {
+			continue
+		}
+		seen[entry.Source] = true
+
+		b.WriteString("<section class=\"block\">\n")
+		b.WriteString("<p class=\"original\">" + html.EscapeString(entry.Source) + "</p>\n")
+		b.WriteString("<p class=\"translation\" contenteditable=\"true\" data-block-id=\"" + html.EscapeString(entry.BlockID) + "\">" +
+			html.EscapeString(entry.Translated) + "</p>\n")
+		b.WriteString("</section>\n")
+	}
+
+	b.WriteString("</main>\n<script>" + reviewScript + "</script>\n</body>\n</html>\n")
+	return b.String()
+}
@@ -0,0 +1,209 @@
+package translator
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// 页眉页脚类型
+const (
+	RunningHeader = "header"
+	RunningFooter = "footer"
+)
+
+const (
+	runningZoneRatio  = 0.1 // 页面顶部/底部多大比例的区域视为页眉/页脚区域
+	runningMinPages   = 3   // 至少在多少页重复出现才视为页眉页脚
+	runningYTolerance = 3.0 // 各页位置允许的纵向偏差（pt）
+)
+
+// runningDigitPattern 页眉页脚中随页变化的数字（页码、卷期号）
+var runningDigitPattern = regexp.MustCompile(`\d+`)
+
+// RunningElementKey 页眉页脚的比较键：规范化文本、忽略大小写，并将数字视为相同
+// 例如 "Journal of Physics 12" 和 "Journal of Physics 13" 的键相同
+func RunningElementKey(text string) string {
+	return runningDigitPattern.ReplaceAllString(strings.ToLower(NormalizeText(text)), "#")
+}
+
+// runningCandidate 位于页面顶部或底部区域的文本
+type runningCandidate struct {
+	text string
+	page int
+	y    float64
+	zone string // RunningHeader 或 RunningFooter
+}
+
+// detectRunning 找出在至少 runningMinPages 页的相近位置重复出现的文本，返回 文本 -> 类型
+func detectRunning(candidates []runningCandidate) map[string]string {
+	groups := make(map[string][]runningCandidate)
+	for _, c := range candidates {
+		key := c.zone + "|" + RunningElementKey(c.text)
+		if strings.Trim(key[len(c.zone)+1:], "# ") == "" {
+			continue // 纯页码由文本块过滤规则处理
+		}
+		groups[key] = append(groups[key], c)
+	}
+
+	running := make(map[string]string)
+	for _, group := range groups {
+		ys := make([]float64, len(group))
+		for i, c := range group {
+			ys[i] = c.y
+		}
+		sort.Float64s(ys)
+		median := ys[len(ys)/2]
+
+		pages := make(map[int]bool)
+		for _, c := range group {
+			if math.Abs(c.y-median) <= runningYTolerance {
+				pages[c.page] = true
+			}
+		}
+		if len(pages) < runningMinPages {
+			continue
+		}
+		for _, c := range group {
+			if pages[c.page] && math.Abs(c.y-median) <= runningYTolerance {
+				running[c.text] = c.zone
+			}
+		}
+	}
+	return running
+}
+
+// runningZone 判断纵坐标（PDF 坐标系，原点在左下角）是否位于页眉或页脚区域
+func runningZone(y, bottom, top float64) string {
+	height := top - bottom
+	if height <= 0 {
+		return ""
+	}
+	switch {
+	case y >= top-height*runningZoneRatio:
+		return RunningHeader
+	case y <= bottom+height*runningZoneRatio:
+		return RunningFooter
+	}
+	return ""
+}
+
+// DetectRunningElements 识别各页重复出现的页眉页脚，返回 文本元素内容 -> "header"/"footer"
+// 页眉页脚位于页面顶部或底部，在多页的相近位置出现且文本只有数字（页码等）不同
+func (tc *TextClusterer) DetectRunningElements(pages []*PDFPageFlow) map[string]string {
+	return detectRunning(pageRunningCandidates(pages))
+}
+
+// pageRunningCandidates 收集页面顶部和底部区域的文本元素，供识别页眉页脚使用
+func pageRunningCandidates(pages []*PDFPageFlow) []runningCandidate {
+	var candidates []runningCandidate
+	for _, page := range pages {
+		bottom, top := page.MediaBox.Y, page.MediaBox.Y+page.MediaBox.Height
+		for _, elem := range page.TextElements {
+			if strings.TrimSpace(elem.Content) == "" {
+				continue
+			}
+			if zone := runningZone(elem.Position.Y, bottom, top); zone != "" {
+				candidates = append(candidates, runningCandidate{text: elem.Content, page: page.PageNumber, y: elem.Position.Y, zone: zone})
+			}
+		}
+	}
+	return candidates
+}
+
+// DetectRunningBlocks 识别解析结果中的页眉页脚，返回 文本块内容 -> "header"/"footer"
+// 解析结果不含页面尺寸，以所有文本块的纵向范围近似页面范围
+func DetectRunningBlocks(content *PDFContent) map[string]string {
+	if content == nil || len(content.TextBlocks) == 0 {
+		return nil
+	}
+
+	bottom, top := math.Inf(1), math.Inf(-1)
+	for _, block := range content.TextBlocks {
+		bottom = math.Min(bottom, block.Y)
+		top = math.Max(top, block.Y)
+	}
+
+	var candidates []runningCandidate
+	for _, block := range content.TextBlocks {
+		if strings.TrimSpace(block.Text) == "" {
+			continue
+		}
+		if zone := runningZone(block.Y, bottom, top); zone != "" {
+			candidates = append(candidates, runningCandidate{text: block.Text, page: block.PageNum, y: block.Y, zone: zone})
+		}
+	}
+	return detectRunning(candidates)
+}
+
+// ReuseRunningTranslations 为尚无译文的页眉页脚补全译文
+// 同一页眉页脚只需翻译一次，其他页的变体（如页码不同）复用该译文并替换其中的数字
+func ReuseRunningTranslations(running map[string]string, translations map[string]string) map[string]string {
+	if len(running) == 0 {
+		return translations
+	}
+
+	translated := make(map[string]string) // 比较键 -> 已翻译的原文
+	for text := range running {
+		if _, ok := translations[text]; ok {
+			key := RunningElementKey(text)
+			if existing, ok := translated[key]; !ok || text < existing {
+				translated[key] = text
+			}
+		}
+	}
+
+	for text := range running {
+		if _, ok := translations[text]; ok {
+			continue
+		}
+		source, ok := translated[RunningElementKey(text)]
+		if !ok {
+			continue
+		}
+		translations[text] = replaceRunningDigits(translations[source], source, text)
+	}
+	return translations
+}
+
+// replaceRunningDigits 将译文中来自 source 的数字依次替换为 target 中对应的数字
+func replaceRunningDigits(translation, source, target string) string {
+	from := runningDigitPattern.FindAllString(source, -1)
+	to := runningDigitPattern.FindAllString(target, -1)
+	if len(from) != len(to) {
+		return translation
+	}
+
+	var b strings.Builder
+	rest := translation
+	for i := range from {
+		idx := strings.Index(rest, from[i])
+		if idx < 0 {
+			continue
+		}
+		b.WriteString(rest[:idx])
+		b.WriteString(to[i])
+		rest = rest[idx+len(from[i]):]
+	}
+	b.WriteString(rest)
+	return b.String()
+}
+
+// runningVariants 返回除每组第一个出现的文本外的页眉页脚变体，这些文本无需单独翻译
+func runningVariants(texts []string, running map[string]string) map[string]bool {
+	variants := make(map[string]bool)
+	first := make(map[string]string) // 比较键 -> 第一个出现的文本
+	for _, text := range texts {
+		if _, ok := running[text]; !ok {
+			continue
+		}
+		key := RunningElementKey(text)
+		if firstText, ok := first[key]; !ok {
+			first[key] = text
+		} else if text != firstText {
+			variants[text] = true
+		}
+	}
+	return variants
+}
@@ -0,0 +1,79 @@
+package translator
+
+import (
+	"fmt"
+	"testing"
+)
+
+// runningTestPages 生成 count 页 Letter 尺寸的页面，每页包含页眉、正文和页脚
+func runningTestPages(count int) []*PDFPageFlow {
+	pages := make([]*PDFPageFlow, count)
+	for i := range pages {
+		n := i + 1
+		pages[i] = &PDFPageFlow{
+			PageNumber: n,
+			MediaBox:   BoundingBox{Width: 612, Height: 792},
+			TextElements: []TextElementFlow{
+				{Content: "Journal of Applied Physics", Position: PositionFlow{X: 72, Y: 760 + float64(n%2)}},
+				{Content: fmt.Sprintf("Body paragraph %d discusses the results.", n), Position: PositionFlow{X: 72, Y: 400}},
+				{Content: fmt.Sprintf("Page %d of %d", n, count), Position: PositionFlow{X: 280, Y: 30}},
+			},
+		}
+	}
+	return pages
+}
+
+func TestDetectRunningElements(t *testing.T) {
+	running := NewTextClusterer().DetectRunningElements(runningTestPages(5))
+
+	if running["Journal of Applied Physics"] != RunningHeader {
+		t.Errorf("页眉未识别: %v", running)
+	}
+	for n := 1; n <= 5; n++ {
+		if footer := fmt.Sprintf("Page %d of 5", n); running[footer] != RunningFooter {
+			t.Errorf("页脚 %q 未识别: %v", footer, running)
+		}
+		if body := fmt.Sprintf("Body paragraph %d discusses the results.", n); running[body] != "" {
+			t.Errorf("正文 %q 被识别为 %s", body, running[body])
+		}
+	}
+}
+
+func TestDetectRunningElementsNeedsRepetition(t *testing.T) {
+	running := NewTextClusterer().DetectRunningElements(runningTestPages(2))
+	if len(running) != 0 {
+		t.Errorf("只出现在 %d 页的文本不应视为页眉页脚: %v", 2, running)
+	}
+
+	// 位置不一致的文本不是页眉
+	pages := runningTestPages(5)
+	for i, page := range pages {
+		page.TextElements[0].Position.Y = 720 + float64(i*10)
+	}
+	if kind := NewTextClusterer().DetectRunningElements(pages)["Journal of Applied Physics"]; kind != "" {
+		t.Errorf("位置不一致的文本被识别为 %s", kind)
+	}
+}
+
+func TestReuseRunningTranslations(t *testing.T) {
+	running := map[string]string{
+		"Page 1 of 5": RunningFooter,
+		"Page 2 of 5": RunningFooter,
+		"Page 3 of 5": RunningFooter,
+	}
+	translations := ReuseRunningTranslations(running, map[string]string{"Page 1 of 5": "第 1 页，共 5 页"})
+	for text, want := range map[string]string{
+		"Page 1 of 5": "第 1 页，共 5 页",
+		"Page 2 of 5": "第 2 页，共 5 页",
+		"Page 3 of 5": "第 3 页，共 5 页",
+	} {
+		if got := translations[text]; got != want {
+			t.Errorf("%q 的译文 = %q，期望 %q", text, got, want)
+		}
+	}
+
+	variants := runningVariants([]string{"Page 1 of 5", "Body", "Page 2 of 5", "Page 3 of 5"}, running)
+	if len(variants) != 2 || variants["Page 1 of 5"] || !variants["Page 2 of 5"] {
+		t.Errorf("runningVariants = %v", variants)
+	}
+}
@@ -0,0 +1,188 @@
+package translator
+
+import (
+	"strings"
+	"unicode"
+)
+
+// SentenceAligner 句子级对齐器，用于交错双语输出
+type SentenceAligner struct {
+	MaxCountDiff int // 允许的原文/译文句子数差异，超出时回退到段落级交错
+
+	SourceLanguage string // 原文语言，用于按语言断句，为空时使用通用规则
+	TargetLanguage string // 译文语言
+}
+
+// NewSentenceAligner 创建句子对齐器
+func NewSentenceAligner() *SentenceAligner {
+	return &SentenceAligner{
+		MaxCountDiff: 1,
+	}
+}
+
+// WithLanguages 设置原文和译文的语言
+func (a *SentenceAligner) WithLanguages(sourceLanguage, targetLanguage string) *SentenceAligner {
+	a.SourceLanguage = sourceLanguage
+	a.TargetLanguage = targetLanguage
+	return a
+}
+
+// Align 将原文与译文按句子交错排列，返回交错后的行
+// 句子数大致相同时逐句交错，否则回退为整段原文加整段译文
+func (a *SentenceAligner) Align(original, translation string) []string {
+	originalSentences := splitSentences(original, a.SourceLanguage)
+	translatedSentences := splitSentences(translation, a.TargetLanguage)
+
+	if !a.canAlign(originalSentences, translatedSentences) {
+		return []string{strings.TrimSpace(original), strings.TrimSpace(translation)}
+	}
+
+	// 句子数不一致时，多出的句子合并到最后一对
+	pairs := len(originalSentences)
+	if len(translatedSentences) < pairs {
+		pairs = len(translatedSentences)
+	}
+
+	lines := make([]string, 0, pairs*2)
+	for i := 0; i < pairs; i++ {
+		originalLine := originalSentences[i]
+		translatedLine := translatedSentences[i]
+		if i == pairs-1 {
+			originalLine = strings.Join(originalSentences[i:], " ")
+			translatedLine = strings.Join(translatedSentences[i:], " ")
+		}
+		lines = append(lines, originalLine, translatedLine)
+	}
+
+	return lines
+}
+
+// Interleave 返回以换行分隔的交错双语文本
+func (a *SentenceAligner) Interleave(original, translation string) string {
+	return strings.Join(a.Align(original, translation), "\n")
+}
+
+// canAlign 判断句子数是否足够接近以进行逐句对齐
+func (a *SentenceAligner) canAlign(originalSentences, translatedSentences []string) bool {
+	if len(originalSentences) <= 1 || len(translatedSentences) <= 1 {
+		return false
+	}
+
+	diff := len(originalSentences) - len(translatedSentences)
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff <= a.MaxCountDiff
+}
+
+// sentenceAbbreviations 常见缩写（小写，不含末尾句点），其后的句点不视为句末
+// "" 为所有语言通用的缩写（学术文档中常见的英文缩写），其余按语言代码追加
+var sentenceAbbreviations = map[string][]string{
+	"": {"dr", "mr", "mrs", "ms", "prof", "sr", "jr", "st", "vs", "e.g", "i.e", "cf", "al",
+		"fig", "figs", "eq", "eqs", "tab", "sec", "ch", "ref", "refs", "no", "vol", "pp", "approx"},
+	"de": {"z.b", "d.h", "bzw", "ca", "vgl", "nr", "abb", "hr", "fr"},
+	"fr": {"m", "mme", "mlle", "env", "p"},
+	"es": {"sra", "srta", "p.ej", "pág", "núm"},
+	"it": {"sig", "sig.ra", "dott", "pag"},
+	"pt": {"sra", "pág", "núm"},
+}
+
+// sentenceClosers 句末标点之后仍属于该句的字符（引号和右括号）
+const sentenceClosers = "\"'”’」』）)]》"
+
+// splitSentences 将文本分割为句子，language 为文本的语言（名称或代码，为空时按通用规则）
+// 中日韩句末标点（。！？）直接断句；英文句末标点只在其后为空白或文本结尾时断句，
+// 所以小数（3.14）和文件名不会被拆开，常见缩写（Dr.、Fig.、et al.）和单个大写字母的姓名缩写之后也不断句
+func splitSentences(text, language string) []string {
+	abbreviations := abbreviationsFor(language)
+	cjk := isCJKLanguage(sentenceLanguageCode(language))
+	runes := []rune(text)
+
+	var sentences []string
+	start := 0
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if !isSentenceTerminator(r) {
+			continue
+		}
+
+		end := sentenceEnd(runes, i+1)
+		if r == '.' || r == '!' || r == '?' {
+			// 英文标点之后需为空白或文本结尾（中日韩文本中也可以直接接中日韩文字）
+			if end < len(runes) && !unicode.IsSpace(runes[end]) && !(cjk && isCJKRune(runes[end])) {
+				continue
+			}
+			if r == '.' && isAbbreviation(runes[start:i], abbreviations) {
+				continue
+			}
+		}
+
+		if sentence := strings.TrimSpace(string(runes[start:end])); sentence != "" {
+			sentences = append(sentences, sentence)
+		}
+		start = end
+		i = end - 1
+	}
+
+	// 添加剩余部分
+	if sentence := strings.TrimSpace(string(runes[start:])); sentence != "" {
+		sentences = append(sentences, sentence)
+	}
+
+	return sentences
+}
+
+// isSentenceTerminator 判断字符是否为句末标点
+func isSentenceTerminator(r rune) bool {
+	switch r {
+	case '.', '!', '?', '。', '！', '？':
+		return true
+	}
+	return false
+}
+
+// sentenceEnd 返回句末标点之后连续的句末标点、引号和右括号结束的位置
+func sentenceEnd(runes []rune, i int) int {
+	for i < len(runes) && (isSentenceTerminator(runes[i]) || strings.ContainsRune(sentenceClosers, runes[i])) {
+		i++
+	}
+	return i
+}
+
+// isAbbreviation 判断句点前的单词是否为缩写：在缩写表中，或为单个大写字母（姓名缩写）
+func isAbbreviation(before []rune, abbreviations map[string]bool) bool {
+	i := len(before)
+	for i > 0 && (unicode.IsLetter(before[i-1]) || before[i-1] == '.') {
+		i--
+	}
+	word := before[i:]
+	if len(word) == 0 {
+		return false
+	}
+	if len(word) == 1 && unicode.IsUpper(word[0]) {
+		return true
+	}
+	return abbreviations[strings.ToLower(string(word))]
+}
+
+// abbreviationsFor 返回语言适用的缩写集合（通用缩写加该语言的缩写）
+func abbreviationsFor(language string) map[string]bool {
+	set := make(map[string]bool)
+	for _, list := range [][]string{sentenceAbbreviations[""], sentenceAbbreviations[sentenceLanguageCode(language)]} {
+		for _, abbr := range list {
+			set[abbr] = true
+		}
+	}
+	return set
+}
+
+// sentenceLanguageCode 将语言名称或代码转换为不含地区的小写语言代码（如 "Uni"、"zh-TW" -> "zh"）
+func sentenceLanguageCode(language string) string {
+	code := mapToLibreTranslateLanguageCode(strings.TrimSpace(language))
+	return strings.ToLower(strings.SplitN(code, "-", 2)[0])
+}
+
+// isCJKRune 判断字符是否为中日韩文字
+func isCJKRune(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
@@ -0,0 +1,69 @@
+package translator
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSentenceAlignerInterleavesSentences(t *testing.T) {
+	original := "The sun rose. Birds began to sing. The day had started."
+	translation := "太阳升起了。鸟儿开始歌唱。新的一天开始了。"
+
+	got := NewSentenceAligner().WithLanguages("en", "zh").Align(original, translation)
+	want := []string{
+		"The sun rose.", "太阳升起了。",
+		"Birds began to sing.", "鸟儿开始歌唱。",
+		"The day had started.", "新的一天开始了。",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Align =\n%q\n期望\n%q", got, want)
+	}
+}
+
+func TestSentenceAlignerFallsBackToParagraphs(t *testing.T) {
+	original := "One. Two. Three. Four."
+	translation := "一二三四。"
+
+	got := NewSentenceAligner().WithLanguages("en", "zh").Align(original, translation)
+	want := []string{original, translation}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("句子数相差过多时应整段交错，得到 %q", got)
+	}
+}
+
+func TestSentenceAlignerMergesExtraSentences(t *testing.T) {
+	original := "First point. Second point. Third point."
+	translation := "第一点。第二点和第三点。"
+
+	got := NewSentenceAligner().WithLanguages("en", "zh").Align(original, translation)
+	want := []string{
+		"First point.", "第一点。",
+		"Second point. Third point.", "第二点和第三点。",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("多出的句子应合并到最后一对，得到 %q", got)
+	}
+}
+
+func TestSplitSentences(t *testing.T) {
+	tests := []struct {
+		text, language string
+		want           []string
+	}{
+		{"Dr. Smith went home.", "en", []string{"Dr. Smith went home."}},
+		{"他来了。她走了。", "zh", []string{"他来了。", "她走了。"}},
+		{"The value is 3.14 exactly.", "en", []string{"The value is 3.14 exactly."}},
+		{"See Fig. 2 and Smith et al. for details. It works!", "", []string{"See Fig. 2 and Smith et al. for details.", "It works!"}},
+		{"J. R. R. Tolkien wrote it. Really?", "en", []string{"J. R. R. Tolkien wrote it.", "Really?"}},
+		{`He said "stop." Then he left.`, "en", []string{`He said "stop."`, "Then he left."}},
+		{"Das ist z.B. ein Test. Gut.", "German", []string{"Das ist z.B. ein Test.", "Gut."}},
+		{"これはペンです。あれは本ですか？はい！", "ja", []string{"これはペンです。", "あれは本ですか？", "はい！"}},
+		{"版本为 v2.0。下一句。", "Uni", []string{"版本为 v2.0。", "下一句。"}},
+		{"", "en", nil},
+	}
+	for _, tt := range tests {
+		if got := splitSentences(tt.text, tt.language); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitSentences(%q, %q) = %q，期望 %q", tt.text, tt.language, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package translator
+
+import (
+	"log"
+	"sync"
+)
+
+// SourceCache 同一文档翻译成多种目标语言时共享的解析结果
+// 文档的验证、打开、PDF 文本解析和语言检测各只执行一次，之后的目标语言直接使用缓存；
+// 各目标语言使用相同的翻译配置并依次翻译，不能并发使用同一个 SourceCache。
+// 方法的接收者为 nil 时不缓存，直接执行对应的操作
+type SourceCache struct {
+	mu        sync.Mutex
+	validated map[string]string      // 输入路径 -> 验证（必要时修复）后的路径
+	documents map[string]*sourceDoc  // 路径 -> 打开的文档
+	contents  map[string]*PDFContent // 路径 -> PDF 解析结果
+	languages map[string]string      // 路径 -> 检测到的主要语言
+	filter    *BlockFilter           // 各目标语言共用的文本块过滤器
+	parses    int                    // 实际打开或解析文档的次数
+}
+
+// sourceDoc 已打开的文档
+type sourceDoc struct {
+	doc     Document
+	docType DocumentType
+}
+
+// NewSourceCache 创建共享解析结果
+func NewSourceCache() *SourceCache {
+	return &SourceCache{
+		validated: make(map[string]string),
+		documents: make(map[string]*sourceDoc),
+		contents:  make(map[string]*PDFContent),
+		languages: make(map[string]string),
+	}
+}
+
+// Parses 返回实际打开或解析文档的次数（同一文档的每种解析方式各计一次）
+func (s *SourceCache) Parses() int {
+	if s == nil {
+		return 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.parses
+}
+
+// validate 验证文档，返回可用的路径（PDF 修复后为修复后的文件）
+func (s *SourceCache) validate(path string, validate func(string) (string, error)) (string, error) {
+	if s == nil {
+		return validate(path)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if validated, ok := s.validated[path]; ok {
+		return validated, nil
+	}
+	validated, err := validate(path)
+	if err != nil {
+		return "", err
+	}
+	s.validated[path] = validated
+	return validated, nil
+}
+
+// open 打开文档，已打开过的文档直接返回
+// PDF 只读取不修改，可插入译文的文档需支持快照，插入译文并保存后恢复原内容（见 shared）；其余文档每次重新打开
+func (s *SourceCache) open(path string) (Document, DocumentType, error) {
+	if s == nil {
+		return OpenDocument(path)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if cached, ok := s.documents[path]; ok {
+		return cached.doc, cached.docType, nil
+	}
+	doc, docType, err := OpenDocument(path)
+	if err != nil {
+		return nil, "", err
+	}
+	s.parses++
+	if _, restorable := doc.(SnapshotDocument); restorable || docType == DocumentTypePDF {
+		s.documents[path] = &sourceDoc{doc: doc, docType: docType}
+	}
+	return doc, docType, nil
+}
+
+// shared 文档是否为共享的缓存文档，共享文档插入译文并保存后需要恢复原内容
+func (s *SourceCache) shared(doc Document) bool {
+	if s == nil {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, cached := range s.documents {
+		if cached.doc == doc {
+			return true
+		}
+	}
+	return false
+}
+
+// pdfContent 解析 PDF 文本，返回解析结果的副本，各目标语言应用译文时互不影响
+func (s *SourceCache) pdfContent(parser *PDFParser, path string) (*PDFContent, error) {
+	if s == nil {
+		return parser.ParsePDF(path)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	content, ok := s.contents[path]
+	if !ok {
+		var err error
+		if content, err = parser.ParsePDF(path); err != nil {
+			return nil, err
+		}
+		s.parses++
+		s.contents[path] = content
+	} else {
+		log.Printf("使用已解析的PDF文本: %s", path)
+	}
+
+	clone := *content
+	clone.TextBlocks = append([]TextBlock(nil), content.TextBlocks...)
+	return &clone, nil
+}
+
+// DetectLanguage 检测文档的主要语言，结果在各目标语言之间共享
+func (s *SourceCache) DetectLanguage(path string) (string, error) {
+	if s == nil {
+		return DetectDocumentLanguage(path)
+	}
+	s.mu.Lock()
+	lang, ok := s.languages[path]
+	s.mu.Unlock()
+	if ok {
+		return lang, nil
+	}
+
+	doc, _, err := s.open(path)
+	if err != nil {
+		return "", err
+	}
+	lang = documentLanguage(doc)
+	s.mu.Lock()
+	s.languages[path] = lang
+	s.mu.Unlock()
+	return lang, nil
+}
+
+// Filter 返回各目标语言共用的文本块过滤器，第一次调用时由 build 创建，并记住每段文本的过滤结果
+func (s *SourceCache) Filter(build func() (*BlockFilter, error)) (*BlockFilter, error) {
+	if s == nil {
+		return build()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.filter == nil {
+		filter, err := build()
+		if err != nil {
+			return nil, err
+		}
+		s.filter = filter.Memoize()
+	}
+	return s.filter, nil
+}
@@ -0,0 +1,86 @@
+package translator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// translateLanguages 使用同一个 SourceCache 将文档依次翻译成各目标语言，返回各语言的输出路径和解析次数
+func translateLanguages(t *testing.T, inputPath string, languages []string) (map[string]string, int) {
+	t.Helper()
+	client, _ := newStubClient(t)
+	dt := &DocumentTranslator{
+		Client:            client,
+		PDFMathTranslator: NewPDFMathTranslator(),
+		Source:            NewSourceCache(),
+	}
+	outputs := make(map[string]string)
+	for _, lang := range languages {
+		outputPath := filepath.Join(t.TempDir(), "out-"+lang+filepath.Ext(inputPath))
+		got, err := dt.TranslateDocument(inputPath, outputPath, lang, "", true, "", nil)
+		if err != nil {
+			t.Fatalf("翻译成 %s 失败: %v", lang, err)
+		}
+		outputs[lang] = got
+	}
+	return outputs, dt.Source.Parses()
+}
+
+func TestSourceCacheParsesEPUBOnce(t *testing.T) {
+	input := writeTestEPUB(t, t.TempDir(), []string{
+		"The quick brown fox jumps over the lazy dog.",
+		"A second chapter with another sentence to translate.",
+	})
+
+	_, single := translateLanguages(t, input, []string{"Japanese"})
+	languages := []string{"Japanese", "French", "German"}
+	outputs, fanOut := translateLanguages(t, input, languages)
+	if single == 0 {
+		t.Fatal("单一语言翻译没有经过 SourceCache 解析文档")
+	}
+	if fanOut != single {
+		t.Errorf("%d 种语言解析文档 %d 次，单一语言解析 %d 次", len(languages), fanOut, single)
+	}
+
+	// 共享文档在每种语言保存后恢复原内容，各语言的输出互不包含其他语言的译文
+	for _, lang := range languages {
+		doc, err := OpenEPUB(outputs[lang])
+		if err != nil {
+			t.Fatal(err)
+		}
+		content := string(doc.Files["OEBPS/ch1.xhtml"])
+		if !strings.Contains(content, "["+lang+"]") {
+			t.Errorf("%s 输出缺少译文: %s", lang, content)
+		}
+		for _, other := range languages {
+			if other != lang && strings.Contains(content, "["+other+"]") {
+				t.Errorf("%s 输出包含 %s 的译文: %s", lang, other, content)
+			}
+		}
+	}
+}
+
+func TestSourceCacheParsesPDFOnce(t *testing.T) {
+	t.Chdir(t.TempDir())
+	input := writeTestPDF(t, t.TempDir(), []string{
+		"The quick brown fox jumps over the lazy dog.",
+		"A second page with another sentence to translate.",
+	})
+
+	_, single := translateLanguages(t, input, []string{"ja"})
+	languages := []string{"ja", "fr", "de"}
+	outputs, fanOut := translateLanguages(t, input, languages)
+	if single == 0 {
+		t.Fatal("单一语言翻译没有经过 SourceCache 解析文档")
+	}
+	if fanOut != single {
+		t.Errorf("%d 种语言解析文档 %d 次，单一语言解析 %d 次", len(languages), fanOut, single)
+	}
+	for _, lang := range languages {
+		if info, err := os.Stat(outputs[lang]); err != nil || info.Size() == 0 {
+			t.Errorf("%s 没有输出: %v", lang, err)
+		}
+	}
+}
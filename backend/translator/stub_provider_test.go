@@ -0,0 +1,128 @@
+package translator
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// stubProvider 测试用的翻译提供商：译文为 "[目标语言] 原文"，fail 中的文本返回错误
+type stubProvider struct {
+	mu    sync.Mutex
+	fail  map[string]bool
+	calls []string
+}
+
+func (p *stubProvider) Translate(text, targetLanguage, userPrompt string) (string, error) {
+	p.mu.Lock()
+	p.calls = append(p.calls, text)
+	p.mu.Unlock()
+	if p.fail[text] {
+		return "", fmt.Errorf("stub: 翻译失败: %s", text)
+	}
+	return fmt.Sprintf("[%s] %s", targetLanguage, text), nil
+}
+
+func (p *stubProvider) GetName() string                       { return "stub" }
+func (p *stubProvider) GetConfig() ProviderConfig             { return ProviderConfig{Type: "stub"} }
+func (p *stubProvider) HealthCheck() error                    { return nil }
+func (p *stubProvider) SupportedLanguages() ([]string, error) { return nil, nil }
+
+// Calls 返回收到的翻译请求数
+func (p *stubProvider) Calls() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.calls)
+}
+
+// newStubClient 创建使用 stubProvider 的翻译客户端，不重试、不等待
+func newStubClient(t *testing.T, fail ...string) (*TranslatorClient, *stubProvider) {
+	t.Helper()
+	stub := &stubProvider{fail: make(map[string]bool)}
+	for _, text := range fail {
+		stub.fail[text] = true
+	}
+	client := &TranslatorClient{
+		Provider:    stub,
+		Filter:      NewBlockFilter(),
+		Concurrency: 2,
+		BatchSize:   1,
+	}
+	return client, stub
+}
+
+// isStubTranslation 是否为 stubProvider 生成的译文
+func isStubTranslation(text string) bool {
+	return strings.HasPrefix(text, "[")
+}
+
+// openAIStub 兼容 OpenAI 接口的测试服务器，记录收到的请求
+type openAIStub struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []stubRequest
+}
+
+// stubRequest 测试服务器收到的请求
+type stubRequest struct {
+	URL    string
+	Header http.Header
+	System string // 系统提示词
+	User   string // 待翻译的原文
+}
+
+// newOpenAIStub 启动测试服务器，reply 根据请求生成回复，为空时回复 "[译] 原文"
+func newOpenAIStub(t testing.TB, reply func(req stubRequest) string) *openAIStub {
+	t.Helper()
+	if reply == nil {
+		reply = func(req stubRequest) string { return "[译] " + req.User }
+	}
+	stub := &openAIStub{}
+	stub.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Messages []struct {
+				Role    string `json:"role"`
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		req := stubRequest{URL: r.URL.String(), Header: r.Header.Clone()}
+		for _, m := range body.Messages {
+			switch m.Role {
+			case "system":
+				req.System = m.Content
+			case "user":
+				req.User = m.Content
+			}
+		}
+		stub.mu.Lock()
+		stub.requests = append(stub.requests, req)
+		stub.mu.Unlock()
+
+		json.NewEncoder(w).Encode(map[string]any{
+			"choices": []map[string]any{{"message": map[string]string{"role": "assistant", "content": reply(req)}}},
+			"usage":   map[string]int{"prompt_tokens": 10, "completion_tokens": 5},
+		})
+	}))
+	t.Cleanup(stub.Close)
+	return stub
+}
+
+// Requests 返回收到的请求
+func (s *openAIStub) Requests() []stubRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]stubRequest(nil), s.requests...)
+}
+
+// Config 返回指向测试服务器的 OpenAI 提供商配置
+func (s *openAIStub) Config() ProviderConfig {
+	return ProviderConfig{Type: ProviderOpenAI, APIURL: s.URL, APIKey: "test-key", Model: "gpt-test"}
+}
@@ -17,18 +17,28 @@ func NewSystemFontDetector() *SystemFontDetector {
 }
 
 // GetSystemFontPath 根据语言获取系统字体路径
+// 找不到 CJK 字体时使用内置回退字体作为最后手段
 func (sfd *SystemFontDetector) GetSystemFontPath(language string) string {
+	var fontPath string
 	switch runtime.GOOS {
 	case "windows":
-		return sfd.getWindowsFont(language)
+		fontPath = sfd.getWindowsFont(language)
 	case "darwin":
-		return sfd.getMacFont(language)
+		fontPath = sfd.getMacFont(language)
 	case "linux":
-		return sfd.getLinuxFont(language)
+		fontPath = sfd.getLinuxFont(language)
 	default:
 		log.Printf("不支持的操作系统: %s", runtime.GOOS)
-		return ""
 	}
+
+	if fontPath == "" && isCJKLanguage(language) {
+		if bundled := BundledFontPath(); bundled != "" {
+			log.Printf("警告：未找到系统 CJK 字体，使用内置回退字体: %s", bundled)
+			return bundled
+		}
+		log.Printf("警告：未找到系统 CJK 字体且没有内置回退字体，译文中的 CJK 字符可能显示为空白")
+	}
+	return fontPath
 }
 
 // getWindowsFont 获取 Windows 系统字体
@@ -38,7 +48,7 @@ func (sfd *SystemFontDetector) getWindowsFont(language string) string {
 	// 根据语言选择字体
 	var fontCandidates []string
 	switch strings.ToLower(language) {
-	case "zh", "Uni", "zh-cn", "zh-tw", "zh-hk":
+	case "zh", "uni", "zh-cn", "zh-tw", "zh-hk":
 		fontCandidates = []string{
 			// 优先选择 TTF 格式字体，避免 TTC 格式
 			"simhei.ttf",   // 黑体
@@ -170,7 +180,7 @@ func (sfd *SystemFontDetector) getMacFont(language string) string {
 
 	var fontCandidates []string
 	switch strings.ToLower(language) {
-	case "zh", "Uni", "zh-cn", "zh-tw", "zh-hk":
+	case "zh", "uni", "zh-cn", "zh-tw", "zh-hk":
 		fontCandidates = []string{
 			"PingFang.ttc",       // 苹方
 			"STHeiti Medium.ttc", // 华文黑体
@@ -246,17 +256,22 @@ func (sfd *SystemFontDetector) getMacFont(language string) string {
 	return sfd.findFirstExistingFont(macFontsDir, fontCandidates)
 }
 
-// getLinuxFont 获取 Linux 系统字体
-func (sfd *SystemFontDetector) getLinuxFont(language string) string {
-	linuxFontsDirs := []string{
+// linuxFontDirs 返回查找 Linux 系统字体的目录
+var linuxFontDirs = func() []string {
+	return []string{
 		"/usr/share/fonts",
 		"/usr/local/share/fonts",
 		filepath.Join(os.Getenv("HOME"), ".fonts"),
 	}
+}
+
+// getLinuxFont 获取 Linux 系统字体
+func (sfd *SystemFontDetector) getLinuxFont(language string) string {
+	linuxFontsDirs := linuxFontDirs()
 
 	var fontCandidates []string
 	switch strings.ToLower(language) {
-	case "zh", "Uni", "zh-cn", "zh-tw", "zh-hk":
+	case "zh", "uni", "zh-cn", "zh-tw", "zh-hk":
 		fontCandidates = []string{
 			"truetype/wqy/wqy-microhei.ttc",         // 文泉驿微米黑
 			"truetype/wqy/wqy-zenhei.ttc",           // 文泉驿正黑
@@ -0,0 +1,69 @@
+package translator
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+	"testing/fstest"
+)
+
+// useFontFixture 将 Linux 字体目录替换为 fontDir，将内置字体替换为 bundled 中的文件，测试结束后恢复
+func useFontFixture(t *testing.T, fontDir string, bundled fstest.MapFS) {
+	t.Helper()
+	if runtime.GOOS != "linux" {
+		t.Skip("只在 Linux 上替换系统字体目录")
+	}
+	t.Setenv("TMPDIR", t.TempDir()) // 内置字体解压到 os.TempDir()
+
+	oldDirs, oldFS := linuxFontDirs, bundledFontFS
+	reset := func() {
+		bundledFontOnce = sync.Once{}
+		bundledFontPath = ""
+	}
+	t.Cleanup(func() {
+		linuxFontDirs, bundledFontFS = oldDirs, oldFS
+		reset()
+	})
+	linuxFontDirs = func() []string { return []string{fontDir} }
+	bundledFontFS = bundled
+	reset()
+}
+
+func TestBundledFontUsedWithoutSystemCJKFont(t *testing.T) {
+	useFontFixture(t, t.TempDir(), fstest.MapFS{"fonts/BundledCJK.ttf": {Data: []byte("font data")}})
+	detector := NewSystemFontDetector()
+
+	for _, language := range []string{"zh", "Uni", "ja", "ko"} {
+		got := detector.GetSystemFontPath(language)
+		if filepath.Base(got) != "BundledCJK.ttf" {
+			t.Errorf("GetSystemFontPath(%q) = %q，期望内置字体", language, got)
+			continue
+		}
+		if data, err := os.ReadFile(got); err != nil || string(data) != "font data" {
+			t.Errorf("内置字体未正确解压: %q, %v", data, err)
+		}
+	}
+	if got := detector.GetSystemFontPath("fr"); got != "" {
+		t.Errorf("非 CJK 语言不应使用内置 CJK 字体: %q", got)
+	}
+}
+
+func TestSystemCJKFontPreferredOverBundled(t *testing.T) {
+	fontDir := t.TempDir()
+	systemFont := filepath.Join(fontDir, "truetype", "droid", "DroidSansFallback.ttf")
+	if err := os.MkdirAll(filepath.Dir(systemFont), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(systemFont, []byte("system font"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	useFontFixture(t, fontDir, fstest.MapFS{"fonts/BundledCJK.ttf": {Data: []byte("font data")}})
+
+	for _, language := range []string{"zh", "Uni"} {
+		if got := NewSystemFontDetector().GetSystemFontPath(language); got != systemFont {
+			t.Errorf("GetSystemFontPath(%q) = %q，期望系统字体 %s", language, got, systemFont)
+		}
+	}
+}
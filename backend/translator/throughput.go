@@ -0,0 +1,76 @@
+package translator
+
+import "sync"
+
+const (
+	// MaxConcurrency 单个任务允许的最大并发翻译请求数
+	MaxConcurrency = 16
+	// MaxBatchSize 单次批量请求允许的最大文本块数
+	MaxBatchSize = 50
+)
+
+// DefaultThroughput 返回提供商的默认并发数和批大小
+// 本地模型默认串行；只有实现了 BatchProvider 的提供商批大小才大于1
+func DefaultThroughput(providerType ProviderType) (concurrency, batchSize int) {
+	switch providerType {
+	case ProviderOllama:
+		return 1, 1
+	case ProviderNLTranslate, ProviderLibreTranslate, ProviderCustom:
+		return 2, 1
+	case ProviderClaude, ProviderGemini:
+		return 4, 1
+	case ProviderYandex:
+		return 4, 20
+	case ProviderTencent:
+		return 4, 10
+	default: // OpenAI、DeepSeek、Azure OpenAI
+		return 8, 1
+	}
+}
+
+// ClampThroughput 计算实际使用的并发数和批大小
+// 小于1的值使用提供商默认值，超过服务端上限的值按上限处理
+func ClampThroughput(providerType ProviderType, concurrency, batchSize int) (int, int) {
+	defaultConcurrency, defaultBatchSize := DefaultThroughput(providerType)
+	if concurrency < 1 {
+		concurrency = defaultConcurrency
+	}
+	if batchSize < 1 {
+		batchSize = defaultBatchSize
+	}
+	return min(concurrency, MaxConcurrency), min(batchSize, MaxBatchSize)
+}
+
+// TranslatePool 限制并发数的翻译工作池
+type TranslatePool struct {
+	workers int
+}
+
+// NewTranslatePool 创建工作池，workers 小于1时按1处理
+func NewTranslatePool(workers int) *TranslatePool {
+	if workers < 1 {
+		workers = 1
+	}
+	return &TranslatePool{workers: workers}
+}
+
+// Run 并发执行 jobs 个任务，全部完成后返回
+func (p *TranslatePool) Run(jobs int, fn func(job int)) {
+	queue := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(p.workers, jobs); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range queue {
+				fn(job)
+			}
+		}()
+	}
+
+	for job := 0; job < jobs; job++ {
+		queue <- job
+	}
+	close(queue)
+	wg.Wait()
+}
@@ -0,0 +1,66 @@
+package translator
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestClampThroughput(t *testing.T) {
+	tests := []struct {
+		provider              ProviderType
+		concurrency, batch    int
+		wantConcurrency, want int
+	}{
+		{ProviderOpenAI, 0, 0, 8, 1},
+		{ProviderOllama, 0, 0, 1, 1},
+		{ProviderYandex, 0, 0, 4, 20},
+		{ProviderOpenAI, 3, 5, 3, 5},
+		{ProviderOpenAI, 1000, 1000, MaxConcurrency, MaxBatchSize},
+		{ProviderOllama, -5, -1, 1, 1},
+	}
+	for _, tt := range tests {
+		concurrency, batch := ClampThroughput(tt.provider, tt.concurrency, tt.batch)
+		if concurrency != tt.wantConcurrency || batch != tt.want {
+			t.Errorf("ClampThroughput(%s, %d, %d) = (%d, %d)，期望 (%d, %d)",
+				tt.provider, tt.concurrency, tt.batch, concurrency, batch, tt.wantConcurrency, tt.want)
+		}
+	}
+}
+
+func TestTranslatePoolLimitsWorkers(t *testing.T) {
+	var mu sync.Mutex
+	running, peak := 0, 0
+	done := make([]bool, 20)
+	NewTranslatePool(3).Run(len(done), func(job int) {
+		mu.Lock()
+		running++
+		if running > peak {
+			peak = running
+		}
+		mu.Unlock()
+
+		time.Sleep(5 * time.Millisecond)
+
+		mu.Lock()
+		running--
+		done[job] = true
+		mu.Unlock()
+	})
+	if peak > 3 {
+		t.Errorf("同时运行 %d 个任务，超过上限 3", peak)
+	}
+	for job, ok := range done {
+		if !ok {
+			t.Errorf("任务 %d 未执行", job)
+		}
+	}
+}
+
+func TestWithThroughputClampsToServerMaximum(t *testing.T) {
+	client, _ := newStubClient(t)
+	client.WithThroughput(MaxConcurrency*10, MaxBatchSize*10)
+	if client.Concurrency != MaxConcurrency || client.BatchSize != MaxBatchSize {
+		t.Fatalf("Concurrency=%d BatchSize=%d，期望 %d、%d", client.Concurrency, client.BatchSize, MaxConcurrency, MaxBatchSize)
+	}
+}
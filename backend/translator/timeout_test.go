@@ -0,0 +1,55 @@
+package translator
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// newSlowServer 启动在请求取消（或测试结束）前不返回的翻译服务桩
+func newSlowServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-done:
+		}
+	}))
+	t.Cleanup(func() {
+		close(done)
+		server.Close()
+	})
+	return server
+}
+
+func TestTranslateAbortsAtDeadline(t *testing.T) {
+	server := newSlowServer(t)
+	client, err := NewTranslatorClient(ProviderConfig{Type: ProviderOpenAI, APIURL: server.URL, APIKey: "key", Model: "gpt-test"}, NewMemoryCache())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	client.WithContext(ctx)
+
+	start := time.Now()
+	_, err = client.Translate("Hello world", "Uni", "")
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("翻译在超时后 %s 才返回", elapsed)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v，期望 context.DeadlineExceeded", err)
+	}
+
+	// 超时后剩余文本块不再请求，回退为原文
+	results := client.TranslateBlocks([]string{"First block", "Second block"}, "Uni", "", nil)
+	for _, r := range results {
+		if !r.UsedFallback || r.Translated != r.Original || !errors.Is(r.Err, context.DeadlineExceeded) {
+			t.Errorf("超时后的结果 = %+v，期望回退为原文", r)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package translator
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+// AllowInsecureTLS 是否允许提供商配置跳过 TLS 证书校验（insecureSkipVerify）
+// 由服务端通过环境变量 ALLOW_INSECURE_TLS 设置，默认不允许，客户端提交的配置无法自行开启
+var AllowInsecureTLS = allowInsecureTLSFromEnv()
+
+// allowInsecureTLSFromEnv 读取环境变量 ALLOW_INSECURE_TLS，未设置或无效时不允许
+func allowInsecureTLSFromEnv() bool {
+	value := os.Getenv("ALLOW_INSECURE_TLS")
+	if value == "" {
+		return false
+	}
+	allow, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("警告：ALLOW_INSECURE_TLS=%q 无效（应为 true 或 false），不允许跳过 TLS 证书校验", value)
+		return false
+	}
+	return allow
+}
+
+// newHTTPClient 根据提供商配置创建 HTTP 客户端
+// 同一主机的提供商共用一个 Transport（见 sharedTransport），以复用连接
+func newHTTPClient(config ProviderConfig) (*http.Client, error) {
+	transport, err := sharedTransport(config)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Client{
+		Timeout:   60 * time.Second,
+		Transport: transport,
+	}, nil
+}
+
+// newTLSConfig 根据提供商配置创建 TLS 配置，未配置 CA 证书和跳过校验时返回 nil（使用默认配置）
+// 配置了 CACert 时在系统证书之外信任该 CA，用于使用自签名证书的自建服务；
+// 证书以 PEM 内容提交，服务端不读取客户端指定的文件
+func newTLSConfig(config ProviderConfig) (*tls.Config, error) {
+	if config.CACert == "" && !config.InsecureSkipVerify {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{}
+	if config.CACert != "" {
+		pool, err := loadCertPool([]byte(config.CACert))
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if config.InsecureSkipVerify {
+		if !AllowInsecureTLS {
+			return nil, fmt.Errorf("服务端未允许跳过 TLS 证书校验（insecureSkipVerify），请改为提交 caCert，或由管理员设置环境变量 ALLOW_INSECURE_TLS=true")
+		}
+		log.Printf("⚠️  警告：%s 已关闭 TLS 证书校验（insecureSkipVerify），连接可能被中间人劫持，请仅在测试环境使用", config.Type)
+		tlsConfig.InsecureSkipVerify = true
+	}
+	return tlsConfig, nil
+}
+
+// loadCertPool 加载系统证书并追加 PEM 格式的 CA 证书
+func loadCertPool(pem []byte) (*x509.CertPool, error) {
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("caCert 中没有有效的 PEM 证书")
+	}
+	return pool, nil
+}
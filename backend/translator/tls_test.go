@@ -0,0 +1,64 @@
+package translator
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestProviderTLSConfig(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+	caCert := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+
+	get := func(config ProviderConfig) error {
+		client, err := newHTTPClient(config)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		return nil
+	}
+
+	if err := get(ProviderConfig{Type: ProviderOllama, APIURL: server.URL}); err == nil {
+		t.Error("未配置 CA 证书时应拒绝自签名证书")
+	}
+	if err := get(ProviderConfig{Type: ProviderOllama, APIURL: server.URL, CACert: caCert}); err != nil {
+		t.Errorf("配置 caCert 后请求失败: %v", err)
+	}
+	if _, err := newHTTPClient(ProviderConfig{Type: ProviderOllama, APIURL: server.URL, CACert: "/etc/passwd"}); err == nil {
+		t.Error("caCert 不是 PEM 内容时应返回错误")
+	}
+}
+
+func TestInsecureSkipVerifyRequiresServerSetting(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	config := ProviderConfig{Type: ProviderOllama, APIURL: server.URL, InsecureSkipVerify: true}
+
+	allow := AllowInsecureTLS
+	defer func() { AllowInsecureTLS = allow }()
+
+	AllowInsecureTLS = false
+	if _, err := NewProvider(config, nil); err == nil {
+		t.Error("服务端未允许时 insecureSkipVerify 应返回错误")
+	}
+
+	AllowInsecureTLS = true
+	client, err := newHTTPClient(config)
+	if err != nil {
+		t.Fatalf("服务端允许后创建客户端失败: %v", err)
+	}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("跳过证书校验后请求失败: %v", err)
+	}
+	resp.Body.Close()
+}
@@ -0,0 +1,148 @@
+package translator
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// tmxDocument TMX（Translation Memory eXchange）1.4 文档
+type tmxDocument struct {
+	XMLName xml.Name  `xml:"tmx"`
+	Version string    `xml:"version,attr"`
+	Header  tmxHeader `xml:"header"`
+	Units   []tmxUnit `xml:"body>tu"`
+}
+
+type tmxHeader struct {
+	CreationTool        string `xml:"creationtool,attr"`
+	CreationToolVersion string `xml:"creationtoolversion,attr"`
+	SegType             string `xml:"segtype,attr"`
+	DataType            string `xml:"datatype,attr"`
+	OTMF                string `xml:"o-tmf,attr"`
+	AdminLang           string `xml:"adminlang,attr"`
+	SrcLang             string `xml:"srclang,attr"`
+}
+
+type tmxUnit struct {
+	Variants []tmxVariant `xml:"tuv"`
+}
+
+type tmxVariant struct {
+	Lang       string `xml:"http://www.w3.org/XML/1998/namespace lang,attr,omitempty"`
+	LegacyLang string `xml:"lang,attr,omitempty"` // TMX 1.1 使用不带命名空间的 lang 属性
+	Seg        string `xml:"seg"`
+}
+
+// lang 返回语言代码（兼容 TMX 1.1）
+func (v tmxVariant) lang() string {
+	if v.Lang != "" {
+		return v.Lang
+	}
+	return v.LegacyLang
+}
+
+// TMXLanguage 将语言名称（如 English、Uni）转换为 TMX 使用的语言代码
+func TMXLanguage(language string) string {
+	return mapToLibreTranslateLanguageCode(language)
+}
+
+// ExportTMX 将原文 -> 译文对导出为 TMX 1.4，按原文排序以保证输出稳定
+func ExportTMX(w io.Writer, pairs map[string]string, srcLang, tgtLang string) error {
+	sources := make([]string, 0, len(pairs))
+	for source := range pairs {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+
+	doc := tmxDocument{
+		Version: "1.4",
+		Header: tmxHeader{
+			CreationTool:        "translator-web",
+			CreationToolVersion: "1.0",
+			SegType:             "paragraph",
+			DataType:            "plaintext",
+			OTMF:                "translator-web",
+			AdminLang:           "en",
+			SrcLang:             srcLang,
+		},
+	}
+	for _, source := range sources {
+		doc.Units = append(doc.Units, tmxUnit{Variants: []tmxVariant{
+			{Lang: srcLang, Seg: source},
+			{Lang: tgtLang, Seg: pairs[source]},
+		}})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("导出 TMX 失败: %w", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// ImportTMX 读取 TMX 中的原文 -> 译文对
+// 原文为语言与文件头 srclang 一致的 tuv（srclang 为 *all* 或未匹配时取第一个），译文为其后第一个其他语言的 tuv
+func ImportTMX(r io.Reader) (map[string]string, error) {
+	var doc tmxDocument
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("解析 TMX 失败: %w", err)
+	}
+
+	srcLang := strings.ToLower(doc.Header.SrcLang)
+	pairs := make(map[string]string)
+	for _, unit := range doc.Units {
+		if len(unit.Variants) < 2 {
+			continue
+		}
+
+		src := 0
+		for i, v := range unit.Variants {
+			if strings.ToLower(v.lang()) == srcLang {
+				src = i
+				break
+			}
+		}
+
+		source := unit.Variants[src].Seg
+		for i, v := range unit.Variants {
+			if i != src && !strings.EqualFold(v.lang(), unit.Variants[src].lang()) {
+				if strings.TrimSpace(source) != "" && strings.TrimSpace(v.Seg) != "" {
+					pairs[source] = v.Seg
+				}
+				break
+			}
+		}
+	}
+	return pairs, nil
+}
+
+// PrimeCache 用已有译文（如导入的 TMX）预先填充缓存，翻译时命中的文本块不再请求翻译服务
+// 使用与翻译时相同的缓存键；含列表标记、图表编号或行内公式的文本块无法与译文对应，予以跳过
+// 返回写入缓存的条目数
+func PrimeCache(cache CacheStore, config ProviderConfig, docType DocumentType, pairs map[string]string, targetLanguage, userPrompt string) int {
+	if cache == nil {
+		return 0
+	}
+
+	targetLanguage = translationTargetLanguage(docType, targetLanguage)
+	base := &BaseProvider{Config: config}
+	primed := 0
+	for source, target := range pairs {
+		block := prepareBlock(source, nil)
+		if block.prefix != "" || len(block.tokens) > 0 || block.body == "" {
+			continue
+		}
+		if err := cache.Set(CacheKeyWithOptions(block.body, targetLanguage, base.translateOptions(userPrompt)), target); err == nil {
+			primed++
+		}
+	}
+	return primed
+}
@@ -0,0 +1,69 @@
+package translator
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTMXRoundTrip(t *testing.T) {
+	pairs := map[string]string{
+		"Hello world":            "Bonjour le monde",
+		"Fish & chips <cheap>":   "Poisson & frites <pas cher>",
+		"Line one\nLine two":     "Ligne un\nLigne deux",
+		"“Quoted” text, ½ price": "Texte « cité », moitié prix",
+	}
+
+	var buf bytes.Buffer
+	if err := ExportTMX(&buf, pairs, "en", "fr"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `<tmx version="1.4">`) || !strings.Contains(buf.String(), `srclang="en"`) {
+		t.Errorf("导出内容缺少 TMX 头:\n%s", buf.String())
+	}
+
+	imported, err := ImportTMX(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(imported, pairs) {
+		t.Errorf("重新导入 = %v，期望 %v", imported, pairs)
+	}
+}
+
+func TestImportTMXLegacyLang(t *testing.T) {
+	data := `<?xml version="1.0"?>
+<tmx version="1.1"><header srclang="EN-US"/><body>
+  <tu><tuv lang="fr-FR"><seg>Bonjour</seg></tuv><tuv lang="en-US"><seg>Hello</seg></tuv></tu>
+  <tu><tuv lang="en-US"><seg>Only source</seg></tuv></tu>
+</body></tmx>`
+	pairs, err := ImportTMX(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(pairs, map[string]string{"Hello": "Bonjour"}) {
+		t.Errorf("ImportTMX = %v", pairs)
+	}
+
+	if _, err := ImportTMX(strings.NewReader("<tmx><body>")); err == nil {
+		t.Error("格式错误的 TMX 应返回错误")
+	}
+}
+
+func TestPrimeCache(t *testing.T) {
+	config := ProviderConfig{Type: "openai", Model: "gpt-4o-mini"}
+	cache := NewMemoryCache()
+	pairs := map[string]string{
+		"The cat sat on the mat.":  "Le chat était assis sur le tapis.",
+		"1. A numbered list item.": "1. Un élément de liste.",
+	}
+	if n := PrimeCache(cache, config, DocumentTypeEPUB, pairs, "French", ""); n != 1 {
+		t.Fatalf("写入缓存 %d 条，期望 1（带列表标记的条目应跳过）", n)
+	}
+
+	estimate := EstimateBlocks([]string{"The cat sat on the mat."}, "French", "", config, cache, NewBlockFilter())
+	if estimate.CacheHits != 1 {
+		t.Errorf("导入的译文未被翻译时的缓存键命中: %+v", *estimate)
+	}
+}
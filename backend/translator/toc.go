@@ -97,7 +97,7 @@ func parseNAV(content []byte) ([]*TOCItem, error) {
 }
 
 // TranslateTOC 翻译目录
-func TranslateTOC(items []*TOCItem, client any, targetLanguage, userPrompt string, cache *Cache) error {
+func TranslateTOC(items []*TOCItem, client any, targetLanguage, userPrompt string, cache CacheStore) error {
 	if len(items) == 0 {
 		return nil
 	}
@@ -140,9 +140,15 @@ func fillTitles(items []*TOCItem, translated []string, index *int) {
 	}
 }
 
-func translateTitlesWithCache(titles []string, client any, targetLanguage, userPrompt string, cache *Cache) ([]string, error) {
+func translateTitlesWithCache(titles []string, client any, targetLanguage, userPrompt string, cache CacheStore) ([]string, error) {
 	results := make([]string, len(titles))
 
+	// 与提供商自身的缓存使用相同的选项（术语表、语气等）
+	opts := TranslateOptions{UserPrompt: userPrompt}
+	if c, ok := client.(*TranslatorClient); ok && c.Provider != nil {
+		opts = (&BaseProvider{Config: c.Provider.GetConfig()}).translateOptions(userPrompt)
+	}
+
 	for i, title := range titles {
 		if title == "" {
 			results[i] = ""
@@ -151,7 +157,7 @@ func translateTitlesWithCache(titles []string, client any, targetLanguage, userP
 
 		// 检查缓存
 		if cache != nil {
-			cacheKey := CacheKey(title, targetLanguage, userPrompt)
+			cacheKey := CacheKeyWithOptions(title, targetLanguage, opts)
 			if cached, ok := cache.Get(cacheKey); ok {
 				results[i] = cached
 				continue
@@ -179,7 +185,7 @@ func translateTitlesWithCache(titles []string, client any, targetLanguage, userP
 
 		// 保存到缓存
 		if cache != nil {
-			cacheKey := CacheKey(title, targetLanguage, userPrompt)
+			cacheKey := CacheKeyWithOptions(title, targetLanguage, opts)
 			cache.Set(cacheKey, translated)
 		}
 	}
@@ -0,0 +1,63 @@
+package translator
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	// ErrEmptyTranslation 翻译服务返回了空白译文
+	ErrEmptyTranslation = errors.New("翻译服务返回了空译文")
+	// ErrTranslationTooShort 译文远短于原文，可能被截断或遗漏了内容
+	ErrTranslationTooShort = errors.New("译文长度异常偏短")
+)
+
+// DefaultMinTranslationLengthRatio 默认的译文与原文最小长度比
+const DefaultMinTranslationLengthRatio = 0.3
+
+// minLengthCheckRunes 原文少于该字符数时不检查长度比（短标题、术语的译文长度差异较大）
+const minLengthCheckRunes = 20
+
+// MinTranslationLengthRatio 译文与原文的最小长度比（按字符数），目标语言为中日韩文字时不检查，0 表示不检查
+// 可通过环境变量 MIN_TRANSLATION_LENGTH_RATIO 设置
+var MinTranslationLengthRatio = minTranslationLengthRatioFromEnv()
+
+// minTranslationLengthRatioFromEnv 读取环境变量 MIN_TRANSLATION_LENGTH_RATIO，未设置或无效时使用默认值
+func minTranslationLengthRatioFromEnv() float64 {
+	value := os.Getenv("MIN_TRANSLATION_LENGTH_RATIO")
+	if value == "" {
+		return DefaultMinTranslationLengthRatio
+	}
+	ratio, err := strconv.ParseFloat(value, 64)
+	if err != nil || ratio < 0 || ratio >= 1 {
+		log.Printf("警告：MIN_TRANSLATION_LENGTH_RATIO=%q 无效（应为 0 到 1 之间的小数），使用默认值 %.1f", value, DefaultMinTranslationLengthRatio)
+		return DefaultMinTranslationLengthRatio
+	}
+	return ratio
+}
+
+// validateTranslation 检查翻译服务返回的结果是否可用
+// 原文非空而译文为空白，或目标语言不是中日韩文字时译文远短于原文，均视为无效
+func validateTranslation(source, result, targetLanguage string) error {
+	if strings.TrimSpace(source) == "" {
+		return nil
+	}
+	if strings.TrimSpace(result) == "" {
+		return ErrEmptyTranslation
+	}
+
+	if MinTranslationLengthRatio <= 0 || isCJKLanguage(mapToLibreTranslateLanguageCode(targetLanguage)) {
+		return nil
+	}
+	sourceLen := utf8.RuneCountInString(strings.TrimSpace(source))
+	resultLen := utf8.RuneCountInString(strings.TrimSpace(result))
+	if sourceLen >= minLengthCheckRunes && float64(resultLen) < float64(sourceLen)*MinTranslationLengthRatio {
+		return fmt.Errorf("%w：原文 %d 个字符，译文只有 %d 个字符", ErrTranslationTooShort, sourceLen, resultLen)
+	}
+	return nil
+}
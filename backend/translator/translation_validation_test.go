@@ -0,0 +1,69 @@
+package translator
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestEmptyTranslationRetriesThenFallsBack(t *testing.T) {
+	stub := newOpenAIStub(t, func(req stubRequest) string {
+		if req.User == "A sentence the model drops." {
+			return "  \n "
+		}
+		return "[译] " + req.User
+	})
+	cache := NewMemoryCache()
+	client, err := NewTranslatorClient(stub.Config(), cache)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.WithRetry(3, 0)
+
+	results := client.TranslateBlocks([]string{"A sentence the model drops.", "A sentence that works."}, "French", "", nil)
+	if !errors.Is(results[0].Err, ErrEmptyTranslation) || !results[0].UsedFallback || results[0].Translated != "A sentence the model drops." {
+		t.Errorf("空译文应在重试后回退为原文，得到 %+v", results[0])
+	}
+	if results[1].Err != nil || results[1].Translated != "[译] A sentence that works." {
+		t.Errorf("正常文本块 = %+v", results[1])
+	}
+
+	// 空译文只重试一次，且不会写入缓存
+	countDropped := func() int {
+		n := 0
+		for _, req := range stub.Requests() {
+			if req.User == "A sentence the model drops." {
+				n++
+			}
+		}
+		return n
+	}
+	if n := countDropped(); n != 2 {
+		t.Errorf("空译文的文本块请求 %d 次，期望 2（重试一次）", n)
+	}
+	client.TranslateBlocks([]string{"A sentence the model drops."}, "French", "", nil)
+	if n := countDropped(); n != 4 {
+		t.Errorf("再次翻译时请求 %d 次，期望 4（空译文未被缓存）", n)
+	}
+}
+
+func TestValidateTranslation(t *testing.T) {
+	long := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 2)
+	tests := []struct {
+		source, result, target string
+		want                   error
+	}{
+		{"Hello", "", "French", ErrEmptyTranslation},
+		{"Hello", " \t", "French", ErrEmptyTranslation},
+		{"", "", "French", nil},
+		{"Hello", "Salut", "French", nil},
+		{long, "Le renard.", "French", ErrTranslationTooShort},
+		{long, "敏捷的狐狸。", "Uni", nil},
+		{long, "Le renard brun rapide saute par-dessus le chien paresseux.", "French", nil},
+	}
+	for _, tt := range tests {
+		if err := validateTranslation(tt.source, tt.result, tt.target); !errors.Is(err, tt.want) || (tt.want == nil && err != nil) {
+			t.Errorf("validateTranslation(%q, %q, %s) = %v，期望 %v", tt.source, tt.result, tt.target, err, tt.want)
+		}
+	}
+}
@@ -11,10 +11,34 @@ import (
 type DocumentTranslator struct {
 	Client            *TranslatorClient
 	PDFMathTranslator *PDFMathTranslator
+	FailedBlocks      []TranslateResult // 翻译失败（已回退为原文）的文本块
+	Stats             BlockStats        // 文本块统计信息
+	OutputFormats     []string          // 需要生成的输出格式，为空时按生成模式决定
+	Artifacts         map[string]string // 已生成的输出文件：输出格式 -> 文件路径
+	PageProgress      *PageProgress     // PDF逐页翻译进度，为空时不跟踪
+	HTMLLayout        HTMLLayout        // 双语 HTML 输出的排版方式
+	VerticalText      bool              // PDF 原文为竖排时以竖排输出 CJK 译文
+	MergeStrategy     MergeStrategy     // PDF 文本元素的合并策略
+	MatchMode         MatchMode         // PDF 译文与文本元素的匹配模式
+	PDFStrategy       PDFStrategy       // 单语 PDF 的生成方式
+	DiffOverlay       bool              // 单语 PDF 只遮罩并重绘译文与原文不同的文本
+	TextExtractor     string            // PDF 文本提取后端，为空时使用默认解析
+
+	OnRegenerationFailure RegenerationFailurePolicy // PDF 重新生成失败时的处理方式
+	Fallbacks             []RegenerationFallback    // PDF 重新生成失败后改为生成的替代输出
+
+	ConfidenceThreshold float64            // 自评可信度低于此值的译文视为低可信度
+	MarkLowConfidence   bool               // 在输出中为低可信度的译文追加标记
+	Confidences         map[string]float64 // 自评可信度低于 1 的文本块：原文 -> 可信度
+
+	CSVColumns []string // CSV/TSV 需要翻译的列（列号或表头名称），为空时翻译所有列
+	CSVHeader  bool     // CSV/TSV 首行为表头，不翻译
+
+	Source *SourceCache // 同一文档翻译成多种语言时共享的解析结果，为空时每次重新解析
 }
 
 // NewDocumentTranslator 创建文档翻译器
-func NewDocumentTranslator(config ProviderConfig, cache *Cache) (*DocumentTranslator, error) {
+func NewDocumentTranslator(config ProviderConfig, cache CacheStore) (*DocumentTranslator, error) {
 	client, err := NewTranslatorClient(config, cache)
 	if err != nil {
 		return nil, err
@@ -30,17 +54,13 @@ func NewDocumentTranslator(config ProviderConfig, cache *Cache) (*DocumentTransl
 func (dt *DocumentTranslator) TranslateDocument(inputPath, outputPath, targetLanguage, userPrompt string, forceRetranslate bool, generateMode string, progressCallback func(float64)) (string, error) {
 	log.Printf("开始翻译文档: %s", inputPath)
 
-	// 验证文档
-	if err := ValidateDocument(inputPath); err != nil {
-		// 为PDF提供更详细的错误信息
-		if strings.Contains(err.Error(), "stream not present") || strings.Contains(err.Error(), "PDF文件格式不受支持") {
-			return "", fmt.Errorf("PDF文件格式不兼容。此PDF可能使用了特殊编码、加密或压缩方式。建议：\n1. 使用其他PDF工具（如Adobe Acrobat、PDFtk等）重新保存该文件\n2. 确保PDF未加密且可以正常复制文本\n3. 尝试将PDF转换为标准格式后再上传")
-		}
-		return "", fmt.Errorf("文档验证失败: %w", err)
+	inputPath, err := dt.Source.validate(inputPath, validateInput)
+	if err != nil {
+		return "", err
 	}
 
 	// 获取文档类型
-	_, docType, err := OpenDocument(inputPath)
+	_, docType, err := dt.Source.open(inputPath)
 	if err != nil {
 		return "", fmt.Errorf("打开文档失败: %w", err)
 	}
@@ -51,13 +71,33 @@ func (dt *DocumentTranslator) TranslateDocument(inputPath, outputPath, targetLan
 	switch docType {
 	case DocumentTypePDF:
 		return dt.translatePDF(inputPath, outputPath, targetLanguage, userPrompt, forceRetranslate, generateMode, progressCallback)
-	case DocumentTypeEPUB:
-		return dt.translateEPUB(inputPath, outputPath, targetLanguage, userPrompt, generateMode, progressCallback)
+	case DocumentTypeEPUB, DocumentTypePPTX, DocumentTypeCSV, DocumentTypeTSV:
+		return dt.translatePackage(inputPath, outputPath, docType, targetLanguage, userPrompt, generateMode, progressCallback)
 	default:
 		return "", fmt.Errorf("不支持的文档类型: %s", docType)
 	}
 }
 
+// validateInput 验证文档，PDF 验证失败时尝试修复一次，返回可用的路径
+func validateInput(inputPath string) (string, error) {
+	err := ValidateDocument(inputPath)
+	if err != nil && strings.ToLower(filepath.Ext(inputPath)) == ".pdf" {
+		validate := func(path string) (struct{}, error) { return struct{}{}, ValidatePDF(path) }
+		var repairedPath string
+		if _, repairedPath, err = repairAndRetry(inputPath, err, validate); err == nil {
+			inputPath = repairedPath
+		}
+	}
+	if err != nil {
+		// 为PDF提供更详细的错误信息
+		if strings.Contains(err.Error(), "stream not present") || strings.Contains(err.Error(), "PDF文件格式不受支持") {
+			return "", fmt.Errorf("PDF文件格式不兼容。此PDF可能使用了特殊编码、加密或压缩方式。建议：\n1. 使用其他PDF工具（如Adobe Acrobat、PDFtk等）重新保存该文件\n2. 确保PDF未加密且可以正常复制文本\n3. 尝试将PDF转换为标准格式后再上传")
+		}
+		return "", fmt.Errorf("文档验证失败: %w", err)
+	}
+	return inputPath, nil
+}
+
 // translatePDF 翻译PDF文档
 func (dt *DocumentTranslator) translatePDF(inputPath, outputPath, targetLanguage, userPrompt string, forceRetranslate bool, generateMode string, progressCallback func(float64)) (string, error) {
 	log.Printf("开始翻译PDF: %s", inputPath)
@@ -67,18 +107,32 @@ func (dt *DocumentTranslator) translatePDF(inputPath, outputPath, targetLanguage
 
 	// 设置翻译客户端
 	dt.PDFMathTranslator.SetTranslatorClient(dt.Client)
+	dt.PDFMathTranslator.Progress = dt.PageProgress
+	dt.PDFMathTranslator.Source = dt.Source
 
 	// 构建PDF翻译配置
 	config := PDFMathConfig{
-		LangIn:       "auto", // 自动检测源语言
-		LangOut:      dt.mapLanguageCode(targetLanguage),
-		Service:      dt.PDFMathTranslator.MapProviderToService(string(dt.Client.Provider.GetConfig().Type)),
-		Thread:       4,
-		Output:       outputDir,
-		IgnoreCache:  forceRetranslate,
-		Prompt:       userPrompt,
-		GenerateMode: generateMode,
-		Envs:         dt.PDFMathTranslator.BuildEnvs(dt.Client.Provider.GetConfig()),
+		LangIn:                "auto", // 自动检测源语言
+		LangOut:               dt.mapLanguageCode(targetLanguage),
+		Service:               dt.PDFMathTranslator.MapProviderToService(string(dt.Client.Provider.GetConfig().Type)),
+		Thread:                4,
+		Output:                outputDir,
+		IgnoreCache:           forceRetranslate,
+		Prompt:                userPrompt,
+		GenerateMode:          generateMode,
+		OutputName:            strings.TrimSuffix(filepath.Base(outputPath), filepath.Ext(outputPath)),
+		OutputFormats:         dt.OutputFormats,
+		HTMLLayout:            string(dt.HTMLLayout),
+		VerticalText:          dt.VerticalText,
+		MergeStrategy:         string(dt.MergeStrategy),
+		MatchMode:             string(dt.MatchMode),
+		Strategy:              string(dt.PDFStrategy),
+		DiffOverlay:           dt.DiffOverlay,
+		TextExtractor:         dt.TextExtractor,
+		OnRegenerationFailure: string(dt.OnRegenerationFailure),
+		ConfidenceThreshold:   dt.ConfidenceThreshold,
+		MarkLowConfidence:     dt.MarkLowConfidence,
+		Envs:                  dt.PDFMathTranslator.BuildEnvs(dt.Client.Provider.GetConfig()),
 	}
 
 	// 执行翻译
@@ -87,6 +141,25 @@ func (dt *DocumentTranslator) translatePDF(inputPath, outputPath, targetLanguage
 		return "", fmt.Errorf("PDF翻译失败: %w", err)
 	}
 
+	// 记录翻译失败的文本块
+	if dt.PDFMathTranslator.Integration != nil {
+		dt.FailedBlocks = dt.PDFMathTranslator.Integration.FailedBlocks
+		dt.Stats = dt.PDFMathTranslator.Integration.Stats
+		dt.Confidences = dt.PDFMathTranslator.Integration.Confidences
+	}
+	dt.Artifacts = result.Artifacts
+	dt.Fallbacks = result.Fallbacks
+
+	// 指定了输出格式时，以第一个请求的格式作为主输出，该格式重新生成失败时使用其替代输出
+	if len(dt.OutputFormats) > 0 {
+		for _, fallback := range result.Fallbacks {
+			if fallback.Format == dt.OutputFormats[0] {
+				return fallback.Path, nil
+			}
+		}
+		return result.Artifacts[dt.OutputFormats[0]], nil
+	}
+
 	// 返回合适的PDF文件路径
 	if generateMode == "monolingual" {
 		if result.MonoFile != "" {
@@ -101,28 +174,55 @@ func (dt *DocumentTranslator) translatePDF(inputPath, outputPath, targetLanguage
 	}
 }
 
-// translateEPUB 翻译EPUB文档
-func (dt *DocumentTranslator) translateEPUB(inputPath, outputPath, targetLanguage, userPrompt, generateMode string, progressCallback func(float64)) (string, error) {
-	log.Printf("开始翻译EPUB: %s", inputPath)
+// translatePackage 翻译EPUB、PPTX等压缩包格式的文档，译文写回原格式
+func (dt *DocumentTranslator) translatePackage(inputPath, outputPath string, docType DocumentType, targetLanguage, userPrompt, generateMode string, progressCallback func(float64)) (_ string, err error) {
+	kind := strings.ToUpper(string(docType))
+	log.Printf("开始翻译%s: %s", kind, inputPath)
 
-	// 打开EPUB文档
-	doc, _, err := OpenDocument(inputPath)
+	// 打开文档，共享的文档在生成输出后恢复到插入译文前的内容，供下一种语言使用
+	doc, _, err := dt.Source.open(inputPath)
 	if err != nil {
-		return "", fmt.Errorf("打开EPUB文档失败: %w", err)
+		return "", fmt.Errorf("打开%s文档失败: %w", kind, err)
+	}
+	shared := dt.Source.shared(doc)
+	if table, ok := doc.(*CSVDocument); ok {
+		if err := table.SelectColumns(dt.CSVColumns, dt.CSVHeader); err != nil {
+			return "", err
+		}
 	}
 
 	// 提取文本块
 	textBlocks := doc.GetTextBlocks()
 	if len(textBlocks) == 0 {
-		return "", fmt.Errorf("EPUB中没有可翻译的文本内容")
+		return "", fmt.Errorf("%s中没有可翻译的文本内容", kind)
+	}
+
+	// 图片替代文本等属性一并翻译
+	if provider, ok := doc.(AttributeTextProvider); ok {
+		textBlocks = append(textBlocks, provider.GetAttributeTextBlocks()...)
 	}
 
 	log.Printf("提取到 %d 个文本块", len(textBlocks))
 
 	// 翻译文本块
 	translations := dt.translateTextBlocks(textBlocks, targetLanguage, userPrompt, progressCallback)
+	if err := dt.Client.Context().Err(); err != nil {
+		return "", fmt.Errorf("翻译%s中止: %w", kind, err)
+	}
+	if dt.MarkLowConfidence && len(dt.Confidences) > 0 {
+		translations = MarkLowConfidence(translations, dt.Confidences, dt.ConfidenceThreshold)
+	}
 
-	// 插入翻译到EPUB
+	// 插入翻译到文档，失败时回滚到插入前的内容
+	var snapshot DocumentSnapshot
+	if snapshotter, ok := doc.(SnapshotDocument); ok {
+		snapshot = snapshotter.Snapshot()
+		defer func() {
+			if err != nil || shared {
+				snapshotter.Restore(snapshot)
+			}
+		}()
+	}
 	if generateMode == "monolingual" {
 		if err := doc.InsertMonolingualTranslation(translations); err != nil {
 			return "", fmt.Errorf("插入单语翻译失败: %w", err)
@@ -133,39 +233,66 @@ func (dt *DocumentTranslator) translateEPUB(inputPath, outputPath, targetLanguag
 		}
 	}
 
-	// 保存EPUB文档
-	if err := doc.Save(outputPath); err != nil {
-		return "", fmt.Errorf("保存EPUB文档失败: %w", err)
+	formats := dt.OutputFormats
+	if len(formats) == 0 {
+		formats = []string{string(docType)}
+	}
+
+	// 按请求的输出格式生成文件
+	base := strings.TrimSuffix(outputPath, filepath.Ext(outputPath))
+	title := fmt.Sprintf("%s 翻译结果 / %s Translation Result", kind, kind)
+	dt.Artifacts = make(map[string]string)
+	for _, format := range formats {
+		path := base + outputFormatSuffix(OutputFormat(format))
+
+		var err error
+		switch OutputFormat(format) {
+		case OutputFormat(docType):
+			err = doc.Save(path)
+		case OutputFormatText:
+			err = saveBlocksText(path, title, textBlocks, translations, false)
+		case OutputFormatBilingualText:
+			err = saveBlocksText(path, title, textBlocks, translations, true)
+		case OutputFormatHTML:
+			err = saveBlocksHTML(path, title, textBlocks, translations, false, dt.HTMLLayout)
+		case OutputFormatBilingualHTML:
+			err = saveBlocksHTML(path, title, textBlocks, translations, true, dt.HTMLLayout)
+		default:
+			err = fmt.Errorf("%s 不支持输出格式: %s", kind, format)
+		}
+		if err != nil {
+			return "", fmt.Errorf("生成 %s 输出失败: %w", format, err)
+		}
+		dt.Artifacts[format] = path
 	}
 
-	log.Printf("EPUB翻译完成: %s", outputPath)
-	return outputPath, nil
+	primary := dt.Artifacts[formats[0]]
+	log.Printf("%s翻译完成: %s", kind, primary)
+	return primary, nil
 }
 
 // translateTextBlocks 翻译文本块的通用方法
 func (dt *DocumentTranslator) translateTextBlocks(textBlocks []string, targetLanguage, userPrompt string, progressCallback func(float64)) map[string]string {
 	translations := make(map[string]string)
 
-	for i, block := range textBlocks {
-		if strings.TrimSpace(block) == "" {
-			continue
-		}
-
-		log.Printf("翻译第 %d/%d 个文本块", i+1, len(textBlocks))
+	log.Printf("开始翻译 %d 个文本块", len(textBlocks))
 
-		translated, err := dt.Client.Translate(block, targetLanguage, userPrompt)
-		if err != nil {
-			log.Printf("警告：翻译第 %d 个文本块失败: %v", i+1, err)
-			translations[block] = block // 使用原文
-		} else {
-			translations[block] = translated
+	results := dt.Client.TranslateBlocks(textBlocks, targetLanguage, userPrompt, progressCallback)
+	for _, result := range results {
+		if strings.TrimSpace(result.Original) == "" {
+			continue
 		}
+		translations[result.Original] = result.Translated
+	}
 
-		// 更新进度
-		if progressCallback != nil {
-			progress := float64(i+1) / float64(len(textBlocks))
-			progressCallback(progress)
-		}
+	dt.FailedBlocks = FailedResults(results)
+	dt.Confidences = ResultConfidences(results)
+	dt.Stats = NewBlockStats(textBlocks)
+	if dt.Stats.SavedBlocks > 0 {
+		log.Printf("去重后需翻译 %d 个文本块，节省 %d 次翻译", dt.Stats.UniqueBlocks, dt.Stats.SavedBlocks)
+	}
+	if len(dt.FailedBlocks) > 0 {
+		log.Printf("警告：%d 个文本块翻译失败，已使用原文", len(dt.FailedBlocks))
 	}
 
 	return translations
@@ -189,5 +316,11 @@ func (dt *DocumentTranslator) mapLanguageCode(language string) string {
 	if code, ok := mapping[language]; ok {
 		return code
 	}
+	// 已是语言代码（如多目标语言请求中的 ja、fr）时原样使用
+	for _, code := range mapping {
+		if strings.EqualFold(language, code) {
+			return code
+		}
+	}
 	return "zh" // 默认通用
 }
@@ -0,0 +1,77 @@
+package translator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMapLanguageCode(t *testing.T) {
+	dt := &DocumentTranslator{}
+	tests := []struct {
+		language string
+		want     string
+	}{
+		{"Uni", "zh"},
+		{"Japanese", "ja"},
+		{"French", "fr"},
+		{"ja", "ja"},
+		{"FR", "fr"},
+		{"de", "de"},
+		{"zh", "zh"},
+		{"Klingon", "zh"},
+		{"", "zh"},
+	}
+	for _, tt := range tests {
+		if got := dt.mapLanguageCode(tt.language); got != tt.want {
+			t.Errorf("mapLanguageCode(%q) = %q, want %q", tt.language, got, tt.want)
+		}
+	}
+}
+
+func TestTranslateDocumentTextOnly(t *testing.T) {
+	t.Chdir(t.TempDir())
+	input := writeTestPDF(t, t.TempDir(), []string{"The quick brown fox jumps over the lazy dog."})
+	client, _ := newStubClient(t)
+	dt := &DocumentTranslator{
+		Client:            client,
+		PDFMathTranslator: NewPDFMathTranslator(),
+		OutputFormats:     []string{string(OutputFormatText)},
+	}
+
+	outputDir := t.TempDir()
+	got, err := dt.TranslateDocument(input, filepath.Join(outputDir, "paper.pdf"), "Uni", "", true, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Ext(got) != ".txt" {
+		t.Errorf("输出路径 = %s，期望文本文件", got)
+	}
+	content, err := os.ReadFile(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "[Uni] ") {
+		t.Errorf("文本输出缺少译文: %q", content)
+	}
+
+	entries, err := os.ReadDir(outputDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if filepath.Ext(e.Name()) == ".pdf" {
+			t.Errorf("只请求文本输出时生成了 PDF: %s", e.Name())
+		}
+	}
+}
+
+func TestValidateOutputFormats(t *testing.T) {
+	if err := ValidateOutputFormats(DocumentTypePDF, ParseOutputFormats("text, bilingual-pdf,text")); err != nil {
+		t.Errorf("PDF 支持 text 和 bilingual-pdf: %v", err)
+	}
+	if err := ValidateOutputFormats(DocumentTypeEPUB, []string{"pdf"}); err == nil {
+		t.Error("EPUB 不支持 pdf 输出")
+	}
+}
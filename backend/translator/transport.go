@@ -0,0 +1,116 @@
+package translator
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// 连接池默认参数
+const (
+	// DefaultMaxIdleConnsPerHost 每个主机保留的空闲连接数，与单个任务的最大并发数一致，避免并发请求反复建立连接
+	DefaultMaxIdleConnsPerHost = MaxConcurrency
+	// DefaultIdleConnTimeout 空闲连接的保留时间
+	DefaultIdleConnTimeout = 90 * time.Second
+	// maxSharedTransports 最多保留的共享 Transport 数，超出时淘汰最久未使用的
+	maxSharedTransports = 64
+)
+
+// transportKey 共享 Transport 的键：同一主机且连接参数相同的提供商共用一个 Transport
+type transportKey struct {
+	host                string
+	caCert              [sha256.Size]byte // CA 证书内容的哈希，未配置时为零值
+	insecureSkipVerify  bool
+	maxIdleConnsPerHost int
+	maxConnsPerHost     int
+	idleConnTimeout     time.Duration
+}
+
+// sharedTransportEntry 共享 Transport 缓存条目
+type sharedTransportEntry struct {
+	key       transportKey
+	transport *http.Transport
+}
+
+var (
+	sharedTransportsMu    sync.Mutex
+	sharedTransports      = make(map[transportKey]*list.Element)
+	sharedTransportsOrder = list.New() // 最近使用的 Transport 在前
+)
+
+// sharedTransport 返回提供商主机对应的共享 Transport，不存在时按配置创建
+// 同一主机的多个提供商实例（如并发任务）复用同一个连接池；
+// 主机和连接参数由客户端提交，缓存的 Transport 数有上限（maxSharedTransports），按最近使用淘汰
+func sharedTransport(config ProviderConfig) (*http.Transport, error) {
+	if config.MaxIdleConnsPerHost < 0 || config.MaxConnsPerHost < 0 || config.IdleConnTimeout < 0 {
+		return nil, fmt.Errorf("连接池参数不能为负数")
+	}
+
+	key := transportKey{
+		host:                providerHost(config),
+		insecureSkipVerify:  config.InsecureSkipVerify,
+		maxIdleConnsPerHost: config.MaxIdleConnsPerHost,
+		maxConnsPerHost:     config.MaxConnsPerHost,
+		idleConnTimeout:     time.Duration(config.IdleConnTimeout) * time.Second,
+	}
+	if config.CACert != "" {
+		key.caCert = sha256.Sum256([]byte(config.CACert))
+	}
+	if key.maxIdleConnsPerHost == 0 {
+		key.maxIdleConnsPerHost = DefaultMaxIdleConnsPerHost
+	}
+	if key.idleConnTimeout == 0 {
+		key.idleConnTimeout = DefaultIdleConnTimeout
+	}
+
+	sharedTransportsMu.Lock()
+	defer sharedTransportsMu.Unlock()
+	if elem, ok := sharedTransports[key]; ok {
+		sharedTransportsOrder.MoveToFront(elem)
+		return elem.Value.(*sharedTransportEntry).transport, nil
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = key.maxIdleConnsPerHost
+	transport.MaxConnsPerHost = key.maxConnsPerHost
+	transport.IdleConnTimeout = key.idleConnTimeout
+	if transport.MaxIdleConns < key.maxIdleConnsPerHost {
+		transport.MaxIdleConns = key.maxIdleConnsPerHost
+	}
+
+	tlsConfig, err := newTLSConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	sharedTransports[key] = sharedTransportsOrder.PushFront(&sharedTransportEntry{key: key, transport: transport})
+	evictSharedTransports()
+	return transport, nil
+}
+
+// evictSharedTransports 淘汰超出上限的最久未使用 Transport 并关闭其空闲连接（调用方需持有 sharedTransportsMu）
+// 仍在使用被淘汰 Transport 的提供商实例不受影响，其连接在请求结束后按 IdleConnTimeout 关闭
+func evictSharedTransports() {
+	for sharedTransportsOrder.Len() > maxSharedTransports {
+		oldest := sharedTransportsOrder.Back()
+		sharedTransportsOrder.Remove(oldest)
+		entry := oldest.Value.(*sharedTransportEntry)
+		delete(sharedTransports, entry.key)
+		entry.transport.CloseIdleConnections()
+	}
+}
+
+// providerHost 返回提供商请求的主机（含端口）；未配置 API 地址时各提供商使用内置地址，以提供商类型区分
+func providerHost(config ProviderConfig) string {
+	if u, err := url.Parse(config.APIURL); err == nil && u.Host != "" {
+		return u.Scheme + "://" + u.Host
+	}
+	return string(config.Type)
+}
@@ -0,0 +1,110 @@
+package translator
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSharedTransportReuse(t *testing.T) {
+	config := ProviderConfig{Type: ProviderOpenAI, APIURL: "https://reuse.example.com/v1/chat/completions"}
+	first, err := sharedTransport(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.APIURL = "https://reuse.example.com/v1/models"
+	if same, _ := sharedTransport(config); same != first {
+		t.Error("同一主机且参数相同时应共用 Transport")
+	}
+	config.MaxConnsPerHost = 2
+	if other, _ := sharedTransport(config); other == first {
+		t.Error("连接参数不同时不应共用 Transport")
+	}
+	if _, err := sharedTransport(ProviderConfig{Type: ProviderOpenAI, IdleConnTimeout: -1}); err == nil {
+		t.Error("负数连接池参数应返回错误")
+	}
+}
+
+func TestSharedTransportEvictsLeastRecentlyUsed(t *testing.T) {
+	closed := make(chan struct{}, 1)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateClosed {
+			select {
+			case closed <- struct{}{}:
+			default:
+			}
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	// 通过第一个 Transport 建立一个空闲的长连接
+	oldest := ProviderConfig{Type: ProviderOllama, APIURL: server.URL}
+	transport, err := sharedTransport(oldest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	for i := 0; i < maxSharedTransports; i++ {
+		if _, err := sharedTransport(ProviderConfig{Type: ProviderOllama, APIURL: fmt.Sprintf("http://evict-%d.example.com", i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sharedTransportsMu.Lock()
+	size := len(sharedTransports)
+	sharedTransportsMu.Unlock()
+	if size > maxSharedTransports {
+		t.Errorf("共享 Transport 数 = %d，超过上限 %d", size, maxSharedTransports)
+	}
+
+	select {
+	case <-closed:
+	case <-time.After(2 * time.Second):
+		t.Error("被淘汰的 Transport 的空闲连接未关闭")
+	}
+	if again, _ := sharedTransport(oldest); again == transport {
+		t.Error("最久未使用的 Transport 应已被淘汰")
+	}
+}
+
+func TestNewProviderAppliesConnectionLimits(t *testing.T) {
+	transportOf := func(config ProviderConfig) *http.Transport {
+		t.Helper()
+		provider, err := NewProvider(config, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		base := provider.(*OpenAIProvider).BaseProvider
+		return base.HTTPClient.Transport.(*http.Transport)
+	}
+
+	config := ProviderConfig{Type: ProviderOpenAI, APIURL: "https://limits.example.com/v1", APIKey: "key",
+		MaxIdleConnsPerHost: 8, MaxConnsPerHost: 4, IdleConnTimeout: 30}
+	first := transportOf(config)
+	if first.MaxIdleConnsPerHost != 8 || first.MaxConnsPerHost != 4 || first.IdleConnTimeout != 30*time.Second {
+		t.Errorf("Transport 参数 = %d/%d/%v，期望 8/4/30s", first.MaxIdleConnsPerHost, first.MaxConnsPerHost, first.IdleConnTimeout)
+	}
+	if first.MaxIdleConns < first.MaxIdleConnsPerHost {
+		t.Errorf("MaxIdleConns = %d，不应小于每主机上限", first.MaxIdleConns)
+	}
+
+	config.Type, config.APIKey = ProviderDeepSeek, "other-key"
+	if second := transportOf(config); second != first {
+		t.Error("同一主机的两个提供商实例应共用 Transport")
+	}
+
+	defaults := transportOf(ProviderConfig{Type: ProviderOpenAI, APIURL: "https://defaults.example.com/v1", APIKey: "key"})
+	if defaults.MaxIdleConnsPerHost != DefaultMaxIdleConnsPerHost || defaults.IdleConnTimeout != DefaultIdleConnTimeout || defaults.MaxConnsPerHost != 0 {
+		t.Errorf("默认 Transport 参数 = %d/%d/%v", defaults.MaxIdleConnsPerHost, defaults.MaxConnsPerHost, defaults.IdleConnTimeout)
+	}
+}
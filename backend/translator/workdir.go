@@ -0,0 +1,65 @@
+package translator
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// flowWorkDirPrefix PDF流处理器临时工作目录名前缀
+const flowWorkDirPrefix = "pdf_flow_"
+
+// DefaultWorkDirMaxAge 启动清理时遗留工作目录的默认保留时长
+const DefaultWorkDirMaxAge = 24 * time.Hour
+
+// flowCacheDir 返回存放临时工作目录的cache目录
+func flowCacheDir() string {
+	currentDir, _ := os.Getwd()
+	return filepath.Join(currentDir, "cache")
+}
+
+// KeepWorkDirFromEnv 根据环境变量判断是否保留临时工作目录
+// 设置 KEEP_WORK_DIR 或 DEBUG 为真值（1、true 等）时保留
+func KeepWorkDirFromEnv() bool {
+	for _, name := range []string{"KEEP_WORK_DIR", "DEBUG"} {
+		if keep, err := strconv.ParseBool(os.Getenv(name)); err == nil && keep {
+			return true
+		}
+	}
+	return false
+}
+
+// SweepWorkDirs 删除cache目录中修改时间早于 maxAge 的遗留 pdf_flow_* 工作目录
+// 用于清理进程异常退出或调试模式下残留的目录，返回删除的目录数
+func SweepWorkDirs(maxAge time.Duration) (int, error) {
+	cacheDir := flowCacheDir()
+	entries, err := os.ReadDir(cacheDir)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	cutoff := time.Now().Add(-maxAge)
+	removed := 0
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), flowWorkDirPrefix) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || info.ModTime().After(cutoff) {
+			continue
+		}
+		path := filepath.Join(cacheDir, entry.Name())
+		if err := os.RemoveAll(path); err != nil {
+			log.Printf("警告：删除遗留工作目录失败 %s: %v", path, err)
+			continue
+		}
+		removed++
+	}
+	return removed, nil
+}
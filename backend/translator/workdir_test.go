@@ -0,0 +1,86 @@
+package translator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCleanupRemovesWorkDir(t *testing.T) {
+	t.Chdir(t.TempDir())
+	for _, keep := range []bool{false, true} {
+		p, err := NewPDFFlowProcessor("input.pdf", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		p.KeepWorkDir = keep
+		if _, err := os.Stat(p.workDir); err != nil {
+			t.Fatalf("工作目录未创建: %v", err)
+		}
+		if err := p.Cleanup(); err != nil {
+			t.Fatal(err)
+		}
+		_, err = os.Stat(p.workDir)
+		if keep && err != nil {
+			t.Errorf("KeepWorkDir=true 时应保留工作目录: %v", err)
+		}
+		if !keep && !os.IsNotExist(err) {
+			t.Errorf("KeepWorkDir=false 时应删除工作目录，Stat 返回 %v", err)
+		}
+	}
+}
+
+func TestKeepWorkDirFromEnv(t *testing.T) {
+	tests := []struct {
+		keep, debug string
+		want        bool
+	}{
+		{"", "", false},
+		{"1", "", true},
+		{"", "true", true},
+		{"false", "no", false},
+	}
+	for _, tt := range tests {
+		t.Setenv("KEEP_WORK_DIR", tt.keep)
+		t.Setenv("DEBUG", tt.debug)
+		if got := KeepWorkDirFromEnv(); got != tt.want {
+			t.Errorf("KEEP_WORK_DIR=%q DEBUG=%q: %v，期望 %v", tt.keep, tt.debug, got, tt.want)
+		}
+	}
+}
+
+func TestSweepWorkDirs(t *testing.T) {
+	t.Chdir(t.TempDir())
+	old := time.Now().Add(-2 * DefaultWorkDirMaxAge)
+	dirs := map[string]bool{ // 目录名 -> 是否应被删除
+		"pdf_flow_old":    true,
+		"pdf_flow_recent": false,
+		"other_old":       false,
+	}
+	for name, stale := range dirs {
+		path := filepath.Join("cache", name)
+		if err := os.MkdirAll(path, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if stale || name == "other_old" {
+			if err := os.Chtimes(path, old, old); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	removed, err := SweepWorkDirs(DefaultWorkDirMaxAge)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed != 1 {
+		t.Errorf("删除 %d 个目录，期望 1", removed)
+	}
+	for name, stale := range dirs {
+		_, err := os.Stat(filepath.Join("cache", name))
+		if exists := err == nil; exists == stale {
+			t.Errorf("%s: 存在=%v", name, exists)
+		}
+	}
+}
@@ -70,10 +70,14 @@ function App() {
   // 自定义API配置状态
   const [customApiConfig, setCustomApiConfig] = useState(() => loadCustomConfig());
 
-  const languages = [
+  // 后端不可用时使用的默认语言列表
+  const defaultLanguages = [
     'Uni', 'English', 'Japanese', 'Korean', 'French',
     'German', 'Spanish', 'Russian', 'Arabic', 'Portuguese'
   ];
+  const [languageOptions, setLanguageOptions] = useState(defaultLanguages);
+  // 已选语言不在列表中时（如切换了提供商）仍保留为可选项
+  const languages = [...new Set([...languageOptions, targetLanguage, sourceLanguage])];
 
   const providers = [
     { value: 'openai', label: 'OpenAI', defaultUrl: 'https://api.openai.com/v1/chat/completions', defaultModel: 'gpt-4' },
@@ -83,6 +87,8 @@ function App() {
     { value: 'ollama', label: 'Ollama (本地)', defaultUrl: 'http://localhost:11434/api/generate', defaultModel: 'llama2', noApiKey: true },
     { value: 'nltranslator', label: 'NLTranslator (Apple 翻译)', defaultUrl: 'http://localhost:8765/translate', defaultModel: '', noApiKey: true, modelOptional: true },
     { value: 'libretranslate', label: 'LibreTranslate', defaultUrl: 'https://libretranslate.com/translate', defaultModel: '', modelOptional: true, apiKeyOptional: true },
+    { value: 'yandex', label: 'Yandex Translate', defaultUrl: 'https://translate.api.cloud.yandex.net/translate/v2/translate', defaultModel: '', modelOptional: true },
+    { value: 'tencent', label: '腾讯云机器翻译', defaultUrl: 'https://tmt.tencentcloudapi.com', defaultModel: '', modelOptional: true },
     { value: 'custom', label: '自定义 API', defaultUrl: '', defaultModel: '', modelOptional: true },
   ];
 
@@ -128,6 +134,31 @@ function App() {
     localStorage.setItem('customApiConfig', JSON.stringify(customApiConfig));
   }, [customApiConfig]);
 
+  // 按提供商从后端加载支持的语言
+  // 输入 API 地址和密钥时等待停止输入后再查询；密钥放在请求体中，不出现在 URL 里
+  useEffect(() => {
+    const body = { provider };
+    if (provider === 'nltranslator' || provider === 'libretranslate') {
+      body.apiUrl = apiUrl;
+      body.apiKey = apiKey;
+    }
+    let cancelled = false;
+    const timeoutId = setTimeout(() => {
+      axios.post('/api/languages', body)
+        .then((response) => {
+          const names = (response.data.languages || []).map((lang) => lang.name);
+          if (!cancelled && names.length > 0) {
+            setLanguageOptions(names);
+          }
+        })
+        .catch((err) => console.error('加载语言列表失败:', err));
+    }, 500);
+    return () => {
+      cancelled = true;
+      clearTimeout(timeoutId);
+    };
+  }, [provider, apiUrl, apiKey]);
+
   // 加载任务列表
   const loadTasks = async () => {
     try {
@@ -137,7 +168,7 @@ function App() {
 
       // 返回是否有活跃任务
       return taskList.some(task =>
-        task.status === 'processing' || task.status === 'pending'
+        task.status === 'processing' || task.status === 'pending' || task.status === 'queued'
       );
     } catch (err) {
       console.error('加载任务失败:', err);
@@ -177,11 +208,11 @@ function App() {
 
   const handleFileChange = (event) => {
     const selectedFile = event.target.files[0];
-    if (selectedFile && (selectedFile.name.endsWith('.epub') || selectedFile.name.endsWith('.pdf'))) {
+    if (selectedFile && (selectedFile.name.endsWith('.epub') || selectedFile.name.endsWith('.pdf') || selectedFile.name.endsWith('.pptx') || selectedFile.name.endsWith('.csv') || selectedFile.name.endsWith('.tsv'))) {
       setFile(selectedFile);
       setError('');
     } else {
-      setError('请选择 .epub 或 .pdf 文件');
+      setError('请选择 .epub、.pdf、.pptx、.csv 或 .tsv 文件');
       setFile(null);
     }
   };
@@ -274,12 +305,23 @@ function App() {
     formData.append('forceRetranslate', forceRetranslate.toString());
     formData.append('generateMode', generateMode); // 新增：生成模式
 
+    const submit = () => axios.post('/api/translate', formData, {
+      headers: {
+        'Content-Type': 'multipart/form-data',
+      },
+    });
+
     try {
-      const response = await axios.post('/api/translate', formData, {
-        headers: {
-          'Content-Type': 'multipart/form-data',
-        },
-      });
+      try {
+        await submit();
+      } catch (err) {
+        // 文档已是目标语言时由用户确认，确认后带 force 重新提交
+        if (err.response?.data?.code !== 'ALREADY_TARGET_LANGUAGE' || !window.confirm(`${err.response.data.error}\n\n仍要翻译吗？`)) {
+          throw err;
+        }
+        formData.append('force', 'true');
+        await submit();
+      }
 
       setFile(null);
       setForceRetranslate(false); // 重置选项
@@ -312,6 +354,8 @@ function App() {
   const getStatusColor = (status) => {
     switch (status) {
       case 'completed': return 'success';
+      case 'partial': return 'warning';
+      case 'timed_out': return 'warning';
       case 'processing': return 'primary';
       case 'failed': return 'error';
       default: return 'default';
@@ -321,8 +365,11 @@ function App() {
   const getStatusText = (status) => {
     switch (status) {
       case 'pending': return '等待中';
+      case 'queued': return '排队中';
       case 'processing': return '翻译中';
       case 'completed': return '已完成';
+      case 'partial': return '部分完成';
+      case 'timed_out': return '已超时';
       case 'failed': return '失败';
       default: return status;
     }
@@ -335,7 +382,7 @@ function App() {
           📚 文档翻译器
         </Typography>
         <Typography variant="subtitle1" color="text.secondary">
-          使用 AI 翻译 EPUB 电子书和 PDF 文档，生成双语对照版本
+          使用 AI 翻译 EPUB 电子书、PDF 文档和 PPTX 演示文稿，生成双语对照版本
         </Typography>
       </Box>
 
@@ -372,11 +419,11 @@ function App() {
               fullWidth
               sx={{ py: 2 }}
             >
-              {file ? file.name : '选择文档文件 (EPUB/PDF)'}
+              {file ? file.name : '选择文档文件 (EPUB/PDF/PPTX)'}
               <input
                 type="file"
                 hidden
-                accept=".epub,.pdf"
+                accept=".epub,.pdf,.pptx,.csv,.tsv"
                 onChange={handleFileChange}
               />
             </Button>
@@ -655,13 +702,31 @@ function App() {
                     </Box>
                   )}
 
+                  {task.status === 'queued' && task.queuePosition > 0 && (
+                    <Typography variant="caption" color="text.secondary" sx={{ display: 'block', mb: 2 }}>
+                      排队位置: 第 {task.queuePosition} 位
+                    </Typography>
+                  )}
+
                   {task.status === 'failed' && task.error && (
                     <Alert severity="error" sx={{ mb: 2 }}>
                       {task.error}
                     </Alert>
                   )}
 
-                  {task.status === 'completed' && (
+                  {task.status === 'partial' && (
+                    <Alert severity="warning" sx={{ mb: 2 }}>
+                      翻译中途失败，可下载部分结果（未翻译的段落保留原文）：{task.error}
+                    </Alert>
+                  )}
+
+                  {task.status === 'timed_out' && (
+                    <Alert severity="warning" sx={{ mb: 2 }}>
+                      翻译超时，已停止未完成的请求{task.outputPath ? '，可下载部分结果（未翻译的段落保留原文）' : ''}
+                    </Alert>
+                  )}
+
+                  {(task.status === 'completed' || task.status === 'partial' || (task.status === 'timed_out' && task.outputPath)) && (
                     <Button
                       variant="contained"
                       startIcon={<Download />}